@@ -38,6 +38,7 @@ val targets = arrayOf(
         "linux/amd64/1.15",
         "linux/amd64/1.16",
         "linux/amd64/1.17",
+        "linux/amd64/1.18",
         "linux/amd64/tip",
 
         "linux/386/1.17",
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+)
+
+func sender(ch chan int, v int) {
+	ch <- v
+}
+
+func receiver(ch chan string) {
+	<-ch
+}
+
+func main() {
+	ch := make(chan int, 4)
+	for i := 1; i <= 4; i++ {
+		ch <- i
+	}
+	<-ch
+	<-ch
+	ch <- 5
+	ch <- 6
+	// the buffer of ch is full and wraps around: 3, 4, 5, 6
+	go sender(ch, 7)
+	empty := make(chan string)
+	go receiver(empty)
+	go receiver(empty)
+	for runtime.NumGoroutine() < 4 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(100 * time.Millisecond)
+	runtime.Breakpoint()
+	fmt.Println(len(ch), len(empty))
+}
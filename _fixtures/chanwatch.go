@@ -0,0 +1,20 @@
+package main
+
+import "fmt"
+
+func main() {
+	ch1 := make(chan int, 1)
+	ch2 := make(chan int, 1)
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 3; i++ {
+			ch2 <- <-ch1
+		}
+		close(done)
+	}()
+	for i := 0; i < 3; i++ { // break here
+		ch1 <- i
+		fmt.Println(<-ch2)
+	}
+	<-done
+}
@@ -0,0 +1,16 @@
+package main
+
+import "fmt"
+
+func f(x int) int {
+	if x > 10 {
+		return x * 2
+	}
+	return x + 1
+}
+
+func main() {
+	for i := 0; i < 3; i++ {
+		fmt.Println(f(i))
+	}
+}
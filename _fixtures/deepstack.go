@@ -0,0 +1,15 @@
+package main
+
+import "runtime"
+
+func recurse(n int) int {
+	if n == 0 {
+		runtime.Breakpoint()
+		return 0
+	}
+	return recurse(n-1) + 1
+}
+
+func main() {
+	recurse(1500)
+}
@@ -0,0 +1,13 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+func main() {
+	os.Setenv("DLV_ENV_A", "1")
+	runtime.Breakpoint()
+	fmt.Println(os.Getenv("DLV_ENV_A"), os.Getenv("DLV_ENV_B"))
+}
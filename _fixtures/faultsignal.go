@@ -0,0 +1,14 @@
+package main
+
+import "fmt"
+
+type T struct {
+	a, b int
+}
+
+func main() {
+	var p *T
+	fmt.Println("before")
+	p.b = 1
+	fmt.Println("after")
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"runtime"
+	"time"
+)
+
+func main() {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(err)
+	}
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			panic(err)
+		}
+		conn.Close()
+	}()
+	time.Sleep(time.Second)
+	fmt.Println(listener.Addr())
+	runtime.Breakpoint()
+	listener.Close()
+}
@@ -0,0 +1,25 @@
+package main
+
+import "time"
+
+var counts [2]int
+
+func worker(i int) {
+	for {
+		counts[i]++
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+//go:noinline
+func checkpoint() {
+}
+
+func main() {
+	go worker(0)
+	go worker(1)
+	for {
+		time.Sleep(200 * time.Millisecond)
+		checkpoint()
+	}
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"runtime"
+	"sync"
+)
+
+var wg sync.WaitGroup
+
+func leaf() {
+	runtime.Breakpoint()
+	wg.Done()
+}
+
+func middle() {
+	go leaf()
+	wg.Done()
+}
+
+func main() {
+	wg.Add(2)
+	go middle()
+	wg.Wait()
+}
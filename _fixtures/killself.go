@@ -0,0 +1,8 @@
+package main
+
+import "syscall"
+
+func main() {
+	syscall.Kill(syscall.Getpid(), syscall.SIGKILL)
+	select {}
+}
@@ -0,0 +1,11 @@
+package main
+
+import "fmt"
+
+func main() {
+	buf := make([]byte, 2500)
+	for i := range buf {
+		buf[i] = byte(i)
+	}
+	fmt.Println(len(buf))
+}
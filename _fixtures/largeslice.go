@@ -0,0 +1,16 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+)
+
+func main() {
+	s := make([]int, 1<<21)
+	for i := range s {
+		s[i] = i
+	}
+	x := 0
+	runtime.Breakpoint()
+	fmt.Println(len(s), x)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+var mu sync.Mutex
+var rwmu sync.RWMutex
+
+func waiter(wg *sync.WaitGroup) {
+	wg.Done()
+	mu.Lock()
+	mu.Unlock()
+}
+
+func reader() {
+	rwmu.RLock()
+	time.Sleep(time.Hour)
+}
+
+func holder() {
+	mu.Lock()
+	defer mu.Unlock()
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go waiter(&wg)
+	}
+	wg.Wait()
+	go reader()
+	for {
+		// wait for the waiters and the reader to block
+		time.Sleep(100 * time.Millisecond)
+		if runtime.NumGoroutine() >= 5 {
+			break
+		}
+	}
+	time.Sleep(time.Second)
+	runtime.Breakpoint()
+}
+
+func main() {
+	holder()
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+)
+
+var sink []byte
+
+func work() int {
+	s := 0
+	for i := 0; i < 1000000; i++ {
+		s += i
+		if i%1000 == 0 {
+			sink = make([]byte, 1024)
+		}
+	}
+	return s
+}
+
+func main() {
+	// Makes os.Create and runtime/pprof available to the debugger.
+	if len(os.Args) > 1 {
+		f, _ := os.Create(os.Args[1])
+		pprof.StartCPUProfile(f)
+		defer pprof.StopCPUProfile()
+		pprof.WriteHeapProfile(f)
+	}
+	runtime.Breakpoint()
+	s := 0
+	for {
+		s += work()
+		if s < 0 {
+			break
+		}
+	}
+	fmt.Println(s)
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"net"
+	"runtime"
+	"sync"
+	"time"
+)
+
+func main() {
+	tm := time.Date(2021, 3, 4, 10, 0, 0, 0, time.UTC)
+	tmfixed := tm.In(time.FixedZone("XYZ", 3*60*60))
+	var n big.Int
+	n.SetString("-123456789012345678901234567890", 10)
+	ip := net.ParseIP("192.168.1.1").To4()
+	ip6 := net.ParseIP("2001:db8::1")
+	var buf bytes.Buffer
+	buf.WriteString("hello, world")
+	buf.Next(7)
+	var m sync.Map
+	m.Store("a", 1)
+	m.Store("b", 2)
+	runtime.Breakpoint()
+	fmt.Println(tm, tmfixed, &n, ip, ip6, buf.String(), &m)
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/trace"
+)
+
+func work(n int, done chan<- int) {
+	s := 0
+	for i := 0; i < n; i++ {
+		s += i
+	}
+	done <- s
+}
+
+func main() {
+	// Makes os.Create and runtime/trace available to the debugger.
+	if len(os.Args) > 1 {
+		f, _ := os.Create(os.Args[1])
+		trace.Start(f)
+		defer trace.Stop()
+	}
+	runtime.Breakpoint()
+	done := make(chan int)
+	for i := 0; i < 10; i++ {
+		go work(1000000, done)
+	}
+	s := 0
+	for i := 0; i < 10; i++ {
+		s += <-done
+	}
+	runtime.Breakpoint()
+	fmt.Println(s)
+}
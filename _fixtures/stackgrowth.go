@@ -0,0 +1,17 @@
+package main
+
+import "runtime"
+
+func grow(n int) int {
+	var buf [1024]byte
+	buf[n%len(buf)] = byte(n)
+	if n == 0 {
+		runtime.Breakpoint()
+		return int(buf[0])
+	}
+	return grow(n-1) + int(buf[n%len(buf)])
+}
+
+func main() {
+	grow(64)
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+)
+
+type Pair[K comparable, V any] struct {
+	Key K
+	Val V
+}
+
+func testfn[T any, K comparable](arg1 T, arg2 K) {
+	m := map[K]T{}
+	m[arg2] = arg1
+	p := Pair[K, T]{arg2, arg1}
+	runtime.Breakpoint()
+	fmt.Println(arg1, arg2, m, p)
+}
+
+func main() {
+	testfn[uint16, float64](1, 2.0)
+	testfn(3, "three")
+	testfn(&Pair[int, string]{1, "a"}, 4)
+}
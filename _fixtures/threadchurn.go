@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+func work(i int) int {
+	return i * 2
+}
+
+func main() {
+	var wg sync.WaitGroup
+	for n := 0; n < 200; n++ {
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				// Returning from a goroutine locked to its thread terminates the
+				// thread.
+				runtime.LockOSThread()
+				work(i)
+			}(i)
+		}
+		wg.Wait()
+	}
+	fmt.Println("done")
+}
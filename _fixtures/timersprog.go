@@ -0,0 +1,20 @@
+package main
+
+import (
+	"runtime"
+	"time"
+)
+
+func afterFunc() {
+	println("fired")
+}
+
+func main() {
+	t := time.NewTimer(2 * time.Hour)
+	tk := time.NewTicker(time.Hour)
+	af := time.AfterFunc(3*time.Hour, afterFunc)
+	runtime.Breakpoint()
+	t.Stop()
+	tk.Stop()
+	af.Stop()
+}
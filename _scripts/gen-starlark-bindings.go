@@ -229,6 +229,7 @@ func genDocs(bindings []binding) []byte {
 	fmt.Fprintf(&buf, "write_file(path, contents) | Writes string to a file\n")
 	fmt.Fprintf(&buf, "cur_scope() | Returns the current evaluation scope\n")
 	fmt.Fprintf(&buf, "default_load_config() | Returns the current default load configuration\n")
+	fmt.Fprintf(&buf, "register_printer(type_name, fn) | Registers fn as the pretty-printer of variables of type type_name, fn is called with the variable and must return a string. Passing None disables pretty-printing for the type\n")
 
 	return buf.Bytes()
 }
@@ -21,6 +21,7 @@ var Verbose bool
 var NOTimeout bool
 var TestIncludePIE bool
 var TestSet, TestRegex, TestBackend, TestBuildMode string
+var TestShard, TestFixturesCache string
 
 func NewMakeCommands() *cobra.Command {
 	RootCommand := &cobra.Command{
@@ -96,6 +97,8 @@ This option can only be specified if testset is basic or a single package.`)
 	
 This option can only be specified if testset is basic or a single package.`)
 	test.PersistentFlags().BoolVarP(&TestIncludePIE, "pie", "", true, "Standard testing should include PIE")
+	test.PersistentFlags().StringVarP(&TestShard, "shard", "", "", `Only runs the k-th of n shards of the tests of each package, specified as k/n.`)
+	test.PersistentFlags().StringVarP(&TestFixturesCache, "fixtures-cache", "", "", `Directory where test fixtures are cached between runs, instead of being rebuilt every time.`)
 
 	RootCommand.AddCommand(test)
 
@@ -293,6 +296,13 @@ func testFlags() []string {
 func testCmd(cmd *cobra.Command, args []string) {
 	checkCertCmd(nil, nil)
 
+	if TestShard != "" {
+		os.Setenv("DELVE_TEST_SHARD", TestShard)
+	}
+	if TestFixturesCache != "" {
+		os.Setenv("DELVE_FIXTURES_CACHE", TestFixturesCache)
+	}
+
 	if os.Getenv("TRAVIS") == "true" && runtime.GOOS == "darwin" {
 		fmt.Println("Building with native backend")
 		execute("go", "build", "-tags=macnative", buildFlags(), DelveMainPackagePath)
@@ -351,6 +361,25 @@ func testStandard() {
 		fmt.Println("\nTesting PIE buildmode, RR backend")
 		testCmdIntl("basic", "", "rr", "pie")
 	}
+	if goversion.VersionAfterOrEqual(runtime.Version(), 1, 18) {
+		fmt.Println("\nTesting gRPC API")
+		testGRPC()
+	}
+}
+
+// testGRPC tests the module in service/rpcgrpc, it is a separate module
+// because it needs Go 1.18 or later.
+func testGRPC() {
+	fmt.Printf("go test ./... (in service/rpcgrpc)\n")
+	x := exec.Command("go", strflatten([]interface{}{"test", testFlags(), "./..."})...)
+	x.Dir = "service/rpcgrpc"
+	x.Stdout = os.Stdout
+	x.Stderr = os.Stderr
+	x.Env = os.Environ()
+	if err := x.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing go test in service/rpcgrpc: %v\n", err)
+		os.Exit(1)
+	}
 }
 
 func testCmdIntl(testSet, testRegex, testBackend, testBuildMode string) {
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
 	"os/exec"
 	"os/signal"
@@ -13,19 +14,23 @@ import (
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/go-delve/delve/pkg/config"
 	"github.com/go-delve/delve/pkg/gobuild"
 	"github.com/go-delve/delve/pkg/goversion"
 	"github.com/go-delve/delve/pkg/logflags"
+	"github.com/go-delve/delve/pkg/metrics"
 	"github.com/go-delve/delve/pkg/terminal"
 	"github.com/go-delve/delve/pkg/version"
 	"github.com/go-delve/delve/service"
 	"github.com/go-delve/delve/service/api"
 	"github.com/go-delve/delve/service/dap"
 	"github.com/go-delve/delve/service/debugger"
+	"github.com/go-delve/delve/service/proxy"
 	"github.com/go-delve/delve/service/rpc2"
 	"github.com/go-delve/delve/service/rpccommon"
+	"github.com/go-delve/delve/service/webui"
 	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
 )
@@ -47,6 +52,23 @@ var (
 	acceptMulti bool
 	// addr is the debugging server listen address.
 	addr string
+	// observerAddr is the listen address for read-only clients.
+	observerAddr string
+	// grpcAddr is the listen address for gRPC clients, the flag setting it
+	// only exists when NewGRPCServer is set.
+	grpcAddr string
+	// captureOutput is true if the headless server should capture the
+	// output of the target for its clients.
+	captureOutput bool
+	// metricsAddr is the listen address of the metrics endpoint.
+	metricsAddr string
+	// dapWebAddr is the listen address of the web frontend of dap sessions.
+	dapWebAddr string
+	// proxyDAP is true if the proxy command should use DAP instead of JSON-RPC.
+	proxyDAP bool
+	// idleTimeout is the amount of time without client activity after
+	// which a headless server detaches and exits.
+	idleTimeout time.Duration
 	// initFile is the path to initialization file.
 	initFile string
 	// buildFlags is the flags passed during compiler invocation.
@@ -60,6 +82,18 @@ var (
 	tty string
 	// disableASLR is used to disable ASLR
 	disableASLR bool
+	// stopOnFault is whether to stop on all fault signals, including the
+	// ones the Go runtime converts into panics.
+	stopOnFault bool
+	// reattachOnExit is the pid file or executable name of the process to
+	// attach to when the target exits.
+	reattachOnExit string
+	// attachWaitFor is the pid file or executable name of the process that
+	// attach waits for.
+	attachWaitFor string
+	// stopOnEntry is whether to run a launched process until main.main
+	// before the debug session starts.
+	stopOnEntry bool
 
 	// backend selection
 	backend string
@@ -82,9 +116,24 @@ var (
 
 	allowNonTerminalInteractive bool
 
+	// batch is true if the terminal client should read commands from
+	// stdin without prompting and without asking questions.
+	batch bool
+
+	// killOnExit and continueOnExit select what happens to the target
+	// when the terminal client exits, instead of asking.
+	killOnExit     bool
+	continueOnExit bool
+
 	conf *config.Config
 )
 
+// NewGRPCServer, if not nil, enables the --grpc-listen flag and creates
+// the server of the gRPC API. It is set by the dlv command of module
+// github.com/go-delve/delve/service/rpcgrpc before calling New, see
+// Documentation/api/grpc/README.md.
+var NewGRPCServer func() service.GRPCServer
+
 const dlvCommandLongDesc = `Delve is a source level debugger for Go programs.
 
 Delve enables you to interact with your program by controlling the execution of the process,
@@ -124,6 +173,13 @@ func New(docCall bool) *cobra.Command {
 
 	rootCommand.PersistentFlags().BoolVarP(&headless, "headless", "", false, "Run debug server only, in headless mode.")
 	rootCommand.PersistentFlags().BoolVarP(&acceptMulti, "accept-multiclient", "", false, "Allows a headless server to accept multiple client connections.")
+	rootCommand.PersistentFlags().StringVar(&observerAddr, "observer-listen", "", "Serves read-only clients, that can inspect the target but not set breakpoints or resume it, on the specified address, only when headless.")
+	if NewGRPCServer != nil {
+		rootCommand.PersistentFlags().StringVar(&grpcAddr, "grpc-listen", "", "Serves the API over gRPC on the specified address, only when headless. See Documentation/api/grpc/README.md.")
+	}
+	rootCommand.PersistentFlags().BoolVar(&captureOutput, "capture-output", false, "Captures the output of the target process, instead of writing it to the output of Delve, so that clients can read it with the 'output' command, only when headless.")
+	rootCommand.PersistentFlags().StringVar(&metricsAddr, "metrics-listen", "", "Serves internal metrics in the Prometheus text format at /metrics on the specified address, only when headless.")
+	rootCommand.PersistentFlags().DurationVar(&idleTimeout, "idle-timeout", 0, "Detaches from the target and exits if no client activity happens for the specified duration, only when headless. Processes started by Delve are killed.")
 	rootCommand.PersistentFlags().IntVar(&apiVersion, "api-version", 1, "Selects API version when headless. New clients should use v2. Can be reset via RPCServer.SetApiVersion. See Documentation/api/json-rpc/README.md.")
 	rootCommand.PersistentFlags().StringVar(&initFile, "init", "", "Init file, executed by the terminal client.")
 	rootCommand.PersistentFlags().StringVar(&buildFlags, "build-flags", buildFlagsDefault, "Build flags, to be passed to the compiler. For example: --build-flags=\"-tags=integration -mod=vendor -cover -v\"")
@@ -133,7 +189,11 @@ func New(docCall bool) *cobra.Command {
 	rootCommand.PersistentFlags().StringVar(&backend, "backend", "default", `Backend selection (see 'dlv help backend').`)
 	rootCommand.PersistentFlags().StringArrayVarP(&redirects, "redirect", "r", []string{}, "Specifies redirect rules for target process (see 'dlv help redirect')")
 	rootCommand.PersistentFlags().BoolVar(&allowNonTerminalInteractive, "allow-non-terminal-interactive", false, "Allows interactive sessions of Delve that don't have a terminal as stdin, stdout and stderr")
+	rootCommand.PersistentFlags().BoolVar(&batch, "batch", false, "Reads commands from stdin without prompting, never asks questions and exits at the end of input. Enabled automatically when stdin is not a terminal. Unless redirected, the stdin of launched targets is the null device.")
+	rootCommand.PersistentFlags().BoolVar(&killOnExit, "kill-on-exit", false, "Kills the target process, and the headless instance when connected to one, when the terminal client exits without asking.")
+	rootCommand.PersistentFlags().BoolVar(&continueOnExit, "continue-on-exit", false, "Resumes the target process, and leaves the headless instance running when connected to one, when the terminal client exits without asking.")
 	rootCommand.PersistentFlags().BoolVar(&disableASLR, "disable-aslr", false, "Disables address space randomization")
+	rootCommand.PersistentFlags().BoolVar(&stopOnFault, "stop-on-fault", false, "Stops the target on all SIGSEGV and SIGBUS signals, including the ones the Go runtime converts into panics (for example nil pointer dereferences). By default the target only stops on faults outside of Go code.")
 
 	// 'attach' subcommand.
 	attachCommand := &cobra.Command{
@@ -144,9 +204,21 @@ func New(docCall bool) *cobra.Command {
 This command will cause Delve to take control of an already running process, and
 begin a new debug session.  When exiting the debug session you will have the
 option to let the process continue or kill it.
+
+With --wait Delve does not take a pid, it waits for a new process to start and
+attaches to it, which makes it possible to debug the initialization of programs
+started by other systems. Delve looks for new processes every 100ms, the
+process can run for up to that long before it is stopped:
+
+	dlv attach --wait myserver
+	dlv attach --wait /var/run/myserver.pid [executable]
+
+The argument of --wait is either the path of a pid file (it must contain a path
+separator) or the name of an executable. Processes that are already running
+when Delve starts are ignored.
 `,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-			if len(args) == 0 {
+			if len(args) == 0 && attachWaitFor == "" {
 				return errors.New("you must provide a PID")
 			}
 			return nil
@@ -154,6 +226,8 @@ option to let the process continue or kill it.
 		Run: attachCmd,
 	}
 	attachCommand.Flags().BoolVar(&continueOnStart, "continue", false, "Continue the debugged process on start.")
+	attachCommand.Flags().StringVar(&attachWaitFor, "wait", "", "Waits for a new process, specified by the path of its pid file or the name of its executable, and attaches to it, new processes are looked for every 100ms.")
+	attachCommand.Flags().StringVar(&reattachOnExit, "reattach-on-exit", "", "When the process exits while continuing, waits for a new one and attaches to it, recreating the breakpoints. The argument is either the path of a pid file (it must contain a path separator) or the name of an executable. Only processes of the owner of the exited process are attached, but any of them started with a matching name is, including ones that are not the restarted target, prefer a pid file in a directory only writable by that user.")
 	rootCommand.AddCommand(attachCommand)
 
 	// 'connect' subcommand.
@@ -171,6 +245,29 @@ option to let the process continue or kill it.
 	}
 	rootCommand.AddCommand(connectCommand)
 
+	// 'serve-ui' subcommand.
+	serveUICommand := &cobra.Command{
+		Use:   "serve-ui addr",
+		Short: "Serve a web frontend for a headless debug server.",
+		Long: `Connects to a running headless debug server and serves a web frontend for it.
+
+The web frontend shows the source code, breakpoints, variables and goroutines
+of the debugged process and can be used to control it from a browser, which
+is useful to debug programs running on headless machines.
+The frontend is served on the address specified by --listen.
+Source files are read locally, they must be available at the same paths
+used by the headless instance, for example by running serve-ui on the same
+machine.`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return errors.New("you must provide an address as the first argument")
+			}
+			return nil
+		},
+		Run: serveUICmd,
+	}
+	rootCommand.AddCommand(serveUICommand)
+
 	// 'dap' subcommand.
 	dapCommand := &cobra.Command{
 		Use:   "dap",
@@ -187,9 +284,12 @@ to be launched or process to be attached to. The following modes are supported:
 - attach + local (attaches to a running process, like 'dlv attach')
 The server does not yet accept multiple client connections (--accept-multiclient).
 While --continue is not supported, stopOnEntry launch/attach attribute can be used to control if
-execution is resumed at the start of the debug session.`,
+execution is resumed at the start of the debug session.
+With --web a read-only web frontend of the debug session, like the one of
+'dlv serve-ui', is served on the specified address.`,
 		Run: dapCmd,
 	}
+	dapCommand.Flags().StringVar(&dapWebAddr, "web", "", "Serves a read-only web frontend of the debug session on the specified address.")
 	// TODO(polina): support --tty when dlv dap allows to launch a program from command-line
 	rootCommand.AddCommand(dapCommand)
 
@@ -208,6 +308,7 @@ session.`,
 	debugCommand.Flags().String("output", "./__debug_bin", "Output path for the binary.")
 	debugCommand.Flags().BoolVar(&continueOnStart, "continue", false, "Continue the debugged process on start.")
 	debugCommand.Flags().StringVar(&tty, "tty", "", "TTY to use for the target program")
+	debugCommand.Flags().BoolVar(&stopOnEntry, "stop-on-entry", false, "Run the program until main.main, after the runtime and package initialization, before starting the debug session.")
 	rootCommand.AddCommand(debugCommand)
 
 	// 'exec' subcommand.
@@ -233,6 +334,7 @@ or later, -gcflags="-N -l" on earlier versions of Go.`,
 	}
 	execCommand.Flags().StringVar(&tty, "tty", "", "TTY to use for the target program")
 	execCommand.Flags().BoolVar(&continueOnStart, "continue", false, "Continue the debugged process on start.")
+	execCommand.Flags().BoolVar(&stopOnEntry, "stop-on-entry", false, "Run the program until main.main, after the runtime and package initialization, before starting the debug session.")
 	rootCommand.AddCommand(execCommand)
 
 	// Deprecated 'run' subcommand.
@@ -263,6 +365,7 @@ See also: 'go help testflag'.`,
 		Run: testCmd,
 	}
 	testCommand.Flags().String("output", "debug.test", "Output path for the binary.")
+	testCommand.Flags().BoolVar(&stopOnEntry, "stop-on-entry", false, "Run the program until main.main, after the runtime and package initialization, before starting the debug session.")
 	rootCommand.AddCommand(testCommand)
 
 	// 'trace' subcommand.
@@ -307,6 +410,94 @@ Currently supports linux/amd64 and linux/arm64 core files, windows/amd64 minidum
 	}
 	rootCommand.AddCommand(coreCommand)
 
+	// 'symbolize' subcommand.
+	symbolizeCommand := &cobra.Command{
+		Use:   "symbolize <executable> [trace file...]",
+		Short: "Annotates addresses in stack traces with their source position.",
+		Long: `Annotates addresses in stack traces with their source position.
+
+The symbolize command reads text, for example the output of a panic, a
+fatal signal or a list of pprof addresses, from the specified files or from
+standard input and writes it to standard output, following every address
+that belongs to a function of the executable with the name of the function
+and its file:line position. No process is started.
+
+Addresses are looked up as they are, the addresses of position independent
+executables must be converted to link time addresses first.`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return errors.New("you must provide an executable")
+			}
+			return nil
+		},
+		Run: symbolizeCmd,
+	}
+	rootCommand.AddCommand(symbolizeCommand)
+
+	// 'inspect' subcommand.
+	inspectCommand := &cobra.Command{
+		Use:   "inspect <executable>",
+		Short: "Prints a summary of the debug info of an executable.",
+		Long: `Prints a summary of the debug info of an executable.
+
+The inspect command loads the debug info of the specified executable, without
+starting it, and prints the version of Go used to build it, the flags that
+affect code generation, whether it was optimized and the number of compile
+units, packages, functions and types it contains.
+
+The full lists of compile units, packages, functions and types can be
+printed with the corresponding flags.`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("you must provide an executable")
+			}
+			return nil
+		},
+		Run: inspectCmd,
+	}
+	inspectCommand.Flags().BoolVar(&inspectCompileUnits, "compile-units", false, "Lists the compile units.")
+	inspectCommand.Flags().BoolVar(&inspectPackages, "packages", false, "Lists the packages.")
+	inspectCommand.Flags().BoolVar(&inspectFunctions, "functions", false, "Lists the functions.")
+	inspectCommand.Flags().BoolVar(&inspectTypes, "types", false, "Lists the types.")
+	rootCommand.AddCommand(inspectCommand)
+
+	// 'proxy' subcommand.
+	proxyCommand := &cobra.Command{
+		Use:   "proxy",
+		Short: "Records or replays the traffic between a client and a headless debug server.",
+		Long: `Records or replays the traffic between a client and a headless debug server.
+
+The 'record' subcommand listens on the address specified by --listen and forwards every
+connection to a running headless debug server, logging every request and response as a
+line of JSON. The 'replay' subcommand answers requests using the responses in such a log,
+without a target process, so that clients can be tested against realistic traffic offline.
+
+By default JSON-RPC traffic is handled, use --dap for servers started with 'dlv dap'.`,
+	}
+	proxyCommand.PersistentFlags().BoolVar(&proxyDAP, "dap", false, "Handle Debug Adapter Protocol traffic instead of JSON-RPC.")
+	proxyCommand.AddCommand(&cobra.Command{
+		Use:   "record addr logfile",
+		Short: "Forwards connections to addr and logs the traffic to logfile.",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 2 {
+				return errors.New("you must provide the address of the server and a log file")
+			}
+			return nil
+		},
+		Run: proxyRecordCmd,
+	}, &cobra.Command{
+		Use:   "replay logfile",
+		Short: "Answers requests using the responses recorded in logfile.",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("you must provide a log file")
+			}
+			return nil
+		},
+		Run: proxyReplayCmd,
+	})
+	rootCommand.AddCommand(proxyCommand)
+
 	// 'version' subcommand.
 	versionCommand := &cobra.Command{
 		Use:   "version",
@@ -441,6 +632,13 @@ func dapCmd(cmd *cobra.Command, args []string) {
 			fmt.Fprintf(os.Stderr, "Warning: program flags ignored with dap; specify via launch/attach request instead\n")
 		}
 
+		if metricsAddr != "" {
+			if err := serveMetrics(metricsAddr); err != nil {
+				fmt.Fprintf(os.Stderr, "couldn't start metrics listener: %s\n", err)
+				return 1
+			}
+		}
+
 		listener, err := net.Listen("tcp", addr)
 		if err != nil {
 			fmt.Printf("couldn't start listener: %s\n", err)
@@ -457,9 +655,26 @@ func dapCmd(cmd *cobra.Command, args []string) {
 				CheckGoVersion:       checkGoVersion,
 			},
 			CheckLocalConnUser: checkLocalConnUser,
+			IdleTimeout:        idleTimeout,
 		})
 		defer server.Stop()
 
+		if dapWebAddr != "" {
+			webListener, err := net.Listen("tcp", dapWebAddr)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "couldn't start web listener: %s\n", err)
+				return 1
+			}
+			webServer := webui.NewServer(webui.NewDebuggerClient(server.Debugger), webListener)
+			fmt.Printf("Web UI listening at: http://%s/\n", webListener.Addr())
+			go func() {
+				if err := webServer.Run(); err != nil {
+					fmt.Fprintln(os.Stderr, err)
+				}
+			}()
+			defer webServer.Stop()
+		}
+
 		server.Run()
 		waitForDisconnectSignal(disconnectChan)
 		return 0
@@ -611,7 +826,24 @@ func traceCmd(cmd *cobra.Command, args []string) {
 		cmds := terminal.DebugCommands(client)
 		t := terminal.New(client, nil)
 		defer t.Close()
+
+		// Stop the target on SIGINT and detach from it cleanly.
+		ch := make(chan os.Signal, 1)
+		signal.Notify(ch, syscall.SIGINT)
+		defer signal.Stop(ch)
+		go func() {
+			for range ch {
+				client.Halt()
+			}
+		}()
+
 		cmds.Call("continue", t)
+		if state, err := client.GetState(); err == nil && !state.Exited {
+			if err := client.Detach(traceAttachPid == 0); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+		}
 		return 0
 	}()
 	os.Exit(status)
@@ -668,6 +900,13 @@ func getPackageDir(pkg string) string {
 }
 
 func attachCmd(cmd *cobra.Command, args []string) {
+	if attachWaitFor != "" {
+		if len(args) > 1 {
+			fmt.Fprint(os.Stderr, "Error: --wait does not take a pid, only the path of the executable\n")
+			os.Exit(1)
+		}
+		os.Exit(execute(0, args, conf, "", debugger.ExecutingOther, args, buildFlags))
+	}
 	pid, err := strconv.Atoi(args[0])
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Invalid pid: %s\n", args[0])
@@ -689,6 +928,83 @@ func connectCmd(cmd *cobra.Command, args []string) {
 	os.Exit(connect(addr, nil, conf, debugger.ExecutingOther))
 }
 
+func proxyProtocol() proxy.Protocol {
+	if proxyDAP {
+		return proxy.DAP
+	}
+	return proxy.JSONRPC
+}
+
+func proxyRecordCmd(cmd *cobra.Command, args []string) {
+	out, err := os.Create(args[1])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer out.Close()
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "couldn't start listener: %s\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Proxy listening at: %s\n", listener.Addr())
+	go func() {
+		waitForDisconnectSignal(nil)
+		listener.Close()
+	}()
+	if err := proxy.Record(listener, args[0], proxyProtocol(), out); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
+
+func proxyReplayCmd(cmd *cobra.Command, args []string) {
+	fh, err := os.Open(args[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	entries, err := proxy.ReadLog(fh)
+	fh.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not read %s: %v\n", args[0], err)
+		os.Exit(1)
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "couldn't start listener: %s\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Replay server listening at: %s\n", listener.Addr())
+	go func() {
+		waitForDisconnectSignal(nil)
+		listener.Close()
+	}()
+	proxy.Replay(listener, proxyProtocol(), entries)
+}
+
+func serveUICmd(cmd *cobra.Command, args []string) {
+	if args[0] == "" {
+		fmt.Fprint(os.Stderr, "An empty address was provided. You must provide an address as the first argument.\n")
+		os.Exit(1)
+	}
+	client := rpc2.NewClient(args[0])
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "couldn't start listener: %s\n", err)
+		os.Exit(1)
+	}
+	server := webui.NewServer(client, listener)
+	fmt.Printf("Web UI listening at: http://%s/\n", listener.Addr())
+	go func() {
+		if err := server.Run(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}()
+	waitForDisconnectSignal(nil)
+	server.Stop()
+}
+
 // waitForDisconnectSignal is a blocking function that waits for either
 // a SIGINT (Ctrl-C) or SIGTERM (kill -15) OS signal or for disconnectChan
 // to be closed by the server when the client disconnects.
@@ -751,6 +1067,16 @@ func connect(addr string, clientConn net.Conn, conf *config.Config, kind debugge
 	}
 	term := terminal.New(client, conf)
 	term.InitFile = initFile
+	term.Batch = batch || !isatty.IsTerminal(os.Stdin.Fd())
+	switch {
+	case killOnExit && continueOnExit:
+		fmt.Fprintln(os.Stderr, "Can not use --kill-on-exit and --continue-on-exit together")
+		return 1
+	case killOnExit:
+		term.OnExit = terminal.ExitKill
+	case continueOnExit:
+		term.OnExit = terminal.ExitContinue
+	}
 	status, err := term.Run()
 	if err != nil {
 		fmt.Println(err)
@@ -779,6 +1105,30 @@ func execute(attachPid int, processArgs []string, conf *config.Config, coreFile
 		}
 	}
 
+	if !headless && metricsAddr != "" {
+		fmt.Fprint(os.Stderr, "Warning metrics-listen: ignored\n")
+	}
+
+	if !headless && observerAddr != "" {
+		fmt.Fprint(os.Stderr, "Warning observer-listen: ignored\n")
+		observerAddr = ""
+	}
+
+	if !headless && grpcAddr != "" {
+		fmt.Fprint(os.Stderr, "Warning grpc-listen: ignored\n")
+		grpcAddr = ""
+	}
+
+	if !headless && captureOutput {
+		fmt.Fprint(os.Stderr, "Warning capture-output: ignored\n")
+		captureOutput = false
+	}
+
+	if !headless && idleTimeout != 0 {
+		fmt.Fprint(os.Stderr, "Warning idle-timeout: ignored\n")
+		idleTimeout = 0
+	}
+
 	if !headless && acceptMulti {
 		fmt.Fprint(os.Stderr, "Warning accept-multi: ignored\n")
 		// acceptMulti won't work in normal (non-headless) mode because we always
@@ -786,7 +1136,13 @@ func execute(attachPid int, processArgs []string, conf *config.Config, coreFile
 		acceptMulti = false
 	}
 
-	if !headless && !allowNonTerminalInteractive {
+	if !headless && !isatty.IsTerminal(os.Stdin.Fd()) {
+		// same as connect, commands piped into the terminal client are
+		// executed in batch mode.
+		batch = true
+	}
+
+	if !headless && !allowNonTerminalInteractive && !batch {
 		for _, f := range []struct {
 			name string
 			file *os.File
@@ -811,6 +1167,10 @@ func execute(attachPid int, processArgs []string, conf *config.Config, coreFile
 		fmt.Fprintf(os.Stderr, "%v\n", err)
 		return 1
 	}
+	if !headless && batch && redirects[0] == "" && tty == "" {
+		// The commands are read from stdin, don't let the target consume them.
+		redirects[0] = os.DevNull
+	}
 
 	var listener net.Listener
 	var clientConn net.Conn
@@ -827,6 +1187,28 @@ func execute(attachPid int, processArgs []string, conf *config.Config, coreFile
 	}
 	defer listener.Close()
 
+	var observerListener net.Listener
+	if observerAddr != "" {
+		observerListener, err = net.Listen("tcp", observerAddr)
+		if err != nil {
+			fmt.Printf("couldn't start observer listener: %s\n", err)
+			return 1
+		}
+		defer observerListener.Close()
+	}
+
+	var grpcListener net.Listener
+	var grpcServer service.GRPCServer
+	if grpcAddr != "" {
+		grpcListener, err = net.Listen("tcp", grpcAddr)
+		if err != nil {
+			fmt.Printf("couldn't start gRPC listener: %s\n", err)
+			return 1
+		}
+		defer grpcListener.Close()
+		grpcServer = NewGRPCServer()
+	}
+
 	var server service.Server
 
 	disconnectChan := make(chan struct{})
@@ -840,13 +1222,18 @@ func execute(attachPid int, processArgs []string, conf *config.Config, coreFile
 	case 1, 2:
 		server = rpccommon.NewServer(&service.Config{
 			Listener:           listener,
+			ObserverListener:   observerListener,
+			GRPCListener:       grpcListener,
+			GRPCServer:         grpcServer,
 			ProcessArgs:        processArgs,
 			AcceptMulti:        acceptMulti,
 			APIVersion:         apiVersion,
 			CheckLocalConnUser: checkLocalConnUser,
 			DisconnectChan:     disconnectChan,
+			IdleTimeout:        idleTimeout,
 			Debugger: debugger.Config{
 				AttachPid:            attachPid,
+				AttachWaitFor:        attachWaitFor,
 				WorkingDir:           workingDir,
 				Backend:              backend,
 				CoreFile:             coreFile,
@@ -858,7 +1245,11 @@ func execute(attachPid int, processArgs []string, conf *config.Config, coreFile
 				CheckGoVersion:       checkGoVersion,
 				TTY:                  tty,
 				Redirects:            redirects,
+				CaptureOutput:        captureOutput,
 				DisableASLR:          disableASLR,
+				ReattachOnExit:       reattachOnExit,
+				StopOnEntry:          stopOnEntry,
+				StopOnFault:          stopOnFault,
 			},
 		})
 	default:
@@ -885,6 +1276,13 @@ func execute(attachPid int, processArgs []string, conf *config.Config, coreFile
 
 	var status int
 	if headless {
+		if metricsAddr != "" {
+			if err := serveMetrics(metricsAddr); err != nil {
+				fmt.Fprintf(os.Stderr, "couldn't start metrics listener: %s\n", err)
+				server.Stop()
+				return 1
+			}
+		}
 		if continueOnStart {
 			client := rpc2.NewClient(listener.Addr().String())
 			client.Disconnect(true) // true = continue after disconnect
@@ -901,6 +1299,19 @@ func execute(attachPid int, processArgs []string, conf *config.Config, coreFile
 	return connect(listener.Addr().String(), clientConn, conf, kind)
 }
 
+// serveMetrics starts serving the internal metrics of Delve on addr.
+func serveMetrics(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Metrics listening at: http://%s/metrics\n", listener.Addr())
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	go http.Serve(listener, mux)
+	return nil
+}
+
 func parseRedirects(redirects []string) ([3]string, error) {
 	r := [3]string{}
 	names := [3]string{"stdin", "stdout", "stderr"}
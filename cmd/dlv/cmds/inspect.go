@@ -0,0 +1,125 @@
+package cmds
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/go-delve/delve/pkg/proc"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// inspectFunctions, inspectTypes, inspectCompileUnits and
+	// inspectPackages select the lists printed by the inspect command.
+	inspectFunctions    bool
+	inspectTypes        bool
+	inspectCompileUnits bool
+	inspectPackages     bool
+)
+
+func inspectCmd(cmd *cobra.Command, args []string) {
+	goos, goarch, err := proc.ExecutablePlatform(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not load %s: %v\n", args[0], err)
+		os.Exit(1)
+	}
+	bi := proc.NewBinaryInfo(goos, goarch)
+	if err := bi.LoadBinaryInfo(args[0], 0, conf.DebugInfoDirectories); err != nil {
+		fmt.Fprintf(os.Stderr, "could not load %s: %v\n", args[0], err)
+		os.Exit(1)
+	}
+	if err := inspect(os.Stdout, args[0], bi); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// inspect writes to w a summary of the debug info of executable path,
+// followed by the lists selected by the command line flags.
+func inspect(w io.Writer, path string, bi *proc.BinaryInfo) error {
+	cus := bi.CompileUnits()
+	gocus, optimized := 0, 0
+	for _, cu := range cus {
+		if cu.IsGo {
+			gocus++
+			if cu.Optimized {
+				optimized++
+			}
+		}
+	}
+	types, err := bi.Types()
+	if err != nil {
+		return err
+	}
+	pkgs := bi.ListPackagesBuildInfo(false)
+	version, flags := splitProducer(bi.Producer())
+
+	if flags == "" {
+		flags = "none recorded"
+	}
+
+	fmt.Fprintf(w, "Executable:     %s\n", path)
+	fmt.Fprintf(w, "Architecture:   %s/%s\n", bi.GOOS, bi.Arch.Name)
+	fmt.Fprintf(w, "Go version:     %s\n", version)
+	fmt.Fprintf(w, "Compiler flags: %s\n", flags)
+	fmt.Fprintf(w, "Optimized:      %d of %d Go compile units\n", optimized, gocus)
+	fmt.Fprintf(w, "Compile units:  %d (%d Go)\n", len(cus), gocus)
+	fmt.Fprintf(w, "Packages:       %d\n", len(pkgs))
+	fmt.Fprintf(w, "Functions:      %d\n", len(bi.Functions))
+	fmt.Fprintf(w, "Types:          %d\n", len(types))
+
+	if inspectCompileUnits {
+		fmt.Fprintf(w, "\nCompile units:\n")
+		for _, cu := range cus {
+			opt := ""
+			if cu.Optimized {
+				opt = " (optimized)"
+			}
+			fmt.Fprintf(w, "\t%s\t%s%s\n", cu.Name, cu.Producer, opt)
+		}
+	}
+	if inspectPackages {
+		fmt.Fprintf(w, "\nPackages:\n")
+		for _, pkg := range pkgs {
+			fmt.Fprintf(w, "\t%s\t%s\n", pkg.ImportPath, pkg.DirectoryPath)
+		}
+	}
+	if inspectFunctions {
+		fmt.Fprintf(w, "\nFunctions:\n")
+		names := make([]string, 0, len(bi.Functions))
+		for i := range bi.Functions {
+			names = append(names, bi.Functions[i].Name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(w, "\t%s\n", name)
+		}
+	}
+	if inspectTypes {
+		fmt.Fprintf(w, "\nTypes:\n")
+		sort.Strings(types)
+		for _, typ := range types {
+			fmt.Fprintf(w, "\t%s\n", typ)
+		}
+	}
+	return nil
+}
+
+// splitProducer splits the producer attribute of the Go compile units,
+// for example "Go cmd/compile go1.12; -N -l", into the version of the
+// compiler and the flags recorded with it, recent versions of the compiler
+// only record some experiments, like regabi.
+func splitProducer(producer string) (version, flags string) {
+	if producer == "" {
+		return "unknown", ""
+	}
+	fields := strings.Split(producer, ";")
+	version = strings.TrimSpace(strings.TrimPrefix(fields[0], "Go cmd/compile "))
+	for i := range fields[1:] {
+		fields[i+1] = strings.TrimSpace(fields[i+1])
+	}
+	return version, strings.Join(fields[1:], " ")
+}
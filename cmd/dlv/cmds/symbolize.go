@@ -0,0 +1,95 @@
+package cmds
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+
+	"github.com/go-delve/delve/pkg/proc"
+	"github.com/spf13/cobra"
+)
+
+// symbolizeAddrRegex matches the addresses that symbolize annotates, the
+// offsets of the frames of a goroutine stack trace ("+0x1d") are excluded.
+var symbolizeAddrRegex = regexp.MustCompile(`(^|[^+0-9A-Za-z_])(0x[0-9a-fA-F]+)\b`)
+
+func symbolizeCmd(cmd *cobra.Command, args []string) {
+	os.Exit(symbolizeMain(args[0], args[1:]))
+}
+
+func symbolizeMain(exe string, traces []string) int {
+	goos, goarch, err := proc.ExecutablePlatform(exe)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not load %s: %v\n", exe, err)
+		return 1
+	}
+	bi := proc.NewBinaryInfo(goos, goarch)
+	if err := bi.LoadBinaryInfo(exe, 0, conf.DebugInfoDirectories); err != nil {
+		fmt.Fprintf(os.Stderr, "could not load %s: %v\n", exe, err)
+		return 1
+	}
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+	if len(traces) == 0 {
+		if err := symbolize(bi, os.Stdin, out); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return 0
+	}
+	for _, path := range traces {
+		fh, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		err = symbolize(bi, fh, out)
+		fh.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			return 1
+		}
+	}
+	return 0
+}
+
+// symbolize copies in to out, following every address of a function of bi
+// with the name of the function and its source position.
+func symbolize(bi *proc.BinaryInfo, in io.Reader, out io.Writer) error {
+	scan := bufio.NewScanner(in)
+	scan.Buffer(nil, 1024*1024)
+	for scan.Scan() {
+		line := symbolizeAddrRegex.ReplaceAllStringFunc(scan.Text(), func(m string) string {
+			sm := symbolizeAddrRegex.FindStringSubmatch(m)
+			pc, err := strconv.ParseUint(sm[2][2:], 16, 64)
+			if err != nil {
+				return m
+			}
+			if loc := symbolizePC(bi, pc); loc != "" {
+				return m + " " + loc
+			}
+			return m
+		})
+		if _, err := fmt.Fprintln(out, line); err != nil {
+			return err
+		}
+	}
+	return scan.Err()
+}
+
+// symbolizePC returns a description of the source position of pc, or the
+// empty string if it isn't the address of a function.
+func symbolizePC(bi *proc.BinaryInfo, pc uint64) string {
+	if bi.PCToFunc(pc) == nil {
+		return ""
+	}
+	file, line, _ := bi.PCToLine(pc)
+	fn := bi.PCToInlineFunc(pc)
+	if fn == nil || file == "" {
+		return ""
+	}
+	return fmt.Sprintf("in %s at %s:%d", fn.Name, file, line)
+}
@@ -19,6 +19,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/go-delve/delve/pkg/proc"
 	protest "github.com/go-delve/delve/pkg/proc/test"
 	"github.com/go-delve/delve/pkg/terminal"
 	"github.com/go-delve/delve/service/dap/daptest"
@@ -781,3 +782,93 @@ func TestDlvTestChdir(t *testing.T) {
 		t.Errorf("output did not contain expected string %q", tgt)
 	}
 }
+
+func TestBatch(t *testing.T) {
+	dlvbin, tmpdir := getDlvBin(t)
+	defer os.RemoveAll(tmpdir)
+
+	fixtures := protest.FindFixturesDir()
+	buildtest := filepath.Join(tmpdir, "buildtest")
+
+	run := func(input string, args ...string) (string, error) {
+		cmd := exec.Command(dlvbin, append(args, "exec", buildtest)...)
+		cmd.Stdin = strings.NewReader(input)
+		out, err := cmd.CombinedOutput()
+		t.Logf("output: %q", out)
+		return string(out), err
+	}
+
+	if out, err := exec.Command("go", "build", "-gcflags=all=-N -l", "-o", buildtest, filepath.Join(fixtures, "buildtest", "main.go")).CombinedOutput(); err != nil {
+		t.Fatalf("go build: %v\n%s", err, out)
+	}
+
+	// Batch mode is enabled automatically when stdin is not a terminal.
+	for _, args := range [][]string{{"--batch"}, nil} {
+		out, err := run("break main.main\n\ncontinue\nprint 1+2\n", args...)
+		if err != nil {
+			t.Errorf("error executing Delve %v: %v", args, err)
+		}
+		if strings.Contains(out, "(dlv)") || strings.Contains(out, "Type 'help'") {
+			t.Errorf("prompt printed in batch mode %v", args)
+		}
+		if !strings.Contains(out, "3\n") {
+			t.Errorf("output of print command missing %v", args)
+		}
+	}
+
+	// A failed command must be reflected in the exit status.
+	if _, err := run("print nonexistent\n", "--batch"); err == nil {
+		t.Errorf("expected non-zero exit status")
+	}
+}
+
+func TestSymbolize(t *testing.T) {
+	dlvbin, tmpdir := getDlvBin(t)
+	defer os.RemoveAll(tmpdir)
+
+	fixture := protest.BuildFixture("testnextprog", 0)
+	bi := proc.NewBinaryInfo(runtime.GOOS, runtime.GOARCH)
+	assertNoError(bi.LoadBinaryInfo(fixture.Path, 0, nil), t, "LoadBinaryInfo")
+	fn := bi.LookupFunc["main.main"]
+	if fn == nil {
+		t.Fatal("could not find main.main")
+	}
+	file, line, _ := bi.PCToLine(fn.Entry)
+
+	cmd := exec.Command(dlvbin, "symbolize", fixture.Path)
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("PC=%#x m=0\n\tmain.go:1 +%#x\nsp=0x0\n", fn.Entry, fn.Entry))
+	out, err := cmd.Output()
+	assertNoError(err, t, "dlv symbolize")
+
+	tgt := fmt.Sprintf("PC=%#x in main.main at %s:%d m=0\n\tmain.go:1 +%#x\nsp=0x0\n", fn.Entry, file, line, fn.Entry)
+	if string(out) != tgt {
+		t.Errorf("got:\n%s\nexpected:\n%s", out, tgt)
+	}
+}
+
+func TestInspect(t *testing.T) {
+	dlvbin, tmpdir := getDlvBin(t)
+	defer os.RemoveAll(tmpdir)
+
+	fixture := protest.BuildFixture("testnextprog", 0)
+	out, err := exec.Command(dlvbin, "inspect", "--functions", "--compile-units", fixture.Path).Output()
+	assertNoError(err, t, "dlv inspect")
+	t.Logf("output: %s", out)
+
+	for _, tgt := range []string{
+		"Executable:     " + fixture.Path + "\n",
+		"Go version:     go1.",
+		"\n\tmain\tGo cmd/compile ",
+		"\n\tmain.main\n",
+	} {
+		if !strings.Contains(string(out), tgt) {
+			t.Errorf("output does not contain %q", tgt)
+		}
+	}
+	// the fixture is built with optimizations disabled
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.HasPrefix(line, "\tmain\t") && strings.HasSuffix(line, "(optimized)") {
+			t.Errorf("main package reported as optimized")
+		}
+	}
+}
@@ -29,12 +29,24 @@ type SubstitutePathRule struct {
 // SubstitutePathRules is a slice of source code path substitution rules.
 type SubstitutePathRules []SubstitutePathRule
 
+// CommandHooks are the commands executed automatically before and after a
+// command.
+type CommandHooks struct {
+	// Pre are executed before the command.
+	Pre []string `yaml:"pre,omitempty"`
+	// Post are executed after the command, if it succeeds.
+	Post []string `yaml:"post,omitempty"`
+}
+
 // Config defines all configuration options available to be set through the config file.
 type Config struct {
 	// Commands aliases.
 	Aliases map[string][]string `yaml:"aliases"`
 	// Source code path substitution rules.
 	SubstitutePath SubstitutePathRules `yaml:"substitute-path"`
+	// Hooks are the commands executed before and after a command, indexed
+	// by the name of the command.
+	Hooks map[string]CommandHooks `yaml:"hooks,omitempty"`
 
 	// MaxStringLen is the maximum string length that the commands print,
 	// locals, args and vars should read (in verbose mode).
@@ -77,6 +89,19 @@ type Config struct {
 	// called (i.e. when execution stops, listCommand is used, etc)
 	SourceListLineCount *int `yaml:"source-list-line-count,omitempty"`
 
+	// HistorySize is the maximum number of commands saved in the history
+	// file (default and maximum: 1000).
+	HistorySize *int `yaml:"history-size,omitempty"`
+	// GlobalHistory, if set, makes all sessions share the same history file
+	// instead of using one history file for each working directory.
+	GlobalHistory bool `yaml:"global-history"`
+
+	// DormantBreakpoints, if set, makes the terminal save the breakpoints
+	// when it detaches from a process it attached to, leaving it running,
+	// and restore them the next time it attaches to a process running the
+	// same executable.
+	DormantBreakpoints bool `yaml:"dormant-breakpoints"`
+
 	// DebugFileDirectories is the list of directories Delve will use
 	// in order to resolve external debug info files.
 	DebugInfoDirectories []string `yaml:"debug-info-directories"`
@@ -249,6 +274,11 @@ func writeDefaultConfig(f *os.File) error {
 aliases:
   # command: ["alias1", "alias2"]
 
+# Commands executed automatically before (pre) or after (post) a command, the
+# post commands are only executed if the command succeeds.
+hooks:
+  # next: {post: ["locals"]}
+
 # Define sources path substitution rules. Can be used to rewrite a source path stored
 # in program's debug information, if the sources were moved to a different place
 # between compilation and debugging.
@@ -272,6 +302,16 @@ substitute-path:
 # Allow user to specify output syntax flavor of assembly, one of this list "intel"(default), "gnu", "go".
 # disassemble-flavor: intel
 
+# Maximum number of commands saved in the history file.
+# history-size: 1000
+
+# Uncomment to share the command history between all working directories.
+# global-history: true
+
+# Uncomment to save the breakpoints when detaching from a process and restore
+# them the next time Delve attaches to a process running the same executable.
+# dormant-breakpoints: true
+
 # List of directories to use when searching for separate debug info files.
 debug-info-directories: ["/usr/lib/debug/.build-id"]
 `)
@@ -0,0 +1,186 @@
+// Package dlvtest helps writing integration tests for tools built on top of
+// Delve.
+//
+// It can build test programs with the flags needed to debug them and find
+// the Go toolchains installed on the machine, so that tests can be repeated
+// with multiple versions of Go. Package headless can be used to start
+// headless instances of Delve debugging them.
+package dlvtest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-delve/delve/pkg/goversion"
+)
+
+// Fixture is a test binary.
+type Fixture struct {
+	// Name is the short name of the fixture.
+	Name string
+	// Path is the absolute path to the test binary.
+	Path string
+	// Source is the absolute path of the test binary source.
+	Source string
+	// BuildDir is the directory where the build command was run.
+	BuildDir string
+}
+
+// BuildOptions describes how to build a fixture.
+type BuildOptions struct {
+	// Dir is the directory where the build command is run.
+	Dir string
+	// Source is the file or package to build, relative to Dir. If empty the
+	// package in Dir is built.
+	Source string
+	// Output is the path of the executable, if empty a temporary file is
+	// used.
+	Output string
+	// Optimized keeps optimizations and inlining enabled, by default they
+	// are disabled with '-N -l'.
+	Optimized bool
+	// GCFlags are additional flags passed to the compiler.
+	GCFlags []string
+	// AllPackages applies the compiler flags to all packages instead of
+	// only the packages being built.
+	AllPackages bool
+	// Tags are the build tags.
+	Tags []string
+	// Flags are additional flags passed to 'go build'.
+	Flags []string
+	// Go is the path of the go command, if empty the one in PATH is used.
+	Go string
+	// Env, if not nil, is the environment of the build command.
+	Env []string
+}
+
+// Build compiles a fixture.
+func Build(opts BuildOptions) (Fixture, error) {
+	dir := opts.Dir
+	if dir == "" {
+		dir = "."
+	}
+	name := strings.TrimSuffix(filepath.Base(opts.Source), ".go")
+	if opts.Source == "" {
+		absdir, _ := filepath.Abs(dir)
+		name = filepath.Base(absdir)
+	}
+	output := opts.Output
+	if output == "" {
+		// Make a (good enough) random temporary file name
+		r := make([]byte, 4)
+		rand.Read(r)
+		output = filepath.Join(os.TempDir(), fmt.Sprintf("%s.%s", name, hex.EncodeToString(r)))
+	}
+	var gcflagsv []string
+	if !opts.Optimized {
+		gcflagsv = append(gcflagsv, "-N", "-l")
+	}
+	gcflagsv = append(gcflagsv, opts.GCFlags...)
+	gcflags := "-gcflags=" + strings.Join(gcflagsv, " ")
+	if opts.AllPackages {
+		gcflags = "-gcflags=all=" + strings.Join(gcflagsv, " ")
+	}
+
+	args := []string{"build"}
+	args = append(args, opts.Flags...)
+	args = append(args, gcflags, "-o", output)
+	if len(opts.Tags) > 0 {
+		args = append(args, "-tags="+strings.Join(opts.Tags, " "))
+	}
+	if opts.Source != "" {
+		args = append(args, opts.Source)
+	}
+
+	gocmd := opts.Go
+	if gocmd == "" {
+		gocmd = "go"
+	}
+	cmd := exec.Command(gocmd, args...)
+	cmd.Dir = dir
+	cmd.Env = opts.Env
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return Fixture{}, fmt.Errorf("error compiling %s: %v\n%s", filepath.Join(dir, opts.Source), err, out)
+	}
+
+	source, _ := filepath.Abs(filepath.Join(dir, opts.Source))
+	source = filepath.ToSlash(source)
+	sympath, err := filepath.EvalSymlinks(source)
+	if err == nil {
+		source = strings.Replace(sympath, "\\", "/", -1)
+	}
+
+	absdir, _ := filepath.Abs(dir)
+
+	return Fixture{Name: name, Path: output, Source: source, BuildDir: absdir}, nil
+}
+
+// Toolchain is a Go toolchain installed on this machine.
+type Toolchain struct {
+	// Path is the path of the go command.
+	Path    string
+	Version goversion.GoVersion
+}
+
+// Toolchains returns the Go toolchains installed on this machine: the one
+// in PATH followed by the ones installed with golang.org/dl in
+// $HOME/sdk.
+func Toolchains() []Toolchain {
+	var r []Toolchain
+	seen := map[string]bool{}
+	add := func(path string) {
+		path, err := filepath.Abs(path)
+		if err != nil {
+			return
+		}
+		if realpath, err := filepath.EvalSymlinks(path); err == nil {
+			path = realpath
+		}
+		if seen[path] {
+			return
+		}
+		seen[path] = true
+		out, err := exec.Command(path, "version").CombinedOutput()
+		if err != nil {
+			return
+		}
+		const goVersionPrefix = "go version "
+		s := string(out)
+		if !strings.HasPrefix(s, goVersionPrefix) {
+			return
+		}
+		ver, ok := goversion.Parse(s[len(goVersionPrefix):])
+		if !ok {
+			return
+		}
+		r = append(r, Toolchain{Path: path, Version: ver})
+	}
+
+	if path, err := exec.LookPath("go"); err == nil {
+		add(path)
+	}
+	usr, err := user.Current()
+	if err != nil {
+		return r
+	}
+	home := usr.HomeDir
+	sdks, _ := ioutil.ReadDir(filepath.Join(home, "sdk"))
+	for _, sdk := range sdks {
+		if !sdk.IsDir() || !strings.HasPrefix(sdk.Name(), "go") {
+			continue
+		}
+		gocmd := "go"
+		if filepath.Separator == '\\' {
+			gocmd = "go.exe"
+		}
+		add(filepath.Join(home, "sdk", sdk.Name(), "bin", gocmd))
+	}
+	return r
+}
@@ -0,0 +1,46 @@
+package dlvtest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuild(t *testing.T) {
+	fixture, err := Build(BuildOptions{
+		Dir:     filepath.Join("..", "..", "_fixtures"),
+		Source:  "testnextprog.go",
+		GCFlags: []string{"-dwarflocationlists=true"},
+		Tags:    []string{"dlvtest"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(fixture.Path)
+	if fixture.Name != "testnextprog" {
+		t.Errorf("wrong name %q", fixture.Name)
+	}
+	if _, err := os.Stat(fixture.Path); err != nil {
+		t.Errorf("executable not found: %v", err)
+	}
+	if filepath.Base(fixture.Source) != "testnextprog.go" || !filepath.IsAbs(fixture.Source) {
+		t.Errorf("wrong source %q", fixture.Source)
+	}
+
+	if _, err := Build(BuildOptions{Dir: filepath.Join("..", "..", "_fixtures"), Source: "nonexistent.go"}); err == nil {
+		t.Error("expected error building a nonexistent file")
+	}
+}
+
+func TestToolchains(t *testing.T) {
+	toolchains := Toolchains()
+	if len(toolchains) == 0 {
+		t.Fatal("no toolchains found")
+	}
+	for _, tc := range toolchains {
+		t.Logf("%s %#v", tc.Path, tc.Version)
+	}
+	if !toolchains[0].Version.IsDevel() && toolchains[0].Version.Major == 0 {
+		t.Errorf("could not parse version of %s", toolchains[0].Path)
+	}
+}
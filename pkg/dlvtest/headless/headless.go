@@ -0,0 +1,77 @@
+// Package headless starts headless instances of Delve for integration
+// tests.
+package headless
+
+import (
+	"errors"
+	"net"
+
+	"github.com/go-delve/delve/pkg/dlvtest"
+	"github.com/go-delve/delve/service"
+	"github.com/go-delve/delve/service/debugger"
+	"github.com/go-delve/delve/service/rpc2"
+	"github.com/go-delve/delve/service/rpccommon"
+)
+
+// Server is a headless instance of Delve.
+type Server struct {
+	// Client is connected to the server.
+	Client *rpc2.RPCClient
+
+	listener net.Listener
+	server   *rpccommon.ServerImpl
+}
+
+// ServerOptions describes how to start a Server.
+type ServerOptions struct {
+	// Args are the arguments passed to the fixture.
+	Args []string
+	// Backend is the backend used to debug the fixture, if empty the
+	// default backend is used.
+	Backend string
+	// WorkingDir is the working directory of the fixture.
+	WorkingDir string
+}
+
+// StartServer starts a headless instance of Delve debugging fixture and
+// connects a client to it.
+func StartServer(fixture dlvtest.Fixture, opts ServerOptions) (*Server, error) {
+	if fixture.Path == "" {
+		return nil, errors.New("fixture was not built")
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	backend := opts.Backend
+	if backend == "" {
+		backend = "default"
+	}
+	server := rpccommon.NewServer(&service.Config{
+		Listener:    listener,
+		ProcessArgs: append([]string{fixture.Path}, opts.Args...),
+		APIVersion:  2,
+		Debugger: debugger.Config{
+			WorkingDir:  opts.WorkingDir,
+			Backend:     backend,
+			ExecuteKind: debugger.ExecutingExistingFile,
+		},
+	})
+	if err := server.Run(); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return &Server{Client: rpc2.NewClient(listener.Addr().String()), listener: listener, server: server}, nil
+}
+
+// Addr returns the address the server is listening on.
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Stop kills the target process and stops the server.
+func (s *Server) Stop() error {
+	err := s.Client.Detach(true)
+	s.server.Stop()
+	return err
+}
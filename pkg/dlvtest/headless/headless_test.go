@@ -0,0 +1,35 @@
+package headless
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-delve/delve/pkg/dlvtest"
+	"github.com/go-delve/delve/service/api"
+)
+
+func TestStartServer(t *testing.T) {
+	fixture, err := dlvtest.Build(dlvtest.BuildOptions{Dir: filepath.Join("..", "..", "..", "_fixtures"), Source: "testnextprog.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(fixture.Path)
+
+	server, err := StartServer(fixture, ServerOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	if _, err := server.Client.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.main"}); err != nil {
+		t.Fatal(err)
+	}
+	state := <-server.Client.Continue()
+	if state.Err != nil {
+		t.Fatal(state.Err)
+	}
+	if fn := state.CurrentThread.Function; fn == nil || fn.Name() != "main.main" {
+		t.Fatalf("wrong stop location %#v", state.CurrentThread)
+	}
+}
@@ -27,6 +27,7 @@ const (
 	AttrGoEmbeddedField dwarf.Attr = 0x2903
 	AttrGoRuntimeType   dwarf.Attr = 0x2904
 	AttrGoPackageName   dwarf.Attr = 0x2905
+	AttrGoDictIndex     dwarf.Attr = 0x2906
 )
 
 // Basic type encodings -- the value for AttrEncoding in a TagBaseType Entry.
@@ -471,6 +472,15 @@ func (t *TypedefType) sizeAlignIntl(recCheck recCheck) (int64, int64) {
 	return t.Type.sizeAlignIntl(recCheck)
 }
 
+// A ParametricType represents a type parameter of a generic function
+// instantiation. Type is the shape type the compiler used for the
+// instantiation, the concrete type is stored in the dictionary of the
+// instantiation at index DictIndex.
+type ParametricType struct {
+	TypedefType
+	DictIndex int64
+}
+
 // A MapType represents a Go map type. It looks like a TypedefType, describing
 // the runtime-internal structure, with extra fields.
 type MapType struct {
@@ -1010,7 +1020,14 @@ func readType(d *dwarf.Data, name string, r *dwarf.Reader, off dwarf.Offset, typ
 			typeCache[off] = it
 			t = &it.TypedefType
 		default:
-			typ = t
+			if dictIndex, ok := e.Val(AttrGoDictIndex).(int64); ok {
+				pt := new(ParametricType)
+				pt.DictIndex = dictIndex
+				typ = pt
+				t = &pt.TypedefType
+			} else {
+				typ = t
+			}
 		}
 		typeCache[off] = typ
 		t.Name, _ = e.Val(dwarf.AttrName).(string)
@@ -1055,6 +1072,8 @@ func readType(d *dwarf.Data, name string, r *dwarf.Reader, off dwarf.Offset, typ
 				*delayedSizes = append(*delayedSizes, delayedSize{typ.Common(), t.Type})
 			case *InterfaceType:
 				*delayedSizes = append(*delayedSizes, delayedSize{typ.Common(), t.Type})
+			case *ParametricType:
+				*delayedSizes = append(*delayedSizes, delayedSize{typ.Common(), t.Type})
 			case *PtrType:
 				b = int64(addressSize)
 			case *FuncType:
@@ -151,6 +151,44 @@ func (lineInfo *DebugLineInfo) AllPCsForFileLines(f string, m map[int][]uint64)
 	return
 }
 
+// LineColumn is a statement of the line table and the column where it
+// starts.
+type LineColumn struct {
+	PC     uint64
+	Column int
+}
+
+// ColumnsForFileLine returns, in order of address, the statements of the
+// line table corresponding to file f and line lineno, along with the
+// column where each one of them starts. Producers that do not emit column
+// information will report column 0 for every entry.
+func (lineInfo *DebugLineInfo) ColumnsForFileLine(f string, lineno int) []LineColumn {
+	if lineInfo == nil {
+		return nil
+	}
+
+	var (
+		r  []LineColumn
+		sm = newStateMachine(lineInfo, lineInfo.Instructions, lineInfo.ptrSize)
+	)
+
+	for {
+		if err := sm.next(); err != nil {
+			if lineInfo.Logf != nil {
+				lineInfo.Logf("ColumnsForFileLine error: %v", err)
+			}
+			break
+		}
+		if sm.isStmt && sm.valid && sm.file == f && sm.line == lineno {
+			if len(r) > 0 && r[len(r)-1].PC == sm.address {
+				continue
+			}
+			r = append(r, LineColumn{PC: sm.address, Column: int(sm.column)})
+		}
+	}
+	return r
+}
+
 var NoSourceError = errors.New("no source available")
 
 // AllPCsBetween returns all PC addresses between begin and end (including both begin and end)
@@ -196,7 +234,13 @@ func (sm *StateMachine) copy() *StateMachine {
 	return &r
 }
 
-func (lineInfo *DebugLineInfo) stateMachineForEntry(basePC uint64) (sm *StateMachine) {
+func (lineInfo *DebugLineInfo) stateMachineForEntry(basePC uint64) *StateMachine {
+	lineInfo.mu.Lock()
+	defer lineInfo.mu.Unlock()
+	return lineInfo.stateMachineForEntryLocked(basePC)
+}
+
+func (lineInfo *DebugLineInfo) stateMachineForEntryLocked(basePC uint64) (sm *StateMachine) {
 	sm = lineInfo.stateMachineCache[basePC]
 	if sm == nil {
 		sm = newStateMachine(lineInfo, lineInfo.Instructions, lineInfo.ptrSize)
@@ -220,12 +264,16 @@ func (lineInfo *DebugLineInfo) PCToLine(basePC, pc uint64) (string, int) {
 		panic(fmt.Errorf("basePC after pc %#x %#x", basePC, pc))
 	}
 
+	lineInfo.mu.Lock()
+	defer lineInfo.mu.Unlock()
 	sm := lineInfo.stateMachineFor(basePC, pc)
 
 	file, line, _ := sm.PCToLine(pc)
 	return file, line
 }
 
+// stateMachineFor returns a state machine that can be used to search pc,
+// lineInfo.mu must be held until the caller is done using it.
 func (lineInfo *DebugLineInfo) stateMachineFor(basePC, pc uint64) *StateMachine {
 	var sm *StateMachine
 	if basePC == 0 {
@@ -237,7 +285,7 @@ func (lineInfo *DebugLineInfo) stateMachineFor(basePC, pc uint64) *StateMachine
 		// As a last resort start from the start of the debug_line section.
 		sm = lineInfo.lastMachineCache[basePC]
 		if sm == nil || sm.lastAddress >= pc {
-			sm = lineInfo.stateMachineForEntry(basePC)
+			sm = lineInfo.stateMachineForEntryLocked(basePC)
 			lineInfo.lastMachineCache[basePC] = sm
 		}
 	}
@@ -321,6 +369,8 @@ func (lineInfo *DebugLineInfo) LineToPCIn(filename string, lineno int, basePC, s
 		panic(fmt.Errorf("basePC after startPC %#x %#x", basePC, startPC))
 	}
 
+	lineInfo.mu.Lock()
+	defer lineInfo.mu.Unlock()
 	sm := lineInfo.stateMachineFor(basePC, startPC)
 
 	var fallbackPC uint64
@@ -375,6 +425,50 @@ func (lineInfo *DebugLineInfo) PrologueEndPC(start, end uint64) (pc uint64, file
 	}
 }
 
+// StmtAtOrAfter returns pc if it belongs to a row marked as stmt, otherwise
+// it returns the first address in the half open interval (pc, end) marked as
+// stmt and belonging to the same line as pc. The search stops, returning
+// false, as soon as a different line is reached.
+// basePC is the entry point of the function containing pc.
+func (lineInfo *DebugLineInfo) StmtAtOrAfter(basePC, pc, end uint64) (uint64, bool) {
+	if lineInfo == nil {
+		return 0, false
+	}
+	sm := lineInfo.stateMachineForEntry(basePC)
+	var file string
+	var line int
+	stmt, covered := false, false
+	for {
+		if sm.valid {
+			if sm.address >= end {
+				return 0, false
+			}
+			if sm.address <= pc {
+				file, line, stmt, covered = sm.file, sm.line, sm.isStmt, true
+			} else {
+				if !covered {
+					return 0, false
+				}
+				if stmt {
+					return pc, true
+				}
+				if sm.file != file || sm.line != line {
+					return 0, false
+				}
+				if sm.isStmt {
+					return sm.address, true
+				}
+			}
+		}
+		if err := sm.next(); err != nil {
+			if lineInfo.Logf != nil {
+				lineInfo.Logf("StmtAtOrAfter error: %v", err)
+			}
+			return 0, false
+		}
+	}
+}
+
 // FirstStmtForLine looks in the half open interval [start, end) for the
 // first PC address marked as stmt for the line at address 'start'.
 func (lineInfo *DebugLineInfo) FirstStmtForLine(start, end uint64) (pc uint64, file string, line int, ok bool) {
@@ -264,3 +264,137 @@ func TestMultipleSequences(t *testing.T) {
 		}
 	}
 }
+
+func TestStmtAtOrAfter(t *testing.T) {
+	instr := bytes.NewBuffer(nil)
+	ptrSize := ptrSizeByRuntimeArch()
+
+	instr.WriteByte(0)
+	util.EncodeULEB128(instr, 9) // 1 + ptr_size
+	instr.WriteByte(DW_LINE_set_address)
+	util.WriteUint(instr, binary.LittleEndian, ptrSize, 0x400000)
+
+	row := func(pcoff uint64, lineoff int64, negateStmt bool) {
+		instr.WriteByte(DW_LNS_advance_pc)
+		util.EncodeULEB128(instr, pcoff)
+		instr.WriteByte(DW_LNS_advance_line)
+		util.EncodeSLEB128(instr, lineoff)
+		if negateStmt {
+			instr.WriteByte(DW_LNS_negate_stmt)
+		}
+		instr.WriteByte(DW_LNS_copy)
+	}
+
+	row(0, 0, false) // thefile.go:1 0x400000 stmt
+	row(2, 1, true)  // thefile.go:2 0x400002
+	row(2, 0, true)  // thefile.go:2 0x400004 stmt
+	row(2, 1, true)  // thefile.go:3 0x400006
+	row(2, 1, true)  // thefile.go:4 0x400008 stmt
+	instr.WriteByte(DW_LNS_advance_pc)
+	util.EncodeULEB128(instr, 2)
+	instr.WriteByte(0)
+	util.EncodeULEB128(instr, 1)
+	instr.WriteByte(DW_LINE_end_sequence)
+
+	lines := &DebugLineInfo{
+		Prologue: &DebugLinePrologue{
+			UnitLength:     1,
+			Version:        2,
+			MinInstrLength: 1,
+			InitialIsStmt:  1,
+			LineBase:       -3,
+			LineRange:      12,
+			OpcodeBase:     13,
+			StdOpLengths:   []uint8{0, 1, 1, 1, 1, 0, 0, 0, 1, 0, 0, 1},
+		},
+		IncludeDirs:       []string{},
+		FileNames:         []*FileEntry{&FileEntry{Path: "thefile.go"}},
+		Instructions:      instr.Bytes(),
+		ptrSize:           ptrSize,
+		stateMachineCache: make(map[uint64]*StateMachine),
+	}
+
+	for _, tc := range []struct {
+		pc, end uint64
+		tgt     uint64
+		ok      bool
+	}{
+		{0x400000, 0x40000a, 0x400000, true},
+		{0x400003, 0x40000a, 0x400004, true}, // moves to the stmt row of the same line
+		{0x400005, 0x40000a, 0x400005, true},
+		{0x400006, 0x40000a, 0, false}, // the next stmt row is on a different line
+		{0x400003, 0x400004, 0, false}, // past end
+	} {
+		pc, ok := lines.StmtAtOrAfter(0x400000, tc.pc, tc.end)
+		if pc != tc.tgt || ok != tc.ok {
+			t.Errorf("StmtAtOrAfter(%#x, %#x): got %#x %v expected %#x %v", tc.pc, tc.end, pc, ok, tc.tgt, tc.ok)
+		}
+	}
+}
+
+func TestColumnsForFileLine(t *testing.T) {
+	instr := bytes.NewBuffer(nil)
+	ptrSize := ptrSizeByRuntimeArch()
+
+	instr.WriteByte(0)
+	util.EncodeULEB128(instr, 9) // 1 + ptr_size
+	instr.WriteByte(DW_LINE_set_address)
+	util.WriteUint(instr, binary.LittleEndian, ptrSize, 0x400000)
+
+	row := func(pcoff uint64, lineoff int64, col uint64, negateStmt bool) {
+		instr.WriteByte(DW_LNS_advance_pc)
+		util.EncodeULEB128(instr, pcoff)
+		instr.WriteByte(DW_LNS_advance_line)
+		util.EncodeSLEB128(instr, lineoff)
+		instr.WriteByte(DW_LNS_set_column)
+		util.EncodeULEB128(instr, col)
+		if negateStmt {
+			instr.WriteByte(DW_LNS_negate_stmt)
+		}
+		instr.WriteByte(DW_LNS_copy)
+	}
+
+	row(0, 0, 1, false)   // thefile.go:1:1 0x400000 stmt
+	row(2, 1, 2, false)   // thefile.go:2:2 0x400002 stmt
+	row(2, 0, 9, true)    // thefile.go:2:9 0x400004
+	row(2, 0, 9, true)    // thefile.go:2:9 0x400006 stmt
+	row(2, 1, 2, false)   // thefile.go:3:2 0x400008 stmt
+	row(2, -1, 20, false) // thefile.go:2:20 0x40000a stmt
+	instr.WriteByte(DW_LNS_advance_pc)
+	util.EncodeULEB128(instr, 2)
+	instr.WriteByte(0)
+	util.EncodeULEB128(instr, 1)
+	instr.WriteByte(DW_LINE_end_sequence)
+
+	lines := &DebugLineInfo{
+		Prologue: &DebugLinePrologue{
+			UnitLength:     1,
+			Version:        2,
+			MinInstrLength: 1,
+			InitialIsStmt:  1,
+			LineBase:       -3,
+			LineRange:      12,
+			OpcodeBase:     13,
+			StdOpLengths:   []uint8{0, 1, 1, 1, 1, 0, 0, 0, 1, 0, 0, 1},
+		},
+		IncludeDirs:       []string{},
+		FileNames:         []*FileEntry{&FileEntry{Path: "thefile.go"}},
+		Instructions:      instr.Bytes(),
+		ptrSize:           ptrSize,
+		stateMachineCache: make(map[uint64]*StateMachine),
+	}
+
+	got := lines.ColumnsForFileLine("thefile.go", 2)
+	expected := []LineColumn{{0x400002, 2}, {0x400006, 9}, {0x40000a, 20}}
+	if len(got) != len(expected) {
+		t.Fatalf("ColumnsForFileLine: got %#v expected %#v", got, expected)
+	}
+	for i := range got {
+		if got[i] != expected[i] {
+			t.Errorf("ColumnsForFileLine: got %#v expected %#v", got, expected)
+		}
+	}
+	if got := lines.ColumnsForFileLine("thefile.go", 4); len(got) != 0 {
+		t.Errorf("ColumnsForFileLine on a missing line: got %#v", got)
+	}
+}
@@ -70,7 +70,10 @@ func ExecuteStackProgram(regs DwarfRegisters, instructions []byte, ptrSize int)
 		}
 		fn, ok := oplut[opcode]
 		if !ok {
-			return 0, nil, fmt.Errorf("invalid instruction %#v", opcode)
+			if name, hasname := opcodeName[opcode]; hasname {
+				return 0, nil, fmt.Errorf("unsupported location expression: %s not implemented", name)
+			}
+			return 0, nil, fmt.Errorf("unsupported location expression: invalid instruction %#x", byte(opcode))
 		}
 
 		err = fn(opcode, ctxt)
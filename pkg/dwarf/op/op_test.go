@@ -23,3 +23,18 @@ func TestExecuteStackProgram(t *testing.T) {
 		t.Fatalf("actual %d != expected %d", actual, expected)
 	}
 }
+
+func TestExecuteStackProgramUnsupported(t *testing.T) {
+	for _, tc := range []struct {
+		instructions []byte
+		errmsg       string
+	}{
+		{[]byte{byte(DW_OP_call4), 0, 0, 0, 0}, "unsupported location expression: DW_OP_call4 not implemented"},
+		{[]byte{0xfe}, "unsupported location expression: invalid instruction 0xfe"},
+	} {
+		_, _, err := ExecuteStackProgram(DwarfRegisters{}, tc.instructions, ptrSizeByRuntimeArch())
+		if err == nil || err.Error() != tc.errmsg {
+			t.Errorf("%#v: expected error %q, got %v", tc.instructions, tc.errmsg, err)
+		}
+	}
+}
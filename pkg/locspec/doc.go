@@ -3,10 +3,12 @@
 //
 // Location spec examples:
 //
-//  locStr ::= <filename>:<line> | <function>[:<line>] | /<regex>/ | (+|-)<offset> | <line> | *<address>
+//  locStr ::= <filename>:<line>[:<column>] | <function>[:<line>] | <function>:return | /<regex>/ | (+|-)<offset> | <line> | *<address>
 //  * <filename> can be the full path of a file or just a suffix
+//  * <column> selects the statement of <line> starting closest to <column>
 //  * <function> ::= <package>.<receiver type>.<name> | <package>.(*<receiver type>).<name> | <receiver type>.<name> | <package>.<name> | (*<receiver type>).<name> | <name>
 //    <function> must be unambiguous
+//  * <function>:return returns a location for every return instruction of <function>
 //  * /<regex>/ will return a location for each function matched by regex
 //  * +<offset> returns a location for the line that is <offset> lines after the current line
 //  * -<offset> returns a location for the line that is <offset> lines before the current line
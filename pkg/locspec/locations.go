@@ -24,11 +24,17 @@ type LocationSpec interface {
 }
 
 // NormalLocationSpec represents a basic location spec.
-// This can be a file:line or func:line.
+// This can be a file:line, file:line:col, func:line or func:return.
 type NormalLocationSpec struct {
 	Base       string
 	FuncBase   *FuncLocationSpec
 	LineOffset int
+	// Return is true for func:return, which selects the return instructions
+	// of the function.
+	Return bool
+	// Column is the column of a file:line:col location, 0 selects the
+	// whole line.
+	Column int
 }
 
 // RegexLocationSpec represents a regular expression
@@ -111,6 +117,15 @@ func parseLocationSpecDefault(locStr, rest string) (LocationSpec, error) {
 	}
 
 	v := strings.Split(rest, ":")
+	column := 0
+	if len(v) > 2 && isNumber(v[len(v)-2]) && isNumber(v[len(v)-1]) {
+		// file:line:col
+		column, _ = strconv.Atoi(v[len(v)-1])
+		if column < 0 {
+			return nil, malformed("column negative")
+		}
+		v = v[:len(v)-1]
+	}
 	if len(v) > 2 {
 		// On Windows, path may contain ":", so split only on last ":"
 		v = []string{strings.Join(v[0:len(v)-1], ":"), v[len(v)-1]}
@@ -123,7 +138,7 @@ func parseLocationSpecDefault(locStr, rest string) (LocationSpec, error) {
 		}
 	}
 
-	spec := &NormalLocationSpec{}
+	spec := &NormalLocationSpec{Column: column}
 
 	spec.Base = v[0]
 	spec.FuncBase = parseFuncLocationSpec(spec.Base)
@@ -135,6 +150,15 @@ func parseLocationSpecDefault(locStr, rest string) (LocationSpec, error) {
 
 	rest = v[1]
 
+	if rest == "return" {
+		if spec.FuncBase == nil {
+			return nil, malformed("return can only be specified for functions")
+		}
+		spec.Return = true
+		spec.LineOffset = -1
+		return spec, nil
+	}
+
 	var err error
 	spec.LineOffset, err = strconv.Atoi(rest)
 	if err != nil || spec.LineOffset < 0 {
@@ -144,6 +168,11 @@ func parseLocationSpecDefault(locStr, rest string) (LocationSpec, error) {
 	return spec, nil
 }
 
+func isNumber(s string) bool {
+	_, err := strconv.Atoi(s)
+	return err == nil
+}
+
 func readRegex(in string) (rx string, rest string) {
 	out := make([]rune, 0, len(in))
 	escaped := false
@@ -369,6 +398,9 @@ func (loc *NormalLocationSpec) Find(t *proc.Target, processArgs []string, scope
 	limit := maxFindLocationCandidates
 	var candidateFiles []string
 	for _, sourceFile := range scope.BinInfo.Sources {
+		if loc.Return {
+			break
+		}
 		substFile := sourceFile
 		if len(substitutePathRules) > 0 {
 			substFile = SubstitutePath(sourceFile, substitutePathRules)
@@ -385,16 +417,26 @@ func (loc *NormalLocationSpec) Find(t *proc.Target, processArgs []string, scope
 
 	var candidateFuncs []string
 	if loc.FuncBase != nil {
+		seenGeneric := make(map[string]bool)
 		for _, f := range scope.BinInfo.Functions {
 			if !loc.FuncBase.Match(f, scope.BinInfo.PackageMap) {
 				continue
 			}
-			if loc.Base == f.Name {
+			// instantiations of a generic function are all matched by the
+			// name of the generic function
+			name := f.NameWithoutTypeParams()
+			if loc.Base == f.Name || loc.Base == name {
 				// if an exact match for the function name is found use it
-				candidateFuncs = []string{f.Name}
+				candidateFuncs = []string{loc.Base}
 				break
 			}
-			candidateFuncs = append(candidateFuncs, f.Name)
+			if name != f.Name {
+				if seenGeneric[name] {
+					continue
+				}
+				seenGeneric[name] = true
+			}
+			candidateFuncs = append(candidateFuncs, name)
 			if len(candidateFuncs) >= limit {
 				break
 			}
@@ -402,6 +444,9 @@ func (loc *NormalLocationSpec) Find(t *proc.Target, processArgs []string, scope
 	}
 
 	if matching := len(candidateFiles) + len(candidateFuncs); matching == 0 {
+		if loc.Return {
+			return nil, fmt.Errorf("location \"%s\" not found", locStr)
+		}
 		// if no result was found this locations string could be an
 		// expression that the user forgot to prefix with '*', try treating it as
 		// such.
@@ -422,12 +467,26 @@ func (loc *NormalLocationSpec) Find(t *proc.Target, processArgs []string, scope
 		if loc.LineOffset < 0 {
 			return nil, fmt.Errorf("Malformed breakpoint location, no line offset specified")
 		}
-		addrs, err = proc.FindFileLocation(t, candidateFiles[0], loc.LineOffset)
+		var column int
+		addrs, column, err = proc.FindFileColumnLocation(t, candidateFiles[0], loc.LineOffset, loc.Column)
 		if includeNonExecutableLines {
 			if _, isCouldNotFindLine := err.(*proc.ErrCouldNotFindLine); isCouldNotFindLine {
 				return []api.Location{{File: candidateFiles[0], Line: loc.LineOffset}}, nil
 			}
 		}
+		if err != nil {
+			return nil, err
+		}
+		r := addressesToLocation(addrs)
+		r.Column = column
+		return []api.Location{r}, nil
+	} else if loc.Column > 0 {
+		return nil, fmt.Errorf("Malformed breakpoint location, a column can only be specified for file:line locations")
+	} else if loc.Return { // len(candidateFuncs) == 1
+		addrs, err = proc.FindFunctionReturnLocations(t, candidateFuncs[0])
+		if err == nil && len(addrs) == 0 {
+			err = fmt.Errorf("function %s does not return", candidateFuncs[0])
+		}
 	} else { // len(candidateFuncs) == 1
 		addrs, err = proc.FindFunctionLocation(t, candidateFuncs[0], loc.LineOffset)
 	}
@@ -127,6 +127,18 @@ func WriteAPIListeningMessage(addr string) {
 	writeListeningMessage("API", addr)
 }
 
+// WriteObserverListeningMessage writes the "Observer API server listening"
+// message in headless mode.
+func WriteObserverListeningMessage(addr string) {
+	writeListeningMessage("Observer API", addr)
+}
+
+// WriteGRPCListeningMessage writes the "gRPC API server listening"
+// message in headless mode.
+func WriteGRPCListeningMessage(addr string) {
+	writeListeningMessage("gRPC API", addr)
+}
+
 func writeListeningMessage(server, addr string) {
 	msg := fmt.Sprintf("%s server listening at: %s", server, addr)
 	if logOut != nil {
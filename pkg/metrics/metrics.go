@@ -0,0 +1,93 @@
+// Package metrics collects statistics about the internal operation of
+// Delve and exposes them in the Prometheus text exposition format.
+//
+// All metrics are process wide counters and are safe for concurrent use.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// Counter is a monotonically increasing metric.
+type Counter struct {
+	v    uint64 // first field to guarantee 64bit alignment
+	name string
+	help string
+	// scale, if non-zero, is the value the counter is divided by when
+	// exposed.
+	scale float64
+}
+
+var counters []*Counter
+
+func newCounter(name, help string, scale float64) *Counter {
+	c := &Counter{name: name, help: help, scale: scale}
+	counters = append(counters, c)
+	return c
+}
+
+var (
+	// CommandsServed counts the API requests served by the headless server,
+	// JSON-RPC, gRPC and DAP requests alike.
+	CommandsServed = newCounter("delve_commands_served_total", "Number of API requests served.", 0)
+	// StopEvents counts the number of times the target stopped after being
+	// resumed.
+	StopEvents = newCounter("delve_stop_events_total", "Number of times the target stopped after being resumed.", 0)
+	// PtraceCalls counts the operations executed on the ptrace thread.
+	PtraceCalls = newCounter("delve_ptrace_calls_total", "Number of ptrace operations executed.", 0)
+	// SymbolLoadTime accumulates the time spent loading debug symbols.
+	SymbolLoadTime = newCounter("delve_symbol_load_seconds_total", "Time spent loading debug symbols, in seconds.", float64(time.Second))
+	// MemCacheHits counts the memory reads served by the memory cache.
+	MemCacheHits = newCounter("delve_memory_cache_hits_total", "Number of memory reads served by the memory cache.", 0)
+	// MemCacheMisses counts the memory reads that were not served by the
+	// memory cache.
+	MemCacheMisses = newCounter("delve_memory_cache_misses_total", "Number of memory reads not served by the memory cache.", 0)
+)
+
+// Inc increments the counter by 1.
+func (c *Counter) Inc() {
+	atomic.AddUint64(&c.v, 1)
+}
+
+// Add adds n to the counter.
+func (c *Counter) Add(n uint64) {
+	atomic.AddUint64(&c.v, n)
+}
+
+// Since adds the time elapsed since t to the counter.
+func (c *Counter) Since(t time.Time) {
+	c.Add(uint64(time.Since(t)))
+}
+
+// Value returns the current value of the counter.
+func (c *Counter) Value() uint64 {
+	return atomic.LoadUint64(&c.v)
+}
+
+// Write writes all metrics to w in the Prometheus text exposition format.
+func Write(w io.Writer) error {
+	for _, c := range counters {
+		var err error
+		if c.scale != 0 {
+			_, err = fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n%s %g\n", c.name, c.help, c.name, c.name, float64(c.Value())/c.scale)
+		} else {
+			_, err = fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n%s %d\n", c.name, c.help, c.name, c.name, c.Value())
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Handler returns an http.Handler that serves the metrics.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		Write(w)
+	})
+}
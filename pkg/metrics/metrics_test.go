@@ -0,0 +1,30 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWrite(t *testing.T) {
+	CommandsServed.Inc()
+	CommandsServed.Add(2)
+	SymbolLoadTime.Add(uint64(1500 * time.Millisecond))
+
+	var buf bytes.Buffer
+	if err := Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	t.Log(out)
+	for _, tgt := range []string{
+		"# TYPE delve_commands_served_total counter\ndelve_commands_served_total 3\n",
+		"\ndelve_symbol_load_seconds_total 1.5\n",
+		"\ndelve_memory_cache_hits_total 0\n",
+	} {
+		if !strings.Contains(out, tgt) {
+			t.Errorf("output does not contain %q", tgt)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package proc
+
+import "strings"
+
+// Activity classifies what a thread or a goroutine is doing, clients use it
+// to hide threads and goroutines that are not executing user code.
+type Activity uint8
+
+const (
+	// ActivityUser is code of the program or of its libraries.
+	ActivityUser Activity = iota
+	// ActivityRuntime is code of the runtime, other than the garbage
+	// collector and system calls.
+	ActivityRuntime
+	// ActivitySyscall is a thread or goroutine blocked in a system call.
+	ActivitySyscall
+	// ActivityGC is a thread or goroutine running (or assisting) the
+	// garbage collector.
+	ActivityGC
+)
+
+func (a Activity) String() string {
+	switch a {
+	case ActivityUser:
+		return "user"
+	case ActivityRuntime:
+		return "runtime"
+	case ActivitySyscall:
+		return "syscall"
+	case ActivityGC:
+		return "gc"
+	}
+	return "unknown"
+}
+
+// ThreadActivity classifies thread using its current location and the
+// status of the goroutine running on it. The result is cached until the
+// target resumes.
+func ThreadActivity(thread Thread) Activity {
+	common := thread.Common()
+	if !common.activityKnown {
+		common.activity = threadActivity(thread)
+		common.activityKnown = true
+	}
+	return common.activity
+}
+
+func threadActivity(thread Thread) Activity {
+	if InSyscall(thread) {
+		return ActivitySyscall
+	}
+	g, _ := GetG(thread)
+	if g != nil {
+		if fn := thread.BinInfo().PCToFunc(g.StartPC); fn != nil && isGCFunction(fn.Name) {
+			return ActivityGC
+		}
+	}
+	loc, err := thread.Location()
+	if err != nil || loc.Fn == nil {
+		return ActivityUser
+	}
+	return functionActivity(loc.Fn.Name)
+}
+
+// GoroutineActivity classifies g using its status and the function it was
+// started with. The current location is not used: goroutines of the
+// program run runtime code, including the write barrier and mark assists,
+// and a goroutine of the program waiting on a channel is still user code.
+func GoroutineActivity(tgt *Target, g *G) Activity {
+	if g.Status == Gsyscall {
+		return ActivitySyscall
+	}
+	start := g.StartLoc(tgt)
+	if start.Fn != nil && isGCFunction(start.Fn.Name) {
+		return ActivityGC
+	}
+	if start.Fn != nil && start.Fn.Name == "runtime.runfinq" && g.Status == Gwaiting {
+		// the finalizer goroutine only runs user code while it calls a
+		// finalizer
+		return ActivityRuntime
+	}
+	if g.System(tgt) {
+		return ActivityRuntime
+	}
+	return ActivityUser
+}
+
+// RuntimeGoroutine returns true if g is a goroutine of the runtime that
+// isn't executing code of the program, like the workers of the garbage
+// collector or the finalizer goroutine waiting for finalizers to run.
+// Goroutine listings hide them by default.
+func RuntimeGoroutine(tgt *Target, g *G) bool {
+	switch GoroutineActivity(tgt, g) {
+	case ActivityRuntime, ActivityGC:
+		return true
+	}
+	return false
+}
+
+// functionActivity classifies the function called name.
+func functionActivity(name string) Activity {
+	switch {
+	case isGCFunction(name):
+		return ActivityGC
+	case strings.HasPrefix(name, "runtime.") || strings.HasPrefix(name, "runtime/internal/") || strings.HasPrefix(name, "internal/runtime/"):
+		return ActivityRuntime
+	}
+	return ActivityUser
+}
+
+// isGCFunction returns true if name is a function of the garbage collector.
+// The runtime prefixes most of them with gc, the others are listed here.
+func isGCFunction(name string) bool {
+	if strings.HasPrefix(name, "runtime.gc") || strings.HasPrefix(name, "runtime.(*gcWork).") || strings.HasPrefix(name, "runtime.markroot") {
+		return true
+	}
+	switch name {
+	case "runtime.scanobject", "runtime.scanblock", "runtime.scanstack", "runtime.scanframeworker",
+		"runtime.greyobject", "runtime.shade", "runtime.bgsweep", "runtime.bgscavenge", "runtime.sweepone",
+		"runtime.wbBufFlush", "runtime.wbBufFlush1":
+		return true
+	}
+	return false
+}
@@ -1,7 +1,6 @@
 package amd64util
 
 import (
-	"errors"
 	"fmt"
 )
 
@@ -58,10 +57,15 @@ func (drs *DebugRegisters) breakpoint(idx uint8) (addr uint64, read, write bool,
 // address, read/write flags and size.
 // If the breakpoint is already in use but the parameters match it does
 // nothing.
+// The hardware can not trap only reads: a breakpoint on reads also
+// triggers on writes, telling them apart is left to the caller.
 func (drs *DebugRegisters) SetBreakpoint(idx uint8, addr uint64, read, write bool, sz int) error {
 	if int(idx) >= len(drs.pAddrs) {
 		return fmt.Errorf("hardware breakpoints exhausted")
 	}
+	if read {
+		write = true
+	}
 	curaddr, curread, curwrite, cursz := drs.breakpoint(idx)
 	if curaddr != 0 {
 		if (curaddr != addr) || (curread != read) || (curwrite != write) || (cursz != sz) {
@@ -71,16 +75,13 @@ func (drs *DebugRegisters) SetBreakpoint(idx uint8, addr uint64, read, write boo
 		return nil
 	}
 
-	if read && !write {
-		return errors.New("break on read only not supported")
-	}
-
 	*(drs.pAddrs[idx]) = addr
 	var lenrw uint64
 	if write {
 		lenrw |= 0x1
 	}
 	if read {
+		// 0x2 is an I/O breakpoint, 0x3 breaks on both reads and writes
 		lenrw |= 0x2
 	}
 	switch sz {
@@ -115,7 +116,7 @@ func (drs *DebugRegisters) ClearBreakpoint(idx uint8) {
 // GetActiveBreakpoint returns the active hardware breakpoint and resets the
 // condition flags.
 func (drs *DebugRegisters) GetActiveBreakpoint() (ok bool, idx uint8) {
-	for idx := uint8(0); idx < 3; idx++ {
+	for idx := uint8(0); idx < uint8(len(drs.pAddrs)); idx++ {
 		enable := *(drs.pDR7) & (1 << enableBitOffset(idx))
 		if enable == 0 {
 			continue
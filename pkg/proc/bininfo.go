@@ -30,6 +30,8 @@ import (
 	"github.com/go-delve/delve/pkg/dwarf/util"
 	"github.com/go-delve/delve/pkg/goversion"
 	"github.com/go-delve/delve/pkg/logflags"
+	"github.com/go-delve/delve/pkg/metrics"
+	"github.com/go-delve/delve/pkg/proc/evalexpr"
 	"github.com/hashicorp/golang-lru/simplelru"
 	"github.com/sirupsen/logrus"
 )
@@ -49,6 +51,10 @@ type BinaryInfo struct {
 	// GOOS operating system this binary is executing on.
 	GOOS string
 
+	// BuildID is the Go build ID of the executable or, if it doesn't have
+	// one, its GNU build ID. Empty if neither could be read.
+	BuildID string
+
 	debugInfoDirectories []string
 
 	// Functions is a list of all DW_TAG_subprogram entries in debug_info, sorted by entry point
@@ -57,6 +63,9 @@ type BinaryInfo struct {
 	Sources []string
 	// LookupFunc maps function names to a description of the function.
 	LookupFunc map[string]*Function
+	// lookupGenericFunc maps function names, with their type parameters
+	// removed, to the instantiations of generic functions.
+	lookupGenericFunc map[string][]*Function
 
 	// SymNames maps addr to a description *elf.Symbol of this addr.
 	SymNames map[uint64]*elf.Symbol
@@ -165,38 +174,129 @@ func FindFileLocation(p Process, fileName string, lineno int) ([]uint64, error)
 	return pcs, nil
 }
 
+// FindFileColumnLocation returns the PCs for a given file:line:col and
+// the column they were resolved to. The statement starting at col is
+// selected or, if there isn't one, the first statement of the line
+// starting after col or, failing that, the last one starting before it.
+// If the line table has no column information for the line, or col is 0,
+// it behaves like FindFileLocation and the returned column is 0.
+func FindFileColumnLocation(p Process, fileName string, lineno, col int) ([]uint64, int, error) {
+	if col <= 0 {
+		pcs, err := FindFileLocation(p, fileName, lineno)
+		return pcs, 0, err
+	}
+	stmts := p.BinInfo().columnsForFileLine(fileName, lineno)
+	resolved := 0
+	for _, stmt := range stmts {
+		switch {
+		case stmt.Column >= col:
+			if resolved < col || stmt.Column < resolved {
+				resolved = stmt.Column
+			}
+		case resolved < col && stmt.Column > resolved:
+			resolved = stmt.Column
+		}
+	}
+	if resolved == 0 {
+		pcs, err := FindFileLocation(p, fileName, lineno)
+		return pcs, 0, err
+	}
+	// Select the first statement starting at the resolved column in each
+	// function, the line can belong to more than one function if it was
+	// inlined.
+	var pcs []uint64
+	seen := make(map[*Function]bool)
+	for _, stmt := range stmts {
+		if stmt.Column != resolved {
+			continue
+		}
+		fn := p.BinInfo().PCToFunc(stmt.PC)
+		if seen[fn] {
+			continue
+		}
+		seen[fn] = true
+		pc := stmt.PC
+		if fn != nil && fn.Entry == pc {
+			pc, _ = FirstPCAfterPrologue(p, fn, true)
+		}
+		pcs = append(pcs, pc)
+	}
+	return pcs, resolved, nil
+}
+
 // FindFunctionLocation finds address of a function's line
 // If lineOffset is passed FindFunctionLocation will return the address of that line
+// If funcName is the name of a generic function the addresses of all its
+// instantiations are returned.
 func FindFunctionLocation(p Process, funcName string, lineOffset int) ([]uint64, error) {
 	bi := p.BinInfo()
-	origfn := bi.LookupFunc[funcName]
-	if origfn == nil {
+	origfns := bi.LookupGenericFunc()[funcName]
+	if origfn := bi.LookupFunc[funcName]; origfn != nil {
+		origfns = []*Function{origfn}
+	}
+	if len(origfns) == 0 {
 		return nil, &ErrFunctionNotFound{funcName}
 	}
 
 	if lineOffset <= 0 {
-		r := make([]uint64, 0, len(origfn.InlinedCalls)+1)
-		if origfn.Entry > 0 {
-			// add concrete implementation of the function
-			pc, err := FirstPCAfterPrologue(p, origfn, false)
-			if err != nil {
-				return nil, err
+		var r []uint64
+		for _, origfn := range origfns {
+			if origfn.Entry > 0 {
+				// add concrete implementation of the function
+				pc, err := FirstPCAfterPrologue(p, origfn, false)
+				if err != nil {
+					return nil, err
+				}
+				r = append(r, pc)
+			}
+			// add inlined calls to the function
+			for _, call := range origfn.InlinedCalls {
+				r = append(r, call.LowPC)
 			}
-			r = append(r, pc)
-		}
-		// add inlined calls to the function
-		for _, call := range origfn.InlinedCalls {
-			r = append(r, call.LowPC)
 		}
 		if len(r) == 0 {
 			return nil, &ErrFunctionNotFound{funcName}
 		}
 		return r, nil
 	}
+	origfn := origfns[0]
 	filename, lineno := origfn.cu.lineInfo.PCToLine(origfn.Entry, origfn.Entry)
 	return bi.LineToPC(filename, lineno+lineOffset)
 }
 
+// FindFunctionReturnLocations returns the addresses of the return
+// instructions of function funcName and of its calls to
+// runtime.deferreturn.
+func FindFunctionReturnLocations(p Process, funcName string) ([]uint64, error) {
+	bi := p.BinInfo()
+	fns := bi.LookupGenericFunc()[funcName]
+	if fn := bi.LookupFunc[funcName]; fn != nil {
+		fns = []*Function{fn}
+	}
+	var addrs []uint64
+	found := false
+	for _, fn := range fns {
+		if fn.Entry == 0 {
+			continue
+		}
+		found = true
+		text, err := Disassemble(p.Memory(), nil, p.Breakpoints(), bi, fn.Entry, fn.End)
+		if err != nil {
+			return nil, err
+		}
+		for _, instr := range text {
+			if instr.IsRet() {
+				addrs = append(addrs, instr.Loc.PC)
+			}
+		}
+		addrs = append(addrs, FindDeferReturnCalls(text)...)
+	}
+	if !found {
+		return nil, &ErrFunctionNotFound{funcName}
+	}
+	return addrs, nil
+}
+
 // FirstPCAfterPrologue returns the address of the first
 // instruction after the prologue for function fn.
 // If sameline is set FirstPCAfterPrologue will always return an
@@ -218,6 +318,16 @@ func FirstPCAfterPrologue(p Process, fn *Function, sameline bool) (uint64, error
 		return fn.Entry, err
 	}
 
+	if pc != fn.Entry {
+		// The instruction after the stack split check could be in the middle
+		// of a statement (for example the setup of the stack frame), move to
+		// the first instruction of the line with the stmt flag set, where
+		// arguments and locals are readable.
+		if pc2, ok := fn.cu.lineInfo.StmtAtOrAfter(fn.Entry, pc, fn.End); ok {
+			pc = pc2
+		}
+	}
+
 	if pc == fn.Entry {
 		// Look for the first instruction with the stmt flag set, so that setting a
 		// breakpoint with file:line and with the function name always result on
@@ -321,7 +431,7 @@ type Function struct {
 // or the empty string if there is none.
 // Borrowed from $GOROOT/debug/gosym/symtab.go
 func (fn *Function) PackageName() string {
-	return packageName(fn.Name)
+	return packageName(fn.NameWithoutTypeParams())
 }
 
 func packageName(name string) string {
@@ -340,25 +450,58 @@ func packageName(name string) string {
 // or the empty string if there is none.
 // Borrowed from $GOROOT/debug/gosym/symtab.go
 func (fn *Function) ReceiverName() string {
-	pathend := strings.LastIndex(fn.Name, "/")
+	name := fn.NameWithoutTypeParams()
+	pathend := strings.LastIndex(name, "/")
 	if pathend < 0 {
 		pathend = 0
 	}
-	l := strings.Index(fn.Name[pathend:], ".")
-	r := strings.LastIndex(fn.Name[pathend:], ".")
+	l := strings.Index(name[pathend:], ".")
+	r := strings.LastIndex(name[pathend:], ".")
 	if l == -1 || r == -1 || l == r {
 		return ""
 	}
-	return fn.Name[pathend+l+1 : pathend+r]
+	return name[pathend+l+1 : pathend+r]
 }
 
 // BaseName returns the symbol name without the package or receiver name.
 // Borrowed from $GOROOT/debug/gosym/symtab.go
 func (fn *Function) BaseName() string {
-	if i := strings.LastIndex(fn.Name, "."); i != -1 {
-		return fn.Name[i+1:]
+	name := fn.NameWithoutTypeParams()
+	if i := strings.LastIndex(name, "."); i != -1 {
+		return name[i+1:]
+	}
+	return name
+}
+
+// NameWithoutTypeParams returns the name of the function without the type
+// parameters of generic instantiations, for example the name of
+// main.Sum[go.shape.int] is main.Sum and the name of
+// main.(*List[go.shape.string]).Push is main.(*List).Push.
+func (fn *Function) NameWithoutTypeParams() string {
+	return removeTypeParams(fn.Name)
+}
+
+// removeTypeParams removes the lists of type parameters from name, a '['
+// following a '.' starts an array type, like in the names of the
+// equality functions generated by the compiler (type..eq.[2]string), and
+// is kept.
+func removeTypeParams(name string) string {
+	if !strings.Contains(name, "[") {
+		return name
 	}
-	return fn.Name
+	var buf strings.Builder
+	depth := 0
+	for i := 0; i < len(name); i++ {
+		switch {
+		case name[i] == '[' && (depth > 0 || (i > 0 && name[i-1] != '.')):
+			depth++
+		case name[i] == ']' && depth > 0:
+			depth--
+		case depth == 0:
+			buf.WriteByte(name[i])
+		}
+	}
+	return buf.String()
 }
 
 // Optimized returns true if the function was optimized by the compiler.
@@ -443,6 +586,51 @@ func NewBinaryInfo(goos, goarch string) *BinaryInfo {
 	return r
 }
 
+// ExecutablePlatform returns the operating system and architecture, as
+// GOOS and GOARCH values, of the executable at path, reading them from its
+// ELF, PE or Mach-O headers.
+func ExecutablePlatform(path string) (goos, goarch string, err error) {
+	if f, err := elf.Open(path); err == nil {
+		defer f.Close()
+		goos = "linux"
+		if f.OSABI == elf.ELFOSABI_FREEBSD {
+			goos = "freebsd"
+		}
+		switch f.Machine {
+		case elf.EM_X86_64:
+			return goos, "amd64", nil
+		case elf.EM_386:
+			return goos, "386", nil
+		case elf.EM_AARCH64:
+			return goos, "arm64", nil
+		}
+		return "", "", &ErrUnsupportedArch{os: goos, cpuArch: f.Machine}
+	}
+	if f, err := pe.Open(path); err == nil {
+		defer f.Close()
+		switch f.Machine {
+		case pe.IMAGE_FILE_MACHINE_AMD64:
+			return "windows", "amd64", nil
+		case pe.IMAGE_FILE_MACHINE_I386:
+			return "windows", "386", nil
+		case pe.IMAGE_FILE_MACHINE_ARM64:
+			return "windows", "arm64", nil
+		}
+		return "", "", &ErrUnsupportedArch{os: "windows", cpuArch: _PEMachine(f.Machine)}
+	}
+	if f, err := macho.Open(path); err == nil {
+		defer f.Close()
+		switch f.Cpu {
+		case macho.CpuAmd64:
+			return "darwin", "amd64", nil
+		case macho.CpuArm64:
+			return "darwin", "arm64", nil
+		}
+		return "", "", &ErrUnsupportedArch{os: "darwin", cpuArch: f.Cpu}
+	}
+	return "", "", fmt.Errorf("%s: unrecognized executable format", path)
+}
+
 // LoadBinaryInfo will load and store the information from the binary at 'path'.
 func (bi *BinaryInfo) LoadBinaryInfo(path string, entryPoint uint64, debugInfoDirs []string) error {
 	fi, err := os.Stat(path)
@@ -597,6 +785,20 @@ func (bi *BinaryInfo) AllPCsForFileLines(filename string, linenos []int) map[int
 	return r
 }
 
+// columnsForFileLine returns the statements of filename:lineno and the
+// columns where they start, in every compile unit.
+func (bi *BinaryInfo) columnsForFileLine(filename string, lineno int) []line.LineColumn {
+	var r []line.LineColumn
+	for _, image := range bi.Images {
+		for _, cu := range image.compileUnits {
+			if cu.lineInfo != nil && cu.lineInfo.Lookup[filename] != nil {
+				r = append(r, cu.lineInfo.ColumnsForFileLine(filename, lineno)...)
+			}
+		}
+	}
+	return r
+}
+
 // PCToFunc returns the concrete function containing the given PC address.
 // If the PC address belongs to an inlined call it will return the containing function.
 func (bi *BinaryInfo) PCToFunc(pc uint64) *Function {
@@ -634,6 +836,21 @@ func (bi *BinaryInfo) PCToInlineFunc(pc uint64) *Function {
 	return bi.LookupFunc[fnname]
 }
 
+// LookupGenericFunc returns a map that maps the names of generic
+// functions, without type parameters, to their instantiations.
+func (bi *BinaryInfo) LookupGenericFunc() map[string][]*Function {
+	if bi.lookupGenericFunc == nil {
+		bi.lookupGenericFunc = make(map[string][]*Function)
+		for i := range bi.Functions {
+			fn := &bi.Functions[i]
+			if dn := fn.NameWithoutTypeParams(); dn != fn.Name {
+				bi.lookupGenericFunc[dn] = append(bi.lookupGenericFunc[dn], fn)
+			}
+		}
+	}
+	return bi.lookupGenericFunc
+}
+
 // PCToImage returns the image containing the given PC address.
 func (bi *BinaryInfo) PCToImage(pc uint64) *Image {
 	fn := bi.PCToFunc(pc)
@@ -662,6 +879,7 @@ type Image struct {
 
 	compileUnits []*compileUnit // compileUnits is sorted by increasing DWARF offset
 
+	dwarfTreeCacheMu    sync.Mutex // stacks can be unwound concurrently, see GoroutinesStacktraces
 	dwarfTreeCache      *simplelru.LRU
 	runtimeMallocgcTree *godwarf.Tree // patched version of runtime.mallocgc's DIE
 
@@ -705,7 +923,9 @@ func (bi *BinaryInfo) AddImage(path string, addr uint64) error {
 	// add Image regardless of error so that we don't attempt to re-add it every time we stop
 	image.index = len(bi.Images)
 	bi.Images = append(bi.Images, image)
+	t0 := time.Now()
 	err := loadBinaryInfo(bi, image, path, addr)
+	metrics.SymbolLoadTime.Since(t0)
 	if err != nil {
 		bi.Images[len(bi.Images)-1].loadErr = err
 	}
@@ -792,15 +1012,20 @@ func (image *Image) getDwarfTree(off dwarf.Offset) (*godwarf.Tree, error) {
 	if image.runtimeMallocgcTree != nil && off == image.runtimeMallocgcTree.Offset {
 		return image.runtimeMallocgcTree, nil
 	}
-	if r, ok := image.dwarfTreeCache.Get(off); ok {
+	image.dwarfTreeCacheMu.Lock()
+	r, ok := image.dwarfTreeCache.Get(off)
+	image.dwarfTreeCacheMu.Unlock()
+	if ok {
 		return r.(*godwarf.Tree), nil
 	}
-	r, err := godwarf.LoadTree(off, image.dwarf, image.StaticBase)
+	tree, err := godwarf.LoadTree(off, image.dwarf, image.StaticBase)
 	if err != nil {
 		return nil, err
 	}
-	image.dwarfTreeCache.Add(off, r)
-	return r, nil
+	image.dwarfTreeCacheMu.Lock()
+	image.dwarfTreeCache.Add(off, tree)
+	image.dwarfTreeCacheMu.Unlock()
+	return tree, nil
 }
 
 type nilCloser struct{}
@@ -843,7 +1068,7 @@ func (bi *BinaryInfo) locationExpr(entry godwarf.Entry, attr dwarf.Attr, pc uint
 	}
 	instr := bi.loclistEntry(off, pc)
 	if instr == nil {
-		return nil, nil, fmt.Errorf("could not find loclist entry at %#x for address %#x", off, pc)
+		return nil, nil, fmt.Errorf("optimized out at %#x (no loclist entry at %#x)", pc, off)
 	}
 	return instr, &locationExpr{pc: pc, off: off, instr: instr}, nil
 }
@@ -1144,6 +1369,61 @@ func parseBuildID(exe *elf.File) (string, string, error) {
 	return desc[:2], desc[2:], nil
 }
 
+// goBuildIDPrefix and goBuildIDSuffix delimit the Go build ID that the
+// linker writes at the start of the text segment of Mach-O and PE
+// executables.
+const (
+	goBuildIDPrefix = "\xff Go build ID: \""
+	goBuildIDSuffix = "\"\n \xff"
+)
+
+// readGoBuildIDNote returns the Go build ID stored in the .note.go.buildid
+// section of an ELF executable, or its GNU build ID if there is no Go
+// build ID note.
+func readGoBuildIDNote(exe *elf.File) string {
+	sec := exe.Section(".note.go.buildid")
+	if sec == nil {
+		desc1, desc2, err := parseBuildID(exe)
+		if err != nil {
+			return ""
+		}
+		return desc1 + desc2
+	}
+	br := sec.Open()
+	bh := new(buildIDHeader)
+	if err := binary.Read(br, binary.LittleEndian, bh); err != nil {
+		return ""
+	}
+	// the name is padded to a multiple of 4 bytes
+	name := make([]byte, (bh.Namesz+3)&^3)
+	if _, err := io.ReadFull(br, name); err != nil || !strings.HasPrefix(string(name), "Go\x00") {
+		return ""
+	}
+	desc := make([]byte, bh.Descsz)
+	if _, err := io.ReadFull(br, desc); err != nil {
+		return ""
+	}
+	return string(desc)
+}
+
+// readGoBuildIDText returns the Go build ID found at the start of the text
+// section r, or the empty string.
+func readGoBuildIDText(r io.Reader) string {
+	buf := make([]byte, 1024)
+	n, _ := io.ReadFull(r, buf)
+	buf = buf[:n]
+	i := bytes.Index(buf, []byte(goBuildIDPrefix))
+	if i < 0 {
+		return ""
+	}
+	buf = buf[i+len(goBuildIDPrefix):]
+	j := bytes.Index(buf, []byte(goBuildIDSuffix))
+	if j < 0 {
+		return ""
+	}
+	return string(buf[:j])
+}
+
 // loadBinaryInfoElf specifically loads information from an ELF binary.
 func loadBinaryInfoElf(bi *BinaryInfo, image *Image, path string, addr uint64, wg *sync.WaitGroup) error {
 	exe, err := os.OpenFile(path, 0, os.ModePerm)
@@ -1174,6 +1454,7 @@ func loadBinaryInfoElf(bi *BinaryInfo, image *Image, path string, addr uint64, w
 			bi.ElfDynamicSection.Addr = dynsec.Addr + image.StaticBase
 			bi.ElfDynamicSection.Size = dynsec.Size
 		}
+		bi.BuildID = readGoBuildIDNote(elfFile)
 	} else {
 		image.StaticBase = addr
 	}
@@ -1369,6 +1650,10 @@ func loadBinaryInfoPE(bi *BinaryInfo, image *Image, path string, entryPoint uint
 
 	image.dwarfReader = image.dwarf.Reader()
 
+	if sec := peFile.Section(".text"); sec != nil {
+		bi.BuildID = readGoBuildIDText(sec.Open())
+	}
+
 	debugLineBytes, err := godwarf.GetDebugSectionPE(peFile, "line")
 	if err != nil {
 		return err
@@ -1445,6 +1730,10 @@ func loadBinaryInfoMacho(bi *BinaryInfo, image *Image, path string, entryPoint u
 
 	image.dwarfReader = image.dwarf.Reader()
 
+	if sec := exe.Section("__text"); sec != nil {
+		bi.BuildID = readGoBuildIDText(sec.Open())
+	}
+
 	debugLineBytes, err := godwarf.GetDebugSectionMacho(exe, "line")
 	if err != nil {
 		return err
@@ -1621,10 +1910,10 @@ func (bi *BinaryInfo) findTypeExpr(expr ast.Expr) (godwarf.Type, error) {
 		alen, litlen := anode.Len.(*ast.BasicLit)
 		if litlen && alen.Kind == token.INT {
 			n, _ := strconv.Atoi(alen.Value)
-			return bi.findArrayType(n, exprToString(anode.Elt))
+			return bi.findArrayType(n, evalexpr.ExprToString(anode.Elt))
 		}
 	}
-	return bi.findType(exprToString(expr))
+	return bi.findType(evalexpr.ExprToString(expr))
 }
 
 func (bi *BinaryInfo) findArrayType(n int, etyp string) (godwarf.Type, error) {
@@ -1784,6 +2073,7 @@ func (bi *BinaryInfo) loadDebugInfoMaps(image *Image, debugInfoBytes, debugLineB
 	for i := range bi.Functions {
 		bi.LookupFunc[bi.Functions[i].Name] = &bi.Functions[i]
 	}
+	bi.lookupGenericFunc = nil
 
 	for _, cu := range image.compileUnits {
 		if cu.lineInfo != nil {
@@ -2232,6 +2522,27 @@ func (bi *BinaryInfo) ListPackagesBuildInfo(includeFiles bool) []*PackageBuildIn
 	return r
 }
 
+// CompileUnitInfo describes a compile unit of the executable.
+type CompileUnitInfo struct {
+	Name      string
+	Producer  string
+	IsGo      bool
+	Optimized bool
+}
+
+// CompileUnits returns the compile units of the executable, in the order
+// they appear in its debug info.
+func (bi *BinaryInfo) CompileUnits() []CompileUnitInfo {
+	r := make([]CompileUnitInfo, 0, len(bi.Images[0].compileUnits))
+	for _, cu := range bi.Images[0].compileUnits {
+		if cu.image != bi.Images[0] {
+			continue
+		}
+		r = append(r, CompileUnitInfo{Name: cu.name, Producer: cu.producer, IsGo: cu.isgo, Optimized: cu.optimized})
+	}
+	return r
+}
+
 // cuFilePath takes a compilation unit "cu" and a file index reference
 // "fileidx" and returns the corresponding file name entry from the
 // DWARF line table associated with the unit; "entry" is the offset of
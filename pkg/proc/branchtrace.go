@@ -0,0 +1,384 @@
+package proc
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-delve/delve/pkg/proc/intelpt"
+
+	"golang.org/x/arch/x86/x86asm"
+)
+
+// BranchTraceKind is the kind of a control flow transfer reconstructed
+// from the branch trace.
+type BranchTraceKind uint8
+
+const (
+	// BranchTraceCall is a function call.
+	BranchTraceCall BranchTraceKind = iota
+	// BranchTraceReturn is a return from a function.
+	BranchTraceReturn
+	// BranchTraceJump is a jump, or any other transfer that isn't a call
+	// or a return, from a function to a different one. For example a tail
+	// call or the delivery of a signal.
+	BranchTraceJump
+	// BranchTraceGap marks a part of the trace that was lost or could not
+	// be decoded.
+	BranchTraceGap
+)
+
+// BranchTraceEvent is a control flow transfer from the instruction at
+// address From to the instruction at address To.
+// For BranchTraceGap events From is the last address decoded before the
+// gap and To the first address decoded after it, either can be 0.
+type BranchTraceEvent struct {
+	Kind     BranchTraceKind
+	From, To uint64
+}
+
+var (
+	// ErrBranchTraceNotSupported is returned by StartBranchTrace when the
+	// backend, the operating system or the CPU do not support hardware
+	// branch tracing.
+	ErrBranchTraceNotSupported = errors.New("branch tracing not supported")
+
+	// ErrBranchTraceNotStarted is returned by BranchTrace if StartBranchTrace
+	// wasn't called.
+	ErrBranchTraceNotStarted = errors.New("branch tracing not started")
+)
+
+const (
+	// maxBranchTraceEvents is the maximum number of events returned by
+	// BranchTrace.
+	maxBranchTraceEvents = 1 << 16
+	// maxTraceWalk is the maximum number of instructions decoded without
+	// consuming a packet before the decoder gives up.
+	maxTraceWalk = 1 << 16
+)
+
+// StartBranchTrace starts recording the control flow of all the threads of
+// the target with the hardware branch tracer of the CPU, currently only
+// Intel Processor Trace on linux/amd64 is supported.
+func (t *Target) StartBranchTrace() error {
+	if _, err := t.Valid(); err != nil {
+		return err
+	}
+	if t.BinInfo().Arch.Name != "amd64" {
+		return ErrBranchTraceNotSupported
+	}
+	return t.proc.StartBranchTrace()
+}
+
+// BranchTrace reconstructs, from the packets recorded by the branch tracer,
+// the calls, returns and jumps between functions executed by the thread
+// before reaching its current position. Only the most recent part of the
+// trace is kept by the tracer, events are returned oldest first.
+func (t *Target) BranchTrace(threadID int) ([]BranchTraceEvent, error) {
+	if _, err := t.Valid(); err != nil {
+		return nil, err
+	}
+	buf, err := t.proc.BranchTrace(threadID)
+	if err != nil {
+		return nil, err
+	}
+	w := &traceWalker{bi: t.BinInfo(), mem: t.Memory(), bps: t.Breakpoints(), text: make(map[uint64][]byte)}
+	w.decode(intelpt.NewDecoder(buf))
+	return w.events, nil
+}
+
+// traceWalker reconstructs the control flow of a thread by walking the
+// instructions of the target, using the packets of the trace to decide the
+// outcome of conditional and indirect branches.
+type traceWalker struct {
+	bi   *BinaryInfo
+	mem  MemoryReadWriter
+	bps  *BreakpointMap
+	text map[uint64][]byte // code of functions, indexed by entry point
+
+	ip      uint64 // address of the next instruction executed
+	ipValid bool
+	lastIP  uint64 // last address decoded, while ipValid is false
+
+	events []BranchTraceEvent
+}
+
+var errTraceDesync = errors.New("trace does not match the code")
+
+func (w *traceWalker) decode(d *intelpt.Decoder) {
+	if !d.Sync() {
+		return
+	}
+	inPSB := false
+	fup := false
+	for {
+		p, ok, err := d.Next()
+		if err == nil && !ok {
+			return
+		}
+		if err == nil {
+			switch p.Kind {
+			case intelpt.PSB:
+				inPSB = true
+			case intelpt.PSBEnd:
+				inPSB = false
+			case intelpt.OVF:
+				w.gap()
+			case intelpt.FUP:
+				switch {
+				case inPSB:
+					if !w.ipValid {
+						w.resume(p.IP, false)
+					}
+				case w.ipValid:
+					// asynchronous event, the next packet is its destination
+					var inst *x86asm.Inst
+					if inst, err = w.walk(p.IP); err == nil && inst != nil {
+						err = errTraceDesync
+					}
+					fup = true
+				default:
+					w.resume(p.IP, false)
+				}
+			case intelpt.TIPPGE:
+				w.resume(p.IP, true)
+			case intelpt.TIPPGD:
+				if w.ipValid && !fup {
+					// tracing was disabled by a far transfer into the
+					// kernel.
+					_, err = w.walk(0)
+				}
+				w.lastIP, w.ipValid = w.ip, false
+				fup = false
+			case intelpt.TNT:
+				for _, taken := range p.Taken {
+					if err = w.conditional(taken); err != nil {
+						break
+					}
+				}
+			case intelpt.TIP:
+				if fup {
+					fup = false
+					w.transfer(BranchTraceJump, w.ip, p.IP)
+					w.ip = p.IP
+				} else {
+					err = w.indirect(p.IP)
+				}
+			}
+		}
+		if err != nil {
+			w.gap()
+			inPSB, fup = false, false
+			if !d.Sync() {
+				return
+			}
+		}
+	}
+}
+
+// resume sets the address of the next instruction after tracing was
+// enabled or the decoder synchronized, if enabled is true and the thread
+// resumed in a different function a BranchTraceJump event is recorded.
+func (w *traceWalker) resume(ip uint64, enabled bool) {
+	if w.ipValid {
+		return
+	}
+	if n := len(w.events); n > 0 && w.events[n-1].Kind == BranchTraceGap && w.events[n-1].To == 0 {
+		w.events[n-1].To = ip
+	} else if enabled && w.lastIP != 0 {
+		w.transfer(BranchTraceJump, w.lastIP, ip)
+	}
+	w.ip, w.ipValid = ip, true
+}
+
+// gap records that the trace was lost after the current position.
+func (w *traceWalker) gap() {
+	from := w.lastIP
+	if w.ipValid {
+		from = w.ip
+	}
+	if n := len(w.events); n > 0 && w.events[n-1].Kind == BranchTraceGap && w.events[n-1].To == 0 {
+		return
+	}
+	w.appendEvent(BranchTraceEvent{Kind: BranchTraceGap, From: from})
+	w.lastIP, w.ipValid = 0, false
+}
+
+// conditional walks to the next conditional branch and takes it if taken
+// is true.
+func (w *traceWalker) conditional(taken bool) error {
+	if !w.ipValid {
+		return nil
+	}
+	inst, err := w.walk(0)
+	if err != nil {
+		return err
+	}
+	if inst == nil || !isConditionalBranch(inst.Op) {
+		return errTraceDesync
+	}
+	if !taken {
+		w.ip += uint64(inst.Len)
+		return nil
+	}
+	dest, ok := directBranchTarget(w.ip, inst)
+	if !ok {
+		return errTraceDesync
+	}
+	w.transfer(BranchTraceJump, w.ip, dest)
+	w.ip = dest
+	return nil
+}
+
+// indirect walks to the next indirect branch, return or far transfer and
+// moves to its destination dest.
+func (w *traceWalker) indirect(dest uint64) error {
+	if !w.ipValid {
+		w.resume(dest, false)
+		return nil
+	}
+	inst, err := w.walk(0)
+	if err != nil {
+		return err
+	}
+	if inst == nil {
+		return errTraceDesync
+	}
+	switch {
+	case inst.Op == x86asm.CALL:
+		w.transfer(BranchTraceCall, w.ip, dest)
+	case inst.Op == x86asm.RET:
+		w.transfer(BranchTraceReturn, w.ip, dest)
+	case inst.Op == x86asm.JMP || isFarTransfer(inst.Op):
+		w.transfer(BranchTraceJump, w.ip, dest)
+	default:
+		return errTraceDesync
+	}
+	w.ip = dest
+	return nil
+}
+
+// walk executes instructions starting at w.ip until it reaches one whose
+// destination can not be determined without the trace (conditional and
+// indirect branches, returns and far transfers), which is returned, or
+// until it reaches stop, if stop is not 0. Direct jumps and calls are
+// followed.
+func (w *traceWalker) walk(stop uint64) (*x86asm.Inst, error) {
+	for i := 0; i < maxTraceWalk; i++ {
+		if stop != 0 && w.ip == stop {
+			return nil, nil
+		}
+		inst, err := w.instruction(w.ip)
+		if err != nil {
+			return nil, err
+		}
+		switch inst.Op {
+		case x86asm.JMP, x86asm.CALL:
+			dest, ok := directBranchTarget(w.ip, &inst)
+			if !ok {
+				return &inst, nil
+			}
+			kind := BranchTraceJump
+			if inst.Op == x86asm.CALL {
+				kind = BranchTraceCall
+			}
+			w.transfer(kind, w.ip, dest)
+			w.ip = dest
+			continue
+		case x86asm.RET:
+			return &inst, nil
+		}
+		if isConditionalBranch(inst.Op) || isFarTransfer(inst.Op) {
+			return &inst, nil
+		}
+		w.ip += uint64(inst.Len)
+	}
+	return nil, errTraceDesync
+}
+
+// transfer records a control flow transfer, jumps are only recorded if
+// they move to a different function.
+func (w *traceWalker) transfer(kind BranchTraceKind, from, to uint64) {
+	if kind == BranchTraceJump {
+		fromFn, toFn := w.bi.PCToFunc(from), w.bi.PCToFunc(to)
+		if fromFn == toFn {
+			return
+		}
+	}
+	w.appendEvent(BranchTraceEvent{Kind: kind, From: from, To: to})
+}
+
+func (w *traceWalker) appendEvent(ev BranchTraceEvent) {
+	if len(w.events) >= 2*maxBranchTraceEvents {
+		n := copy(w.events, w.events[len(w.events)-maxBranchTraceEvents:])
+		w.events = w.events[:n]
+	}
+	w.events = append(w.events, ev)
+}
+
+// instruction decodes the instruction at pc, as it was before breakpoints
+// were written.
+func (w *traceWalker) instruction(pc uint64) (x86asm.Inst, error) {
+	var code []byte
+	var base uint64
+	if fn := w.bi.PCToFunc(pc); fn != nil && fn.End > fn.Entry {
+		code = w.text[fn.Entry]
+		if code == nil {
+			code = make([]byte, fn.End-fn.Entry)
+			if _, err := w.mem.ReadMemory(code, fn.Entry); err != nil {
+				return x86asm.Inst{}, err
+			}
+			w.restoreBreakpoints(code, fn.Entry)
+			w.text[fn.Entry] = code
+		}
+		base = fn.Entry
+	} else {
+		code = make([]byte, w.bi.Arch.MaxInstructionLength())
+		if _, err := w.mem.ReadMemory(code, pc); err != nil {
+			return x86asm.Inst{}, err
+		}
+		w.restoreBreakpoints(code, pc)
+		base = pc
+	}
+	inst, err := x86asm.Decode(code[pc-base:], 64)
+	if err != nil {
+		return inst, fmt.Errorf("could not decode instruction at %#x: %v", pc, err)
+	}
+	return inst, nil
+}
+
+// restoreBreakpoints replaces the breakpoints written in code, which was
+// read from address addr, with the original instructions.
+func (w *traceWalker) restoreBreakpoints(code []byte, addr uint64) {
+	for _, bp := range w.bps.M {
+		if bp.Addr < addr || bp.Addr >= addr+uint64(len(code)) {
+			continue
+		}
+		copy(code[bp.Addr-addr:], bp.OriginalData)
+	}
+}
+
+// directBranchTarget returns the destination of a jump or call with a
+// relative operand.
+func directBranchTarget(pc uint64, inst *x86asm.Inst) (uint64, bool) {
+	rel, ok := inst.Args[0].(x86asm.Rel)
+	if !ok {
+		return 0, false
+	}
+	return uint64(int64(pc) + int64(inst.Len) + int64(rel)), true
+}
+
+func isConditionalBranch(op x86asm.Op) bool {
+	switch op {
+	case x86asm.JA, x86asm.JAE, x86asm.JB, x86asm.JBE, x86asm.JCXZ, x86asm.JE, x86asm.JECXZ, x86asm.JG, x86asm.JGE, x86asm.JL, x86asm.JLE, x86asm.JNE, x86asm.JNO, x86asm.JNP, x86asm.JNS, x86asm.JO, x86asm.JP, x86asm.JRCXZ, x86asm.JS, x86asm.LOOP, x86asm.LOOPE, x86asm.LOOPNE:
+		return true
+	}
+	return false
+}
+
+func isFarTransfer(op x86asm.Op) bool {
+	switch op {
+	case x86asm.SYSCALL, x86asm.SYSENTER, x86asm.SYSRET, x86asm.SYSEXIT, x86asm.INT, x86asm.INTO, x86asm.IRET, x86asm.IRETD, x86asm.IRETQ, x86asm.LCALL, x86asm.LJMP, x86asm.LRET, x86asm.UD1, x86asm.UD2:
+		return true
+	}
+	return false
+}
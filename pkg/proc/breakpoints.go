@@ -1,13 +1,18 @@
 package proc
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"go/ast"
 	"go/constant"
-	"go/parser"
 	"go/token"
 	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-delve/delve/pkg/dwarf/godwarf"
+	"github.com/go-delve/delve/pkg/proc/evalexpr"
 )
 
 const (
@@ -30,16 +35,29 @@ type Breakpoint struct {
 	FunctionName string
 	File         string
 	Line         int
+	Column       int // column of Line, set by the client for file:line:col breakpoints
+
+	Addr         uint64   // Address breakpoint is set for.
+	OriginalData []byte   // If software breakpoint, the data we replace with breakpoint instruction.
+	Name         string   // User defined name of the breakpoint
+	Groups       []string // User defined groups the breakpoint belongs to
+	LogicalID    int      // ID of the logical breakpoint that owns this physical breakpoint
 
-	Addr         uint64 // Address breakpoint is set for.
-	OriginalData []byte // If software breakpoint, the data we replace with breakpoint instruction.
-	Name         string // User defined name of the breakpoint
-	LogicalID    int    // ID of the logical breakpoint that owns this physical breakpoint
+	// Logical is the logical breakpoint that owns this physical breakpoint,
+	// it is nil if this breakpoint was never a user breakpoint.
+	Logical *LogicalBreakpoint
 
 	WatchExpr    string
 	WatchType    WatchType
 	HWBreakIndex uint8 // hardware breakpoint index
 
+	// watchVarType is the type of the watched expression.
+	watchVarType godwarf.Type
+	// watchValue is the content of the memory watched by a read-only
+	// watchpoint when it was last checked. The hardware also traps writes
+	// to it, they are recognized because they change its content.
+	watchValue []byte
+
 	// Breaklets is the list of overlapping breakpoints on this physical breakpoint.
 	// There can be at most one UserBreakpoint in this list but multiple internal breakpoints are allowed.
 	Breaklets []*Breaklet
@@ -58,6 +76,124 @@ type Breakpoint struct {
 	returnInfo *returnBreakpointInfo
 }
 
+// LogicalBreakpoint represents a breakpoint set by the user. A logical
+// breakpoint can correspond to several physical breakpoints, for example
+// when the line it is set on belongs to a function that was inlined in
+// multiple places. Hits on any of its physical breakpoints are counted on
+// the logical breakpoint.
+type LogicalBreakpoint struct {
+	LogicalID int
+
+	HitCount      map[int]uint64 // Number of times a breakpoint has been reached in a certain goroutine
+	TotalHitCount uint64         // Number of times a breakpoint has been reached
+
+	// History, if not nil, records the hits of a watchpoint or breakpoint,
+	// which does not stop the target, see SetWatchpointHistory and
+	// SetHistory.
+	History *WatchHistory
+}
+
+// SetHistory makes lbp record its last n hits instead of stopping the
+// target, the hits already recorded are kept. If n is zero the history is
+// discarded and the breakpoint stops the target again.
+func (lbp *LogicalBreakpoint) SetHistory(n int) error {
+	if n < 0 {
+		return fmt.Errorf("invalid history size %d", n)
+	}
+	if n == 0 {
+		lbp.History = nil
+		return nil
+	}
+	if lbp.History == nil {
+		lbp.History = &WatchHistory{}
+	}
+	lbp.History.Max = n
+	if len(lbp.History.Entries) > n {
+		lbp.History.Entries = append([]WatchHistoryEntry(nil), lbp.History.Entries[len(lbp.History.Entries)-n:]...)
+	}
+	return nil
+}
+
+// WatchHistory records the writes to the memory location of a watchpoint
+// or the hits of a breakpoint.
+type WatchHistory struct {
+	// Max is the number of entries kept, older entries are discarded.
+	Max     int
+	Entries []WatchHistoryEntry
+}
+
+// WatchHistoryEntry describes a write to the memory location of a
+// watchpoint or a hit of a breakpoint.
+type WatchHistoryEntry struct {
+	GoroutineID int
+	// PC is the address of the instruction following the write, for
+	// watchpoints, or the address of the breakpoint.
+	PC uint64
+	// Value is the value of the watched expression after the write, nil
+	// for breakpoints.
+	Value *Variable
+	// Variables are the values of the Variables expressions of the
+	// breakpoint at the time of the hit.
+	Variables []*Variable
+	// Stack is the stacktrace of the goroutine, up to Stacktrace frames of
+	// the breakpoint or watchHistoryStackDepth frames.
+	Stack []Stackframe
+}
+
+// watchHistoryStackDepth is the depth of the stacktraces recorded by
+// WatchHistory.
+const watchHistoryStackDepth = 10
+
+func (h *WatchHistory) record(bp *Breakpoint, thread Thread) {
+	e := WatchHistoryEntry{}
+	if bp.WatchType != 0 {
+		if regs, err := thread.Registers(); err == nil {
+			e.PC = regs.PC()
+		}
+	} else {
+		e.PC = bp.Addr
+	}
+	depth := watchHistoryStackDepth
+	if bp.Stacktrace > 0 {
+		depth = bp.Stacktrace
+	}
+	if g, err := GetG(thread); err == nil && g != nil {
+		e.GoroutineID = g.ID
+		e.Stack, _ = g.Stacktrace(depth, 0)
+	} else {
+		e.Stack, _ = ThreadStacktrace(thread, depth)
+	}
+	if bp.WatchType != 0 {
+		e.Value = newVariable(bp.WatchExpr, bp.Addr, bp.watchVarType, thread.BinInfo(), thread.ProcessMemory())
+		e.Value.loadValue(loadSingleValue)
+	}
+	if len(bp.Variables) > 0 {
+		scope, err := GoroutineScope(nil, thread)
+		if err != nil {
+			scope, err = ThreadScope(nil, thread)
+		}
+		for _, expr := range bp.Variables {
+			var v *Variable
+			if err == nil {
+				var everr error
+				v, everr = scope.EvalVariable(expr, loadFullValue)
+				if everr != nil {
+					v = &Variable{Name: expr, Unreadable: everr}
+				}
+			} else {
+				v = &Variable{Name: expr, Unreadable: err}
+			}
+			e.Variables = append(e.Variables, v)
+		}
+	}
+
+	if len(h.Entries) >= h.Max {
+		copy(h.Entries, h.Entries[len(h.Entries)-h.Max+1:])
+		h.Entries = h.Entries[:h.Max-1]
+	}
+	h.Entries = append(h.Entries, e)
+}
+
 // Breaklet represents one of multiple breakpoints that can overlap on a
 // single physical breakpoint.
 type Breaklet struct {
@@ -68,9 +204,6 @@ type Breaklet struct {
 	// Cond: if not nil the breakpoint will be triggered only if evaluating Cond returns true
 	Cond ast.Expr
 
-	HitCount      map[int]uint64 // Number of times a breakpoint has been reached in a certain goroutine
-	TotalHitCount uint64         // Number of times a breakpoint has been reached
-
 	// DeferReturns: when kind == NextDeferBreakpoint this breakpoint
 	// will also check if the caller is runtime.gopanic or if the return
 	// address is in the DeferReturns array.
@@ -83,11 +216,16 @@ type Breaklet struct {
 	DeferReturns []uint64
 
 	// HitCond: if not nil the breakpoint will be triggered only if the evaluated HitCond returns
-	// true with the TotalHitCount.
+	// true with the TotalHitCount of the logical breakpoint.
 	HitCond *struct {
 		Op  token.Token
 		Val int
 	}
+
+	// Caller: if not empty the breakpoint will be triggered only if the
+	// function containing it was called by the function named Caller, see
+	// matchCaller.
+	Caller string
 }
 
 // BreakpointKind determines the behavior of delve when the
@@ -109,6 +247,14 @@ const (
 	// Continue will set a new breakpoint (of NextBreakpoint kind) on the
 	// destination of CALL, delete this breakpoint and then continue again
 	StepBreakpoint
+	// FreezeBreakpoint is a breakpoint set by FreezeGoroutine, when its
+	// condition is true the thread is kept at the breakpoint instead of
+	// stepping over it, Continue does not stop on it.
+	FreezeBreakpoint
+	// CoverageBreakpoint is a breakpoint set by StartCoverage, Continue
+	// records that its line was executed, clears it and does not stop on
+	// it.
+	CoverageBreakpoint
 
 	steppingMask = NextBreakpoint | NextDeferBreakpoint | StepBreakpoint
 )
@@ -163,10 +309,31 @@ func (bpe BreakpointExistsError) Error() string {
 // attempting to set a breakpoint at an invalid address.
 type InvalidAddressError struct {
 	Address uint64
+	// Mapping is the memory mapping containing Address, nil if Address is
+	// not mapped.
+	Mapping *MemoryMapEntry
 }
 
 func (iae InvalidAddressError) Error() string {
-	return fmt.Sprintf("Invalid address %#v\n", iae.Address)
+	if iae.Mapping == nil {
+		return fmt.Sprintf("invalid address %#x: not mapped in the target", iae.Address)
+	}
+	return fmt.Sprintf("invalid address %#x: not in an executable mapping (%#x-%#x %s)", iae.Address, iae.Mapping.Addr, iae.Mapping.Addr+iae.Mapping.Size, iae.Mapping.Filename)
+}
+
+// BreakpointWriteError is returned when the breakpoint instruction could
+// not be written to, or removed from, the memory of the target process.
+type BreakpointWriteError struct {
+	Addr  uint64
+	Erase bool // the error happened while removing the breakpoint
+	Err   error
+}
+
+func (bwe BreakpointWriteError) Error() string {
+	if bwe.Erase {
+		return fmt.Sprintf("could not clear breakpoint at %#x: %v", bwe.Addr, bwe.Err)
+	}
+	return fmt.Sprintf("could not set breakpoint at %#x: %v", bwe.Addr, bwe.Err)
 }
 
 type returnBreakpointInfo struct {
@@ -179,8 +346,20 @@ type returnBreakpointInfo struct {
 // CheckCondition evaluates bp's condition on thread.
 func (bp *Breakpoint) CheckCondition(thread Thread) BreakpointState {
 	bpstate := BreakpointState{Breakpoint: bp, Active: false, Stepping: false, SteppingInto: false, CondError: nil}
+	// Freeze breaklets are checked first, a frozen goroutine must not stop
+	// or count as a hit for the other breaklets every time it is resumed.
+	for _, breaklet := range bp.Breaklets {
+		if breaklet.Kind == FreezeBreakpoint {
+			bpstate.checkCond(breaklet, thread)
+		}
+	}
+	if bpstate.Frozen {
+		return bpstate
+	}
 	for _, breaklet := range bp.Breaklets {
-		bpstate.checkCond(breaklet, thread)
+		if breaklet.Kind != FreezeBreakpoint {
+			bpstate.checkCond(breaklet, thread)
+		}
 	}
 	return bpstate
 }
@@ -191,6 +370,9 @@ func (bpstate *BreakpointState) checkCond(breaklet *Breaklet, thread Thread) {
 	if breaklet.Cond != nil {
 		active, condErr = evalBreakpointCondition(thread, breaklet.Cond)
 	}
+	if active && condErr == nil && breaklet.Caller != "" {
+		active, condErr = checkCaller(thread, breaklet.Caller)
+	}
 
 	if condErr != nil && bpstate.CondError == nil {
 		bpstate.CondError = condErr
@@ -201,11 +383,31 @@ func (bpstate *BreakpointState) checkCond(breaklet *Breaklet, thread Thread) {
 
 	switch breaklet.Kind {
 	case UserBreakpoint:
+		if bpstate.watchValue != nil && bpstate.watchedMemoryChanged(thread.ProcessMemory()) {
+			// a write to the memory watched by a read-only watchpoint
+			active = false
+			break
+		}
+		lbp := bpstate.Logical
 		if g, err := GetG(thread); err == nil {
-			breaklet.HitCount[g.ID]++
+			lbp.HitCount[g.ID]++
+		}
+		lbp.TotalHitCount++
+		active = checkHitCond(breaklet, lbp.TotalHitCount)
+		if active && lbp.History != nil {
+			lbp.History.record(bpstate.Breakpoint, thread)
+			active = false
 		}
-		breaklet.TotalHitCount++
-		active = checkHitCond(breaklet)
+
+	case FreezeBreakpoint:
+		if active {
+			bpstate.Frozen = true
+		}
+		active = false
+
+	case CoverageBreakpoint:
+		bpstate.Covered = true
+		active = false
 
 	case StepBreakpoint, NextBreakpoint, NextDeferBreakpoint:
 		nextDeferOk := true
@@ -236,31 +438,54 @@ func (bpstate *BreakpointState) checkCond(breaklet *Breaklet, thread Thread) {
 	}
 }
 
-// checkHitCond evaluates bp's hit condition on thread.
-func checkHitCond(breaklet *Breaklet) bool {
+// checkHitCond evaluates the hit condition of breaklet against the total
+// hit count of its logical breakpoint.
+func checkHitCond(breaklet *Breaklet, totalHitCount uint64) bool {
 	if breaklet.HitCond == nil {
 		return true
 	}
+	hits := int(totalHitCount)
 	// Evaluate the breakpoint condition.
 	switch breaklet.HitCond.Op {
 	case token.EQL:
-		return int(breaklet.TotalHitCount) == breaklet.HitCond.Val
+		return hits == breaklet.HitCond.Val
 	case token.NEQ:
-		return int(breaklet.TotalHitCount) != breaklet.HitCond.Val
+		return hits != breaklet.HitCond.Val
 	case token.GTR:
-		return int(breaklet.TotalHitCount) > breaklet.HitCond.Val
+		return hits > breaklet.HitCond.Val
 	case token.LSS:
-		return int(breaklet.TotalHitCount) < breaklet.HitCond.Val
+		return hits < breaklet.HitCond.Val
 	case token.GEQ:
-		return int(breaklet.TotalHitCount) >= breaklet.HitCond.Val
+		return hits >= breaklet.HitCond.Val
 	case token.LEQ:
-		return int(breaklet.TotalHitCount) <= breaklet.HitCond.Val
+		return hits <= breaklet.HitCond.Val
 	case token.REM:
-		return int(breaklet.TotalHitCount)%breaklet.HitCond.Val == 0
+		return hits%breaklet.HitCond.Val == 0
 	}
 	return false
 }
 
+// checkCaller returns true if the function of the topmost frame of thread
+// was called by the function named caller.
+func checkCaller(thread Thread, caller string) (bool, error) {
+	frames, err := ThreadStacktrace(thread, 1)
+	if err != nil {
+		return false, err
+	}
+	if len(frames) < 2 || frames[1].Call.Fn == nil {
+		return false, nil
+	}
+	return matchCaller(frames[1].Call.Fn, caller), nil
+}
+
+// matchCaller returns true if name is the name of fn, without type
+// parameters, or a suffix of it starting at a path component, i.e.
+// "mypkg.HandleRequest" matches "example.com/mypkg.HandleRequest".
+func matchCaller(fn *Function, name string) bool {
+	fnname := fn.NameWithoutTypeParams()
+	return fnname == name || strings.HasSuffix(fnname, "/"+name)
+}
+
 func isPanicCall(frames []Stackframe) (bool, int) {
 	// In Go prior to 1.17 the call stack for a panic is:
 	//  0. deferred function call
@@ -317,6 +542,44 @@ func (bp *Breakpoint) IsUser() bool {
 	return false
 }
 
+// VerboseDescr returns a description of each breaklet of bp, it is used
+// to show the breakpoints set internally by Delve, for example by next and
+// step, which are otherwise hidden from the user.
+func (bp *Breakpoint) VerboseDescr() []string {
+	r := []string{}
+	for _, breaklet := range bp.Breaklets {
+		if breaklet == nil {
+			continue
+		}
+		var kind string
+		switch breaklet.Kind {
+		case UserBreakpoint:
+			kind = "User"
+		case NextBreakpoint:
+			kind = "Next"
+		case NextDeferBreakpoint:
+			kind = "NextDefer"
+		case StepBreakpoint:
+			kind = "Step"
+		case FreezeBreakpoint:
+			kind = "Freeze"
+		case CoverageBreakpoint:
+			kind = "Coverage"
+		default:
+			kind = fmt.Sprintf("Unknown(%d)", breaklet.Kind)
+		}
+		descr := kind
+		if breaklet.Cond != nil {
+			descr += fmt.Sprintf(" Cond=%q", evalexpr.ExprToString(breaklet.Cond))
+		}
+		if len(breaklet.DeferReturns) > 0 {
+			descr += fmt.Sprintf(" DeferReturns=%#x", breaklet.DeferReturns)
+		}
+		r = append(r, descr)
+	}
+	return r
+}
+
 // UserBreaklet returns the user breaklet for this breakpoint, or nil if
 // none exist.
 func (bp *Breakpoint) UserBreaklet() *Breaklet {
@@ -367,6 +630,9 @@ func (nbp NoBreakpointError) Error() string {
 type BreakpointMap struct {
 	M map[uint64]*Breakpoint
 
+	// Logical is a map of logical breakpoint IDs to logical breakpoints.
+	Logical map[int]*LogicalBreakpoint
+
 	breakpointIDCounter         int
 	internalBreakpointIDCounter int
 }
@@ -374,14 +640,16 @@ type BreakpointMap struct {
 // NewBreakpointMap creates a new BreakpointMap.
 func NewBreakpointMap() BreakpointMap {
 	return BreakpointMap{
-		M: make(map[uint64]*Breakpoint),
+		M:       make(map[uint64]*Breakpoint),
+		Logical: make(map[int]*LogicalBreakpoint),
 	}
 }
 
 // SetBreakpoint sets a breakpoint at addr, and stores it in the process wide
-// break point table.
+// break point table. User breakpoints are associated with a new logical
+// breakpoint.
 func (t *Target) SetBreakpoint(addr uint64, kind BreakpointKind, cond ast.Expr) (*Breakpoint, error) {
-	return t.setBreakpointInternal(addr, kind, 0, cond)
+	return t.setBreakpointInternal(0, addr, kind, 0, cond)
 }
 
 // SetWatchpoint sets a data breakpoint at addr and stores it in the
@@ -391,7 +659,7 @@ func (t *Target) SetWatchpoint(scope *EvalScope, expr string, wtype WatchType, c
 		return nil, errors.New("at least one of read and write must be set for watchpoint")
 	}
 
-	n, err := parser.ParseExpr(expr)
+	n, err := evalexpr.Parse(expr)
 	if err != nil {
 		return nil, err
 	}
@@ -420,30 +688,73 @@ func (t *Target) SetWatchpoint(scope *EvalScope, expr string, wtype WatchType, c
 		return nil, errors.New("can not watch stack allocated variable")
 	}
 
-	bp, err := t.setBreakpointInternal(xv.Addr, UserBreakpoint, wtype.withSize(uint8(sz)), cond)
+	var watchValue []byte
+	if wtype&WatchWrite == 0 {
+		watchValue = make([]byte, sz)
+		if _, err := t.Memory().ReadMemory(watchValue, xv.Addr); err != nil {
+			return nil, err
+		}
+	}
+
+	bp, err := t.setBreakpointInternal(0, xv.Addr, UserBreakpoint, wtype.withSize(uint8(sz)), cond)
 	if bp != nil {
 		bp.WatchExpr = expr
+		bp.watchVarType = xv.DwarfType
+		bp.watchValue = watchValue
 	}
 	return bp, err
 }
 
-func (t *Target) setBreakpointInternal(addr uint64, kind BreakpointKind, wtype WatchType, cond ast.Expr) (*Breakpoint, error) {
+// watchedMemoryChanged returns true if the memory watched by the read-only
+// watchpoint bp changed since the last time it was checked.
+func (bp *Breakpoint) watchedMemoryChanged(mem MemoryReadWriter) bool {
+	buf := make([]byte, len(bp.watchValue))
+	if _, err := mem.ReadMemory(buf, bp.Addr); err != nil || bytes.Equal(buf, bp.watchValue) {
+		return false
+	}
+	bp.watchValue = buf
+	return true
+}
+
+// SetWatchpointHistory makes the write watchpoint bp record its last n
+// hits instead of stopping the target. If n is zero the history is
+// discarded and the watchpoint stops the target again.
+func (t *Target) SetWatchpointHistory(bp *Breakpoint, n int) error {
+	if bp.WatchType == 0 || bp.Logical == nil {
+		return errors.New("not a watchpoint")
+	}
+	if bp.WatchType&WatchWrite == 0 {
+		return errors.New("history can only be recorded for write watchpoints")
+	}
+	return bp.Logical.SetHistory(n)
+}
+
+// setBreakpointInternal sets a breakpoint at addr. If kind is
+// UserBreakpoint the breakpoint is associated with the logical breakpoint
+// logicalID, or with a new logical breakpoint if logicalID is 0.
+func (t *Target) setBreakpointInternal(logicalID int, addr uint64, kind BreakpointKind, wtype WatchType, cond ast.Expr) (*Breakpoint, error) {
 	if valid, err := t.Valid(); !valid {
 		return nil, err
 	}
 	bpmap := t.Breakpoints()
 	newBreaklet := &Breaklet{Kind: kind, Cond: cond}
-	if kind == UserBreakpoint {
-		newBreaklet.HitCount = map[int]uint64{}
-	}
 	if bp, ok := bpmap.M[addr]; ok {
 		if !bp.canOverlap(kind) {
 			return bp, BreakpointExistsError{bp.File, bp.Line, bp.Addr}
 		}
+		if kind == UserBreakpoint {
+			bpmap.setLogical(bp, logicalID)
+		}
 		bp.Breaklets = append(bp.Breaklets, newBreaklet)
 		return bp, nil
 	}
 
+	if wtype == 0 {
+		if err := t.checkBreakpointAddr(addr); err != nil {
+			return nil, err
+		}
+	}
+
 	f, l, fn := t.BinInfo().PCToLine(uint64(addr))
 
 	fnName := ""
@@ -477,15 +788,14 @@ func (t *Target) setBreakpointInternal(addr uint64, kind BreakpointKind, wtype W
 
 	err := t.proc.WriteBreakpoint(newBreakpoint)
 	if err != nil {
-		return nil, err
+		return nil, BreakpointWriteError{Addr: addr, Err: err}
 	}
 
 	if kind != UserBreakpoint {
 		bpmap.internalBreakpointIDCounter++
 		newBreakpoint.LogicalID = bpmap.internalBreakpointIDCounter
 	} else {
-		bpmap.breakpointIDCounter++
-		newBreakpoint.LogicalID = bpmap.breakpointIDCounter
+		bpmap.setLogical(newBreakpoint, logicalID)
 	}
 
 	newBreakpoint.Breaklets = append(newBreakpoint.Breaklets, newBreaklet)
@@ -495,15 +805,54 @@ func (t *Target) setBreakpointInternal(addr uint64, kind BreakpointKind, wtype W
 	return newBreakpoint, nil
 }
 
+// checkBreakpointAddr returns an InvalidAddressError if addr does not
+// belong to a known function and isn't inside an executable mapping of the
+// target, writing a breakpoint instruction there would corrupt data instead
+// of stopping the target.
+// If the backend can not list the memory mappings of the target the
+// address is not checked.
+func (t *Target) checkBreakpointAddr(addr uint64) error {
+	if t.BinInfo().PCToFunc(addr) != nil {
+		return nil
+	}
+	mappings, err := t.MemoryMap()
+	if err != nil {
+		return nil
+	}
+	for i := range mappings {
+		m := &mappings[i]
+		if addr >= m.Addr && addr < m.Addr+m.Size {
+			if m.Exec {
+				return nil
+			}
+			return InvalidAddressError{Address: addr, Mapping: m}
+		}
+	}
+	return InvalidAddressError{Address: addr}
+}
+
+// setLogical associates bp with the logical breakpoint logicalID, creating
+// it if it doesn't exist. If logicalID is 0 a new logical breakpoint is
+// created with the next available ID.
+func (bpmap *BreakpointMap) setLogical(bp *Breakpoint, logicalID int) {
+	if logicalID == 0 {
+		bpmap.breakpointIDCounter++
+		logicalID = bpmap.breakpointIDCounter
+	}
+	lbp := bpmap.Logical[logicalID]
+	if lbp == nil {
+		lbp = &LogicalBreakpoint{LogicalID: logicalID, HitCount: map[int]uint64{}}
+		bpmap.Logical[logicalID] = lbp
+	}
+	bp.LogicalID = logicalID
+	bp.Logical = lbp
+}
+
 // SetBreakpointWithID creates a breakpoint at addr, with the specified logical ID.
+// If a logical breakpoint with the same ID already exists the new physical
+// breakpoint is added to it.
 func (t *Target) SetBreakpointWithID(id int, addr uint64) (*Breakpoint, error) {
-	bpmap := t.Breakpoints()
-	bp, err := t.SetBreakpoint(addr, UserBreakpoint, nil)
-	if err == nil {
-		bp.LogicalID = id
-		bpmap.breakpointIDCounter--
-	}
-	return bp, err
+	return t.setBreakpointInternal(id, addr, UserBreakpoint, 0, nil)
 }
 
 // canOverlap returns true if a breakpoint of kind can be overlapped to the
@@ -527,17 +876,85 @@ func (t *Target) ClearBreakpoint(addr uint64) (*Breakpoint, error) {
 		return nil, NoBreakpointError{Addr: addr}
 	}
 
+	cleared, err := t.clearUserBreaklet(bp)
+	if err != nil {
+		return nil, err
+	}
+	t.Breakpoints().deleteLogicalIfUnused(cleared.LogicalID)
+	return cleared, nil
+}
+
+// ClearBreakpoints clears, in a single pass over the breakpoint map, the
+// user breakpoints for which filter returns true and returns them sorted
+// by logical ID and address.
+// The breakpoints with a negative logical ID, set on unrecovered panics
+// and fatal throws, are never cleared. If a breakpoint can not be cleared
+// the breakpoints cleared until then are returned with the error.
+func (t *Target) ClearBreakpoints(filter func(*Breakpoint) bool) ([]*Breakpoint, error) {
+	if valid, err := t.Valid(); !valid {
+		return nil, err
+	}
+	bpmap := t.Breakpoints()
+	var r []*Breakpoint
+	var err error
+	for _, bp := range bpmap.M {
+		if !bp.IsUser() || bp.LogicalID < 0 || !filter(bp) {
+			continue
+		}
+		var cleared *Breakpoint
+		if cleared, err = t.clearUserBreaklet(bp); err != nil {
+			break
+		}
+		r = append(r, cleared)
+	}
+	for _, bp := range r {
+		bpmap.deleteLogicalIfUnused(bp.LogicalID)
+	}
+	sort.Slice(r, func(i, j int) bool {
+		if r[i].LogicalID != r[j].LogicalID {
+			return r[i].LogicalID < r[j].LogicalID
+		}
+		return r[i].Addr < r[j].Addr
+	})
+	return r, err
+}
+
+// clearUserBreaklet removes the user breaklet of bp, erasing bp if no
+// other breaklet is left, and returns the cleared user breakpoint.
+// If bp is kept for its internal breaklets it stops belonging to its
+// logical breakpoint and a copy made before that is returned.
+func (t *Target) clearUserBreaklet(bp *Breakpoint) (*Breakpoint, error) {
+	oldBreaklets := append([]*Breaklet(nil), bp.Breaklets...)
 	for i := range bp.Breaklets {
 		if bp.Breaklets[i].Kind == UserBreakpoint {
 			bp.Breaklets[i] = nil
 		}
 	}
 
-	_, err := t.finishClearBreakpoint(bp)
+	erased, err := t.finishClearBreakpoint(bp)
 	if err != nil {
+		// The breakpoint is still in memory, keep it.
+		bp.Breaklets = oldBreaklets
 		return nil, err
 	}
-	return bp, nil
+	if erased {
+		return bp, nil
+	}
+	cleared := *bp
+	bp.LogicalID = 0
+	bp.Logical = nil
+	return &cleared, nil
+}
+
+// deleteLogicalIfUnused deletes the logical breakpoint with the specified
+// ID if it doesn't have any physical breakpoints left.
+func (bpmap *BreakpointMap) deleteLogicalIfUnused(logicalID int) {
+	for _, bp := range bpmap.M {
+		if bp.LogicalID == logicalID && bp.IsUser() {
+			return
+		}
+	}
+	delete(bpmap.Logical, logicalID)
 }
 
 // ClearInternalBreakpoints removes all stepping breakpoints from the map,
@@ -568,7 +985,10 @@ func (t *Target) ClearSteppingBreakpoints() error {
 
 // finishClearBreakpoint clears nil breaklets from the breaklet list of bp
 // and if it is empty erases the breakpoint.
-// Returns true if the breakpoint was deleted
+// Returns true if the breakpoint was deleted. If erasing the breakpoint
+// fails it is left in the breakpoint map, with no breaklets, so that the
+// map stays consistent with the memory of the target and the erase is
+// retried by the next call to ClearSteppingBreakpoints.
 func (t *Target) finishClearBreakpoint(bp *Breakpoint) (bool, error) {
 	oldBreaklets := bp.Breaklets
 	bp.Breaklets = bp.Breaklets[:0]
@@ -581,7 +1001,7 @@ func (t *Target) finishClearBreakpoint(bp *Breakpoint) (bool, error) {
 		return false, nil
 	}
 	if err := t.proc.EraseBreakpoint(bp); err != nil {
-		return false, err
+		return false, BreakpointWriteError{Addr: bp.Addr, Erase: true, Err: err}
 	}
 
 	delete(t.Breakpoints().M, bp.Addr)
@@ -620,6 +1040,12 @@ type BreakpointState struct {
 	// SteppingInto is true if one of the active stepping breaklets has Kind ==
 	// StepBreakpoint.
 	SteppingInto bool
+	// Frozen is true if the thread is running a frozen goroutine and must
+	// not be stepped over the breakpoint or resumed.
+	Frozen bool
+	// Covered is true if the breakpoint has a coverage breaklet, see
+	// StartCoverage.
+	Covered bool
 	// CondError contains any error encountered while evaluating the
 	// breakpoint's condition.
 	CondError error
@@ -631,6 +1057,8 @@ func (bpstate *BreakpointState) Clear() {
 	bpstate.Active = false
 	bpstate.Stepping = false
 	bpstate.SteppingInto = false
+	bpstate.Frozen = false
+	bpstate.Covered = false
 	bpstate.CondError = nil
 }
 
@@ -642,6 +1070,9 @@ func (bpstate *BreakpointState) String() string {
 	if bpstate.Stepping {
 		s += " stepping"
 	}
+	if bpstate.Frozen {
+		s += " frozen"
+	}
 	return s
 }
 
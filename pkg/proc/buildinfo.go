@@ -0,0 +1,417 @@
+package proc
+
+import (
+	"bytes"
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// BuildInfo is the build information that the Go linker embeds in
+// executables, see runtime/debug.BuildInfo. Only the Go version is
+// available for executables not built in module mode.
+type BuildInfo struct {
+	// GoVersion is the version of the Go toolchain that built the
+	// executable, for example "go1.17.2".
+	GoVersion string
+	// Path is the package path of the main package.
+	Path string
+	// Main is the module containing the main package.
+	Main Module
+	// Deps are the dependencies of the main module.
+	Deps []*Module
+	// Settings describe the build, for example the VCS revision of the
+	// sources and the build flags (Go 1.18 and later).
+	Settings []BuildSetting
+}
+
+// Module is a module linked in an executable.
+type Module struct {
+	Path    string
+	Version string
+	Sum     string
+	Replace *Module // replacement of this module, if any
+}
+
+// BuildSetting is a key/value pair describing one setting of a build.
+type BuildSetting struct {
+	Key, Value string
+}
+
+// ErrNoBuildInfo is returned by BinaryInfo.BuildInfo when the executable
+// does not contain build information.
+var ErrNoBuildInfo = errors.New("no build information in executable")
+
+// buildInfoMagic is the start of the header of the build information,
+// which is aligned to 16 bytes.
+const buildInfoMagic = "\xff Go buildinf:"
+
+// buildInfoExe is an executable the build information can be read from.
+type buildInfoExe interface {
+	io.Closer
+	// dataStart returns the address of the section containing the build
+	// information.
+	dataStart() (uint64, error)
+	// readData reads size bytes at address addr, or less if the section
+	// containing addr ends before.
+	readData(addr, size uint64) ([]byte, error)
+}
+
+// BuildInfo reads the build information of the executable of the target.
+func (bi *BinaryInfo) BuildInfo() (*BuildInfo, error) {
+	if len(bi.Images) == 0 {
+		return nil, ErrNoBuildInfo
+	}
+	var exe buildInfoExe
+	path := bi.Images[0].Path
+	switch bi.GOOS {
+	case "linux", "freebsd":
+		f, err := elf.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		exe = &elfBuildInfoExe{f}
+	case "windows":
+		f, err := pe.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		exe = &peBuildInfoExe{f}
+	case "darwin":
+		f, err := macho.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		exe = &machoBuildInfoExe{f}
+	default:
+		return nil, errors.New("unsupported operating system")
+	}
+	defer exe.Close()
+	return readBuildInfo(exe)
+}
+
+// readBuildInfo reads the build information of exe, its format is the
+// one read by debug/buildinfo.
+func readBuildInfo(exe buildInfoExe) (*BuildInfo, error) {
+	start, err := exe.dataStart()
+	if err != nil {
+		return nil, err
+	}
+	const searchSize = 64 * 1024
+	data, err := exe.readData(start, searchSize)
+	if err != nil {
+		return nil, err
+	}
+	const buildInfoAlign = 16
+	const buildInfoHeaderSize = 32
+	for {
+		i := bytes.Index(data, []byte(buildInfoMagic))
+		if i < 0 || len(data)-i < buildInfoHeaderSize {
+			return nil, ErrNoBuildInfo
+		}
+		if i%buildInfoAlign == 0 {
+			data = data[i:]
+			break
+		}
+		data = data[(i+buildInfoAlign-1)&^(buildInfoAlign-1):]
+	}
+
+	ptrSize := int(data[14])
+	flags := data[15]
+	var vers, mod string
+	if flags&2 != 0 {
+		// Go 1.18 and later: the strings follow the header, prefixed by
+		// their length.
+		data = data[buildInfoHeaderSize:]
+		var ok bool
+		if vers, data, ok = decodeBuildInfoString(data); ok {
+			mod, _, ok = decodeBuildInfoString(data)
+		}
+		if !ok {
+			return nil, errors.New("malformed build information")
+		}
+	} else {
+		// Before Go 1.18: the header contains pointers to the two strings.
+		var bo binary.ByteOrder = binary.LittleEndian
+		if flags&1 != 0 {
+			bo = binary.BigEndian
+		}
+		var readPtr func([]byte) uint64
+		switch ptrSize {
+		case 4:
+			readPtr = func(b []byte) uint64 { return uint64(bo.Uint32(b)) }
+		case 8:
+			readPtr = bo.Uint64
+		default:
+			return nil, fmt.Errorf("malformed build information: pointer size %d", ptrSize)
+		}
+		vers = readBuildInfoString(exe, ptrSize, readPtr, readPtr(data[16:]))
+		mod = readBuildInfoString(exe, ptrSize, readPtr, readPtr(data[16+ptrSize:]))
+	}
+	if vers == "" {
+		return nil, ErrNoBuildInfo
+	}
+
+	// The module information is surrounded by 16 byte sentinels.
+	if len(mod) >= 33 && mod[len(mod)-17] == '\n' {
+		mod = mod[16 : len(mod)-16]
+	} else {
+		mod = ""
+	}
+	r, err := parseModInfo(mod)
+	if err != nil {
+		return nil, err
+	}
+	r.GoVersion = vers
+	return r, nil
+}
+
+// decodeBuildInfoString decodes a string prefixed by its length, encoded
+// as a varint, at the start of data and returns it with the rest of data.
+func decodeBuildInfoString(data []byte) (string, []byte, bool) {
+	n, sz := binary.Uvarint(data)
+	if sz <= 0 || n > uint64(len(data)-sz) {
+		return "", nil, false
+	}
+	return string(data[sz : sz+int(n)]), data[sz+int(n):], true
+}
+
+// readBuildInfoString reads the Go string whose header is at addr.
+func readBuildInfoString(exe buildInfoExe, ptrSize int, readPtr func([]byte) uint64, addr uint64) string {
+	hdr, err := exe.readData(addr, uint64(2*ptrSize))
+	if err != nil || len(hdr) < 2*ptrSize {
+		return ""
+	}
+	dataAddr := readPtr(hdr)
+	dataLen := readPtr(hdr[ptrSize:])
+	const maxSize = 32 * 1024 * 1024
+	if dataLen > maxSize {
+		return ""
+	}
+	data, err := exe.readData(dataAddr, dataLen)
+	if err != nil || uint64(len(data)) < dataLen {
+		return ""
+	}
+	return string(data)
+}
+
+// parseModInfo parses the module information recorded by the linker,
+// returned by runtime/debug.ReadBuildInfo. Each line is made of fields
+// separated by tabs, the first one describes what the line is:
+//
+//	path	<main package path>
+//	mod	<module path>	<version>	<sum>
+//	dep	<module path>	<version>	<sum>
+//	=>	<module path>	<version>	<sum>
+//	build	<key>=<value>
+//
+// where "=>" lines replace the module described by the preceding line.
+func parseModInfo(mod string) (*BuildInfo, error) {
+	r := &BuildInfo{}
+	var last *Module
+	for lineno, line := range strings.Split(mod, "\n") {
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		malformed := func() error {
+			return fmt.Errorf("malformed build information at line %d: %q", lineno+1, line)
+		}
+		readModule := func() (*Module, error) {
+			if len(fields) != 3 && len(fields) != 4 {
+				return nil, malformed()
+			}
+			m := &Module{Path: fields[1], Version: fields[2]}
+			if len(fields) == 4 {
+				m.Sum = fields[3]
+			}
+			return m, nil
+		}
+		switch fields[0] {
+		case "path":
+			if len(fields) != 2 {
+				return nil, malformed()
+			}
+			r.Path = fields[1]
+		case "mod":
+			m, err := readModule()
+			if err != nil {
+				return nil, err
+			}
+			r.Main = *m
+			last = &r.Main
+		case "dep":
+			m, err := readModule()
+			if err != nil {
+				return nil, err
+			}
+			r.Deps = append(r.Deps, m)
+			last = m
+		case "=>":
+			m, err := readModule()
+			if err != nil {
+				return nil, err
+			}
+			if last == nil {
+				return nil, malformed()
+			}
+			last.Replace = m
+			last = nil
+		case "build":
+			setting, ok := parseBuildSetting(strings.TrimPrefix(line, "build\t"))
+			if !ok {
+				return nil, malformed()
+			}
+			r.Settings = append(r.Settings, setting)
+		}
+	}
+	return r, nil
+}
+
+// parseBuildSetting parses the key=value pair of a build line. Keys and
+// values that contain spaces, tabs, quotes or equal signs are quoted, see
+// runtime/debug.ParseBuildInfo.
+func parseBuildSetting(kv string) (BuildSetting, bool) {
+	var key, value string
+	switch {
+	case kv == "" || kv[0] == '=':
+		return BuildSetting{}, false
+	case kv[0] == '"' || kv[0] == '`':
+		// the quoted key ends at the first equal sign that follows a valid
+		// quoted string
+		found := false
+		for i := 1; i < len(kv); i++ {
+			if kv[i] != '=' {
+				continue
+			}
+			if k, err := strconv.Unquote(kv[:i]); err == nil {
+				key, value, found = k, kv[i+1:], true
+				break
+			}
+		}
+		if !found {
+			return BuildSetting{}, false
+		}
+	default:
+		i := strings.Index(kv, "=")
+		if i < 0 {
+			return BuildSetting{}, false
+		}
+		key, value = kv[:i], kv[i+1:]
+	}
+	if value != "" && (value[0] == '"' || value[0] == '`') {
+		var err error
+		if value, err = strconv.Unquote(value); err != nil {
+			return BuildSetting{}, false
+		}
+	}
+	return BuildSetting{Key: key, Value: value}, true
+}
+
+type elfBuildInfoExe struct {
+	*elf.File
+}
+
+func (x *elfBuildInfoExe) dataStart() (uint64, error) {
+	if sect := x.Section(".go.buildinfo"); sect != nil {
+		return sect.Addr, nil
+	}
+	for _, p := range x.Progs {
+		if p.Type == elf.PT_LOAD && p.Flags&(elf.PF_X|elf.PF_W) == elf.PF_W {
+			return p.Vaddr, nil
+		}
+	}
+	return 0, ErrNoBuildInfo
+}
+
+func (x *elfBuildInfoExe) readData(addr, size uint64) ([]byte, error) {
+	for _, p := range x.Progs {
+		if p.Type != elf.PT_LOAD || addr < p.Vaddr || addr >= p.Vaddr+p.Filesz {
+			continue
+		}
+		if n := p.Vaddr + p.Filesz - addr; n < size {
+			size = n
+		}
+		return readBuildInfoData(p, addr-p.Vaddr, size)
+	}
+	return nil, fmt.Errorf("address %#x not in executable", addr)
+}
+
+type peBuildInfoExe struct {
+	*pe.File
+}
+
+func (x *peBuildInfoExe) imageBase() uint64 {
+	switch oh := x.OptionalHeader.(type) {
+	case *pe.OptionalHeader32:
+		return uint64(oh.ImageBase)
+	case *pe.OptionalHeader64:
+		return oh.ImageBase
+	}
+	return 0
+}
+
+func (x *peBuildInfoExe) dataStart() (uint64, error) {
+	if sect := x.Section(".data"); sect != nil {
+		return x.imageBase() + uint64(sect.VirtualAddress), nil
+	}
+	return 0, ErrNoBuildInfo
+}
+
+func (x *peBuildInfoExe) readData(addr, size uint64) ([]byte, error) {
+	base := x.imageBase()
+	for _, sect := range x.Sections {
+		start := base + uint64(sect.VirtualAddress)
+		end := start + uint64(sect.Size)
+		if addr < start || addr >= end {
+			continue
+		}
+		if n := end - addr; n < size {
+			size = n
+		}
+		return readBuildInfoData(sect, addr-start, size)
+	}
+	return nil, fmt.Errorf("address %#x not in executable", addr)
+}
+
+type machoBuildInfoExe struct {
+	*macho.File
+}
+
+func (x *machoBuildInfoExe) dataStart() (uint64, error) {
+	if sect := x.Section("__go_buildinfo"); sect != nil {
+		return sect.Addr, nil
+	}
+	if sect := x.Section("__data"); sect != nil {
+		return sect.Addr, nil
+	}
+	return 0, ErrNoBuildInfo
+}
+
+func (x *machoBuildInfoExe) readData(addr, size uint64) ([]byte, error) {
+	for _, sect := range x.Sections {
+		if addr < sect.Addr || addr >= sect.Addr+sect.Size {
+			continue
+		}
+		if n := sect.Addr + sect.Size - addr; n < size {
+			size = n
+		}
+		return readBuildInfoData(sect, addr-sect.Addr, size)
+	}
+	return nil, fmt.Errorf("address %#x not in executable", addr)
+}
+
+func readBuildInfoData(r io.ReaderAt, off, size uint64) ([]byte, error) {
+	buf := make([]byte, size)
+	n, err := r.ReadAt(buf, int64(off))
+	if n > 0 && err == io.EOF {
+		err = nil
+	}
+	return buf[:n], err
+}
@@ -0,0 +1,168 @@
+package proc
+
+import (
+	"errors"
+	"fmt"
+	"go/constant"
+	"reflect"
+
+	"github.com/go-delve/delve/pkg/dwarf/godwarf"
+)
+
+// maxChanWaiters is the maximum number of goroutines read from the wait
+// queues of a channel, to protect against corrupted memory.
+const maxChanWaiters = 100000
+
+// ChanState describes the state of a channel.
+type ChanState struct {
+	Addr   uint64
+	Len    int64
+	Cap    int64
+	Closed bool
+	// Buffer are the elements in the buffer of the channel, in the order
+	// they will be received.
+	Buffer []*Variable
+	// Senders and Receivers are the IDs of the goroutines blocked sending
+	// to and receiving from the channel, in the order they will be woken.
+	Senders   []int
+	Receivers []int
+}
+
+// ChanInfo decodes the state of v, which must be a channel or a pointer to
+// a channel: the elements in its buffer are read starting at recvx, see
+// $GOROOT/src/runtime/chan.go, and the goroutines parked on it are read
+// from its wait queues. At most cfg.MaxArrayValues elements of the buffer
+// are loaded.
+func (t *Target) ChanInfo(v *Variable, cfg LoadConfig) (*ChanState, error) {
+	for v.Kind == reflect.Ptr {
+		v = v.maybeDereference()
+		if v.Unreadable != nil {
+			return nil, v.Unreadable
+		}
+		if v.Addr == 0 {
+			return nil, errors.New("nil pointer")
+		}
+	}
+	if v.Unreadable != nil {
+		return nil, v.Unreadable
+	}
+	chanType, ok := v.RealType.(*godwarf.ChanType)
+	if v.Kind != reflect.Chan || !ok {
+		return nil, fmt.Errorf("%s is not a channel", v.TypeString())
+	}
+	hchan := v.clone()
+	hchan.RealType = resolveTypedef(&(chanType.TypedefType))
+	hchan = hchan.maybeDereference()
+	if hchan.Unreadable != nil {
+		return nil, hchan.Unreadable
+	}
+	if hchan.Addr == 0 {
+		return nil, errors.New("nil channel")
+	}
+
+	r := &ChanState{Addr: hchan.Addr}
+	var err error
+	if r.Len, err = intField(hchan, "qcount"); err != nil {
+		return nil, err
+	}
+	if r.Cap, err = intField(hchan, "dataqsiz"); err != nil {
+		return nil, err
+	}
+	closed, err := intField(hchan, "closed")
+	if err != nil {
+		return nil, err
+	}
+	r.Closed = closed != 0
+	recvx, err := intField(hchan, "recvx")
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Len > 0 {
+		if r.Len > r.Cap || recvx >= r.Cap {
+			return nil, fmt.Errorf("corrupted channel: %d elements, capacity %d, recvx %d", r.Len, r.Cap, recvx)
+		}
+		buf, err := hchan.structMember("buf")
+		if err != nil {
+			return nil, err
+		}
+		// loadChanInfo changed the type of buf to a pointer to an array of
+		// dataqsiz elements
+		buf = buf.maybeDereference()
+		if buf.Unreadable != nil {
+			return nil, buf.Unreadable
+		}
+		n := r.Len
+		if n > int64(cfg.MaxArrayValues) {
+			n = int64(cfg.MaxArrayValues)
+		}
+		for i := int64(0); i < n; i++ {
+			elem, err := buf.sliceAccess(int((recvx + i) % r.Cap))
+			if err != nil {
+				return nil, err
+			}
+			elem.loadValue(cfg)
+			r.Buffer = append(r.Buffer, elem)
+		}
+	}
+
+	if r.Senders, err = chanWaiters(hchan, "sendq"); err != nil {
+		return nil, err
+	}
+	if r.Receivers, err = chanWaiters(hchan, "recvq"); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// chanWaiters returns the IDs of the goroutines in the wait queue name of
+// hchan, the sudogs of a runtime.waitq are linked through their next
+// field.
+func chanWaiters(hchan *Variable, name string) ([]int, error) {
+	q, err := hchan.structMember(name)
+	if err != nil {
+		return nil, err
+	}
+	first, err := q.structMember("first")
+	if err != nil {
+		return nil, err
+	}
+	ptrtyp, ok := first.RealType.(*godwarf.PtrType)
+	if !ok {
+		return nil, errors.New("unknown type of runtime.waitq")
+	}
+	sudogType := ptrtyp.Type
+	bi, mem := hchan.bi, hchan.mem
+	ptrSize := int64(bi.Arch.PtrSize())
+
+	var r []int
+	w, err := readUintRaw(mem, first.Addr, ptrSize)
+	if err != nil {
+		return nil, err
+	}
+	for w != 0 {
+		if len(r) >= maxChanWaiters {
+			return nil, errors.New("too many channel waiters")
+		}
+		sudog := newVariable("", w, sudogType, bi, mem)
+		g, err := sudog.structMember("g")
+		if err != nil {
+			return nil, err
+		}
+		g = g.maybeDereference()
+		goid := g.loadFieldNamed("goid")
+		if goid == nil {
+			return nil, errors.New("unreadable goroutine")
+		}
+		id, _ := constant.Int64Val(goid.Value)
+		r = append(r, int(id))
+		next, err := sudog.structMember("next")
+		if err != nil {
+			return nil, err
+		}
+		if w, err = readUintRaw(mem, next.Addr, ptrSize); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
@@ -280,6 +280,12 @@ func (p *process) ReadMemory(data []byte, addr uint64) (n int, err error) {
 	return n, err
 }
 
+// ConcurrentReads returns true, the memory of a core file is read from
+// files or from memory.
+func (p *process) ConcurrentReads() bool {
+	return true
+}
+
 // WriteMemory will only return an error for core files, you cannot write
 // to the memory of a core process.
 func (p *process) WriteMemory(addr uint64, data []byte) (int, error) {
@@ -468,6 +474,17 @@ func (p *process) MemoryMap() ([]proc.MemoryMapEntry, error) {
 	return nil, proc.ErrMemoryMapNotSupported
 }
 
+// StartBranchTrace returns ErrBranchTraceNotSupported, core files do not
+// contain branch traces.
+func (p *process) StartBranchTrace() error {
+	return proc.ErrBranchTraceNotSupported
+}
+
+// BranchTrace returns ErrBranchTraceNotStarted.
+func (p *process) BranchTrace(threadID int) ([]byte, error) {
+	return nil, proc.ErrBranchTraceNotStarted
+}
+
 func (p *process) DumpProcessNotes(notes []elfwriter.Note, threadDone func()) (threadsDone bool, out []elfwriter.Note, err error) {
 	return false, notes, nil
 }
@@ -0,0 +1,150 @@
+package proc
+
+import (
+	"errors"
+	"sort"
+)
+
+// CoverageLine is a source line instrumented by StartCoverage.
+type CoverageLine struct {
+	File     string
+	Line     int
+	Executed bool
+}
+
+// coverageState is the state of the coverage collection started by
+// StartCoverage.
+type coverageState struct {
+	// lines maps every instrumented line to true once it is executed.
+	lines map[coverageKey]bool
+	// pending maps the address of every coverage breaklet that wasn't hit
+	// yet to the breaklet.
+	pending map[uint64]*Breaklet
+}
+
+type coverageKey struct {
+	file string
+	line int
+}
+
+// StartCoverage starts recording which lines of the source files selected
+// by filter are executed: a breakpoint, that does not stop the target, is
+// set on every statement of the functions defined in those files and
+// cleared the first time it is hit. Returns the number of instrumented
+// lines. Any previously collected coverage is discarded.
+func (t *Target) StartCoverage(filter func(file string) bool) (int, error) {
+	if _, err := t.Valid(); err != nil {
+		return 0, err
+	}
+	if err := t.StopCoverage(); err != nil {
+		return 0, err
+	}
+	bi := t.BinInfo()
+	cov := &coverageState{lines: make(map[coverageKey]bool), pending: make(map[uint64]*Breaklet)}
+	t.coverage = cov
+	for i := range bi.Functions {
+		fn := &bi.Functions[i]
+		if fn.Entry == 0 || fn.cu == nil || fn.cu.lineInfo == nil {
+			continue
+		}
+		if file, _, _ := bi.PCToLine(fn.Entry); file == "<autogenerated>" || !filter(file) {
+			continue
+		}
+		pcs, err := fn.cu.lineInfo.AllPCsBetween(fn.Entry, fn.End-1, "", 0)
+		if err != nil {
+			continue
+		}
+		for _, pc := range pcs {
+			if _, ok := cov.pending[pc]; ok {
+				continue
+			}
+			file, line, _ := bi.PCToLine(pc)
+			if file == "" || !filter(file) {
+				continue
+			}
+			bp, err := t.SetBreakpoint(pc, CoverageBreakpoint, nil)
+			if err != nil {
+				t.StopCoverage()
+				return 0, err
+			}
+			cov.lines[coverageKey{file, line}] = false
+			cov.pending[pc] = bp.Breaklets[len(bp.Breaklets)-1]
+		}
+	}
+	return len(cov.lines), nil
+}
+
+// StopCoverage clears the breakpoints set by StartCoverage, the coverage
+// collected so far is still returned by Coverage.
+func (t *Target) StopCoverage() error {
+	if t.coverage == nil {
+		return nil
+	}
+	var err error
+	for addr := range t.coverage.pending {
+		if err1 := t.clearCoverageBreaklet(addr); err1 != nil && err == nil {
+			err = err1
+		}
+	}
+	return err
+}
+
+// Coverage returns the lines instrumented by StartCoverage, sorted by file
+// and line number.
+func (t *Target) Coverage() ([]CoverageLine, error) {
+	if t.coverage == nil {
+		return nil, errors.New("coverage collection not started")
+	}
+	r := make([]CoverageLine, 0, len(t.coverage.lines))
+	for k, executed := range t.coverage.lines {
+		r = append(r, CoverageLine{File: k.file, Line: k.line, Executed: executed})
+	}
+	sort.Slice(r, func(i, j int) bool {
+		if r[i].File != r[j].File {
+			return r[i].File < r[j].File
+		}
+		return r[i].Line < r[j].Line
+	})
+	return r, nil
+}
+
+// recordCoverage marks as executed the lines of the coverage breakpoints
+// hit by threads and clears them, so that they do not slow down the target
+// any further.
+func (t *Target) recordCoverage(threads []Thread) error {
+	if t.coverage == nil || len(t.coverage.pending) == 0 {
+		return nil
+	}
+	for _, th := range threads {
+		bpstate := th.Breakpoint()
+		if bpstate.Breakpoint == nil || !bpstate.Covered {
+			continue
+		}
+		bp := bpstate.Breakpoint
+		if _, ok := t.coverage.pending[bp.Addr]; !ok {
+			continue
+		}
+		t.coverage.lines[coverageKey{bp.File, bp.Line}] = true
+		if err := t.clearCoverageBreaklet(bp.Addr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// clearCoverageBreaklet removes the coverage breaklet at addr.
+func (t *Target) clearCoverageBreaklet(addr uint64) error {
+	breaklet := t.coverage.pending[addr]
+	delete(t.coverage.pending, addr)
+	bp := t.Breakpoints().M[addr]
+	if bp == nil {
+		return nil
+	}
+	for i := range bp.Breaklets {
+		if bp.Breaklets[i] == breaklet {
+			bp.Breaklets[i] = nil
+		}
+	}
+	_, err := t.finishClearBreakpoint(bp)
+	return err
+}
@@ -10,6 +10,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"go/constant"
+	"strings"
 	"testing"
 	"unsafe"
 
@@ -325,6 +326,47 @@ func TestIssue1419(t *testing.T) {
 	}
 }
 
+func TestUnreadableLocals(t *testing.T) {
+	// Variables that can not be read are listed with the reason, instead of
+	// being left out or hiding the other variables.
+	dwb := dwarfbuilder.New()
+
+	intoff := dwb.AddBaseType("int", dwarfbuilder.DW_ATE_signed, 8)
+
+	dwb.AddSubprogram("main.main", 0x40100, 0x41000)
+	dwb.AddVariable("a", intoff, dwarfbuilder.LocationBlock(op.DW_OP_call_frame_cfa))
+	dwb.AddVariable("b", intoff, dwarfbuilder.LocationBlock(op.DW_OP_form_tls_address))
+	dwb.AddVariable("c", dwarf.Offset(0xffffff), dwarfbuilder.LocationBlock(op.DW_OP_call_frame_cfa))
+	dwb.TagClose()
+
+	bi, _ := fakeBinaryInfo(t, dwb)
+
+	mainfn := bi.LookupFunc["main.main"]
+
+	mem := newFakeMemory(fakeCFA(), uint64(0x1234))
+	regs := linutil.AMD64Registers{Regs: &linutil.AMD64PtraceRegs{}}
+	regs.Regs.Rip = 0x40100
+	regs.Regs.Rsp = fakeCFA()
+
+	scope := &proc.EvalScope{Location: proc.Location{PC: 0x40100, Fn: mainfn}, Regs: *dwarfRegisters(bi, &regs), Mem: mem, BinInfo: bi}
+
+	vars, err := scope.Locals()
+	assertNoError(err, t, "Locals()")
+	found := map[string]*proc.Variable{}
+	for _, v := range vars {
+		found[v.Name] = v
+	}
+	if a := found["a"]; a == nil || a.Unreadable != nil {
+		t.Errorf("variable 'a' missing or unreadable: %v", a)
+	}
+	if b := found["b"]; b == nil || b.Unreadable == nil || !strings.Contains(b.Unreadable.Error(), "unsupported location expression") {
+		t.Errorf("wrong unreadable reason for variable 'b': %v", b)
+	}
+	if c := found["c"]; c == nil || c.Unreadable == nil || !strings.Contains(c.Unreadable.Error(), "could not read debug info") {
+		t.Errorf("wrong unreadable reason for variable 'c': %v", c)
+	}
+}
+
 func TestLocationCovers(t *testing.T) {
 	dwb := dwarfbuilder.New()
 
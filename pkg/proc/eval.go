@@ -1,25 +1,24 @@
 package proc
 
 import (
-	"bytes"
 	"debug/dwarf"
 	"errors"
 	"fmt"
 	"go/ast"
 	"go/constant"
-	"go/parser"
-	"go/printer"
-	"go/scanner"
 	"go/token"
 	"reflect"
+	"runtime"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/go-delve/delve/pkg/dwarf/godwarf"
 	"github.com/go-delve/delve/pkg/dwarf/op"
 	"github.com/go-delve/delve/pkg/dwarf/reader"
 	"github.com/go-delve/delve/pkg/goversion"
+	"github.com/go-delve/delve/pkg/proc/evalexpr"
 )
 
 var errOperationOnSpecialFloat = errors.New("operations on non-finite floats not implemented")
@@ -163,8 +162,17 @@ func (scope *EvalScope) EvalExpression(expr string, cfg LoadConfig) (*Variable,
 		// makes sure that the other goroutine won't wait forever if we make a mistake
 		defer close(scope.callCtx.continueRequest)
 	}
-	t, err := parser.ParseExpr(expr)
-	if eqOff, isAs := isAssignment(err); scope.callCtx != nil && isAs {
+	if scope.target != nil && scope.target.opctx != nil {
+		// Loading a large value can take a long time, reads fail once the
+		// operation is canceled so that it stops early.
+		mem := scope.Mem
+		scope.Mem = &cancelableMemory{mem, scope.target}
+		defer func() {
+			scope.Mem = mem
+		}()
+	}
+	t, err := evalexpr.Parse(expr)
+	if eqOff, isAs := evalexpr.IsAssignment(err); scope.callCtx != nil && isAs {
 		lexpr := expr[:eqOff]
 		rexpr := expr[eqOff+1:]
 		err := scope.SetVariable(lexpr, rexpr)
@@ -185,6 +193,12 @@ func (scope *EvalScope) EvalExpression(expr string, cfg LoadConfig) (*Variable,
 		return nil, err
 	}
 	ev.loadValue(cfg)
+	if scope.target != nil {
+		if err := scope.target.OperationCanceled(); err != nil {
+			scope.callCtx.doReturn(nil, err)
+			return nil, err
+		}
+	}
 	if ev.Name == "" {
 		ev.Name = expr
 	}
@@ -192,14 +206,6 @@ func (scope *EvalScope) EvalExpression(expr string, cfg LoadConfig) (*Variable,
 	return ev, nil
 }
 
-func isAssignment(err error) (int, bool) {
-	el, isScannerErr := err.(scanner.ErrorList)
-	if isScannerErr && el[0].Msg == "expected '==', found '='" {
-		return el[0].Pos.Offset, true
-	}
-	return 0, false
-}
-
 // Locals returns all variables in 'scope'.
 func (scope *EvalScope) Locals() ([]*Variable, error) {
 	if scope.Fn == nil {
@@ -224,10 +230,13 @@ func (scope *EvalScope) Locals() ([]*Variable, error) {
 	for _, entry := range varEntries {
 		val, err := extractVarInfoFromEntry(scope.target, scope.BinInfo, scope.image(), scope.Regs, scope.Mem, entry.Tree)
 		if err != nil {
-			// skip variables that we can't parse yet
-			continue
-		}
-		if trustArgOrder && ((val.Unreadable != nil && val.Addr == 0) || val.Flags&VariableFakeAddress != 0) && entry.Tag == dwarf.TagFormalParameter {
+			// report variables that we can't parse yet instead of hiding them
+			name, _ := entry.Val(dwarf.AttrName).(string)
+			if name == "" {
+				continue
+			}
+			val = unreadableVariable(name, err, scope.BinInfo, scope.Mem)
+		} else if trustArgOrder && ((val.Unreadable != nil && val.Addr == 0) || val.Flags&VariableFakeAddress != 0) && entry.Tag == dwarf.TagFormalParameter {
 			addr := afterLastArgAddr(vars)
 			if addr == 0 {
 				addr = uint64(scope.Regs.CFA)
@@ -251,6 +260,8 @@ func (scope *EvalScope) Locals() ([]*Variable, error) {
 		depths = append(depths, depth)
 	}
 
+	vars, depths = scope.resolveParametricTypes(vars, depths)
+
 	if len(vars) <= 0 {
 		return vars, nil
 	}
@@ -286,6 +297,39 @@ func (scope *EvalScope) Locals() ([]*Variable, error) {
 	return vars, nil
 }
 
+// resolveParametricTypes removes the dictionary argument of instantiations
+// of generic functions from vars and replaces the types of the variables
+// that use type parameters with the concrete types read from the
+// dictionary. If the dictionary can't be read the shape types used by the
+// compiler are kept.
+func (scope *EvalScope) resolveParametricTypes(vars []*Variable, depths []int) ([]*Variable, []int) {
+	var dictAddr uint64
+	for i, v := range vars {
+		if v.Name != ".dict" {
+			continue
+		}
+		if v.Unreadable == nil {
+			dictAddr, _ = readUintRaw(v.mem, v.Addr, int64(scope.BinInfo.Arch.PtrSize()))
+		}
+		vars = append(vars[:i], vars[i+1:]...)
+		depths = append(depths[:i], depths[i+1:]...)
+		break
+	}
+	for i, v := range vars {
+		typ := resolveParametricType(scope.BinInfo, scope.Mem, v.DwarfType, dictAddr)
+		if typ == v.DwarfType {
+			continue
+		}
+		nv := newVariable(v.Name, v.Addr, typ, scope.BinInfo, v.mem)
+		nv.Flags = v.Flags
+		nv.LocationExpr = v.LocationExpr
+		nv.DeclLine = v.DeclLine
+		nv.Unreadable = v.Unreadable
+		vars[i] = nv
+	}
+	return vars, depths
+}
+
 func afterLastArgAddr(vars []*Variable) uint64 {
 	for i := len(vars) - 1; i >= 0; i-- {
 		v := vars[i]
@@ -306,6 +350,9 @@ func afterLastArgAddr(vars []*Variable) uint64 {
 //   non-empty) or a pointer shaped type (map, channel, pointer or struct
 //   containing a single pointer field) the type conversion to "interface {}"
 //   is performed.
+// * If dstv is a non-empty interface and srcv is a pointer shaped type the
+//   type conversion is performed, provided that the target program contains
+//   the same conversion.
 // * If srcv and dstv have the same type and are both addressable then the
 //   contents of srcv are copied byte-by-byte into dstv
 func (scope *EvalScope) setValue(dstv, srcv *Variable, srcExpr string) error {
@@ -313,7 +360,8 @@ func (scope *EvalScope) setValue(dstv, srcv *Variable, srcExpr string) error {
 
 	typerr := srcv.isType(dstv.RealType, dstv.Kind)
 	if _, isTypeConvErr := typerr.(*typeConvErr); isTypeConvErr {
-		// attempt iface -> eface and ptr-shaped -> eface conversions.
+		// attempt iface -> eface, ptr-shaped -> eface and ptr-shaped -> iface
+		// conversions.
 		return convertToEface(srcv, dstv)
 	}
 	if typerr != nil {
@@ -381,7 +429,7 @@ func (scope *EvalScope) EvalVariable(name string, cfg LoadConfig) (*Variable, er
 
 // SetVariable sets the value of the named variable
 func (scope *EvalScope) SetVariable(name, value string) error {
-	t, err := parser.ParseExpr(name)
+	t, err := evalexpr.Parse(name)
 	if err != nil {
 		return err
 	}
@@ -399,7 +447,7 @@ func (scope *EvalScope) SetVariable(name, value string) error {
 		return fmt.Errorf("Expression \"%s\" is unreadable: %v", name, xv.Unreadable)
 	}
 
-	t, err = parser.ParseExpr(value)
+	t, err = evalexpr.Parse(value)
 	if err != nil {
 		return err
 	}
@@ -455,18 +503,31 @@ func regsReplaceStaticBase(regs op.DwarfRegisters, image *Image) op.DwarfRegiste
 	return regs
 }
 
-// PackageVariables returns the name, value, and type of all package variables in the application.
-func (scope *EvalScope) PackageVariables(cfg LoadConfig) ([]*Variable, error) {
-	pkgvars := make([]packageVar, len(scope.BinInfo.packageVars))
-	copy(pkgvars, scope.BinInfo.packageVars)
+// PackageVariables returns the name, value, and type of all package
+// variables in the application accepted by filter. If filter is nil all
+// package variables are returned.
+// Filtering happens before any debug_info entry is read so that listing a
+// small subset of variables is cheap even on large binaries.
+func (scope *EvalScope) PackageVariables(filter func(name string) bool, cfg LoadConfig) ([]*Variable, error) {
+	pkgvars := make([]packageVar, 0, len(scope.BinInfo.packageVars))
+	for _, pkgvar := range scope.BinInfo.packageVars {
+		if filter == nil || filter(pkgvar.name) {
+			pkgvars = append(pkgvars, pkgvar)
+		}
+	}
 	sort.Slice(pkgvars, func(i, j int) bool {
 		if pkgvars[i].cu.image.addr == pkgvars[j].cu.image.addr {
 			return pkgvars[i].offset < pkgvars[j].offset
 		}
 		return pkgvars[i].cu.image.addr < pkgvars[j].cu.image.addr
 	})
-	vars := make([]*Variable, 0, len(scope.BinInfo.packageVars))
+	vars := make([]*Variable, 0, len(pkgvars))
 	for _, pkgvar := range pkgvars {
+		if scope.target != nil {
+			if err := scope.target.OperationCanceled(); err != nil {
+				return nil, err
+			}
+		}
 		reader := pkgvar.cu.image.dwarfReader
 		reader.Seek(pkgvar.offset)
 		entry, err := reader.Next()
@@ -482,13 +543,104 @@ func (scope *EvalScope) PackageVariables(cfg LoadConfig) ([]*Variable, error) {
 		if err != nil {
 			continue
 		}
-		val.loadValue(cfg)
 		vars = append(vars, val)
 	}
 
+	loadPackageVarsGroups(cachePackageVariables(vars))
+	for _, val := range vars {
+		val.loadValue(cfg)
+	}
+
 	return vars, nil
 }
 
+// packageVarsCacheGap is the maximum distance between two package variables
+// that will be read from target memory with a single request.
+const packageVarsCacheGap = 4 * 1024
+
+// packageVarsCacheMax is the maximum size of a single memory read issued by
+// cachePackageVariables.
+const packageVarsCacheMax = 1024 * 1024
+
+// cachePackageVariables groups variables that are close to each other in
+// memory so that each group is read with a single request, global
+// variables are laid out contiguously in the data and bss sections so this
+// reduces the number of round trips to the target from one per variable
+// to a handful. The memory of each group is read the first time one of its
+// variables is loaded, or by loadPackageVarsGroups.
+func cachePackageVariables(vars []*Variable) []*packageVarsGroup {
+	byaddr := make([]*Variable, 0, len(vars))
+	for _, v := range vars {
+		if v.Unreadable != nil || v.Addr == 0 || v.Flags&VariableFakeAddress != 0 || v.RealType == nil {
+			continue
+		}
+		if _, iscomposite := v.mem.(*compositeMemory); iscomposite {
+			continue
+		}
+		byaddr = append(byaddr, v)
+	}
+	sort.Slice(byaddr, func(i, j int) bool { return byaddr[i].Addr < byaddr[j].Addr })
+
+	var groups []*packageVarsGroup
+	for len(byaddr) > 0 {
+		start := byaddr[0].Addr
+		end := start + uint64(byaddr[0].RealType.Size())
+		n := 1
+		for ; n < len(byaddr); n++ {
+			v := byaddr[n]
+			vend := v.Addr + uint64(v.RealType.Size())
+			if v.mem != byaddr[0].mem || v.Addr > end+packageVarsCacheGap || vend-start > packageVarsCacheMax {
+				break
+			}
+			if vend > end {
+				end = vend
+			}
+		}
+		if n > 1 && cacheEnabled {
+			group := &packageVarsGroup{addr: start, buf: make([]byte, end-start), mem: byaddr[0].mem}
+			for _, v := range byaddr[:n] {
+				v.mem = &packageVarMemory{v.mem, group}
+			}
+			groups = append(groups, group)
+		}
+		byaddr = byaddr[n:]
+	}
+	return groups
+}
+
+// loadPackageVarsGroups reads the memory of the groups created by
+// cachePackageVariables, concurrently, if the memory of the target can be
+// read from multiple goroutines. Otherwise groups are read lazily, the
+// first time one of their variables is loaded.
+func loadPackageVarsGroups(groups []*packageVarsGroup) {
+	concurrent := make([]*packageVarsGroup, 0, len(groups))
+	for _, group := range groups {
+		if concurrentReads(group.mem) {
+			concurrent = append(concurrent, group)
+		}
+	}
+	work := make(chan *packageVarsGroup)
+	var wg sync.WaitGroup
+	workers := runtime.NumCPU()
+	if workers > len(concurrent) {
+		workers = len(concurrent)
+	}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for group := range work {
+				group.load()
+			}
+		}()
+	}
+	for _, group := range concurrent {
+		work <- group
+	}
+	close(work)
+	wg.Wait()
+}
+
 func (scope *EvalScope) findGlobal(pkgName, varName string) (*Variable, error) {
 	for _, pkgPath := range scope.BinInfo.PackageMap[pkgName] {
 		v, err := scope.findGlobalInternal(pkgPath + "." + varName)
@@ -576,7 +728,7 @@ func (scope *EvalScope) evalToplevelTypeCast(t ast.Expr, cfg LoadConfig) (*Varia
 	if call == nil || len(call.Args) != 1 {
 		return nil, nil
 	}
-	targetTypeStr := exprToString(removeParen(call.Fun))
+	targetTypeStr := evalexpr.ExprToString(evalexpr.RemoveParen(call.Fun))
 	var targetType godwarf.Type
 	switch targetTypeStr {
 	case "[]byte", "[]uint8":
@@ -608,7 +760,7 @@ func (scope *EvalScope) evalToplevelTypeCast(t ast.Expr, cfg LoadConfig) (*Varia
 	v := newVariable("", 0, targetType, scope.BinInfo, scope.Mem)
 	v.loaded = true
 
-	converr := fmt.Errorf("can not convert %q to %s", exprToString(call.Args[0]), targetTypeStr)
+	converr := fmt.Errorf("can not convert %q to %s", evalexpr.ExprToString(call.Args[0]), targetTypeStr)
 
 	switch targetTypeStr {
 	case "[]byte", "[]uint8":
@@ -787,23 +939,6 @@ func (scope *EvalScope) evalAST(t ast.Expr) (*Variable, error) {
 	}
 }
 
-func exprToString(t ast.Expr) string {
-	var buf bytes.Buffer
-	printer.Fprint(&buf, token.NewFileSet(), t)
-	return buf.String()
-}
-
-func removeParen(n ast.Expr) ast.Expr {
-	for {
-		p, ok := n.(*ast.ParenExpr)
-		if !ok {
-			break
-		}
-		n = p.X
-	}
-	return n
-}
-
 // Eval type cast expressions
 func (scope *EvalScope) evalTypeCast(node *ast.CallExpr) (*Variable, error) {
 	argv, err := scope.evalAST(node.Args[0])
@@ -818,7 +953,7 @@ func (scope *EvalScope) evalTypeCast(node *ast.CallExpr) (*Variable, error) {
 	fnnode := node.Fun
 
 	// remove all enclosing parenthesis from the type name
-	fnnode = removeParen(fnnode)
+	fnnode = evalexpr.RemoveParen(fnnode)
 
 	styp, err := scope.BinInfo.findTypeExpr(fnnode)
 	if err != nil {
@@ -826,7 +961,7 @@ func (scope *EvalScope) evalTypeCast(node *ast.CallExpr) (*Variable, error) {
 	}
 	typ := resolveTypedef(styp)
 
-	converr := fmt.Errorf("can not convert %q to %s", exprToString(node.Args[0]), typ.String())
+	converr := fmt.Errorf("can not convert %q to %s", evalexpr.ExprToString(node.Args[0]), typ.String())
 
 	v := newVariable("", 0, styp, scope.BinInfo, scope.Mem)
 	v.loaded = true
@@ -859,11 +994,11 @@ func (scope *EvalScope) evalTypeCast(node *ast.CallExpr) (*Variable, error) {
 		switch argv.Kind {
 		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 			n, _ := constant.Int64Val(argv.Value)
-			v.Value = constant.MakeUint64(convertInt(uint64(n), false, ttyp.Size()))
+			v.Value = constant.MakeUint64(evalexpr.ConvertInt(uint64(n), false, ttyp.Size()))
 			return v, nil
 		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 			n, _ := constant.Uint64Val(argv.Value)
-			v.Value = constant.MakeUint64(convertInt(n, false, ttyp.Size()))
+			v.Value = constant.MakeUint64(evalexpr.ConvertInt(n, false, ttyp.Size()))
 			return v, nil
 		case reflect.Float32, reflect.Float64:
 			x, _ := constant.Float64Val(argv.Value)
@@ -877,11 +1012,11 @@ func (scope *EvalScope) evalTypeCast(node *ast.CallExpr) (*Variable, error) {
 		switch argv.Kind {
 		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 			n, _ := constant.Int64Val(argv.Value)
-			v.Value = constant.MakeInt64(int64(convertInt(uint64(n), true, ttyp.Size())))
+			v.Value = constant.MakeInt64(int64(evalexpr.ConvertInt(uint64(n), true, ttyp.Size())))
 			return v, nil
 		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 			n, _ := constant.Uint64Val(argv.Value)
-			v.Value = constant.MakeInt64(int64(convertInt(n, true, ttyp.Size())))
+			v.Value = constant.MakeInt64(int64(evalexpr.ConvertInt(n, true, ttyp.Size())))
 			return v, nil
 		case reflect.Float32, reflect.Float64:
 			x, _ := constant.Float64Val(argv.Value)
@@ -913,17 +1048,6 @@ func (scope *EvalScope) evalTypeCast(node *ast.CallExpr) (*Variable, error) {
 	return nil, converr
 }
 
-func convertInt(n uint64, signed bool, size int64) uint64 {
-	bits := uint64(size) * 8
-	mask := uint64((1 << bits) - 1)
-	r := n & mask
-	if signed && (r>>(bits-1)) != 0 {
-		// sign extension
-		r |= ^uint64(0) &^ mask
-	}
-	return r
-}
-
 func (scope *EvalScope) evalBuiltinCall(node *ast.CallExpr) (*Variable, error) {
 	fnnode, ok := node.Fun.(*ast.Ident)
 	if !ok {
@@ -966,7 +1090,7 @@ func capBuiltin(args []*Variable, nodeargs []ast.Expr) (*Variable, error) {
 	}
 
 	arg := args[0]
-	invalidArgErr := fmt.Errorf("invalid argument %s (type %s) for cap", exprToString(nodeargs[0]), arg.TypeString())
+	invalidArgErr := fmt.Errorf("invalid argument %s (type %s) for cap", evalexpr.ExprToString(nodeargs[0]), arg.TypeString())
 
 	switch arg.Kind {
 	case reflect.Ptr:
@@ -998,7 +1122,7 @@ func lenBuiltin(args []*Variable, nodeargs []ast.Expr) (*Variable, error) {
 		return nil, fmt.Errorf("wrong number of arguments to len: %d", len(args))
 	}
 	arg := args[0]
-	invalidArgErr := fmt.Errorf("invalid argument %s (type %s) for len", exprToString(nodeargs[0]), arg.TypeString())
+	invalidArgErr := fmt.Errorf("invalid argument %s (type %s) for len", evalexpr.ExprToString(nodeargs[0]), arg.TypeString())
 
 	switch arg.Kind {
 	case reflect.Ptr:
@@ -1055,11 +1179,11 @@ func complexBuiltin(args []*Variable, nodeargs []ast.Expr) (*Variable, error) {
 	}
 
 	if realev.Value == nil || ((realev.Value.Kind() != constant.Int) && (realev.Value.Kind() != constant.Float)) {
-		return nil, fmt.Errorf("invalid argument 1 %s (type %s) to complex", exprToString(nodeargs[0]), realev.TypeString())
+		return nil, fmt.Errorf("invalid argument 1 %s (type %s) to complex", evalexpr.ExprToString(nodeargs[0]), realev.TypeString())
 	}
 
 	if imagev.Value == nil || ((imagev.Value.Kind() != constant.Int) && (imagev.Value.Kind() != constant.Float)) {
-		return nil, fmt.Errorf("invalid argument 2 %s (type %s) to complex", exprToString(nodeargs[1]), imagev.TypeString())
+		return nil, fmt.Errorf("invalid argument 2 %s (type %s) to complex", evalexpr.ExprToString(nodeargs[1]), imagev.TypeString())
 	}
 
 	sz := int64(0)
@@ -1097,7 +1221,7 @@ func imagBuiltin(args []*Variable, nodeargs []ast.Expr) (*Variable, error) {
 	}
 
 	if arg.Kind != reflect.Complex64 && arg.Kind != reflect.Complex128 {
-		return nil, fmt.Errorf("invalid argument %s (type %s) to imag", exprToString(nodeargs[0]), arg.TypeString())
+		return nil, fmt.Errorf("invalid argument %s (type %s) to imag", evalexpr.ExprToString(nodeargs[0]), arg.TypeString())
 	}
 
 	return newConstant(constant.Imag(arg.Value), arg.mem), nil
@@ -1116,7 +1240,7 @@ func realBuiltin(args []*Variable, nodeargs []ast.Expr) (*Variable, error) {
 	}
 
 	if arg.Value == nil || ((arg.Value.Kind() != constant.Int) && (arg.Value.Kind() != constant.Float) && (arg.Value.Kind() != constant.Complex)) {
-		return nil, fmt.Errorf("invalid argument %s (type %s) to real", exprToString(nodeargs[0]), arg.TypeString())
+		return nil, fmt.Errorf("invalid argument %s (type %s) to real", evalexpr.ExprToString(nodeargs[0]), arg.TypeString())
 	}
 
 	return newConstant(constant.Real(arg.Value), arg.mem), nil
@@ -1201,14 +1325,26 @@ func (scope *EvalScope) evalStructSelector(node *ast.SelectorExpr) (*Variable, e
 		return xv.registerVariableTypeConv(node.Sel.Name)
 	}
 
-	rv, err := xv.findMethod(node.Sel.Name)
+	// Fields and methods at a shallower depth hide the ones at deeper
+	// depths, like the Go selector rules.
+	rv, mdepth, err := xv.findMethodDepth(node.Sel.Name)
 	if err != nil {
 		return nil, err
 	}
-	if rv != nil {
+	if rv == nil {
+		return xv.structMember(node.Sel.Name)
+	}
+	if xv.Unreadable != nil || rv.Unreadable != nil {
 		return rv, nil
 	}
-	return xv.structMember(node.Sel.Name)
+	fv, fdepth, err := xv.structMemberDepth(node.Sel.Name)
+	switch {
+	case err != nil || fdepth > mdepth:
+		return rv, nil
+	case fdepth == mdepth:
+		return nil, fmt.Errorf("ambiguous selector %s.%s", xv.Name, node.Sel.Name)
+	}
+	return fv, nil
 }
 
 // Evaluates expressions <subexpr>.(<type>)
@@ -1218,7 +1354,7 @@ func (scope *EvalScope) evalTypeAssert(node *ast.TypeAssertExpr) (*Variable, err
 		return nil, err
 	}
 	if xv.Kind != reflect.Interface {
-		return nil, fmt.Errorf("expression \"%s\" not an interface", exprToString(node.X))
+		return nil, fmt.Errorf("expression \"%s\" not an interface", evalexpr.ExprToString(node.X))
 	}
 	xv.loadInterface(0, false, loadFullValue)
 	if xv.Unreadable != nil {
@@ -1228,7 +1364,7 @@ func (scope *EvalScope) evalTypeAssert(node *ast.TypeAssertExpr) (*Variable, err
 		return nil, xv.Children[0].Unreadable
 	}
 	if xv.Children[0].Addr == 0 {
-		return nil, fmt.Errorf("interface conversion: %s is nil, not %s", xv.DwarfType.String(), exprToString(node.Type))
+		return nil, fmt.Errorf("interface conversion: %s is nil, not %s", xv.DwarfType.String(), evalexpr.ExprToString(node.Type))
 	}
 	// Accept .(data) as a type assertion that always succeeds, so that users
 	// can access the data field of an interface without actually having to
@@ -1269,7 +1405,7 @@ func (scope *EvalScope) evalIndex(node *ast.IndexExpr) (*Variable, error) {
 		return nil, err
 	}
 
-	cantindex := fmt.Errorf("expression \"%s\" (%s) does not support indexing", exprToString(node.X), xev.TypeString())
+	cantindex := fmt.Errorf("expression \"%s\" (%s) does not support indexing", evalexpr.ExprToString(node.X), xev.TypeString())
 
 	switch xev.Kind {
 	case reflect.Ptr:
@@ -1287,7 +1423,7 @@ func (scope *EvalScope) evalIndex(node *ast.IndexExpr) (*Variable, error) {
 
 	case reflect.Slice, reflect.Array, reflect.String:
 		if xev.Base == 0 {
-			return nil, fmt.Errorf("can not index \"%s\"", exprToString(node.X))
+			return nil, fmt.Errorf("can not index \"%s\"", evalexpr.ExprToString(node.X))
 		}
 		n, err := idxev.asInt()
 		if err != nil {
@@ -1326,7 +1462,7 @@ func (scope *EvalScope) evalReslice(node *ast.SliceExpr) (*Variable, error) {
 		}
 		low, err = lowv.asInt()
 		if err != nil {
-			return nil, fmt.Errorf("can not convert \"%s\" to int: %v", exprToString(node.Low), err)
+			return nil, fmt.Errorf("can not convert \"%s\" to int: %v", evalexpr.ExprToString(node.Low), err)
 		}
 	}
 
@@ -1339,14 +1475,14 @@ func (scope *EvalScope) evalReslice(node *ast.SliceExpr) (*Variable, error) {
 		}
 		high, err = highv.asInt()
 		if err != nil {
-			return nil, fmt.Errorf("can not convert \"%s\" to int: %v", exprToString(node.High), err)
+			return nil, fmt.Errorf("can not convert \"%s\" to int: %v", evalexpr.ExprToString(node.High), err)
 		}
 	}
 
 	switch xev.Kind {
 	case reflect.Slice, reflect.Array, reflect.String:
 		if xev.Base == 0 {
-			return nil, fmt.Errorf("can not slice \"%s\"", exprToString(node.X))
+			return nil, fmt.Errorf("can not slice \"%s\"", evalexpr.ExprToString(node.X))
 		}
 		return xev.reslice(low, high)
 	case reflect.Map:
@@ -1365,7 +1501,7 @@ func (scope *EvalScope) evalReslice(node *ast.SliceExpr) (*Variable, error) {
 		}
 		fallthrough
 	default:
-		return nil, fmt.Errorf("can not slice \"%s\" (type %s)", exprToString(node.X), xev.TypeString())
+		return nil, fmt.Errorf("can not slice \"%s\" (type %s)", evalexpr.ExprToString(node.X), xev.TypeString())
 	}
 }
 
@@ -1377,7 +1513,7 @@ func (scope *EvalScope) evalPointerDeref(node *ast.StarExpr) (*Variable, error)
 	}
 
 	if xev.Kind != reflect.Ptr {
-		return nil, fmt.Errorf("expression \"%s\" (%s) can not be dereferenced", exprToString(node.X), xev.TypeString())
+		return nil, fmt.Errorf("expression \"%s\" (%s) can not be dereferenced", evalexpr.ExprToString(node.X), xev.TypeString())
 	}
 
 	if xev == nilVariable {
@@ -1403,7 +1539,7 @@ func (scope *EvalScope) evalAddrOf(node *ast.UnaryExpr) (*Variable, error) {
 		return nil, err
 	}
 	if xev.Addr == 0 || xev.DwarfType == nil {
-		return nil, fmt.Errorf("can not take address of \"%s\"", exprToString(node.X))
+		return nil, fmt.Errorf("can not take address of \"%s\"", evalexpr.ExprToString(node.X))
 	}
 
 	return xev.pointerToVariable(), nil
@@ -1420,42 +1556,6 @@ func (v *Variable) pointerToVariable() *Variable {
 	return rv
 }
 
-func constantUnaryOp(op token.Token, y constant.Value) (r constant.Value, err error) {
-	defer func() {
-		if ierr := recover(); ierr != nil {
-			err = fmt.Errorf("%v", ierr)
-		}
-	}()
-	r = constant.UnaryOp(op, y, 0)
-	return
-}
-
-func constantBinaryOp(op token.Token, x, y constant.Value) (r constant.Value, err error) {
-	defer func() {
-		if ierr := recover(); ierr != nil {
-			err = fmt.Errorf("%v", ierr)
-		}
-	}()
-	switch op {
-	case token.SHL, token.SHR:
-		n, _ := constant.Uint64Val(y)
-		r = constant.Shift(x, op, uint(n))
-	default:
-		r = constant.BinaryOp(x, op, y)
-	}
-	return
-}
-
-func constantCompare(op token.Token, x, y constant.Value) (r bool, err error) {
-	defer func() {
-		if ierr := recover(); ierr != nil {
-			err = fmt.Errorf("%v", ierr)
-		}
-	}()
-	r = constant.Compare(x, op, y)
-	return
-}
-
 // Evaluates expressions: -<subexpr> and +<subexpr>
 func (scope *EvalScope) evalUnary(node *ast.UnaryExpr) (*Variable, error) {
 	xv, err := scope.evalAST(node.X)
@@ -1471,9 +1571,9 @@ func (scope *EvalScope) evalUnary(node *ast.UnaryExpr) (*Variable, error) {
 		return nil, errOperationOnSpecialFloat
 	}
 	if xv.Value == nil {
-		return nil, fmt.Errorf("operator %s can not be applied to \"%s\"", node.Op.String(), exprToString(node.X))
+		return nil, fmt.Errorf("operator %s can not be applied to \"%s\"", node.Op.String(), evalexpr.ExprToString(node.X))
 	}
-	rc, err := constantUnaryOp(node.Op, xv.Value)
+	rc, err := evalexpr.UnaryOp(node.Op, xv.Value)
 	if err != nil {
 		return nil, err
 	}
@@ -1624,14 +1724,14 @@ func (scope *EvalScope) evalBinary(node *ast.BinaryExpr) (*Variable, error) {
 			yv.loadValue(loadFullValueLongerStrings)
 		}
 		if xv.Value == nil {
-			return nil, fmt.Errorf("operator %s can not be applied to \"%s\"", node.Op.String(), exprToString(node.X))
+			return nil, fmt.Errorf("operator %s can not be applied to \"%s\"", node.Op.String(), evalexpr.ExprToString(node.X))
 		}
 
 		if yv.Value == nil {
-			return nil, fmt.Errorf("operator %s can not be applied to \"%s\"", node.Op.String(), exprToString(node.Y))
+			return nil, fmt.Errorf("operator %s can not be applied to \"%s\"", node.Op.String(), evalexpr.ExprToString(node.Y))
 		}
 
-		rc, err := constantBinaryOp(op, xv.Value, yv.Value)
+		rc, err := evalexpr.BinaryOp(op, xv.Value, yv.Value)
 		if err != nil {
 			return nil, err
 		}
@@ -1647,10 +1747,10 @@ func (scope *EvalScope) evalBinary(node *ast.BinaryExpr) (*Variable, error) {
 			r.Len = xv.Len + yv.Len
 		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 			n, _ := constant.Int64Val(r.Value)
-			r.Value = constant.MakeInt64(int64(convertInt(uint64(n), true, typ.Size())))
+			r.Value = constant.MakeInt64(int64(evalexpr.ConvertInt(uint64(n), true, typ.Size())))
 		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 			n, _ := constant.Uint64Val(r.Value)
-			r.Value = constant.MakeUint64(convertInt(n, false, typ.Size()))
+			r.Value = constant.MakeUint64(evalexpr.ConvertInt(n, false, typ.Size()))
 		}
 		return r, nil
 	}
@@ -1667,7 +1767,7 @@ func compareOp(op token.Token, xv *Variable, yv *Variable) (bool, error) {
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
 		fallthrough
 	case reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
-		return constantCompare(op, xv.Value, yv.Value)
+		return evalexpr.Compare(op, xv.Value, yv.Value)
 	case reflect.String:
 		if xv.Len != yv.Len {
 			switch op {
@@ -1686,7 +1786,7 @@ func compareOp(op token.Token, xv *Variable, yv *Variable) (bool, error) {
 		if int64(len(constant.StringVal(xv.Value))) != xv.Len || int64(len(constant.StringVal(yv.Value))) != yv.Len {
 			return false, fmt.Errorf("string too long for comparison")
 		}
-		return constantCompare(op, xv.Value, yv.Value)
+		return evalexpr.Compare(op, xv.Value, yv.Value)
 	}
 
 	if op != token.EQL && op != token.NEQ {
@@ -2032,85 +2132,126 @@ func (v *Variable) reslice(low int64, high int64) (*Variable, error) {
 
 // findMethod finds method mname in the type of variable v
 func (v *Variable) findMethod(mname string) (*Variable, error) {
+	r, _, err := v.findMethodDepth(mname)
+	return r, err
+}
+
+// findMethodDepth finds method mname in the type of variable v, or in the
+// types of its embedded fields, and returns the depth at which it was
+// found. Like structMemberDepth embedded fields are searched one depth at
+// a time and two methods with the same name at the shallowest depth are
+// ambiguous. If the method doesn't exist nil is returned.
+func (v *Variable) findMethodDepth(mname string) (*Variable, int, error) {
 	if _, isiface := v.RealType.(*godwarf.InterfaceType); isiface {
 		v.loadInterface(0, false, loadFullValue)
 		if v.Unreadable != nil {
-			return nil, v.Unreadable
+			return nil, 0, v.Unreadable
 		}
-		return v.Children[0].findMethod(mname)
+		return v.Children[0].findMethodDepth(mname)
 	}
 
 	queue := []*Variable{v}
 	seen := map[string]struct{}{}
 
-	for len(queue) > 0 {
-		v := queue[0]
-		queue = append(queue[:0], queue[1:]...)
-		if _, isseen := seen[v.RealType.String()]; isseen {
-			continue
-		}
-		seen[v.RealType.String()] = struct{}{}
-
-		typ := v.DwarfType
-		ptyp, isptr := typ.(*godwarf.PtrType)
-		if isptr {
-			typ = ptyp.Type
-		}
+	for depth := 0; len(queue) > 0; depth++ {
+		var found *Variable
+		var next []*Variable
+		var levelSeen []string
 
-		typePath := typ.Common().Name
-		dot := strings.LastIndex(typePath, ".")
-		if dot < 0 {
-			// probably just a C type
-			continue
-		}
-
-		pkg := typePath[:dot]
-		receiver := typePath[dot+1:]
+		for _, v := range queue {
+			if _, isseen := seen[v.RealType.String()]; isseen {
+				continue
+			}
+			levelSeen = append(levelSeen, v.RealType.String())
 
-		if fn, ok := v.bi.LookupFunc[fmt.Sprintf("%s.%s.%s", pkg, receiver, mname)]; ok {
-			r, err := functionToVariable(fn, v.bi, v.mem)
+			r, err := v.findOwnMethod(mname)
 			if err != nil {
-				return nil, err
+				return nil, depth, err
 			}
-			if isptr {
-				r.Children = append(r.Children, *(v.maybeDereference()))
-			} else {
-				r.Children = append(r.Children, *v)
+			if r != nil {
+				if found != nil {
+					return nil, depth, fmt.Errorf("ambiguous selector %s.%s", v.Name, mname)
+				}
+				found = r
+				continue
 			}
-			return r, nil
-		}
 
-		if fn, ok := v.bi.LookupFunc[fmt.Sprintf("%s.(*%s).%s", pkg, receiver, mname)]; ok {
-			r, err := functionToVariable(fn, v.bi, v.mem)
-			if err != nil {
-				return nil, err
+			// queue embedded fields for search
+			structVar := v.maybeDereference()
+			structVar.Name = v.Name
+			if structVar.Unreadable != nil {
+				return structVar, depth, nil
 			}
-			if isptr {
-				r.Children = append(r.Children, *v)
-			} else {
-				r.Children = append(r.Children, *(v.pointerToVariable()))
+			switch t := structVar.RealType.(type) {
+			case *godwarf.StructType:
+				for _, field := range t.Field {
+					if field.Embedded {
+						embeddedVar, err := structVar.toField(field)
+						if err != nil {
+							return nil, depth, err
+						}
+						next = append(next, embeddedVar)
+					}
+				}
 			}
-			return r, nil
 		}
 
-		// queue embedded fields for search
-		structVar := v.maybeDereference()
-		structVar.Name = v.Name
-		if structVar.Unreadable != nil {
-			return structVar, nil
+		if found != nil {
+			return found, depth, nil
 		}
-		switch t := structVar.RealType.(type) {
-		case *godwarf.StructType:
-			for _, field := range t.Field {
-				if field.Embedded {
-					embeddedVar, err := structVar.toField(field)
-					if err != nil {
-						return nil, err
-					}
-					queue = append(queue, embeddedVar)
-				}
-			}
+		for _, typ := range levelSeen {
+			seen[typ] = struct{}{}
 		}
+		queue = next
+	}
+
+	return nil, 0, nil
+}
+
+// findOwnMethod returns method mname of the type of v, or of the type v
+// points to, without searching embedded fields. If the method doesn't
+// exist nil is returned.
+func (v *Variable) findOwnMethod(mname string) (*Variable, error) {
+	typ := v.DwarfType
+	ptyp, isptr := typ.(*godwarf.PtrType)
+	if isptr {
+		typ = ptyp.Type
+	}
+
+	typePath := typ.Common().Name
+	dot := strings.LastIndex(typePath, ".")
+	if dot < 0 {
+		// probably just a C type
+		return nil, nil
+	}
+
+	pkg := typePath[:dot]
+	receiver := typePath[dot+1:]
+
+	if fn, ok := v.bi.LookupFunc[fmt.Sprintf("%s.%s.%s", pkg, receiver, mname)]; ok {
+		r, err := functionToVariable(fn, v.bi, v.mem)
+		if err != nil {
+			return nil, err
+		}
+		if isptr {
+			r.Children = append(r.Children, *(v.maybeDereference()))
+		} else {
+			r.Children = append(r.Children, *v)
+		}
+		return r, nil
+	}
+
+	if fn, ok := v.bi.LookupFunc[fmt.Sprintf("%s.(*%s).%s", pkg, receiver, mname)]; ok {
+		r, err := functionToVariable(fn, v.bi, v.mem)
+		if err != nil {
+			return nil, err
+		}
+		if isptr {
+			r.Children = append(r.Children, *v)
+		} else {
+			r.Children = append(r.Children, *(v.pointerToVariable()))
+		}
+		return r, nil
 	}
 
 	return nil, nil
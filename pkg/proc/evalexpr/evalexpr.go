@@ -0,0 +1,171 @@
+// Package evalexpr contains the parts of the expression evaluator that do
+// not depend on the target process: parsing and checking expressions,
+// recognizing assignments, printing expressions back and applying
+// operators to constant values.
+// It is used by pkg/proc to evaluate expressions and breakpoint
+// conditions, checking them with Parse means that unsupported expressions
+// are rejected before the target is involved, for example when a
+// breakpoint condition is set instead of every time it is hit.
+package evalexpr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/printer"
+	"go/scanner"
+	"go/token"
+)
+
+// Parse parses expr and checks that it only uses the syntax supported by
+// the evaluator. Errors returned by go/parser are returned unchanged, so
+// that IsAssignment can be used on them.
+func Parse(expr string) (ast.Expr, error) {
+	t, err := parser.ParseExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+	if err := Check(t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Check returns an error if t uses syntax that is not supported by the
+// evaluator. Type expressions, in conversions and type assertions, and the
+// arguments of function calls, which can be types for builtins, are not
+// checked, they are resolved against the target.
+func Check(t ast.Expr) error {
+	switch node := t.(type) {
+	case *ast.Ident, *ast.BasicLit, *ast.CallExpr:
+		return nil
+	case *ast.ParenExpr:
+		return Check(node.X)
+	case *ast.SelectorExpr:
+		return Check(node.X)
+	case *ast.TypeAssertExpr:
+		if node.Type == nil {
+			return errors.New("type switch guards not supported")
+		}
+		return Check(node.X)
+	case *ast.IndexExpr:
+		if err := Check(node.X); err != nil {
+			return err
+		}
+		return Check(node.Index)
+	case *ast.SliceExpr:
+		if node.Slice3 {
+			return errors.New("3-index slice expressions not supported")
+		}
+		for _, n := range []ast.Expr{node.X, node.Low, node.High} {
+			if n == nil {
+				continue
+			}
+			if err := Check(n); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *ast.StarExpr:
+		return Check(node.X)
+	case *ast.UnaryExpr:
+		switch node.Op {
+		case token.ARROW:
+			return errors.New("receive expressions not supported")
+		}
+		return Check(node.X)
+	case *ast.BinaryExpr:
+		if err := Check(node.X); err != nil {
+			return err
+		}
+		return Check(node.Y)
+	default:
+		return fmt.Errorf("expression %T not implemented", t)
+	}
+}
+
+// IsAssignment returns true if err is the error returned by
+// go/parser.ParseExpr for an expression of the form 'lhs = rhs', the
+// returned offset is the offset of the '=' sign in the expression.
+func IsAssignment(err error) (int, bool) {
+	el, isScannerErr := err.(scanner.ErrorList)
+	if isScannerErr && el[0].Msg == "expected '==', found '='" {
+		return el[0].Pos.Offset, true
+	}
+	return 0, false
+}
+
+// ExprToString returns the source representation of t.
+func ExprToString(t ast.Expr) string {
+	var buf bytes.Buffer
+	printer.Fprint(&buf, token.NewFileSet(), t)
+	return buf.String()
+}
+
+// RemoveParen returns n without the parenthesis surrounding it.
+func RemoveParen(n ast.Expr) ast.Expr {
+	for {
+		p, ok := n.(*ast.ParenExpr)
+		if !ok {
+			break
+		}
+		n = p.X
+	}
+	return n
+}
+
+// UnaryOp returns 'op y'.
+func UnaryOp(op token.Token, y constant.Value) (r constant.Value, err error) {
+	defer func() {
+		if ierr := recover(); ierr != nil {
+			err = fmt.Errorf("%v", ierr)
+		}
+	}()
+	r = constant.UnaryOp(op, y, 0)
+	return
+}
+
+// BinaryOp returns 'x op y', token.QUO_ASSIGN can be used to force an
+// integer division.
+func BinaryOp(op token.Token, x, y constant.Value) (r constant.Value, err error) {
+	defer func() {
+		if ierr := recover(); ierr != nil {
+			err = fmt.Errorf("%v", ierr)
+		}
+	}()
+	switch op {
+	case token.SHL, token.SHR:
+		n, _ := constant.Uint64Val(y)
+		r = constant.Shift(x, op, uint(n))
+	default:
+		r = constant.BinaryOp(x, op, y)
+	}
+	return
+}
+
+// Compare returns the result of the comparison 'x op y'.
+func Compare(op token.Token, x, y constant.Value) (r bool, err error) {
+	defer func() {
+		if ierr := recover(); ierr != nil {
+			err = fmt.Errorf("%v", ierr)
+		}
+	}()
+	r = constant.Compare(x, op, y)
+	return
+}
+
+// ConvertInt truncates n to an integer of size bytes, sign extending the
+// result if signed is set.
+func ConvertInt(n uint64, signed bool, size int64) uint64 {
+	bits := uint64(size) * 8
+	mask := uint64((1 << bits) - 1)
+	r := n & mask
+	if signed && (r>>(bits-1)) != 0 {
+		// sign extension
+		r |= ^uint64(0) &^ mask
+	}
+	return r
+}
@@ -0,0 +1,122 @@
+package evalexpr
+
+import (
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	for _, tc := range []struct {
+		expr string
+		err  string
+	}{
+		{"a.b[1].c", ""},
+		{"*p == nil && x > 2", ""},
+		{"s[1:len(s)]", ""},
+		{"i.(*main.T).f", ""},
+		{"[]byte(s)", ""},
+		{`"some/pkg".v + 1`, ""},
+		{"f(g(x), []int{1})", ""},
+		{"-x", ""},
+		{"s[1:2:3]", "3-index slice expressions not supported"},
+		{"[]int{1, 2}", "expression *ast.CompositeLit not implemented"},
+		{"x == [2]int{1, 2}", "expression *ast.CompositeLit not implemented"},
+		{"func() {}", "expression *ast.FuncLit not implemented"},
+		{"<-ch", "receive expressions not supported"},
+		{"a +", "1:4: expected operand, found 'EOF'"},
+	} {
+		_, err := Parse(tc.expr)
+		errstr := ""
+		if err != nil {
+			errstr = err.Error()
+		}
+		if errstr != tc.err {
+			t.Errorf("%q: expected error %q got %q", tc.expr, tc.err, errstr)
+		}
+	}
+	_, err := Parse("a = 1")
+	if _, ok := IsAssignment(err); !ok {
+		t.Errorf("assignment not recognized from the error returned by Parse: %v", err)
+	}
+}
+
+func TestIsAssignment(t *testing.T) {
+	for _, tc := range []struct {
+		expr string
+		off  int
+		ok   bool
+	}{
+		{"a = 1", 2, true},
+		{"a.b[2] = \"x\"", 7, true},
+		{"a == 1", 0, false},
+		{"a +", 0, false},
+	} {
+		_, err := parser.ParseExpr(tc.expr)
+		off, ok := IsAssignment(err)
+		if off != tc.off || ok != tc.ok {
+			t.Errorf("%q: expected (%d, %v) got (%d, %v)", tc.expr, tc.off, tc.ok, off, ok)
+		}
+	}
+}
+
+func TestExprToString(t *testing.T) {
+	for _, tc := range []struct{ in, tgt string }{
+		{"a.b[1]", "a.b[1]"},
+		{"(((*p)))", "*p"},
+		{"x+y*2", "x + y*2"},
+	} {
+		n, err := parser.ParseExpr(tc.in)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.in, err)
+		}
+		if out := ExprToString(RemoveParen(n)); out != tc.tgt {
+			t.Errorf("%q: expected %q got %q", tc.in, tc.tgt, out)
+		}
+	}
+}
+
+func TestConstantOps(t *testing.T) {
+	r, err := BinaryOp(token.QUO_ASSIGN, constant.MakeInt64(7), constant.MakeInt64(2))
+	if err != nil || constant.Compare(r, token.NEQ, constant.MakeInt64(3)) {
+		t.Errorf("integer division: %v %v", r, err)
+	}
+	r, err = BinaryOp(token.SHL, constant.MakeInt64(1), constant.MakeInt64(4))
+	if err != nil || constant.Compare(r, token.NEQ, constant.MakeInt64(16)) {
+		t.Errorf("shift: %v %v", r, err)
+	}
+	if _, err := BinaryOp(token.QUO, constant.MakeInt64(1), constant.MakeInt64(0)); err == nil {
+		t.Errorf("division by zero did not return an error")
+	}
+	r, err = UnaryOp(token.SUB, constant.MakeInt64(3))
+	if err != nil || constant.Compare(r, token.NEQ, constant.MakeInt64(-3)) {
+		t.Errorf("negation: %v %v", r, err)
+	}
+	if b, err := Compare(token.LSS, constant.MakeInt64(1), constant.MakeInt64(2)); err != nil || !b {
+		t.Errorf("comparison: %v %v", b, err)
+	}
+}
+
+func TestConvertInt(t *testing.T) {
+	var testCases = []struct {
+		in     uint64
+		signed bool
+		size   int64
+		tgt    uint64
+	}{
+		{1, false, 1, 1},
+		{uint64(0xf0), true, 1, 0xfffffffffffffff0},
+		{uint64(0xf0), false, 1, 0xf0},
+		{uint64(0x70), true, 1, 0x70},
+		{uint64(0x90f0), true, 2, 0xffffffffffff90f0},
+		{uint64(0x90f0), false, 2, 0x90f0},
+	}
+	for _, tc := range testCases {
+		out := ConvertInt(tc.in, tc.signed, tc.size)
+		t.Logf("in=%#016x signed=%v size=%d -> %#016x\n", tc.in, tc.signed, tc.size, out)
+		if out != tc.tgt {
+			t.Errorf("expected=%#016x got=%#016x\n", tc.tgt, out)
+		}
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/go-delve/delve/pkg/dwarf/regnum"
 	"github.com/go-delve/delve/pkg/goversion"
 	"github.com/go-delve/delve/pkg/logflags"
+	"github.com/go-delve/delve/pkg/proc/evalexpr"
 )
 
 // This file implements the function call injection introduced in go1.11.
@@ -61,9 +62,14 @@ var (
 	errNotEnoughArguments         = errors.New("not enough arguments")
 	errNotAGoFunction             = errors.New("not a Go function")
 	errFuncCallNotAllowed         = errors.New("function calls not allowed without using 'call'")
-	errFuncCallNotAllowedStrAlloc = errors.New("literal string can not be allocated because function calls are not allowed without using 'call'")
+	errAssignmentInterrupted      = errors.New("the target stopped before the string was allocated, the assignment will complete when it is resumed")
 )
 
+// ErrFuncCallNotAllowedStrAlloc is returned by EvalScope.SetVariable when
+// assigning a string literal would require allocating memory in the
+// target, see SetVariable.
+var ErrFuncCallNotAllowedStrAlloc = errors.New("literal string can not be allocated because function calls are not allowed without using 'call'")
+
 type functionCallState struct {
 	// savedRegs contains the saved registers
 	savedRegs Registers
@@ -204,6 +210,45 @@ func EvalExpressionWithCalls(t *Target, g *G, expr string, retLoadCfg LoadConfig
 	return finishEvalExpressionWithCalls(t, g, contReq, ok)
 }
 
+// SetVariable sets the value of variable 'name' in the topmost frame of
+// the goroutine running on 'thread', it takes the place of a
+// Thread.SetVariable method, which the Thread interface does not have
+// because assignments need the Target to inject function calls.
+// Unlike EvalScope.SetVariable it can also assign string literals: the
+// memory they need is allocated by injecting a call to runtime.mallocgc,
+// which resumes the target. Other assignments do not resume the target.
+// An error is returned if the target stops, or the allocation panics,
+// before the call returns.
+func SetVariable(t *Target, thread Thread, name, value string) error {
+	scope, err := GoroutineScope(t, thread)
+	if err != nil {
+		return err
+	}
+	err = scope.SetVariable(name, value)
+	if err != ErrFuncCallNotAllowedStrAlloc {
+		return err
+	}
+	g, err := GetG(thread)
+	if err != nil {
+		return err
+	}
+	err = EvalExpressionWithCalls(t, g, name+" = "+value, loadSingleValue, true)
+	if err != nil {
+		return err
+	}
+	if t.fncallForG[g.ID] != nil {
+		// the target stopped, for example at a breakpoint, before the
+		// allocation returned
+		return errAssignmentInterrupted
+	}
+	for _, v := range g.Thread.Common().returnValues {
+		if v.Name == "~panic" {
+			return fncallPanicErr{v}
+		}
+	}
+	return nil
+}
+
 func finishEvalExpressionWithCalls(t *Target, g *G, contReq continueRequest, ok bool) error {
 	fncallLog("stashing return values for %d in thread=%d", g.ID, g.Thread.ThreadID())
 	g.Thread.Common().CallReturn = true
@@ -459,7 +504,7 @@ func funcCallEvalFuncExpr(scope *EvalScope, fncall *functionCallState, allowCall
 		return err
 	}
 	if fnvar.Kind != reflect.Func {
-		return fmt.Errorf("expression %q is not a function", exprToString(fncall.expr.Fun))
+		return fmt.Errorf("expression %q is not a function", evalexpr.ExprToString(fncall.expr.Fun))
 	}
 	fnvar.loadValue(LoadConfig{false, 0, 0, 0, 0, 0})
 	if fnvar.Unreadable != nil {
@@ -470,7 +515,7 @@ func funcCallEvalFuncExpr(scope *EvalScope, fncall *functionCallState, allowCall
 	}
 	fncall.fn = bi.PCToFunc(uint64(fnvar.Base))
 	if fncall.fn == nil {
-		return fmt.Errorf("could not find DIE for function %q", exprToString(fncall.expr.Fun))
+		return fmt.Errorf("could not find DIE for function %q", evalexpr.ExprToString(fncall.expr.Fun))
 	}
 	if !fncall.fn.cu.isgo {
 		return errNotAGoFunction
@@ -492,7 +537,7 @@ func funcCallEvalFuncExpr(scope *EvalScope, fncall *functionCallState, allowCall
 	if len(fnvar.Children) > 0 && argnum == (len(fncall.formalArgs)-1) {
 		argnum++
 		fncall.receiver = &fnvar.Children[0]
-		fncall.receiver.Name = exprToString(fncall.expr.Fun)
+		fncall.receiver.Name = evalexpr.ExprToString(fncall.expr.Fun)
 	}
 
 	if argnum > len(fncall.formalArgs) {
@@ -534,9 +579,9 @@ func funcCallEvalArgs(scope *EvalScope, fncall *functionCallState, formalScope *
 
 		actualArg, err := scope.evalAST(fncall.expr.Args[i])
 		if err != nil {
-			return fmt.Errorf("error evaluating %q as argument %s in function %s: %v", exprToString(fncall.expr.Args[i]), formalArg.name, fncall.fn.Name, err)
+			return fmt.Errorf("error evaluating %q as argument %s in function %s: %v", evalexpr.ExprToString(fncall.expr.Args[i]), formalArg.name, fncall.fn.Name, err)
 		}
-		actualArg.Name = exprToString(fncall.expr.Args[i])
+		actualArg.Name = evalexpr.ExprToString(fncall.expr.Args[i])
 
 		err = funcCallCopyOneArg(scope, fncall, actualArg, formalArg, formalScope)
 		if err != nil {
@@ -561,7 +606,7 @@ func funcCallCopyOneArg(scope *EvalScope, fncall *functionCallState, actualArg *
 	}
 
 	//TODO(aarzilli): autmoatic wrapping in interfaces for cases not handled
-	// by convertToEface.
+	// by convertToEface and convertToIface.
 
 	var formalArgVar *Variable
 	if formalArg.dwarfEntry != nil {
@@ -974,7 +1019,7 @@ func allocString(scope *EvalScope, v *Variable) error {
 	}
 
 	if scope.callCtx == nil {
-		return errFuncCallNotAllowedStrAlloc
+		return ErrFuncCallNotAllowedStrAlloc
 	}
 	savedLoadCfg := scope.callCtx.retLoadCfg
 	scope.callCtx.retLoadCfg = loadFullValue
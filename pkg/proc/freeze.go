@@ -0,0 +1,108 @@
+package proc
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// frozenGoroutine describes how a frozen goroutine is kept parked.
+type frozenGoroutine struct {
+	bp       *Breakpoint
+	breaklet *Breaklet
+}
+
+// FreezeGoroutine prevents the goroutine goid from running: a breakpoint,
+// conditioned on goid, is set on the instruction the goroutine will
+// execute next and, once the goroutine reaches it, its thread is left
+// stopped by the backend when the target is resumed. Other goroutines
+// continue to run and can be blocked if they wait on the frozen goroutine
+// (this includes the garbage collector if the goroutine can not be
+// preempted).
+func (t *Target) FreezeGoroutine(goid int) error {
+	if _, err := t.Valid(); err != nil {
+		return err
+	}
+	if recorded, _ := t.Recorded(); recorded {
+		return errors.New("can not freeze goroutines of a recording")
+	}
+	if _, frozen := t.frozen[goid]; frozen {
+		return nil
+	}
+	g, err := FindGoroutine(t, goid)
+	if err != nil {
+		return err
+	}
+	if g == nil {
+		return fmt.Errorf("unknown goroutine %d", goid)
+	}
+	if g.Status == Gdead {
+		return fmt.Errorf("goroutine %d is dead", goid)
+	}
+	pc := g.PC
+	if g.Thread != nil {
+		regs, err := g.Thread.Registers()
+		if err != nil {
+			return err
+		}
+		pc = regs.PC()
+	}
+	bp, err := t.SetBreakpoint(pc, FreezeBreakpoint, sameGoroutineCondition(g))
+	if err != nil {
+		return err
+	}
+	t.frozen[goid] = &frozenGoroutine{bp: bp, breaklet: bp.Breaklets[len(bp.Breaklets)-1]}
+	if g.Thread != nil {
+		// If the thread is already stopped on this breakpoint it will not hit
+		// it again, it must not be stepped over it.
+		if bpstate := g.Thread.Breakpoint(); bpstate.Breakpoint == bp {
+			bpstate.Frozen = true
+		}
+	}
+	return nil
+}
+
+// ThawGoroutine allows a goroutine frozen by FreezeGoroutine to run again.
+func (t *Target) ThawGoroutine(goid int) error {
+	if _, err := t.Valid(); err != nil {
+		return err
+	}
+	fg, ok := t.frozen[goid]
+	if !ok {
+		return fmt.Errorf("goroutine %d is not frozen", goid)
+	}
+	for i := range fg.bp.Breaklets {
+		if fg.bp.Breaklets[i] == fg.breaklet {
+			fg.bp.Breaklets[i] = nil
+		}
+	}
+	if _, err := t.finishClearBreakpoint(fg.bp); err != nil {
+		return err
+	}
+	delete(t.frozen, goid)
+	for _, thread := range t.ThreadList() {
+		if bpstate := thread.Breakpoint(); bpstate.Breakpoint == fg.bp && bpstate.Frozen {
+			if g, _ := GetG(thread); g != nil && g.ID == goid {
+				bpstate.Frozen = false
+			}
+		}
+	}
+	return nil
+}
+
+// FrozenGoroutines returns the IDs of the goroutines frozen by
+// FreezeGoroutine, in increasing order.
+func (t *Target) FrozenGoroutines() []int {
+	r := make([]int, 0, len(t.frozen))
+	for goid := range t.frozen {
+		r = append(r, goid)
+	}
+	sort.Ints(r)
+	return r
+}
+
+// IsFrozen returns true if goroutine goid was frozen by FreezeGoroutine.
+func (t *Target) IsFrozen(goid int) bool {
+	_, ok := t.frozen[goid]
+	return ok
+}
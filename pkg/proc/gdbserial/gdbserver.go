@@ -428,7 +428,10 @@ func getLdEnvVars() []string {
 // LLDBLaunch starts an instance of lldb-server and connects to it, asking
 // it to launch the specified target program with the specified arguments
 // (cmd) on the specified directory wd.
-func LLDBLaunch(cmd []string, wd string, flags proc.LaunchFlags, debugInfoDirs []string, tty string, redirects [3]string) (*proc.Target, error) {
+// The standard input of the target is read from stdinPath, if not empty,
+// its output is redirected as specified by stdoutOR and stderrOR, redirects
+// are only supported when using debugserver.
+func LLDBLaunch(cmd []string, wd string, flags proc.LaunchFlags, debugInfoDirs []string, tty string, stdinPath string, stdoutOR proc.OutputRedirect, stderrOR proc.OutputRedirect) (*proc.Target, error) {
 	if runtime.GOOS == "windows" {
 		return nil, ErrUnsupportedOS
 	}
@@ -445,6 +448,7 @@ func LLDBLaunch(cmd []string, wd string, flags proc.LaunchFlags, debugInfoDirs [
 		process       *exec.Cmd
 		err           error
 		hasRedirects  bool
+		extraFiles    []*os.File
 	)
 
 	if debugserverExecutable := getDebugServerAbsolutePath(); debugserverExecutable != "" {
@@ -459,6 +463,15 @@ func LLDBLaunch(cmd []string, wd string, flags proc.LaunchFlags, debugInfoDirs [
 		if tty != "" {
 			args = append(args, "--stdio-path", tty)
 		} else {
+			// Files are passed to debugserver as extra file descriptors,
+			// starting at 3, and debugserver opens them through /dev/fd.
+			redirects := [3]string{stdinPath, stdoutOR.Path, stderrOR.Path}
+			for i, redirect := range []proc.OutputRedirect{stdoutOR, stderrOR} {
+				if redirect.Path == "" && redirect.File != nil {
+					redirects[i+1] = fmt.Sprintf("/dev/fd/%d", 3+len(extraFiles))
+					extraFiles = append(extraFiles, redirect.File)
+				}
+			}
 			found := [3]bool{}
 			names := [3]string{"stdin", "stdout", "stderr"}
 			for i := range redirects {
@@ -518,6 +531,7 @@ func LLDBLaunch(cmd []string, wd string, flags proc.LaunchFlags, debugInfoDirs [
 	if wd != "" {
 		process.Dir = wd
 	}
+	process.ExtraFiles = extraFiles
 
 	if isatty.IsTerminal(os.Stdin.Fd()) {
 		process.SysProcAttr = sysProcAttr(foreground)
@@ -788,7 +802,7 @@ func (p *gdbProcess) ContinueOnce() (proc.Thread, proc.StopReason, error) {
 	if p.conn.direction == proc.Forward {
 		// step threads stopped at any breakpoint over their breakpoint
 		for _, thread := range p.threads {
-			if thread.CurrentBreakpoint.Breakpoint != nil {
+			if thread.CurrentBreakpoint.Breakpoint != nil && !thread.CurrentBreakpoint.Frozen {
 				if err := thread.StepInstruction(); err != nil {
 					return nil, proc.StopUnknown, err
 				}
@@ -797,7 +811,9 @@ func (p *gdbProcess) ContinueOnce() (proc.Thread, proc.StopReason, error) {
 	}
 
 	for _, th := range p.threads {
-		th.clearBreakpointState()
+		if !th.CurrentBreakpoint.Frozen {
+			th.clearBreakpointState()
+		}
 	}
 
 	p.setCtrlC(false)
@@ -1526,6 +1542,17 @@ func (p *gdbProcess) MemoryMap() ([]proc.MemoryMapEntry, error) {
 	return r, nil
 }
 
+// StartBranchTrace returns ErrBranchTraceNotSupported, the gdbserial
+// protocol has no support for hardware branch tracing.
+func (p *gdbProcess) StartBranchTrace() error {
+	return proc.ErrBranchTraceNotSupported
+}
+
+// BranchTrace returns ErrBranchTraceNotStarted.
+func (p *gdbProcess) BranchTrace(threadID int) ([]byte, error) {
+	return nil, proc.ErrBranchTraceNotStarted
+}
+
 func (p *gdbProcess) DumpProcessNotes(notes []elfwriter.Note, threadDone func()) (threadsDone bool, out []elfwriter.Note, err error) {
 	return false, notes, nil
 }
@@ -541,12 +541,25 @@ func (conn *gdbConn) resume(threads map[int]*gdbThread, tu *threadUpdater) (stri
 	if conn.direction == proc.Forward {
 		conn.outbuf.Reset()
 		fmt.Fprintf(&conn.outbuf, "$vCont")
+		frozen := false
 		for _, th := range threads {
-			if th.sig != 0 {
+			if th.CurrentBreakpoint.Frozen {
+				frozen = true
+			}
+		}
+		for _, th := range threads {
+			switch {
+			case th.CurrentBreakpoint.Frozen:
+				// threads without an action are not resumed
+			case th.sig != 0:
 				fmt.Fprintf(&conn.outbuf, ";C%02x:%s", th.sig, th.strID)
+			case frozen:
+				fmt.Fprintf(&conn.outbuf, ";c:%s", th.strID)
 			}
 		}
-		fmt.Fprintf(&conn.outbuf, ";c")
+		if !frozen {
+			fmt.Fprintf(&conn.outbuf, ";c")
+		}
 	} else {
 		if err := conn.selectThread('c', "p-1.-1", "resume"); err != nil {
 			return "", 0, err
@@ -718,7 +731,8 @@ func (conn *gdbConn) parseStopPacket(resp []byte, threadID string, tu *threadUpd
 		return false, sp, nil
 
 	case 'W', 'X':
-		// process exited, next two character are exit code
+		// process exited, next two character are exit code (W) or the
+		// signal that terminated it (X)
 
 		semicolon := bytes.Index(resp, []byte{';'})
 
@@ -726,6 +740,9 @@ func (conn *gdbConn) parseStopPacket(resp []byte, threadID string, tu *threadUpd
 			semicolon = len(resp)
 		}
 		status, _ := strconv.ParseUint(string(resp[1:semicolon]), 16, 8)
+		if resp[0] == 'X' {
+			return false, stopPacket{}, proc.ErrProcessExited{Pid: conn.pid, Status: -int(status), Signal: int(status)}
+		}
 		return false, stopPacket{}, proc.ErrProcessExited{Pid: conn.pid, Status: int(status)}
 
 	case 'N':
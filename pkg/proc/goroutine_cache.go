@@ -5,8 +5,13 @@ type goroutineCache struct {
 	allGCache     []*G
 
 	allgentryAddr, allglenAddr uint64
+	startingStackSizeAddr      uint64
 }
 
+// defaultStartingStackSize is the initial size of goroutine stacks before
+// Go 1.19, when it became adaptive (runtime.startingStackSize).
+const defaultStartingStackSize = 2048
+
 func (gcache *goroutineCache) init(bi *BinaryInfo) {
 	var err error
 
@@ -21,6 +26,22 @@ func (gcache *goroutineCache) init(bi *BinaryInfo) {
 		// try old name (pre Go 1.6)
 		gcache.allgentryAddr, _ = rdr.AddrFor("runtime.allg", exeimage.StaticBase, bi.Arch.PtrSize())
 	}
+
+	rdr.Seek(0)
+	gcache.startingStackSizeAddr, _ = rdr.AddrFor("runtime.startingStackSize", exeimage.StaticBase, bi.Arch.PtrSize())
+}
+
+// startingStackSize returns the size of the stack of newly created
+// goroutines.
+func (gcache *goroutineCache) startingStackSize(bi *BinaryInfo, mem MemoryReadWriter) uint64 {
+	if gcache.startingStackSizeAddr == 0 {
+		return defaultStartingStackSize
+	}
+	n, err := readUintRaw(mem, gcache.startingStackSizeAddr, 4)
+	if err != nil || n == 0 {
+		return defaultStartingStackSize
+	}
+	return n
 }
 
 func (gcache *goroutineCache) getRuntimeAllg(bi *BinaryInfo, mem MemoryReadWriter) (uint64, uint64, error) {
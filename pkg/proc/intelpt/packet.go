@@ -0,0 +1,299 @@
+// Package intelpt decodes the packets of the Intel Processor Trace format,
+// as described in the Intel 64 and IA-32 Architectures Software Developer's
+// Manual, Volume 3, Chapter 32 "Intel Processor Trace".
+//
+// Only the packets needed to reconstruct the control flow of a program are
+// returned by the decoder, timing and power management packets are
+// skipped.
+package intelpt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// PacketKind is the kind of a packet.
+type PacketKind uint8
+
+const (
+	// PSB is the synchronization point of the trace, the decoder resets
+	// its state after it.
+	PSB PacketKind = iota
+	// PSBEnd ends the packets describing the processor state that follow
+	// a PSB packet.
+	PSBEnd
+	// TNT reports whether the conditional branches executed were taken.
+	TNT
+	// TIP reports the target of an indirect branch, a return or a far
+	// transfer.
+	TIP
+	// TIPPGE reports that tracing was enabled, IP is the address of the
+	// first instruction traced.
+	TIPPGE
+	// TIPPGD reports that tracing was disabled, for example because the
+	// target entered the kernel.
+	TIPPGD
+	// FUP reports the address of the instruction that caused an
+	// asynchronous event, the packet that follows it describes the event.
+	FUP
+	// OVF reports that the processor lost packets because of an internal
+	// buffer overflow.
+	OVF
+)
+
+var kindNames = [...]string{
+	PSB:    "PSB",
+	PSBEnd: "PSBEND",
+	TNT:    "TNT",
+	TIP:    "TIP",
+	TIPPGE: "TIP.PGE",
+	TIPPGD: "TIP.PGD",
+	FUP:    "FUP",
+	OVF:    "OVF",
+}
+
+func (k PacketKind) String() string {
+	if int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return fmt.Sprintf("PacketKind(%d)", k)
+}
+
+// Packet is a decoded packet.
+type Packet struct {
+	Kind PacketKind
+	// IP is the address carried by TIP, TIP.PGE, TIP.PGD and FUP packets,
+	// already combined with the address of the previous packets.
+	IP uint64
+	// IPSuppressed is true if the packet does not carry an address.
+	IPSuppressed bool
+	// Taken contains the outcome of the conditional branches reported by a
+	// TNT packet, oldest first.
+	Taken []bool
+}
+
+// ErrUnknownPacket is returned by Decoder.Next when it finds a packet it
+// can not decode, call Decoder.Sync to resume decoding.
+var ErrUnknownPacket = errors.New("unknown packet")
+
+var psbPattern = bytes.Repeat([]byte{0x02, 0x82}, 8)
+
+// Decoder decodes a buffer of packets.
+type Decoder struct {
+	buf    []byte
+	pos    int
+	lastIP uint64
+}
+
+// NewDecoder returns a decoder for the packets in buf. The decoder must be
+// synchronized, by calling Sync, before calling Next.
+func NewDecoder(buf []byte) *Decoder {
+	return &Decoder{buf: buf, pos: -1}
+}
+
+// Sync moves the decoder to the next PSB packet, returns false if there
+// isn't one.
+func (d *Decoder) Sync() bool {
+	start := d.pos + 1
+	if start < 0 {
+		start = 0
+	}
+	if start > len(d.buf) {
+		return false
+	}
+	i := bytes.Index(d.buf[start:], psbPattern)
+	if i < 0 {
+		d.pos = len(d.buf)
+		return false
+	}
+	d.pos = start + i
+	d.lastIP = 0
+	return true
+}
+
+// Next returns the next packet, ok is false once the end of the buffer is
+// reached.
+func (d *Decoder) Next() (p Packet, ok bool, err error) {
+	if d.pos < 0 {
+		return p, false, errors.New("decoder not synchronized")
+	}
+	for d.pos < len(d.buf) {
+		b := d.buf[d.pos:]
+		n, skip, err := d.decode(b, &p)
+		if err != nil {
+			return p, false, err
+		}
+		if n > len(b) {
+			// truncated packet at the end of the buffer
+			d.pos = len(d.buf)
+			return p, false, nil
+		}
+		d.pos += n
+		if !skip {
+			return p, true, nil
+		}
+	}
+	return p, false, nil
+}
+
+// decode decodes the packet at the start of b into p, returning its size.
+// Returns skip = true for packets that are not returned by Next.
+func (d *Decoder) decode(b []byte, p *Packet) (n int, skip bool, err error) {
+	*p = Packet{}
+	switch {
+	case b[0] == 0x00: // PAD
+		return 1, true, nil
+
+	case b[0]&0x1f == 0x0d, b[0]&0x1f == 0x11, b[0]&0x1f == 0x01, b[0]&0x1f == 0x1d:
+		switch b[0] & 0x1f {
+		case 0x0d:
+			p.Kind = TIP
+		case 0x11:
+			p.Kind = TIPPGE
+		case 0x01:
+			p.Kind = TIPPGD
+		case 0x1d:
+			p.Kind = FUP
+		}
+		return d.decodeIP(b, p)
+
+	case b[0] == 0x19: // TSC
+		return 8, true, nil
+	case b[0] == 0x59: // MTC
+		return 2, true, nil
+	case b[0] == 0x99: // MODE
+		return 2, true, nil
+	case b[0]&0x03 == 0x03: // CYC
+		n = 1
+		if b[0]&0x04 != 0 {
+			for {
+				n++
+				if n > len(b) || b[n-1]&0x01 == 0 {
+					break
+				}
+			}
+		}
+		return n, true, nil
+
+	case b[0] == 0x02:
+		if len(b) < 2 {
+			return 2, true, nil
+		}
+		switch b[1] {
+		case 0x82: // PSB
+			p.Kind = PSB
+			d.lastIP = 0
+			return len(psbPattern), false, nil
+		case 0x23:
+			p.Kind = PSBEnd
+			return 2, false, nil
+		case 0xf3:
+			p.Kind = OVF
+			d.lastIP = 0
+			return 2, false, nil
+		case 0xa3: // long TNT
+			if len(b) < 8 {
+				return 8, true, nil
+			}
+			var payload [8]byte
+			copy(payload[:], b[2:8])
+			p.Kind = TNT
+			p.Taken = tntBits(binary.LittleEndian.Uint64(payload[:]), 48)
+			return 8, false, nil
+		case 0x03: // CBR
+			return 4, true, nil
+		case 0x43: // PIP
+			return 8, true, nil
+		case 0x83: // TraceStop
+			return 2, true, nil
+		case 0xc8: // VMCS
+			return 7, true, nil
+		case 0x73: // TMA
+			return 7, true, nil
+		case 0xc3: // MNT
+			return 11, true, nil
+		case 0x62, 0xe2: // EXSTOP
+			return 2, true, nil
+		case 0xc2: // MWAIT
+			return 10, true, nil
+		case 0x22: // PWRE
+			return 4, true, nil
+		case 0xa2: // PWRX
+			return 7, true, nil
+		}
+		if b[1]&0x1f == 0x12 { // PTW
+			if b[1]&0x60 == 0 {
+				return 6, true, nil
+			}
+			return 10, true, nil
+		}
+
+	case b[0]&0x01 == 0: // short TNT
+		p.Kind = TNT
+		p.Taken = tntBits(uint64(b[0]>>1), 7)
+		return 1, false, nil
+	}
+	return 0, false, ErrUnknownPacket
+}
+
+// decodeIP decodes the address of a TIP, TIP.PGE, TIP.PGD or FUP packet.
+func (d *Decoder) decodeIP(b []byte, p *Packet) (int, bool, error) {
+	var size int
+	switch b[0] >> 5 {
+	case 0:
+		p.IPSuppressed = true
+		return 1, false, nil
+	case 1:
+		size = 2
+	case 2:
+		size = 4
+	case 3, 4:
+		size = 6
+	case 6:
+		size = 8
+	default:
+		return 0, false, ErrUnknownPacket
+	}
+	if len(b) < 1+size {
+		return 1 + size, false, nil
+	}
+	var payload [8]byte
+	copy(payload[:], b[1:1+size])
+	ip := binary.LittleEndian.Uint64(payload[:])
+	switch b[0] >> 5 {
+	case 1, 2, 4:
+		mask := uint64(1)<<uint(size*8) - 1
+		ip = d.lastIP&^mask | ip
+	case 3:
+		// sign extend bit 47
+		if ip&(1<<47) != 0 {
+			ip |= 0xffff << 48
+		}
+	}
+	d.lastIP = ip
+	p.IP = ip
+	return 1 + size, false, nil
+}
+
+// tntBits returns the branch outcomes encoded in the n low bits of v: the
+// most significant bit set is the stop bit, the bits below it are
+// outcomes, oldest first.
+func tntBits(v uint64, n int) []bool {
+	stop := -1
+	for i := n - 1; i >= 0; i-- {
+		if v&(1<<uint(i)) != 0 {
+			stop = i
+			break
+		}
+	}
+	if stop <= 0 {
+		return nil
+	}
+	r := make([]bool, 0, stop)
+	for i := stop - 1; i >= 0; i-- {
+		r = append(r, v&(1<<uint(i)) != 0)
+	}
+	return r
+}
@@ -0,0 +1,76 @@
+package intelpt
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestDecoder(t *testing.T) {
+	var buf bytes.Buffer
+	buf.Write([]byte{0x55, 0x00})                                           // garbage before the first PSB
+	buf.Write(psbPattern)                                                   // PSB
+	buf.Write([]byte{0x19, 1, 2, 3, 4, 5, 6, 7})                            // TSC
+	buf.Write([]byte{0xdd, 0x00, 0x10, 0x40, 0x00, 0x00, 0x00, 0x00, 0x00}) // FUP 0x401000
+	buf.Write([]byte{0x02, 0x23})                                           // PSBEND
+	buf.Write([]byte{0x00})                                                 // PAD
+	buf.Write([]byte{0x2d, 0x20, 0x30})                                     // TIP, updates the low 16 bits
+	buf.Write([]byte{0x0a})                                                 // short TNT: not taken, taken
+	buf.Write([]byte{0x02, 0xa3, 0x05, 0, 0, 0, 0, 0})                      // long TNT: not taken, taken
+	buf.Write([]byte{0x01})                                                 // TIP.PGD, IP suppressed
+	buf.Write([]byte{0x02, 0xf3})                                           // OVF
+	buf.Write([]byte{0x71, 0x00, 0x00, 0x00, 0x00, 0x00, 0x80})             // TIP.PGE, sign extended
+	buf.Write([]byte{0x2d, 0x00})                                           // truncated
+
+	d := NewDecoder(buf.Bytes())
+	if !d.Sync() {
+		t.Fatal("could not synchronize")
+	}
+
+	tgt := []Packet{
+		{Kind: PSB},
+		{Kind: FUP, IP: 0x401000},
+		{Kind: PSBEnd},
+		{Kind: TIP, IP: 0x403020},
+		{Kind: TNT, Taken: []bool{false, true}},
+		{Kind: TNT, Taken: []bool{false, true}},
+		{Kind: TIPPGD, IPSuppressed: true},
+		{Kind: OVF},
+		{Kind: TIPPGE, IP: 0xffff800000000000},
+	}
+	var out []Packet
+	for {
+		p, ok, err := d.Next()
+		if err != nil {
+			t.Fatalf("error after %d packets: %v", len(out), err)
+		}
+		if !ok {
+			break
+		}
+		out = append(out, p)
+	}
+	if !reflect.DeepEqual(out, tgt) {
+		t.Errorf("mismatch\ngot:  %v\nwant: %v", out, tgt)
+	}
+}
+
+func TestDecoderUnknownPacket(t *testing.T) {
+	buf := append(append([]byte{}, psbPattern...), 0x02, 0xff)
+	buf = append(buf, psbPattern...)
+	d := NewDecoder(buf)
+	if !d.Sync() {
+		t.Fatal("could not synchronize")
+	}
+	if p, ok, err := d.Next(); err != nil || !ok || p.Kind != PSB {
+		t.Fatalf("expected PSB got %v %v %v", p, ok, err)
+	}
+	if _, _, err := d.Next(); err != ErrUnknownPacket {
+		t.Fatalf("expected ErrUnknownPacket got %v", err)
+	}
+	if !d.Sync() {
+		t.Fatal("could not resynchronize")
+	}
+	if p, ok, err := d.Next(); err != nil || !ok || p.Kind != PSB {
+		t.Fatalf("expected PSB got %v %v %v", p, ok, err)
+	}
+}
@@ -39,6 +39,9 @@ type ProcessInternal interface {
 	Detach(bool) error
 	ContinueOnce() (trapthread Thread, stopReason StopReason, err error)
 
+	// WriteBreakpoint writes bp to the target. If it fails any partial
+	// change made to the target (to memory or to the debug registers of
+	// some threads) must be undone.
 	WriteBreakpoint(*Breakpoint) error
 	EraseBreakpoint(*Breakpoint) error
 
@@ -47,6 +50,15 @@ type ProcessInternal interface {
 	DumpProcessNotes(notes []elfwriter.Note, threadDone func()) (bool, []elfwriter.Note, error)
 	// MemoryMap returns the memory map of the target process. This method must be implemented if CanDump is true.
 	MemoryMap() ([]MemoryMapEntry, error)
+
+	// StartBranchTrace starts recording the control flow of all threads
+	// with the hardware branch tracer of the CPU. Implementing this method
+	// is optional, backends that do not support it return
+	// ErrBranchTraceNotSupported.
+	StartBranchTrace() error
+	// BranchTrace returns the most recent Intel Processor Trace packets
+	// recorded for the thread since StartBranchTrace was called.
+	BranchTrace(threadID int) ([]byte, error)
 }
 
 // RecordingManipulation is an interface for manipulating process recordings.
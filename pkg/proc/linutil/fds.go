@@ -0,0 +1,151 @@
+package linutil
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FileDescriptor is an open file descriptor of a process.
+type FileDescriptor struct {
+	Num int
+	// Path is the target of the /proc/<pid>/fd/<num> symlink, for example
+	// the path of a file, "pipe:[inode]" or "socket:[inode]".
+	Path string
+	// Proto is the protocol of sockets: tcp, tcp6, udp, udp6 or unix.
+	Proto string
+	// Local and Remote are the addresses of sockets.
+	Local, Remote string
+	// State is the state of TCP sockets.
+	State string
+}
+
+// tcpStates are the names of the states of TCP sockets used in
+// /proc/net/tcp, see include/net/tcp_states.h in the linux sources.
+var tcpStates = []string{"", "ESTABLISHED", "SYN_SENT", "SYN_RECV", "FIN_WAIT1", "FIN_WAIT2", "TIME_WAIT", "CLOSE", "CLOSE_WAIT", "LAST_ACK", "LISTEN", "CLOSING", "NEW_SYN_RECV"}
+
+// ProcessFileDescriptors lists the open file descriptors of process pid,
+// sorted by number, using /proc/<pid>/fd. The addresses of sockets are
+// read from the tables in /proc/<pid>/net.
+func ProcessFileDescriptors(pid int) ([]FileDescriptor, error) {
+	dir := fmt.Sprintf("/proc/%d/fd", pid)
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	r := make([]FileDescriptor, 0, len(fis))
+	for _, fi := range fis {
+		num, err := strconv.Atoi(fi.Name())
+		if err != nil {
+			continue
+		}
+		path, err := os.Readlink(filepath.Join(dir, fi.Name()))
+		if err != nil {
+			// the file descriptor was closed
+			continue
+		}
+		r = append(r, FileDescriptor{Num: num, Path: path})
+	}
+	sort.Slice(r, func(i, j int) bool { return r[i].Num < r[j].Num })
+
+	sockets := map[string]*FileDescriptor{}
+	for i := range r {
+		if strings.HasPrefix(r[i].Path, "socket:[") && strings.HasSuffix(r[i].Path, "]") {
+			sockets[r[i].Path[len("socket:["):len(r[i].Path)-1]] = &r[i]
+		}
+	}
+	if len(sockets) == 0 {
+		return r, nil
+	}
+	for _, proto := range []string{"tcp", "tcp6", "udp", "udp6"} {
+		fh, err := os.Open(fmt.Sprintf("/proc/%d/net/%s", pid, proto))
+		if err != nil {
+			continue
+		}
+		parseInetSockets(fh, proto, sockets)
+		fh.Close()
+	}
+	if fh, err := os.Open(fmt.Sprintf("/proc/%d/net/unix", pid)); err == nil {
+		parseUnixSockets(fh, sockets)
+		fh.Close()
+	}
+	return r, nil
+}
+
+// parseInetSockets parses a table of internet sockets with the format of
+// /proc/net/tcp and fills the socket file descriptors with matching inodes.
+func parseInetSockets(rd io.Reader, proto string, sockets map[string]*FileDescriptor) {
+	s := bufio.NewScanner(rd)
+	s.Scan() // header
+	for s.Scan() {
+		// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode ...
+		fields := strings.Fields(s.Text())
+		if len(fields) < 10 {
+			continue
+		}
+		fd := sockets[fields[9]]
+		if fd == nil {
+			continue
+		}
+		fd.Proto = proto
+		fd.Local = parseInetAddr(fields[1])
+		fd.Remote = parseInetAddr(fields[2])
+		if strings.HasPrefix(proto, "tcp") {
+			if st, err := strconv.ParseUint(fields[3], 16, 8); err == nil && int(st) < len(tcpStates) {
+				fd.State = tcpStates[st]
+			}
+		}
+	}
+}
+
+// parseInetAddr parses an address in the format used by /proc/net/tcp:
+// the IP address as hexadecimal 32bit words in host byte order (assumed
+// to be little endian), followed by the port.
+func parseInetAddr(s string) string {
+	colon := strings.Index(s, ":")
+	if colon < 0 {
+		return s
+	}
+	buf, err := hex.DecodeString(s[:colon])
+	port, err2 := strconv.ParseUint(s[colon+1:], 16, 16)
+	if err != nil || err2 != nil || len(buf)%4 != 0 {
+		return s
+	}
+	ip := make(net.IP, len(buf))
+	for i := 0; i < len(buf); i += 4 {
+		binary.BigEndian.PutUint32(ip[i:], binary.LittleEndian.Uint32(buf[i:]))
+	}
+	return net.JoinHostPort(ip.String(), strconv.Itoa(int(port)))
+}
+
+// parseUnixSockets parses a table of unix sockets with the format of
+// /proc/net/unix and fills the socket file descriptors with matching
+// inodes.
+func parseUnixSockets(rd io.Reader, sockets map[string]*FileDescriptor) {
+	s := bufio.NewScanner(rd)
+	s.Scan() // header
+	for s.Scan() {
+		// Num RefCount Protocol Flags Type St Inode Path
+		fields := strings.Fields(s.Text())
+		if len(fields) < 7 {
+			continue
+		}
+		fd := sockets[fields[6]]
+		if fd == nil {
+			continue
+		}
+		fd.Proto = "unix"
+		if len(fields) >= 8 {
+			fd.Local = fields[7]
+		}
+	}
+}
@@ -3,6 +3,8 @@ package linutil
 import (
 	"fmt"
 
+	"github.com/go-delve/delve/pkg/dwarf/op"
+	"github.com/go-delve/delve/pkg/dwarf/regnum"
 	"github.com/go-delve/delve/pkg/proc"
 )
 
@@ -138,6 +140,24 @@ func (r *ARM64Registers) Copy() (proc.Registers, error) {
 	return &rr, nil
 }
 
+// SetReg changes the value of one of the general purpose registers, regNum
+// is a DWARF register number. The caller is responsible for writing the
+// registers back to the thread. The first return value is always false,
+// floating point registers can not be changed.
+func (r *ARM64Registers) SetReg(regNum uint64, reg *op.DwarfRegister) (fpchanged bool, err error) {
+	switch {
+	case regNum <= regnum.ARM64_LR:
+		r.Regs.Regs[regNum-regnum.ARM64_X0] = reg.Uint64Val
+	case regNum == regnum.ARM64_SP:
+		r.Regs.Sp = reg.Uint64Val
+	case regNum == regnum.ARM64_PC:
+		r.Regs.Pc = reg.Uint64Val
+	default:
+		return false, fmt.Errorf("can not set %s", regnum.ARM64ToName(regNum))
+	}
+	return false, nil
+}
+
 type ARM64PtraceFpRegs struct {
 	Vregs []byte
 	Fpsr  uint32
@@ -1,6 +1,10 @@
 package linutil
 
 import (
+	"fmt"
+
+	"github.com/go-delve/delve/pkg/dwarf/op"
+	"github.com/go-delve/delve/pkg/dwarf/regnum"
 	"github.com/go-delve/delve/pkg/proc"
 	"github.com/go-delve/delve/pkg/proc/amd64util"
 )
@@ -133,3 +137,35 @@ func (r *I386Registers) Copy() (proc.Registers, error) {
 	}
 	return &rr, nil
 }
+
+// SetReg changes the value of one of the general purpose registers, regNum
+// is a DWARF register number. The caller is responsible for writing the
+// registers back to the thread. The first return value is always false,
+// floating point registers can not be changed.
+func (r *I386Registers) SetReg(regNum uint64, reg *op.DwarfRegister) (fpchanged bool, err error) {
+	var p *int32
+	switch regNum {
+	case regnum.I386_Eax:
+		p = &r.Regs.Eax
+	case regnum.I386_Ecx:
+		p = &r.Regs.Ecx
+	case regnum.I386_Edx:
+		p = &r.Regs.Edx
+	case regnum.I386_Ebx:
+		p = &r.Regs.Ebx
+	case regnum.I386_Esp:
+		p = &r.Regs.Esp
+	case regnum.I386_Ebp:
+		p = &r.Regs.Ebp
+	case regnum.I386_Esi:
+		p = &r.Regs.Esi
+	case regnum.I386_Edi:
+		p = &r.Regs.Edi
+	case regnum.I386_Eip:
+		p = &r.Regs.Eip
+	default:
+		return false, fmt.Errorf("can not set %s", regnum.I386ToName(int(regNum)))
+	}
+	*p = int32(reg.Uint64Val)
+	return false, nil
+}
@@ -0,0 +1,49 @@
+package linutil
+
+import (
+	"testing"
+
+	"github.com/go-delve/delve/pkg/dwarf/op"
+	"github.com/go-delve/delve/pkg/dwarf/regnum"
+)
+
+func TestSetReg(t *testing.T) {
+	// SetReg should map DWARF register numbers to the fields of the
+	// structures returned by PTRACE_GETREGS.
+	amd64 := NewAMD64Registers(&AMD64PtraceRegs{}, nil)
+	arm64 := NewARM64Registers(&ARM64PtraceRegs{}, false, 0, nil)
+	i386 := NewI386Registers(&I386PtraceRegs{}, nil)
+
+	for _, tc := range []struct {
+		setReg func(uint64, *op.DwarfRegister) (bool, error)
+		regnum uint64
+		get    func() uint64
+	}{
+		{amd64.SetReg, regnum.AMD64_Rip, amd64.PC},
+		{amd64.SetReg, regnum.AMD64_Rsp, amd64.SP},
+		{amd64.SetReg, regnum.AMD64_Rbp, amd64.BP},
+		{arm64.SetReg, regnum.ARM64_PC, arm64.PC},
+		{arm64.SetReg, regnum.ARM64_SP, arm64.SP},
+		{arm64.SetReg, regnum.ARM64_BP, arm64.BP},
+		{arm64.SetReg, regnum.ARM64_LR, func() uint64 { return arm64.Regs.Regs[30] }},
+		{i386.SetReg, regnum.I386_Eip, i386.PC},
+		{i386.SetReg, regnum.I386_Esp, i386.SP},
+		{i386.SetReg, regnum.I386_Ebp, i386.BP},
+	} {
+		const v = 0x1234
+		if _, err := tc.setReg(tc.regnum, op.DwarfRegisterFromUint64(v)); err != nil {
+			t.Errorf("register %d: %v", tc.regnum, err)
+			continue
+		}
+		if got := tc.get(); got != v {
+			t.Errorf("register %d: expected %#x got %#x", tc.regnum, v, got)
+		}
+	}
+
+	if _, err := arm64.SetReg(regnum.ARM64_V0, op.DwarfRegisterFromUint64(0)); err == nil {
+		t.Errorf("expected error setting V0 on arm64")
+	}
+	if _, err := i386.SetReg(regnum.I386_XMM0, op.DwarfRegisterFromUint64(0)); err == nil {
+		t.Errorf("expected error setting XMM0 on 386")
+	}
+}
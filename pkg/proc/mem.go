@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/go-delve/delve/pkg/dwarf/op"
+	"github.com/go-delve/delve/pkg/metrics"
 )
 
 const cacheEnabled = true
@@ -40,6 +41,7 @@ func (m *memCache) contains(addr uint64, size int) bool {
 
 func (m *memCache) ReadMemory(data []byte, addr uint64) (n int, err error) {
 	if m.contains(addr, len(data)) {
+		metrics.MemCacheHits.Inc()
 		if !m.loaded {
 			_, err := m.mem.ReadMemory(m.cache, m.cacheAddr)
 			if err != nil {
@@ -51,6 +53,8 @@ func (m *memCache) ReadMemory(data []byte, addr uint64) (n int, err error) {
 		return len(data), nil
 	}
 
+	metrics.MemCacheMisses.Inc()
+
 	return m.mem.ReadMemory(data, addr)
 }
 
@@ -76,6 +80,108 @@ func cacheMemory(mem MemoryReadWriter, addr uint64, size int) MemoryReadWriter {
 	return &memCache{false, addr, make([]byte, size), mem}
 }
 
+// cancelableMemory fails all reads once the operation in progress on the
+// target is canceled, see Target.SetOperationContext.
+type cancelableMemory struct {
+	MemoryReadWriter
+	t *Target
+}
+
+func (m *cancelableMemory) ReadMemory(data []byte, addr uint64) (int, error) {
+	if err := m.t.OperationCanceled(); err != nil {
+		return 0, err
+	}
+	return m.MemoryReadWriter.ReadMemory(data, addr)
+}
+
+// ConcurrentReader is implemented by the memory of the backends that can
+// serve reads issued by multiple goroutines at the same time.
+type ConcurrentReader interface {
+	// ConcurrentReads returns true if ReadMemory can be called by
+	// multiple goroutines at the same time.
+	ConcurrentReads() bool
+}
+
+// concurrentReads returns true if mem can be read by multiple goroutines
+// at the same time.
+func concurrentReads(mem MemoryReadWriter) bool {
+	for {
+		switch m := mem.(type) {
+		case *cancelableMemory:
+			mem = m.MemoryReadWriter
+		case ConcurrentReader:
+			return m.ConcurrentReads()
+		default:
+			return false
+		}
+	}
+}
+
+// packageVarsGroup is a range of memory containing package variables that
+// is read from the target with a single request, see
+// cachePackageVariables.
+type packageVarsGroup struct {
+	addr uint64
+	buf  []byte
+	mem  MemoryReadWriter
+	// loaded is true once buf has been read, failed is true if reading it
+	// failed, for example because the range contains an unmapped page.
+	loaded, failed bool
+}
+
+func (group *packageVarsGroup) load() {
+	if group.loaded || group.failed {
+		return
+	}
+	if n, err := group.mem.ReadMemory(group.buf, group.addr); err != nil || n < len(group.buf) {
+		group.failed = true
+		return
+	}
+	group.loaded = true
+}
+
+// packageVarMemory is the memory of a package variable that belongs to a
+// packageVarsGroup. Reads are served by the group, the original memory of
+// the variable is used for the addresses outside of the group or if the
+// group could not be read, so that a read error only makes unreadable the
+// variables whose own memory can not be read.
+type packageVarMemory struct {
+	MemoryReadWriter
+	group *packageVarsGroup
+}
+
+func (m *packageVarMemory) ReadMemory(data []byte, addr uint64) (int, error) {
+	group := m.group
+	if addr >= group.addr && addr+uint64(len(data)) <= group.addr+uint64(len(group.buf)) {
+		group.load()
+		if group.loaded {
+			metrics.MemCacheHits.Inc()
+			copy(data, group.buf[addr-group.addr:])
+			return len(data), nil
+		}
+	}
+	return m.MemoryReadWriter.ReadMemory(data, addr)
+}
+
+// memOperationCanceled returns ErrOperationCanceled if mem was created for
+// an operation that has been canceled since.
+func memOperationCanceled(mem MemoryReadWriter) error {
+	for {
+		switch m := mem.(type) {
+		case *cancelableMemory:
+			return m.t.OperationCanceled()
+		case *memCache:
+			mem = m.mem
+		case *packageVarMemory:
+			mem = m.MemoryReadWriter
+		case *compositeMemory:
+			mem = m.realmem
+		default:
+			return nil
+		}
+	}
+}
+
 // compositeMemory represents a chunk of memory that is stored in CPU
 // registers or non-contiguously.
 //
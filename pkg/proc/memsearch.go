@@ -0,0 +1,97 @@
+package proc
+
+import (
+	"bytes"
+	"errors"
+)
+
+// MemorySearchResult is an occurrence of the pattern searched by
+// SearchMemory.
+type MemorySearchResult struct {
+	Addr uint64
+	// Mapping is the memory mapping containing Addr, or nil if the memory
+	// map of the target is not available.
+	Mapping *MemoryMapEntry
+}
+
+// searchMemoryChunkSize is the size of the blocks of memory read by
+// SearchMemory.
+const searchMemoryChunkSize = 1 << 20
+
+// SearchMemory searches pattern in the readable memory of the target
+// between start and end, or in all the readable mappings if end is zero,
+// and returns the addresses of at most max occurrences (all of them if max
+// is zero), in increasing order of mapping. The search can be interrupted
+// using the operation context of the target.
+func (t *Target) SearchMemory(pattern []byte, start, end uint64, max int) ([]MemorySearchResult, error) {
+	if len(pattern) == 0 {
+		return nil, errors.New("empty search pattern")
+	}
+	if end != 0 && end <= start {
+		return nil, errors.New("end of the search range must follow its start")
+	}
+	mappings, err := t.MemoryMap()
+	nomap := err == ErrMemoryMapNotSupported
+	switch {
+	case nomap:
+		if end == 0 {
+			return nil, errors.New("the memory map of the target is not available, a range to search must be specified")
+		}
+		mappings = []MemoryMapEntry{{Addr: start, Size: end - start, Read: true}}
+	case err != nil:
+		return nil, err
+	}
+	if end == 0 {
+		end = ^uint64(0)
+	}
+
+	mem := t.Memory()
+	var r []MemorySearchResult
+	buf := make([]byte, searchMemoryChunkSize+len(pattern)-1)
+	for i := range mappings {
+		m := &mappings[i]
+		if !m.Read {
+			continue
+		}
+		lo, hi := m.Addr, m.Addr+m.Size
+		if lo < start {
+			lo = start
+		}
+		if hi > end {
+			hi = end
+		}
+		// Consecutive chunks overlap by len(pattern)-1 bytes so that
+		// occurrences straddling two chunks are found.
+		for addr := lo; addr+uint64(len(pattern)) <= hi; addr += searchMemoryChunkSize {
+			if err := t.OperationCanceled(); err != nil {
+				return r, err
+			}
+			n := uint64(len(buf))
+			if addr+n > hi {
+				n = hi - addr
+			}
+			chunk := buf[:n]
+			if _, err := mem.ReadMemory(chunk, addr); err != nil {
+				// unreadable memory, skip the rest of the mapping
+				break
+			}
+			for off := 0; ; {
+				idx := bytes.Index(chunk[off:], pattern)
+				if idx < 0 || off+idx >= searchMemoryChunkSize {
+					// occurrences starting in the overlap are found in the next chunk
+					break
+				}
+				res := MemorySearchResult{Addr: addr + uint64(off+idx)}
+				if !nomap {
+					res.Mapping = m
+				}
+				r = append(r, res)
+				if max > 0 && len(r) >= max {
+					return r, nil
+				}
+				off += idx + 1
+			}
+		}
+	}
+	return r, nil
+}
@@ -0,0 +1,330 @@
+package proc
+
+import (
+	"errors"
+	"fmt"
+	"go/constant"
+	"reflect"
+
+	"github.com/go-delve/delve/pkg/dwarf/godwarf"
+)
+
+// Bits of the state word of sync.Mutex, see $GOROOT/src/sync/mutex.go.
+const (
+	mutexLocked      = 1 << iota // mutex is locked
+	mutexWoken                   // a waiter was woken up
+	mutexStarving                // mutex is in starvation mode
+	mutexWaiterShift = iota      // the number of waiters is stored in the remaining bits
+)
+
+// maxSemaWaiters is the maximum number of nodes of the semaphore tree
+// visited by semaWaiters, to protect against corrupted memory.
+const maxSemaWaiters = 100000
+
+// mutexHolderStackDepth is the depth of the stacktraces scanned for
+// deferred calls by MutexInfo.
+const mutexHolderStackDepth = 50
+
+// MutexState describes the state of a sync.Mutex or sync.RWMutex.
+type MutexState struct {
+	Addr uint64
+	// RW is true for a sync.RWMutex, in which case the fields Locked,
+	// Woken, Starving, Waiters and Queued describe its writer mutex.
+	RW bool
+
+	Locked   bool
+	Woken    bool
+	Starving bool
+	// Waiters is the number of waiters recorded in the state word.
+	Waiters int
+	// Queued are the IDs of the goroutines waiting to acquire the mutex.
+	Queued []int
+
+	// Readers is the number of readers holding a sync.RWMutex.
+	Readers int
+	// WriterPending is true if a writer holds, or is waiting for, a
+	// sync.RWMutex, the writer holds it if Readers is zero.
+	WriterPending bool
+	// QueuedWriter are the IDs of the goroutines holding the writer mutex
+	// and waiting for the readers to release a sync.RWMutex.
+	QueuedWriter []int
+	// QueuedReaders are the IDs of the goroutines waiting for a writer to
+	// release a sync.RWMutex.
+	QueuedReaders []int
+
+	// Holders are the IDs of the goroutines that probably hold the mutex:
+	// sync.Mutex does not record its owner, goroutines are reported if they
+	// have a deferred call unlocking it.
+	Holders []int
+}
+
+// MutexInfo decodes the state of v, which must be a sync.Mutex, a
+// sync.RWMutex or a pointer to one of them, and finds the goroutines
+// waiting on it in the semaphore tables of the runtime.
+func (t *Target) MutexInfo(v *Variable) (*MutexState, error) {
+	for v.Kind == reflect.Ptr {
+		v = v.maybeDereference()
+		if v.Unreadable != nil {
+			return nil, v.Unreadable
+		}
+		if v.Addr == 0 {
+			return nil, errors.New("nil pointer")
+		}
+	}
+	if v.Addr == 0 {
+		return nil, errors.New("mutex is not addressable")
+	}
+
+	r := &MutexState{Addr: v.Addr}
+	var unlockFns []string
+	var err error
+
+	switch v.RealType.String() {
+	case "sync.Mutex":
+		unlockFns = []string{"sync.(*Mutex).Unlock"}
+	case "sync.RWMutex":
+		r.RW = true
+		unlockFns = []string{"sync.(*RWMutex).Unlock", "sync.(*RWMutex).RUnlock"}
+		readerCount, err := intField(v, "readerCount")
+		if err != nil {
+			return nil, err
+		}
+		r.Readers = int(readerCount)
+		if readerCount < 0 {
+			// A writer is pending, readerCount also counts the readers
+			// waiting for it, the readers it is waiting for are counted by
+			// readerWait.
+			r.WriterPending = true
+			readerWait, err := intField(v, "readerWait")
+			if err != nil {
+				return nil, err
+			}
+			r.Readers = int(readerWait)
+		}
+		if r.QueuedWriter, err = t.semaWaitersOf(v, "writerSem"); err != nil {
+			return nil, err
+		}
+		if r.QueuedReaders, err = t.semaWaitersOf(v, "readerSem"); err != nil {
+			return nil, err
+		}
+		if v, err = v.structMember("w"); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%s is not a sync.Mutex or sync.RWMutex", v.TypeString())
+	}
+
+	// Since Go 1.24 sync.Mutex wraps internal/sync.Mutex.
+	if mu, err := v.structMember("mu"); err == nil {
+		v = mu
+	}
+	state, err := intField(v, "state")
+	if err != nil {
+		return nil, err
+	}
+	r.Locked = state&mutexLocked != 0
+	r.Woken = state&mutexWoken != 0
+	r.Starving = state&mutexStarving != 0
+	r.Waiters = int(uint32(state) >> mutexWaiterShift)
+	if r.Queued, err = t.semaWaitersOf(v, "sema"); err != nil {
+		return nil, err
+	}
+
+	r.Holders, err = t.mutexHolders(r.Addr, unlockFns)
+	return r, err
+}
+
+// intField returns the value of the integer field name of v, which can
+// also be wrapped into a sync/atomic type.
+func intField(v *Variable, name string) (int64, error) {
+	f, err := v.structMember(name)
+	if err != nil {
+		return 0, err
+	}
+	f.loadValue(loadFullValue)
+	if f.Unreadable != nil {
+		return 0, f.Unreadable
+	}
+	if f.Kind == reflect.Struct {
+		f = f.fieldVariable("v")
+		if f == nil {
+			return 0, fmt.Errorf("unknown type of field %s", name)
+		}
+	}
+	switch f.Kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, _ := constant.Int64Val(f.Value)
+		return n, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, _ := constant.Uint64Val(f.Value)
+		return int64(n), nil
+	}
+	return 0, fmt.Errorf("unknown type of field %s", name)
+}
+
+// semaWaitersOf returns the goroutines waiting on the semaphore stored in
+// the field name of v.
+func (t *Target) semaWaitersOf(v *Variable, name string) ([]int, error) {
+	f, err := v.structMember(name)
+	if err != nil {
+		return nil, err
+	}
+	return t.semaWaiters(f.Addr)
+}
+
+// semaWaiters returns the IDs of the goroutines waiting on the semaphore
+// at addr, by looking it up in runtime.semtable. Each entry of semtable is
+// a treap of sudogs, ordered by the address of the semaphore, the sudogs
+// waiting on the same address are linked through their waitlink field.
+// See $GOROOT/src/runtime/sema.go.
+func (t *Target) semaWaiters(addr uint64) ([]int, error) {
+	bi := t.BinInfo()
+	mem := t.Memory()
+	scope := globalScope(bi, bi.Images[0], mem)
+	semtable, err := scope.findGlobal("runtime", "semtable")
+	if err != nil {
+		return nil, err
+	}
+	if semtable.Kind != reflect.Array || semtable.Len == 0 {
+		return nil, errors.New("unknown type of runtime.semtable")
+	}
+	root, err := semtable.sliceAccess(int((addr >> 3) % uint64(semtable.Len)))
+	if err != nil {
+		return nil, err
+	}
+	// Since Go 1.19 the entries of semtable are padded.
+	if r, err := root.structMember("root"); err == nil {
+		root = r
+	}
+	treap, err := root.structMember("treap")
+	if err != nil {
+		return nil, err
+	}
+	ptrtyp, ok := treap.RealType.(*godwarf.PtrType)
+	if !ok {
+		return nil, errors.New("unknown type of runtime.semaRoot")
+	}
+	sudogType := ptrtyp.Type
+
+	ptrSize := int64(bi.Arch.PtrSize())
+	readPtr := func(a uint64, name string) (uint64, error) {
+		f, err := newVariable("", a, sudogType, bi, mem).structMember(name)
+		if err != nil {
+			return 0, err
+		}
+		return readUintRaw(mem, f.Addr, ptrSize)
+	}
+
+	var r []int
+	visited := 0
+	var visit func(a uint64) error
+	visit = func(a uint64) error {
+		if a == 0 {
+			return nil
+		}
+		visited++
+		if visited > maxSemaWaiters {
+			return errors.New("too many semaphore waiters")
+		}
+		elem, err := readPtr(a, "elem")
+		if err != nil {
+			return err
+		}
+		if elem == addr {
+			for w := a; w != 0; {
+				g, err := newVariable("", w, sudogType, bi, mem).structMember("g")
+				if err != nil {
+					return err
+				}
+				g = g.maybeDereference()
+				goid := g.loadFieldNamed("goid")
+				if goid == nil {
+					return errors.New("unreadable goroutine")
+				}
+				id, _ := constant.Int64Val(goid.Value)
+				r = append(r, int(id))
+				if w, err = readPtr(w, "waitlink"); err != nil {
+					return err
+				}
+				visited++
+				if visited > maxSemaWaiters {
+					return errors.New("too many semaphore waiters")
+				}
+			}
+		}
+		for _, child := range []string{"prev", "next"} {
+			c, err := readPtr(a, child)
+			if err != nil {
+				return err
+			}
+			if err := visit(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	head, err := readUintRaw(mem, treap.Addr, ptrSize)
+	if err != nil {
+		return nil, err
+	}
+	err = visit(head)
+	return r, err
+}
+
+// mutexHolders returns the IDs of the goroutines that have a deferred call
+// to one of unlockFns with the mutex at addr as receiver.
+func (t *Target) mutexHolders(addr uint64, unlockFns []string) ([]int, error) {
+	gs, _, err := GoroutinesInfo(t, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	var r []int
+	for _, g := range gs {
+		if g.Status == Gdead {
+			continue
+		}
+		frames, err := g.Stacktrace(mutexHolderStackDepth, StacktraceReadDefers)
+		if err != nil {
+			continue
+		}
+		if t.defersUnlock(frames, addr, unlockFns) {
+			r = append(r, g.ID)
+		}
+	}
+	return r, nil
+}
+
+func (t *Target) defersUnlock(frames []Stackframe, addr uint64, unlockFns []string) bool {
+	for _, frame := range frames {
+		for _, d := range frame.Defers {
+			if d.Unreadable != nil {
+				continue
+			}
+			_, _, fn := d.DeferredFunc(t)
+			if fn == nil || !stringInSlice(fn.Name, unlockFns) {
+				continue
+			}
+			scope, err := d.EvalScope(t, t.CurrentThread())
+			if err != nil {
+				continue
+			}
+			args, err := scope.FunctionArguments(loadSingleValue)
+			if err != nil || len(args) == 0 || args[0].Kind != reflect.Ptr || len(args[0].Children) == 0 {
+				continue
+			}
+			if args[0].Children[0].Addr == addr {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func stringInSlice(s string, v []string) bool {
+	for i := range v {
+		if v[i] == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,173 @@
+package native
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"unsafe"
+
+	sys "golang.org/x/sys/unix"
+
+	"github.com/go-delve/delve/pkg/proc"
+)
+
+const (
+	intelPTSysfs = "/sys/bus/event_source/devices/intel_pt"
+
+	// intelPTAuxPages is the number of pages of the buffer receiving the
+	// trace of each thread, must be a power of 2.
+	intelPTAuxPages = 1024
+)
+
+// ptTracer is the Intel Processor Trace perf event of a thread.
+type ptTracer struct {
+	fd     int
+	header []byte // perf_event_mmap_page followed by the (unused) data buffer
+	aux    []byte // trace buffer
+}
+
+// StartBranchTrace opens an Intel Processor Trace perf event for every
+// thread of the target, threads created later are traced as soon as they
+// are added. Only user space is traced and returns are always reported
+// (noretcomp), the trace buffers are opened read only so that the kernel
+// overwrites the oldest packets when they are full.
+func (dbp *nativeProcess) StartBranchTrace() error {
+	if dbp.os.branchTracers != nil {
+		return nil
+	}
+	attr, err := intelPTAttr()
+	if err != nil {
+		return err
+	}
+	dbp.os.branchTraceAttr = attr
+	dbp.os.branchTracers = make(map[int]*ptTracer)
+	for tid := range dbp.threads {
+		if err := dbp.startThreadBranchTrace(tid); err != nil {
+			dbp.closeBranchTracers()
+			return err
+		}
+	}
+	return nil
+}
+
+// BranchTrace returns the content of the trace buffer of thread threadID,
+// oldest packet first.
+func (dbp *nativeProcess) BranchTrace(threadID int) ([]byte, error) {
+	if dbp.os.branchTracers == nil {
+		return nil, proc.ErrBranchTraceNotStarted
+	}
+	t := dbp.os.branchTracers[threadID]
+	if t == nil {
+		return nil, fmt.Errorf("thread %d is not traced", threadID)
+	}
+	page := (*sys.PerfEventMmapPage)(unsafe.Pointer(&t.header[0]))
+	return auxData(t.aux, atomic.LoadUint64(&page.Aux_head)), nil
+}
+
+// auxData returns a copy of the content of the AUX ring buffer aux, oldest
+// byte first, head is the total number of bytes written to it. Once the
+// buffer has wrapped the oldest byte is the one at head modulo its size.
+func auxData(aux []byte, head uint64) []byte {
+	size := uint64(len(aux))
+	if head < size {
+		return append([]byte(nil), aux[:head]...)
+	}
+	off := head % size
+	r := make([]byte, 0, size)
+	r = append(r, aux[off:]...)
+	r = append(r, aux[:off]...)
+	return r
+}
+
+// startThreadBranchTrace opens the perf event tracing thread tid and maps
+// its buffers.
+func (dbp *nativeProcess) startThreadBranchTrace(tid int) error {
+	attr := *dbp.os.branchTraceAttr
+	fd, err := sys.PerfEventOpen(&attr, tid, -1, -1, sys.PERF_FLAG_FD_CLOEXEC)
+	if err != nil {
+		return fmt.Errorf("could not open Intel Processor Trace event for thread %d: %v", tid, err)
+	}
+	t := &ptTracer{fd: fd}
+	pagesize := os.Getpagesize()
+	t.header, err = sys.Mmap(fd, 0, 2*pagesize, sys.PROT_READ|sys.PROT_WRITE, sys.MAP_SHARED)
+	if err != nil {
+		t.close()
+		return fmt.Errorf("could not map perf buffer for thread %d: %v", tid, err)
+	}
+	page := (*sys.PerfEventMmapPage)(unsafe.Pointer(&t.header[0]))
+	page.Aux_offset = uint64(len(t.header))
+	page.Aux_size = uint64(intelPTAuxPages * pagesize)
+	t.aux, err = sys.Mmap(fd, int64(page.Aux_offset), int(page.Aux_size), sys.PROT_READ, sys.MAP_SHARED)
+	if err != nil {
+		t.close()
+		return fmt.Errorf("could not map trace buffer for thread %d: %v", tid, err)
+	}
+	dbp.os.branchTracers[tid] = t
+	return nil
+}
+
+// removeThread removes thread tid, which exited, from the threads of dbp
+// and closes its branch tracer, if any.
+func (dbp *nativeProcess) removeThread(tid int) {
+	delete(dbp.threads, tid)
+	if t := dbp.os.branchTracers[tid]; t != nil {
+		t.close()
+		delete(dbp.os.branchTracers, tid)
+	}
+}
+
+func (dbp *nativeProcess) closeBranchTracers() {
+	for _, t := range dbp.os.branchTracers {
+		t.close()
+	}
+	dbp.os.branchTracers = nil
+}
+
+func (t *ptTracer) close() {
+	if t.aux != nil {
+		sys.Munmap(t.aux)
+	}
+	if t.header != nil {
+		sys.Munmap(t.header)
+	}
+	sys.Close(t.fd)
+}
+
+// intelPTAttr returns the attributes of the perf event used to trace
+// threads, reading the type of the intel_pt PMU and the position of its
+// noretcomp option from sysfs.
+func intelPTAttr() (*sys.PerfEventAttr, error) {
+	buf, err := ioutil.ReadFile(intelPTSysfs + "/type")
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, proc.ErrBranchTraceNotSupported
+		}
+		return nil, err
+	}
+	typ, err := strconv.ParseUint(strings.TrimSpace(string(buf)), 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse type of intel_pt PMU: %v", err)
+	}
+	attr := &sys.PerfEventAttr{
+		Type: uint32(typ),
+		Size: uint32(unsafe.Sizeof(sys.PerfEventAttr{})),
+		Bits: sys.PerfBitExcludeKernel | sys.PerfBitExcludeHv,
+	}
+	// Returns must be reported with TIP packets, the decoder does not keep
+	// track of the call stack needed to decode compressed returns.
+	buf, err = ioutil.ReadFile(intelPTSysfs + "/format/noretcomp")
+	if err != nil {
+		return nil, fmt.Errorf("intel_pt PMU does not support noretcomp: %v", err)
+	}
+	// the format is "config:<bit>"
+	s := strings.TrimSpace(string(buf))
+	bit, err := strconv.ParseUint(strings.TrimPrefix(s, "config:"), 10, 6)
+	if err != nil || !strings.HasPrefix(s, "config:") {
+		return nil, fmt.Errorf("could not parse format of noretcomp option: %q", s)
+	}
+	attr.Config |= 1 << bit
+	return attr, nil
+}
@@ -0,0 +1,26 @@
+package native
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestAuxData(t *testing.T) {
+	aux := []byte("efghabcd")
+	for _, tc := range []struct {
+		head uint64
+		want string
+	}{
+		{0, ""},
+		{3, "efg"},
+		{8, "efghabcd"},
+		// the buffer wrapped, the oldest byte follows the last one written
+		{12, "abcdefgh"},
+		{20, "abcdefgh"},
+		{17, "fghabcde"},
+	} {
+		if got := auxData(aux, tc.head); !bytes.Equal(got, []byte(tc.want)) {
+			t.Errorf("auxData(%q, %d) = %q, want %q", aux, tc.head, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+//+build !linux
+
+package native
+
+import "github.com/go-delve/delve/pkg/proc"
+
+// StartBranchTrace returns ErrBranchTraceNotSupported.
+func (dbp *nativeProcess) StartBranchTrace() error {
+	return proc.ErrBranchTraceNotSupported
+}
+
+// BranchTrace returns ErrBranchTraceNotStarted.
+func (dbp *nativeProcess) BranchTrace(threadID int) ([]byte, error) {
+	return nil, proc.ErrBranchTraceNotStarted
+}
+
+func (dbp *nativeProcess) closeBranchTracers() {
+}
@@ -1,10 +1,14 @@
 package native
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"os"
 	"runtime"
 	"sync"
 
+	"github.com/go-delve/delve/pkg/metrics"
 	"github.com/go-delve/delve/pkg/proc"
 )
 
@@ -207,35 +211,59 @@ func (dbp *nativeProcess) CheckAndClearManualStopRequest() bool {
 
 func (dbp *nativeProcess) WriteBreakpoint(bp *proc.Breakpoint) error {
 	if bp.WatchType != 0 {
+		done := make([]*nativeThread, 0, len(dbp.threads))
 		for _, thread := range dbp.threads {
 			err := thread.writeHardwareBreakpoint(bp.Addr, bp.WatchType, bp.HWBreakIndex)
 			if err != nil {
-				return err
+				for _, thread := range done {
+					_ = thread.clearHardwareBreakpoint(bp.Addr, bp.WatchType, bp.HWBreakIndex)
+				}
+				return fmt.Errorf("thread %d: %v", thread.ID, err)
 			}
+			done = append(done, thread)
 		}
 		return nil
 	}
 
-	bp.OriginalData = make([]byte, dbp.bi.Arch.BreakpointSize())
-	_, err := dbp.memthread.ReadMemory(bp.OriginalData, bp.Addr)
+	originalData := make([]byte, dbp.bi.Arch.BreakpointSize())
+	_, err := dbp.memthread.ReadMemory(originalData, bp.Addr)
 	if err != nil {
 		return err
 	}
-	return dbp.writeSoftwareBreakpoint(dbp.memthread, bp.Addr)
+	err = dbp.writeSoftwareBreakpoint(dbp.memthread, bp.Addr)
+	if err == nil {
+		// Read the instruction back, writes to some mappings can appear to
+		// succeed without changing what the target executes.
+		written := make([]byte, len(originalData))
+		_, err = dbp.memthread.ReadMemory(written, bp.Addr)
+		if err == nil && !bytes.Equal(written, dbp.bi.Arch.BreakpointInstruction()) {
+			err = errors.New("breakpoint instruction not written to memory")
+		}
+	}
+	if err != nil {
+		// Part of the breakpoint instruction could have been written.
+		_, _ = dbp.memthread.WriteMemory(bp.Addr, originalData)
+		return err
+	}
+	bp.OriginalData = originalData
+	return nil
 }
 
 func (dbp *nativeProcess) EraseBreakpoint(bp *proc.Breakpoint) error {
 	if bp.WatchType != 0 {
+		// Clear the breakpoint from as many threads as possible before
+		// reporting the failure.
+		var err error
 		for _, thread := range dbp.threads {
-			err := thread.clearHardwareBreakpoint(bp.Addr, bp.WatchType, bp.HWBreakIndex)
-			if err != nil {
-				return err
+			if err1 := thread.clearHardwareBreakpoint(bp.Addr, bp.WatchType, bp.HWBreakIndex); err1 != nil && err == nil {
+				err = fmt.Errorf("thread %d: %v", thread.ID, err1)
 			}
 		}
-		return nil
+		return err
 	}
 
-	return dbp.memthread.clearSoftwareBreakpoint(bp)
+	_, err := dbp.memthread.WriteMemory(bp.Addr, bp.OriginalData)
+	return err
 }
 
 // ContinueOnce will continue the target until it stops.
@@ -331,12 +359,14 @@ func (dbp *nativeProcess) handlePtraceFuncs() {
 }
 
 func (dbp *nativeProcess) execPtraceFunc(fn func()) {
+	metrics.PtraceCalls.Inc()
 	dbp.ptraceChan <- fn
 	<-dbp.ptraceDoneChan
 }
 
 func (dbp *nativeProcess) postExit() {
 	dbp.exited = true
+	dbp.closeBranchTracers()
 	close(dbp.ptraceChan)
 	close(dbp.ptraceDoneChan)
 	dbp.bi.Close()
@@ -350,11 +380,11 @@ func (dbp *nativeProcess) writeSoftwareBreakpoint(thread *nativeThread, addr uin
 	return err
 }
 
-func openRedirects(redirects [3]string, foreground bool) (stdin, stdout, stderr *os.File, closefn func(), err error) {
+func openRedirects(stdinPath string, stdoutOR proc.OutputRedirect, stderrOR proc.OutputRedirect, foreground bool) (stdin, stdout, stderr *os.File, closefn func(), err error) {
 	toclose := []*os.File{}
 
-	if redirects[0] != "" {
-		stdin, err = os.Open(redirects[0])
+	if stdinPath != "" {
+		stdin, err = os.Open(stdinPath)
 		if err != nil {
 			return nil, nil, nil, nil, err
 		}
@@ -363,24 +393,27 @@ func openRedirects(redirects [3]string, foreground bool) (stdin, stdout, stderr
 		stdin = os.Stdin
 	}
 
-	create := func(path string, dflt *os.File) *os.File {
-		if path == "" {
+	create := func(redirect proc.OutputRedirect, dflt *os.File) *os.File {
+		if redirect.Path == "" {
+			if redirect.File != nil {
+				return redirect.File
+			}
 			return dflt
 		}
 		var f *os.File
-		f, err = os.Create(path)
+		f, err = os.Create(redirect.Path)
 		if f != nil {
 			toclose = append(toclose, f)
 		}
 		return f
 	}
 
-	stdout = create(redirects[1], os.Stdout)
+	stdout = create(stdoutOR, os.Stdout)
 	if err != nil {
 		return nil, nil, nil, nil, err
 	}
 
-	stderr = create(redirects[2], os.Stderr)
+	stderr = create(stderrOR, os.Stderr)
 	if err != nil {
 		return nil, nil, nil, nil, err
 	}
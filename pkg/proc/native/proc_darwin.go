@@ -38,7 +38,7 @@ type osProcessDetails struct {
 // custom fork/exec process in order to take advantage of
 // PT_SIGEXC on Darwin which will turn Unix signals into
 // Mach exceptions.
-func Launch(cmd []string, wd string, flags proc.LaunchFlags, _ []string, _ string, _ [3]string) (*proc.Target, error) {
+func Launch(cmd []string, wd string, flags proc.LaunchFlags, _ []string, _ string, _ string, _ proc.OutputRedirect, _ proc.OutputRedirect) (*proc.Target, error) {
 	argv0Go, err := filepath.Abs(cmd[0])
 	if err != nil {
 		return nil, err
@@ -311,7 +311,7 @@ func (dbp *nativeProcess) trapWait(pid int) (*nativeThread, error) {
 				return nil, err
 			}
 			dbp.postExit()
-			return nil, proc.ErrProcessExited{Pid: dbp.pid, Status: status.ExitStatus()}
+			return nil, proc.NewErrProcessExited(dbp.pid, status)
 
 		case C.MACH_RCV_INTERRUPTED:
 			dbp.stopMu.Lock()
@@ -402,9 +402,9 @@ func (dbp *nativeProcess) exitGuard(err error) error {
 		return err
 	}
 	_, status, werr := dbp.wait(dbp.pid, sys.WNOHANG)
-	if werr == nil && status.Exited() {
+	if werr == nil && (status.Exited() || status.Signaled()) {
 		dbp.postExit()
-		return proc.ErrProcessExited{Pid: dbp.pid, Status: status.ExitStatus()}
+		return proc.NewErrProcessExited(dbp.pid, status)
 	}
 	return err
 }
@@ -412,15 +412,18 @@ func (dbp *nativeProcess) exitGuard(err error) error {
 func (dbp *nativeProcess) resume() error {
 	// all threads stopped over a breakpoint are made to step over it
 	for _, thread := range dbp.threads {
-		if thread.CurrentBreakpoint.Breakpoint != nil {
+		if thread.CurrentBreakpoint.Breakpoint != nil && !thread.CurrentBreakpoint.Frozen {
 			if err := thread.StepInstruction(); err != nil {
 				return err
 			}
 			thread.CurrentBreakpoint.Clear()
 		}
 	}
-	// everything is resumed
+	// everything is resumed, except threads running a frozen goroutine
 	for _, thread := range dbp.threads {
+		if thread.CurrentBreakpoint.Frozen {
+			continue
+		}
 		if err := thread.resume(); err != nil {
 			return dbp.exitGuard(err)
 		}
@@ -42,7 +42,7 @@ type osProcessDetails struct {
 // to be supplied to that process. `wd` is working directory of the program.
 // If the DWARF information cannot be found in the binary, Delve will look
 // for external debug files in the directories passed in.
-func Launch(cmd []string, wd string, flags proc.LaunchFlags, debugInfoDirs []string, tty string, redirects [3]string) (*proc.Target, error) {
+func Launch(cmd []string, wd string, flags proc.LaunchFlags, debugInfoDirs []string, tty string, stdinPath string, stdoutOR proc.OutputRedirect, stderrOR proc.OutputRedirect) (*proc.Target, error) {
 	var (
 		process *exec.Cmd
 		err     error
@@ -50,7 +50,7 @@ func Launch(cmd []string, wd string, flags proc.LaunchFlags, debugInfoDirs []str
 
 	foreground := flags&proc.LaunchForeground != 0
 
-	stdin, stdout, stderr, closefn, err := openRedirects(redirects, foreground)
+	stdin, stdout, stderr, closefn, err := openRedirects(stdinPath, stdoutOR, stderrOR, foreground)
 	if err != nil {
 		return nil, err
 	}
@@ -228,7 +228,7 @@ func (dbp *nativeProcess) trapWaitInternal(pid int, halt bool) (*nativeThread, e
 		}
 		if status.Exited() {
 			dbp.postExit()
-			return nil, proc.ErrProcessExited{Pid: wpid, Status: status.ExitStatus()}
+			return nil, proc.NewErrProcessExited(wpid, status)
 		}
 
 		var info sys.PtraceLwpInfoStruct
@@ -332,16 +332,29 @@ func (dbp *nativeProcess) exitGuard(err error) error {
 func (dbp *nativeProcess) resume() error {
 	// all threads stopped over a breakpoint are made to step over it
 	for _, thread := range dbp.threads {
-		if thread.CurrentBreakpoint.Breakpoint != nil {
+		if thread.CurrentBreakpoint.Breakpoint != nil && !thread.CurrentBreakpoint.Frozen {
 			if err := thread.StepInstruction(); err != nil {
 				return err
 			}
 			thread.CurrentBreakpoint.Clear()
 		}
 	}
-	// all threads are resumed
+	// all threads are resumed, except threads running a frozen goroutine
+	// which are suspended
 	var err error
-	dbp.execPtraceFunc(func() { err = ptraceCont(dbp.pid, 0) })
+	dbp.execPtraceFunc(func() {
+		for _, thread := range dbp.threads {
+			if thread.CurrentBreakpoint.Frozen {
+				err = ptraceSuspend(thread.ID)
+			} else {
+				err = ptraceResume(thread.ID)
+			}
+			if err != nil {
+				return
+			}
+		}
+		err = ptraceCont(dbp.pid, 0)
+	})
 	return err
 }
 
@@ -18,6 +18,7 @@ import (
 
 	sys "golang.org/x/sys/unix"
 
+	"github.com/go-delve/delve/pkg/logflags"
 	"github.com/go-delve/delve/pkg/proc"
 	"github.com/go-delve/delve/pkg/proc/linutil"
 
@@ -45,6 +46,11 @@ const (
 // process details.
 type osProcessDetails struct {
 	comm string
+
+	// branchTracers contains the Intel Processor Trace events of each
+	// thread, see StartBranchTrace.
+	branchTracers   map[int]*ptTracer
+	branchTraceAttr *sys.PerfEventAttr
 }
 
 // Launch creates and begins debugging a new process. First entry in
@@ -52,7 +58,9 @@ type osProcessDetails struct {
 // to be supplied to that process. `wd` is working directory of the program.
 // If the DWARF information cannot be found in the binary, Delve will look
 // for external debug files in the directories passed in.
-func Launch(cmd []string, wd string, flags proc.LaunchFlags, debugInfoDirs []string, tty string, redirects [3]string) (*proc.Target, error) {
+// The standard input of the process is read from stdinPath, if not empty,
+// its output is redirected as specified by stdoutOR and stderrOR.
+func Launch(cmd []string, wd string, flags proc.LaunchFlags, debugInfoDirs []string, tty string, stdinPath string, stdoutOR proc.OutputRedirect, stderrOR proc.OutputRedirect) (*proc.Target, error) {
 	var (
 		process *exec.Cmd
 		err     error
@@ -60,7 +68,7 @@ func Launch(cmd []string, wd string, flags proc.LaunchFlags, debugInfoDirs []str
 
 	foreground := flags&proc.LaunchForeground != 0
 
-	stdin, stdout, stderr, closefn, err := openRedirects(redirects, foreground)
+	stdin, stdout, stderr, closefn, err := openRedirects(stdinPath, stdoutOR, stderrOR, foreground)
 	if err != nil {
 		return nil, err
 	}
@@ -268,6 +276,13 @@ func (dbp *nativeProcess) addThread(tid int, attach bool) (*nativeThread, error)
 	if dbp.memthread == nil {
 		dbp.memthread = dbp.threads[tid]
 	}
+	if dbp.os.branchTracers != nil {
+		if err := dbp.startThreadBranchTrace(tid); err != nil {
+			// Tracing is best effort for new threads, opening the event can fail
+			// for example because the user exceeded perf_event_mlock_kb.
+			logflags.DebuggerLogger().Warnf("thread %d will not be traced: %v", tid, err)
+		}
+	}
 	for _, bp := range dbp.Breakpoints().M {
 		if bp.WatchType != 0 {
 			err := dbp.threads[tid].writeHardwareBreakpoint(bp.Addr, bp.WatchType, bp.HWBreakIndex)
@@ -337,19 +352,19 @@ func (dbp *nativeProcess) trapWaitInternal(pid int, options trapWaitOptions) (*n
 		if status.Exited() {
 			if wpid == dbp.pid {
 				dbp.postExit()
-				return nil, proc.ErrProcessExited{Pid: wpid, Status: status.ExitStatus()}
+				return nil, proc.NewErrProcessExited(wpid, status)
 			}
-			delete(dbp.threads, wpid)
+			dbp.removeThread(wpid)
 			continue
 		}
 		if status.Signaled() {
 			// Signaled means the thread was terminated due to a signal.
 			if wpid == dbp.pid {
 				dbp.postExit()
-				return nil, proc.ErrProcessExited{Pid: wpid, Status: -int(status.Signal())}
+				return nil, proc.NewErrProcessExited(wpid, status)
 			}
 			// does this ever happen?
-			delete(dbp.threads, wpid)
+			dbp.removeThread(wpid)
 			continue
 		}
 		if status.StopSignal() == sys.SIGTRAP && status.TrapCause() == sys.PTRACE_EVENT_CLONE {
@@ -358,17 +373,17 @@ func (dbp *nativeProcess) trapWaitInternal(pid int, options trapWaitOptions) (*n
 			var cloned uint
 			dbp.execPtraceFunc(func() { cloned, err = sys.PtraceGetEventMsg(wpid) })
 			if err != nil {
-				if err == sys.ESRCH {
+				if errors.Is(err, sys.ESRCH) {
 					// thread died while we were adding it
 					continue
 				}
-				return nil, fmt.Errorf("could not get event message: %s", err)
+				return nil, fmt.Errorf("could not get event message: %w", err)
 			}
 			th, err = dbp.addThread(int(cloned), false)
 			if err != nil {
-				if err == sys.ESRCH {
+				if errors.Is(err, sys.ESRCH) {
 					// thread died while we were adding it
-					delete(dbp.threads, int(cloned))
+					dbp.removeThread(int(cloned))
 					continue
 				}
 				return nil, err
@@ -379,16 +394,16 @@ func (dbp *nativeProcess) trapWaitInternal(pid int, options trapWaitOptions) (*n
 				return nil, nil
 			}
 			if err = th.Continue(); err != nil {
-				if err == sys.ESRCH {
+				if errors.Is(err, sys.ESRCH) {
 					// thread died while we were adding it
-					delete(dbp.threads, th.ID)
+					dbp.removeThread(th.ID)
 					continue
 				}
-				return nil, fmt.Errorf("could not continue new thread %d %s", cloned, err)
+				return nil, fmt.Errorf("could not continue new thread %d %w", cloned, err)
 			}
 			if err = dbp.threads[int(wpid)].Continue(); err != nil {
-				if err != sys.ESRCH {
-					return nil, fmt.Errorf("could not continue existing thread %d %s", wpid, err)
+				if !errors.Is(err, sys.ESRCH) {
+					return nil, fmt.Errorf("could not continue existing thread %d %w", wpid, err)
 				}
 			}
 			continue
@@ -405,6 +420,16 @@ func (dbp *nativeProcess) trapWaitInternal(pid int, options trapWaitOptions) (*n
 			return th, nil
 		}
 
+		if isFaultSignal(status.StopSignal()) && (!halt || !th.os.running) {
+			// Stop before the fault signal is delivered to the thread so that the
+			// user can inspect the state of the thread at the faulting
+			// instruction, the signal is delivered when the thread is resumed.
+			// Threads we sent a STOP signal to are excluded, see below.
+			th.holdFaultSignal(status.StopSignal())
+			th.os.running = false
+			return th, nil
+		}
+
 		// TODO(dp) alert user about unexpected signals here.
 		if halt && !th.os.running {
 			// We are trying to stop the process, queue this signal to be delivered
@@ -416,7 +441,7 @@ func (dbp *nativeProcess) trapWaitInternal(pid int, options trapWaitOptions) (*n
 			th.os.running = false
 			return th, nil
 		} else if err := th.resumeWithSig(int(status.StopSignal())); err != nil {
-			if err != sys.ESRCH {
+			if !errors.Is(err, sys.ESRCH) {
 				return nil, err
 			}
 			// do the same thing we do if a thread quit
@@ -424,7 +449,7 @@ func (dbp *nativeProcess) trapWaitInternal(pid int, options trapWaitOptions) (*n
 				dbp.postExit()
 				return nil, proc.ErrProcessExited{Pid: wpid, Status: status.ExitStatus()}
 			}
-			delete(dbp.threads, wpid)
+			dbp.removeThread(wpid)
 		}
 	}
 }
@@ -489,8 +514,45 @@ func (dbp *nativeProcess) wait(pid, options int) (int, *sys.WaitStatus, error) {
 	}
 }
 
+// forgetExitedThread handles an error returned by an operation on thread
+// th: if the error is ESRCH because the thread exited, which can happen at
+// any time for short lived threads, the thread is removed from the
+// threads of dbp and nil is returned, otherwise err is returned.
+// The thread group leader is never removed, its exit is handled as the exit
+// of the process.
+func (dbp *nativeProcess) forgetExitedThread(th *nativeThread, err error) error {
+	if !errors.Is(err, sys.ESRCH) || th.ID == dbp.pid || !dbp.threadExited(th.ID) {
+		return err
+	}
+	dbp.removeThread(th.ID)
+	if dbp.memthread == th {
+		dbp.memthread = dbp.threads[dbp.pid]
+	}
+	return nil
+}
+
+// threadExited returns true if thread tid of the target no longer exists
+// or is a zombie.
+func (dbp *nativeProcess) threadExited(tid int) bool {
+	buf, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/task/%d/stat", dbp.pid, tid))
+	if err != nil {
+		return true
+	}
+	// The state follows the name of the task, which is enclosed in parenthesis
+	// and can contain spaces and parenthesis.
+	i := bytes.LastIndexByte(buf, ')')
+	if i < 0 || i+2 >= len(buf) {
+		return false
+	}
+	switch buf[i+2] {
+	case statusZombie, 'X', 'x':
+		return true
+	}
+	return false
+}
+
 func (dbp *nativeProcess) exitGuard(err error) error {
-	if err != sys.ESRCH {
+	if !errors.Is(err, sys.ESRCH) {
 		return err
 	}
 	if status(dbp.pid, dbp.os.comm) == statusZombie {
@@ -504,16 +566,22 @@ func (dbp *nativeProcess) exitGuard(err error) error {
 func (dbp *nativeProcess) resume() error {
 	// all threads stopped over a breakpoint are made to step over it
 	for _, thread := range dbp.threads {
-		if thread.CurrentBreakpoint.Breakpoint != nil {
+		if thread.CurrentBreakpoint.Breakpoint != nil && !thread.CurrentBreakpoint.Frozen {
 			if err := thread.StepInstruction(); err != nil {
-				return err
+				if err := dbp.forgetExitedThread(thread, err); err != nil {
+					return err
+				}
+				continue
 			}
 			thread.CurrentBreakpoint.Clear()
 		}
 	}
-	// everything is resumed
+	// everything is resumed, except threads running a frozen goroutine
 	for _, thread := range dbp.threads {
-		if err := thread.resume(); err != nil && err != sys.ESRCH {
+		if thread.CurrentBreakpoint.Frozen {
+			continue
+		}
+		if err := thread.resume(); err != nil && !errors.Is(err, sys.ESRCH) {
 			return err
 		}
 	}
@@ -546,7 +614,9 @@ func (dbp *nativeProcess) stop(trapthread *nativeThread) (*nativeThread, error)
 	for _, th := range dbp.threads {
 		if th.os.running {
 			if err := th.stop(); err != nil {
-				return nil, dbp.exitGuard(err)
+				if err := dbp.forgetExitedThread(th, err); err != nil {
+					return nil, dbp.exitGuard(err)
+				}
 			}
 		}
 	}
@@ -593,7 +663,9 @@ func (dbp *nativeProcess) stop(trapthread *nativeThread) (*nativeThread, error)
 
 		if th.CurrentBreakpoint.Breakpoint == nil && th.os.setbp {
 			if err := th.SetCurrentBreakpoint(true); err != nil {
-				err1 = err
+				if err := dbp.forgetExitedThread(th, err); err != nil {
+					err1 = err
+				}
 				continue
 			}
 		}
@@ -21,7 +21,7 @@ type osProcessDetails struct {
 }
 
 // Launch creates and begins debugging a new process.
-func Launch(cmd []string, wd string, flags proc.LaunchFlags, _ []string, _ string, redirects [3]string) (*proc.Target, error) {
+func Launch(cmd []string, wd string, flags proc.LaunchFlags, _ []string, _ string, stdinPath string, stdoutOR proc.OutputRedirect, stderrOR proc.OutputRedirect) (*proc.Target, error) {
 	argv0Go, err := filepath.Abs(cmd[0])
 	if err != nil {
 		return nil, err
@@ -29,7 +29,7 @@ func Launch(cmd []string, wd string, flags proc.LaunchFlags, _ []string, _ strin
 
 	env := proc.DisableAsyncPreemptEnv()
 
-	stdin, stdout, stderr, closefn, err := openRedirects(redirects, true)
+	stdin, stdout, stderr, closefn, err := openRedirects(stdinPath, stdoutOR, stderrOR, true)
 	if err != nil {
 		return nil, err
 	}
@@ -392,7 +392,7 @@ func (dbp *nativeProcess) exitGuard(err error) error {
 
 func (dbp *nativeProcess) resume() error {
 	for _, thread := range dbp.threads {
-		if thread.CurrentBreakpoint.Breakpoint != nil {
+		if thread.CurrentBreakpoint.Breakpoint != nil && !thread.CurrentBreakpoint.Frozen {
 			if err := thread.StepInstruction(); err != nil {
 				return err
 			}
@@ -400,7 +400,12 @@ func (dbp *nativeProcess) resume() error {
 		}
 	}
 
+	// threads running a frozen goroutine stay suspended, stop does not
+	// suspend them again, see stop
 	for _, thread := range dbp.threads {
+		if thread.CurrentBreakpoint.Frozen {
+			continue
+		}
 		_, err := _ResumeThread(thread.os.hThread)
 		if err != nil {
 			return err
@@ -428,14 +433,13 @@ func (dbp *nativeProcess) stop(trapthread *nativeThread) (*nativeThread, error)
 	// We need to explicitly call SuspendThread because otherwise the
 	// call to _ContinueDebugEvent will resume execution of some of the
 	// target threads.
-
-	err := trapthread.SetCurrentBreakpoint(true)
-	if err != nil {
-		return nil, err
-	}
+	// Threads running a frozen goroutine were not resumed by resume and are
+	// still suspended, suspending them again would leave them suspended
+	// after they are thawed. This must be done before the current
+	// breakpoint of trapthread is set, which could freeze it.
 
 	for _, thread := range dbp.threads {
-		if !thread.os.dbgUiRemoteBreakIn {
+		if !thread.os.dbgUiRemoteBreakIn && !thread.CurrentBreakpoint.Frozen {
 			_, err := _SuspendThread(thread.os.hThread)
 			if err != nil {
 				return nil, err
@@ -443,6 +447,11 @@ func (dbp *nativeProcess) stop(trapthread *nativeThread) (*nativeThread, error)
 		}
 	}
 
+	err := trapthread.SetCurrentBreakpoint(true)
+	if err != nil {
+		return nil, err
+	}
+
 	for {
 		var err error
 		var tid int
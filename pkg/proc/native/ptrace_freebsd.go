@@ -40,6 +40,27 @@ func ptraceSingleStep(id int) error {
 	return sys.PtraceSingleStep(id)
 }
 
+// ptraceSuspend executes ptrace PT_SUSPEND, the thread will not run when
+// the process is continued.
+// id must be an LWPID
+func ptraceSuspend(id int) error {
+	return ptraceLwp(C.PT_SUSPEND, id)
+}
+
+// ptraceResume executes ptrace PT_RESUME, undoing ptraceSuspend.
+// id must be an LWPID
+func ptraceResume(id int) error {
+	return ptraceLwp(C.PT_RESUME, id)
+}
+
+func ptraceLwp(req, id int) error {
+	_, _, err := syscall.Syscall6(syscall.SYS_PTRACE, uintptr(req), uintptr(id), 0, 0, 0, 0)
+	if err != syscall.Errno(0) {
+		return err
+	}
+	return nil
+}
+
 // Get a list of the thread ids of a process
 func ptraceGetLwpList(pid int) (tids []int32) {
 	num_lwps, _ := C.ptrace_get_num_lwps(C.int(pid))
@@ -2,6 +2,7 @@ package native
 
 import (
 	"syscall"
+	"unsafe"
 
 	sys "golang.org/x/sys/unix"
 )
@@ -24,3 +25,23 @@ func ptraceDetach(tid, sig int) error {
 func ptraceCont(tid, sig int) error {
 	return sys.PtraceCont(tid, sig)
 }
+
+// ptraceSingleStepWithSig executes ptrace PTRACE_SINGLESTEP delivering
+// signal sig to the thread.
+func ptraceSingleStepWithSig(tid, sig int) error {
+	_, _, err := sys.Syscall6(sys.SYS_PTRACE, sys.PTRACE_SINGLESTEP, uintptr(tid), 0, uintptr(sig), 0, 0)
+	if err != syscall.Errno(0) {
+		return err
+	}
+	return nil
+}
+
+// ptraceGetSiginfo executes ptrace PTRACE_GETSIGINFO, the siginfo_t
+// structure of the signal that stopped the thread is copied into buf.
+func ptraceGetSiginfo(tid int, buf *[128]byte) error {
+	_, _, err := sys.Syscall6(sys.SYS_PTRACE, sys.PTRACE_GETSIGINFO, uintptr(tid), 0, uintptr(unsafe.Pointer(buf)), 0, 0)
+	if err != syscall.Errno(0) {
+		return err
+	}
+	return nil
+}
@@ -6,41 +6,19 @@ import (
 	sys "golang.org/x/sys/unix"
 
 	"github.com/go-delve/delve/pkg/dwarf/op"
-	"github.com/go-delve/delve/pkg/dwarf/regnum"
 	"github.com/go-delve/delve/pkg/proc"
 	"github.com/go-delve/delve/pkg/proc/amd64util"
 	"github.com/go-delve/delve/pkg/proc/linutil"
 )
 
-// setPC sets EIP to the value specified by 'pc'.
-func (thread *nativeThread) setPC(pc uint64) error {
-	ir, err := registers(thread)
-	if err != nil {
-		return err
-	}
-	r := ir.(*linutil.I386Registers)
-	r.Regs.Eip = int32(pc)
-	thread.dbp.execPtraceFunc(func() { err = sys.PtraceSetRegs(thread.ID, (*sys.PtraceRegs)(r.Regs)) })
-	return err
-}
-
 func (thread *nativeThread) SetReg(regNum uint64, reg *op.DwarfRegister) error {
 	ir, err := registers(thread)
 	if err != nil {
 		return err
 	}
 	r := ir.(*linutil.I386Registers)
-	switch regNum {
-	case regnum.I386_Eip:
-		r.Regs.Eip = int32(reg.Uint64Val)
-	case regnum.I386_Esp:
-		r.Regs.Esp = int32(reg.Uint64Val)
-	case regnum.I386_Edx:
-		r.Regs.Edx = int32(reg.Uint64Val)
-	default:
-		//TODO(aarzilli): when the register calling convention is adopted by Go on
-		// i386 this should be implemented.
-		return fmt.Errorf("changing register %d not implemented", regNum)
+	if _, err := r.SetReg(regNum, reg); err != nil {
+		return err
 	}
 	thread.dbp.execPtraceFunc(func() { err = sys.PtraceSetRegs(thread.ID, (*sys.PtraceRegs)(r.Regs)) })
 	return err
@@ -13,18 +13,6 @@ import (
 	"github.com/go-delve/delve/pkg/proc/linutil"
 )
 
-// SetPC sets RIP to the value specified by 'pc'.
-func (thread *nativeThread) setPC(pc uint64) error {
-	ir, err := registers(thread)
-	if err != nil {
-		return err
-	}
-	r := ir.(*linutil.AMD64Registers)
-	r.Regs.Rip = pc
-	thread.dbp.execPtraceFunc(func() { err = sys.PtraceSetRegs(thread.ID, (*sys.PtraceRegs)(r.Regs)) })
-	return err
-}
-
 // SetReg changes the value of the specified register.
 func (thread *nativeThread) SetReg(regNum uint64, reg *op.DwarfRegister) error {
 	ir, err := registers(thread)
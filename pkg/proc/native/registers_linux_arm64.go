@@ -2,14 +2,12 @@ package native
 
 import (
 	"debug/elf"
-	"fmt"
 	"syscall"
 	"unsafe"
 
 	sys "golang.org/x/sys/unix"
 
 	"github.com/go-delve/delve/pkg/dwarf/op"
-	"github.com/go-delve/delve/pkg/dwarf/regnum"
 	"github.com/go-delve/delve/pkg/proc"
 	"github.com/go-delve/delve/pkg/proc/linutil"
 )
@@ -66,36 +64,15 @@ func ptraceGetFpRegset(tid int) (fpregset []byte, err error) {
 	return fpregset, err
 }
 
-// setPC sets PC to the value specified by 'pc'.
-func (thread *nativeThread) setPC(pc uint64) error {
-	ir, err := registers(thread)
-	if err != nil {
-		return err
-	}
-	r := ir.(*linutil.ARM64Registers)
-	r.Regs.Pc = pc
-	thread.dbp.execPtraceFunc(func() { err = ptraceSetGRegs(thread.ID, r.Regs) })
-	return err
-}
-
 func (thread *nativeThread) SetReg(regNum uint64, reg *op.DwarfRegister) error {
 	ir, err := registers(thread)
 	if err != nil {
 		return err
 	}
 	r := ir.(*linutil.ARM64Registers)
-
-	switch regNum {
-	case regnum.ARM64_PC:
-		r.Regs.Pc = reg.Uint64Val
-	case regnum.ARM64_SP:
-		r.Regs.Sp = reg.Uint64Val
-	default:
-		//TODO(aarzilli): when the register calling convention is adopted by Go on
-		// arm64 this should be implemented.
-		return fmt.Errorf("changing register %d not implemented", regNum)
+	if _, err := r.SetReg(regNum, reg); err != nil {
+		return err
 	}
-
 	thread.dbp.execPtraceFunc(func() { err = ptraceSetGRegs(thread.ID, r.Regs) })
 	return err
 }
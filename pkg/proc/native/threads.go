@@ -60,7 +60,9 @@ func (t *nativeThread) StepInstruction() (err error) {
 			return err
 		}
 		defer func() {
-			err = t.writeHardwareBreakpoint(bp.Addr, bp.WatchType, bp.HWBreakIndex)
+			if err1 := t.writeHardwareBreakpoint(bp.Addr, bp.WatchType, bp.HWBreakIndex); err == nil {
+				err = err1
+			}
 		}()
 	}
 
@@ -79,7 +81,9 @@ func (t *nativeThread) StepInstruction() (err error) {
 
 		// Restore breakpoint now that we have passed it.
 		defer func() {
-			err = t.dbp.writeSoftwareBreakpoint(t, bp.Addr)
+			if err1 := t.dbp.writeSoftwareBreakpoint(t, bp.Addr); err == nil {
+				err = err1
+			}
 		}()
 	}
 
@@ -88,7 +92,7 @@ func (t *nativeThread) StepInstruction() (err error) {
 		if _, exited := err.(proc.ErrProcessExited); exited {
 			return err
 		}
-		return fmt.Errorf("step failed: %s", err.Error())
+		return fmt.Errorf("step failed: %w", err)
 	}
 	return nil
 }
@@ -173,7 +177,7 @@ func (t *nativeThread) ThreadID() int {
 // clearSoftwareBreakpoint clears the specified breakpoint.
 func (t *nativeThread) clearSoftwareBreakpoint(bp *proc.Breakpoint) error {
 	if _, err := t.WriteMemory(bp.Addr, bp.OriginalData); err != nil {
-		return fmt.Errorf("could not clear breakpoint %s", err)
+		return fmt.Errorf("could not clear breakpoint %w", err)
 	}
 	return nil
 }
@@ -55,7 +55,12 @@ func (t *nativeThread) resumeWithSig(sig int) (err error) {
 }
 
 func (t *nativeThread) singleStep() (err error) {
-	t.dbp.execPtraceFunc(func() { err = ptraceSingleStep(t.ID) })
+	t.dbp.execPtraceFunc(func() {
+		// the thread could have been suspended by resume
+		if err = ptraceResume(t.ID); err == nil {
+			err = ptraceSingleStep(t.ID)
+		}
+	})
 	if err != nil {
 		return err
 	}
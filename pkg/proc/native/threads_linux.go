@@ -1,10 +1,13 @@
 package native
 
 import (
+	"encoding/binary"
+	"errors"
 	"fmt"
 
 	sys "golang.org/x/sys/unix"
 
+	"github.com/go-delve/delve/pkg/dwarf/op"
 	"github.com/go-delve/delve/pkg/proc"
 )
 
@@ -19,10 +22,19 @@ type osSpecificDetails struct {
 	phantomBreakpointPC uint64
 }
 
+// setPC sets the program counter of the thread to pc.
+func (t *nativeThread) setPC(pc uint64) error {
+	return t.SetReg(t.dbp.bi.Arch.PCRegNum, op.DwarfRegisterFromUint64(pc))
+}
+
 func (t *nativeThread) stop() (err error) {
 	err = sys.Tgkill(t.dbp.pid, t.ID, sys.SIGSTOP)
+	if errors.Is(err, sys.ESRCH) {
+		// the thread exited, let the caller handle it
+		return
+	}
 	if err != nil {
-		err = fmt.Errorf("stop err %s on thread %d", err, t.ID)
+		err = fmt.Errorf("stop err %w on thread %d", err, t.ID)
 		return
 	}
 	return
@@ -38,9 +50,39 @@ func (t *nativeThread) Stopped() bool {
 func (t *nativeThread) resume() error {
 	sig := t.os.delayedSignal
 	t.os.delayedSignal = 0
+	t.common.Signal = nil
 	return t.resumeWithSig(sig)
 }
 
+// isFaultSignal returns true if sig is one of the signals that are held
+// back and reported to the user before being delivered, see
+// proc.SignalInfo.
+func isFaultSignal(sig sys.Signal) bool {
+	return sig == sys.SIGSEGV || sig == sys.SIGBUS
+}
+
+// holdFaultSignal records that the thread received the fault signal sig,
+// which will be delivered to it when it is resumed.
+func (t *nativeThread) holdFaultSignal(sig sys.Signal) {
+	t.os.delayedSignal = int(sig)
+	info := &proc.SignalInfo{Signo: int(sig)}
+	var buf [128]byte
+	var err error
+	t.dbp.execPtraceFunc(func() { err = ptraceGetSiginfo(t.ID, &buf) })
+	if err == nil {
+		// siginfo_t starts with si_signo, si_errno and si_code, for SIGSEGV and
+		// SIGBUS the union that follows starts with si_addr, aligned to the
+		// size of a pointer.
+		info.Code = int(int32(binary.LittleEndian.Uint32(buf[8:])))
+		if t.dbp.bi.Arch.PtrSize() == 4 {
+			info.Addr = uint64(binary.LittleEndian.Uint32(buf[12:]))
+		} else {
+			info.Addr = binary.LittleEndian.Uint64(buf[16:])
+		}
+	}
+	t.common.Signal = info
+}
+
 func (t *nativeThread) resumeWithSig(sig int) (err error) {
 	t.os.running = true
 	t.dbp.execPtraceFunc(func() { err = ptraceCont(t.ID, sig) })
@@ -48,8 +90,17 @@ func (t *nativeThread) resumeWithSig(sig int) (err error) {
 }
 
 func (t *nativeThread) singleStep() (err error) {
+	sig := 0
+	if t.common.Signal != nil {
+		// Deliver the fault signal held back for the thread, otherwise the
+		// faulting instruction would be executed again.
+		sig = t.os.delayedSignal
+		t.os.delayedSignal = 0
+		t.common.Signal = nil
+	}
 	for {
-		t.dbp.execPtraceFunc(func() { err = sys.PtraceSingleStep(t.ID) })
+		t.dbp.execPtraceFunc(func() { err = ptraceSingleStepWithSig(t.ID, sig) })
+		sig = 0
 		if err != nil {
 			return err
 		}
@@ -57,17 +108,21 @@ func (t *nativeThread) singleStep() (err error) {
 		if err != nil {
 			return err
 		}
-		if (status == nil || status.Exited()) && wpid == t.dbp.pid {
+		if (status == nil || status.Exited() || status.Signaled()) && wpid == t.dbp.pid {
 			t.dbp.postExit()
-			rs := 0
-			if status != nil {
-				rs = status.ExitStatus()
+			if status == nil {
+				return proc.ErrProcessExited{Pid: t.dbp.pid}
 			}
-			return proc.ErrProcessExited{Pid: t.dbp.pid, Status: rs}
+			return proc.NewErrProcessExited(t.dbp.pid, status)
 		}
 		if wpid == t.ID && status.StopSignal() == sys.SIGTRAP {
 			return nil
 		}
+		if wpid == t.ID && isFaultSignal(status.StopSignal()) {
+			// The instruction faulted, the thread is stopped on it.
+			t.holdFaultSignal(status.StopSignal())
+			return nil
+		}
 	}
 }
 
@@ -102,3 +157,10 @@ func (t *nativeThread) ReadMemory(data []byte, addr uint64) (n int, err error) {
 	}
 	return
 }
+
+// ConcurrentReads returns true, process_vm_readv can be called by multiple
+// goroutines at the same time and the fallback on PTRACE_PEEKDATA goes
+// through execPtraceFunc.
+func (t *nativeThread) ConcurrentReads() bool {
+	return true
+}
@@ -0,0 +1,67 @@
+package proc
+
+import (
+	"go/constant"
+	"strings"
+)
+
+// netpollStackDepth is the depth of the stacktraces searched by
+// NetpollWaiters.
+const netpollStackDepth = 20
+
+// NetpollWaiters returns the IDs of the goroutines blocked waiting for a
+// file descriptor to become ready for reading or writing, indexed by file
+// descriptor.
+// A goroutine is blocked on a file descriptor if it is inside
+// runtime.poll_runtime_pollWait, the file descriptor is read from the
+// pollDesc argument of that frame or from the receiver of the calling
+// methods of internal/poll.FD.
+func NetpollWaiters(t *Target) (map[int][]int, error) {
+	gs, _, err := GoroutinesInfo(t, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	r := map[int][]int{}
+	for _, g := range gs {
+		if g.Status != Gwaiting {
+			continue
+		}
+		frames, err := g.Stacktrace(netpollStackDepth, 0)
+		if err != nil {
+			continue
+		}
+		if fd, ok := netpollFD(t, g, frames); ok {
+			r[fd] = append(r[fd], g.ID)
+		}
+	}
+	return r, nil
+}
+
+// netpollFD returns the file descriptor the goroutine g, with stacktrace
+// frames, is blocked on.
+func netpollFD(t *Target, g *G, frames []Stackframe) (int, bool) {
+	waiting := false
+	for i := range frames {
+		if frames[i].Call.Fn == nil {
+			continue
+		}
+		var expr string
+		switch name := frames[i].Call.Fn.Name; {
+		case name == "runtime.poll_runtime_pollWait" || name == "internal/poll.runtime_pollWait":
+			waiting = true
+			expr = "pd.fd"
+		case waiting && strings.HasPrefix(name, "internal/poll.(*FD)."):
+			expr = "fd.Sysfd"
+		default:
+			continue
+		}
+		scope := FrameToScope(t, t.BinInfo(), t.Memory(), g, frames[i:]...)
+		v, err := scope.EvalExpression(expr, loadSingleValue)
+		if err != nil || v.Unreadable != nil || v.Value == nil {
+			continue
+		}
+		fd, _ := constant.Int64Val(constant.ToInt(v.Value))
+		return int(fd), true
+	}
+	return 0, false
+}
@@ -1,8 +1,11 @@
 package proc
 
 import (
+	"errors"
 	"path/filepath"
+	"reflect"
 	"runtime"
+	"syscall"
 	"testing"
 	"unsafe"
 
@@ -120,6 +123,15 @@ func TestDwarfVersion(t *testing.T) {
 	}
 }
 
+func TestExecutablePlatform(t *testing.T) {
+	fixture := protest.BuildFixture("math", 0)
+	goos, goarch, err := ExecutablePlatform(fixture.Path)
+	assertNoError(err, t, "ExecutablePlatform")
+	if goos != runtime.GOOS || goarch != runtime.GOARCH {
+		t.Errorf("expected %s/%s got %s/%s", runtime.GOOS, runtime.GOARCH, goos, goarch)
+	}
+}
+
 func TestRegabiFlagSentinel(t *testing.T) {
 	// Detect if the regabi flag in the producer string gets removed
 	if !goversion.VersionAfterOrEqual(runtime.Version(), 1, 17) || runtime.GOARCH != "amd64" {
@@ -132,3 +144,88 @@ func TestRegabiFlagSentinel(t *testing.T) {
 		t.Errorf("regabi flag not set")
 	}
 }
+
+type fakeWaitStatus struct {
+	exited   bool
+	code     int
+	signal   syscall.Signal
+	coreDump bool
+}
+
+func (ws fakeWaitStatus) Exited() bool           { return ws.exited }
+func (ws fakeWaitStatus) ExitStatus() int        { return ws.code }
+func (ws fakeWaitStatus) Signaled() bool         { return ws.signal != 0 }
+func (ws fakeWaitStatus) Signal() syscall.Signal { return ws.signal }
+func (ws fakeWaitStatus) CoreDump() bool         { return ws.coreDump }
+
+func TestNewErrProcessExited(t *testing.T) {
+	tests := []struct {
+		ws  WaitStatus
+		tgt ErrProcessExited
+	}{
+		{nil, ErrProcessExited{Pid: 1}},
+		{fakeWaitStatus{exited: true, code: 3}, ErrProcessExited{Pid: 1, Status: 3}},
+		{fakeWaitStatus{signal: 9}, ErrProcessExited{Pid: 1, Status: -9, Signal: 9}},
+		{fakeWaitStatus{signal: 11, coreDump: true}, ErrProcessExited{Pid: 1, Status: -11, Signal: 11, CoreDumped: true}},
+	}
+	for _, tc := range tests {
+		if pe := NewErrProcessExited(1, tc.ws); pe != tc.tgt {
+			t.Errorf("for %#v got %#v expected %#v", tc.ws, pe, tc.tgt)
+		}
+	}
+}
+
+func TestBreakpointWriteError(t *testing.T) {
+	err := error(BreakpointWriteError{Addr: 0x4a1b20, Err: errors.New("input/output error")})
+	if got, want := err.Error(), "could not set breakpoint at 0x4a1b20: input/output error"; got != want {
+		t.Errorf("got %q expected %q", got, want)
+	}
+	err = BreakpointWriteError{Addr: 0x4a1b20, Erase: true, Err: errors.New("input/output error")}
+	if got, want := err.Error(), "could not clear breakpoint at 0x4a1b20: input/output error"; got != want {
+		t.Errorf("got %q expected %q", got, want)
+	}
+}
+
+func TestParseModInfo(t *testing.T) {
+	const modinfo = "path\texample.com/cmd/prog\n" +
+		"mod\texample.com\t(devel)\t\n" +
+		"dep\tgolang.org/x/arch\tv0.0.0-20190927153633-4e8777c89be4\th1:QlVATYS7JBoZMVaf+cNjb90WD/beKVHnIxFKT4QaHVI=\n" +
+		"dep\tgolang.org/x/sys\tv0.1.0\n" +
+		"=>\t../sys\t(devel)\t\n" +
+		"build\t-compiler=gc\n" +
+		"build\tvcs.revision=0123456789abcdef\n" +
+		"build\tvcs.modified=true\n" +
+		"build\t-ldflags=\"-X main.version=1.0\"\n" +
+		"build\t\"key=with equal\"=value\n"
+	bi, err := parseModInfo(modinfo)
+	assertNoError(err, t, "parseModInfo")
+	tgt := &BuildInfo{
+		Path: "example.com/cmd/prog",
+		Main: Module{Path: "example.com", Version: "(devel)"},
+		Deps: []*Module{
+			{Path: "golang.org/x/arch", Version: "v0.0.0-20190927153633-4e8777c89be4", Sum: "h1:QlVATYS7JBoZMVaf+cNjb90WD/beKVHnIxFKT4QaHVI="},
+			{Path: "golang.org/x/sys", Version: "v0.1.0", Replace: &Module{Path: "../sys", Version: "(devel)"}},
+		},
+		Settings: []BuildSetting{{"-compiler", "gc"}, {"vcs.revision", "0123456789abcdef"}, {"vcs.modified", "true"}, {"-ldflags", "-X main.version=1.0"}, {"key=with equal", "value"}},
+	}
+	if !reflect.DeepEqual(bi, tgt) {
+		t.Errorf("got %#v expected %#v", bi, tgt)
+	}
+
+	for _, bad := range []string{"mod\texample.com\n", "=>\t../sys\t(devel)\n", "build\t-compiler\n", "build\t=gc\n", "build\t-ldflags=\"-X\n"} {
+		if _, err := parseModInfo(bad); err == nil {
+			t.Errorf("no error parsing %q", bad)
+		}
+	}
+}
+
+func TestBuildInfo(t *testing.T) {
+	fixture := protest.BuildFixture("math", 0)
+	bi := NewBinaryInfo(runtime.GOOS, runtime.GOARCH)
+	assertNoError(bi.LoadBinaryInfo(fixture.Path, 0, nil), t, "LoadBinaryInfo")
+	info, err := bi.BuildInfo()
+	assertNoError(err, t, "BuildInfo")
+	if info.GoVersion != runtime.Version() {
+		t.Errorf("wrong Go version %q, expected %q", info.GoVersion, runtime.Version())
+	}
+}
@@ -2,6 +2,7 @@ package proc_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"flag"
 	"fmt"
@@ -20,6 +21,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"syscall"
 	"testing"
 	"time"
 
@@ -101,9 +103,9 @@ func withTestProcessArgs(name string, t testing.TB, wd string, args []string, bu
 
 	switch testBackend {
 	case "native":
-		p, err = native.Launch(append([]string{fixture.Path}, args...), wd, 0, []string{}, "", [3]string{})
+		p, err = native.Launch(append([]string{fixture.Path}, args...), wd, 0, []string{}, "", "", proc.OutputRedirect{}, proc.OutputRedirect{})
 	case "lldb":
-		p, err = gdbserial.LLDBLaunch(append([]string{fixture.Path}, args...), wd, 0, []string{}, "", [3]string{})
+		p, err = gdbserial.LLDBLaunch(append([]string{fixture.Path}, args...), wd, 0, []string{}, "", "", proc.OutputRedirect{}, proc.OutputRedirect{})
 	case "rr":
 		protest.MustHaveRecordingAllowed(t)
 		t.Log("recording")
@@ -187,6 +189,106 @@ func TestExit(t *testing.T) {
 	})
 }
 
+func TestExitKilledBySignal(t *testing.T) {
+	// The exit of a process killed by a signal reports the signal.
+	if runtime.GOOS == "windows" {
+		t.Skip("signals not supported on windows")
+	}
+	protest.AllowRecording(t)
+	withTestProcess("killself", t, func(p *proc.Target, fixture protest.Fixture) {
+		err := p.Continue()
+		pe, ok := err.(proc.ErrProcessExited)
+		if !ok {
+			t.Fatalf("Continue() returned unexpected error type %s", err)
+		}
+		if pe.Signal != int(syscall.SIGKILL) || pe.Status != -int(syscall.SIGKILL) {
+			t.Errorf("Unexpected exit: status %d signal %d", pe.Status, pe.Signal)
+		}
+		if _, err := p.Valid(); err != pe {
+			t.Errorf("Valid() returned %v, expected %v", err, pe)
+		}
+	})
+}
+
+func TestFaultSignal(t *testing.T) {
+	// The target stops on the faulting instruction before the runtime
+	// converts a SIGSEGV into a panic.
+	skipUnlessOn(t, "only implemented on linux", "linux")
+	if testBackend != "native" {
+		t.Skip("only implemented on the native backend")
+	}
+	withTestProcess("faultsignal", t, func(p *proc.Target, fixture protest.Fixture) {
+		p.StopOnFault = true
+		assertNoError(p.Continue(), t, "Continue()")
+		if p.StopReason != proc.StopSignal {
+			t.Fatalf("expected stop on signal, got %v", p.StopReason)
+		}
+		sig := p.CurrentThread().Common().Signal
+		if sig == nil {
+			t.Fatal("no signal recorded for the current thread")
+		}
+		if sig.Signo != int(syscall.SIGSEGV) || sig.Addr != 8 {
+			t.Errorf("unexpected signal info %#v", sig)
+		}
+		if _, ln := currentLineNumber(p, t); ln != 12 {
+			t.Errorf("stopped at line %d, expected 12", ln)
+		}
+		assertNoError(p.Continue(), t, "second Continue()")
+		if p.StopReason != proc.StopPanic {
+			t.Errorf("expected the fault to be converted into a panic, got %v", p.StopReason)
+		}
+	})
+}
+
+func TestFaultSignalDefault(t *testing.T) {
+	// Without StopOnFault faults in Go code are delivered to the target,
+	// which can recover from the panic.
+	skipUnlessOn(t, "only implemented on linux", "linux")
+	if testBackend != "native" {
+		t.Skip("only implemented on the native backend")
+	}
+	withTestProcess("issue594", t, func(p *proc.Target, fixture protest.Fixture) {
+		assertNoError(p.Continue(), t, "Continue()")
+		if p.StopReason != proc.StopHardcodedBreakpoint {
+			t.Errorf("expected stop on runtime.Breakpoint, got %v", p.StopReason)
+		}
+		if _, ln := currentLineNumber(p, t); ln != 21 {
+			t.Errorf("stopped at line %d, expected 21", ln)
+		}
+	})
+	withTestProcess("issue594", t, func(p *proc.Target, fixture protest.Fixture) {
+		p.StopOnFault = true
+		assertNoError(p.Continue(), t, "Continue()")
+		if p.StopReason != proc.StopSignal {
+			t.Fatalf("expected stop on signal, got %v", p.StopReason)
+		}
+		if _, ln := currentLineNumber(p, t); ln != 15 {
+			t.Errorf("stopped at line %d, expected 15", ln)
+		}
+		assertNoError(p.Continue(), t, "second Continue()")
+		if _, ln := currentLineNumber(p, t); ln != 21 {
+			t.Errorf("stopped at line %d after the fault, expected 21", ln)
+		}
+	})
+}
+
+func TestThreadsExitingDuringContinue(t *testing.T) {
+	// Threads that exit while delve is stopping or resuming the target must
+	// not make Continue fail.
+	withTestProcess("threadchurn", t, func(p *proc.Target, fixture protest.Fixture) {
+		bp := setFunctionBreakpoint(p, t, "main.work")
+		for i := 0; i < 100; i++ {
+			assertNoError(p.Continue(), t, fmt.Sprintf("Continue() %d", i))
+		}
+		_, err := p.ClearBreakpoint(bp.Addr)
+		assertNoError(err, t, "ClearBreakpoint()")
+		err = p.Continue()
+		if _, exited := err.(proc.ErrProcessExited); !exited {
+			t.Fatalf("expected the process to exit, got %v", err)
+		}
+	})
+}
+
 func TestExitAfterContinue(t *testing.T) {
 	protest.AllowRecording(t)
 	withTestProcess("continuetestprog", t, func(p *proc.Target, fixture protest.Fixture) {
@@ -318,8 +420,8 @@ func TestBreakpoint(t *testing.T) {
 		assertNoError(err, t, "Registers")
 		pc := regs.PC()
 
-		if bp.UserBreaklet().TotalHitCount != 1 {
-			t.Fatalf("Breakpoint should be hit once, got %d\n", bp.UserBreaklet().TotalHitCount)
+		if bp.Logical.TotalHitCount != 1 {
+			t.Fatalf("Breakpoint should be hit once, got %d\n", bp.Logical.TotalHitCount)
 		}
 
 		if pc-1 != bp.Addr && pc != bp.Addr {
@@ -377,6 +479,35 @@ func TestClearBreakpointBreakpoint(t *testing.T) {
 	})
 }
 
+func TestClearBreakpointKeepsInternalBreaklets(t *testing.T) {
+	// Clearing a user breakpoint that shares its address with an internal
+	// breakpoint must detach the internal breakpoint from the logical
+	// breakpoint, which is deleted.
+	withTestProcess("testprog", t, func(p *proc.Target, fixture protest.Fixture) {
+		bp := setFunctionBreakpoint(p, t, "main.sleepytime")
+		logicalID := bp.LogicalID
+		_, err := p.SetBreakpoint(bp.Addr, proc.NextBreakpoint, nil)
+		assertNoError(err, t, "SetBreakpoint(NextBreakpoint)")
+
+		cleared, err := p.ClearBreakpoint(bp.Addr)
+		assertNoError(err, t, "ClearBreakpoint()")
+		if cleared.LogicalID != logicalID || cleared.Logical == nil {
+			t.Errorf("wrong cleared breakpoint: LogicalID=%d Logical=%v, expected LogicalID=%d", cleared.LogicalID, cleared.Logical, logicalID)
+		}
+
+		internal := p.Breakpoints().M[bp.Addr]
+		if internal == nil {
+			t.Fatal("internal breakpoint removed")
+		}
+		if internal.IsUser() || internal.Logical != nil || internal.LogicalID != 0 {
+			t.Errorf("internal breakpoint still belongs to logical breakpoint %d: IsUser=%v LogicalID=%d Logical=%v", logicalID, internal.IsUser(), internal.LogicalID, internal.Logical)
+		}
+		if _, ok := p.Breakpoints().Logical[logicalID]; ok {
+			t.Errorf("logical breakpoint %d not deleted", logicalID)
+		}
+	})
+}
+
 type nextTest struct {
 	begin, end int
 }
@@ -944,6 +1075,43 @@ func stackMatch(stack []loc, locations []proc.Stackframe, skipRuntime bool) bool
 	return i >= len(stack)
 }
 
+func TestCachedStacktrace(t *testing.T) {
+	// CachedStacktrace returns the same frames as Stacktrace, whatever the
+	// depth of the stack it cached previously.
+	protest.AllowRecording(t)
+	withTestProcess("deepstack", t, func(p *proc.Target, fixture protest.Fixture) {
+		assertNoError(p.Continue(), t, "Continue()")
+		g, err := proc.GetG(p.CurrentThread())
+		assertNoError(err, t, "GetG()")
+		full, err := g.Stacktrace(2000, 0)
+		assertNoError(err, t, "Stacktrace()")
+		if !full[len(full)-1].Bottom {
+			t.Fatalf("stack deeper than expected")
+		}
+		for _, depth := range []int{10, 50, 49, 200, 1800, 3000, 5} {
+			frames, err := proc.CachedStacktrace(p, g, depth, 0)
+			assertNoError(err, t, fmt.Sprintf("CachedStacktrace(%d)", depth))
+			n := depth + 1
+			if n > len(full) {
+				n = len(full)
+			}
+			if len(frames) != n {
+				t.Errorf("depth %d: wrong number of frames %d, expected %d", depth, len(frames), n)
+				continue
+			}
+			for i := range frames {
+				if frames[i].Current.PC != full[i].Current.PC || frames[i].Regs.CFA != full[i].Regs.CFA {
+					t.Errorf("depth %d: frame %d mismatch", depth, i)
+					break
+				}
+			}
+			if frames[n-1].Bottom != (n == len(full)) {
+				t.Errorf("depth %d: wrong Bottom flag on the last frame", depth)
+			}
+		}
+	})
+}
+
 func TestStacktraceGoroutine(t *testing.T) {
 	skipOn(t, "broken - cgo stacktraces", "darwin", "arm64")
 
@@ -1460,18 +1628,18 @@ func TestBreakpointCounts(t *testing.T) {
 			}
 		}
 
-		t.Logf("TotalHitCount: %d", bp.UserBreaklet().TotalHitCount)
-		if bp.UserBreaklet().TotalHitCount != 200 {
-			t.Fatalf("Wrong TotalHitCount for the breakpoint (%d)", bp.UserBreaklet().TotalHitCount)
+		t.Logf("TotalHitCount: %d", bp.Logical.TotalHitCount)
+		if bp.Logical.TotalHitCount != 200 {
+			t.Fatalf("Wrong TotalHitCount for the breakpoint (%d)", bp.Logical.TotalHitCount)
 		}
 
-		if len(bp.UserBreaklet().HitCount) != 2 {
-			t.Fatalf("Wrong number of goroutines for breakpoint (%d)", len(bp.UserBreaklet().HitCount))
+		if len(bp.Logical.HitCount) != 2 {
+			t.Fatalf("Wrong number of goroutines for breakpoint (%d)", len(bp.Logical.HitCount))
 		}
 
-		for _, v := range bp.UserBreaklet().HitCount {
+		for _, v := range bp.Logical.HitCount {
 			if v != 100 {
-				t.Fatalf("Wrong HitCount for breakpoint (%v)", bp.UserBreaklet().HitCount)
+				t.Fatalf("Wrong HitCount for breakpoint (%v)", bp.Logical.HitCount)
 			}
 		}
 	})
@@ -1527,23 +1695,23 @@ func TestBreakpointCountsWithDetection(t *testing.T) {
 				total += m[i] + 1
 			}
 
-			if uint64(total) != bp.UserBreaklet().TotalHitCount {
-				t.Fatalf("Mismatched total count %d %d\n", total, bp.UserBreaklet().TotalHitCount)
+			if uint64(total) != bp.Logical.TotalHitCount {
+				t.Fatalf("Mismatched total count %d %d\n", total, bp.Logical.TotalHitCount)
 			}
 		}
 
-		t.Logf("TotalHitCount: %d", bp.UserBreaklet().TotalHitCount)
-		if bp.UserBreaklet().TotalHitCount != 200 {
-			t.Fatalf("Wrong TotalHitCount for the breakpoint (%d)", bp.UserBreaklet().TotalHitCount)
+		t.Logf("TotalHitCount: %d", bp.Logical.TotalHitCount)
+		if bp.Logical.TotalHitCount != 200 {
+			t.Fatalf("Wrong TotalHitCount for the breakpoint (%d)", bp.Logical.TotalHitCount)
 		}
 
-		if len(bp.UserBreaklet().HitCount) != 2 {
-			t.Fatalf("Wrong number of goroutines for breakpoint (%d)", len(bp.UserBreaklet().HitCount))
+		if len(bp.Logical.HitCount) != 2 {
+			t.Fatalf("Wrong number of goroutines for breakpoint (%d)", len(bp.Logical.HitCount))
 		}
 
-		for _, v := range bp.UserBreaklet().HitCount {
+		for _, v := range bp.Logical.HitCount {
 			if v != 100 {
-				t.Fatalf("Wrong HitCount for breakpoint (%v)", bp.UserBreaklet().HitCount)
+				t.Fatalf("Wrong HitCount for breakpoint (%v)", bp.Logical.HitCount)
 			}
 		}
 	})
@@ -1715,6 +1883,26 @@ func TestCondBreakpointError(t *testing.T) {
 	})
 }
 
+func TestCallerBreakpoint(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestProcess("callme", t, func(p *proc.Target, fixture protest.Fixture) {
+		bp := setFunctionBreakpoint(p, t, "fmt.Println")
+		bp.UserBreaklet().Caller = "main.callme2"
+
+		assertNoError(p.Continue(), t, "Continue()")
+		frames, err := proc.ThreadStacktrace(p.CurrentThread(), 1)
+		assertNoError(err, t, "ThreadStacktrace()")
+		if len(frames) < 2 || frames[1].Call.Fn == nil || frames[1].Call.Fn.Name != "main.callme2" {
+			t.Fatalf("stopped with the wrong caller: %v", frames)
+		}
+
+		err = p.Continue()
+		if _, exited := err.(proc.ErrProcessExited); !exited {
+			t.Fatalf("Unexpected error on Continue(): %v", err)
+		}
+	})
+}
+
 func TestHitCondBreakpointEQ(t *testing.T) {
 	withTestProcess("break", t, func(p *proc.Target, fixture protest.Fixture) {
 		bp := setFileBreakpoint(p, t, fixture.Source, 7)
@@ -1932,7 +2120,7 @@ func TestPackageVariables(t *testing.T) {
 		assertNoError(err, t, "Continue()")
 		scope, err := proc.GoroutineScope(p, p.CurrentThread())
 		assertNoError(err, t, "Scope()")
-		vars, err := scope.PackageVariables(normalLoadConfig)
+		vars, err := scope.PackageVariables(nil, normalLoadConfig)
 		assertNoError(err, t, "PackageVariables()")
 		failed := false
 		for _, v := range vars {
@@ -1947,6 +2135,32 @@ func TestPackageVariables(t *testing.T) {
 		if failed {
 			t.Fatalf("previous errors")
 		}
+
+		// The filter is applied before the variables are read and must return
+		// the same variables, with the same values, as filtering the full list.
+		filterCalls := 0
+		mainvars, err := scope.PackageVariables(func(name string) bool {
+			filterCalls++
+			return strings.HasPrefix(name, "main.")
+		}, normalLoadConfig)
+		assertNoError(err, t, "PackageVariables(main)")
+		if filterCalls < len(vars) {
+			t.Errorf("filter called %d times for %d variables", filterCalls, len(vars))
+		}
+		expected := map[string]string{}
+		for _, v := range vars {
+			if strings.HasPrefix(v.Name, "main.") {
+				expected[v.Name] = api.ConvertVar(v).SinglelineString()
+			}
+		}
+		if len(mainvars) == 0 || len(mainvars) != len(expected) {
+			t.Fatalf("wrong number of variables in package main: %d, expected %d", len(mainvars), len(expected))
+		}
+		for _, v := range mainvars {
+			if s := api.ConvertVar(v).SinglelineString(); s != expected[v.Name] {
+				t.Errorf("variable %s: %s, expected %s", v.Name, s, expected[v.Name])
+			}
+		}
 	})
 }
 
@@ -2162,9 +2376,9 @@ func TestUnsupportedArch(t *testing.T) {
 
 	switch testBackend {
 	case "native":
-		p, err = native.Launch([]string{outfile}, ".", 0, []string{}, "", [3]string{})
+		p, err = native.Launch([]string{outfile}, ".", 0, []string{}, "", "", proc.OutputRedirect{}, proc.OutputRedirect{})
 	case "lldb":
-		p, err = gdbserial.LLDBLaunch([]string{outfile}, ".", 0, []string{}, "", [3]string{})
+		p, err = gdbserial.LLDBLaunch([]string{outfile}, ".", 0, []string{}, "", "", proc.OutputRedirect{}, proc.OutputRedirect{})
 	default:
 		t.Skip("test not valid for this backend")
 	}
@@ -5299,6 +5513,144 @@ func TestWatchpointsBasic(t *testing.T) {
 	})
 }
 
+func TestWatchpointRead(t *testing.T) {
+	skipOn(t, "not implemented", "windows")
+	skipOn(t, "not implemented", "freebsd")
+	skipOn(t, "not implemented", "darwin")
+	skipOn(t, "not implemented", "386")
+	skipOn(t, "not implemented", "arm64")
+	skipOn(t, "not implemented", "rr")
+
+	withTestProcess("databpeasy", t, func(p *proc.Target, fixture protest.Fixture) {
+		setFunctionBreakpoint(p, t, "main.main")
+		assertNoError(p.Continue(), t, "Continue 0")
+		assertLineNumber(p, t, 11, "Continue 0") // Position 0
+		assertNoError(p.Continue(), t, "Continue 1")
+		assertLineNumber(p, t, 19, "Continue 1") // Position 2
+
+		scope, err := proc.GoroutineScope(p, p.CurrentThread())
+		assertNoError(err, t, "GoroutineScope")
+
+		_, err = p.SetWatchpoint(scope, "globalvar1", proc.WatchRead, nil)
+		assertNoError(err, t, "SetDataBreakpoint(read-only)")
+
+		assertNoError(p.Continue(), t, "Continue 2")
+		assertLineNumber(p, t, 20, "Continue 2") // Position 3
+		if p.StopReason != proc.StopWatchpoint {
+			t.Errorf("wrong stop reason %v", p.StopReason)
+		}
+
+		assertNoError(p.Continue(), t, "Continue 3")
+		assertLineNumber(p, t, 21, "Continue 3")
+
+		// The write on line 22 doesn't stop the target.
+		assertNoError(p.Continue(), t, "Continue 4")
+		assertLineNumber(p, t, 23, "Continue 4")
+	})
+}
+
+func TestWatchpointHistory(t *testing.T) {
+	skipOn(t, "not implemented", "windows")
+	skipOn(t, "not implemented", "freebsd")
+	skipOn(t, "not implemented", "darwin")
+	skipOn(t, "not implemented", "386")
+	skipOn(t, "not implemented", "arm64")
+	skipOn(t, "not implemented", "rr")
+
+	withTestProcess("databpeasy", t, func(p *proc.Target, fixture protest.Fixture) {
+		setFunctionBreakpoint(p, t, "main.main")
+		assertNoError(p.Continue(), t, "Continue 0")
+
+		scope, err := proc.GoroutineScope(p, p.CurrentThread())
+		assertNoError(err, t, "GoroutineScope")
+
+		bp, err := p.SetWatchpoint(scope, "globalvar1", proc.WatchWrite, nil)
+		assertNoError(err, t, "SetWatchpoint")
+		assertNoError(p.SetWatchpointHistory(bp, 2), t, "SetWatchpointHistory")
+
+		checkHistory := func(tgt []int64) {
+			t.Helper()
+			entries := bp.Logical.History.Entries
+			if len(entries) != len(tgt) {
+				t.Fatalf("wrong number of history entries, expected %d got %d", len(tgt), len(entries))
+			}
+			for i, e := range entries {
+				if e.GoroutineID != 1 {
+					t.Errorf("entry %d: wrong goroutine %d", i, e.GoroutineID)
+				}
+				if len(e.Stack) == 0 || e.Stack[0].Current.Fn == nil || e.Stack[0].Current.Fn.Name != "main.main" {
+					t.Errorf("entry %d: wrong stacktrace %v", i, e.Stack)
+				}
+				if n, _ := constant.Int64Val(e.Value.Value); n != tgt[i] {
+					t.Errorf("entry %d: expected value %d got %v", i, tgt[i], e.Value.Value)
+				}
+			}
+		}
+
+		// The watchpoint doesn't stop the target, the first manual breakpoint does.
+		assertNoError(p.Continue(), t, "Continue 1")
+		assertLineNumber(p, t, 19, "Continue 1") // Position 2
+		checkHistory([]int64{2})
+
+		assertNoError(p.Continue(), t, "Continue 2")
+		assertLineNumber(p, t, 25, "Continue 2") // Position 4
+		checkHistory([]int64{2, 5})
+
+		assertNoError(p.SetWatchpointHistory(bp, 0), t, "SetWatchpointHistory(0)")
+		assertNoError(p.Continue(), t, "Continue 3")
+		assertLineNumber(p, t, 33, "Continue 3") // Position 5
+	})
+}
+
+func TestBreakpointHistory(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestProcess("break", t, func(p *proc.Target, fixture protest.Fixture) {
+		bp := setFileBreakpoint(p, t, fixture.Source, 7)
+		bp.Variables = []string{"i"}
+		assertNoError(bp.Logical.SetHistory(3), t, "SetHistory")
+
+		// The breakpoint records its hits without stopping the target.
+		err := p.Continue()
+		if _, exited := err.(proc.ErrProcessExited); !exited {
+			t.Fatalf("expected process to exit, got %v", err)
+		}
+
+		entries := bp.Logical.History.Entries
+		if len(entries) != 3 {
+			t.Fatalf("wrong number of history entries, expected 3 got %d", len(entries))
+		}
+		for i, e := range entries {
+			if e.PC != bp.Addr {
+				t.Errorf("entry %d: wrong PC %#x (expected %#x)", i, e.PC, bp.Addr)
+			}
+			if e.Value != nil {
+				t.Errorf("entry %d: unexpected value %v", i, e.Value)
+			}
+			if len(e.Stack) == 0 || e.Stack[0].Current.Fn == nil || e.Stack[0].Current.Fn.Name != "main.main" {
+				t.Errorf("entry %d: wrong stacktrace %v", i, e.Stack)
+			}
+			if len(e.Variables) != 1 || e.Variables[0].Unreadable != nil {
+				t.Fatalf("entry %d: wrong variables %v", i, e.Variables)
+			}
+			if n, _ := constant.Int64Val(e.Variables[0].Value); n != int64(9+i) {
+				t.Errorf("entry %d: expected i = %d got %v", i, 9+i, e.Variables[0].Value)
+			}
+		}
+	})
+}
+
+func TestBuildID(t *testing.T) {
+	withTestProcess("testnextprog", t, func(p *proc.Target, fixture protest.Fixture) {
+		out, err := exec.Command("go", "tool", "buildid", fixture.Path).CombinedOutput()
+		if err != nil {
+			t.Skipf("go tool buildid: %v %s", err, out)
+		}
+		if tgt := strings.TrimSpace(string(out)); p.BinInfo().BuildID != tgt {
+			t.Errorf("wrong build ID %q, expected %q", p.BinInfo().BuildID, tgt)
+		}
+	})
+}
+
 func TestWatchpointCounts(t *testing.T) {
 	skipOn(t, "not implemented", "windows")
 	skipOn(t, "not implemented", "freebsd")
@@ -5327,18 +5679,18 @@ func TestWatchpointCounts(t *testing.T) {
 			}
 		}
 
-		t.Logf("TotalHitCount: %d", bp.UserBreaklet().TotalHitCount)
-		if bp.UserBreaklet().TotalHitCount != 200 {
-			t.Fatalf("Wrong TotalHitCount for the breakpoint (%d)", bp.UserBreaklet().TotalHitCount)
+		t.Logf("TotalHitCount: %d", bp.Logical.TotalHitCount)
+		if bp.Logical.TotalHitCount != 200 {
+			t.Fatalf("Wrong TotalHitCount for the breakpoint (%d)", bp.Logical.TotalHitCount)
 		}
 
-		if len(bp.UserBreaklet().HitCount) != 2 {
-			t.Fatalf("Wrong number of goroutines for breakpoint (%d)", len(bp.UserBreaklet().HitCount))
+		if len(bp.Logical.HitCount) != 2 {
+			t.Fatalf("Wrong number of goroutines for breakpoint (%d)", len(bp.Logical.HitCount))
 		}
 
-		for _, v := range bp.UserBreaklet().HitCount {
+		for _, v := range bp.Logical.HitCount {
 			if v != 100 {
-				t.Fatalf("Wrong HitCount for breakpoint (%v)", bp.UserBreaklet().HitCount)
+				t.Fatalf("Wrong HitCount for breakpoint (%v)", bp.Logical.HitCount)
 			}
 		}
 	})
@@ -5416,3 +5768,281 @@ func TestDwrapStartLocation(t *testing.T) {
 		}
 	})
 }
+
+func TestOperationCanceled(t *testing.T) {
+	// Long running operations should stop when the context set with
+	// SetOperationContext is canceled.
+	withTestProcess("goroutinestackprog", t, func(p *proc.Target, fixture protest.Fixture) {
+		setFunctionBreakpoint(p, t, "main.stacktraceme")
+		assertNoError(p.Continue(), t, "Continue()")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		p.SetOperationContext(ctx)
+		_, _, err := proc.GoroutinesInfo(p, 0, 0)
+		if err != proc.ErrOperationCanceled {
+			t.Fatalf("expected ErrOperationCanceled, got %v", err)
+		}
+
+		p.SetOperationContext(nil)
+		_, _, err = proc.GoroutinesInfo(p, 0, 0)
+		assertNoError(err, t, "GoroutinesInfo()")
+	})
+}
+
+func TestGoroutinesStacktraces(t *testing.T) {
+	// GoroutinesStacktraces should return the same stacktraces as calling
+	// Stacktrace on each goroutine, including when only the top frames,
+	// which are read without reading the whole stack, are requested.
+	protest.AllowRecording(t)
+	withTestProcess("goroutinestackprog", t, func(p *proc.Target, fixture protest.Fixture) {
+		setFunctionBreakpoint(p, t, "main.stacktraceme")
+		assertNoError(p.Continue(), t, "Continue()")
+
+		gs, _, err := proc.GoroutinesInfo(p, 0, 0)
+		assertNoError(err, t, "GoroutinesInfo")
+
+		for _, depth := range []int{1, 40} {
+			stacks, err := proc.GoroutinesStacktraces(p, depth, 0)
+			assertNoError(err, t, "GoroutinesStacktraces")
+			if len(stacks) != len(gs) {
+				t.Fatalf("expected %d stacktraces, got %d", len(gs), len(stacks))
+			}
+
+			agoroutineCount := 0
+			for i, g := range gs {
+				if stacks[i].G.ID != g.ID {
+					t.Fatalf("stacktrace %d: expected goroutine %d, got %d", i, g.ID, stacks[i].G.ID)
+				}
+				frames, err := g.Stacktrace(depth, 0)
+				if (err != nil) != (stacks[i].Err != nil) {
+					t.Errorf("depth %d goroutine %d: mismatched errors %v and %v", depth, g.ID, err, stacks[i].Err)
+					continue
+				}
+				if len(frames) != len(stacks[i].Frames) {
+					t.Errorf("depth %d goroutine %d: expected %d frames, got %d", depth, g.ID, len(frames), len(stacks[i].Frames))
+					continue
+				}
+				for j := range frames {
+					if frames[j].Call.PC != stacks[i].Frames[j].Call.PC || frames[j].Regs.CFA != stacks[i].Frames[j].Regs.CFA {
+						t.Errorf("depth %d goroutine %d frame %d: expected %#x (CFA %#x), got %#x (CFA %#x)", depth, g.ID, j, frames[j].Call.PC, frames[j].Regs.CFA, stacks[i].Frames[j].Call.PC, stacks[i].Frames[j].Regs.CFA)
+					}
+				}
+				if stackMatch([]loc{{-1, "main.agoroutine"}}, stacks[i].Frames, true) {
+					agoroutineCount++
+				}
+			}
+			if depth > 1 && agoroutineCount < 10 {
+				t.Errorf("expected at least 10 goroutines in main.agoroutine, got %d", agoroutineCount)
+			}
+		}
+	})
+}
+
+func TestNextCount(t *testing.T) {
+	// NextCount should execute multiple next operations without returning
+	// and stop early when a breakpoint is hit.
+	protest.AllowRecording(t)
+	withTestProcess("testnextprog", t, func(p *proc.Target, fixture protest.Fixture) {
+		setFunctionBreakpoint(p, t, "main.testnext")
+		assertNoError(p.Continue(), t, "Continue()")
+		assertLineNumber(p, t, 17, "Continue()")
+		assertNoError(p.NextCount(3), t, "NextCount(3)")
+		assertLineNumber(p, t, 23, "NextCount(3)")
+
+		setFileBreakpoint(p, t, fixture.Source, 26)
+		assertNoError(p.NextCount(10), t, "NextCount(10)")
+		assertLineNumber(p, t, 26, "NextCount(10)")
+		if p.StopReason != proc.StopBreakpoint {
+			t.Errorf("expected stop on breakpoint, got %v", p.StopReason)
+		}
+	})
+
+	// NextCount reuses the breakpoints of the previous iteration while it
+	// stays in the same frame, it must stop on the same lines as repeated
+	// calls to Next, including through loops and returns.
+	counts := []int{3, 5, 7, 4}
+	var lines []int
+	withTestProcess("testnextprog", t, func(p *proc.Target, fixture protest.Fixture) {
+		setFunctionBreakpoint(p, t, "main.testnext")
+		assertNoError(p.Continue(), t, "Continue()")
+		total := 0
+		for _, n := range counts {
+			total += n
+		}
+		for i := 0; i < total; i++ {
+			assertNoError(p.Next(), t, "Next()")
+			_, ln := currentLineNumber(p, t)
+			lines = append(lines, ln)
+		}
+	})
+	withTestProcess("testnextprog", t, func(p *proc.Target, fixture protest.Fixture) {
+		setFunctionBreakpoint(p, t, "main.testnext")
+		assertNoError(p.Continue(), t, "Continue()")
+		total := 0
+		for _, n := range counts {
+			total += n
+			assertNoError(p.NextCount(n), t, fmt.Sprintf("NextCount(%d)", n))
+			if p.StopReason != proc.StopNextFinished {
+				t.Fatalf("NextCount(%d): wrong stop reason %v", n, p.StopReason)
+			}
+			assertLineNumber(p, t, lines[total-1], fmt.Sprintf("NextCount(%d)", n))
+			if p.Breakpoints().HasSteppingBreakpoints() {
+				t.Fatalf("NextCount(%d): stepping breakpoints left", n)
+			}
+		}
+	})
+}
+
+func TestCoverage(t *testing.T) {
+	// Lines executed after StartCoverage should be recorded, without
+	// stopping the target, lines that are not executed should not.
+	withTestProcess("coverageprog", t, func(p *proc.Target, fixture protest.Fixture) {
+		setFunctionBreakpoint(p, t, "main.main")
+		assertNoError(p.Continue(), t, "Continue()")
+		n, err := p.StartCoverage(func(file string) bool { return file == fixture.Source })
+		assertNoError(err, t, "StartCoverage()")
+		if n == 0 {
+			t.Fatal("no lines instrumented")
+		}
+
+		setFileBreakpoint(p, t, fixture.Source, 15)
+		assertNoError(p.Continue(), t, "Continue()")
+		assertLineNumber(p, t, 15, "Continue()")
+
+		lines, err := p.Coverage()
+		assertNoError(err, t, "Coverage()")
+		executed := map[int]bool{}
+		for _, l := range lines {
+			if l.File != fixture.Source {
+				t.Errorf("line of unexpected file %s:%d", l.File, l.Line)
+			}
+			executed[l.Line] = l.Executed
+		}
+		for _, line := range []int{6, 9, 14} {
+			if !executed[line] {
+				t.Errorf("line %d not recorded as executed", line)
+			}
+		}
+		if v, ok := executed[7]; !ok || v {
+			t.Errorf("line 7 should be instrumented but not executed (%v %v)", v, ok)
+		}
+
+		assertNoError(p.StopCoverage(), t, "StopCoverage()")
+		for _, bp := range p.Breakpoints().M {
+			for _, breaklet := range bp.Breaklets {
+				if breaklet.Kind == proc.CoverageBreakpoint {
+					t.Errorf("coverage breakpoint left at %s:%d", bp.File, bp.Line)
+				}
+			}
+		}
+	})
+}
+
+func TestBranchTrace(t *testing.T) {
+	// The calls to main.f executed after StartBranchTrace should be
+	// reconstructed from the trace.
+	withTestProcess("coverageprog", t, func(p *proc.Target, fixture protest.Fixture) {
+		setFunctionBreakpoint(p, t, "main.main")
+		assertNoError(p.Continue(), t, "Continue()")
+		if err := p.StartBranchTrace(); err != nil {
+			t.Skipf("branch tracing not available: %v", err)
+		}
+		setFileBreakpoint(p, t, fixture.Source, 15)
+		assertNoError(p.Continue(), t, "Continue()")
+
+		evs, err := p.BranchTrace(p.CurrentThread().ThreadID())
+		assertNoError(err, t, "BranchTrace()")
+		calls, returns := 0, 0
+		for _, ev := range evs {
+			fn := p.BinInfo().PCToFunc(ev.To)
+			switch {
+			case ev.Kind == proc.BranchTraceCall && fn != nil && fn.Name == "main.f":
+				calls++
+			case ev.Kind == proc.BranchTraceReturn && fn != nil && fn.Name == "main.main":
+				returns++
+			}
+		}
+		if calls != 3 {
+			t.Errorf("expected 3 calls to main.f, got %d in %v", calls, evs)
+		}
+		if returns < 3 {
+			t.Errorf("expected at least 3 returns to main.main, got %d", returns)
+		}
+	})
+}
+
+func TestBreakpointNonExecutableAddress(t *testing.T) {
+	// Setting a breakpoint on a data address or on an unmapped address must
+	// fail without writing to the memory of the target.
+	protest.AllowRecording(t)
+	withTestProcess("testvariables", t, func(p *proc.Target, fixture protest.Fixture) {
+		if _, err := p.MemoryMap(); err != nil {
+			t.Skip("memory map not supported")
+		}
+		setFunctionBreakpoint(p, t, "main.foobar")
+		assertNoError(p.Continue(), t, "Continue()")
+
+		p1 := evalVariable(p, t, "main.p1")
+		before, err := dataAtAddr(p.Memory(), p1.Addr)
+		assertNoError(err, t, "dataAtAddr")
+
+		_, err = p.SetBreakpoint(p1.Addr, proc.UserBreakpoint, nil)
+		if iae, ok := err.(proc.InvalidAddressError); !ok || iae.Mapping == nil || iae.Mapping.Exec {
+			t.Fatalf("expected InvalidAddressError for a data address, got %v", err)
+		}
+		after, err := dataAtAddr(p.Memory(), p1.Addr)
+		assertNoError(err, t, "dataAtAddr")
+		if !bytes.Equal(before, after) {
+			t.Fatalf("memory of main.p1 changed from %#v to %#v", before, after)
+		}
+
+		_, err = p.SetBreakpoint(8, proc.UserBreakpoint, nil)
+		if iae, ok := err.(proc.InvalidAddressError); !ok || iae.Mapping != nil {
+			t.Fatalf("expected InvalidAddressError for an unmapped address, got %v", err)
+		}
+	})
+}
+
+func TestGenericFunctions(t *testing.T) {
+	if !goversion.VersionAfterOrEqual(runtime.Version(), 1, 18) {
+		t.Skip("generics not supported")
+	}
+	protest.AllowRecording(t)
+	withTestProcess("testvariables_generic", t, func(p *proc.Target, fixture protest.Fixture) {
+		// a breakpoint on a generic function is set on all its instantiations
+		addrs, err := proc.FindFunctionLocation(p, "main.testfn", 0)
+		assertNoError(err, t, "FindFunctionLocation(main.testfn)")
+		if len(addrs) != 3 {
+			t.Fatalf("expected 3 instantiations of main.testfn, got %d", len(addrs))
+		}
+
+		for _, tc := range []struct {
+			arg1, arg2, p string
+		}{
+			{"uint16", "float64", "main.Pair[float64,uint16]"},
+			{"int", "string", "main.Pair[string,int]"},
+			{"*main.Pair[int,string]", "int", "main.Pair[int,*main.Pair[int,string]]"},
+		} {
+			assertNoError(p.Continue(), t, "Continue()")
+			for name, typ := range map[string]string{"arg1": tc.arg1, "arg2": tc.arg2, "p": tc.p} {
+				v := evalVariable(p, t, name)
+				if v.Unreadable != nil {
+					t.Errorf("%s: unreadable: %v", name, v.Unreadable)
+				}
+				if got := v.DwarfType.String(); got != typ {
+					t.Errorf("%s: expected type %q, got %q", name, typ, got)
+				}
+			}
+			scope, err := proc.GoroutineScope(p, p.CurrentThread())
+			assertNoError(err, t, "GoroutineScope()")
+			vars, err := scope.Locals()
+			assertNoError(err, t, "Locals()")
+			for _, v := range vars {
+				if v.Name == ".dict" {
+					t.Errorf("dictionary argument listed in locals")
+				}
+			}
+		}
+	})
+}
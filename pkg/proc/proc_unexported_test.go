@@ -1,7 +1,11 @@
 package proc
 
 import (
+	"errors"
+	"fmt"
 	"testing"
+
+	"github.com/go-delve/delve/pkg/dwarf/godwarf"
 )
 
 func TestAlignAddr(t *testing.T) {
@@ -74,25 +78,103 @@ func TestAlignAddr(t *testing.T) {
 	}
 }
 
-func TestConvertInt(t *testing.T) {
-	var testCases = []struct {
-		in     uint64
-		signed bool
-		size   int64
-		tgt    uint64
+func TestRemoveTypeParams(t *testing.T) {
+	for _, tc := range []struct{ in, tgt string }{
+		{"main.main", "main.main"},
+		{"main.testfn[go.shape.int,go.shape.string]", "main.testfn"},
+		{"main.(*List[go.shape.string]).Push", "main.(*List).Push"},
+		{"main.Map[go.shape.map[string]int,go.shape.[2]int]", "main.Map"},
+		{"type..eq.[2]interface {}", "type..eq.[2]interface {}"},
+	} {
+		if out := removeTypeParams(tc.in); out != tc.tgt {
+			t.Errorf("removeTypeParams(%q): expected %q got %q", tc.in, tc.tgt, out)
+		}
+	}
+}
+
+func TestMatchCaller(t *testing.T) {
+	for _, tc := range []struct {
+		fn, name string
+		tgt      bool
 	}{
-		{1, false, 1, 1},
-		{uint64(0xf0), true, 1, 0xfffffffffffffff0},
-		{uint64(0xf0), false, 1, 0xf0},
-		{uint64(0x70), true, 1, 0x70},
-		{uint64(0x90f0), true, 2, 0xffffffffffff90f0},
-		{uint64(0x90f0), false, 2, 0x90f0},
+		{"main.main", "main.main", true},
+		{"example.com/mypkg.HandleRequest", "mypkg.HandleRequest", true},
+		{"example.com/mypkg.HandleRequest", "example.com/mypkg.HandleRequest", true},
+		{"example.com/notmypkg.HandleRequest", "mypkg.HandleRequest", false},
+		{"example.com/mypkg.HandleRequest", "HandleRequest", false},
+		{"main.handle[go.shape.int]", "main.handle", true},
+	} {
+		if out := matchCaller(&Function{Name: tc.fn}, tc.name); out != tc.tgt {
+			t.Errorf("matchCaller(%q, %q): expected %v got %v", tc.fn, tc.name, tc.tgt, out)
+		}
+	}
+}
+
+// gappedMemory is a fake target memory made of the ranges in mapped, reads
+// touching any other address fail.
+type gappedMemory struct {
+	mapped     map[uint64][]byte
+	concurrent bool
+}
+
+func (mem *gappedMemory) ReadMemory(data []byte, addr uint64) (int, error) {
+	for start, buf := range mem.mapped {
+		if addr >= start && addr+uint64(len(data)) <= start+uint64(len(buf)) {
+			return copy(data, buf[addr-start:]), nil
+		}
 	}
-	for _, tc := range testCases {
-		out := convertInt(tc.in, tc.signed, tc.size)
-		t.Logf("in=%#016x signed=%v size=%d -> %#016x\n", tc.in, tc.signed, tc.size, out)
-		if out != tc.tgt {
-			t.Errorf("expected=%#016x got=%#016x\n", tc.tgt, out)
+	return 0, fmt.Errorf("could not read %#x", addr)
+}
+
+func (mem *gappedMemory) WriteMemory(addr uint64, data []byte) (int, error) {
+	return 0, errors.New("not implemented")
+}
+
+func (mem *gappedMemory) ConcurrentReads() bool {
+	return mem.concurrent
+}
+
+func TestPackageVariablesUnmappedGap(t *testing.T) {
+	// The variables a and c are grouped in a single read with b, which is
+	// in an unmapped page between them, the read of the group fails but a
+	// and c must still be readable.
+	uint64Type := &godwarf.UintType{BasicType: godwarf.BasicType{CommonType: godwarf.CommonType{ByteSize: 8}}}
+	for _, concurrent := range []bool{false, true} {
+		mem := &gappedMemory{
+			mapped: map[uint64][]byte{
+				0x1000: {1, 0, 0, 0, 0, 0, 0, 0},
+				0x1800: {3, 0, 0, 0, 0, 0, 0, 0},
+			},
+			concurrent: concurrent,
+		}
+		vars := []*Variable{
+			{Name: "a", Addr: 0x1000, RealType: uint64Type, mem: mem},
+			{Name: "b", Addr: 0x1400, RealType: uint64Type, mem: mem},
+			{Name: "c", Addr: 0x1800, RealType: uint64Type, mem: mem},
+		}
+		groups := cachePackageVariables(vars)
+		if len(groups) != 1 {
+			t.Fatalf("concurrent=%v: expected the variables to be grouped, got %d groups", concurrent, len(groups))
+		}
+		loadPackageVarsGroups(groups)
+		for _, v := range vars {
+			buf := make([]byte, 8)
+			_, err := v.mem.ReadMemory(buf, v.Addr)
+			switch v.Name {
+			case "b":
+				if err == nil {
+					t.Errorf("concurrent=%v: reading %s did not fail", concurrent, v.Name)
+				}
+			default:
+				if err != nil {
+					t.Errorf("concurrent=%v: reading %s: %v", concurrent, v.Name, err)
+				} else if want := mem.mapped[v.Addr][0]; buf[0] != want {
+					t.Errorf("concurrent=%v: wrong value for %s: %d, expected %d", concurrent, v.Name, buf[0], want)
+				}
+			}
+		}
+		if !groups[0].failed {
+			t.Errorf("concurrent=%v: reading the group did not fail", concurrent)
 		}
 	}
 }
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"go/constant"
+	"runtime"
+	"sync"
 
 	"github.com/go-delve/delve/pkg/dwarf/frame"
 	"github.com/go-delve/delve/pkg/dwarf/op"
@@ -109,6 +111,12 @@ func ThreadStacktrace(thread Thread, depth int) ([]Stackframe, error) {
 }
 
 func (g *G) stackIterator(opts StacktraceOptions) (*stackIterator, error) {
+	return g.stackIteratorWithMem(g.variable.mem, opts)
+}
+
+// stackIteratorWithMem is like stackIterator but reads the stack of g
+// using mem.
+func (g *G) stackIteratorWithMem(mem MemoryReadWriter, opts StacktraceOptions) (*stackIterator, error) {
 	bi := g.variable.bi
 	if g.Thread != nil {
 		regs, err := g.Thread.Registers()
@@ -119,13 +127,13 @@ func (g *G) stackIterator(opts StacktraceOptions) (*stackIterator, error) {
 		dwarfRegs := *(bi.Arch.RegistersToDwarfRegisters(so.StaticBase, regs))
 		dwarfRegs.ChangeFunc = g.Thread.SetReg
 		return newStackIterator(
-			bi, g.variable.mem,
+			bi, mem,
 			dwarfRegs,
 			g.stack.hi, g, opts), nil
 	}
 	so := g.variable.bi.PCToImage(g.PC)
 	return newStackIterator(
-		bi, g.variable.mem,
+		bi, mem,
 		bi.Arch.addrAndStackRegsToDwarfRegisters(so.StaticBase, g.PC, g.SP, g.BP, g.LR),
 		g.stack.hi, g, opts), nil
 }
@@ -163,6 +171,165 @@ func (g *G) Stacktrace(depth int, opts StacktraceOptions) ([]Stackframe, error)
 	return frames, nil
 }
 
+// stackCacheEntry is a stacktrace cached by CachedStacktrace.
+type stackCacheEntry struct {
+	goid   int
+	opts   StacktraceOptions
+	depth  int
+	frames []Stackframe
+}
+
+// CachedStacktrace returns the stack trace of g, up to depth frames, like
+// g.Stacktrace does. The frames are kept until the target is resumed so
+// that clients retrieving a deep stack a chunk at a time, with increasing
+// depths, do not unwind it from the top for every chunk: when a deeper
+// stack is requested the cached one is at least doubled in depth.
+func CachedStacktrace(t *Target, g *G, depth int, opts StacktraceOptions) ([]Stackframe, error) {
+	c := t.stackCache
+	if c == nil || c.goid != g.ID || c.opts != opts {
+		c = nil
+	}
+	// The cached stack is complete if it has fewer frames than requested.
+	if c == nil || (depth > c.depth && len(c.frames) > c.depth) {
+		udepth := depth
+		if c != nil && udepth < 2*c.depth {
+			udepth = 2 * c.depth
+		}
+		frames, err := g.Stacktrace(udepth, opts)
+		if err != nil {
+			return nil, err
+		}
+		c = &stackCacheEntry{goid: g.ID, opts: opts, depth: udepth, frames: frames}
+		t.stackCache = c
+	}
+	n := len(c.frames)
+	if depth+1 < n {
+		n = depth + 1
+	}
+	return c.frames[:n:n], nil
+}
+
+// GoroutineStacktrace is the stack trace of a goroutine, as returned by
+// GoroutinesStacktraces.
+type GoroutineStacktrace struct {
+	G      *G
+	Frames []Stackframe
+	Err    error
+}
+
+// goroutineStackReadLimit is the maximum amount of stack memory read at
+// once for each goroutine by GoroutinesStacktraces, frames beyond it are
+// read on demand.
+const goroutineStackReadLimit = 256 * 1024
+
+// goroutineFrameReadSize is the amount of stack memory read for each
+// requested frame by GoroutinesStacktraces, so that asking for the top
+// frames of each goroutine does not read their whole stack.
+const goroutineFrameReadSize = 1024
+
+// GoroutinesStacktraces returns the stack traces, up to depth frames, of all
+// the goroutines of the target.
+// The top of the live part of the stack of each goroutine, large enough
+// for depth frames of average size, is read from the target with a single
+// read and the stacks are unwound concurrently, which is
+// much faster than calling Stacktrace on each goroutine for programs with
+// many goroutines.
+func GoroutinesStacktraces(t *Target, depth int, opts StacktraceOptions) ([]GoroutineStacktrace, error) {
+	gs, _, err := GoroutinesInfo(t, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	r := make([]GoroutineStacktrace, len(gs))
+
+	// Goroutines running on a thread need to read the registers of the
+	// thread, which backends don't allow concurrently, unwind them here.
+	parked := make([]int, 0, len(gs))
+	for i, g := range gs {
+		r[i].G = g
+		if g.Thread != nil {
+			r[i].Frames, r[i].Err = g.Stacktrace(depth, opts&^StacktraceReadDefers)
+		} else {
+			parked = append(parked, i)
+		}
+	}
+
+	mem := &lockedMemory{mem: t.Memory()}
+	work := make(chan int)
+	var wg sync.WaitGroup
+	workers := runtime.NumCPU()
+	if workers > len(parked) {
+		workers = len(parked)
+	}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				r[i].Frames, r[i].Err = r[i].G.concurrentStacktrace(mem, depth, opts)
+			}
+		}()
+	}
+	for _, i := range parked {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+
+	// Reading deferred calls loads variables, which isn't safe to do
+	// concurrently.
+	if opts&StacktraceReadDefers != 0 {
+		for i := range r {
+			if r[i].Err == nil {
+				r[i].G.readDefers(r[i].Frames)
+			}
+		}
+	}
+	return r, nil
+}
+
+// concurrentStacktrace unwinds the stack of g, which must not be running
+// on a thread, reading memory through mem. The part of the stack starting
+// at the saved stack pointer of g that the first depth frames are expected
+// to use is read at once.
+func (g *G) concurrentStacktrace(lmem *lockedMemory, depth int, opts StacktraceOptions) ([]Stackframe, error) {
+	var mem MemoryReadWriter = lmem
+	if g.SP != 0 && g.SP < g.stack.hi {
+		sz := g.stack.hi - g.SP
+		if max := uint64(depth+1) * goroutineFrameReadSize; sz > max {
+			sz = max
+		}
+		if sz > goroutineStackReadLimit {
+			sz = goroutineStackReadLimit
+		}
+		mem = cacheMemory(mem, g.SP, int(sz))
+	}
+	it, err := g.stackIteratorWithMem(mem, opts&^StacktraceReadDefers)
+	if err != nil {
+		return nil, err
+	}
+	it.loadMu = &lmem.mu
+	return it.stacktrace(depth)
+}
+
+// lockedMemory serializes the accesses to a MemoryReadWriter that can not
+// be used concurrently.
+type lockedMemory struct {
+	mu  sync.Mutex
+	mem MemoryReadWriter
+}
+
+func (m *lockedMemory) ReadMemory(buf []byte, addr uint64) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.mem.ReadMemory(buf, addr)
+}
+
+func (m *lockedMemory) WriteMemory(addr uint64, data []byte) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.mem.WriteMemory(addr, data)
+}
+
 // NullAddrError is an error for a null address.
 type NullAddrError struct{}
 
@@ -192,6 +359,10 @@ type stackIterator struct {
 	g0_sched_sp        uint64 // value of g0.sched.sp (see comments around its use)
 	g0_sched_sp_loaded bool   // g0_sched_sp was loaded from g0
 
+	// loadMu, if not nil, must be held while loading variables, for stack
+	// iterators used concurrently.
+	loadMu sync.Locker
+
 	opts StacktraceOptions
 }
 
@@ -235,7 +406,7 @@ func (it *stackIterator) switchToGoroutineStack() {
 	it.pc = it.g.PC
 	it.regs.Reg(it.regs.SPRegNum).Uint64Val = it.g.SP
 	it.regs.AddReg(it.regs.BPRegNum, op.DwarfRegisterFromUint64(it.g.BP))
-	if it.bi.Arch.Name == "arm64" {
+	if it.bi.Arch.usesLR {
 		it.regs.Reg(it.regs.LRRegNum).Uint64Val = it.g.LR
 	}
 }
@@ -430,7 +601,7 @@ func (it *stackIterator) advanceRegs() (callFrameRegs op.DwarfRegisters, ret uin
 		}
 	}
 
-	if it.bi.Arch.Name == "arm64" {
+	if it.bi.Arch.usesLR {
 		if ret == 0 && it.regs.Reg(it.regs.LRRegNum) != nil {
 			ret = it.regs.Reg(it.regs.LRRegNum).Uint64Val
 		}
@@ -503,6 +674,10 @@ func (it *stackIterator) loadG0SchedSP() {
 		return
 	}
 	it.g0_sched_sp_loaded = true
+	if it.loadMu != nil {
+		it.loadMu.Lock()
+		defer it.loadMu.Unlock()
+	}
 	if it.g != nil {
 		mvar, _ := it.g.variable.structMember("m")
 		if mvar != nil {
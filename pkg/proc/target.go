@@ -1,12 +1,14 @@
 package proc
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"go/constant"
 	"os"
 	"sort"
 	"strings"
+	"syscall"
 
 	"github.com/go-delve/delve/pkg/dwarf/op"
 	"github.com/go-delve/delve/pkg/goversion"
@@ -23,6 +25,11 @@ var (
 
 	// ErrProcessDetached indicates that we detached from the target process.
 	ErrProcessDetached = errors.New("detached from the process")
+
+	// ErrOperationCanceled is returned by long running operations that were
+	// interrupted because the context set with SetOperationContext was
+	// canceled.
+	ErrOperationCanceled = errors.New("operation canceled")
 )
 
 type LaunchFlags uint8
@@ -32,6 +39,15 @@ const (
 	LaunchDisableASLR
 )
 
+// OutputRedirect specifies where the standard output or standard error of
+// a launched target is written: to the file at Path, if Path is not empty,
+// otherwise to File, if it is not nil, otherwise to the standard output or
+// standard error of the debugger.
+type OutputRedirect struct {
+	Path string
+	File *os.File
+}
+
 // Target represents the process being debugged.
 type Target struct {
 	Process
@@ -46,6 +62,11 @@ type Target struct {
 	// CanDump is true if core dumping is supported.
 	CanDump bool
 
+	// StopOnFault, if set, makes Continue stop on every fault signal, see
+	// StopSignal. Otherwise it only stops on the faults that the Go runtime
+	// can not convert into a panic, the others are delivered to the target.
+	StopOnFault bool
+
 	// currentThread is the thread that will be used by next/step/stepout and to evaluate variables if no goroutine is selected.
 	currentThread Thread
 
@@ -56,6 +77,16 @@ type Target struct {
 	// fncallForG stores a mapping of current active function calls.
 	fncallForG map[int]*callInjection
 
+	// nextCount is the state of the NextCount call in progress, if any.
+	nextCount *nextCountState
+
+	// frozen maps the ID of frozen goroutines to the breaklet keeping them
+	// parked, see FreezeGoroutine.
+	frozen map[int]*frozenGoroutine
+
+	// coverage is the state of the coverage collection, see StartCoverage.
+	coverage *coverageState
+
 	asyncPreemptChanged bool  // runtime/debug.asyncpreemptoff was changed
 	asyncPreemptOff     int64 // cached value of runtime/debug.asyncpreemptoff
 
@@ -65,28 +96,80 @@ type Target struct {
 	gcache goroutineCache
 	iscgo  *bool
 
-	// exitStatus is the exit status of the process we are debugging.
+	// stackCache is the stacktrace most recently returned by
+	// CachedStacktrace, it must be cleared whenever the target is resumed.
+	stackCache *stackCacheEntry
+
+	// exitErr describes how the process we are debugging exited.
 	// Saved here to relay to any future commands.
-	exitStatus int
+	exitErr ErrProcessExited
 
 	// fakeMemoryRegistry contains the list of all compositeMemory objects
 	// created since the last restart, it exists so that registerized variables
 	// can be given a unique address.
 	fakeMemoryRegistry    []*compositeMemory
 	fakeMemoryRegistryMap map[string]*compositeMemory
+
+	// opctx is checked periodically by long running operations, see
+	// SetOperationContext.
+	opctx context.Context
 }
 
 // ErrProcessExited indicates that the process has exited and contains both
 // process id and exit status.
 type ErrProcessExited struct {
-	Pid    int
+	Pid int
+	// Status is the exit code of the process or, if it was killed by a
+	// signal, the opposite of the signal number.
 	Status int
+	// Signal is the signal that killed the process, 0 if it exited
+	// normally.
+	Signal int
+	// CoreDumped is true if the process was killed by a signal and
+	// produced a core dump.
+	CoreDumped bool
 }
 
 func (pe ErrProcessExited) Error() string {
+	if pe.Signal != 0 {
+		coreDumped := ""
+		if pe.CoreDumped {
+			coreDumped = ", core dumped"
+		}
+		return fmt.Sprintf("Process %d has exited with status %d (killed by signal %d%s)", pe.Pid, pe.Status, pe.Signal, coreDumped)
+	}
 	return fmt.Sprintf("Process %d has exited with status %d", pe.Pid, pe.Status)
 }
 
+// WaitStatus is the status of a process returned by the wait system call,
+// implemented by syscall.WaitStatus and golang.org/x/sys/unix.WaitStatus.
+type WaitStatus interface {
+	Exited() bool
+	ExitStatus() int
+	Signaled() bool
+	Signal() syscall.Signal
+	CoreDump() bool
+}
+
+// NewErrProcessExited returns the error describing the exit of process
+// pid, with wait status ws.
+func NewErrProcessExited(pid int, ws WaitStatus) ErrProcessExited {
+	pe := ErrProcessExited{Pid: pid}
+	if ws == nil {
+		return pe
+	}
+	if ws.Signaled() {
+		pe.Signal = int(ws.Signal())
+		pe.Status = -pe.Signal
+		pe.CoreDumped = ws.CoreDump()
+		return pe
+	}
+	if ws.Exited() {
+		pe.Status = ws.ExitStatus()
+	}
+	return pe
+}
+
 // StopReason describes the reason why the target process is stopped.
 // A process could be stopped for multiple simultaneous reasons, in which
 // case only one will be reported.
@@ -115,6 +198,14 @@ func (sr StopReason) String() string {
 		return "call returned"
 	case StopWatchpoint:
 		return "watchpoint"
+	case StopPanic:
+		return "panic"
+	case StopFatalThrow:
+		return "fatal throw"
+	case StopTracepoint:
+		return "tracepoint"
+	case StopSignal:
+		return "signal"
 	default:
 		return ""
 	}
@@ -131,6 +222,10 @@ const (
 	StopNextFinished                   // The next/step/stepout command terminated
 	StopCallReturned                   // An injected call completed
 	StopWatchpoint                     // The target process hit one or more watchpoints
+	StopPanic                          // The target process hit the unrecovered panic breakpoint
+	StopFatalThrow                     // The target process hit the fatal throw breakpoint
+	StopTracepoint                     // The target process hit a tracepoint
+	StopSignal                         // The target process received a fault signal (SIGSEGV, SIGBUS), see SignalInfo and Target.StopOnFault
 )
 
 // NewTargetConfig contains the configuration for a new Target object,
@@ -177,6 +272,7 @@ func NewTarget(p Process, currentThread Thread, cfg NewTargetConfig) (*Target, e
 		Process:       p,
 		proc:          p.(ProcessInternal),
 		fncallForG:    make(map[int]*callInjection),
+		frozen:        make(map[int]*frozenGoroutine),
 		StopReason:    cfg.StopReason,
 		currentThread: currentThread,
 		CanDump:       cfg.CanDump,
@@ -223,7 +319,7 @@ func (t *Target) Valid() (bool, error) {
 	ok, err := t.proc.Valid()
 	if !ok && err != nil {
 		if pe, ok := err.(ErrProcessExited); ok {
-			pe.Status = t.exitStatus
+			pe.Status, pe.Signal, pe.CoreDumped = t.exitErr.Status, t.exitErr.Signal, t.exitErr.CoreDumped
 			err = pe
 		}
 	}
@@ -246,8 +342,10 @@ func (t *Target) SupportsFunctionCalls() bool {
 func (t *Target) ClearCaches() {
 	t.clearFakeMemory()
 	t.gcache.Clear()
+	t.stackCache = nil
 	for _, thread := range t.ThreadList() {
 		thread.Common().g = nil
+		thread.Common().activityKnown = false
 	}
 }
 
@@ -275,6 +373,33 @@ func (t *Target) SelectedGoroutine() *G {
 	return t.selectedGoroutine
 }
 
+// SetOperationContext sets the context that long running operations, such
+// as enumerating goroutines or loading the variables of a stacktrace, check
+// to determine whether they should stop early. Passing nil removes it.
+func (t *Target) SetOperationContext(ctx context.Context) {
+	t.opctx = ctx
+}
+
+// OperationCanceled returns ErrOperationCanceled if the context set with
+// SetOperationContext has been canceled, nil otherwise.
+func (t *Target) OperationCanceled() error {
+	if t.opctx == nil {
+		return nil
+	}
+	select {
+	case <-t.opctx.Done():
+		return ErrOperationCanceled
+	default:
+		return nil
+	}
+}
+
+// MemoryMap returns the memory mappings of the target process, or
+// ErrMemoryMapNotSupported if the backend can not list them.
+func (t *Target) MemoryMap() ([]MemoryMapEntry, error) {
+	return t.proc.MemoryMap()
+}
+
 // SwitchGoroutine will change the selected and active goroutine.
 func (p *Target) SwitchGoroutine(g *G) error {
 	if ok, err := p.Valid(); !ok {
@@ -12,6 +12,7 @@ import (
 
 	"github.com/go-delve/delve/pkg/astutil"
 	"github.com/go-delve/delve/pkg/dwarf/reader"
+	"github.com/go-delve/delve/pkg/proc/evalexpr"
 )
 
 const maxSkipAutogeneratedWrappers = 5 // maximum recursion depth for skipAutogeneratedWrappers
@@ -43,6 +44,177 @@ func (dbp *Target) Next() (err error) {
 	return dbp.Continue()
 }
 
+// NextCount calls Next count times, stopping early if the target stops
+// for any reason other than the completion of a next (for example because
+// a breakpoint was hit, the target exited or a manual stop was requested).
+// The breakpoints set by Next are kept for the following iterations as
+// long as they stop in the same frame, see nextCountState.
+func (dbp *Target) NextCount(count int) error {
+	if count < 1 {
+		count = 1
+	}
+	nc := &nextCountState{remaining: count}
+	dbp.nextCount = nc
+	defer func() {
+		dbp.nextCount = nil
+	}()
+	for first := true; nc.remaining > 0; first = false {
+		if !first {
+			if err := dbp.OperationCanceled(); err != nil {
+				return nil
+			}
+		}
+		if err := nc.init(dbp); err != nil {
+			return err
+		}
+		if err := dbp.Next(); err != nil {
+			return err
+		}
+		nc.remaining--
+		if dbp.StopReason != StopNextFinished || dbp.Breakpoints().HasSteppingBreakpoints() {
+			// interrupted by a breakpoint or a manual stop, the remaining
+			// iterations are discarded.
+			return nil
+		}
+	}
+	return nil
+}
+
+// StepCount calls Step count times, see NextCount.
+// Breakpoints are not reused between iterations: the breakpoints set by
+// Step on the destinations of the calls of the current line are only
+// valid for that line.
+func (dbp *Target) StepCount(count int) error {
+	return dbp.repeatStepping(count, dbp.Step)
+}
+
+// StepOutCount calls StepOut count times, see NextCount.
+// Breakpoints are not reused between iterations: they are set on the
+// return address of the current frame, which is gone once it is reached.
+func (dbp *Target) StepOutCount(count int) error {
+	return dbp.repeatStepping(count, dbp.StepOut)
+}
+
+// nextCountState is the state of a NextCount call.
+// Next sets a breakpoint on every line of the current function except the
+// current one, conditioned on the current frame. When one of them is hit
+// in the same frame the next iteration would set the same breakpoints,
+// plus the ones on the line it started from: instead of clearing them and
+// stopping, Continue adds the missing ones and resumes the target,
+// ignoring the breakpoints on the line the iteration started from.
+type nextCountState struct {
+	remaining int // iterations left, including the current one
+
+	fn       *Function // function of the frame the breakpoints are valid for, nil if they can not be reused
+	frameoff int64
+	cond     ast.Expr // condition of the breakpoints set by next on the lines of fn
+
+	file string // line the current iteration started from
+	line int
+
+	startPCs []uint64 // statements of the line the first iteration started from, no breakpoint was set on them
+	allLines bool     // breakpoints were also set on startPCs
+}
+
+// init records the frame the next iteration starts from.
+func (nc *nextCountState) init(dbp *Target) error {
+	nc.fn, nc.startPCs, nc.allLines = nil, nil, false
+	selg := dbp.SelectedGoroutine()
+	if selg == nil || dbp.GetDirection() == Backward {
+		return nil
+	}
+	topframe, _, err := topframe(selg, dbp.CurrentThread())
+	if err != nil {
+		return err
+	}
+	fn := topframe.Current.Fn
+	if fn == nil || topframe.Inlined {
+		return nil
+	}
+	pcs, err := fn.cu.lineInfo.AllPCsBetween(fn.Entry, fn.End-1, "", -1)
+	if err != nil {
+		return err
+	}
+	pcs, err = removeInlinedCalls(pcs, topframe)
+	if err != nil {
+		return err
+	}
+	for _, pc := range pcs {
+		if file, line, _ := dbp.BinInfo().PCToLine(pc); file == topframe.Current.File && line == topframe.Current.Line {
+			nc.startPCs = append(nc.startPCs, pc)
+		}
+	}
+	nc.fn = fn
+	nc.frameoff = topframe.FrameOffset()
+	nc.cond = astutil.And(sameGoroutineCondition(selg), frameoffCondition(&topframe))
+	nc.file, nc.line = topframe.Current.File, topframe.Current.Line
+	return nil
+}
+
+// reuse is called by Continue when a next finished on curthread, it
+// returns true if the stepping breakpoints should be kept and the target
+// resumed for the next iteration.
+func (nc *nextCountState) reuse(dbp *Target, curthread Thread) (bool, error) {
+	if nc.fn == nil || nc.remaining <= 1 || dbp.OperationCanceled() != nil {
+		return false, nil
+	}
+	topframe, _, err := topframe(dbp.SelectedGoroutine(), curthread)
+	if err != nil || topframe.Current.Fn != nc.fn || topframe.Inlined || topframe.FrameOffset() != nc.frameoff {
+		// returned to the caller or through a deferred call
+		return false, nil
+	}
+	if topframe.Current.File == nc.file && topframe.Current.Line == nc.line {
+		// still on the line this iteration started from
+		return true, nil
+	}
+	if !nc.allLines {
+		for _, pc := range nc.startPCs {
+			if _, err := dbp.SetBreakpoint(pc, NextBreakpoint, nc.cond); err != nil {
+				return false, err
+			}
+		}
+		nc.allLines = true
+	}
+	nc.remaining--
+	nc.file, nc.line = topframe.Current.File, topframe.Current.Line
+	return true, nil
+}
+
+// repeatStepping executes step count times without returning control to
+// the client between iterations. A count smaller than 1 executes step once.
+func (dbp *Target) repeatStepping(count int, step func() error) error {
+	if count < 1 {
+		count = 1
+	}
+	for i := 0; i < count; i++ {
+		if i > 0 {
+			if err := dbp.OperationCanceled(); err != nil {
+				return nil
+			}
+		}
+		if err := step(); err != nil {
+			return err
+		}
+		if dbp.StopReason != StopNextFinished || dbp.Breakpoints().HasSteppingBreakpoints() {
+			// interrupted by a breakpoint or a manual stop, the remaining
+			// iterations are discarded.
+			return nil
+		}
+	}
+	return nil
+}
+
+// faultInGoCode returns true if thread received a fault signal while
+// executing Go code, the Go runtime converts those faults into panics.
+// Faults in C code, for example in cgo calls, crash the target.
+func faultInGoCode(thread Thread) bool {
+	loc, err := thread.Location()
+	if err != nil || loc.Fn == nil {
+		return false
+	}
+	return loc.Fn.cu.isgo
+}
+
 // Continue continues execution of the debugged
 // process. It will continue until it hits a breakpoint
 // or is otherwise stopped.
@@ -85,7 +257,7 @@ func (dbp *Target) Continue() error {
 				}
 			}
 			if pe, ok := err.(ErrProcessExited); ok {
-				dbp.exitStatus = pe.Status
+				dbp.exitErr = pe
 			}
 			return err
 		}
@@ -95,6 +267,10 @@ func (dbp *Target) Continue() error {
 
 		threads := dbp.ThreadList()
 
+		if err := dbp.recordCoverage(threads); err != nil {
+			return err
+		}
+
 		callInjectionDone, callErr := callInjectionProtocol(dbp, threads)
 		// callErr check delayed until after pickCurrentThread, which must always
 		// happen, otherwise the debugger could be left in an inconsistent
@@ -111,6 +287,21 @@ func (dbp *Target) Continue() error {
 		curthread := dbp.CurrentThread()
 		curbp := curthread.Breakpoint()
 
+		if curbp.Breakpoint == nil && curthread.Common().Signal != nil {
+			// Faults caused by an injected function call are handled by the
+			// call injection protocol, the signal will be delivered when the
+			// target is resumed.
+			if g, _ := GetG(curthread); g == nil || dbp.fncallForG[g.ID] == nil {
+				if !dbp.StopOnFault && faultInGoCode(curthread) {
+					// The runtime could convert the fault into a panic and recover
+					// from it, the signal is delivered when the target is resumed.
+					continue
+				}
+				dbp.StopReason = StopSignal
+				return conditionErrors(threads)
+			}
+		}
+
 		switch {
 		case curbp.Breakpoint == nil:
 			// runtime.Breakpoint, manual stop or debugCallV1-related stop
@@ -182,6 +373,15 @@ func (dbp *Target) Continue() error {
 					return dbp.StepInstruction()
 				}
 			} else {
+				if dbp.nextCount != nil {
+					again, err := dbp.nextCount.reuse(dbp, curthread)
+					if err != nil {
+						return err
+					}
+					if again {
+						continue
+					}
+				}
 				curthread.Common().returnValues = curbp.Breakpoint.returnInfo.Collect(dbp, curthread)
 				if err := dbp.ClearSteppingBreakpoints(); err != nil {
 					return err
@@ -203,9 +403,17 @@ func (dbp *Target) Continue() error {
 			if curbp.Name == UnrecoveredPanic {
 				dbp.ClearSteppingBreakpoints()
 			}
-			dbp.StopReason = StopBreakpoint
-			if curbp.Breakpoint.WatchType != 0 {
+			switch {
+			case curbp.Breakpoint.WatchType != 0:
 				dbp.StopReason = StopWatchpoint
+			case curbp.Name == UnrecoveredPanic:
+				dbp.StopReason = StopPanic
+			case curbp.Name == FatalThrow:
+				dbp.StopReason = StopFatalThrow
+			case curbp.Tracepoint:
+				dbp.StopReason = StopTracepoint
+			default:
+				dbp.StopReason = StopBreakpoint
 			}
 			return conditionErrors(threads)
 		default:
@@ -1050,7 +1258,7 @@ type onNextGoroutineWalker struct {
 }
 
 func (w *onNextGoroutineWalker) Visit(n ast.Node) ast.Visitor {
-	if binx, isbin := n.(*ast.BinaryExpr); isbin && binx.Op == token.EQL && exprToString(binx.X) == "runtime.curg.goid" {
+	if binx, isbin := n.(*ast.BinaryExpr); isbin && binx.Op == token.EQL && evalexpr.ExprToString(binx.X) == "runtime.curg.goid" {
 		w.ret, w.err = evalBreakpointCondition(w.thread, n.(ast.Expr))
 		return nil
 	}
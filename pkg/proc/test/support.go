@@ -2,18 +2,27 @@ package test
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"flag"
 	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
 
+	"github.com/go-delve/delve/pkg/dlvtest"
 	"github.com/go-delve/delve/pkg/goversion"
 )
 
@@ -23,16 +32,7 @@ var EnableRace = flag.Bool("racetarget", false, "Enables race detector on inferi
 var runningWithFixtures bool
 
 // Fixture is a test binary.
-type Fixture struct {
-	// Name is the short name of the fixture.
-	Name string
-	// Path is the absolute path to the test binary.
-	Path string
-	// Source is the absolute path of the test binary source.
-	Source string
-	// BuildDir is the directory where the build command was run.
-	BuildDir string
-}
+type Fixture = dlvtest.Fixture
 
 // FixtureKey holds the name and builds flags used for a test fixture.
 type fixtureKey struct {
@@ -40,12 +40,36 @@ type fixtureKey struct {
 	Flags BuildFlags
 }
 
+// fixtureBuild is a fixture that is being built or was built, concurrent
+// requests for the same fixture wait for the same build.
+type fixtureBuild struct {
+	once    sync.Once
+	fixture Fixture
+	cached  bool
+	err     error
+}
+
 // Fixtures is a map of fixtureKey{ Fixture.Name, buildFlags } to Fixture.
-var fixtures = make(map[fixtureKey]Fixture)
+var (
+	fixtures   = make(map[fixtureKey]*fixtureBuild)
+	fixturesMu sync.Mutex
+)
 
 // PathsToRemove is a list of files and directories to remove after running all the tests
 var PathsToRemove []string
 
+const (
+	// fixturesCacheEnv is the environment variable holding the directory
+	// where built fixtures are cached between runs, keyed by the hash of
+	// their source, the build flags and the version of Go. If it is not set
+	// fixtures are rebuilt by every test run.
+	fixturesCacheEnv = "DELVE_FIXTURES_CACHE"
+	// testShardEnv is the environment variable selecting the shard of the
+	// tests to run, in the form k/n, where 1 <= k <= n. The tests of the
+	// package are split in n shards and only the k-th one is run.
+	testShardEnv = "DELVE_TEST_SHARD"
+)
+
 // FindFixturesDir will search for the directory holding all test fixtures
 // beginning with the current directory and searching up 10 directories.
 func FindFixturesDir() string {
@@ -81,34 +105,50 @@ const (
 )
 
 // BuildFixture will compile the fixture 'name' using the provided build flags.
+// It is safe to call BuildFixture concurrently.
 func BuildFixture(name string, flags BuildFlags) Fixture {
 	if !runningWithFixtures {
 		panic("RunTestsWithFixtures not called")
 	}
-	fk := fixtureKey{name, flags}
-	if f, ok := fixtures[fk]; ok {
-		return f
+	b := startFixtureBuild(name, flags)
+	if b.err != nil {
+		fmt.Println(b.err)
+		os.Exit(1)
 	}
+	return b.fixture
+}
+
+func startFixtureBuild(name string, flags BuildFlags) *fixtureBuild {
+	fk := fixtureKey{name, flags}
+	fixturesMu.Lock()
+	b := fixtures[fk]
+	if b == nil {
+		b = &fixtureBuild{}
+		fixtures[fk] = b
+	}
+	fixturesMu.Unlock()
+	b.once.Do(func() {
+		b.fixture, b.cached, b.err = buildFixture(name, flags)
+	})
+	return b
+}
 
+func buildFixture(name string, flags BuildFlags) (fixture Fixture, cached bool, err error) {
+	var env []string
 	if flags&EnableCGOOptimization == 0 {
-		os.Setenv("CGO_CFLAGS", "-O0 -g")
+		env = append(os.Environ(), "CGO_CFLAGS=-O0 -g")
 	}
 
 	fixturesDir := FindFixturesDir()
 
-	// Make a (good enough) random temporary file name
-	r := make([]byte, 4)
-	rand.Read(r)
 	dir := fixturesDir
-	path := filepath.Join(fixturesDir, name+".go")
+	source := name + ".go"
 	if name[len(name)-1] == '/' {
 		dir = filepath.Join(dir, name)
-		path = ""
-		name = name[:len(name)-1]
+		source = ""
 	}
-	tmpfile := filepath.Join(os.TempDir(), fmt.Sprintf("%s.%s", name, hex.EncodeToString(r)))
 
-	buildFlags := []string{"build"}
+	buildFlags := []string{}
 	var ver goversion.GoVersion
 	if ver, _ = goversion.Parse(runtime.Version()); runtime.GOOS == "windows" && ver.Major > 0 && !ver.AfterOrEqual(goversion.GoVersion{Major: 1, Minor: 9, Rev: -1}) {
 		// Work-around for https://github.com/golang/go/issues/13154
@@ -124,13 +164,6 @@ func BuildFixture(name string, flags BuildFlags) Fixture {
 	if flags&EnableOptimization == 0 {
 		gcflagsv = append(gcflagsv, "-N")
 	}
-	var gcflags string
-	if flags&AllNonOptimized != 0 {
-		gcflags = "-gcflags=all=" + strings.Join(gcflagsv, " ")
-	} else {
-		gcflags = "-gcflags=" + strings.Join(gcflagsv, " ")
-	}
-	buildFlags = append(buildFlags, gcflags, "-o", tmpfile)
 	if *EnableRace {
 		buildFlags = append(buildFlags, "-race")
 	}
@@ -150,58 +183,204 @@ func BuildFixture(name string, flags BuildFlags) Fixture {
 			buildFlags = append(buildFlags, "-ldflags=-compressdwarf=false")
 		}
 	}
-	if path != "" {
-		buildFlags = append(buildFlags, name+".go")
+
+	opts := dlvtest.BuildOptions{
+		Dir:         dir,
+		Source:      source,
+		Optimized:   true,
+		GCFlags:     gcflagsv,
+		AllPackages: flags&AllNonOptimized != 0,
+		Flags:       buildFlags,
+		Env:         env,
 	}
 
-	cmd := exec.Command("go", buildFlags...)
-	cmd.Dir = dir
+	var cachePath string
+	if cacheDir := os.Getenv(fixturesCacheEnv); cacheDir != "" {
+		key, err := fixtureCacheKey(opts, flags)
+		if err == nil {
+			cachePath = filepath.Join(cacheDir, strings.TrimSuffix(name, "/")+"."+key)
+			if _, err := os.Stat(cachePath); err == nil {
+				return fixtureAt(opts, cachePath), true, nil
+			}
+			if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err != nil {
+				cachePath = ""
+			}
+		}
+	}
+	if cachePath != "" {
+		// Build to a temporary file in the cache directory and rename it
+		// once it's complete, so that concurrent test runs never see a
+		// partially written fixture.
+		r := make([]byte, 4)
+		rand.Read(r)
+		opts.Output = cachePath + ".tmp" + hex.EncodeToString(r)
+	}
 
 	// Build the test binary
-	if out, err := cmd.CombinedOutput(); err != nil {
-		fmt.Printf("Error compiling %s: %s\n", path, err)
-		fmt.Printf("%s\n", string(out))
-		os.Exit(1)
+	fixture, err = dlvtest.Build(opts)
+	if err != nil {
+		return Fixture{}, false, err
 	}
+	tmpfile := fixture.Path
 
 	if flags&EnableDWZCompression != 0 {
 		cmd := exec.Command("dwz", tmpfile)
 		if out, err := cmd.CombinedOutput(); err != nil {
 			if regexp.MustCompile(`dwz: Section offsets in (.*?) not monotonically increasing`).FindString(string(out)) == "" {
-				fmt.Printf("Error running dwz on %s: %s\n", tmpfile, err)
-				fmt.Printf("%s\n", string(out))
-				os.Exit(1)
+				os.Remove(tmpfile)
+				return Fixture{}, false, fmt.Errorf("Error running dwz on %s: %s\n%s", tmpfile, err, string(out))
 			}
 		}
 	}
 
-	source, _ := filepath.Abs(path)
+	if cachePath != "" {
+		if err := os.Rename(tmpfile, cachePath); err == nil {
+			fixture.Path = cachePath
+			cached = true
+		}
+	}
+
+	return fixture, cached, nil
+}
+
+// fixtureAt returns the fixture described by opts, already built at path.
+func fixtureAt(opts dlvtest.BuildOptions, path string) Fixture {
+	name := strings.TrimSuffix(filepath.Base(opts.Source), ".go")
+	absdir, _ := filepath.Abs(opts.Dir)
+	if opts.Source == "" {
+		name = filepath.Base(absdir)
+	}
+	source, _ := filepath.Abs(filepath.Join(opts.Dir, opts.Source))
 	source = filepath.ToSlash(source)
-	sympath, err := filepath.EvalSymlinks(source)
-	if err == nil {
+	if sympath, err := filepath.EvalSymlinks(source); err == nil {
 		source = strings.Replace(sympath, "\\", "/", -1)
 	}
+	return Fixture{Name: name, Path: path, Source: source, BuildDir: absdir}
+}
+
+var goVersionOutput struct {
+	once sync.Once
+	out  []byte
+	err  error
+}
+
+// fixtureCacheKey returns the key of the fixture built with opts in the
+// fixtures cache: a hash of everything that can change the executable,
+// the sources, including the packages of _fixtures/internal they import,
+// the build flags, the environment and the version of Go.
+func fixtureCacheKey(opts dlvtest.BuildOptions, flags BuildFlags) (string, error) {
+	goVersionOutput.once.Do(func() {
+		goVersionOutput.out, goVersionOutput.err = exec.Command("go", "version").CombinedOutput()
+	})
+	if goVersionOutput.err != nil {
+		return "", goVersionOutput.err
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%d\n%q\n%q\n%v\n", goVersionOutput.out, flags, opts.GCFlags, opts.Flags, opts.AllPackages)
+	for _, v := range []string{"GOOS", "GOARCH", "GOFLAGS", "CGO_ENABLED", "CGO_CFLAGS"} {
+		fmt.Fprintf(h, "%s=%s\n", v, os.Getenv(v))
+	}
+	fmt.Fprintf(h, "%q\n", opts.Env)
 
-	absdir, _ := filepath.Abs(dir)
+	var imports []string
+	hashFile := func(path string) error {
+		buf, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "%s %d\n", filepath.ToSlash(path), len(buf))
+		h.Write(buf)
+		if strings.HasSuffix(path, ".go") {
+			if f, err := parser.ParseFile(token.NewFileSet(), path, buf, parser.ImportsOnly); err == nil {
+				for _, imp := range f.Imports {
+					if path, err := strconv.Unquote(imp.Path.Value); err == nil && strings.HasPrefix(path, fixturesImportPath) {
+						imports = append(imports, path)
+					}
+				}
+			}
+		}
+		return nil
+	}
 
-	fixture := Fixture{Name: name, Path: tmpfile, Source: source, BuildDir: absdir}
+	if opts.Source != "" {
+		if err := hashFile(filepath.Join(opts.Dir, opts.Source)); err != nil {
+			return "", err
+		}
+	} else {
+		err := filepath.Walk(opts.Dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return err
+			}
+			return hashFile(path)
+		})
+		if err != nil {
+			return "", err
+		}
+	}
 
-	fixtures[fk] = fixture
-	return fixtures[fk]
+	hashed := map[string]bool{}
+	for len(imports) > 0 {
+		path := imports[0]
+		imports = imports[1:]
+		if hashed[path] {
+			continue
+		}
+		hashed[path] = true
+		dir := filepath.Join(FindFixturesDir(), filepath.FromSlash(strings.TrimPrefix(path, fixturesImportPath)))
+		files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+		if err != nil {
+			return "", err
+		}
+		for _, file := range files {
+			if err := hashFile(file); err != nil {
+				return "", err
+			}
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16], nil
 }
 
+// fixturesImportPath is the prefix of the import paths of the packages
+// of the fixtures directory.
+const fixturesImportPath = "github.com/go-delve/delve/_fixtures/"
+
 // RunTestsWithFixtures will pre-compile test fixtures before running test
-// methods. Test binaries are deleted before exiting.
+// methods. Test binaries are deleted before exiting, unless they are kept
+// in the fixtures cache.
+//
+// If the tests are sharded, see testShardEnv, only the tests belonging to
+// the selected shard are run.
 func RunTestsWithFixtures(m *testing.M) int {
 	runningWithFixtures = true
 	defer func() {
 		runningWithFixtures = false
 	}()
+
+	if !flag.Parsed() {
+		flag.Parse()
+	}
+	runFlag := flag.Lookup("test.run")
+	listFlag := flag.Lookup("test.list")
+	if listFlag == nil || listFlag.Value.String() == "" {
+		if shard := os.Getenv(testShardEnv); shard != "" {
+			if err := selectShard(shard, runFlag); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", testShardEnv, err)
+				return 1
+			}
+		} else if runFlag != nil && runFlag.Value.String() == "" {
+			// Running the whole test suite, most fixtures will be used.
+			prebuildFixtures()
+		}
+	}
+
 	status := m.Run()
 
 	// Remove the fixtures.
-	for _, f := range fixtures {
-		os.Remove(f.Path)
+	for _, b := range fixtures {
+		if b.err == nil && !b.cached {
+			os.Remove(b.fixture.Path)
+		}
 	}
 
 	for _, p := range PathsToRemove {
@@ -218,6 +397,120 @@ func RunTestsWithFixtures(m *testing.M) int {
 	return status
 }
 
+// prebuildFixtures concurrently builds, with the default build flags, the
+// single file fixtures used by the tests of the package being tested.
+// Errors are ignored here, they will be reported by the test trying to use
+// the fixture.
+func prebuildFixtures() {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, runtime.NumCPU())
+	for _, name := range usedFixtures() {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			startFixtureBuild(name, 0)
+		}(name)
+	}
+	wg.Wait()
+}
+
+// usedFixtures returns the names of the single file fixtures that appear
+// as string literals in the test files of the current directory, the
+// directory of the package being tested.
+func usedFixtures() []string {
+	matches, _ := filepath.Glob(filepath.Join(FindFixturesDir(), "*.go"))
+	names := map[string]bool{}
+	for _, match := range matches {
+		names[strings.TrimSuffix(filepath.Base(match), ".go")] = true
+	}
+	testFiles, _ := filepath.Glob("*_test.go")
+	used := map[string]bool{}
+	for _, testFile := range testFiles {
+		f, err := parser.ParseFile(token.NewFileSet(), testFile, nil, 0)
+		if err != nil {
+			continue
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			if lit, ok := n.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+				if name, err := strconv.Unquote(lit.Value); err == nil && names[name] {
+					used[name] = true
+				}
+			}
+			return true
+		})
+	}
+	r := make([]string, 0, len(used))
+	for name := range used {
+		r = append(r, name)
+	}
+	sort.Strings(r)
+	return r
+}
+
+// selectShard restricts the tests run by the test binary to the ones in
+// the shard described by shard, which has the form k/n. The tests matching
+// the -test.run flag are listed by running the test binary itself with
+// -test.list.
+func selectShard(shard string, runFlag *flag.Flag) error {
+	k, n, err := parseShard(shard)
+	if err != nil {
+		return err
+	}
+	run := "."
+	if runFlag != nil && runFlag.Value.String() != "" {
+		run = runFlag.Value.String()
+	}
+	cmd := exec.Command(os.Args[0], "-test.list="+run)
+	cmd.Env = append(os.Environ(), testShardEnv+"=")
+	out, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("could not list tests: %v", err)
+	}
+	tests := shardTests(strings.Split(strings.TrimSpace(string(out)), "\n"), k, n)
+	if len(tests) == 0 {
+		// Nothing to run, use a pattern that can not match any test.
+		tests = []string{"$^"}
+	}
+	if runFlag == nil {
+		return errors.New("-test.run flag not found")
+	}
+	return runFlag.Value.Set("^(" + strings.Join(tests, "|") + ")$")
+}
+
+// parseShard parses a shard in the form k/n.
+func parseShard(shard string) (k, n int, err error) {
+	v := strings.Split(shard, "/")
+	if len(v) == 2 {
+		k, err = strconv.Atoi(v[0])
+		if err == nil {
+			n, err = strconv.Atoi(v[1])
+		}
+	}
+	if len(v) != 2 || err != nil || n < 1 || k < 1 || k > n {
+		return 0, 0, fmt.Errorf("malformed shard %q, expected k/n with 1 <= k <= n", shard)
+	}
+	return k, n, nil
+}
+
+// shardTests returns the tests belonging to the k-th of n shards, tests
+// are assigned to shards by their position in the list.
+func shardTests(tests []string, k, n int) []string {
+	r := []string{}
+	i := 0
+	for _, test := range tests {
+		if test == "" {
+			continue
+		}
+		if i%n == k-1 {
+			r = append(r, regexp.QuoteMeta(test))
+		}
+		i++
+	}
+	return r
+}
+
 var recordingAllowed = map[string]bool{}
 var recordingAllowedMu sync.Mutex
 
@@ -0,0 +1,40 @@
+package test
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseShard(t *testing.T) {
+	for _, tc := range []struct {
+		shard string
+		k, n  int
+		ok    bool
+	}{
+		{"1/1", 1, 1, true},
+		{"2/3", 2, 3, true},
+		{"0/3", 0, 0, false},
+		{"4/3", 0, 0, false},
+		{"3", 0, 0, false},
+		{"a/b", 0, 0, false},
+	} {
+		k, n, err := parseShard(tc.shard)
+		if k != tc.k || n != tc.n || (err == nil) != tc.ok {
+			t.Errorf("parseShard(%q): got %d %d %v", tc.shard, k, n, err)
+		}
+	}
+}
+
+func TestShardTests(t *testing.T) {
+	tests := []string{"TestA", "TestB", "", "TestC", "TestD", "TestE"}
+	var all []string
+	for k := 1; k <= 2; k++ {
+		all = append(all, shardTests(tests, k, 2)...)
+	}
+	if len(all) != 5 {
+		t.Errorf("shards do not cover all tests: %v", all)
+	}
+	if got := shardTests(tests, 2, 2); !reflect.DeepEqual(got, []string{"TestB", "TestD"}) {
+		t.Errorf("shard 2/2: got %v", got)
+	}
+}
@@ -2,6 +2,9 @@ package proc
 
 import (
 	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
 
 	"github.com/go-delve/delve/pkg/dwarf/op"
 )
@@ -55,6 +58,60 @@ type CommonThread struct {
 	CallReturn   bool // returnValues are the return values of a call injection
 	returnValues []*Variable
 	g            *G // cached g for this thread
+
+	// activity is the cached classification of the thread, valid if
+	// activityKnown is set.
+	activity      Activity
+	activityKnown bool
+
+	// Signal is the fault signal received by the thread that has not been
+	// delivered to it yet, the backend delivers it when the thread is
+	// resumed and resets Signal.
+	Signal *SignalInfo
+}
+
+// SignalInfo describes a fault signal (SIGSEGV, SIGBUS) received by a
+// thread, the signal is held back by the debugger so that the state of the
+// thread at the faulting instruction can be inspected before the signal
+// handler of the runtime converts the fault into a panic or a crash.
+type SignalInfo struct {
+	Signo int    // signal number
+	Code  int    // si_code, the reason the signal was sent
+	Addr  uint64 // si_addr, the faulting memory address
+}
+
+// FindThread returns the thread with ID threadID, or the current thread of
+// the target if threadID is 0.
+func FindThread(t *Target, threadID int) (Thread, error) {
+	if threadID == 0 {
+		return t.CurrentThread(), nil
+	}
+	thread, ok := t.FindThread(threadID)
+	if !ok {
+		return nil, fmt.Errorf("couldn't find thread %d", threadID)
+	}
+	return thread, nil
+}
+
+// InSyscall returns true if thread is blocked in a system call, either
+// because the goroutine running on it entered one or, for threads that are
+// not running a goroutine or are on the system stack, because it is
+// stopped inside one of the system call wrappers of the runtime.
+func InSyscall(thread Thread) bool {
+	g, _ := GetG(thread)
+	if g != nil && g.Status == Gsyscall {
+		return true
+	}
+	if g != nil && !g.SystemStack {
+		return false
+	}
+	loc, err := thread.Location()
+	if err != nil || loc.Fn == nil || !strings.HasPrefix(loc.Fn.Name, "runtime.") {
+		return false
+	}
+	// The runtime implements its system calls in sys_$GOOS_$GOARCH.s
+	base := filepath.Base(loc.File)
+	return strings.HasPrefix(base, "sys_") && strings.HasSuffix(base, ".s")
 }
 
 // ReturnValues reads the return values from the function executing on
@@ -0,0 +1,172 @@
+package proc
+
+import (
+	"errors"
+	"fmt"
+	"go/constant"
+	"reflect"
+	"sort"
+)
+
+// Timer is a timer of the runtime, created for example by time.NewTimer,
+// time.AfterFunc, time.Sleep or time.NewTicker.
+type Timer struct {
+	Addr uint64
+	// When is the time at which the timer fires, on the monotonic clock of
+	// the runtime (runtime.nanotime).
+	When int64
+	// Period is the period of tickers, or zero.
+	Period int64
+	// Func is the function called by the runtime when the timer fires.
+	Func *Variable
+	// Arg is the argument passed to Func. For timers created by the time
+	// package this is the channel receiving the time for time.Timer and
+	// time.Ticker and the function to call for time.AfterFunc.
+	Arg *Variable
+}
+
+// Status of the timers in Go 1.14 to Go 1.22, see $GOROOT/src/runtime/time.go.
+const (
+	timerDeleted         = 3
+	timerRemoving        = 4
+	timerRemoved         = 5
+	timerModifiedEarlier = 7
+	timerModifiedLater   = 8
+)
+
+// timerZombie is set in the state of deleted timers since Go 1.23.
+const timerZombie = 4
+
+var timersLoadConfig = LoadConfig{FollowPointers: true, MaxVariableRecurse: 3, MaxStringLen: 64, MaxArrayValues: 64, MaxStructFields: -1}
+
+// maxTimers is the maximum number of timers read from each timer heap.
+const maxTimers = 100000
+
+// Timers returns the pending timers of the target, sorted by the time at
+// which they fire.
+func Timers(t *Target) ([]*Timer, error) {
+	if _, err := t.Valid(); err != nil {
+		return nil, err
+	}
+	bi := t.BinInfo()
+	scope := globalScope(bi, bi.Images[0], t.Memory())
+
+	// Before Go 1.14 timers are kept in a fixed number of buckets, later
+	// each P has its own heap of timers.
+	var heapExprs []string
+	if timers, err := scope.findGlobal("runtime", "timers"); err == nil && timers.Kind == reflect.Array {
+		for i := int64(0); i < timers.Len; i++ {
+			heapExprs = append(heapExprs, fmt.Sprintf("runtime.timers[%d].t", i))
+		}
+	} else {
+		nv, err := scope.EvalExpression("len(runtime.allp)", loadSingleValue)
+		if err != nil {
+			return nil, err
+		}
+		n, _ := constant.Int64Val(nv.Value)
+		for i := int64(0); i < n; i++ {
+			heapExprs = append(heapExprs, fmt.Sprintf("runtime.allp[%d].timers", i))
+		}
+	}
+
+	var r []*Timer
+	for _, expr := range heapExprs {
+		timers, err := readTimerHeap(scope, expr)
+		if err != nil {
+			return nil, err
+		}
+		r = append(r, timers...)
+	}
+	sort.SliceStable(r, func(i, j int) bool { return r[i].When < r[j].When })
+	return r, nil
+}
+
+// readTimerHeap reads the timers in the heap expr evaluates to.
+func readTimerHeap(scope *EvalScope, expr string) ([]*Timer, error) {
+	heap, err := scope.EvalExpression(expr, LoadConfig{})
+	if err != nil {
+		return nil, err
+	}
+	if heap.Kind == reflect.Struct {
+		// Since Go 1.23 the heap is a slice of timerWhen in a timers struct.
+		if heap, err = heap.structMember("heap"); err != nil {
+			return nil, err
+		}
+	}
+	if heap.Kind != reflect.Slice {
+		return nil, fmt.Errorf("unknown type of %s", expr)
+	}
+	if heap.Len > maxTimers {
+		return nil, fmt.Errorf("too many timers in %s", expr)
+	}
+	cfg := timersLoadConfig
+	cfg.MaxArrayValues = int(heap.Len)
+	heap.loadValue(cfg)
+	if heap.Unreadable != nil {
+		return nil, heap.Unreadable
+	}
+
+	r := make([]*Timer, 0, len(heap.Children))
+	for i := range heap.Children {
+		tv := &heap.Children[i]
+		if tv.Kind == reflect.Struct {
+			if tv = tv.fieldVariable("timer"); tv == nil {
+				return nil, fmt.Errorf("unknown type of %s", expr)
+			}
+		}
+		if tv.Kind != reflect.Ptr || len(tv.Children) == 0 {
+			return nil, fmt.Errorf("unknown type of %s", expr)
+		}
+		timer, err := convertTimer(&tv.Children[0])
+		if err != nil {
+			return nil, err
+		}
+		if timer != nil {
+			r = append(r, timer)
+		}
+	}
+	return r, nil
+}
+
+// convertTimer converts a runtime.timer into a Timer, it returns nil for
+// deleted timers.
+func convertTimer(tv *Variable) (*Timer, error) {
+	if tv.Unreadable != nil {
+		return nil, tv.Unreadable
+	}
+	intField := func(name string) (int64, bool) {
+		f := tv.fieldVariable(name)
+		if f != nil && f.Kind == reflect.Struct {
+			// sync/atomic types
+			f = f.fieldVariable("v")
+		}
+		if f == nil || f.Unreadable != nil || f.Value == nil {
+			return 0, false
+		}
+		n, _ := constant.Int64Val(constant.ToInt(f.Value))
+		return n, true
+	}
+
+	when, ok := intField("when")
+	if !ok {
+		return nil, errors.New("unknown type of runtime.timer")
+	}
+	if status, ok := intField("status"); ok {
+		switch status {
+		case timerDeleted, timerRemoving, timerRemoved:
+			return nil, nil
+		case timerModifiedEarlier, timerModifiedLater:
+			when, _ = intField("nextwhen")
+		}
+	}
+	if state, ok := intField("state"); ok && state&timerZombie != 0 {
+		return nil, nil
+	}
+	period, _ := intField("period")
+
+	r := &Timer{Addr: tv.Addr, When: when, Period: period, Func: tv.fieldVariable("f"), Arg: tv.fieldVariable("arg")}
+	if r.Func == nil || r.Arg == nil {
+		return nil, errors.New("unknown type of runtime.timer")
+	}
+	return r, nil
+}
@@ -172,6 +172,41 @@ func runtimeTypeToDIE(_type *Variable, dataAddr uint64) (typ godwarf.Type, kind
 	return typ, kind, nil
 }
 
+// resolveParametricType returns the concrete type of typ if it is a type
+// parameter, or a pointer to a type parameter, of an instantiation of a
+// generic function. The concrete type is read from the dictionary at
+// dictAddr, if that fails the shape type of the type parameter is returned.
+// Other types are returned unchanged.
+func resolveParametricType(bi *BinaryInfo, mem MemoryReadWriter, typ godwarf.Type, dictAddr uint64) godwarf.Type {
+	switch t := typ.(type) {
+	case *godwarf.ParametricType:
+		if dictAddr == 0 {
+			return t.Type
+		}
+		ptrSize := int64(bi.Arch.PtrSize())
+		rtypeAddr, err := readUintRaw(mem, dictAddr+uint64(t.DictIndex*ptrSize), ptrSize)
+		if err != nil || rtypeAddr == 0 {
+			return t.Type
+		}
+		rtyp, err := bi.findType("runtime._type")
+		if err != nil {
+			return t.Type
+		}
+		concrete, _, err := runtimeTypeToDIE(newVariable("", rtypeAddr, rtyp, bi, mem), 0)
+		if err != nil {
+			return t.Type
+		}
+		return concrete
+	case *godwarf.PtrType:
+		elem := resolveParametricType(bi, mem, t.Type, dictAddr)
+		if elem == t.Type {
+			return typ
+		}
+		return pointerTo(elem, bi.Arch)
+	}
+	return typ
+}
+
 type nameOfRuntimeTypeEntry struct {
 	typename string
 	kind     int64
@@ -327,6 +327,9 @@ func GoroutinesInfo(dbp *Target, start, count int) ([]*G, int, error) {
 		if count != 0 && len(allg) >= count {
 			return allg, int(i), nil
 		}
+		if err := dbp.OperationCanceled(); err != nil {
+			return nil, -1, err
+		}
 		gvar, err := newGVariable(dbp.CurrentThread(), allgptr+(i*uint64(dbp.BinInfo().Arch.PtrSize())), true)
 		if err != nil {
 			allg = append(allg, &G{Unreadable: err})
@@ -503,16 +506,22 @@ func (g *G) UserCurrent() Location {
 // Go returns the location of the 'go' statement
 // that spawned this goroutine.
 func (g *G) Go() Location {
-	pc := g.GoPC
-	if fn := g.variable.bi.PCToFunc(pc); fn != nil {
+	return goStatementLocation(g.variable.bi, g.GoPC)
+}
+
+// goStatementLocation returns the location of the go statement whose
+// return address is gopc.
+func goStatementLocation(bi *BinaryInfo, gopc uint64) Location {
+	pc := gopc
+	if fn := bi.PCToFunc(pc); fn != nil {
 		// Backup to CALL instruction.
 		// Mimics runtime/traceback.go:677.
-		if g.GoPC > fn.Entry {
+		if gopc > fn.Entry {
 			pc--
 		}
 	}
-	f, l, fn := g.variable.bi.PCToLine(pc)
-	return Location{PC: g.GoPC, File: f, Line: l, Fn: fn}
+	f, l, fn := bi.PCToLine(pc)
+	return Location{PC: gopc, File: f, Line: l, Fn: fn}
 }
 
 // StartLoc returns the starting location of the goroutine.
@@ -540,6 +549,43 @@ func (g *G) System(tgt *Target) bool {
 	return strings.HasPrefix(loc.Fn.Name, "runtime.")
 }
 
+// GoroutineStack describes the stack of a goroutine.
+type GoroutineStack struct {
+	Lo, Hi uint64 // bounds of the stack
+	// Used is the number of bytes between the base of the stack and the
+	// stack pointer of the goroutine.
+	Used uint64
+	// Growths is the number of times the stack was doubled starting from
+	// the initial size of goroutine stacks. The runtime does not record
+	// this number, it is estimated from the current size of the stack and
+	// can be inaccurate if the stack was shrunk by the garbage collector
+	// or if the initial size of stacks changed since the goroutine was
+	// created.
+	Growths int
+}
+
+// Stack returns the bounds and the usage of the stack of g.
+func (g *G) Stack(tgt *Target) GoroutineStack {
+	r := GoroutineStack{Lo: g.stack.lo, Hi: g.stack.hi}
+	if r.Hi <= r.Lo {
+		return r
+	}
+	sp := g.SP
+	if g.Thread != nil && !g.SystemStack {
+		if regs, err := g.Thread.Registers(); err == nil {
+			sp = regs.SP()
+		}
+	}
+	if sp > r.Lo && sp <= r.Hi {
+		r.Used = r.Hi - sp
+	}
+	start := tgt.gcache.startingStackSize(g.variable.bi, g.variable.mem)
+	for size := start; size < r.Hi-r.Lo; size <<= 1 {
+		r.Growths++
+	}
+	return r
+}
+
 func (g *G) Labels() map[string]string {
 	if g.labels != nil {
 		return *g.labels
@@ -567,9 +613,11 @@ func (g *G) Labels() map[string]string {
 }
 
 type Ancestor struct {
-	ID         int64 // Goroutine ID
+	ID         int64  // Goroutine ID
+	GoPC       uint64 // PC of the go statement that created this ancestor
 	Unreadable error
 	pcsVar     *Variable
+	bi         *BinaryInfo
 }
 
 // IsNilErr is returned when a variable is nil.
@@ -726,6 +774,8 @@ func resolveTypedef(typ godwarf.Type) godwarf.Type {
 		switch tt := typ.(type) {
 		case *godwarf.TypedefType:
 			typ = tt.Type
+		case *godwarf.ParametricType:
+			typ = tt.Type
 		case *godwarf.QualType:
 			typ = tt.Type
 		default:
@@ -984,6 +1034,11 @@ func Ancestors(p Process, g *G, n int) ([]Ancestor, error) {
 			continue
 		}
 		r[i].ID, _ = constant.Int64Val(goidv.Value)
+		r[i].bi = p.BinInfo()
+		if gopcv := av.Children[i].fieldVariable("gopc"); gopcv != nil && gopcv.Unreadable == nil {
+			gopc, _ := constant.Int64Val(gopcv.Value)
+			r[i].GoPC = uint64(gopc)
+		}
 		pcsVar := av.Children[i].fieldVariable("pcs")
 		if pcsVar.Unreadable != nil {
 			r[i].Unreadable = pcsVar.Unreadable
@@ -996,6 +1051,14 @@ func Ancestors(p Process, g *G, n int) ([]Ancestor, error) {
 	return r, nil
 }
 
+// Go returns the location of the go statement that created ancestor 'a'.
+func (a *Ancestor) Go() Location {
+	if a.bi == nil || a.GoPC == 0 {
+		return Location{}
+	}
+	return goStatementLocation(a.bi, a.GoPC)
+}
+
 // Stack returns the stack trace of ancestor 'a' as saved by the runtime.
 func (a *Ancestor) Stack(n int) ([]Stackframe, error) {
 	if a.Unreadable != nil {
@@ -1035,17 +1098,27 @@ func (a *Ancestor) Stack(n int) ([]Stackframe, error) {
 }
 
 func (v *Variable) structMember(memberName string) (*Variable, error) {
+	r, _, err := v.structMemberDepth(memberName)
+	return r, err
+}
+
+// structMemberDepth returns the field memberName of v and the depth at
+// which it was found, following Go's selector rules: embedded structs are
+// searched one depth at a time, a field hides the fields with the same
+// name at deeper depths and two fields with the same name at the
+// shallowest depth are ambiguous.
+func (v *Variable) structMemberDepth(memberName string) (*Variable, int, error) {
 	if v.Unreadable != nil {
-		return v.clone(), nil
+		return v.clone(), 0, nil
 	}
 	vname := v.Name
 	if v.loaded && (v.Flags&VariableFakeAddress) != 0 {
 		for i := range v.Children {
 			if v.Children[i].Name == memberName {
-				return &v.Children[i], nil
+				return &v.Children[i], 0, nil
 			}
 		}
-		return nil, fmt.Errorf("%s has no member %s", vname, memberName)
+		return nil, 0, fmt.Errorf("%s has no member %s", vname, memberName)
 	}
 	switch v.Kind {
 	case reflect.Chan:
@@ -1062,56 +1135,72 @@ func (v *Variable) structMember(memberName string) (*Variable, error) {
 	seen := map[string]struct{}{} // prevent infinite loops
 	first := true
 
-	for len(queue) > 0 {
-		v := queue[0]
-		queue = append(queue[:0], queue[1:]...)
-		if _, isseen := seen[v.RealType.String()]; isseen {
-			continue
-		}
-		seen[v.RealType.String()] = struct{}{}
+	for depth := 0; len(queue) > 0; depth++ {
+		var found *Variable
+		var next []*Variable
+		var levelSeen []string
 
-		structVar := v.maybeDereference()
-		structVar.Name = v.Name
-		if structVar.Unreadable != nil {
-			return structVar, nil
-		}
+		for _, v := range queue {
+			if _, isseen := seen[v.RealType.String()]; isseen {
+				continue
+			}
+			levelSeen = append(levelSeen, v.RealType.String())
 
-		switch t := structVar.RealType.(type) {
-		case *godwarf.StructType:
-			for _, field := range t.Field {
-				if field.Name == memberName {
-					return structVar.toField(field)
-				}
-				isEmbeddedStructMember :=
-					field.Embedded ||
-						(field.Type.Common().Name == field.Name) ||
-						(len(field.Name) > 1 &&
-							field.Name[0] == '*' &&
-							field.Type.Common().Name[1:] == field.Name[1:])
-				if !isEmbeddedStructMember {
-					continue
-				}
-				embeddedVar, err := structVar.toField(field)
-				if err != nil {
-					return nil, err
+			structVar := v.maybeDereference()
+			structVar.Name = v.Name
+			if structVar.Unreadable != nil {
+				return structVar, depth, nil
+			}
+
+			switch t := structVar.RealType.(type) {
+			case *godwarf.StructType:
+				for _, field := range t.Field {
+					isEmbeddedStructMember :=
+						field.Embedded ||
+							(field.Type.Common().Name == field.Name) ||
+							(len(field.Name) > 1 &&
+								field.Name[0] == '*' &&
+								field.Type.Common().Name[1:] == field.Name[1:])
+					// Check for embedded field referenced by type name
+					parts := strings.Split(field.Name, ".")
+					if field.Name != memberName && !(isEmbeddedStructMember && len(parts) > 1 && parts[1] == memberName) {
+						if isEmbeddedStructMember {
+							embeddedVar, err := structVar.toField(field)
+							if err != nil {
+								return nil, depth, err
+							}
+							embeddedVar.Name = structVar.Name
+							next = append(next, embeddedVar)
+						}
+						continue
+					}
+					if found != nil {
+						return nil, depth, fmt.Errorf("ambiguous selector %s.%s", vname, memberName)
+					}
+					var err error
+					found, err = structVar.toField(field)
+					if err != nil {
+						return nil, depth, err
+					}
 				}
-				// Check for embedded field referenced by type name
-				parts := strings.Split(field.Name, ".")
-				if len(parts) > 1 && parts[1] == memberName {
-					return embeddedVar, nil
+			default:
+				if first {
+					return nil, depth, fmt.Errorf("%s (type %s) is not a struct", vname, structVar.TypeString())
 				}
-				embeddedVar.Name = structVar.Name
-				queue = append(queue, embeddedVar)
-			}
-		default:
-			if first {
-				return nil, fmt.Errorf("%s (type %s) is not a struct", vname, structVar.TypeString())
 			}
+			first = false
+		}
+
+		if found != nil {
+			return found, depth, nil
 		}
-		first = false
+		for _, typ := range levelSeen {
+			seen[typ] = struct{}{}
+		}
+		queue = next
 	}
 
-	return nil, fmt.Errorf("%s has no member %s", vname, memberName)
+	return nil, 0, fmt.Errorf("%s has no member %s", vname, memberName)
 }
 
 func readVarEntry(entry *godwarf.Tree, image *Image) (name string, typ godwarf.Type, err error) {
@@ -1137,7 +1226,7 @@ func extractVarInfoFromEntry(tgt *Target, bi *BinaryInfo, image *Image, regs op.
 
 	n, t, err := readVarEntry(entry, image)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("could not read debug info: %v", err)
 	}
 
 	addr, pieces, descr, err := bi.Location(entry, dwarf.AttrLocation, regs.PC(), regs)
@@ -1184,7 +1273,7 @@ func (v *Variable) maybeDereference() *Variable {
 		ptrval, err := readUintRaw(v.mem, v.Addr, t.ByteSize)
 		r := v.newVariable("", ptrval, t.Type, DereferenceMemory(v.mem))
 		if err != nil {
-			r.Unreadable = err
+			r.Unreadable = v.memoryReadError(err, t.ByteSize)
 		}
 
 		return r
@@ -1193,6 +1282,29 @@ func (v *Variable) maybeDereference() *Variable {
 	}
 }
 
+// memoryReadError describes the failure to read size bytes at the address
+// of v, the errors returned by the backends (usually EIO or EFAULT) don't
+// say which memory couldn't be read. Variables without a real address
+// report errors from compositeMemory, which are already descriptive.
+func (v *Variable) memoryReadError(err error, size int64) error {
+	if err == nil || v.Flags&VariableFakeAddress != 0 {
+		return err
+	}
+	if _, exited := err.(ErrProcessExited); exited {
+		return err
+	}
+	return fmt.Errorf("could not read %d bytes at %#x: %v", size, v.Addr, err)
+}
+
+// unreadableVariable returns a variable called name reporting err, it is
+// used in place of variables whose debug info could not be interpreted so
+// that they are listed along with the others.
+func unreadableVariable(name string, err error, bi *BinaryInfo, mem MemoryReadWriter) *Variable {
+	v := newVariable(name, 0, &godwarf.UnsupportedType{CommonType: godwarf.CommonType{Name: "<unknown>"}}, bi, mem)
+	v.Unreadable = err
+	return v
+}
+
 func loadValues(vars []*Variable, cfg LoadConfig) {
 	for i := range vars {
 		vars[i].loadValueInternal(0, cfg)
@@ -1300,26 +1412,28 @@ func (v *Variable) loadValueInternal(recurseLevel int, cfg LoadConfig) {
 		v.readComplex(v.RealType.(*godwarf.ComplexType).ByteSize)
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		var val int64
-		val, v.Unreadable = readIntRaw(v.mem, v.Addr, v.RealType.(*godwarf.IntType).ByteSize)
+		var err error
+		val, err = readIntRaw(v.mem, v.Addr, v.RealType.(*godwarf.IntType).ByteSize)
+		v.Unreadable = v.memoryReadError(err, v.RealType.Size())
 		v.Value = constant.MakeInt64(val)
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
 		if v.Flags&VariableCPURegister != 0 {
 			v.Value = constant.MakeUint64(v.reg.Uint64Val)
 		} else {
-			var val uint64
-			val, v.Unreadable = readUintRaw(v.mem, v.Addr, v.RealType.(*godwarf.UintType).ByteSize)
+			val, err := readUintRaw(v.mem, v.Addr, v.RealType.(*godwarf.UintType).ByteSize)
+			v.Unreadable = v.memoryReadError(err, v.RealType.Size())
 			v.Value = constant.MakeUint64(val)
 		}
 	case reflect.Bool:
 		val := make([]byte, 1)
 		_, err := v.mem.ReadMemory(val, v.Addr)
-		v.Unreadable = err
+		v.Unreadable = v.memoryReadError(err, 1)
 		if err == nil {
 			v.Value = constant.MakeBool(val[0] != 0)
 		}
 	case reflect.Float32, reflect.Float64:
-		var val float64
-		val, v.Unreadable = v.readFloatRaw(v.RealType.(*godwarf.FloatType).ByteSize)
+		val, err := v.readFloatRaw(v.RealType.(*godwarf.FloatType).ByteSize)
+		v.Unreadable = v.memoryReadError(err, v.RealType.Size())
 		v.Value = constant.MakeFloat64(val)
 		switch {
 		case math.IsInf(val, +1):
@@ -1343,6 +1457,9 @@ func (v *Variable) loadValueInternal(recurseLevel int, cfg LoadConfig) {
 // containing a single pointer)
 func convertToEface(srcv, dstv *Variable) error {
 	if dstv.RealType.String() != "interface {}" {
+		if _, isiface := dstv.RealType.(*godwarf.InterfaceType); isiface {
+			return convertToIface(srcv, dstv)
+		}
 		return &typeConvErr{srcv.DwarfType, dstv.RealType}
 	}
 	if _, isiface := srcv.RealType.(*godwarf.InterfaceType); isiface {
@@ -1365,6 +1482,84 @@ func convertToEface(srcv, dstv *Variable) error {
 	return dstv.writeEmptyInterface(typeAddr, srcv)
 }
 
+// convertToIface converts srcv, a pointer shaped variable, into the
+// non-empty interface type of dstv and writes it to dstv.
+// The itab for the conversion is not created, it must already exist in
+// runtime.itabTable, which means that the conversion must appear somewhere
+// in the target program.
+func convertToIface(srcv, dstv *Variable) error {
+	if _, isiface := srcv.RealType.(*godwarf.InterfaceType); isiface {
+		return &typeConvErr{srcv.DwarfType, dstv.RealType}
+	}
+	typeAddr, typeKind, runtimeTypeFound, err := dwarfToRuntimeType(srcv.bi, srcv.mem, srcv.RealType)
+	if err != nil {
+		return err
+	}
+	if !runtimeTypeFound || typeKind&kindDirectIface == 0 {
+		return &typeConvErr{srcv.DwarfType, dstv.RealType}
+	}
+	interAddr, _, runtimeTypeFound, err := dwarfToRuntimeType(dstv.bi, dstv.mem, dstv.DwarfType)
+	if err != nil {
+		return err
+	}
+	if !runtimeTypeFound {
+		return &typeConvErr{srcv.DwarfType, dstv.RealType}
+	}
+	tabAddr, err := findItab(dstv.bi, dstv.mem, interAddr, typeAddr)
+	if err != nil {
+		return err
+	}
+	if tabAddr == 0 {
+		return fmt.Errorf("can not convert %s to %s: conversion not used by the target program", srcv.DwarfType, dstv.RealType)
+	}
+	return dstv.writeInterface(tabAddr, srcv)
+}
+
+// findItab returns the address of the itab converting the type at typeAddr
+// to the interface type at interAddr, or 0 if no such itab exists in
+// runtime.itabTable.
+func findItab(bi *BinaryInfo, mem MemoryReadWriter, interAddr, typeAddr uint64) (uint64, error) {
+	scope := globalScope(bi, bi.Images[0], mem)
+	itabTable, err := scope.findGlobal("runtime", "itabTable")
+	if err != nil {
+		return 0, err
+	}
+	itabTable = itabTable.maybeDereference()
+	if itabTable.Unreadable != nil {
+		return 0, itabTable.Unreadable
+	}
+	sizev := itabTable.loadFieldNamed("size")
+	entries, err := itabTable.structMember("entries")
+	if sizev == nil || err != nil {
+		return 0, errors.New("unreadable runtime.itabTable")
+	}
+	size, _ := constant.Uint64Val(sizev.Value)
+	ptrSize := int64(bi.Arch.PtrSize())
+	for i := uint64(0); i < size; i++ {
+		tab, err := readUintRaw(mem, entries.Addr+i*uint64(ptrSize), ptrSize)
+		if err != nil {
+			return 0, err
+		}
+		if tab == 0 {
+			continue
+		}
+		// The first two fields of runtime.itab are the interface type and the
+		// concrete type.
+		inter, err := readUintRaw(mem, tab, ptrSize)
+		if err != nil {
+			return 0, err
+		}
+		typ, err := readUintRaw(mem, tab+uint64(ptrSize), ptrSize)
+		if err != nil {
+			return 0, err
+		}
+		if inter == interAddr && typ == typeAddr {
+			return tab, nil
+		}
+	}
+	return 0, nil
+}
+
 func readStringInfo(mem MemoryReadWriter, arch *Arch, addr uint64) (uint64, int64, error) {
 	// string data structure is always two ptrs in size. Addr, followed by len
 	// http://research.swtch.com/godata
@@ -1583,6 +1778,12 @@ func (v *Variable) loadArrayValues(recurseLevel int, cfg LoadConfig) {
 	}
 
 	for i := int64(0); i < count; i++ {
+		if i%1024 == 0 {
+			if err := memOperationCanceled(mem); err != nil {
+				v.Unreadable = err
+				return
+			}
+		}
 		fieldvar := v.newVariable("", uint64(int64(v.Base)+(i*v.stride)), v.fieldType, mem)
 		fieldvar.loadValueInternal(recurseLevel+1, cfg)
 
@@ -1754,6 +1955,25 @@ func (v *Variable) writeEmptyInterface(typeAddr uint64, data *Variable) error {
 	return nil
 }
 
+func (v *Variable) writeInterface(tabAddr uint64, data *Variable) error {
+	ityp := resolveTypedef(&v.RealType.(*godwarf.InterfaceType).TypedefType).(*godwarf.StructType)
+	for _, f := range ityp.Field {
+		switch f.Name {
+		case "tab":
+			tabv, _ := v.toField(f)
+			if err := tabv.writeUint(tabAddr, tabv.RealType.Size()); err != nil {
+				return err
+			}
+		case "data":
+			datav, _ := v.toField(f)
+			if err := datav.writeCopy(data); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func (v *Variable) writeSlice(len, cap int64, base uint64) error {
 	for _, f := range v.RealType.(*godwarf.SliceType).Field {
 		switch f.Name {
@@ -0,0 +1,165 @@
+package proc
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"sort"
+
+	"github.com/go-delve/delve/pkg/dwarf/godwarf"
+)
+
+// MemoryRegion is a memory mapping of the target annotated with the
+// structures of the Go runtime it contains.
+type MemoryRegion struct {
+	MemoryMapEntry
+	// Image is the path of the executable or shared library mapped by
+	// this region, if any.
+	Image string
+	// GoHeap is true if the region contains spans of the Go heap.
+	GoHeap bool
+	// GoroutineStacks is the number of goroutine stacks in the region.
+	GoroutineStacks int
+}
+
+// Span states of the Go heap, see $GOROOT/src/runtime/mheap.go.
+const (
+	mSpanInUse = 1 // allocated for the garbage collected heap
+)
+
+// goPageSize is the size of the pages of the Go heap (runtime._PageSize).
+const goPageSize = 8192
+
+// maxHeapSpans is the maximum number of spans read from runtime.mheap_.
+const maxHeapSpans = 1 << 24
+
+// MemoryRegions returns the memory map of the target, annotated with the
+// regions belonging to the executable and its shared libraries, to the Go
+// heap and to goroutine stacks.
+func (t *Target) MemoryRegions() ([]MemoryRegion, error) {
+	mappings, err := t.MemoryMap()
+	if err != nil {
+		return nil, err
+	}
+	r := make([]MemoryRegion, len(mappings))
+	for i := range mappings {
+		r[i].MemoryMapEntry = mappings[i]
+	}
+	sort.Slice(r, func(i, j int) bool { return r[i].Addr < r[j].Addr })
+
+	find := func(addr uint64) *MemoryRegion {
+		i := sort.Search(len(r), func(i int) bool { return r[i].Addr+r[i].Size > addr })
+		if i < len(r) && r[i].Addr <= addr {
+			return &r[i]
+		}
+		return nil
+	}
+
+	images := map[string]string{}
+	for _, image := range t.BinInfo().Images {
+		images[realPath(image.Path)] = image.Path
+	}
+	for i := range r {
+		if r[i].Filename != "" {
+			r[i].Image = images[realPath(r[i].Filename)]
+		}
+	}
+
+	gs, _, err := GoroutinesInfo(t, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range gs {
+		if g.stack.lo == 0 || g.stack.hi <= g.stack.lo {
+			continue
+		}
+		if m := find(g.stack.lo); m != nil {
+			m.GoroutineStacks++
+		}
+	}
+
+	err = t.heapSpans(func(start, npages uint64, state uint8) {
+		if state != mSpanInUse {
+			return
+		}
+		for _, addr := range []uint64{start, start + npages*goPageSize - 1} {
+			if m := find(addr); m != nil {
+				m.GoHeap = true
+			}
+		}
+	})
+	return r, err
+}
+
+// heapSpans calls fn for every span of the Go heap listed in
+// runtime.mheap_.allspans.
+func (t *Target) heapSpans(fn func(start, npages uint64, state uint8)) error {
+	bi := t.BinInfo()
+	mem := t.Memory()
+	scope := globalScope(bi, bi.Images[0], mem)
+	allspans, err := scope.EvalExpression("runtime.mheap_.allspans", LoadConfig{})
+	if err != nil {
+		return err
+	}
+	if allspans.Kind != reflect.Slice {
+		return errors.New("unknown type of runtime.mheap_.allspans")
+	}
+	if allspans.Len > maxHeapSpans {
+		return errors.New("too many spans in runtime.mheap_.allspans")
+	}
+	ptrtyp, ok := resolveTypedef(allspans.fieldType).(*godwarf.PtrType)
+	if !ok {
+		return errors.New("unknown type of runtime.mheap_.allspans")
+	}
+	mspanType := ptrtyp.Type
+
+	ptrSize := int64(bi.Arch.PtrSize())
+	spans := cacheMemory(mem, allspans.Base, int(allspans.Len*ptrSize))
+	for i := int64(0); i < allspans.Len; i++ {
+		p, err := readUintRaw(spans, allspans.Base+uint64(i*ptrSize), ptrSize)
+		if err != nil {
+			return err
+		}
+		if p == 0 {
+			continue
+		}
+		s := newVariable("", p, mspanType, bi, mem)
+		startv, err := s.structMember("startAddr")
+		if err != nil {
+			return err
+		}
+		npagesv, err := s.structMember("npages")
+		if err != nil {
+			return err
+		}
+		// Since Go 1.14 the state is wrapped into mSpanStateBox, the
+		// wrapped value is always its first byte.
+		statev, err := s.structMember("state")
+		if err != nil {
+			return err
+		}
+		start, err := readUintRaw(mem, startv.Addr, ptrSize)
+		if err != nil {
+			return err
+		}
+		npages, err := readUintRaw(mem, npagesv.Addr, ptrSize)
+		if err != nil {
+			return err
+		}
+		state, err := readUintRaw(mem, statev.Addr, 1)
+		if err != nil {
+			return err
+		}
+		fn(start, npages, uint8(state))
+	}
+	return nil
+}
+
+// realPath returns path with its symbolic links evaluated, or path itself
+// if they can not be evaluated.
+func realPath(path string) string {
+	if p, err := filepath.EvalSymlinks(path); err == nil {
+		return p
+	}
+	return path
+}
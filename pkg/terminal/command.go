@@ -5,6 +5,7 @@ package terminal
 import (
 	"bufio"
 	"bytes"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"go/parser"
@@ -23,6 +24,7 @@ import (
 	"time"
 
 	"github.com/cosiner/argv"
+	"github.com/go-delve/delve/pkg/config"
 	"github.com/go-delve/delve/pkg/locspec"
 	"github.com/go-delve/delve/pkg/terminal/colorize"
 	"github.com/go-delve/delve/service"
@@ -67,7 +69,10 @@ type command struct {
 	group           commandGroup
 	allowedPrefixes cmdPrefix
 	helpMsg         string
-	cmdFn           cmdfunc
+	// related are the names of the commands listed in the "See also"
+	// section of the help of this command.
+	related []string
+	cmdFn   cmdfunc
 }
 
 // Returns true if the command string matches one of the aliases for this command
@@ -116,27 +121,49 @@ func DebugCommands(client service.Client) *Commands {
 	help [command]
 
 Type "help" followed by the name of a command for more information about it.`},
-		{aliases: []string{"break", "b"}, group: breakCmds, cmdFn: breakpoint, helpMsg: `Sets a breakpoint.
+		{aliases: []string{"break", "b"}, related: []string{"on", "condition", "clear", "toggle", "breakpoints"}, group: breakCmds, cmdFn: breakpoint, helpMsg: `Sets a breakpoint.
 
-	break [name] <linespec>
+	break [-g <group>]... [name] <linespec> [-caller <function>] [if <condition>]
 
 See $GOPATH/src/github.com/go-delve/delve/Documentation/cli/locspec.md for the syntax of linespec.
 
-See also: "help on", "help cond" and "help clear"`},
-		{aliases: []string{"trace", "t"}, group: breakCmds, cmdFn: tracepoint, helpMsg: `Set tracepoint.
+The -g option adds the breakpoint to a group, it can be repeated to add the breakpoint to more than one group. All the breakpoints of a group can be enabled or disabled with "toggle -g" and deleted with "clear -g".
 
-	trace [name] <linespec>
+With -caller the breakpoint only stops when the function containing it was called directly by the specified function, a suffix of the function name that starts with a package name is enough. See also "condition -caller".
 
-A tracepoint is a breakpoint that does not stop the execution of the program, instead when the tracepoint is hit a notification is displayed. See $GOPATH/src/github.com/go-delve/delve/Documentation/cli/locspec.md for the syntax of linespec.
+If a condition is specified the breakpoint only stops when the condition, a boolean expression evaluated in the scope of the breakpoint, is true. The condition can be changed later with the "condition" command.
 
-See also: "help on", "help cond" and "help clear"`},
-		{aliases: []string{"watch"}, group: breakCmds, cmdFn: watchpoint, helpMsg: `Set watchpoint.
+Examples:
+
+	break main.go:42
+	break mybp main.(*Server).handle
+	break -g auth handlers.go:42
+	break db.go:10 -caller mypkg.HandleRequest
+	break loop.go:12 if i == 42 && name == "foo"`},
+		{aliases: []string{"trace", "t"}, related: []string{"break", "on", "condition", "clear"}, group: breakCmds, cmdFn: tracepoint, helpMsg: `Set tracepoint.
+
+	trace [-g <group>]... [name] <linespec> [-caller <function>] [if <condition>]
+
+A tracepoint is a breakpoint that does not stop the execution of the program, instead when the tracepoint is hit a notification is displayed. See $GOPATH/src/github.com/go-delve/delve/Documentation/cli/locspec.md for the syntax of linespec. See "help break" for the -g and -caller options and for conditions.`},
+		{aliases: []string{"break-origin"}, related: []string{"goroutines", "break"}, group: breakCmds, cmdFn: breakOrigin, helpMsg: `Sets a breakpoint where a goroutine was created.
+
+	break-origin [-start] [name]
+
+Sets a breakpoint on the go statement that created the selected goroutine. If -start is specified the breakpoint is set on the first line of the goroutine's start function instead.
+Use the goroutine prefix to select a different goroutine, for example:
+
+	goroutine 12 break-origin -start`},
+		{aliases: []string{"watch"}, related: []string{"print", "history", "clear"}, group: breakCmds, cmdFn: watchpoint, helpMsg: `Set watchpoint.
 	
 	watch [-r|-w|-rw] <expr>
+	watch -history <expr>
+	watch -chan <expr>
 	
-	-r	stops when the memory location is read
-	-w	stops when the memory location is written
-	-rw	stops when the memory location is read or written
+	-r		stops when the memory location is read
+	-w		stops when the memory location is written
+	-rw		stops when the memory location is read or written
+	-history	records the last 10 writes to the memory location, without stopping
+	-chan		stops when any goroutine sends to or receives from the channel <expr>
 
 The memory location is specified with the same expression language used by 'print', for example:
 
@@ -144,8 +171,28 @@ The memory location is specified with the same expression language used by 'prin
 
 will watch the address of variable 'v'.
 
-See also: "help print".`},
-		{aliases: []string{"restart", "r"}, group: runCmds, cmdFn: restart, helpMsg: `Restart process.
+Read watchpoints, set with -r, can be used to find which goroutine is reading a variable. The hardware can not watch only reads, writes are recognized because they change the content of the memory location and do not stop the program: a write of the value already stored is reported as a read.
+
+The writes recorded with -history, together with the goroutine and the stacktrace of the writer, are displayed by the history command.
+
+With -chan the program stops inside runtime.chansend or runtime.chanrecv, the channel operation is in the caller frame, see "help stepout" and "help frame". Operations executed by select statements with more than one case are not caught.`},
+		{aliases: []string{"history"}, related: []string{"watch", "on"}, group: breakCmds, allowedPrefixes: onPrefix, cmdFn: watchHistory, helpMsg: `Prints the writes recorded by a watchpoint or the hits recorded by a breakpoint.
+
+	history [-full] <expr|name|id>
+	on <breakpoint name or id> history [<n>]
+
+Prints the writes recorded by a watchpoint set with 'watch -history', oldest first. For each write the location of the writing instruction, the goroutine and the new value are printed, with -full the stacktrace of the goroutine is also printed. The watchpoint is specified by its name, its ID or the expression used to create it.
+
+When used with the 'on' prefix the breakpoint records its last n hits, 10 by default, instead of stopping the target: for each hit the goroutine, its stacktrace and the values of the expressions added with 'on <breakpoint> print' are recorded and later printed by 'history'. The number of stack frames recorded can be changed with 'on <breakpoint> stack'. Use 'on <breakpoint> history 0' to make the breakpoint stop the target again.
+
+Example:
+
+	break mybp server.go:120
+	on mybp print req.URL.Path
+	on mybp history 20
+	continue
+	history -full mybp`},
+		{aliases: []string{"restart", "r"}, related: []string{"rebuild", "checkpoint", "rewind"}, group: runCmds, cmdFn: restart, helpMsg: `Restart process.
 
 For recorded targets the command takes the following forms:
 
@@ -166,28 +213,50 @@ A list of file redirections can be specified after the new argument list to over
 	>output.txt	redirects the standard output of the target process to output.txt
 	2>error.txt	redirects the standard error of the target process to error.txt
 `},
-		{aliases: []string{"rebuild"}, group: runCmds, cmdFn: c.rebuild, allowedPrefixes: revPrefix, helpMsg: "Rebuild the target executable and restarts it. It does not work if the executable was not built by delve."},
-		{aliases: []string{"continue", "c"}, group: runCmds, cmdFn: c.cont, allowedPrefixes: revPrefix, helpMsg: `Run until breakpoint or program termination.
+		{aliases: []string{"rebuild"}, related: []string{"restart"}, group: runCmds, cmdFn: c.rebuild, allowedPrefixes: revPrefix, helpMsg: "Rebuild the target executable and restarts it. It does not work if the executable was not built by delve."},
+		{aliases: []string{"continue", "c"}, related: []string{"next", "step", "stepout", "rewind"}, group: runCmds, cmdFn: c.cont, allowedPrefixes: revPrefix, helpMsg: `Run until breakpoint or program termination.
 
 	continue [<linespec>]
+	continue [-max <n>] -until <expr>
 
 Optional linespec argument allows you to continue until a specific location is reached. The program will halt if a breakpoint is hit before reaching the specified location.
 
+Pressing Ctrl-C while the program is running stops it and returns to the prompt, a next, step or stepout in progress is interrupted. Pressing Ctrl-C twice at the prompt exits the debugger.
+
 For example:
 
 	continue main.main
 	continue encoding/json.Marshal
+
+With -until the program is continued past breakpoints and watchpoints until the boolean expression expr, evaluated in the scope of the current goroutine every time one is hit, is true. The loop is executed by the debugger without returning to the client, at most n times (default: 1000), and is also stopped by any other event, like a manual stop.
+
+For example:
+
+	continue -until i == 100
+	continue -max 10000 -until len(queue) > 5
+`},
+		{aliases: []string{"step", "s"}, related: []string{"next", "stepout", "step-instruction"}, group: runCmds, cmdFn: c.step, allowedPrefixes: revPrefix, helpMsg: `Single step through program.
+
+	step [count]
+
+Optional [count] argument allows you to step multiple times. Stepping stops early if a breakpoint is hit.
 `},
-		{aliases: []string{"step", "s"}, group: runCmds, cmdFn: c.step, allowedPrefixes: revPrefix, helpMsg: "Single step through program."},
-		{aliases: []string{"step-instruction", "si"}, group: runCmds, allowedPrefixes: revPrefix, cmdFn: c.stepInstruction, helpMsg: "Single step a single cpu instruction."},
-		{aliases: []string{"next", "n"}, group: runCmds, cmdFn: c.next, allowedPrefixes: revPrefix, helpMsg: `Step over to next source line.
+		{aliases: []string{"step-instruction", "si"}, related: []string{"step", "disassemble"}, group: runCmds, allowedPrefixes: revPrefix, cmdFn: c.stepInstruction, helpMsg: "Single step a single cpu instruction."},
+		{aliases: []string{"next", "n"}, related: []string{"step", "stepout", "continue"}, group: runCmds, cmdFn: c.next, allowedPrefixes: revPrefix, helpMsg: `Step over to next source line.
 
 	next [count]
 
-Optional [count] argument allows you to skip multiple lines.
+Optional [count] argument allows you to skip multiple lines. Stepping stops early if a breakpoint is hit.
+`},
+		{aliases: []string{"stepout", "so"}, related: []string{"step", "next"}, group: runCmds, allowedPrefixes: revPrefix, cmdFn: c.stepout, helpMsg: `Step out of the current function.
+
+	stepout [count]
+
+Optional [count] argument allows you to step out of multiple functions. Stepping stops early if a breakpoint is hit.
+
+The values returned by the function are printed after the stop location, as "=> (returned: ...)". The same happens when next steps across the return of the current function.
 `},
-		{aliases: []string{"stepout", "so"}, group: runCmds, allowedPrefixes: revPrefix, cmdFn: c.stepout, helpMsg: "Step out of the current function."},
-		{aliases: []string{"call"}, group: runCmds, cmdFn: c.call, helpMsg: `Resumes process, injecting a function call (EXPERIMENTAL!!!)
+		{aliases: []string{"call"}, related: []string{"print", "set"}, group: runCmds, cmdFn: c.call, helpMsg: `Resumes process, injecting a function call (EXPERIMENTAL!!!)
 	
 	call [-unsafe] <function call expression>
 	
@@ -203,24 +272,43 @@ Current limitations:
 - calling a function will resume execution of all goroutines.
 - only supported on linux's native backend.
 `},
-		{aliases: []string{"threads"}, group: goroutineCmds, cmdFn: threads, helpMsg: "Print out info for every traced thread."},
-		{aliases: []string{"thread", "tr"}, group: goroutineCmds, cmdFn: thread, helpMsg: `Switch to the specified thread.
+		{aliases: []string{"threads"}, related: []string{"thread", "goroutines"}, group: goroutineCmds, cmdFn: threads, helpMsg: `Print out info for every traced thread.
+
+	threads [-t [<depth>]]
+
+Threads stopped at a breakpoint and threads blocked in a system call are marked as such.
+
+	-t	prints a stacktrace for each thread, <depth> frames deep (10 by default)`},
+		{aliases: []string{"thread", "tr"}, related: []string{"threads", "goroutine"}, group: goroutineCmds, cmdFn: thread, helpMsg: `Switch to the specified thread.
 
 	thread <id>`},
-		{aliases: []string{"clear"}, group: breakCmds, cmdFn: clear, helpMsg: `Deletes breakpoint.
+		{aliases: []string{"clear"}, related: []string{"clearall", "toggle", "breakpoints"}, group: breakCmds, cmdFn: clear, helpMsg: `Deletes breakpoint.
 
-	clear <breakpoint name or id>`},
-		{aliases: []string{"clearall"}, group: breakCmds, cmdFn: clearAll, helpMsg: `Deletes multiple breakpoints.
+	clear <breakpoint name or id>
+	clear -g <group>
+
+The second form deletes all the breakpoints of a group, see "help break".`},
+		{aliases: []string{"clearall"}, related: []string{"clear", "breakpoints"}, group: breakCmds, cmdFn: clearAll, helpMsg: `Deletes multiple breakpoints.
 
 	clearall [<linespec>]
+	clearall -file <file>
+	clearall -func <function>
+
+If called with the linespec argument it will delete all the breakpoints matching the linespec. If linespec is omitted all breakpoints are deleted.
+
+The second and third forms delete all the breakpoints in a file, or in a function. The file can be specified with the last components of its path, for example "clearall -file main.go".
+
+The breakpoints set internally by Delve, like the one on unrecovered panics, are not deleted, see "help breakpoints".`},
+		{aliases: []string{"toggle"}, related: []string{"clear", "breakpoints"}, group: breakCmds, cmdFn: toggle, helpMsg: `Toggles on or off a breakpoint.
 
-If called with the linespec argument it will delete all the breakpoints matching the linespec. If linespec is omitted all breakpoints are deleted.`},
-		{aliases: []string{"toggle"}, group: breakCmds, cmdFn: toggle, helpMsg: `Toggles on or off a breakpoint.
+	toggle <breakpoint name or id>
+	toggle -g <group> [on|off]
 
-toggle <breakpoint name or id>`},
-		{aliases: []string{"goroutines", "grs"}, group: goroutineCmds, cmdFn: goroutines, helpMsg: `List program goroutines.
+A breakpoint can be referred to as $, the last breakpoint created with break or trace. The second form enables (on) or disables (off) all the breakpoints of a group, see "help break". If neither on or off is specified the breakpoints of the group are disabled if any of them is enabled, otherwise they are enabled.`},
+		{aliases: []string{"goroutines", "grs"}, related: []string{"goroutine", "stack", "freeze"}, group: goroutineCmds, cmdFn: goroutines, helpMsg: `List program goroutines.
 
-	goroutines [-u|-r|-g|-s] [-t [depth]] [-l] [-with loc expr] [-without loc expr] [-group argument]
+	goroutines [-u|-r|-g|-s] [-t [depth]] [-l] [-a [n]] [-stack] [-all] [-with loc expr] [-without loc expr] [-group argument]
+	goroutines -diff [-u|-r|-g|-s] [-t [depth]] [-l]
 
 Print out info for every goroutine. The flag controls what information is shown along with each goroutine:
 
@@ -230,11 +318,15 @@ Print out info for every goroutine. The flag controls what information is shown
 	-s	displays location of the start function
 	-t	displays goroutine's stacktrace (an optional depth value can be specified, default: 10)
 	-l	displays goroutine's labels
+	-a	displays the goroutines that created each goroutine, up to n generations (default: 10), combined with -t also displays their stacktraces (target process must have tracebackancestors enabled)
+	-stack	displays the bounds of the goroutine's stack, how much of it is in use and how many times it was grown (estimated from its size)
 
 If no flag is specified the default is -u, i.e. the first frame within the first 30 frames that is not executing a runtime private function.
 
 FILTERING
 
+Goroutines of the runtime that are not executing code of the program, like the workers of the garbage collector and the finalizer goroutine while it waits for finalizers, are not displayed unless -all is specified.
+
 If -with or -without are specified only goroutines that match the given condition are returned.
 
 To only display goroutines where the specified location contains (or does not contain, for -without and -wo) expr as a substring, use:
@@ -266,41 +358,140 @@ To only display user (or runtime) goroutines, use:
 	goroutines -with user
 	goroutines -without user
 
+To only display goroutines of the runtime (or other goroutines), use:
+
+	goroutines -all -with runtime
+	goroutines -without runtime
+
 GROUPING
 
-	goroutines -group (userloc|curloc|goloc|startloc|running|user)
+	goroutines -group (userloc|curloc|goloc|startloc|running|user|runtime)
 
-Groups goroutines by the given location, running status or user classification, up to 5 goroutines per group will be displayed as well as the total number of goroutines in the group.
+Groups goroutines by the given location, running status, user or runtime classification, up to 5 goroutines per group will be displayed as well as the total number of goroutines in the group.
 
 	goroutines -group label key
 
 Groups goroutines by the value of the label with the specified key.
+
+DIFF
+
+	goroutines -diff
+
+Lists the goroutines created and the goroutines exited between the previous stop and the current one, showing the location of their start function unless another location flag is specified. The first time -diff is used the current goroutines are recorded, from then on the goroutines are recorded at every stop.
 `},
-		{aliases: []string{"goroutine", "gr"}, group: goroutineCmds, allowedPrefixes: onPrefix, cmdFn: c.goroutine, helpMsg: `Shows or changes current goroutine
+		{aliases: []string{"goroutine", "gr"}, related: []string{"goroutines", "frame", "stack"}, group: goroutineCmds, allowedPrefixes: onPrefix, cmdFn: c.goroutine, helpMsg: `Shows or changes current goroutine
 
 	goroutine
 	goroutine <id>
 	goroutine <id> <command>
 
 Called without arguments it will show information about the current goroutine.
-Called with a single argument it will switch to the specified goroutine.
+Called with a single argument it will switch to the specified goroutine. The current frame and the display list (see "help display") are remembered for each goroutine: switching back to a goroutine restores them, until the program is resumed, which resets the current frame. The first time a goroutine is selected it starts from the topmost frame and a copy of the current display list.
 Called with more arguments it will execute a command on the specified goroutine.`},
-		{aliases: []string{"breakpoints", "bp"}, group: breakCmds, cmdFn: breakpoints, helpMsg: "Print out info for active breakpoints."},
-		{aliases: []string{"print", "p"}, group: dataCmds, allowedPrefixes: onPrefix | deferredPrefix, cmdFn: printVar, helpMsg: `Evaluate an expression.
+		{aliases: []string{"freeze"}, related: []string{"thaw", "goroutines"}, group: goroutineCmds, cmdFn: freezeGoroutine, helpMsg: `Keeps a goroutine and its thread from running.
+
+	freeze [<id>]
+
+A breakpoint is set on the next instruction of the goroutine (by default the current goroutine), when the goroutine reaches it the thread running the goroutine is left stopped while the program runs, until the goroutine is thawed. Freezing works on threads: the goroutine stays on the stopped thread, which does not run other goroutines either. Goroutines on other threads keep running, and can block if they wait on the frozen goroutine or its thread. Frozen goroutines are marked in the output of the goroutines command.`},
+		{aliases: []string{"thaw"}, related: []string{"freeze"}, group: goroutineCmds, cmdFn: thawGoroutine, helpMsg: `Lets a frozen goroutine run again.
 
-	[goroutine <n>] [frame <m>] print [%format] <expression>
+	thaw [<id>]
+
+If no id is specified the current goroutine is thawed.`},
+		{aliases: []string{"breakpoints", "bp"}, related: []string{"break", "clear", "toggle", "condition", "on"}, group: breakCmds, cmdFn: breakpoints, helpMsg: `Print out info for active breakpoints.
+
+	breakpoints [-a]
+
+Breakpoints, tracepoints and watchpoints are sorted by file and line, watchpoints are listed after them. Disabled breakpoints are marked "(disabled)".
+
+The breakpoints set internally by Delve, for example on unrecovered panics, on the return addresses of a traced function or by next and step, are only listed when -a is specified, separately at the end. They are not deleted by clearall and clear -g.
+
+The total number of times each breakpoint was hit is printed after its location, the number of hits of each goroutine is printed on the "hits" line. Hit counts are kept when a breakpoint is disabled and enabled again.`},
+		{aliases: []string{"print", "p"}, related: []string{"display", "set", "whatis", "examinemem"}, group: dataCmds, allowedPrefixes: onPrefix | deferredPrefix, cmdFn: printVar, helpMsg: `Evaluate an expression.
+
+	[goroutine <n>] [frame <m>] print [-pretty|-json|-raw] [%format] <expression>
 
 See $GOPATH/src/github.com/go-delve/delve/Documentation/cli/expr.md for a description of supported expressions.
 
-The optional format argument is a format specifier, like the ones used by the fmt package. For example "print %x v" will print v as an hexadecimal number.`},
-		{aliases: []string{"whatis"}, group: dataCmds, cmdFn: whatisCommand, helpMsg: `Prints type of an expression.
+The optional format argument is a format specifier, like the ones used by the fmt package. For example "print %x v" will print v as an hexadecimal number.
+
+With -pretty every field of a struct, element of an array or slice and entry of a map is printed on its own line. With -json the value is printed as JSON: structs and maps become objects, arrays and slices become arrays and nil pointers, slices, maps and interfaces become null.
+
+Values of some types of the standard library, time.Time, math/big.Int, net.IP, bytes.Buffer and sync.Map, are printed by pretty-printers that show their logical contents instead of their internals, for example time.Time(2021-03-04 10:00:00 +0000 UTC). With -raw pretty-printers are not used. See $GOPATH/src/github.com/go-delve/delve/Documentation/cli/starlark.md for how to register more pretty-printers with register_printer.
+
+The same options can be used with 'display' and with 'on <breakpoint> print', where they control how the expression is printed when the breakpoint or tracepoint is hit.
+
+Examples:
+
+	print x
+	print s.m["key"].field
+	print %x buf[:16]
+	print %b flags
+	print -pretty cfg
+	print -json req.Header
+	frame 2 print err`},
+		{aliases: []string{"whatis"}, related: []string{"print", "types"}, group: dataCmds, cmdFn: whatisCommand, helpMsg: `Prints type of an expression.
 
 	whatis <expression>`},
-		{aliases: []string{"set"}, group: dataCmds, cmdFn: setVar, helpMsg: `Changes the value of a variable.
+		{aliases: []string{"mutex"}, related: []string{"goroutines"}, group: dataCmds, allowedPrefixes: onPrefix, cmdFn: mutexCommand, helpMsg: `Prints the state of a mutex.
+
+	mutex [-s] <expression>
+
+The expression must evaluate to a sync.Mutex, a sync.RWMutex or a pointer to one of them. The command decodes the state of the mutex and lists the goroutines waiting to acquire it. With -s the stacktraces of the goroutines are also printed.
+
+The sync package does not record which goroutine holds a mutex, the goroutines that have a deferred call unlocking the mutex are reported as its probable holders.`},
+		{aliases: []string{"chan"}, related: []string{"goroutines", "watch"}, group: dataCmds, allowedPrefixes: onPrefix, cmdFn: chanCommand, helpMsg: `Prints the elements buffered in a channel.
+
+	chan <expression>
+
+The expression must evaluate to a channel or a pointer to a channel. The command prints the elements in the buffer of the channel in the order they will be received, followed by the goroutines blocked sending to and receiving from the channel, with their stacktraces.`},
+		{aliases: []string{"search"}, related: []string{"examinemem", "vmmap"}, group: dataCmds, cmdFn: searchMemory, helpMsg: `Searches the memory of the target for a sequence of bytes.
+
+	search -s <string> [<start> <end>]
+	search -x <hex bytes> [<start> <end>]
+
+With -s searches a string, which can be quoted using the Go syntax, with -x searches a sequence of bytes written in hexadecimal, for example:
+
+	search -s "hello, world\n"
+	search -x DEADBEEF 0xc000000000 0xc000400000
+
+If a range of addresses is not specified all the readable memory mappings of the target are searched. For every occurrence, up to 100, the address and the memory mapping containing it are printed. The search can be interrupted with ctrl-C.`},
+		{aliases: []string{"vmmap"}, related: []string{"search", "libraries"}, group: dataCmds, cmdFn: vmmap, helpMsg: `Lists the memory mappings of the target.
+
+	vmmap
+
+For each memory mapping its address range, size, permissions and backing file are printed. Mappings are annotated if they belong to the executable (or to one of its shared libraries), if they contain spans of the Go heap and with the number of goroutine stacks they contain.
+
+Not supported on core files and on some operating systems.`},
+		{aliases: []string{"env"}, group: dataCmds, cmdFn: env, helpMsg: `Prints or changes the environment of the target.
+
+	env
+	env <key>
+	env <key>=<value>
+	env -u <key>
+
+Without arguments prints the environment of the target, as returned by os.Environ, with a key prints the value of that environment variable. The environment is read from the memory of the target, changes made with os.Setenv are included.
+
+The form 'env <key>=<value>' sets an environment variable and 'env -u <key>' removes it, by calling os.Setenv and os.Unsetenv on the current goroutine: the same limitations of the call command apply, see "help call".`},
+		{aliases: []string{"fds"}, related: []string{"goroutines"}, group: dataCmds, cmdFn: fds, helpMsg: `Lists the open file descriptors of the target process.
+
+	fds
+
+For each file descriptor the opened file is printed, for sockets the protocol and the local and remote addresses are printed instead. The goroutines blocked waiting for a file descriptor to become ready, for example because they are reading from a network connection, are listed after it.
+
+Only supported on linux.`},
+		{aliases: []string{"timers"}, related: []string{"goroutines"}, group: dataCmds, cmdFn: timers, helpMsg: `Lists the pending timers of the runtime.
+
+	timers
+
+Prints the timers created by time.Sleep, time.NewTimer, time.AfterFunc, time.NewTicker and similar functions that have not fired yet, sorted by the time at which they will fire. For each timer the function called when it fires and its argument are printed, for time.Timer and time.Ticker this is the channel that will receive the time, for time.AfterFunc the function that will be called.
+
+Times are read from the monotonic clock of the runtime, the time of the first timer is printed and the times of the others are printed relative to it.`},
+		{aliases: []string{"set"}, related: []string{"print", "call"}, group: dataCmds, cmdFn: setVar, helpMsg: `Changes the value of a variable.
 
 	[goroutine <n>] [frame <m>] set <variable> = <value>
 
-See $GOPATH/src/github.com/go-delve/delve/Documentation/cli/expr.md for a description of supported expressions. Only numerical variables and pointers can be changed.`},
+See $GOPATH/src/github.com/go-delve/delve/Documentation/cli/expr.md for a description of supported expressions. Numerical, boolean, pointer and string variables can be changed. Assigning a string literal allocates memory in the target through a function call, which resumes it, and is only possible in the topmost frame of a goroutine running on a thread.`},
 		{aliases: []string{"sources"}, cmdFn: sources, helpMsg: `Print list of source files.
 
 	sources [<regex>]
@@ -316,23 +507,23 @@ If regex is specified only the functions matching it will be returned.`},
 	types [<regex>]
 
 If regex is specified only the types matching it will be returned.`},
-		{aliases: []string{"args"}, allowedPrefixes: onPrefix | deferredPrefix, group: dataCmds, cmdFn: args, helpMsg: `Print function arguments.
+		{aliases: []string{"args"}, related: []string{"locals", "vars"}, allowedPrefixes: onPrefix | deferredPrefix, group: dataCmds, cmdFn: args, helpMsg: `Print function arguments.
 
 	[goroutine <n>] [frame <m>] args [-v] [<regex>]
 
 If regex is specified only function arguments with a name matching it will be returned. If -v is specified more information about each function argument will be shown.`},
-		{aliases: []string{"locals"}, allowedPrefixes: onPrefix | deferredPrefix, group: dataCmds, cmdFn: locals, helpMsg: `Print local variables.
+		{aliases: []string{"locals"}, related: []string{"args", "vars", "print"}, allowedPrefixes: onPrefix | deferredPrefix, group: dataCmds, cmdFn: locals, helpMsg: `Print local variables.
 
 	[goroutine <n>] [frame <m>] locals [-v] [<regex>]
 
 The name of variables that are shadowed in the current scope will be shown in parenthesis.
 
 If regex is specified only local variables with a name matching it will be returned. If -v is specified more information about each local variable will be shown.`},
-		{aliases: []string{"vars"}, cmdFn: vars, group: dataCmds, helpMsg: `Print package variables.
+		{aliases: []string{"vars"}, related: []string{"locals", "args"}, cmdFn: vars, group: dataCmds, helpMsg: `Print package variables.
 
-	vars [-v] [<regex>]
+	vars [-a] [-v] [<regex>]
 
-If regex is specified only package variables with a name matching it will be returned. If -v is specified more information about each package variable will be shown.`},
+If regex is specified only package variables with a name matching it will be returned, otherwise only the variables of the package the current thread is stopped in are shown. If -a is specified variables of all packages are shown. If -v is specified more information about each package variable will be shown.`},
 		{aliases: []string{"regs"}, cmdFn: regs, group: dataCmds, helpMsg: `Print contents of CPU registers.
 
 	regs [-a]
@@ -340,10 +531,14 @@ If regex is specified only package variables with a name matching it will be ret
 Argument -a shows more registers. Individual registers can also be displayed by 'print' and 'display'. See $GOPATH/src/github.com/go-delve/delve/Documentation/cli/expr.md.`},
 		{aliases: []string{"exit", "quit", "q"}, cmdFn: exitCommand, helpMsg: `Exit the debugger.
 		
-	exit [-c]
+	exit [-c|-k]
 	
-When connected to a headless instance started with the --accept-multiclient, pass -c to resume the execution of the target process before disconnecting.`},
-		{aliases: []string{"list", "ls", "l"}, cmdFn: listCommand, helpMsg: `Show source code.
+Pass -c to resume the execution of the target process before exiting, when connected to a headless instance started with --accept-multiclient the headless instance is also left running.
+
+Pass -k to kill the target process, and the headless instance if connected to one, without asking.
+
+Without arguments the behavior is selected by the --kill-on-exit and --continue-on-exit flags, if neither was used Delve asks whether to kill processes it attached to and headless instances.`},
+		{aliases: []string{"list", "ls", "l"}, related: []string{"stack", "frame", "disassemble"}, cmdFn: listCommand, helpMsg: `Show source code.
 
 	[goroutine <n>] [frame <m>] list [<linespec>]
 
@@ -355,7 +550,7 @@ For example:
 	list testvariables.go:10000
 	list main.main:30
 	list 40`},
-		{aliases: []string{"stack", "bt"}, allowedPrefixes: onPrefix, group: stackCmds, cmdFn: stackCommand, helpMsg: `Print stack trace.
+		{aliases: []string{"stack", "bt"}, related: []string{"frame", "goroutine", "deferred"}, allowedPrefixes: onPrefix, group: stackCmds, cmdFn: stackCommand, helpMsg: `Print stack trace.
 
 	[goroutine <n>] [frame <m>] stack [<depth>] [-full] [-offsets] [-defer] [-a <n>] [-adepth <depth>] [-mode <mode>]
 
@@ -369,7 +564,7 @@ For example:
 			simple	- disables automatic switch between cgo and go
 			fromg	- starts from the registers stored in the runtime.g struct
 `},
-		{aliases: []string{"frame"},
+		{aliases: []string{"frame"}, related: []string{"up", "down", "stack"},
 			group: stackCmds,
 			cmdFn: func(t *Term, ctx callContext, arg string) error {
 				return c.frameCommand(t, ctx, arg, frameSet)
@@ -381,7 +576,7 @@ For example:
 
 The first form sets frame used by subsequent commands such as "print" or "set".
 The second form runs the command on the given frame.`},
-		{aliases: []string{"up"},
+		{aliases: []string{"up"}, related: []string{"down", "frame"},
 			group: stackCmds,
 			cmdFn: func(t *Term, ctx callContext, arg string) error {
 				return c.frameCommand(t, ctx, arg, frameUp)
@@ -392,7 +587,7 @@ The second form runs the command on the given frame.`},
 	up [<m>] <command>
 
 Move the current frame up by <m>. The second form runs the command on the given frame.`},
-		{aliases: []string{"down"},
+		{aliases: []string{"down"}, related: []string{"up", "frame"},
 			group: stackCmds,
 			cmdFn: func(t *Term, ctx callContext, arg string) error {
 				return c.frameCommand(t, ctx, arg, frameDown)
@@ -403,7 +598,7 @@ Move the current frame up by <m>. The second form runs the command on the given
 	down [<m>] <command>
 
 Move the current frame down by <m>. The second form runs the command on the given frame.`},
-		{aliases: []string{"deferred"}, group: stackCmds, cmdFn: c.deferredCommand, helpMsg: `Executes command in the context of a deferred call.
+		{aliases: []string{"deferred"}, related: []string{"stack"}, group: stackCmds, cmdFn: c.deferredCommand, helpMsg: `Executes command in the context of a deferred call.
 
 	deferred <n> <command>
 
@@ -415,7 +610,7 @@ Executes the specified command (print, args, locals) in the context of the n-th
 If path ends with the .star extension it will be interpreted as a starlark script. See $GOPATH/src/github.com/go-delve/delve/Documentation/cli/starlark.md for the syntax.
 
 If path is a single '-' character an interactive starlark interpreter will start instead. Type 'exit' to exit.`},
-		{aliases: []string{"disassemble", "disass"}, cmdFn: disassCommand, helpMsg: `Disassembler.
+		{aliases: []string{"disassemble", "disass"}, related: []string{"list", "step-instruction"}, cmdFn: disassCommand, helpMsg: `Disassembler.
 
 	[goroutine <n>] [frame <m>] disassemble [-a <start> <end>] [-l <locspec>]
 
@@ -423,17 +618,20 @@ If no argument is specified the function being executed in the selected stack fr
 
 	-a <start> <end>	disassembles the specified address range
 	-l <locspec>		disassembles the specified function`},
-		{aliases: []string{"on"}, group: breakCmds, cmdFn: c.onCmd, helpMsg: `Executes a command when a breakpoint is hit.
+		{aliases: []string{"on"}, related: []string{"break", "condition"}, group: breakCmds, cmdFn: c.onCmd, helpMsg: `Executes a command when a breakpoint is hit.
 
 	on <breakpoint name or id> <command>.
 
-Supported commands: print, stack and goroutine)`},
-		{aliases: []string{"condition", "cond"}, group: breakCmds, cmdFn: conditionCmd, helpMsg: `Set breakpoint condition.
+Supported commands: print, stack, goroutine and history). A breakpoint can be referred to as $, the last breakpoint created with break or trace.`},
+		{aliases: []string{"condition", "cond"}, related: []string{"break", "on"}, group: breakCmds, cmdFn: conditionCmd, helpMsg: `Set breakpoint condition.
 
 	condition <breakpoint name or id> <boolean expression>.
 	condition -hitcount <breakpoint name or id> <operator> <argument>
+	condition -caller <breakpoint name or id> <function>
 
-Specifies that the breakpoint, tracepoint or watchpoint should break only if the boolean expression is true.
+Specifies that the breakpoint, tracepoint or watchpoint should break only if the boolean expression is true. A breakpoint can be referred to as $, the last breakpoint created with break or trace.
+
+With the -caller option the breakpoint breaks only when the function containing it was called directly by the specified function, see "help break". Passing "-" as the function removes the condition.
 
 With the -hitcount option a condition on the breakpoint hit count can be set, the following operators are supported
 
@@ -445,7 +643,14 @@ With the -hitcount option a condition on the breakpoint hit count can be set, th
 	condition -hitcount bp != n
 	condition -hitcount bp % n
 	
-The '% n' form means we should stop at the breakpoint when the hitcount is a multiple of n.`},
+The '% n' form means we should stop at the breakpoint when the hitcount is a multiple of n.
+
+Examples:
+
+	condition 1 i == 10
+	condition mybp err != nil
+	condition -hitcount mybp > 5
+	condition -caller mybp mypkg.HandleRequest`},
 		{aliases: []string{"config"}, cmdFn: configureCmd, helpMsg: `Changes configuration parameters.
 
 	config -list
@@ -468,7 +673,17 @@ Adds or removes a path substitution rule.
 	config alias <command> <alias>
 	config alias <alias>
 
-Defines <alias> as an alias to <command> or removes an alias.`},
+Defines <alias> as an alias to <command> or removes an alias.
+
+	config hook pre|post <command> <hook command>
+	config hook pre|post <command>
+
+Adds a command executed automatically before (pre) or after (post) <command>, or removes all the pre or post hooks of <command>. Post hooks are only executed if <command> succeeds, commands executed by hooks do not execute hooks themselves. For example:
+
+	config hook post next locals
+
+Hooks can also be stored by the server with the SetCommandHooks API call, they are shared by all its clients and executed after the ones in the configuration.
+`},
 
 		{aliases: []string{"edit", "ed"}, cmdFn: edit, helpMsg: `Open where you are in $DELVE_EDITOR or $EDITOR
 
@@ -476,14 +691,38 @@ Defines <alias> as an alias to <command> or removes an alias.`},
 	
 If locspec is omitted edit will open the current source file in the editor, otherwise it will open the specified location.`},
 		{aliases: []string{"libraries"}, cmdFn: libraries, helpMsg: `List loaded dynamic libraries`},
+		{aliases: []string{"process"}, cmdFn: processInfo, helpMsg: `Prints information about the target process.
+
+	process
+
+Prints the pid, executable, command line, working directory and start time of the target and the version of Go its executable was built with. The working directory and the start time of processes delve attached to are only known on linux.`},
+		{aliases: []string{"buildinfo"}, cmdFn: buildInfo, helpMsg: `Prints the build information of the executable.
+
+	buildinfo
+
+Prints the build ID of the executable, the version of Go it was built with, its main module, module dependencies and build settings, such as the VCS revision and whether the sources were modified, in the same format as 'go version -m'. Use it to check that the executable being debugged matches the sources.`},
+		{aliases: []string{"output"}, cmdFn: output, helpMsg: `Prints the output of the target captured by a headless instance.
+
+	output [-all]
+
+Prints the standard output and standard error written by the target since the last time the output command was used, or all the output retained by the headless instance if -all is specified. The output is only captured if the headless instance was started with --capture-output.`},
 
-		{aliases: []string{"examinemem", "x"}, group: dataCmds, cmdFn: examineMemoryCmd, helpMsg: `Examine memory:
+		{aliases: []string{"clients"}, related: []string{"handoff"}, cmdFn: clients, helpMsg: `List the clients connected to a headless instance.
+
+The client controlling the session (the driver) is marked with '*', read-only clients with 'r'. Only the driver can change the state of the target, for example set breakpoints or resume it, the first client doing so becomes the driver.`},
+		{aliases: []string{"handoff"}, related: []string{"clients"}, cmdFn: handoff, helpMsg: `Hands off control of the session to another client.
+
+	handoff [<client id>]
+
+If no client is specified control is released and the next client changing the state of the target becomes the driver. See also 'clients'.`},
+
+		{aliases: []string{"examinemem", "x"}, related: []string{"print", "search"}, group: dataCmds, cmdFn: examineMemoryCmd, helpMsg: `Examine memory:
 
 	examinemem [-fmt <format>] [-count|-len <count>] [-size <size>] <address>
 	examinemem [-fmt <format>] [-count|-len <count>] [-size <size>] -x <expression>
 
 Format represents the data format and the value is one of this list (default hex): bin(binary), oct(octal), dec(decimal), hex(hexadecimal), addr(address).
-Length is the number of bytes (default 1) and must be less than or equal to 1000.
+Length is the number of bytes (default 1), ranges larger than 1000 bytes are read and printed in chunks and can be interrupted with ctrl-C.
 Address is the memory location of the target to examine. Please note '-len' is deprecated by '-count and -size'.
 Expression can be an integer expression or pointer value of the memory location to examine.
 
@@ -494,20 +733,89 @@ For example:
     x -fmt hex -count 20 -size 1 -x &myVar
     x -fmt hex -count 20 -size 1 -x myPtrVar`},
 
-		{aliases: []string{"display"}, group: dataCmds, cmdFn: display, helpMsg: `Print value of an expression every time the program stops.
+		{aliases: []string{"xdump"}, related: []string{"examinemem", "print"}, group: dataCmds, cmdFn: xdumpCmd, helpMsg: `Prints the contents of a byte slice, byte array or string as a hex and ASCII dump.
+
+	xdump <expression>
+
+Each row shows sixteen bytes, preceded by their offset from the start of the value. The backing array is read in bulk instead of being loaded as individual values, so xdump is not limited by max-array-values and can be interrupted with ctrl-C.
+
+For example:
+
+    xdump buf[:n]
+    xdump &hdr`},
+
+		{aliases: []string{"display"}, related: []string{"print", "diff"}, group: dataCmds, cmdFn: display, helpMsg: `Print value of an expression every time the program stops.
 
-	display -a [%format] <expression>
+	display -a [-pretty|-json|-raw] [%format] <expression>
 	display -d <number>
 
-The '-a' option adds an expression to the list of expression printed every time the program stops. The '-d' option removes the specified expression from the list.
+The '-a' option adds an expression to the list of expression printed every time the program stops. The '-d' option removes the specified expression from the list. See 'help print' for a description of the format options.
 
 If display is called without arguments it will print the value of all expression in the list.`},
+		{aliases: []string{"diff"}, related: []string{"display", "print", "watch"}, group: dataCmds, cmdFn: diffCommand, helpMsg: `Print the changes of the value of an expression every time the program stops.
+
+	diff <expression>
+	diff -d <number>
+	diff
+
+The value of the expression is saved and, every time the program stops, the fields of structs, elements of arrays and slices and entries of maps that changed since the previous stop are printed. The '-d' option stops tracking the specified expression. Without arguments the list of tracked expressions is printed.
+
+For example:
+
+	diff cfg
+	diff queue[0]`},
 
 		{aliases: []string{"dump"}, cmdFn: dump, helpMsg: `Creates a core dump from the current process state
 
 	dump <output file>
 
 The core dump is always written in ELF, even on systems (windows, macOS) where this is not customary. For environments other than linux/amd64 threads and registers are dumped in a format that only Delve can read back.`},
+
+		{aliases: []string{"report"}, related: []string{"dump", "stack", "goroutines"}, cmdFn: report, helpMsg: `Writes a report of the current state of the program, for bug trackers.
+
+	report [-json] [-frames <n>] <output file>
+
+The report contains the reason the program stopped (including the value of unrecovered panics), the stack of the current goroutine with the local variables of its first n frames (default 3), the registers of the current thread, the stacks of all goroutines, the version of Delve and of Go used to build the program and the loaded dynamic libraries.
+The report is written in Markdown, or in JSON if -json is specified.`},
+
+		{aliases: []string{"profile"}, cmdFn: profile, helpMsg: `Collects a profile of the program using runtime/pprof.
+
+	profile cpu [<duration>] [<output file>]
+	profile heap [<output file>]
+
+The CPU profile is collected while the program runs for the specified duration (default: 30s), after which it is stopped again; it stops earlier if a breakpoint is hit or the program is interrupted. The heap profile describes the current state of the program and does not resume it.
+
+The output file defaults to cpu.pprof and heap.pprof respectively, it is written by the program, relative to its working directory, and can be inspected with 'go tool pprof'. The profile is collected by calling runtime/pprof on the current goroutine, therefore the program must import runtime/pprof.`},
+		{aliases: []string{"runtime-trace"}, cmdFn: runtimeTrace, helpMsg: `Collects an execution trace of the Go runtime.
+
+	runtime-trace start <output file>
+	runtime-trace stop
+
+Starts or stops the execution tracer of the Go runtime, the trace can be inspected with 'go tool trace'. The trace is started by calling runtime/trace.Start on the current goroutine, therefore the program must import runtime/trace, and the output file is created by the program, relative to its working directory.`},
+		{aliases: []string{"coverage"}, related: []string{"continue", "next", "step"}, cmdFn: coverageCommand, helpMsg: `Records which source lines are executed during the debug session.
+
+	coverage start [<regexp>]
+	coverage stop
+	coverage report <output file>
+	coverage
+
+'coverage start' instruments every statement of the source files matching the regular expression, by default all files except the ones of the standard library, of the module cache and of vendor directories. The lines executed by the program while it is continued or stepped are recorded, until 'coverage stop' is called. Instrumenting a line slows down the program only until the line is executed for the first time.
+
+'coverage report' writes the lines recorded so far to a Go coverage profile, which can be inspected with 'go tool cover'. Without arguments the number of executed lines of every file is printed.
+
+For example:
+
+	coverage start mypkg/.*\.go
+	continue
+	coverage report session.cov`},
+		{aliases: []string{"lasttrace"}, group: stackCmds, related: []string{"stack"}, cmdFn: lasttraceCommand, helpMsg: `Shows the calls, returns and jumps between functions that led to the current position.
+
+	lasttrace start
+	lasttrace [-t <thread id>] [<n>]
+
+'lasttrace start' starts recording the control flow of the target with the hardware branch tracer of the CPU, only the most recent part of the trace is kept. Intel Processor Trace, on linux/amd64 with the native backend, is currently the only tracer supported.
+
+Without 'start' the last n (default 20) transfers between functions executed by the current thread, or the thread specified with -t, are printed oldest first, indented by call depth. Parts of the trace that were lost are shown as gaps.`},
 	}
 
 	addrecorded := client == nil
@@ -524,12 +832,14 @@ The core dump is always written in ELF, even on systems (windows, macOS) where t
 		c.cmds = append(c.cmds,
 			command{
 				aliases: []string{"rewind", "rw"},
+				related: []string{"continue", "checkpoint", "rev"},
 				group:   runCmds,
 				cmdFn:   c.rewind,
 				helpMsg: "Run backwards until breakpoint or program termination.",
 			},
 			command{
 				aliases: []string{"check", "checkpoint"},
+				related: []string{"checkpoints", "clear-checkpoint", "restart"},
 				cmdFn:   checkpoint,
 				helpMsg: `Creates a checkpoint at the current position.
 
@@ -539,11 +849,13 @@ The "note" is arbitrary text that can be used to identify the checkpoint, if it
 			},
 			command{
 				aliases: []string{"checkpoints"},
+				related: []string{"checkpoint", "clear-checkpoint"},
 				cmdFn:   checkpoints,
 				helpMsg: "Print out info for existing checkpoints.",
 			},
 			command{
 				aliases: []string{"clear-checkpoint", "clearcheck"},
+				related: []string{"checkpoints"},
 				cmdFn:   clearCheckpoint,
 				helpMsg: `Deletes checkpoint.
 
@@ -551,6 +863,7 @@ The "note" is arbitrary text that can be used to identify the checkpoint, if it
 			},
 			command{
 				aliases: []string{"rev"},
+				related: []string{"rewind"},
 				group:   runCmds,
 				cmdFn:   c.revCmd,
 				helpMsg: `Reverses the execution of the target program for the command specified.
@@ -584,16 +897,35 @@ func (c *Commands) Find(cmdstr string, prefix cmdPrefix) cmdfunc {
 		return nullCommand
 	}
 
-	for _, v := range c.cmds {
-		if v.match(cmdstr) {
-			if prefix != noPrefix && v.allowedPrefixes&prefix == 0 {
+	if cmd := c.find(cmdstr, prefix); cmd != nil {
+		return cmd.cmdFn
+	}
+
+	return noCmdAvailable
+}
+
+func (c *Commands) find(cmdstr string, prefix cmdPrefix) *command {
+	for i := range c.cmds {
+		if c.cmds[i].match(cmdstr) {
+			if prefix != noPrefix && c.cmds[i].allowedPrefixes&prefix == 0 {
 				continue
 			}
-			return v.cmdFn
+			return &c.cmds[i]
 		}
 	}
+	return nil
+}
 
-	return noCmdAvailable
+// relatedCommands returns the names of the commands related to cmd that
+// are available.
+func (c *Commands) relatedCommands(cmd *command) []string {
+	var r []string
+	for _, name := range cmd.related {
+		if rcmd := c.find(name, noPrefix); rcmd != nil {
+			r = append(r, rcmd.aliases[0])
+		}
+	}
+	return r
 }
 
 // CallWithContext takes a command and a context that command should be executed in.
@@ -604,7 +936,52 @@ func (c *Commands) CallWithContext(cmdstr string, t *Term, ctx callContext) erro
 	if len(vals) > 1 {
 		args = strings.TrimSpace(vals[1])
 	}
-	return c.Find(cmdname, ctx.Prefix)(t, ctx, args)
+	cmd := c.find(cmdname, ctx.Prefix)
+	if cmd == nil || ctx.Prefix == onPrefix || t == nil || t.runningHook {
+		return c.Find(cmdname, ctx.Prefix)(t, ctx, args)
+	}
+	hooks := t.commandHooks(cmd.aliases[0])
+	c.runHooks(t, hooks.Pre)
+	err := cmd.cmdFn(t, ctx, args)
+	if err == nil {
+		c.runHooks(t, hooks.Post)
+	}
+	return err
+}
+
+// commandHooks returns the hooks of the command called name, the ones in
+// the configuration followed by the ones stored by the server.
+func (t *Term) commandHooks(name string) config.CommandHooks {
+	var hooks config.CommandHooks
+	if t.conf != nil {
+		hooks = t.conf.Hooks[name]
+	}
+	if t.client == nil {
+		return hooks
+	}
+	// Servers that do not support command hooks return an error.
+	if serverHooks, err := t.client.ListCommandHooks(); err == nil {
+		hooks.Pre = append(hooks.Pre[:len(hooks.Pre):len(hooks.Pre)], serverHooks[name].Pre...)
+		hooks.Post = append(hooks.Post[:len(hooks.Post):len(hooks.Post)], serverHooks[name].Post...)
+	}
+	return hooks
+}
+
+// runHooks executes the hook commands, commands executed by hooks do not
+// run their own hooks.
+func (c *Commands) runHooks(t *Term, hooks []string) {
+	if len(hooks) == 0 {
+		return
+	}
+	t.runningHook = true
+	defer func() {
+		t.runningHook = false
+	}()
+	for _, hook := range hooks {
+		if err := c.Call(hook, t); err != nil {
+			fmt.Fprintf(os.Stderr, "Hook %q failed: %s\n", hook, err)
+		}
+	}
 }
 
 // Call takes a command to execute.
@@ -643,15 +1020,21 @@ func nullCommand(t *Term, ctx callContext, args string) error {
 
 func (c *Commands) help(t *Term, ctx callContext, args string) error {
 	if args != "" {
-		for _, cmd := range c.cmds {
-			for _, alias := range cmd.aliases {
-				if alias == args {
-					fmt.Println(cmd.helpMsg)
-					return nil
-				}
+		cmd := c.find(args, noPrefix)
+		if cmd == nil {
+			return noCmdError
+		}
+		fmt.Println(cmd.helpMsg)
+		if len(cmd.aliases) > 1 {
+			fmt.Printf("\nAliases: %s\n", strings.Join(cmd.aliases[1:], " "))
+		}
+		if related := c.relatedCommands(cmd); len(related) > 0 {
+			for i := range related {
+				related[i] = fmt.Sprintf("%q", "help "+related[i])
 			}
+			fmt.Printf("\nSee also: %s\n", strings.Join(related, ", "))
 		}
-		return noCmdError
+		return nil
 	}
 
 	fmt.Println("The following commands are available:")
@@ -691,6 +1074,24 @@ func (a byThreadID) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
 func (a byThreadID) Less(i, j int) bool { return a[i].ID < a[j].ID }
 
 func threads(t *Term, ctx callContext, args string) error {
+	withStack, depth := false, 10
+	fields := strings.Fields(args)
+	for i := 0; i < len(fields); i++ {
+		switch fields[i] {
+		case "-t":
+			withStack = true
+			// optional depth argument
+			if i+1 < len(fields) {
+				n, err := strconv.Atoi(fields[i+1])
+				if err == nil {
+					depth = n
+					i++
+				}
+			}
+		default:
+			return fmt.Errorf("wrong argument: '%s'", fields[i])
+		}
+	}
 	threads, err := t.client.ListThreads()
 	if err != nil {
 		return err
@@ -699,18 +1100,47 @@ func threads(t *Term, ctx callContext, args string) error {
 	if err != nil {
 		return err
 	}
+	var stacks map[int]*api.ThreadStacktrace
+	if withStack {
+		stacktraces, err := t.client.ThreadsStacktraces(depth)
+		if err != nil {
+			return err
+		}
+		stacks = make(map[int]*api.ThreadStacktrace, len(stacktraces))
+		for i := range stacktraces {
+			stacks[stacktraces[i].ThreadID] = &stacktraces[i]
+		}
+	}
 	sort.Sort(byThreadID(threads))
 	for _, th := range threads {
 		prefix := "  "
 		if state.CurrentThread != nil && state.CurrentThread.ID == th.ID {
 			prefix = "* "
 		}
+		marker := ""
+		switch {
+		case th.Breakpoint != nil:
+			id := th.Breakpoint.Name
+			if id == "" {
+				id = strconv.Itoa(th.Breakpoint.ID)
+			}
+			marker = fmt.Sprintf(" [breakpoint %s]", id)
+		case th.Activity == api.ActivitySyscall:
+			marker = " [syscall]"
+		}
 		if th.Function != nil {
-			fmt.Printf("%sThread %d at %#v %s:%d %s\n",
+			fmt.Printf("%sThread %d at %#v %s:%d %s%s\n",
 				prefix, th.ID, th.PC, t.formatPath(th.File),
-				th.Line, th.Function.Name())
+				th.Line, th.Function.Name(), marker)
 		} else {
-			fmt.Printf("%sThread %s\n", prefix, t.formatThread(th))
+			fmt.Printf("%sThread %s%s\n", prefix, t.formatThread(th), marker)
+		}
+		if stack := stacks[th.ID]; stack != nil {
+			if stack.Err != "" {
+				fmt.Printf("\t%s\n", stack.Err)
+				continue
+			}
+			printStack(t, os.Stdout, stack.Locations, "\t", false)
 		}
 	}
 	return nil
@@ -759,9 +1189,35 @@ type printGoroutinesFlags uint8
 const (
 	printGoroutinesStack printGoroutinesFlags = 1 << iota
 	printGoroutinesLabels
+	printGoroutinesAncestors
+	printGoroutinesStackUsage
 )
 
-func printGoroutines(t *Term, indent string, gs []*api.Goroutine, fgl formatGoroutineLoc, flags printGoroutinesFlags, depth int, state *api.DebuggerState) error {
+// goroutinesStacks returns the stacktraces of all goroutines, indexed by
+// goroutine ID, if flags requests them. Unwinding all goroutines at once is
+// much faster than requesting their stacktraces one by one, if the server
+// doesn't support it nil is returned.
+func (t *Term) goroutinesStacks(flags printGoroutinesFlags, depth int) map[int][]api.Stackframe {
+	if flags&printGoroutinesStack == 0 {
+		return nil
+	}
+	stacktraces, err := t.client.GoroutinesStacktraces(depth, 0)
+	if err != nil {
+		return nil
+	}
+	stacks := make(map[int][]api.Stackframe, len(stacktraces))
+	for i := range stacktraces {
+		if stacktraces[i].Err == "" {
+			stacks[stacktraces[i].GoroutineID] = stacktraces[i].Locations
+		}
+	}
+	return stacks
+}
+
+// printGoroutines prints the goroutines in gs. If stacks is not nil it
+// contains the stacktraces of the goroutines, indexed by goroutine ID,
+// goroutines missing from it have their stacktrace requested separately.
+func printGoroutines(t *Term, indent string, gs []*api.Goroutine, fgl formatGoroutineLoc, flags printGoroutinesFlags, depth, ancestors int, state *api.DebuggerState, stacks map[int][]api.Stackframe) error {
 	for _, g := range gs {
 		prefix := indent + "  "
 		if state.SelectedGoroutine != nil && g.ID == state.SelectedGoroutine.ID {
@@ -771,17 +1227,51 @@ func printGoroutines(t *Term, indent string, gs []*api.Goroutine, fgl formatGoro
 		if flags&printGoroutinesLabels != 0 {
 			writeGoroutineLabels(os.Stdout, g, indent+"\t")
 		}
+		if flags&printGoroutinesStackUsage != 0 && g.Stack.Hi > g.Stack.Lo {
+			fmt.Printf("%s\tStack: %#x-%#x, %d of %d bytes used, grown %d times\n", indent, g.Stack.Lo, g.Stack.Hi, g.Stack.Used, g.Stack.Hi-g.Stack.Lo, g.Stack.Growths)
+		}
 		if flags&printGoroutinesStack != 0 {
-			stack, err := t.client.Stacktrace(g.ID, depth, 0, nil)
+			stack, ok := stacks[g.ID]
+			if !ok {
+				var err error
+				stack, err = t.client.Stacktrace(g.ID, depth, 0, nil)
+				if err != nil {
+					return err
+				}
+			}
+			printStack(t, os.Stdout, stack, indent+"\t", false)
+		}
+		if flags&printGoroutinesAncestors != 0 {
+			ancestors, err := t.client.Ancestors(g.ID, ancestors, depth)
 			if err != nil {
 				return err
 			}
-			printStack(t, os.Stdout, stack, indent+"\t", false)
+			printAncestors(t, indent+"\t", ancestors, flags&printGoroutinesStack != 0)
 		}
 	}
 	return nil
 }
 
+// printAncestors prints the chain of goroutines that created a goroutine,
+// starting with its direct creator. If withStack is set the stacktrace
+// saved by the runtime for each ancestor is also printed.
+func printAncestors(t *Term, indent string, ancestors []api.Ancestor, withStack bool) {
+	for _, ancestor := range ancestors {
+		fmt.Printf("%sCreated by Goroutine %d", indent, ancestor.ID)
+		if ancestor.GoStatementLoc.PC != 0 {
+			fmt.Printf(", itself created at %s", t.formatLocation(ancestor.GoStatementLoc))
+		}
+		fmt.Printf(":\n")
+		if ancestor.Unreadable != "" {
+			fmt.Printf("%s\t%s\n", indent, ancestor.Unreadable)
+			continue
+		}
+		if withStack {
+			printStack(t, os.Stdout, ancestor.Stack, indent+"\t", false)
+		}
+	}
+}
+
 const (
 	maxGroupMembers    = 5
 	maxGoroutineGroups = 50
@@ -794,7 +1284,9 @@ func goroutines(t *Term, ctx callContext, argstr string) error {
 	var fgl = fglUserCurrent
 	var flags printGoroutinesFlags
 	var depth = 10
+	var ancestors = 10
 	var batchSize = goroutineBatchSize
+	var diff, fglSet, all bool
 
 	group.MaxGroupMembers = maxGroupMembers
 	group.MaxGroups = maxGoroutineGroups
@@ -803,15 +1295,31 @@ func goroutines(t *Term, ctx callContext, argstr string) error {
 		arg := args[i]
 		switch arg {
 		case "-u":
-			fgl = fglUserCurrent
+			fgl, fglSet = fglUserCurrent, true
 		case "-r":
-			fgl = fglRuntimeCurrent
+			fgl, fglSet = fglRuntimeCurrent, true
 		case "-g":
-			fgl = fglGo
+			fgl, fglSet = fglGo, true
 		case "-s":
-			fgl = fglStart
+			fgl, fglSet = fglStart, true
+		case "-diff":
+			diff = true
+		case "-all":
+			all = true
 		case "-l":
 			flags |= printGoroutinesLabels
+		case "-stack":
+			flags |= printGoroutinesStackUsage
+		case "-a":
+			flags |= printGoroutinesAncestors
+			// optional number of ancestors
+			if i+1 < len(args) && len(args[i+1]) > 0 {
+				n, err := strconv.Atoi(args[i+1])
+				if err == nil {
+					ancestors = n
+					i++
+				}
+			}
 		case "-t":
 			flags |= printGoroutinesStack
 			// optional depth argument
@@ -865,6 +1373,18 @@ func goroutines(t *Term, ctx callContext, argstr string) error {
 	if err != nil {
 		return err
 	}
+	if diff {
+		if len(filters) > 0 || group.GroupBy != api.GoroutineFieldNone {
+			return errors.New("-diff can not be used with -with, -without or -group")
+		}
+		if !fglSet {
+			fgl = fglStart
+		}
+		return t.printGoroutinesDiff(fgl, flags, depth, ancestors, state)
+	}
+	if !all {
+		filters = append(filters, api.ListGoroutinesFilter{Kind: api.GoroutineRuntime, Negated: true})
+	}
 	var (
 		start         = 0
 		gslen         = 0
@@ -873,6 +1393,7 @@ func goroutines(t *Term, ctx callContext, argstr string) error {
 		tooManyGroups bool
 	)
 	t.longCommandStart()
+	stacks := t.goroutinesStacks(flags, depth)
 	for start >= 0 {
 		if t.longCommandCanceled() {
 			fmt.Printf("interrupted\n")
@@ -885,7 +1406,7 @@ func goroutines(t *Term, ctx callContext, argstr string) error {
 		if len(groups) > 0 {
 			for i := range groups {
 				fmt.Printf("%s\n", groups[i].Name)
-				err = printGoroutines(t, "\t", gs[groups[i].Offset:][:groups[i].Count], fgl, flags, depth, state)
+				err = printGoroutines(t, "\t", gs[groups[i].Offset:][:groups[i].Count], fgl, flags, depth, ancestors, state, stacks)
 				if err != nil {
 					return err
 				}
@@ -899,7 +1420,7 @@ func goroutines(t *Term, ctx callContext, argstr string) error {
 			}
 		} else {
 			sort.Sort(byGoroutineID(gs))
-			err = printGoroutines(t, "", gs, fgl, flags, depth, state)
+			err = printGoroutines(t, "", gs, fgl, flags, depth, ancestors, state, stacks)
 			if err != nil {
 				return err
 			}
@@ -932,6 +1453,8 @@ func readGoroutinesFilterKind(args []string, i int) (api.GoroutineField, error)
 		return api.GoroutineRunning, nil
 	case "user":
 		return api.GoroutineUser, nil
+	case "runtime":
+		return api.GoroutineRuntime, nil
 	default:
 		return api.GoroutineFieldNone, fmt.Errorf("unrecognized argument to %s %s", args[i-1], args[i])
 	}
@@ -946,7 +1469,7 @@ func readGoroutinesFilter(args []string, pi *int) (*api.ListGoroutinesFilter, er
 	}
 	*pi++
 	switch r.Kind {
-	case api.GoroutineRunning, api.GoroutineUser:
+	case api.GoroutineRunning, api.GoroutineUser, api.GoroutineRuntime:
 		return r, nil
 	}
 	if *pi+1 >= len(args) {
@@ -1001,8 +1524,11 @@ func (c *Commands) goroutine(t *Term, ctx callContext, argstr string) error {
 		if err != nil {
 			return err
 		}
-		c.frame = 0
+		t.switchGoroutineContext(selectedGID(oldState), gid)
 		fmt.Printf("Switched from %d to %d (thread %d)\n", selectedGID(oldState), gid, newState.CurrentThread.ID)
+		if c.frame != 0 {
+			fmt.Printf("Frame %d\n", c.frame)
+		}
 		return nil
 	}
 
@@ -1156,6 +1682,9 @@ func (t *Term) formatGoroutine(g *api.Goroutine, fgl formatGoroutineLoc) string
 		}
 		fmt.Fprintf(buf, "]")
 	}
+	if g.Frozen {
+		fmt.Fprintf(buf, " (frozen)")
+	}
 
 	return buf.String()
 }
@@ -1306,6 +1835,7 @@ func restartIntl(t *Term, rerecord bool, restartPos string, resetArgs bool, newA
 	if err != nil {
 		return err
 	}
+	t.goroutineContexts = nil
 	for i := range discarded {
 		fmt.Printf("Discarded %s at %s: %v\n", formatBreakpointName(discarded[i].Breakpoint, false), t.formatBreakpointLocation(discarded[i].Breakpoint), discarded[i].Reason)
 	}
@@ -1397,6 +1927,12 @@ func (c *Commands) rebuild(t *Term, ctx callContext, args string) error {
 }
 
 func (c *Commands) cont(t *Term, ctx callContext, args string) error {
+	if strings.HasPrefix(args, "-max ") || strings.HasPrefix(args, "-until ") {
+		if ctx.Prefix == revPrefix {
+			return errors.New("-until can not be used with rev")
+		}
+		return c.contUntil(t, args)
+	}
 	if args != "" {
 		tmp, err := setBreakpoint(t, ctx, false, args)
 		if err != nil {
@@ -1428,6 +1964,69 @@ func (c *Commands) cont(t *Term, ctx callContext, args string) error {
 	return nil
 }
 
+// contUntil implements 'continue -until'.
+func (c *Commands) contUntil(t *Term, args string) error {
+	opts := &api.ContinueOptions{}
+	if strings.HasPrefix(args, "-max ") {
+		v := split2PartsBySpace(strings.TrimSpace(args[len("-max "):]))
+		n, err := strconv.Atoi(v[0])
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid maximum number of iterations %q", v[0])
+		}
+		opts.MaxIterations = n
+		if len(v) < 2 {
+			v = append(v, "")
+		}
+		args = v[1]
+	}
+	if !strings.HasPrefix(args, "-until ") {
+		return errors.New("-until expected")
+	}
+	opts.Until = strings.TrimSpace(args[len("-until "):])
+	if opts.Until == "" {
+		return errors.New("not enough arguments")
+	}
+
+	defer t.onStop()
+	c.frame = 0
+
+	type result struct {
+		state *api.DebuggerState
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		state, err := t.client.Command(api.Continue, opts)
+		done <- result{state, err}
+	}()
+
+	// Progress is reported while the loop runs on the server.
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case r := <-done:
+			if r.state != nil && r.state.ContinueIterations > 0 {
+				fmt.Printf("\rContinued %d times\n", r.state.ContinueIterations)
+			}
+			if r.err != nil {
+				printcontextNoState(t)
+				return r.err
+			}
+			if r.state.Exited {
+				return r.state.ExitError(t.client.ProcessPid())
+			}
+			printcontext(t, r.state)
+			printfile(t, r.state.CurrentThread.File, r.state.CurrentThread.Line, true)
+			return nil
+		case <-ticker.C:
+			if state, err := t.client.GetStateNonBlocking(); err == nil && state.Running {
+				fmt.Printf("\rContinued %d times...", state.ContinueIterations)
+			}
+		}
+	}
+}
+
 func continueUntilCompleteNext(t *Term, state *api.DebuggerState, op string, shouldPrintFile bool) error {
 	defer t.onStop()
 	if !state.NextInProgress {
@@ -1437,6 +2036,16 @@ func continueUntilCompleteNext(t *Term, state *api.DebuggerState, op string, sho
 		return nil
 	}
 	for {
+		if t.longCommandCanceled() {
+			// A manual stop was requested, the remainder of the operation
+			// must not be executed.
+			fmt.Printf("\t%s interrupted\n", op)
+			if err := t.client.CancelNext(); err != nil {
+				return err
+			}
+			printfile(t, state.CurrentThread.File, state.CurrentThread.Line, true)
+			return nil
+		}
 		fmt.Printf("\tbreakpoint hit during %s, continuing...\n", op)
 		stateChan := t.client.DirectionCongruentContinue()
 		var state *api.DebuggerState
@@ -1466,7 +2075,7 @@ func scopePrefixSwitch(t *Term, ctx callContext) error {
 
 func exitedToError(state *api.DebuggerState, err error) (*api.DebuggerState, error) {
 	if err == nil && state.Exited {
-		return nil, fmt.Errorf("Process %d has exited with status %d", state.Pid, state.ExitStatus)
+		return nil, state.ExitError(state.Pid)
 	}
 	return state, err
 }
@@ -1476,17 +2085,11 @@ func (c *Commands) step(t *Term, ctx callContext, args string) error {
 		return err
 	}
 	c.frame = 0
-	stepfn := t.client.Step
-	if ctx.Prefix == revPrefix {
-		stepfn = t.client.ReverseStep
-	}
-	state, err := exitedToError(stepfn())
+	count, err := parseStepCount(args, "step")
 	if err != nil {
-		printcontextNoState(t)
 		return err
 	}
-	printcontext(t, state)
-	return continueUntilCompleteNext(t, state, "step", true)
+	return repeatedStep(t, "step", api.Step, &api.StepOptions{ReverseDirection: ctx.Prefix == revPrefix, Count: count})
 }
 
 var notOnFrameZeroErr = errors.New("not on topmost frame")
@@ -1534,35 +2137,11 @@ func (c *Commands) next(t *Term, ctx callContext, args string) error {
 	if c.frame != 0 {
 		return notOnFrameZeroErr
 	}
-
-	nextfn := t.client.Next
-	if ctx.Prefix == revPrefix {
-		nextfn = t.client.ReverseNext
-	}
-
-	var count int64
-	var err error
-	if count, err = parseOptionalCount(args); err != nil {
+	count, err := parseStepCount(args, "next")
+	if err != nil {
 		return err
-	} else if count <= 0 {
-		return errors.New("Invalid next count")
 	}
-	for ; count > 0; count-- {
-		state, err := exitedToError(nextfn())
-		if err != nil {
-			printcontextNoState(t)
-			return err
-		}
-		// If we're about the exit the loop, print the context.
-		finishedNext := count == 1
-		if finishedNext {
-			printcontext(t, state)
-		}
-		if err := continueUntilCompleteNext(t, state, "next", finishedNext); err != nil {
-			return err
-		}
-	}
-	return nil
+	return repeatedStep(t, "next", api.Next, &api.NextOptions{ReverseDirection: ctx.Prefix == revPrefix, Count: count})
 }
 
 func (c *Commands) stepout(t *Term, ctx callContext, args string) error {
@@ -1572,19 +2151,37 @@ func (c *Commands) stepout(t *Term, ctx callContext, args string) error {
 	if c.frame != 0 {
 		return notOnFrameZeroErr
 	}
+	count, err := parseStepCount(args, "stepout")
+	if err != nil {
+		return err
+	}
+	return repeatedStep(t, "stepout", api.StepOut, &api.StepOutOptions{ReverseDirection: ctx.Prefix == revPrefix, Count: count})
+}
 
-	stepoutfn := t.client.StepOut
-	if ctx.Prefix == revPrefix {
-		stepoutfn = t.client.ReverseStepOut
+// parseStepCount parses the optional repeat count of next, step and
+// stepout.
+func parseStepCount(args, cmdname string) (int, error) {
+	count, err := parseOptionalCount(args)
+	if err != nil {
+		return 0, err
+	}
+	if count <= 0 {
+		return 0, fmt.Errorf("Invalid %s count", cmdname)
 	}
+	return int(count), nil
+}
 
-	state, err := exitedToError(stepoutfn())
+// repeatedStep executes a stepping command, the repetitions specified by
+// opts are executed by the debugger without returning to the client.
+func repeatedStep(t *Term, cmdname, name string, opts api.CommandOptions) error {
+	t.longCommandStart()
+	state, err := exitedToError(t.client.Command(name, opts))
 	if err != nil {
 		printcontextNoState(t)
 		return err
 	}
 	printcontext(t, state)
-	return continueUntilCompleteNext(t, state, "stepout", true)
+	return continueUntilCompleteNext(t, state, cmdname, true)
 }
 
 func (c *Commands) call(t *Term, ctx callContext, args string) error {
@@ -1597,6 +2194,7 @@ func (c *Commands) call(t *Term, ctx callContext, args string) error {
 		unsafe = true
 		args = args[len(unsafePrefix):]
 	}
+	t.longCommandStart()
 	state, err := exitedToError(t.client.Call(ctx.Scope.GoroutineID, args, unsafe))
 	c.frame = 0
 	if err != nil {
@@ -1611,6 +2209,14 @@ func clear(t *Term, ctx callContext, args string) error {
 	if len(args) == 0 {
 		return fmt.Errorf("not enough arguments")
 	}
+	if strings.HasPrefix(args, "-g ") {
+		group := strings.TrimSpace(args[len("-g "):])
+		bps, err := t.client.ClearBreakpointGroup(group)
+		for _, bp := range bps {
+			fmt.Printf("%s cleared at %s\n", formatBreakpointName(bp, true), t.formatBreakpointLocation(bp))
+		}
+		return err
+	}
 	id, err := strconv.Atoi(args)
 	var bp *api.Breakpoint
 	if err == nil {
@@ -1626,50 +2232,39 @@ func clear(t *Term, ctx callContext, args string) error {
 }
 
 func clearAll(t *Term, ctx callContext, args string) error {
-	breakPoints, err := t.client.ListBreakpoints()
-	if err != nil {
-		return err
-	}
-
-	var locPCs map[uint64]struct{}
-	if args != "" {
+	var filter api.BreakpointFilter
+	switch {
+	case strings.HasPrefix(args, "-file "):
+		filter.File = strings.TrimSpace(args[len("-file "):])
+	case strings.HasPrefix(args, "-func "):
+		filter.FunctionName = strings.TrimSpace(args[len("-func "):])
+	case args == "-file" || args == "-func":
+		return fmt.Errorf("not enough arguments")
+	case args != "":
 		locs, err := t.client.FindLocation(api.EvalScope{GoroutineID: -1, Frame: 0}, args, true, t.substitutePathRules())
 		if err != nil {
 			return err
 		}
-		locPCs = make(map[uint64]struct{})
 		for _, loc := range locs {
-			for _, pc := range loc.PCs {
-				locPCs[pc] = struct{}{}
-			}
-			locPCs[loc.PC] = struct{}{}
+			filter.Addrs = append(filter.Addrs, loc.PCs...)
+			filter.Addrs = append(filter.Addrs, loc.PC)
 		}
 	}
 
-	for _, bp := range breakPoints {
-		if locPCs != nil {
-			if _, ok := locPCs[bp.Addr]; !ok {
-				continue
-			}
-		}
-
-		if bp.ID < 0 {
-			continue
-		}
-
-		_, err := t.client.ClearBreakpoint(bp.ID)
-		if err != nil {
-			fmt.Printf("Couldn't delete %s at %s: %s\n", formatBreakpointName(bp, false), t.formatBreakpointLocation(bp), err)
-		}
+	bps, err := t.client.ClearBreakpoints(filter)
+	for _, bp := range bps {
 		fmt.Printf("%s cleared at %s\n", formatBreakpointName(bp, true), t.formatBreakpointLocation(bp))
 	}
-	return nil
+	return err
 }
 
 func toggle(t *Term, ctx callContext, args string) error {
 	if args == "" {
 		return fmt.Errorf("not enough arguments")
 	}
+	if strings.HasPrefix(args, "-g ") {
+		return toggleGroup(t, strings.TrimSpace(args[len("-g "):]))
+	}
 	id, err := strconv.Atoi(args)
 	var bp *api.Breakpoint
 	if err == nil {
@@ -1684,6 +2279,104 @@ func toggle(t *Term, ctx callContext, args string) error {
 	return nil
 }
 
+func toggleGroup(t *Term, args string) error {
+	v := strings.Fields(args)
+	if len(v) == 0 || len(v) > 2 {
+		return errors.New("wrong number of arguments")
+	}
+	group := v[0]
+	var disabled bool
+	if len(v) == 2 {
+		switch v[1] {
+		case "on":
+			disabled = false
+		case "off":
+			disabled = true
+		default:
+			return fmt.Errorf("unknown argument %q", v[1])
+		}
+	} else {
+		bps, err := t.client.ListBreakpoints()
+		if err != nil {
+			return err
+		}
+		found := false
+		for _, bp := range bps {
+			if breakpointInGroup(bp, group) {
+				found = true
+				disabled = disabled || !bp.Disabled
+			}
+		}
+		if !found {
+			return fmt.Errorf("no breakpoints in group %s", group)
+		}
+	}
+	bps, err := t.client.AmendBreakpointGroup(group, disabled)
+	for _, bp := range bps {
+		fmt.Printf("%s toggled at %s\n", formatBreakpointName(bp, true), t.formatBreakpointLocation(bp))
+	}
+	return err
+}
+
+func breakpointInGroup(bp *api.Breakpoint, group string) bool {
+	for _, g := range bp.Groups {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
+
+// parseBreakpointGroups parses the -g options at the start of args.
+func parseBreakpointGroups(args string) (groups []string, rest string, err error) {
+	for strings.HasPrefix(args, "-g ") {
+		v := split2PartsBySpace(strings.TrimSpace(args[len("-g "):]))
+		if err := api.ValidBreakpointGroup(v[0]); err != nil {
+			return nil, "", err
+		}
+		groups = append(groups, v[0])
+		args = ""
+		if len(v) > 1 {
+			args = v[1]
+		}
+	}
+	return groups, args, nil
+}
+
+// breakpointCondRx matches the 'if' keyword introducing the condition of
+// a breakpoint.
+var breakpointCondRx = regexp.MustCompile(`\sif(\s|$)`)
+
+// parseBreakpointCondition removes a trailing 'if <condition>' clause from
+// args and returns the condition.
+func parseBreakpointCondition(args string) (cond string, rest string, err error) {
+	loc := breakpointCondRx.FindStringIndex(args)
+	if loc == nil {
+		return "", args, nil
+	}
+	cond = strings.TrimSpace(args[loc[1]:])
+	if cond == "" {
+		return "", "", errors.New("if must be followed by a condition")
+	}
+	return cond, strings.TrimSpace(args[:loc[0]]), nil
+}
+
+// parseBreakpointCaller removes a trailing '-caller <function>' option
+// from args and returns the function.
+func parseBreakpointCaller(args string) (caller string, rest string, err error) {
+	fields := strings.Fields(args)
+	for i, field := range fields {
+		if field != "-caller" {
+			continue
+		}
+		if i != len(fields)-2 {
+			return "", "", errors.New("-caller must be followed by a function name and be the last option")
+		}
+		return fields[i+1], strings.TrimSpace(args[:strings.LastIndex(args, "-caller")]), nil
+	}
+	return "", args, nil
+}
+
 // byID sorts breakpoints by ID.
 type byID []*api.Breakpoint
 
@@ -1691,56 +2384,171 @@ func (a byID) Len() int           { return len(a) }
 func (a byID) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
 func (a byID) Less(i, j int) bool { return a[i].ID < a[j].ID }
 
+// byLocation sorts breakpoints by file and line, breakpoints without a
+// file, like watchpoints, are sorted by ID after the others.
+type byLocation []*api.Breakpoint
+
+func (a byLocation) Len() int      { return len(a) }
+func (a byLocation) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a byLocation) Less(i, j int) bool {
+	if (a[i].File == "") != (a[j].File == "") {
+		return a[i].File != ""
+	}
+	if a[i].File != a[j].File {
+		return a[i].File < a[j].File
+	}
+	if a[i].Line != a[j].Line {
+		return a[i].Line < a[j].Line
+	}
+	return a[i].ID < a[j].ID
+}
+
+// isInternalBreakpoint returns true if bp was set by Delve rather than
+// directly by the user.
+func isInternalBreakpoint(bp *api.Breakpoint) bool {
+	return bp.ID <= 0 || bp.TraceReturn
+}
+
 func breakpoints(t *Term, ctx callContext, args string) error {
-	breakPoints, err := t.client.ListBreakpoints()
+	all := false
+	switch args {
+	case "":
+		// nothing to do
+	case "-a":
+		all = true
+	default:
+		return fmt.Errorf("wrong argument: %q", args)
+	}
+	listBreakpoints := t.client.ListBreakpoints
+	if all {
+		listBreakpoints = t.client.ListAllBreakpoints
+	}
+	breakPoints, err := listBreakpoints()
 	if err != nil {
 		return err
 	}
-	sort.Sort(byID(breakPoints))
+	var user, internal []*api.Breakpoint
 	for _, bp := range breakPoints {
-		fmt.Printf("%s at %v (%d)\n", formatBreakpointName(bp, true), t.formatBreakpointLocation(bp), bp.TotalHitCount)
-
-		var attrs []string
-		if bp.Cond != "" {
-			attrs = append(attrs, fmt.Sprintf("\tcond %s", bp.Cond))
-		}
-		if bp.HitCond != "" {
-			attrs = append(attrs, fmt.Sprintf("\tcond -hitcount %s", bp.HitCond))
+		if isInternalBreakpoint(bp) {
+			if all {
+				internal = append(internal, bp)
+			}
+		} else {
+			user = append(user, bp)
 		}
-		if bp.Stacktrace > 0 {
-			attrs = append(attrs, fmt.Sprintf("\tstack %d", bp.Stacktrace))
+	}
+	sort.Sort(byLocation(user))
+	// Stable so that the breakpoints set by next and step, that all have ID
+	// 0, stay sorted by address.
+	sort.Stable(byID(internal))
+	for _, bp := range user {
+		t.printBreakpointInfo(bp)
+	}
+	if len(internal) > 0 {
+		if len(user) > 0 {
+			fmt.Println()
 		}
-		if bp.Goroutine {
-			attrs = append(attrs, "\tgoroutine")
+		fmt.Println("Internal breakpoints:")
+		for _, bp := range internal {
+			t.printBreakpointInfo(bp)
 		}
-		if bp.LoadArgs != nil {
-			if *(bp.LoadArgs) == longLoadConfig {
-				attrs = append(attrs, "\targs -v")
-			} else {
-				attrs = append(attrs, "\targs")
-			}
+	}
+	return nil
+}
+
+// printBreakpointInfo prints the location and the attributes of bp.
+func (t *Term) printBreakpointInfo(bp *api.Breakpoint) {
+	fmt.Printf("%s at %v (%d)\n", formatBreakpointName(bp, true), t.formatBreakpointLocation(bp), bp.TotalHitCount)
+
+	var attrs []string
+	if bp.Cond != "" {
+		attrs = append(attrs, fmt.Sprintf("\tcond %s", bp.Cond))
+	}
+	if bp.HitCond != "" {
+		attrs = append(attrs, fmt.Sprintf("\tcond -hitcount %s", bp.HitCond))
+	}
+	if bp.Caller != "" {
+		attrs = append(attrs, fmt.Sprintf("\tcond -caller %s", bp.Caller))
+	}
+	if bp.Stacktrace > 0 {
+		attrs = append(attrs, fmt.Sprintf("\tstack %d", bp.Stacktrace))
+	}
+	if bp.Goroutine {
+		attrs = append(attrs, "\tgoroutine")
+	}
+	if bp.WatchHistory > 0 && bp.WatchExpr == "" {
+		attrs = append(attrs, fmt.Sprintf("\thistory %d", bp.WatchHistory))
+	}
+	if len(bp.Groups) > 0 {
+		attrs = append(attrs, fmt.Sprintf("\tgroups %s", strings.Join(bp.Groups, ", ")))
+	}
+	if bp.LoadArgs != nil {
+		if *(bp.LoadArgs) == longLoadConfig {
+			attrs = append(attrs, "\targs -v")
+		} else {
+			attrs = append(attrs, "\targs")
 		}
-		if bp.LoadLocals != nil {
-			if *(bp.LoadLocals) == longLoadConfig {
-				attrs = append(attrs, "\tlocals -v")
-			} else {
-				attrs = append(attrs, "\tlocals")
-			}
+	}
+	if bp.LoadLocals != nil {
+		if *(bp.LoadLocals) == longLoadConfig {
+			attrs = append(attrs, "\tlocals -v")
+		} else {
+			attrs = append(attrs, "\tlocals")
 		}
-		for i := range bp.Variables {
+	}
+	for i := range bp.Variables {
+		if format := t.breakpointVarFormat(bp.ID, bp.Variables[i]).String(); format != "" {
+			attrs = append(attrs, fmt.Sprintf("\tprint %s %s", format, bp.Variables[i]))
+		} else {
 			attrs = append(attrs, fmt.Sprintf("\tprint %s", bp.Variables[i]))
 		}
-		if len(attrs) > 0 {
-			fmt.Printf("%s\n", strings.Join(attrs, "\n"))
+	}
+	if hits := formatHitCounts(bp.HitCount); hits != "" {
+		attrs = append(attrs, "\thits "+hits)
+	}
+	for _, descr := range bp.VerboseDescr {
+		attrs = append(attrs, "\t"+descr)
+	}
+	if len(attrs) > 0 {
+		fmt.Printf("%s\n", strings.Join(attrs, "\n"))
+	}
+}
+
+// formatHitCounts formats the per-goroutine hit counts of a breakpoint,
+// sorted by goroutine ID.
+func formatHitCounts(hitCount map[string]uint64) string {
+	gids := make([]int, 0, len(hitCount))
+	for s := range hitCount {
+		gid, err := strconv.Atoi(s)
+		if err != nil {
+			continue
 		}
+		gids = append(gids, gid)
 	}
-	return nil
+	sort.Ints(gids)
+	r := make([]string, len(gids))
+	for i, gid := range gids {
+		r[i] = fmt.Sprintf("goroutine(%d):%d", gid, hitCount[strconv.Itoa(gid)])
+	}
+	return strings.Join(r, " ")
 }
 
 func setBreakpoint(t *Term, ctx callContext, tracepoint bool, argstr string) ([]*api.Breakpoint, error) {
+	groups, argstr, err := parseBreakpointGroups(argstr)
+	if err != nil {
+		return nil, err
+	}
+	cond, argstr, err := parseBreakpointCondition(argstr)
+	if err != nil {
+		return nil, err
+	}
+	caller, argstr, err := parseBreakpointCaller(argstr)
+	if err != nil {
+		return nil, err
+	}
 	args := split2PartsBySpace(argstr)
 
-	requestedBp := &api.Breakpoint{}
+	requestedBp := &api.Breakpoint{Groups: groups, Caller: caller, Cond: cond}
 	spec := ""
 	switch len(args) {
 	case 1:
@@ -1774,6 +2582,7 @@ func setBreakpoint(t *Term, ctx callContext, tracepoint bool, argstr string) ([]
 	for _, loc := range locs {
 		requestedBp.Addr = loc.PC
 		requestedBp.Addrs = loc.PCs
+		requestedBp.Column = loc.Column
 		if tracepoint {
 			requestedBp.LoadArgs = &ShortLoadConfig
 		}
@@ -1783,6 +2592,7 @@ func setBreakpoint(t *Term, ctx callContext, tracepoint bool, argstr string) ([]
 			return nil, err
 		}
 		created = append(created, bp)
+		t.lastBreakpointID = bp.ID
 
 		fmt.Printf("%s set at %s\n", formatBreakpointName(bp, true), t.formatBreakpointLocation(bp))
 	}
@@ -1853,10 +2663,70 @@ func edit(t *Term, ctx callContext, args string) error {
 	return cmd.Run()
 }
 
+func breakOrigin(t *Term, ctx callContext, argstr string) error {
+	start := false
+	if argstr == "-start" || strings.HasPrefix(argstr, "-start ") {
+		start = true
+		argstr = strings.TrimSpace(argstr[len("-start"):])
+	}
+	if strings.Contains(argstr, " ") {
+		return errors.New("wrong number of arguments: break-origin [-start] [name]")
+	}
+	g, err := findGoroutine(t, ctx.Scope.GoroutineID)
+	if err != nil {
+		return err
+	}
+	var spec string
+	if start {
+		if g.StartLoc.Function == nil {
+			return fmt.Errorf("could not find start function of goroutine %d (startpc %#x)", g.ID, g.StartPC)
+		}
+		spec = g.StartLoc.Function.Name()
+	} else {
+		if g.GoStatementLoc.File == "" {
+			return fmt.Errorf("could not find go statement of goroutine %d (gopc %#x)", g.ID, g.GoPC)
+		}
+		spec = fmt.Sprintf("%s:%d", g.GoStatementLoc.File, g.GoStatementLoc.Line)
+	}
+	if argstr != "" {
+		spec = argstr + " " + spec
+	}
+	_, err = setBreakpoint(t, ctx, false, spec)
+	return err
+}
+
+// findGoroutine returns the goroutine with the specified ID, or the selected
+// goroutine if gid is negative.
+func findGoroutine(t *Term, gid int) (*api.Goroutine, error) {
+	if gid < 0 {
+		state, err := t.client.GetState()
+		if err != nil {
+			return nil, err
+		}
+		if state.SelectedGoroutine == nil {
+			return nil, errors.New("no selected goroutine")
+		}
+		return state.SelectedGoroutine, nil
+	}
+	for start := 0; start >= 0; {
+		gs, next, err := t.client.ListGoroutines(start, goroutineBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, g := range gs {
+			if g.ID == gid {
+				return g, nil
+			}
+		}
+		start = next
+	}
+	return nil, fmt.Errorf("unknown goroutine %d", gid)
+}
+
 func watchpoint(t *Term, ctx callContext, args string) error {
 	v := strings.SplitN(args, " ", 2)
 	if len(v) != 2 {
-		return errors.New("wrong number of arguments: watch [-r|-w|-rw] <expr>")
+		return errors.New("wrong number of arguments: watch [-r|-w|-rw|-history|-chan] <expr>")
 	}
 	var wtype api.WatchType
 	switch v[0] {
@@ -1866,10 +2736,23 @@ func watchpoint(t *Term, ctx callContext, args string) error {
 		wtype = api.WatchWrite
 	case "-rw":
 		wtype = api.WatchRead | api.WatchWrite
+	case "-history":
+		wtype = api.WatchWrite
+	case "-chan":
+		// not a hardware watchpoint
 	default:
 		return fmt.Errorf("wrong argument %q to watch", v[0])
 	}
-	bp, err := t.client.CreateWatchpoint(ctx.Scope, v[1], wtype)
+	var bp *api.Breakpoint
+	var err error
+	switch v[0] {
+	case "-history":
+		bp, err = t.client.CreateWatchpointHistory(ctx.Scope, v[1], defaultWatchHistory)
+	case "-chan":
+		bp, err = t.client.CreateChanWatchpoint(ctx.Scope, v[1])
+	default:
+		bp, err = t.client.CreateWatchpoint(ctx.Scope, v[1], wtype)
+	}
 	if err != nil {
 		return err
 	}
@@ -1877,6 +2760,88 @@ func watchpoint(t *Term, ctx callContext, args string) error {
 	return nil
 }
 
+// defaultWatchHistory is the number of writes recorded by 'watch -history'.
+const defaultWatchHistory = 10
+
+func watchHistory(t *Term, ctx callContext, args string) error {
+	if ctx.Prefix == onPrefix {
+		if ctx.Breakpoint.WatchExpr != "" {
+			return errors.New("the history of watchpoints is set with 'watch -history'")
+		}
+		n := defaultWatchHistory
+		if args != "" {
+			var err error
+			n, err = strconv.Atoi(args)
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid history size %q", args)
+			}
+		}
+		ctx.Breakpoint.WatchHistory = n
+		return nil
+	}
+	full := false
+	if strings.HasPrefix(args, "-full ") {
+		full = true
+		args = strings.TrimSpace(args[len("-full "):])
+	}
+	if args == "" {
+		return errors.New("wrong number of arguments: history [-full] <expr|name|id>")
+	}
+	bp, err := getWatchpoint(t, args)
+	if err != nil {
+		return err
+	}
+	entries, err := t.client.WatchHistory(bp.ID)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		if bp.WatchExpr == "" {
+			fmt.Printf("no hits recorded by %s\n", formatBreakpointName(bp, false))
+		} else {
+			fmt.Printf("no writes recorded by %s\n", formatBreakpointName(bp, false))
+		}
+		return nil
+	}
+	for _, e := range entries {
+		fn := ""
+		if e.Function != nil {
+			fn = e.Function.Name() + " "
+		}
+		if bp.WatchExpr == "" {
+			fmt.Printf("%#x %s%s:%d goroutine %d\n", e.PC, fn, t.formatPath(e.File), e.Line, e.GoroutineID)
+			for _, v := range e.Variables {
+				fmt.Printf("\t%s = %s\n", v.Name, v.SinglelineString())
+			}
+		} else {
+			fmt.Printf("%#x %s%s:%d goroutine %d: %s = %s\n", e.PC, fn, t.formatPath(e.File), e.Line, e.GoroutineID, bp.WatchExpr, e.Value.SinglelineString())
+		}
+		if full {
+			printStack(t, os.Stdout, e.Stacktrace, "\t", false)
+		}
+	}
+	return nil
+}
+
+// getWatchpoint returns the watchpoint with the given name or ID or,
+// failing that, the watchpoint created with the expression arg.
+func getWatchpoint(t *Term, arg string) (*api.Breakpoint, error) {
+	bp, err := getBreakpointByIDOrName(t, arg)
+	if err == nil {
+		return bp, nil
+	}
+	bps, err2 := t.client.ListBreakpoints()
+	if err2 != nil {
+		return nil, err2
+	}
+	for _, bp := range bps {
+		if bp.WatchExpr == arg {
+			return bp, nil
+		}
+	}
+	return nil, err
+}
+
 func examineMemoryCmd(t *Term, ctx callContext, argstr string) error {
 	var (
 		address uint64
@@ -1961,11 +2926,6 @@ loop:
 		}
 	}
 
-	// TODO, maybe configured by user.
-	if count*size > 1000 {
-		return fmt.Errorf("read memory range (count*size) must be less than or equal to 1000 bytes")
-	}
-
 	if len(args) == 0 {
 		return fmt.Errorf("no address specified")
 	}
@@ -1999,40 +2959,471 @@ loop:
 		}
 	}
 
-	memArea, isLittleEndian, err := t.client.ExamineMemory(address, count*size)
-	if err != nil {
-		return err
+	// Memory is read in chunks, each a multiple of the length of an output
+	// row, so that big ranges can be printed as they are read and the
+	// command can be interrupted.
+	chunkSize := (examineMemoryChunkSize / (8 * size)) * (8 * size)
+	t.longCommandStart()
+	for length := count * size; length > 0; {
+		if t.longCommandCanceled() {
+			fmt.Printf("interrupted\n")
+			return nil
+		}
+		n := length
+		if n > chunkSize {
+			n = chunkSize
+		}
+		memArea, isLittleEndian, err := t.client.ExamineMemory(address, n)
+		if err != nil {
+			return err
+		}
+		fmt.Print(api.PrettyExamineMemory(uintptr(address), memArea, isLittleEndian, priFmt, size))
+		address += uint64(n)
+		length -= n
 	}
-	fmt.Print(api.PrettyExamineMemory(uintptr(address), memArea, isLittleEndian, priFmt, size))
 	return nil
 }
 
-func parseFormatArg(args string) (fmtstr, argsOut string) {
-	if len(args) < 1 || args[0] != '%' {
-		return "", args
+// The maximum number of bytes requested on each ExamineMemory call.
+const examineMemoryChunkSize = 1000
+
+func xdumpCmd(t *Term, ctx callContext, args string) error {
+	if args == "" {
+		return fmt.Errorf("not enough arguments")
 	}
-	v := strings.SplitN(args, " ", 2)
-	if len(v) == 1 {
-		return v[0], ""
+	cfg := api.LoadConfig{MaxStringLen: 1, MaxArrayValues: 1}
+	val, err := t.client.EvalVariable(ctx.Scope, args, cfg)
+	if err != nil {
+		return err
+	}
+	if val.Kind == reflect.Ptr {
+		val, err = t.client.EvalVariable(ctx.Scope, "*("+args+")", cfg)
+		if err != nil {
+			return err
+		}
+	}
+	if val.Unreadable != "" {
+		return fmt.Errorf("%s", val.Unreadable)
+	}
+	switch val.Kind {
+	case reflect.String:
+	case reflect.Slice, reflect.Array:
+		if val.Len > 0 && (len(val.Children) == 0 || (val.Children[0].RealType != "uint8" && val.Children[0].RealType != "int8")) {
+			return fmt.Errorf("%s is not a byte slice, byte array or string", val.Type)
+		}
+	default:
+		return fmt.Errorf("%s is not a byte slice, byte array or string", val.Type)
+	}
+
+	// Rows are sixteen bytes long, chunks are a multiple of that so that
+	// offsets stay aligned.
+	chunkSize := (examineMemoryChunkSize / 16) * 16
+	t.longCommandStart()
+	for off := 0; off < int(val.Len); off += chunkSize {
+		if t.longCommandCanceled() {
+			fmt.Printf("interrupted\n")
+			return nil
+		}
+		n := int(val.Len) - off
+		if n > chunkSize {
+			n = chunkSize
+		}
+		mem, _, err := t.client.ExamineMemory(val.Base+uint64(off), n)
+		if err != nil {
+			return err
+		}
+		fmt.Print(api.PrettyHexDump(mem, off))
 	}
-	return v[0], v[1]
+	return nil
 }
 
 func printVar(t *Term, ctx callContext, args string) error {
 	if len(args) == 0 {
 		return fmt.Errorf("not enough arguments")
 	}
+	format, args, err := parsePrintFormat(args)
+	if err != nil {
+		return err
+	}
+	if args == "" {
+		return fmt.Errorf("not enough arguments")
+	}
 	if ctx.Prefix == onPrefix {
 		ctx.Breakpoint.Variables = append(ctx.Breakpoint.Variables, args)
+		t.setBreakpointVarFormat(ctx.Breakpoint.ID, args, format)
 		return nil
 	}
-	fmtstr, args := parseFormatArg(args)
 	val, err := t.client.EvalVariable(ctx.Scope, args, t.loadConfig())
 	if err != nil {
 		return err
 	}
 
-	fmt.Println(val.MultilineString("", fmtstr))
+	t.applyPrettyPrinters(val, format)
+	fmt.Println(format.multiline(val, ""))
+	return nil
+}
+
+func mutexCommand(t *Term, ctx callContext, args string) error {
+	var flags printGoroutinesFlags
+	if strings.HasPrefix(args, "-s ") {
+		flags |= printGoroutinesStack
+		args = strings.TrimSpace(args[len("-s "):])
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("not enough arguments")
+	}
+	ms, err := t.client.MutexState(ctx.Scope, args)
+	if err != nil {
+		return err
+	}
+	state, err := t.client.GetState()
+	if err != nil {
+		return err
+	}
+
+	printList := func(descr string, gs []*api.Goroutine) error {
+		if len(gs) == 0 {
+			return nil
+		}
+		fmt.Printf("%s:\n", descr)
+		return printGoroutines(t, "", gs, fglUserCurrent, flags, 10, 0, state, nil)
+	}
+
+	lockState := func(locked bool) string {
+		if locked {
+			return "locked"
+		}
+		return "unlocked"
+	}
+
+	if ms.RW {
+		fmt.Printf("sync.RWMutex at %#x: writer %s, %d readers", ms.Addr, lockState(ms.WriterPending && ms.Readers == 0), ms.Readers)
+		if ms.WriterPending {
+			fmt.Printf(", writer pending")
+		}
+	} else {
+		fmt.Printf("sync.Mutex at %#x: %s", ms.Addr, lockState(ms.Locked))
+	}
+	if ms.Woken {
+		fmt.Printf(", woken")
+	}
+	if ms.Starving {
+		fmt.Printf(", starving")
+	}
+	fmt.Printf(", %d waiters\n", ms.Waiters)
+
+	for _, x := range []struct {
+		descr string
+		gs    []*api.Goroutine
+	}{
+		{"Probable holders", ms.Holders},
+		{"Writer waiting for readers", ms.QueuedWriter},
+		{"Readers waiting for the writer", ms.QueuedReaders},
+		{"Waiting", ms.Queued},
+	} {
+		if err := printList(x.descr, x.gs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// maxSearchResults is the maximum number of results printed by search.
+const maxSearchResults = 100
+
+func searchMemory(t *Term, ctx callContext, args string) error {
+	const usage = "wrong arguments: search -s <string>|-x <hex bytes> [<start> <end>]"
+	v := split2PartsBySpace(args)
+	if len(v) != 2 {
+		return errors.New(usage)
+	}
+	var pattern []byte
+	var rest string
+	switch v[0] {
+	case "-s":
+		str, r, err := splitQuotedArg(v[1])
+		if err != nil {
+			return err
+		}
+		pattern, rest = []byte(str), r
+	case "-x":
+		w := split2PartsBySpace(v[1])
+		var err error
+		pattern, err = hex.DecodeString(strings.TrimPrefix(w[0], "0x"))
+		if err != nil {
+			return fmt.Errorf("invalid hex bytes %q: %v", w[0], err)
+		}
+		if len(w) > 1 {
+			rest = w[1]
+		}
+	default:
+		return errors.New(usage)
+	}
+
+	var start, end uint64
+	if bounds := strings.Fields(rest); len(bounds) > 0 {
+		if len(bounds) != 2 {
+			return errors.New(usage)
+		}
+		var err error
+		if start, err = strconv.ParseUint(bounds[0], 0, 64); err != nil {
+			return fmt.Errorf("invalid start address %q", bounds[0])
+		}
+		if end, err = strconv.ParseUint(bounds[1], 0, 64); err != nil {
+			return fmt.Errorf("invalid end address %q", bounds[1])
+		}
+	}
+
+	results, err := t.client.SearchMemory(pattern, start, end, maxSearchResults+1)
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		fmt.Println("not found")
+		return nil
+	}
+	for i, r := range results {
+		if i >= maxSearchResults {
+			fmt.Printf("(more than %d results, only the first %d are shown)\n", maxSearchResults, maxSearchResults)
+			break
+		}
+		fmt.Printf("%#x", r.Addr)
+		if r.Region != nil {
+			fmt.Printf(" in %s", formatMemoryRegion(r.Region))
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// formatMemoryRegion formats a memory region as its range of addresses,
+// permissions and mapped file.
+func formatMemoryRegion(r *api.MemoryRegion) string {
+	s := fmt.Sprintf("%#x-%#x %s", r.Addr, r.Addr+r.Size, memoryRegionPerm(r))
+	if r.Filename != "" {
+		s += " " + r.Filename
+	}
+	return s
+}
+
+func memoryRegionPerm(r *api.MemoryRegion) string {
+	perm := []byte("---")
+	if r.Read {
+		perm[0] = 'r'
+	}
+	if r.Write {
+		perm[1] = 'w'
+	}
+	if r.Exec {
+		perm[2] = 'x'
+	}
+	return string(perm)
+}
+
+func vmmap(t *Term, ctx callContext, args string) error {
+	if args != "" {
+		return errors.New("too many arguments")
+	}
+	regions, err := t.client.ListMemoryRegions()
+	if err != nil {
+		return err
+	}
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 0, 8, 1, ' ', 0)
+	for i := range regions {
+		r := &regions[i]
+		var notes []string
+		if r.Image != "" {
+			notes = append(notes, "binary")
+		}
+		if r.GoHeap {
+			notes = append(notes, "go heap")
+		}
+		switch r.GoroutineStacks {
+		case 0:
+		case 1:
+			notes = append(notes, "1 goroutine stack")
+		default:
+			notes = append(notes, fmt.Sprintf("%d goroutine stacks", r.GoroutineStacks))
+		}
+		note := ""
+		if len(notes) > 0 {
+			note = "[" + strings.Join(notes, ", ") + "]"
+		}
+		fmt.Fprintf(w, "%#x-%#x\t%s\t%dK\t%s\t%s\n", r.Addr, r.Addr+r.Size, memoryRegionPerm(r), r.Size/1024, r.Filename, note)
+	}
+	return w.Flush()
+}
+
+// splitQuotedArg splits the first argument of args, which can be quoted
+// using the Go syntax, from the rest of args.
+func splitQuotedArg(args string) (arg, rest string, err error) {
+	if args == "" || (args[0] != '"' && args[0] != '`') {
+		v := split2PartsBySpace(args)
+		if len(v) > 1 {
+			rest = v[1]
+		}
+		return v[0], rest, nil
+	}
+	quote := args[0]
+	for i := 1; i < len(args); i++ {
+		switch args[i] {
+		case '\\':
+			if quote == '"' {
+				i++
+			}
+		case quote:
+			arg, err = strconv.Unquote(args[:i+1])
+			return arg, strings.TrimSpace(args[i+1:]), err
+		}
+	}
+	return "", "", errors.New("unterminated string")
+}
+
+func env(t *Term, ctx callContext, args string) error {
+	switch {
+	case strings.HasPrefix(args, "-u "):
+		return t.client.UnsetEnv(strings.TrimSpace(args[len("-u "):]))
+	case strings.Contains(args, "="):
+		i := strings.Index(args, "=")
+		return t.client.SetEnv(args[:i], args[i+1:])
+	}
+	environ, err := t.client.Environ()
+	if err != nil {
+		return err
+	}
+	for _, kv := range environ {
+		if args == "" {
+			fmt.Println(kv)
+		} else if strings.HasPrefix(kv, args+"=") {
+			fmt.Println(kv[len(args)+1:])
+			return nil
+		}
+	}
+	if args != "" {
+		return fmt.Errorf("%s is not set", args)
+	}
+	return nil
+}
+
+func fds(t *Term, ctx callContext, args string) error {
+	if args != "" {
+		return errors.New("too many arguments")
+	}
+	fds, err := t.client.ListFileDescriptors()
+	if err != nil {
+		return err
+	}
+	state, err := t.client.GetState()
+	if err != nil {
+		return err
+	}
+	gs, _, err := t.client.ListGoroutines(0, 0)
+	if err != nil {
+		return err
+	}
+	gmap := make(map[int]*api.Goroutine, len(gs))
+	for _, g := range gs {
+		gmap[g.ID] = g
+	}
+	for _, fd := range fds {
+		switch {
+		case fd.Proto == "unix":
+			fmt.Printf("%d unix %s", fd.Num, fd.Path)
+			if fd.Local != "" {
+				fmt.Printf(" %s", fd.Local)
+			}
+		case fd.Proto != "":
+			fmt.Printf("%d %s %s -> %s", fd.Num, fd.Proto, fd.Local, fd.Remote)
+			if fd.State != "" {
+				fmt.Printf(" (%s)", fd.State)
+			}
+		default:
+			fmt.Printf("%d %s", fd.Num, fd.Path)
+		}
+		fmt.Println()
+		for _, gid := range fd.Goroutines {
+			g := gmap[gid]
+			if g == nil {
+				fmt.Printf("\t  Goroutine %d\n", gid)
+				continue
+			}
+			if err := printGoroutines(t, "\t", []*api.Goroutine{g}, fglUserCurrent, 0, 0, 0, state, nil); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func timers(t *Term, ctx callContext, args string) error {
+	if args != "" {
+		return errors.New("too many arguments")
+	}
+	timers, err := t.client.ListTimers()
+	if err != nil {
+		return err
+	}
+	if len(timers) == 0 {
+		fmt.Println("No pending timers")
+		return nil
+	}
+	for i, timer := range timers {
+		when := fmt.Sprintf("%d", timer.When)
+		if i > 0 {
+			when = fmt.Sprintf("+%v", time.Duration(timer.When-timers[0].When))
+		}
+		fmt.Printf("Timer %#x at %s", timer.Addr, when)
+		if timer.Period != 0 {
+			fmt.Printf(" every %v", timer.Period)
+		}
+		fmt.Printf(": %s(%s)\n", timer.Func, timer.Arg.SinglelineString())
+	}
+	return nil
+}
+
+func chanCommand(t *Term, ctx callContext, args string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("not enough arguments")
+	}
+	cs, err := t.client.ChanState(ctx.Scope, args, t.loadConfig())
+	if err != nil {
+		return err
+	}
+	state, err := t.client.GetState()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("channel at %#x: %d/%d", cs.Addr, cs.Len, cs.Cap)
+	if cs.Closed {
+		fmt.Printf(", closed")
+	}
+	fmt.Println()
+	if len(cs.Buffer) > 0 {
+		fmt.Printf("Buffer:\n")
+		for i := range cs.Buffer {
+			fmt.Printf("\t[%d] %s\n", i, cs.Buffer[i].SinglelineString())
+		}
+		if int64(len(cs.Buffer)) < cs.Len {
+			fmt.Printf("\t...+%d more\n", cs.Len-int64(len(cs.Buffer)))
+		}
+	}
+	for _, x := range []struct {
+		descr string
+		gs    []*api.Goroutine
+	}{
+		{"Blocked sending", cs.Senders},
+		{"Blocked receiving", cs.Receivers},
+	} {
+		if len(x.gs) == 0 {
+			continue
+		}
+		fmt.Printf("%s:\n", x.descr)
+		if err := printGoroutines(t, "", x.gs, fglUserCurrent, printGoroutinesStack, 10, 0, state, nil); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -2173,8 +3564,20 @@ func locals(t *Term, ctx callContext, args string) error {
 }
 
 func vars(t *Term, ctx callContext, args string) error {
+	allPackages := false
+	if v := split2PartsBySpace(args); len(v) >= 1 && v[0] == "-a" {
+		allPackages = true
+		args = ""
+		if len(v) == 2 {
+			args = v[1]
+		}
+	}
 	filter, cfg := parseVarArguments(args, t)
-	vars, err := t.client.ListPackageVariables(filter, cfg)
+	pkg := ""
+	if filter == "" && !allPackages {
+		pkg = "."
+	}
+	vars, err := t.client.ListPackageVariablesInPackage(pkg, filter, cfg)
 	if err != nil {
 		return err
 	}
@@ -2209,29 +3612,64 @@ func stackCommand(t *Term, ctx callContext, args string) error {
 		ctx.Breakpoint.Stacktrace = sa.depth
 		return nil
 	}
-	var cfg *api.LoadConfig
 	if sa.full {
-		cfg = &ShortLoadConfig
+		err = printFullStack(t, ctx.Scope.GoroutineID, sa)
+	} else {
+		var stack []api.Stackframe
+		stack, err = t.client.Stacktrace(ctx.Scope.GoroutineID, sa.depth, sa.opts, nil)
+		if err == nil {
+			printStack(t, os.Stdout, stack, "", sa.offsets)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if sa.ancestors > 0 {
+		ancestors, err := t.client.Ancestors(ctx.Scope.GoroutineID, sa.ancestors, sa.ancestorDepth)
+		if err != nil {
+			return err
+		}
+		printAncestors(t, "", ancestors, true)
 	}
-	stack, err := t.client.Stacktrace(ctx.Scope.GoroutineID, sa.depth, sa.opts, cfg)
+	return nil
+}
+
+// The number of stack frames, including their variables, requested on
+// each RPC call by 'stack -full'.
+const stackFullChunkSize = 8
+
+// printFullStack prints the stacktrace of goroutine gid including local
+// variables and arguments. Frame variables are requested from the server in
+// chunks so that output can be printed as it arrives and the command can be
+// interrupted midway.
+func printFullStack(t *Term, gid int, sa stackArgs) error {
+	// Retrieving the frames without variables is cheap and lets us know how
+	// many frames there are.
+	stack, err := t.client.Stacktrace(gid, sa.depth, sa.opts, nil)
 	if err != nil {
 		return err
 	}
-	printStack(t, os.Stdout, stack, "", sa.offsets)
-	if sa.ancestors > 0 {
-		ancestors, err := t.client.Ancestors(ctx.Scope.GoroutineID, sa.ancestors, sa.ancestorDepth)
+	t.longCommandStart()
+	for first := 0; first < len(stack); first += stackFullChunkSize {
+		if t.longCommandCanceled() {
+			fmt.Printf("interrupted\n")
+			return nil
+		}
+		n := stackFullChunkSize
+		if first+n > len(stack) {
+			n = len(stack) - first
+		}
+		chunk, err := t.client.StacktraceChunk(gid, first, n-1, sa.opts, &ShortLoadConfig)
 		if err != nil {
 			return err
 		}
-		for _, ancestor := range ancestors {
-			fmt.Printf("Created by Goroutine %d:\n", ancestor.ID)
-			if ancestor.Unreadable != "" {
-				fmt.Printf("\t%s\n", ancestor.Unreadable)
-				continue
-			}
-			printStack(t, os.Stdout, ancestor.Stack, "\t", false)
+		if len(chunk) > n {
+			chunk = chunk[:n]
 		}
+		copy(stack[first:], chunk)
+		api.PrintStackChunk(t.formatPath, os.Stdout, chunk, first, len(stack), "", sa.offsets, func(api.Stackframe) bool { return true })
 	}
+	api.PrintStackEnd(os.Stdout, stack, "")
 	return nil
 }
 
@@ -2482,6 +3920,136 @@ func libraries(t *Term, ctx callContext, args string) error {
 	return nil
 }
 
+func processInfo(t *Term, ctx callContext, args string) error {
+	if args != "" {
+		return errors.New("too many arguments")
+	}
+	info, err := t.client.GetTargetInfo()
+	if err != nil {
+		return err
+	}
+	args2 := make([]string, len(info.Args))
+	for i, arg := range info.Args {
+		if arg == "" || strings.ContainsAny(arg, " \t\n\"'") {
+			arg = strconv.Quote(arg)
+		}
+		args2[i] = arg
+	}
+	fmt.Printf("Pid:\t\t%d\n", info.Pid)
+	fmt.Printf("Executable:\t%s\n", info.Path)
+	fmt.Printf("Command line:\t%s\n", strings.Join(args2, " "))
+	if info.WorkingDir != "" {
+		fmt.Printf("Directory:\t%s\n", info.WorkingDir)
+	}
+	if !info.StartTime.IsZero() {
+		fmt.Printf("Started:\t%s\n", info.StartTime.Format(time.RFC3339))
+	}
+	if info.GoVersion != "" {
+		fmt.Printf("Go version:\t%s\n", info.GoVersion)
+	}
+	return nil
+}
+
+func buildInfo(t *Term, ctx callContext, args string) error {
+	if args != "" {
+		return errors.New("too many arguments")
+	}
+	info, err := t.client.GetBuildInfo()
+	if err != nil {
+		return err
+	}
+	if info.BuildID != "" {
+		fmt.Printf("\tbuildid\t%s\n", info.BuildID)
+	}
+	fmt.Printf("\tgo\t%s\n", info.GoVersion)
+	if info.Path != "" {
+		fmt.Printf("\tpath\t%s\n", info.Path)
+	}
+	printModule := func(kind string, m *api.Module) {
+		fmt.Printf("\t%s\t%s\t%s", kind, m.Path, m.Version)
+		if m.Sum != "" {
+			fmt.Printf("\t%s", m.Sum)
+		}
+		fmt.Println()
+		if m.Replace != nil {
+			fmt.Printf("\t=>\t%s\t%s", m.Replace.Path, m.Replace.Version)
+			if m.Replace.Sum != "" {
+				fmt.Printf("\t%s", m.Replace.Sum)
+			}
+			fmt.Println()
+		}
+	}
+	if info.Main.Path != "" {
+		printModule("mod", &info.Main)
+	}
+	for i := range info.Deps {
+		printModule("dep", &info.Deps[i])
+	}
+	for _, s := range info.Settings {
+		fmt.Printf("\tbuild\t%s=%s\n", s.Key, s.Value)
+	}
+	return nil
+}
+
+func output(t *Term, ctx callContext, args string) error {
+	switch args = strings.TrimSpace(args); args {
+	case "":
+	case "-all":
+		t.outputOffsets = [2]int64{}
+	default:
+		return fmt.Errorf("wrong argument: '%s'", args)
+	}
+	stdout, stderr, err := t.client.GetOutput(t.outputOffsets[0], t.outputOffsets[1])
+	if err != nil {
+		return err
+	}
+	for i, out := range []struct {
+		api.TargetOutput
+		w io.Writer
+	}{{stdout, os.Stdout}, {stderr, os.Stderr}} {
+		if args == "" && out.Offset > t.outputOffsets[i] {
+			fmt.Fprintf(out.w, "(%d bytes of output discarded)\n", out.Offset-t.outputOffsets[i])
+		}
+		out.w.Write(out.Data)
+		t.outputOffsets[i] = out.Offset + int64(len(out.Data))
+	}
+	return nil
+}
+
+func clients(t *Term, ctx callContext, args string) error {
+	clients, self, err := t.client.ListClients()
+	if err != nil {
+		return err
+	}
+	for _, c := range clients {
+		mark := " "
+		switch {
+		case c.Driver:
+			mark = "*"
+		case c.Observer:
+			mark = "r"
+		}
+		you := ""
+		if c.ID == self {
+			you = " (this client)"
+		}
+		fmt.Printf("%s %d. %s%s\n", mark, c.ID, c.Addr, you)
+	}
+	return nil
+}
+
+func handoff(t *Term, ctx callContext, args string) error {
+	to := 0
+	if args = strings.TrimSpace(args); args != "" {
+		var err error
+		to, err = strconv.Atoi(args)
+		if err != nil {
+			return fmt.Errorf("invalid client ID %q", args)
+		}
+	}
+	return t.client.HandOff(to)
+}
+
 func digits(n int) int {
 	if n <= 0 {
 		return 1
@@ -2494,6 +4062,9 @@ func printStack(t *Term, out io.Writer, stack []api.Stackframe, ind string, offs
 }
 
 func printcontext(t *Term, state *api.DebuggerState) {
+	if state.StopReason.Kind == api.StopSignal {
+		fmt.Printf("%s received, fault address %#x (code %d)\n", state.StopReason.Signal, state.StopReason.FaultAddr, state.StopReason.SignalCode)
+	}
 	for i := range state.Threads {
 		if (state.CurrentThread != nil) && (state.Threads[i].ID == state.CurrentThread.ID) {
 			continue
@@ -2544,15 +4115,17 @@ func printcontextLocation(t *Term, loc api.Location) {
 	}
 }
 
+// printReturnValues prints the values returned by the function that
+// stepout, or next across a return, stepped out of.
 func printReturnValues(th *api.Thread) {
-	if th.ReturnValues == nil {
+	if len(th.ReturnValues) == 0 {
 		return
 	}
-	fmt.Println("Values returned:")
+	retVals := make([]string, 0, len(th.ReturnValues))
 	for _, v := range th.ReturnValues {
-		fmt.Printf("\t%s: %s\n", v.Name, v.MultilineString("\t", ""))
+		retVals = append(retVals, fmt.Sprintf("%s = %s", v.Name, v.SinglelineString()))
 	}
-	fmt.Println()
+	fmt.Printf("=> (returned: %s)\n", strings.Join(retVals, ", "))
 }
 
 func printcontextThread(t *Term, th *api.Thread) {
@@ -2652,7 +4225,9 @@ func printBreakpointInfo(t *Term, th *api.Thread, tracepointOnNewline bool) {
 
 	for _, v := range bpi.Variables {
 		tracepointnl()
-		fmt.Printf("\t%s: %s\n", v.Name, v.MultilineString("\t", ""))
+		format := t.breakpointVarFormat(bp.ID, v.Name)
+		t.applyPrettyPrinters(&v, format)
+		fmt.Printf("\t%s: %s\n", v.Name, format.multiline(&v, "\t"))
 	}
 
 	for _, v := range bpi.Locals {
@@ -2736,16 +4311,25 @@ func (ere ExitRequestError) Error() string {
 }
 
 func exitCommand(t *Term, ctx callContext, args string) error {
-	if args == "-c" {
-		if !t.client.IsMulticlient() {
-			return errors.New("not connected to an --accept-multiclient server")
-		}
-		t.quitContinue = true
+	switch strings.TrimSpace(args) {
+	case "":
+	case "-c":
+		t.quitBehavior = ExitContinue
+	case "-k":
+		t.quitBehavior = ExitKill
+	default:
+		return fmt.Errorf("wrong argument to exit %q", args)
 	}
 	return ExitRequestError{}
 }
 
 func getBreakpointByIDOrName(t *Term, arg string) (*api.Breakpoint, error) {
+	if arg == "$" {
+		if t.lastBreakpointID == 0 {
+			return nil, errors.New("no breakpoint was created")
+		}
+		return t.client.GetBreakpoint(t.lastBreakpointID)
+	}
 	if id, err := strconv.Atoi(arg); err == nil {
 		return t.client.GetBreakpoint(id)
 	}
@@ -2796,6 +4380,24 @@ func conditionCmd(t *Term, ctx callContext, argstr string) error {
 		return t.client.AmendBreakpoint(bp)
 	}
 
+	if args[0] == "-caller" {
+		args = split2PartsBySpace(args[1])
+		if len(args) < 2 {
+			return fmt.Errorf("not enough arguments")
+		}
+		bp, err := getBreakpointByIDOrName(t, args[0])
+		if err != nil {
+			return err
+		}
+
+		bp.Caller = args[1]
+		if bp.Caller == "-" {
+			bp.Caller = ""
+		}
+
+		return t.client.AmendBreakpoint(bp)
+	}
+
 	bp, err := getBreakpointByIDOrName(t, args[0])
 	if err != nil {
 		return err
@@ -2814,11 +4416,18 @@ func (c *Commands) executeFile(t *Term, name string) error {
 
 	scanner := bufio.NewScanner(fh)
 	lineno := 0
+	prefix := ""
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
 		lineno++
 
-		if line == "" || line[0] == '#' {
+		if prefix == "" && (line == "" || line[0] == '#') {
+			continue
+		}
+
+		line = prefix + line
+		var cont bool
+		if prefix, cont = lineContinuation(line); cont {
 			continue
 		}
 
@@ -2829,6 +4438,9 @@ func (c *Commands) executeFile(t *Term, name string) error {
 			fmt.Printf("%s:%d: %v\n", name, lineno, err)
 		}
 	}
+	if prefix != "" {
+		fmt.Printf("%s:%d: incomplete command at end of file\n", name, lineno)
+	}
 
 	return scanner.Err()
 }
@@ -2898,6 +4510,70 @@ func clearCheckpoint(t *Term, ctx callContext, args string) error {
 	return t.client.ClearCheckpoint(id)
 }
 
+func freezeGoroutine(t *Term, ctx callContext, args string) error {
+	gid, err := goroutineArg(t, args)
+	if err != nil {
+		return err
+	}
+	return t.client.FreezeGoroutine(gid)
+}
+
+func thawGoroutine(t *Term, ctx callContext, args string) error {
+	gid, err := goroutineArg(t, args)
+	if err != nil {
+		return err
+	}
+	return t.client.ThawGoroutine(gid)
+}
+
+// goroutineArg parses args as a goroutine ID, if args is empty the ID of
+// the selected goroutine is returned.
+func goroutineArg(t *Term, args string) (int, error) {
+	if args == "" {
+		state, err := t.client.GetState()
+		if err != nil {
+			return 0, err
+		}
+		if state.SelectedGoroutine == nil {
+			return 0, errors.New("no selected goroutine")
+		}
+		return state.SelectedGoroutine.ID, nil
+	}
+	gid, err := strconv.Atoi(args)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a goroutine ID", args)
+	}
+	return gid, nil
+}
+
+func diffCommand(t *Term, ctx callContext, args string) error {
+	const delOption = "-d "
+	switch {
+	case args == "":
+		for i, d := range t.diffs {
+			if d.expr != "" {
+				fmt.Printf("%d: %s\n", i, d.expr)
+			}
+		}
+		return nil
+
+	case strings.HasPrefix(args, delOption):
+		args = strings.TrimSpace(args[len(delOption):])
+		n, err := strconv.Atoi(args)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", args)
+		}
+		return t.removeDiff(n)
+
+	default:
+		if err := t.addDiff(args); err != nil {
+			return err
+		}
+		fmt.Printf("%d: %s\n", len(t.diffs)-1, args)
+		return nil
+	}
+}
+
 func display(t *Term, ctx callContext, args string) error {
 	const (
 		addOption = "-a "
@@ -2909,11 +4585,14 @@ func display(t *Term, ctx callContext, args string) error {
 
 	case strings.HasPrefix(args, addOption):
 		args = strings.TrimSpace(args[len(addOption):])
-		fmtstr, args := parseFormatArg(args)
+		format, args, err := parsePrintFormat(args)
+		if err != nil {
+			return err
+		}
 		if args == "" {
 			return fmt.Errorf("not enough arguments")
 		}
-		t.addDisplay(args, fmtstr)
+		t.addDisplay(args, format)
 		t.printDisplay(len(t.displays) - 1)
 
 	case strings.HasPrefix(args, delOption):
@@ -2960,6 +4639,240 @@ func dump(t *Term, ctx callContext, args string) error {
 	return nil
 }
 
+// defaultCPUProfileDuration is the duration of CPU profiles when none is
+// specified.
+const defaultCPUProfileDuration = 30 * time.Second
+
+func profile(t *Term, ctx callContext, args string) error {
+	v := strings.Fields(args)
+	if len(v) == 0 {
+		return errors.New("not enough arguments")
+	}
+	kind := api.ProfileKind(v[0])
+	v = v[1:]
+	duration := defaultCPUProfileDuration
+	switch kind {
+	case api.CPUProfile:
+		if len(v) > 0 {
+			if d, err := time.ParseDuration(v[0]); err == nil {
+				duration = d
+				v = v[1:]
+			}
+		}
+	case api.HeapProfile:
+	default:
+		return fmt.Errorf("unknown profile %q, expected cpu or heap", kind)
+	}
+	path := string(kind) + ".pprof"
+	if len(v) > 0 {
+		path = v[0]
+		v = v[1:]
+	}
+	if len(v) > 0 {
+		return errors.New("too many arguments")
+	}
+
+	if kind == api.CPUProfile {
+		fmt.Printf("Collecting CPU profile for %v...\n", duration)
+	}
+	state, err := t.client.Profile(kind, duration, path)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Profile written to %s\n", path)
+	if kind == api.CPUProfile {
+		printcontext(t, state)
+	}
+	return nil
+}
+
+func runtimeTrace(t *Term, ctx callContext, args string) error {
+	v := split2PartsBySpace(args)
+	var path string
+	if len(v) > 1 {
+		path = v[1]
+	}
+	switch v[0] {
+	case "start":
+		if path == "" {
+			return errors.New("not enough arguments")
+		}
+		if err := t.client.StartRuntimeTrace(path); err != nil {
+			return err
+		}
+		fmt.Printf("Writing runtime execution trace to %s\n", path)
+	case "stop":
+		path, err := t.client.StopRuntimeTrace()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Runtime execution trace written to %s, use 'go tool trace %s' to inspect it\n", path, path)
+	default:
+		return errors.New("wrong arguments, expected start or stop")
+	}
+	return nil
+}
+
+func coverageCommand(t *Term, ctx callContext, args string) error {
+	v := split2PartsBySpace(args)
+	var arg string
+	if len(v) > 1 {
+		arg = v[1]
+	}
+	switch v[0] {
+	case "start":
+		n, err := t.client.StartCoverage(arg)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Recording coverage of %d lines\n", n)
+	case "stop":
+		return t.client.StopCoverage()
+	case "report":
+		if arg == "" {
+			return errors.New("not enough arguments")
+		}
+		lines, err := t.client.GetCoverage()
+		if err != nil {
+			return err
+		}
+		fh, err := os.Create(arg)
+		if err != nil {
+			return err
+		}
+		err = writeCoverProfile(fh, lines)
+		if err1 := fh.Close(); err == nil {
+			err = err1
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Coverage profile written to %s, use 'go tool cover -html=%s' to inspect it\n", arg, arg)
+	case "":
+		lines, err := t.client.GetCoverage()
+		if err != nil {
+			return err
+		}
+		w := new(tabwriter.Writer)
+		w.Init(os.Stdout, 0, 8, 1, ' ', 0)
+		for i := 0; i < len(lines); {
+			file := lines[i].File
+			total, executed := 0, 0
+			for ; i < len(lines) && lines[i].File == file; i++ {
+				total++
+				if lines[i].Executed {
+					executed++
+				}
+			}
+			fmt.Fprintf(w, "%s\t%d/%d\t(%.1f%%)\n", t.formatPath(file), executed, total, 100*float64(executed)/float64(total))
+		}
+		return w.Flush()
+	default:
+		return errors.New("wrong arguments, expected start, stop or report")
+	}
+	return nil
+}
+
+func lasttraceCommand(t *Term, ctx callContext, args string) error {
+	if args == "start" {
+		if err := t.client.StartBranchTrace(); err != nil {
+			return err
+		}
+		fmt.Println("Branch tracing started")
+		return nil
+	}
+	n, threadID := 20, 0
+	fields := strings.Fields(args)
+	for i := 0; i < len(fields); i++ {
+		var err error
+		switch fields[i] {
+		case "-t":
+			i++
+			if i >= len(fields) {
+				return errors.New("not enough arguments")
+			}
+			threadID, err = strconv.Atoi(fields[i])
+		default:
+			n, err = strconv.Atoi(fields[i])
+			if err == nil && n <= 0 {
+				err = errors.New("number of events must be positive")
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	evs, err := t.client.GetBranchTrace(threadID)
+	if err != nil {
+		return err
+	}
+	if len(evs) > n {
+		evs = evs[len(evs)-n:]
+	}
+	if len(evs) == 0 {
+		fmt.Println("No transfers between functions recorded")
+		return nil
+	}
+	t.printBranchTrace(os.Stdout, evs)
+	return nil
+}
+
+// printBranchTrace writes evs to w, indenting every event by the call
+// depth relative to the shallowest event.
+func (t *Term) printBranchTrace(w io.Writer, evs []api.BranchTraceEvent) {
+	depths := make([]int, len(evs))
+	depth, min := 0, 0
+	for i := range evs {
+		if evs[i].Kind == api.BranchTraceReturn {
+			depth--
+		}
+		depths[i] = depth
+		if depth < min {
+			min = depth
+		}
+		if evs[i].Kind == api.BranchTraceCall {
+			depth++
+		}
+	}
+	for i, ev := range evs {
+		indent := strings.Repeat("  ", depths[i]-min)
+		if ev.Kind == api.BranchTraceGap {
+			fmt.Fprintf(w, "%s... trace lost after %s\n", indent, t.formatBranchTraceLocation(ev.From))
+			continue
+		}
+		fmt.Fprintf(w, "%s%-6s %s -> %s\n", indent, ev.Kind, t.formatBranchTraceLocation(ev.From), t.formatBranchTraceLocation(ev.To))
+	}
+}
+
+func (t *Term) formatBranchTraceLocation(loc api.Location) string {
+	switch {
+	case loc.PC == 0:
+		return "?"
+	case loc.File == "":
+		return fmt.Sprintf("%s %#x", loc.Function.Name(), loc.PC)
+	default:
+		return fmt.Sprintf("%s %s:%d", loc.Function.Name(), t.formatPath(loc.File), loc.Line)
+	}
+}
+
+// writeCoverProfile writes lines to w in the format of Go coverage
+// profiles, in set mode, with one block covering every line.
+func writeCoverProfile(w io.Writer, lines []api.CoverageLine) error {
+	if _, err := fmt.Fprintln(w, "mode: set"); err != nil {
+		return err
+	}
+	for _, l := range lines {
+		count := 0
+		if l.Executed {
+			count = 1
+		}
+		if _, err := fmt.Fprintf(w, "%s:%d.1,%d.1 1 %d\n", filepath.ToSlash(l.File), l.Line, l.Line+1, count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func formatBreakpointName(bp *api.Breakpoint, upcase bool) string {
 	thing := "breakpoint"
 	if bp.Tracepoint {
@@ -2975,6 +4888,10 @@ func formatBreakpointName(bp *api.Breakpoint, upcase bool) string {
 	if id == "" {
 		id = strconv.Itoa(bp.ID)
 	}
+	if bp.ID == 0 && len(bp.VerboseDescr) > 0 {
+		// set by next or step
+		return fmt.Sprintf("%s (internal)", thing)
+	}
 	if bp.WatchExpr != "" && bp.WatchExpr != bp.Name {
 		return fmt.Sprintf("%s %s on [%s]", thing, id, bp.WatchExpr)
 	}
@@ -3006,6 +4923,9 @@ func (t *Term) formatBreakpointLocation(bp *api.Breakpoint) string {
 			fmt.Fprintf(&out, "%s() ", bp.FunctionName)
 		}
 		fmt.Fprintf(&out, "%s:%d", p, bp.Line)
+		if bp.Column > 0 {
+			fmt.Fprintf(&out, ":%d", bp.Column)
+		}
 	}
 	return out.String()
 }
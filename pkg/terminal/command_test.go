@@ -1,6 +1,8 @@
 package terminal
 
 import (
+	"bytes"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io/ioutil"
@@ -8,8 +10,10 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"testing"
@@ -869,6 +873,29 @@ func TestIssue1090(t *testing.T) {
 	})
 }
 
+func TestGoroutinesHideRuntime(t *testing.T) {
+	// Goroutines of the runtime are only listed with -all.
+	withTestTerminal("goroutinestackprog", t, func(term *FakeTerminal) {
+		term.MustExec("break stacktraceme")
+		term.MustExec("continue")
+
+		out := term.MustExec("goroutines -s")
+		if strings.Contains(out, "runtime.forcegchelper") {
+			t.Errorf("runtime goroutine listed without -all:\n%s", out)
+		}
+		if !strings.Contains(out, "main.agoroutine") {
+			t.Errorf("user goroutine missing:\n%s", out)
+		}
+		out = term.MustExec("goroutines -all -with runtime -s")
+		if !strings.Contains(out, "runtime.forcegchelper") {
+			t.Errorf("runtime goroutine missing with -all:\n%s", out)
+		}
+		if strings.Contains(out, "main.agoroutine") {
+			t.Errorf("user goroutine listed by -with runtime:\n%s", out)
+		}
+	})
+}
+
 func TestPrintContextParkedGoroutine(t *testing.T) {
 	withTestTerminal("goroutinestackprog", t, func(term *FakeTerminal) {
 		term.MustExec("break stacktraceme")
@@ -904,6 +931,87 @@ func TestPrintContextParkedGoroutine(t *testing.T) {
 	})
 }
 
+func TestGoroutineAncestors(t *testing.T) {
+	if !goversion.VersionAfterOrEqual(runtime.Version(), 1, 11) {
+		t.Skip("not supported on Go <= 1.10")
+	}
+	savedGodebug := os.Getenv("GODEBUG")
+	os.Setenv("GODEBUG", "tracebackancestors=100")
+	defer os.Setenv("GODEBUG", savedGodebug)
+	withTestTerminal("goroutineancestors", t, func(term *FakeTerminal) {
+		term.MustExec("continue")
+
+		// The current goroutine was created by the goroutine running
+		// main.middle, itself created by the go statement at line 22.
+		out := term.MustExec("stack -a 1")
+		t.Logf("stack -a 1 -> %q", out)
+		if !strings.Contains(out, "itself created at ") || !strings.Contains(out, "goroutineancestors.go:22 main.main") {
+			t.Fatalf("go statement of the ancestor missing from stack output")
+		}
+		if !strings.Contains(out, "main.middle") {
+			t.Fatalf("stack of the ancestor missing from stack output")
+		}
+
+		out = term.MustExec("goroutines -a 2")
+		t.Logf("goroutines -a 2 -> %q", out)
+		if !strings.Contains(out, "goroutineancestors.go:22 main.main") {
+			t.Fatalf("go statement of the ancestor missing from goroutines output")
+		}
+
+		out = term.MustExec("goroutines -a 1 -t")
+		t.Logf("goroutines -a 1 -t -> %q", out)
+		if !strings.Contains(out, "main.middle") {
+			t.Fatalf("ancestor stacks missing from goroutines -a -t output")
+		}
+	})
+}
+
+func TestBreakOrigin(t *testing.T) {
+	withTestTerminal("goroutineancestors", t, func(term *FakeTerminal) {
+		term.MustExec("continue")
+
+		state, err := term.client.GetState()
+		if err != nil {
+			t.Fatalf("GetState: %v", err)
+		}
+		g := state.SelectedGoroutine
+		if g.GoPC == 0 || g.StartPC == 0 {
+			t.Fatalf("GoPC and StartPC not set: %#x %#x", g.GoPC, g.StartPC)
+		}
+		if g.StartLoc.PC != g.StartPC {
+			t.Errorf("StartPC %#x does not match the start location %#x", g.StartPC, g.StartLoc.PC)
+		}
+
+		term.MustExec("break-origin origin")
+		term.MustExec("break-origin -start originstart")
+
+		bps, err := term.client.ListBreakpoints()
+		if err != nil {
+			t.Fatalf("ListBreakpoints: %v", err)
+		}
+		found := 0
+		for _, bp := range bps {
+			switch bp.Name {
+			case "origin":
+				found++
+				// The selected goroutine runs main.leaf, started by the go
+				// statement in main.middle.
+				if bp.Line != 16 || bp.FunctionName != "main.middle" {
+					t.Errorf("wrong location for break-origin: %s:%d %s", bp.File, bp.Line, bp.FunctionName)
+				}
+			case "originstart":
+				found++
+				if bp.FunctionName != "main.leaf" || bp.Line != 11 {
+					t.Errorf("wrong location for break-origin -start: %s:%d %s", bp.File, bp.Line, bp.FunctionName)
+				}
+			}
+		}
+		if found != 2 {
+			t.Fatalf("breakpoints not found: %#v", bps)
+		}
+	})
+}
+
 func TestStepOutReturn(t *testing.T) {
 	ver, _ := goversion.Parse(runtime.Version())
 	if ver.Major >= 0 && !ver.AfterOrEqual(goversion.GoVersion{Major: 1, Minor: 10, Rev: -1}) {
@@ -914,12 +1022,24 @@ func TestStepOutReturn(t *testing.T) {
 		term.MustExec("continue")
 		out := term.MustExec("stepout")
 		t.Logf("output: %q", out)
-		if !strings.Contains(out, "num: ") || !strings.Contains(out, "str: ") {
+		if !strings.Contains(out, `=> (returned: str = "return 47", num = 48)`) {
 			t.Fatal("could not find parameter")
 		}
 	})
 }
 
+func TestNextReturnValues(t *testing.T) {
+	withTestTerminal("stepoutret", t, func(term *FakeTerminal) {
+		term.MustExec("break stepoutret.go:6")
+		term.MustExec("continue")
+		out := term.MustExec("next")
+		t.Logf("output: %q", out)
+		if !strings.Contains(out, `=> (returned: str = "return 47", num = 48)`) {
+			t.Fatal("could not find return values")
+		}
+	})
+}
+
 func TestOptimizationCheck(t *testing.T) {
 	withTestTerminal("continuetestprog", t, func(term *FakeTerminal) {
 		term.MustExec("break main.main")
@@ -1093,6 +1213,117 @@ func TestExamineMemoryCmd(t *testing.T) {
 	})
 }
 
+func TestVarsCmd(t *testing.T) {
+	// vars lists the variables of the package of the current function,
+	// vars -a those of all packages.
+	withTestTerminal("testvariables", t, func(term *FakeTerminal) {
+		term.MustExec("continue")
+		out := term.MustExec("vars")
+		if !strings.Contains(out, "main.p1 = 10") || strings.Contains(out, "runtime.") {
+			t.Errorf("wrong output for vars:\n%s", out)
+		}
+		out = term.MustExec("vars -a")
+		if !strings.Contains(out, "main.p1 = 10") || !strings.Contains(out, "runtime.") {
+			t.Errorf("wrong output for vars -a:\n%s", out)
+		}
+		out = term.MustExec("vars runtime.firstmoduledata")
+		if !strings.Contains(out, "runtime.firstmoduledata") {
+			t.Errorf("wrong output for vars runtime.firstmoduledata:\n%s", out)
+		}
+		out = term.MustExec("vars -a nonexistent")
+		if strings.Contains(out, "main.p1") {
+			t.Errorf("wrong output for vars -a nonexistent:\n%s", out)
+		}
+	})
+}
+
+func TestExamineMemoryChunks(t *testing.T) {
+	// ranges larger than 1000 bytes are read in chunks
+	withTestTerminal("largebuffer", t, func(term *FakeTerminal) {
+		term.MustExec("break largebuffer.go:10")
+		term.MustExec("continue")
+
+		addressStr := strings.TrimSpace(term.MustExec("print uintptr(unsafe.Pointer(&buf[0]))"))
+		address, err := strconv.ParseUint(addressStr, 0, 64)
+		if err != nil {
+			t.Fatalf("could not convert %s into uint64, err %s", addressStr, err)
+		}
+		res := term.MustExec("examinemem -count 2500 -fmt hex -x &buf[0]")
+		lines := strings.Split(strings.TrimSpace(res), "\n")
+		if len(lines) != 313 {
+			t.Errorf("wrong number of lines %d, expected 313", len(lines))
+		}
+		for _, tgt := range []string{
+			fmt.Sprintf("%#x:   0xe8   0xe9   0xea   0xeb   0xec   0xed   0xee   0xef", address+1000),
+			fmt.Sprintf("%#x:   0xc0   0xc1   0xc2   0xc3", address+2496),
+		} {
+			if !strings.Contains(res, tgt+"\n") {
+				t.Errorf("line %q not found in:\n%s", tgt, res)
+			}
+		}
+	})
+}
+
+func TestSearchMemoryCmd(t *testing.T) {
+	withTestTerminal("examinememory", t, func(term *FakeTerminal) {
+		term.MustExec("break examinememory.go:19")
+		term.MustExec("continue")
+
+		addressStr := strings.TrimSpace(term.MustExec("p bspUintptr"))
+		address, err := strconv.ParseUint(addressStr, 0, 64)
+		if err != nil {
+			t.Fatalf("could convert %s into uint64, err %s", addressStr, err)
+		}
+
+		firstField := func(res string) string {
+			lines := strings.Split(strings.TrimSpace(res), "\n")
+			if len(lines) != 1 {
+				t.Fatalf("wrong number of search results %q", res)
+			}
+			return strings.Fields(lines[0])[0]
+		}
+
+		res := term.MustExec(fmt.Sprintf("search -x 0b0c0d0e %#x %#x", address, address+51))
+		if firstField(res) != fmt.Sprintf("%#x", address+1) {
+			t.Fatalf("wrong search result %q", res)
+		}
+		res = term.MustExec(fmt.Sprintf("search -s \"\\x0c\\x0d\" %#x %#x", address, address+51))
+		if firstField(res) != fmt.Sprintf("%#x", address+2) {
+			t.Fatalf("wrong search result %q", res)
+		}
+		res = term.MustExec(fmt.Sprintf("search -x ff %#x %#x", address, address+51))
+		if strings.TrimSpace(res) != "not found" {
+			t.Fatalf("wrong search result %q", res)
+		}
+
+		if runtime.GOOS == "linux" {
+			res = term.MustExec("search -x 0a0b0c0d0e0f10111213")
+			if !strings.Contains(res, fmt.Sprintf("%#x in ", address)) {
+				t.Fatalf("address %#x not found in %q", address, res)
+			}
+		}
+	})
+}
+
+func TestSplitQuotedArg(t *testing.T) {
+	for _, tc := range []struct {
+		in, arg, rest string
+		err           bool
+	}{
+		{"hello world", "hello", "world", false},
+		{"hello", "hello", "", false},
+		{`"hello world" 1 2`, "hello world", "1 2", false},
+		{`"a\"b\n"`, "a\"b\n", "", false},
+		{"`a\\b` 1", "a\\b", "1", false},
+		{`"hello`, "", "", true},
+	} {
+		arg, rest, err := splitQuotedArg(tc.in)
+		if (err != nil) != tc.err || arg != tc.arg || rest != tc.rest {
+			t.Errorf("%q: got %q %q %v", tc.in, arg, rest, err)
+		}
+	}
+}
+
 func TestPrintOnTracepoint(t *testing.T) {
 	withTestTerminal("increment", t, func(term *FakeTerminal) {
 		term.MustExec("trace main.Increment")
@@ -1180,6 +1411,176 @@ func TestPrintFormat(t *testing.T) {
 	})
 }
 
+func TestBreakpointIfCondition(t *testing.T) {
+	withTestTerminal("break", t, func(term *FakeTerminal) {
+		term.MustExec("break bp1 main.main:4 if i == 5")
+		out := term.MustExec("breakpoints")
+		if !strings.Contains(out, "\tcond i == 5") {
+			t.Errorf("condition not listed:\n%s", out)
+		}
+		term.MustExec("continue")
+		if out := term.MustExec("print i"); strings.TrimSpace(out) != "5" {
+			t.Errorf("stopped at the wrong iteration: %q", out)
+		}
+		if _, err := term.Exec("break main.main:4 if"); err == nil {
+			t.Errorf("breakpoint without condition after if accepted")
+		}
+	})
+}
+
+func TestBreakpointHistoryCmd(t *testing.T) {
+	withTestTerminal("break", t, func(term *FakeTerminal) {
+		term.MustExec("break bp1 main.main:4")
+		term.MustExec("on bp1 print i")
+		term.MustExec("on bp1 history 3")
+		out := term.MustExec("breakpoints")
+		if !strings.Contains(out, "\thistory 3") {
+			t.Errorf("history not listed:\n%s", out)
+		}
+		out = term.MustExec("history bp1")
+		if !strings.Contains(out, "no hits recorded") {
+			t.Errorf("unexpected output of history before continuing:\n%s", out)
+		}
+		if _, err := term.Exec("continue"); err == nil || !strings.Contains(err.Error(), "exited") {
+			t.Fatalf("expected the target to exit without stopping, got %v", err)
+		}
+		out = term.MustExec("history bp1")
+		for _, v := range []string{"i = 9\n", "i = 10\n", "i = 11\n"} {
+			if !strings.Contains(out, v) {
+				t.Errorf("%q missing from history:\n%s", v, out)
+			}
+		}
+		if strings.Contains(out, "i = 8\n") {
+			t.Errorf("history not limited to the last 3 hits:\n%s", out)
+		}
+	})
+}
+
+func TestBreakpointsScript(t *testing.T) {
+	withTestTerminal("testnextprog", t, func(term *FakeTerminal) {
+		term.MustExec("break main.helloworld")
+		term.MustExec("break -g grp bpnamed main.testnext -caller main.main if 1 == 1")
+		term.MustExec("condition -hitcount bpnamed > 1")
+		term.MustExec("on bpnamed print %x j")
+		term.MustExec("on bpnamed stack 3")
+		term.MustExec("trace main.sleepytime")
+		out := term.MustExec("break main.testgoroutine")
+		var id int
+		if _, err := fmt.Sscanf(out, "Breakpoint %d ", &id); err != nil {
+			t.Fatalf("could not parse breakpoint ID from %q: %v", out, err)
+		}
+		term.MustExec(fmt.Sprintf("on %d goroutine", id))
+		term.MustExec(fmt.Sprintf("toggle %d", id))
+
+		bps, err := term.client.ListBreakpoints()
+		if err != nil {
+			t.Fatalf("ListBreakpoints: %v", err)
+		}
+		script := string(term.breakpointsScript(bps))
+		t.Logf("script:\n%s", script)
+		for _, tgt := range []string{
+			"break -g grp bpnamed ",
+			" -caller main.main if 1 == 1\n",
+			"condition -hitcount bpnamed > 1\n",
+			"on bpnamed print %x j\n",
+			"on bpnamed stack 3\n",
+			"on $ goroutine\n",
+			"toggle $\n",
+		} {
+			if !strings.Contains(script, tgt) {
+				t.Errorf("%q missing from script", tgt)
+			}
+		}
+		if n := strings.Count(script, "trace "); n != 1 {
+			t.Errorf("expected a single trace command, got %d", n)
+		}
+		if strings.Contains(script, fmt.Sprintf("bp%d", id)) || strings.Contains(script, " args") {
+			t.Errorf("unexpected commands in script")
+		}
+
+		// Recreating the breakpoints from the script restores their attributes.
+		for _, bp := range bps {
+			if bp.ID > 0 {
+				if _, err := term.client.ClearBreakpoint(bp.ID); err != nil {
+					t.Fatalf("ClearBreakpoint: %v", err)
+				}
+			}
+		}
+		dir, err := ioutil.TempDir("", "dormant")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "bps.dlv")
+		if err := ioutil.WriteFile(path, []byte(script), 0600); err != nil {
+			t.Fatal(err)
+		}
+		term.MustExec("source " + path)
+		bps2, err := term.client.ListBreakpoints()
+		if err != nil {
+			t.Fatalf("ListBreakpoints: %v", err)
+		}
+		if script2 := string(term.breakpointsScript(bps2)); strings.Count(script2, "\n") != strings.Count(script, "\n") {
+			t.Errorf("breakpoints not restored, script after restore:\n%s", script2)
+		}
+		for _, bp := range bps2 {
+			if bp.FunctionName == "main.testgoroutine" && bp.Name != "" {
+				t.Errorf("unnamed breakpoint restored with name %q", bp.Name)
+			}
+		}
+		out = term.MustExec("breakpoints")
+		for _, tgt := range []string{"\tcond 1 == 1", "\tcond -hitcount > 1", "\tcond -caller main.main", "\tprint %x j", "\tstack 3", "\tgoroutine", "\tgroups grp", "(disabled)"} {
+			if !strings.Contains(out, tgt) {
+				t.Errorf("%q missing from breakpoints after restore:\n%s", tgt, out)
+			}
+		}
+	})
+}
+
+func TestBreakpointsListing(t *testing.T) {
+	withTestTerminal("testnextprog", t, func(term *FakeTerminal) {
+		term.MustExec("break main.testgoroutine")
+		term.MustExec("break main.helloworld")
+		term.MustExec("break main.sleepytime")
+		term.MustExec("toggle 3")
+		if out := term.MustExec("breakpoints"); strings.Contains(out, "unrecovered-panic") {
+			t.Errorf("internal breakpoints listed without -a:\n%s", out)
+		}
+		out := term.MustExec("breakpoints -a")
+		t.Logf("%s", out)
+		lines := []int{}
+		for _, line := range strings.Split(out, "\n") {
+			if strings.HasPrefix(line, "Internal breakpoints:") {
+				break
+			}
+			if !strings.HasPrefix(line, "Breakpoint ") {
+				continue
+			}
+			i := strings.LastIndex(line, ":")
+			var n int
+			if _, err := fmt.Sscanf(line[i+1:], "%d ", &n); err != nil {
+				t.Fatalf("could not parse line number of %q: %v", line, err)
+			}
+			lines = append(lines, n)
+		}
+		if len(lines) != 3 || !sort.IntsAreSorted(lines) {
+			t.Errorf("breakpoints not sorted by line: %v", lines)
+		}
+		i := strings.Index(out, "Internal breakpoints:")
+		if i < 0 || !strings.Contains(out[i:], "unrecovered-panic") || strings.Contains(out[:i], "unrecovered-panic") {
+			t.Errorf("internal breakpoints not listed separately")
+		}
+		if !strings.Contains(out, "Breakpoint 3 (disabled)") {
+			t.Errorf("disabled breakpoint not marked")
+		}
+
+		term.MustExec("clearall")
+		if out := term.MustExec("breakpoints -a"); !strings.Contains(out, "unrecovered-panic") {
+			t.Errorf("internal breakpoint deleted by clearall:\n%s", out)
+		}
+	})
+}
+
 func TestHitCondBreakpoint(t *testing.T) {
 	withTestTerminal("break", t, func(term *FakeTerminal) {
 		term.MustExec("break bp1 main.main:4")
@@ -1192,3 +1593,642 @@ func TestHitCondBreakpoint(t *testing.T) {
 		}
 	})
 }
+
+func TestGoroutineContext(t *testing.T) {
+	withTestTerminal("goroutinestackprog", t, func(term *FakeTerminal) {
+		term.MustExec("break main.stacktraceme")
+		term.MustExec("continue")
+		state, err := term.client.GetState()
+		if err != nil {
+			t.Fatal(err)
+		}
+		curgid := state.SelectedGoroutine.ID
+		gs, _, err := term.client.ListGoroutines(0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		othergid := 0
+		for _, g := range gs {
+			if g.UserCurrentLoc.Function != nil && g.UserCurrentLoc.Function.Name() == "main.agoroutine" {
+				othergid = g.ID
+				break
+			}
+		}
+		if othergid == 0 {
+			t.Fatal("could not find a goroutine running main.agoroutine")
+		}
+
+		term.MustExec("frame 1")
+		term.MustExec("display -a dummy")
+
+		term.MustExec(fmt.Sprintf("goroutine %d", othergid))
+		if term.cmds.frame != 0 {
+			t.Errorf("frame not reset after switching to a new goroutine: %d", term.cmds.frame)
+		}
+		term.MustExec("display -a 1+1")
+		if len(term.displays) != 2 {
+			t.Errorf("wrong display list for goroutine %d: %v", othergid, term.displays)
+		}
+
+		term.MustExec(fmt.Sprintf("goroutine %d", curgid))
+		if term.cmds.frame != 1 {
+			t.Errorf("frame not restored after switching back: %d", term.cmds.frame)
+		}
+		if len(term.displays) != 1 || term.displays[0].expr != "dummy" {
+			t.Errorf("display list not restored after switching back: %v", term.displays)
+		}
+
+		term.MustExec(fmt.Sprintf("goroutine %d", othergid))
+		if len(term.displays) != 2 {
+			t.Errorf("display list not restored for goroutine %d: %v", othergid, term.displays)
+		}
+
+		// Stopping on a different goroutine switches to its context.
+		term.MustExec("break main.func1")
+		term.MustExec("continue")
+		if len(term.displays) != 1 || term.displays[0].expr != "dummy" {
+			t.Errorf("display list not restored after stopping on goroutine %d: %v", curgid, term.displays)
+		}
+		if term.cmds.frame != 0 {
+			t.Errorf("frame not reset after stopping: %d", term.cmds.frame)
+		}
+	})
+}
+
+func TestParseBreakpointGroups(t *testing.T) {
+	tests := []struct {
+		in     string
+		groups []string
+		rest   string
+		err    bool
+	}{
+		{"main.go:10", nil, "main.go:10", false},
+		{"-g auth main.go:10", []string{"auth"}, "main.go:10", false},
+		{"-g auth -g http-handlers name main.go:10", []string{"auth", "http-handlers"}, "name main.go:10", false},
+		{"-g auth", []string{"auth"}, "", false},
+		{"-g a,b main.go:10", nil, "", true},
+	}
+	for _, tc := range tests {
+		groups, rest, err := parseBreakpointGroups(tc.in)
+		if tc.err {
+			if err == nil {
+				t.Errorf("%q: expected error", tc.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tc.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(groups, tc.groups) || rest != tc.rest {
+			t.Errorf("%q: got %q %q, expected %q %q", tc.in, groups, rest, tc.groups, tc.rest)
+		}
+	}
+}
+
+func TestParseBreakpointCondition(t *testing.T) {
+	tests := []struct {
+		in   string
+		cond string
+		rest string
+		err  bool
+	}{
+		{"main.go:10", "", "main.go:10", false},
+		{"main.go:10 if i == 42 && name == \"foo\"", "i == 42 && name == \"foo\"", "main.go:10", false},
+		{"mybp main.f -caller main.main if x > 0", "x > 0", "mybp main.f -caller main.main", false},
+		{"main.go:10\tif\tok", "ok", "main.go:10", false},
+		{"main.gift", "", "main.gift", false},
+		{"main.go:10 if", "", "", true},
+	}
+	for _, tc := range tests {
+		cond, rest, err := parseBreakpointCondition(tc.in)
+		if tc.err {
+			if err == nil {
+				t.Errorf("%q: expected error", tc.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tc.in, err)
+			continue
+		}
+		if cond != tc.cond || rest != tc.rest {
+			t.Errorf("%q: got %q %q, expected %q %q", tc.in, cond, rest, tc.cond, tc.rest)
+		}
+	}
+}
+
+func TestParseBreakpointCaller(t *testing.T) {
+	tests := []struct {
+		in     string
+		caller string
+		rest   string
+		err    bool
+	}{
+		{"main.go:10", "", "main.go:10", false},
+		{"main.go:10 -caller mypkg.HandleRequest", "mypkg.HandleRequest", "main.go:10", false},
+		{"name main.(*T).f -caller main.main", "main.main", "name main.(*T).f", false},
+		{"main.go:10 -caller", "", "", true},
+		{"-caller main.main main.go:10", "", "", true},
+	}
+	for _, tc := range tests {
+		caller, rest, err := parseBreakpointCaller(tc.in)
+		if tc.err {
+			if err == nil {
+				t.Errorf("%q: expected error", tc.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tc.in, err)
+			continue
+		}
+		if caller != tc.caller || rest != tc.rest {
+			t.Errorf("%q: got %q %q, expected %q %q", tc.in, caller, rest, tc.caller, tc.rest)
+		}
+	}
+}
+
+func TestBreakpointGroupsCmd(t *testing.T) {
+	withTestTerminal("testtoggle", t, func(term *FakeTerminal) {
+		term.MustExec("break -g a main.main")
+		term.MustExec("break -g a -g b lower main.lineOne")
+		if out := term.MustExec("breakpoints"); !strings.Contains(out, "\tgroups a, b\n") {
+			t.Errorf("groups not listed: %q", out)
+		}
+		out := term.MustExec("toggle -g a")
+		if n := strings.Count(out, " toggled at "); n != 2 {
+			t.Errorf("wrong number of breakpoints toggled: %q", out)
+		}
+		if out := term.MustExec("breakpoints"); strings.Count(out, "(disabled)") != 2 {
+			t.Errorf("breakpoints not disabled: %q", out)
+		}
+		term.MustExec("toggle -g b on")
+		out = term.MustExec("clear -g a")
+		if n := strings.Count(out, " cleared at "); n != 2 {
+			t.Errorf("wrong number of breakpoints cleared: %q", out)
+		}
+		term.AssertExecError("clear -g a", "no breakpoints in group a")
+	})
+}
+
+func TestClearAllCmd(t *testing.T) {
+	withTestTerminal("testtoggle", t, func(term *FakeTerminal) {
+		term.MustExec("break main.main")
+		term.MustExec("break main.lineOne")
+		term.MustExec("break main.lineTwo")
+		term.MustExec("break main.lineThree")
+		out := term.MustExec("clearall -func main.lineOne")
+		if n := strings.Count(out, " cleared at "); n != 1 {
+			t.Errorf("wrong number of breakpoints cleared: %q", out)
+		}
+		out = term.MustExec("clearall main.lineTwo")
+		if n := strings.Count(out, " cleared at "); n != 1 {
+			t.Errorf("wrong number of breakpoints cleared: %q", out)
+		}
+		out = term.MustExec("clearall -file testtoggle.go")
+		if n := strings.Count(out, " cleared at "); n != 2 {
+			t.Errorf("wrong number of breakpoints cleared: %q", out)
+		}
+		term.AssertExecError("clearall -file", "not enough arguments")
+	})
+}
+
+func TestProcessCmd(t *testing.T) {
+	withTestTerminal("testnextprog", t, func(term *FakeTerminal) {
+		out := term.MustExec("process")
+		t.Logf("%s", out)
+		if !strings.Contains(out, fmt.Sprintf("Pid:\t\t%d\n", term.client.ProcessPid())) {
+			t.Errorf("pid not printed")
+		}
+		if !strings.Contains(out, "Executable:\t") || !strings.Contains(out, "Command line:\t") {
+			t.Errorf("executable or command line not printed")
+		}
+		if runtime.GOOS == "linux" && !strings.Contains(out, "Directory:\t") {
+			t.Errorf("working directory not printed")
+		}
+		term.AssertExecError("process 1", "too many arguments")
+	})
+}
+
+func TestBuildInfoCmd(t *testing.T) {
+	withTestTerminal("testnextprog", t, func(term *FakeTerminal) {
+		out := term.MustExec("buildinfo")
+		t.Logf("%s", out)
+		if !strings.Contains(out, "\tgo\t"+runtime.Version()+"\n") {
+			t.Errorf("go version not printed")
+		}
+		term.AssertExecError("buildinfo 1", "too many arguments")
+	})
+}
+
+func TestCommandHooks(t *testing.T) {
+	var term Term
+	term.conf = &config.Config{}
+	term.cmds = DebugCommands(nil)
+
+	mustCall := func(cmdstr string) {
+		t.Helper()
+		if err := term.cmds.Call(cmdstr, &term); err != nil {
+			t.Fatalf("error executing %q: %v", cmdstr, err)
+		}
+	}
+
+	mustCall("config hook post source config max-string-len 7")
+	if len(term.conf.Hooks["source"].Post) != 1 {
+		t.Fatalf("hook not set: %v", term.conf.Hooks)
+	}
+	if err := term.cmds.Call("config hook post nonexistent-command help", &term); err == nil {
+		t.Fatalf("hook set on a nonexistent command")
+	}
+	if err := term.cmds.Call("source /nonexistent/script", &term); err == nil {
+		t.Fatalf("expected error executing source")
+	}
+	if term.conf.MaxStringLen != nil {
+		t.Fatalf("post hook executed after a failed command")
+	}
+
+	// the pre hook of "help" runs "config", which does not run its own hooks
+	mustCall("config hook pre help config max-array-values 3")
+	mustCall("config hook pre config config max-string-len 9")
+	term.conf.MaxStringLen = nil
+	mustCall("help")
+	if term.conf.MaxArrayValues == nil || *term.conf.MaxArrayValues != 3 {
+		t.Fatalf("pre hook not executed")
+	}
+	if term.conf.MaxStringLen != nil {
+		t.Fatalf("hooks of a command executed by a hook were executed")
+	}
+
+	mustCall("config hook pre help")
+	if _, ok := term.conf.Hooks["help"]; ok {
+		t.Fatalf("hooks not removed: %v", term.conf.Hooks)
+	}
+}
+
+func TestServerCommandHooks(t *testing.T) {
+	withTestTerminal("testvariables2", t, func(term *FakeTerminal) {
+		term.conf = &config.Config{}
+		if err := term.client.SetCommandHooks("help", api.CommandHooks{Pre: []string{"config max-array-values 5"}}); err != nil {
+			t.Fatal(err)
+		}
+		term.MustExec("help")
+		if term.conf.MaxArrayValues == nil || *term.conf.MaxArrayValues != 5 {
+			t.Fatalf("hook stored by the server not executed")
+		}
+		if err := term.client.SetCommandHooks("help", api.CommandHooks{}); err != nil {
+			t.Fatal(err)
+		}
+		hooks, err := term.client.ListCommandHooks()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(hooks) != 0 {
+			t.Fatalf("hooks not removed: %v", hooks)
+		}
+	})
+}
+
+func TestCommandHooksWithoutTerm(t *testing.T) {
+	// commands can be executed without a terminal, in which case there are
+	// no hooks to run
+	cmds := DebugCommands(nil)
+	err := cmds.Call("thread", nil)
+	if err == nil || err.Error() != "you must specify a thread" {
+		t.Fatalf("wrong error executing thread without a terminal: %v", err)
+	}
+}
+
+func TestHelpRelated(t *testing.T) {
+	cmds := DebugCommands(nil)
+	for i := range cmds.cmds {
+		cmd := &cmds.cmds[i]
+		for _, name := range cmd.related {
+			if cmds.find(name, noPrefix) == nil {
+				t.Errorf("command %q: unknown related command %q", cmd.aliases[0], name)
+			}
+		}
+	}
+	related := cmds.relatedCommands(cmds.find("b", noPrefix))
+	if len(related) == 0 || related[0] != "on" {
+		t.Errorf("wrong related commands for break: %v", related)
+	}
+}
+
+func TestExitCommandArgs(t *testing.T) {
+	for _, tc := range []struct {
+		args string
+		tgt  ExitBehavior
+	}{
+		{"", ExitAsk},
+		{"-c", ExitContinue},
+		{"-k", ExitKill},
+	} {
+		var term Term
+		term.cmds = DebugCommands(nil)
+		err := term.cmds.Call(strings.TrimSpace("exit "+tc.args), &term)
+		if _, ok := err.(ExitRequestError); !ok {
+			t.Errorf("%q: unexpected error %v", tc.args, err)
+		}
+		if term.quitBehavior != tc.tgt {
+			t.Errorf("%q: got %d expected %d", tc.args, term.quitBehavior, tc.tgt)
+		}
+	}
+
+	var term Term
+	term.cmds = DebugCommands(nil)
+	if _, ok := term.cmds.Call("exit -x", &term).(ExitRequestError); ok {
+		t.Errorf("wrong argument accepted")
+	}
+}
+
+func TestParsePrintFormat(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		f    printFormat
+		rest string
+		err  bool
+	}{
+		{"a", printFormat{}, "a", false},
+		{"%x a", printFormat{verb: "%x"}, "a", false},
+		{"-pretty a.b", printFormat{pretty: true}, "a.b", false},
+		{"-json %x a", printFormat{json: true, verb: "%x"}, "a", false},
+		{"-raw -pretty a", printFormat{raw: true, pretty: true}, "a", false},
+		{"-a", printFormat{}, "-a", false},
+		{"-pretty -json a", printFormat{}, "", true},
+		{"%x %d a", printFormat{}, "", true},
+	} {
+		f, rest, err := parsePrintFormat(tc.in)
+		if tc.err {
+			if err == nil {
+				t.Errorf("%q: expected error", tc.in)
+			}
+			continue
+		}
+		if err != nil || f != tc.f || rest != tc.rest {
+			t.Errorf("%q: got %#v %q %v", tc.in, f, rest, err)
+		}
+	}
+}
+
+func TestPrettyAndJSONOutput(t *testing.T) {
+	v := &api.Variable{Name: "s", Type: "main.S", Kind: reflect.Struct, Len: 3, Children: []api.Variable{
+		{Name: "A", Type: "int", Kind: reflect.Int, Value: "10"},
+		{Name: "B", Type: "string", Kind: reflect.String, Value: "x\"y", Len: 3},
+		{Name: "C", Type: "[]bool", Kind: reflect.Slice, Len: 1, Cap: 1, Base: 0x1000, Children: []api.Variable{
+			{Type: "bool", Kind: reflect.Bool, Value: "true"},
+		}},
+	}}
+
+	out := printFormat{json: true}.multiline(v, "")
+	tgt := "{\n\t\"A\": 10,\n\t\"B\": \"x\\\"y\",\n\t\"C\": [\n\t\ttrue\n\t]\n}"
+	if out != tgt {
+		t.Errorf("json output mismatch, got:\n%s\nexpected:\n%s", out, tgt)
+	}
+
+	out = printFormat{json: true, verb: "%x"}.multiline(&v.Children[0], "")
+	if out != `"a"` {
+		t.Errorf("json output with verb mismatch: %s", out)
+	}
+
+	out = printFormat{pretty: true}.multiline(v, "")
+	tgt = "main.S {\n\tA: 10,\n\tB: \"x\\\"y\",\n\tC: []bool len: 1, cap: 1, [\n\t\ttrue,\n\t],\n}"
+	if out != tgt {
+		t.Errorf("pretty output mismatch, got:\n%s\nexpected:\n%s", out, tgt)
+	}
+}
+
+func TestDiffVariables(t *testing.T) {
+	mkstruct := func(a, b string, elems ...string) *api.Variable {
+		v := &api.Variable{Type: "main.S", Kind: reflect.Struct, Len: 2, Children: []api.Variable{
+			{Name: "A", Type: "int", Kind: reflect.Int, Value: a},
+			{Name: "B", Type: "string", Kind: reflect.String, Value: b, Len: int64(len(b))},
+			{Name: "C", Type: "[]int", Kind: reflect.Slice, Len: int64(len(elems)), Cap: int64(len(elems)), Base: 0x1000},
+		}}
+		for _, e := range elems {
+			v.Children[2].Children = append(v.Children[2].Children, api.Variable{Type: "int", Kind: reflect.Int, Value: e})
+		}
+		return v
+	}
+
+	changes := diffVariables("s", mkstruct("1", "x", "1"), mkstruct("1", "x", "1"), nil)
+	if len(changes) != 0 {
+		t.Errorf("unexpected changes: %v", changes)
+	}
+
+	changes = diffVariables("s", mkstruct("1", "x", "1"), mkstruct("2", "x", "1", "3"), nil)
+	tgt := []variableChange{
+		{"s.A", "1", "2"},
+		{"s.C (len, cap)", "1, 1", "2, 2"},
+		{"s.C[1]", "<missing>", "3"},
+	}
+	if !reflect.DeepEqual(changes, tgt) {
+		t.Errorf("got %v expected %v", changes, tgt)
+	}
+}
+
+func TestDiffCommand(t *testing.T) {
+	withTestTerminal("break", t, func(term *FakeTerminal) {
+		term.MustExec("break main.main:6")
+		term.MustExec("continue")
+		term.MustExec("diff i")
+		out := term.MustExec("continue")
+		if !strings.Contains(out, "diff 0: i\n\ti: 0 => 1\n") {
+			t.Errorf("change not printed: %q", out)
+		}
+		term.MustExec("diff -d 0")
+		out = term.MustExec("continue")
+		if strings.Contains(out, "diff 0") {
+			t.Errorf("change printed after removing the expression: %q", out)
+		}
+	})
+}
+
+func TestWriteCoverProfile(t *testing.T) {
+	var buf bytes.Buffer
+	err := writeCoverProfile(&buf, []api.CoverageLine{
+		{File: "/src/a.go", Line: 3, Executed: true},
+		{File: "/src/a.go", Line: 4},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tgt := "mode: set\n/src/a.go:3.1,4.1 1 1\n/src/a.go:4.1,5.1 1 0\n"
+	if buf.String() != tgt {
+		t.Errorf("got %q expected %q", buf.String(), tgt)
+	}
+}
+
+func TestPrintBranchTrace(t *testing.T) {
+	loc := func(fn, file string, line int) api.Location {
+		return api.Location{PC: 0x1000, File: file, Line: line, Function: &api.Function{Name_: fn}}
+	}
+	evs := []api.BranchTraceEvent{
+		{Kind: api.BranchTraceReturn, From: loc("main.g", "/src/main.go", 5), To: loc("main.main", "/src/main.go", 20)},
+		{Kind: api.BranchTraceCall, From: loc("main.main", "/src/main.go", 21), To: loc("main.f", "/src/main.go", 10)},
+		{Kind: api.BranchTraceGap, From: loc("main.f", "/src/main.go", 11)},
+	}
+	var buf bytes.Buffer
+	term := &Term{conf: &config.Config{}}
+	term.printBranchTrace(&buf, evs)
+	tgt := "return main.g /src/main.go:5 -> main.main /src/main.go:20\n" +
+		"call   main.main /src/main.go:21 -> main.f /src/main.go:10\n" +
+		"  ... trace lost after main.f /src/main.go:11\n"
+	if buf.String() != tgt {
+		t.Errorf("got:\n%s\nexpected:\n%s", buf.String(), tgt)
+	}
+}
+
+func TestDiffGoroutines(t *testing.T) {
+	gs := func(ids ...int) map[int]*api.Goroutine {
+		r := make(map[int]*api.Goroutine)
+		for _, id := range ids {
+			r[id] = &api.Goroutine{ID: id}
+		}
+		return r
+	}
+	ids := func(gs []*api.Goroutine) []int {
+		r := []int{}
+		for _, g := range gs {
+			r = append(r, g.ID)
+		}
+		return r
+	}
+	created, exited := diffGoroutines(gs(1, 2, 5, 7), gs(1, 7, 9, 8, 12))
+	if !reflect.DeepEqual(ids(created), []int{8, 9, 12}) {
+		t.Errorf("created: got %v", ids(created))
+	}
+	if !reflect.DeepEqual(ids(exited), []int{2, 5}) {
+		t.Errorf("exited: got %v", ids(exited))
+	}
+}
+
+func TestFormatHitCounts(t *testing.T) {
+	out := formatHitCounts(map[string]uint64{"10": 2, "1": 5, "2": 1})
+	if tgt := "goroutine(1):5 goroutine(2):1 goroutine(10):2"; out != tgt {
+		t.Errorf("got %q expected %q", out, tgt)
+	}
+	if out := formatHitCounts(nil); out != "" {
+		t.Errorf("got %q for no hits", out)
+	}
+}
+
+func TestReportCommand(t *testing.T) {
+	withTestTerminal("panic", t, func(term *FakeTerminal) {
+		term.MustExec("continue")
+
+		dir, err := ioutil.TempDir("", "delve-report")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+
+		mdpath := filepath.Join(dir, "report.md")
+		term.MustExec("report " + mdpath)
+		buf, err := ioutil.ReadFile(mdpath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		md := string(buf)
+		t.Logf("%s", md)
+		for _, tgt := range []string{"- Stop reason: panic", "BOOM!", "## Current goroutine", "main.main", "msg = ", "## Registers", "## Goroutines ("} {
+			if !strings.Contains(md, tgt) {
+				t.Errorf("report does not contain %q", tgt)
+			}
+		}
+
+		jsonpath := filepath.Join(dir, "report.json")
+		term.MustExec("report -json -frames 0 " + jsonpath)
+		buf, err = ioutil.ReadFile(jsonpath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var r crashReport
+		if err := json.Unmarshal(buf, &r); err != nil {
+			t.Fatal(err)
+		}
+		if r.StopReason.Kind != api.StopPanic || len(r.Stack) == 0 || len(r.Goroutines) == 0 || r.BuildInfo == nil {
+			t.Errorf("unexpected report %#v", r)
+		}
+		for _, frame := range r.Stack {
+			if len(frame.Locals) > 0 {
+				t.Errorf("local variables loaded with -frames 0")
+			}
+		}
+	})
+}
+
+func TestPrettyPrinters(t *testing.T) {
+	mkvar := func() *api.Variable {
+		return &api.Variable{Name: "v", Type: "main.S", Kind: reflect.Struct, Len: 1, Children: []api.Variable{
+			{Name: "P", Type: "main.Point", Kind: reflect.Struct, Addr: 0x1000, Len: 2, Children: []api.Variable{
+				{Name: "X", Type: "int", Kind: reflect.Int, Value: "1"},
+				{Name: "Y", Type: "int", Kind: reflect.Int, Value: "2"},
+			}},
+		}}
+	}
+	term := &Term{}
+	term.registerPrettyPrinter("main.Point", func(t *Term, v *api.Variable) (string, error) {
+		return fmt.Sprintf("(%s, %s)", v.Children[0].Value, v.Children[1].Value), nil
+	})
+
+	v := mkvar()
+	term.applyPrettyPrinters(v, printFormat{})
+	if s := v.SinglelineString(); s != "main.S {P: main.Point((1, 2))}" {
+		t.Errorf("pretty-printed: %q", s)
+	}
+
+	v = mkvar()
+	term.applyPrettyPrinters(v, printFormat{raw: true})
+	if s := v.SinglelineString(); s != "main.S {P: main.Point {X: 1, Y: 2}}" {
+		t.Errorf("raw: %q", s)
+	}
+
+	term.registerPrettyPrinter("main.Point", nil)
+	v = mkvar()
+	term.applyPrettyPrinters(v, printFormat{})
+	if s := v.SinglelineString(); s != "main.S {P: main.Point {X: 1, Y: 2}}" {
+		t.Errorf("disabled: %q", s)
+	}
+}
+
+func TestBuiltinPrettyPrinters(t *testing.T) {
+	withTestTerminal("prettyprinters", t, func(term *FakeTerminal) {
+		term.MustExec("continue")
+		for _, tc := range []struct{ expr, tgt string }{
+			{"tm", "time.Time(2021-03-04 10:00:00 +0000 UTC)"},
+			{"tmfixed", "time.Time(2021-03-04 13:00:00 +0300 XYZ)"},
+			{"n", "math/big.Int(-123456789012345678901234567890)"},
+			{"ip", "net.IP(192.168.1.1)"},
+			{"ip6", "net.IP(2001:db8::1)"},
+			{"buf", `bytes.Buffer("world")`},
+			{"m", `sync.Map(["a": 1, "b": 2])`},
+		} {
+			out := strings.TrimSpace(term.MustExec("print " + tc.expr))
+			if out != tc.tgt {
+				t.Errorf("print %s: got %q, expected %q", tc.expr, out, tc.tgt)
+			}
+		}
+	})
+}
+
+func TestXdumpCmd(t *testing.T) {
+	withTestTerminal("testvariables2", t, func(term *FakeTerminal) {
+		term.MustExec("continue")
+		out := term.MustExec("xdump byteslice")
+		tgt := "00000000  74 c3 a8 73 74                                    |t..st|\n"
+		if out != tgt {
+			t.Errorf("wrong output for byteslice:\n%s", out)
+		}
+		if out := term.MustExec("xdump &bytearray"); out != tgt {
+			t.Errorf("wrong output for &bytearray:\n%s", out)
+		}
+		out = term.MustExec("xdump longstr")
+		if !strings.HasPrefix(out, "00000000  76 65 72 79 20 6c 6f 6e  67 20 73 74 72 69 6e 67  |very long string|\n") || !strings.Contains(out, "\n00000080  ") {
+			t.Errorf("wrong output for longstr:\n%s", out)
+		}
+		if _, err := term.Exec("xdump runeslice"); err == nil || !strings.Contains(err.Error(), "not a byte slice") {
+			t.Errorf("unexpected error for runeslice: %v", err)
+		}
+	})
+}
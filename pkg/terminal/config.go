@@ -106,6 +106,9 @@ func configureSet(t *Term, args string) error {
 	if cfgname == "alias" {
 		return configureSetAlias(t, rest)
 	}
+	if cfgname == "hook" {
+		return configureSetHook(t, rest)
+	}
 
 	field := configureFindFieldByName(t.conf, cfgname)
 	if !field.CanAddr() {
@@ -202,3 +205,38 @@ func configureSetAlias(t *Term, rest string) error {
 	t.cmds.Merge(t.conf.Aliases)
 	return nil
 }
+
+func configureSetHook(t *Term, rest string) error {
+	v := strings.SplitN(rest, " ", 3)
+	if len(v) < 2 {
+		return fmt.Errorf("not enough arguments to \"config hook\"")
+	}
+	kind := v[0]
+	if kind != "pre" && kind != "post" {
+		return fmt.Errorf("unknown hook kind %q, must be pre or post", kind)
+	}
+	cmd := t.cmds.find(v[1], noPrefix)
+	if cmd == nil {
+		return fmt.Errorf("unknown command %q", v[1])
+	}
+	name := cmd.aliases[0]
+	if t.conf.Hooks == nil {
+		t.conf.Hooks = make(map[string]config.CommandHooks)
+	}
+	hooks := t.conf.Hooks[name]
+	hookp := &hooks.Pre
+	if kind == "post" {
+		hookp = &hooks.Post
+	}
+	if len(v) == 3 && strings.TrimSpace(v[2]) != "" {
+		*hookp = append(*hookp, strings.TrimSpace(v[2]))
+	} else {
+		*hookp = nil
+	}
+	if len(hooks.Pre) == 0 && len(hooks.Post) == 0 {
+		delete(t.conf.Hooks, name)
+	} else {
+		t.conf.Hooks[name] = hooks
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+package terminal
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+// diffEntry is an expression tracked by the diff command, val is the
+// value it had at the last stop.
+type diffEntry struct {
+	expr string
+	val  *api.Variable
+}
+
+// variableChange is a difference between two values of an expression.
+type variableChange struct {
+	path     string
+	old, new string
+}
+
+func (t *Term) addDiff(expr string) error {
+	val, err := t.client.EvalVariable(api.EvalScope{GoroutineID: -1}, expr, t.loadConfig())
+	if err != nil {
+		return err
+	}
+	t.diffs = append(t.diffs, diffEntry{expr: expr, val: val})
+	return nil
+}
+
+func (t *Term) removeDiff(n int) error {
+	if n < 0 || n >= len(t.diffs) || t.diffs[n].expr == "" {
+		return fmt.Errorf("%d is out of range", n)
+	}
+	t.diffs[n] = diffEntry{}
+	for i := len(t.diffs) - 1; i >= 0; i-- {
+		if t.diffs[i].expr != "" {
+			t.diffs = t.diffs[:i+1]
+			return nil
+		}
+	}
+	t.diffs = t.diffs[:0]
+	return nil
+}
+
+// printDiffs evaluates the expressions tracked by the diff command and
+// prints the parts of their values that changed since the last stop.
+func (t *Term) printDiffs() {
+	for i := range t.diffs {
+		d := &t.diffs[i]
+		if d.expr == "" {
+			continue
+		}
+		val, err := t.client.EvalVariable(api.EvalScope{GoroutineID: -1}, d.expr, t.loadConfig())
+		if err != nil {
+			if isErrProcessExited(err) {
+				return
+			}
+			fmt.Printf("diff %d: %s = error %v\n", i, d.expr, err)
+			continue
+		}
+		changes := diffVariables(d.expr, d.val, val, nil)
+		d.val = val
+		if len(changes) == 0 {
+			continue
+		}
+		fmt.Printf("diff %d: %s\n", i, d.expr)
+		for _, c := range changes {
+			fmt.Printf("\t%s: %s => %s\n", c.path, c.old, c.new)
+		}
+	}
+}
+
+// diffVariables appends to changes the differences between old and new,
+// two values of the expression path. Structs, arrays, slices, maps,
+// pointers and interfaces are compared member by member, so that only the
+// fields and elements that changed are reported.
+func diffVariables(path string, old, new *api.Variable, changes []variableChange) []variableChange {
+	report := func() []variableChange {
+		o, n := old.SinglelineString(), new.SinglelineString()
+		if o == n {
+			return changes
+		}
+		return append(changes, variableChange{path, o, n})
+	}
+
+	if old.Unreadable != "" || new.Unreadable != "" || old.Kind != new.Kind || old.Type != new.Type {
+		return report()
+	}
+
+	switch new.Kind {
+	case reflect.Struct:
+		if len(old.Children) != len(new.Children) {
+			return report()
+		}
+		for i := range new.Children {
+			changes = diffVariables(path+"."+new.Children[i].Name, &old.Children[i], &new.Children[i], changes)
+		}
+		return changes
+
+	case reflect.Array, reflect.Slice:
+		if new.Kind == reflect.Slice && (old.Len != new.Len || old.Cap != new.Cap) {
+			changes = append(changes, variableChange{path + " (len, cap)", fmt.Sprintf("%d, %d", old.Len, old.Cap), fmt.Sprintf("%d, %d", new.Len, new.Cap)})
+		}
+		n := len(old.Children)
+		if len(new.Children) < n {
+			n = len(new.Children)
+		}
+		for i := 0; i < n; i++ {
+			changes = diffVariables(fmt.Sprintf("%s[%d]", path, i), &old.Children[i], &new.Children[i], changes)
+		}
+		for i := n; i < len(new.Children); i++ {
+			changes = append(changes, variableChange{fmt.Sprintf("%s[%d]", path, i), "<missing>", new.Children[i].SinglelineString()})
+		}
+		for i := n; i < len(old.Children); i++ {
+			changes = append(changes, variableChange{fmt.Sprintf("%s[%d]", path, i), old.Children[i].SinglelineString(), "<missing>"})
+		}
+		return changes
+
+	case reflect.Map:
+		oldEntries := make(map[string]*api.Variable)
+		for i := 0; i+1 < len(old.Children); i += 2 {
+			oldEntries[old.Children[i].SinglelineString()] = &old.Children[i+1]
+		}
+		for i := 0; i+1 < len(new.Children); i += 2 {
+			key := new.Children[i].SinglelineString()
+			keypath := fmt.Sprintf("%s[%s]", path, key)
+			if o := oldEntries[key]; o != nil {
+				changes = diffVariables(keypath, o, &new.Children[i+1], changes)
+				delete(oldEntries, key)
+			} else {
+				changes = append(changes, variableChange{keypath, "<missing>", new.Children[i+1].SinglelineString()})
+			}
+		}
+		for i := 0; i+1 < len(old.Children); i += 2 {
+			key := old.Children[i].SinglelineString()
+			if o := oldEntries[key]; o != nil {
+				changes = append(changes, variableChange{fmt.Sprintf("%s[%s]", path, key), o.SinglelineString(), "<missing>"})
+			}
+		}
+		return changes
+
+	case reflect.Ptr:
+		if len(old.Children) != 1 || len(new.Children) != 1 || old.Children[0].Addr != new.Children[0].Addr {
+			return append(changes, variableChange{path, fmt.Sprintf("(%s)(%#x)", old.Type, pointerTarget(old)), fmt.Sprintf("(%s)(%#x)", new.Type, pointerTarget(new))})
+		}
+		return diffVariables(path, &old.Children[0], &new.Children[0], changes)
+
+	case reflect.Interface:
+		if len(old.Children) != 1 || len(new.Children) != 1 || old.Children[0].Type != new.Children[0].Type {
+			return report()
+		}
+		return diffVariables(path, &old.Children[0], &new.Children[0], changes)
+	}
+
+	return report()
+}
+
+// pointerTarget returns the address pointed to by v.
+func pointerTarget(v *api.Variable) uint64 {
+	if len(v.Children) != 1 {
+		return 0
+	}
+	return v.Children[0].Addr
+}
+
+// goroutinesSnapshots are the goroutines that existed at the previous and
+// at the current stop, indexed by ID.
+type goroutinesSnapshots struct {
+	prev, cur map[int]*api.Goroutine
+}
+
+// snapshotGoroutines records the goroutines of the current stop, the ones
+// recorded before become the ones of the previous stop.
+func (t *Term) snapshotGoroutines() error {
+	s := t.grsSnapshots
+	s.prev, s.cur = s.cur, nil
+	gs, _, err := t.client.ListGoroutines(0, 0)
+	if err != nil {
+		return err
+	}
+	s.cur = make(map[int]*api.Goroutine, len(gs))
+	for _, g := range gs {
+		s.cur[g.ID] = g
+	}
+	return nil
+}
+
+// printGoroutinesDiff prints the goroutines created and exited between the
+// previous stop and the current one. The first time it is called it starts
+// recording the goroutines at every stop.
+func (t *Term) printGoroutinesDiff(fgl formatGoroutineLoc, flags printGoroutinesFlags, depth, ancestors int, state *api.DebuggerState) error {
+	if t.grsSnapshots == nil {
+		t.grsSnapshots = &goroutinesSnapshots{}
+		if err := t.snapshotGoroutines(); err != nil {
+			t.grsSnapshots = nil
+			return err
+		}
+		fmt.Printf("Recorded %d goroutines, continue the program and use 'goroutines -diff' again to see which goroutines were created or exited\n", len(t.grsSnapshots.cur))
+		return nil
+	}
+	s := t.grsSnapshots
+	if s.prev == nil || s.cur == nil {
+		return fmt.Errorf("goroutines were not recorded at the previous stop")
+	}
+	created, exited := diffGoroutines(s.prev, s.cur)
+	if len(created) > 0 {
+		fmt.Printf("Created:\n")
+		if err := printGoroutines(t, "", created, fgl, flags, depth, ancestors, state, nil); err != nil {
+			return err
+		}
+	}
+	if len(exited) > 0 {
+		// exited goroutines only have the information recorded at the
+		// previous stop.
+		fmt.Printf("Exited:\n")
+		if err := printGoroutines(t, "", exited, fgl, flags&printGoroutinesLabels, 0, 0, state, nil); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("[%d goroutines, %d created, %d exited]\n", len(s.cur), len(created), len(exited))
+	return nil
+}
+
+// diffGoroutines returns the goroutines in cur but not in prev and the
+// ones in prev but not in cur, sorted by ID.
+func diffGoroutines(prev, cur map[int]*api.Goroutine) (created, exited []*api.Goroutine) {
+	for id, g := range cur {
+		if prev[id] == nil {
+			created = append(created, g)
+		}
+	}
+	for id, g := range prev {
+		if cur[id] == nil {
+			exited = append(exited, g)
+		}
+	}
+	sort.Sort(byGoroutineID(created))
+	sort.Sort(byGoroutineID(exited))
+	return created, exited
+}
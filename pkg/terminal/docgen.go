@@ -31,9 +31,14 @@ func (commands *Commands) WriteMarkdown(w io.Writer) {
 	fmt.Fprint(w, "If `$XDG_CONFIG_HOME` is set, then configuration and command history files are located in `$XDG_CONFIG_HOME/dlv`. ")
 	fmt.Fprint(w, "Otherwise, they are located in `$HOME/.config/dlv` on Linux and `$HOME/.dlv` on other systems.\n\n")
 	fmt.Fprint(w, "The configuration file `config.yml` contains all the configurable options and their default values. ")
-	fmt.Fprint(w, "The command history is stored in `.dbg_history`.\n\n")
+	fmt.Fprint(w, "The command history of each working directory is stored in a separate file in the `history` directory, ")
+	fmt.Fprint(w, "or in `.dbg_history` if the `global-history` option is set. Duplicate commands are only saved once and at most `history-size` commands are kept. ")
+	fmt.Fprint(w, "Press Ctrl-R at the prompt to search the history.\n\n")
 
 	fmt.Fprint(w, "# Commands\n")
+	fmt.Fprint(w, "\nA command can be continued on the next line by ending a line with a backslash, ")
+	fmt.Fprint(w, "lines containing unbalanced parenthesis, brackets or braces are continued automatically. ")
+	fmt.Fprint(w, "This also applies to the commands of files executed by `source`.\n")
 
 	for _, cgd := range commandGroupDescriptions {
 		fmt.Fprintf(w, "\n## %s\n\n", cgd.description)
@@ -54,8 +59,19 @@ func (commands *Commands) WriteMarkdown(w io.Writer) {
 
 	}
 
-	for _, cmd := range commands.cmds {
+	for i := range commands.cmds {
+		cmd := &commands.cmds[i]
 		fmt.Fprintf(w, "## %s\n%s\n\n", cmd.aliases[0], replaceDocPath(cmd.helpMsg))
+		if related := commands.relatedCommands(cmd); len(related) > 0 {
+			fmt.Fprint(w, "See also:")
+			for j, name := range related {
+				if j > 0 {
+					fmt.Fprint(w, ",")
+				}
+				fmt.Fprintf(w, " [%s](#%s)", name, name)
+			}
+			fmt.Fprint(w, "\n\n")
+		}
 		if len(cmd.aliases) > 1 {
 			fmt.Fprint(w, "Aliases:")
 			for _, alias := range cmd.aliases[1:] {
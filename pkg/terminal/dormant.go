@@ -0,0 +1,186 @@
+package terminal
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/go-delve/delve/pkg/config"
+	"github.com/go-delve/delve/service/api"
+)
+
+// dormantBreakpointsDir is the directory, inside the configuration
+// directory, where the breakpoints saved on detach are kept, one file for
+// each executable.
+const dormantBreakpointsDir = "breakpoints"
+
+// dormantBreakpointsPath returns the path of the file where the
+// breakpoints set on the executable with the given build ID are saved.
+func dormantBreakpointsPath(buildID string) (string, error) {
+	dir, err := config.GetConfigFilePath(dormantBreakpointsDir)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, fmt.Sprintf("%x", sha256.Sum256([]byte(buildID)))[:16]+".dlv"), nil
+}
+
+// dormantBreakpointsFile returns the path of the file where the
+// breakpoints of the target are saved or the empty string if dormant
+// breakpoints are disabled or can not be used with the current target.
+func (t *Term) dormantBreakpointsFile() string {
+	if t.conf == nil || !t.conf.DormantBreakpoints || !t.client.AttachedToExistingProcess() {
+		return ""
+	}
+	v, err := t.client.GetVersion()
+	if err != nil || v.TargetBuildID == "" {
+		return ""
+	}
+	path, err := dormantBreakpointsPath(v.TargetBuildID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to find breakpoints file: %v\n", err)
+		return ""
+	}
+	return path
+}
+
+// restoreDormantBreakpoints executes the breakpoints file saved the last
+// time the terminal detached from a process running the same executable,
+// the file is deleted afterwards.
+func (t *Term) restoreDormantBreakpoints() error {
+	path := t.dormantBreakpointsFile()
+	if path == "" {
+		return nil
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+	if state, err := t.client.GetStateNonBlocking(); err == nil && state.Running {
+		// breakpoints can only be created while the target is stopped, for
+		// example when Delve attached with --continue
+		if _, err := t.client.Halt(); err != nil {
+			return err
+		}
+		defer func() {
+			go func() {
+				for range t.client.Continue() {
+				}
+			}()
+		}()
+	}
+	fmt.Printf("Restoring breakpoints from %s\n", path)
+	err := t.cmds.executeFile(t, path)
+	if rmerr := os.Remove(path); rmerr != nil && err == nil {
+		err = rmerr
+	}
+	return err
+}
+
+// saveDormantBreakpoints saves the breakpoints of the target, as a list of
+// commands, before detaching from a process that will keep running.
+func (t *Term) saveDormantBreakpoints() {
+	path := t.dormantBreakpointsFile()
+	if path == "" {
+		return
+	}
+	bps, err := t.client.ListBreakpoints()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to save breakpoints: %v\n", err)
+		return
+	}
+	script := t.breakpointsScript(bps)
+	if len(script) == 0 {
+		os.Remove(path)
+		return
+	}
+	if err := ioutil.WriteFile(path, script, 0600); err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to save breakpoints: %v\n", err)
+		return
+	}
+	fmt.Printf("Breakpoints saved to %s, they will be restored the next time Delve attaches to this executable\n", path)
+}
+
+// breakpointsScript returns the commands that recreate bps. Watchpoints
+// are not saved, neither are breakpoints without a source location: their
+// address changes when a position independent executable is loaded at a
+// different address. The commands following the creation of a breakpoint
+// without a name refer to it as $.
+func (t *Term) breakpointsScript(bps []*api.Breakpoint) []byte {
+	bps = append([]*api.Breakpoint(nil), bps...)
+	sort.Sort(byID(bps))
+	var buf bytes.Buffer
+	for _, bp := range bps {
+		if bp.ID <= 0 || bp.WatchExpr != "" || bp.TraceReturn || bp.File == "" {
+			continue
+		}
+		loadArgs := bp.LoadArgs != nil && !(bp.Tracepoint && *bp.LoadArgs == ShortLoadConfig)
+		ref := bp.Name
+		if ref == "" {
+			ref = "$"
+		}
+
+		if bp.Tracepoint {
+			buf.WriteString("trace")
+		} else {
+			buf.WriteString("break")
+		}
+		for _, g := range bp.Groups {
+			fmt.Fprintf(&buf, " -g %s", g)
+		}
+		if bp.Name != "" {
+			fmt.Fprintf(&buf, " %s", bp.Name)
+		}
+		fmt.Fprintf(&buf, " %s:%d", bp.File, bp.Line)
+		if bp.Caller != "" {
+			fmt.Fprintf(&buf, " -caller %s", bp.Caller)
+		}
+		if bp.Cond != "" {
+			fmt.Fprintf(&buf, " if %s", bp.Cond)
+		}
+		buf.WriteString("\n")
+
+		if bp.HitCond != "" {
+			fmt.Fprintf(&buf, "condition -hitcount %s %s\n", ref, bp.HitCond)
+		}
+		if bp.Stacktrace > 0 {
+			fmt.Fprintf(&buf, "on %s stack %d\n", ref, bp.Stacktrace)
+		}
+		if bp.Goroutine {
+			fmt.Fprintf(&buf, "on %s goroutine\n", ref)
+		}
+		if loadArgs {
+			if *bp.LoadArgs == longLoadConfig {
+				fmt.Fprintf(&buf, "on %s args -v\n", ref)
+			} else {
+				fmt.Fprintf(&buf, "on %s args\n", ref)
+			}
+		}
+		if bp.LoadLocals != nil {
+			if *bp.LoadLocals == longLoadConfig {
+				fmt.Fprintf(&buf, "on %s locals -v\n", ref)
+			} else {
+				fmt.Fprintf(&buf, "on %s locals\n", ref)
+			}
+		}
+		for _, expr := range bp.Variables {
+			if format := t.breakpointVarFormat(bp.ID, expr).String(); format != "" {
+				fmt.Fprintf(&buf, "on %s print %s %s\n", ref, format, expr)
+			} else {
+				fmt.Fprintf(&buf, "on %s print %s\n", ref, expr)
+			}
+		}
+		if bp.WatchHistory > 0 {
+			fmt.Fprintf(&buf, "on %s history %d\n", ref, bp.WatchHistory)
+		}
+		if bp.Disabled {
+			fmt.Fprintf(&buf, "toggle %s\n", ref)
+		}
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,303 @@
+package terminal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+// printFormat is the output format of a variable, it is shared by print,
+// display and the variables printed when a breakpoint or a tracepoint is
+// hit.
+type printFormat struct {
+	// verb is a format verb of the fmt package, like %x, applied to
+	// basic values.
+	verb string
+	// pretty prints every field, element and map entry on its own line.
+	pretty bool
+	// json prints the variable tree as JSON.
+	json bool
+	// raw disables the pretty-printers, see applyPrettyPrinters.
+	raw bool
+}
+
+// parsePrintFormat parses the format options at the start of args:
+// a format verb (%x), -pretty, -json and -raw. It returns the format and
+// the remainder of args.
+func parsePrintFormat(args string) (printFormat, string, error) {
+	var f printFormat
+	for {
+		args = strings.TrimSpace(args)
+		var opt string
+		if v := strings.SplitN(args, " ", 2); len(v) > 0 {
+			opt = v[0]
+		}
+		switch {
+		case strings.HasPrefix(opt, "%"):
+			if f.verb != "" {
+				return f, "", fmt.Errorf("more than one format verb")
+			}
+			f.verb = opt
+		case opt == "-pretty":
+			f.pretty = true
+		case opt == "-json":
+			f.json = true
+		case opt == "-raw":
+			f.raw = true
+		default:
+			if f.pretty && f.json {
+				return f, "", fmt.Errorf("-pretty and -json can not be used together")
+			}
+			return f, args, nil
+		}
+		args = args[len(opt):]
+	}
+}
+
+// String returns the options that, parsed by parsePrintFormat, produce f.
+func (f printFormat) String() string {
+	var opts []string
+	if f.pretty {
+		opts = append(opts, "-pretty")
+	}
+	if f.json {
+		opts = append(opts, "-json")
+	}
+	if f.raw {
+		opts = append(opts, "-raw")
+	}
+	if f.verb != "" {
+		opts = append(opts, f.verb)
+	}
+	return strings.Join(opts, " ")
+}
+
+// multiline returns the representation of v used by print, lines after the
+// first one are prefixed by indent.
+func (f printFormat) multiline(v *api.Variable, indent string) string {
+	switch {
+	case f.json:
+		return f.jsonString(v, indent)
+	case f.pretty:
+		var buf bytes.Buffer
+		f.writePretty(&buf, v, indent, true)
+		return buf.String()
+	default:
+		return v.MultilineString(indent, f.verb)
+	}
+}
+
+// singleline returns the representation of v used by display, the output
+// of -pretty and -json spans multiple lines nonetheless.
+func (f printFormat) singleline(v *api.Variable) string {
+	if f.json || f.pretty {
+		return f.multiline(v, "")
+	}
+	return v.SinglelineStringFormatted(f.verb)
+}
+
+// writePretty writes v to buf with every field of structs, element of
+// arrays and slices and entry of maps on a separate line.
+func (f printFormat) writePretty(buf *bytes.Buffer, v *api.Variable, indent string, includeType bool) {
+	if v.Unreadable != "" || !prettyExpandable(v) {
+		buf.WriteString(v.SinglelineStringFormatted(f.verb))
+		return
+	}
+
+	switch v.Kind {
+	case reflect.Ptr:
+		buf.WriteString("*")
+		f.writePretty(buf, &v.Children[0], indent, includeType)
+		return
+	case reflect.Interface:
+		if includeType {
+			fmt.Fprintf(buf, "%s(", v.Type)
+		}
+		f.writePretty(buf, &v.Children[0], indent, true)
+		if includeType {
+			buf.WriteString(")")
+		}
+		return
+	}
+
+	if includeType {
+		switch v.Kind {
+		case reflect.Slice:
+			fmt.Fprintf(buf, "%s len: %d, cap: %d, ", v.Type, v.Len, v.Cap)
+		default:
+			fmt.Fprintf(buf, "%s ", v.Type)
+		}
+	}
+
+	open, close := "[", "]"
+	if v.Kind == reflect.Struct {
+		open, close = "{", "}"
+	}
+	nested := indent + "\t"
+	buf.WriteString(open)
+	loaded := len(v.Children)
+	if v.Kind == reflect.Map {
+		for i := 0; i+1 < len(v.Children); i += 2 {
+			fmt.Fprintf(buf, "\n%s%s: ", nested, v.Children[i].SinglelineStringFormatted(f.verb))
+			f.writePretty(buf, &v.Children[i+1], nested, false)
+			buf.WriteString(",")
+		}
+		loaded = len(v.Children) / 2
+	} else {
+		for i := range v.Children {
+			buf.WriteString("\n" + nested)
+			if v.Kind == reflect.Struct {
+				fmt.Fprintf(buf, "%s: ", v.Children[i].Name)
+			}
+			f.writePretty(buf, &v.Children[i], nested, v.Kind == reflect.Struct)
+			buf.WriteString(",")
+		}
+	}
+	if int64(loaded) < v.Len {
+		fmt.Fprintf(buf, "\n%s...+%d more", nested, v.Len-int64(loaded))
+	}
+	buf.WriteString("\n" + indent + close)
+}
+
+// prettyExpandable returns true if v contains loaded children that
+// writePretty prints on separate lines.
+func prettyExpandable(v *api.Variable) bool {
+	switch v.Kind {
+	case reflect.Struct, reflect.Array, reflect.Slice, reflect.Map:
+		return len(v.Children) > 0
+	case reflect.Ptr, reflect.Interface:
+		return len(v.Children) == 1 && v.Children[0].Addr != 0 && prettyExpandable(&v.Children[0])
+	}
+	return false
+}
+
+// jsonString returns the JSON encoding of the value of v. Basic values are
+// encoded as JSON numbers, strings and booleans, unless a format verb is
+// specified, structs and maps are encoded as objects, arrays and slices as
+// arrays, nil pointers, slices, maps and interfaces as null.
+func (f printFormat) jsonString(v *api.Variable, indent string) string {
+	var buf, out bytes.Buffer
+	f.writeJSON(&buf, v)
+	if err := json.Indent(&out, buf.Bytes(), indent, "\t"); err != nil {
+		return buf.String()
+	}
+	return out.String()
+}
+
+func (f printFormat) writeJSON(buf *bytes.Buffer, v *api.Variable) {
+	writeString := func(s string) {
+		b, _ := json.Marshal(s)
+		buf.Write(b)
+	}
+
+	if v.Unreadable != "" {
+		buf.WriteString(`{"unreadable":`)
+		writeString(v.Unreadable)
+		buf.WriteString("}")
+		return
+	}
+
+	switch v.Kind {
+	case reflect.Struct:
+		if len(v.Children) == 0 && v.Len != 0 {
+			writeString(v.SinglelineString())
+			return
+		}
+		buf.WriteString("{")
+		for i := range v.Children {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			writeString(v.Children[i].Name)
+			buf.WriteString(":")
+			f.writeJSON(buf, &v.Children[i])
+		}
+		buf.WriteString("}")
+
+	case reflect.Array, reflect.Slice:
+		if v.Kind == reflect.Slice && v.Base == 0 && len(v.Children) == 0 {
+			buf.WriteString("null")
+			return
+		}
+		buf.WriteString("[")
+		for i := range v.Children {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			f.writeJSON(buf, &v.Children[i])
+		}
+		buf.WriteString("]")
+
+	case reflect.Map:
+		if v.Base == 0 && len(v.Children) == 0 {
+			buf.WriteString("null")
+			return
+		}
+		buf.WriteString("{")
+		for i := 0; i+1 < len(v.Children); i += 2 {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			key := &v.Children[i]
+			if key.Kind == reflect.String {
+				writeString(key.Value)
+			} else {
+				writeString(key.SinglelineStringFormatted(f.verb))
+			}
+			buf.WriteString(":")
+			f.writeJSON(buf, &v.Children[i+1])
+		}
+		buf.WriteString("}")
+
+	case reflect.Ptr, reflect.Interface:
+		if len(v.Children) == 0 || v.Children[0].Addr == 0 && v.Children[0].Value == "" && len(v.Children[0].Children) == 0 {
+			buf.WriteString("null")
+			return
+		}
+		f.writeJSON(buf, &v.Children[0])
+
+	case reflect.String:
+		writeString(v.Value)
+
+	case reflect.Bool:
+		if f.verb != "" {
+			writeString(v.SinglelineStringFormatted(f.verb))
+			return
+		}
+		buf.WriteString(v.Value)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		if f.verb == "" && json.Valid([]byte(v.Value)) {
+			buf.WriteString(v.Value)
+			return
+		}
+		writeString(v.SinglelineStringFormatted(f.verb))
+
+	default:
+		writeString(v.SinglelineStringFormatted(f.verb))
+	}
+}
+
+// setBreakpointVarFormat records the format used to print expr when
+// breakpoint id is hit, the expression itself is evaluated by the server.
+func (t *Term) setBreakpointVarFormat(id int, expr string, f printFormat) {
+	if t.bpVarFormats == nil {
+		t.bpVarFormats = make(map[int]map[string]printFormat)
+	}
+	if t.bpVarFormats[id] == nil {
+		t.bpVarFormats[id] = make(map[string]printFormat)
+	}
+	t.bpVarFormats[id][expr] = f
+}
+
+// breakpointVarFormat returns the format used to print expr when breakpoint
+// id is hit.
+func (t *Term) breakpointVarFormat(id int, expr string) printFormat {
+	return t.bpVarFormats[id][expr]
+}
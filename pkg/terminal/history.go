@@ -0,0 +1,112 @@
+package terminal
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/peterh/liner"
+
+	"github.com/go-delve/delve/pkg/config"
+)
+
+// historyDir is the directory, inside the configuration directory, where
+// the per-project history files are saved.
+const historyDir = "history"
+
+// historyFilePath returns the path of the history file of the current
+// working directory, or the path of the global history file if
+// conf.GlobalHistory is set.
+func historyFilePath(conf *config.Config) (string, error) {
+	if conf != nil && conf.GlobalHistory {
+		return config.GetConfigFilePath(historyFile)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	dir, err := config.GetConfigFilePath(historyDir)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, fmt.Sprintf("%x", sha256.Sum256([]byte(wd)))[:16]), nil
+}
+
+// historySize returns the maximum number of entries saved in the history
+// file.
+func (t *Term) historySize() int {
+	if t.conf == nil || t.conf.HistorySize == nil || *t.conf.HistorySize > liner.HistoryLimit {
+		return liner.HistoryLimit
+	}
+	if *t.conf.HistorySize < 0 {
+		return 0
+	}
+	return *t.conf.HistorySize
+}
+
+// loadHistory reads the history file into the line editor.
+func (t *Term) loadHistory() {
+	path, err := historyFilePath(t.conf)
+	if err != nil {
+		fmt.Printf("Unable to load history file: %v. History will not be saved for this session.\n", err)
+		return
+	}
+	t.historyPath = path
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			fmt.Printf("Unable to read history file: %v\n", err)
+		}
+		return
+	}
+	lines := dedupHistory(strings.Split(string(buf), "\n"), t.historySize())
+	if _, err := t.line.ReadHistory(strings.NewReader(strings.Join(lines, "\n"))); err != nil {
+		fmt.Printf("Unable to read history file: %v\n", err)
+	}
+}
+
+// saveHistory writes the history of the line editor to the history file,
+// without duplicate entries and truncated to the configured size.
+func (t *Term) saveHistory() {
+	if t.historyPath == "" {
+		return
+	}
+	var buf bytes.Buffer
+	if _, err := t.line.WriteHistory(&buf); err != nil {
+		fmt.Println("readline history error:", err)
+		return
+	}
+	lines := dedupHistory(strings.Split(buf.String(), "\n"), t.historySize())
+	out := strings.Join(lines, "\n")
+	if out != "" {
+		out += "\n"
+	}
+	if err := ioutil.WriteFile(t.historyPath, []byte(out), 0600); err != nil {
+		fmt.Printf("error writing history file: %s\n", err)
+	}
+}
+
+// dedupHistory removes empty lines and all but the most recent occurrence
+// of every entry from lines and keeps at most the last size entries.
+func dedupHistory(lines []string, size int) []string {
+	seen := make(map[string]bool)
+	r := []string{}
+	for i := len(lines) - 1; i >= 0 && len(r) < size; i-- {
+		if lines[i] == "" || seen[lines[i]] {
+			continue
+		}
+		seen[lines[i]] = true
+		r = append(r, lines[i])
+	}
+	for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+		r[i], r[j] = r[j], r[i]
+	}
+	return r
+}
@@ -0,0 +1,63 @@
+package terminal
+
+import (
+	"reflect"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+// A prettyPrinter returns a summary of the logical contents of v, a
+// variable of the type the printer is registered for, that print,
+// display and breakpoints show instead of the fields of v. The built-in
+// printers are api.BuiltinPrettyPrinters.
+type prettyPrinter func(t *Term, v *api.Variable) (string, error)
+
+// registerPrettyPrinter registers fn as the pretty-printer of the type
+// called typename, replacing the built-in one. A nil fn disables
+// pretty-printing of the type.
+func (t *Term) registerPrettyPrinter(typename string, fn prettyPrinter) {
+	if t.prettyPrinters == nil {
+		t.prettyPrinters = make(map[string]prettyPrinter)
+	}
+	t.prettyPrinters[typename] = fn
+}
+
+func (t *Term) prettyPrinter(typename string) prettyPrinter {
+	if fn, ok := t.prettyPrinters[typename]; ok {
+		return fn
+	}
+	if fn := api.BuiltinPrettyPrinters[typename]; fn != nil {
+		return func(t *Term, v *api.Variable) (string, error) {
+			return fn(t.evalForPrinter, v, t.loadConfig())
+		}
+	}
+	return nil
+}
+
+// evalForPrinter evaluates expr in the selected goroutine for the
+// built-in pretty-printers.
+func (t *Term) evalForPrinter(expr string, cfg api.LoadConfig) (*api.Variable, error) {
+	return t.client.EvalVariable(api.EvalScope{GoroutineID: -1}, expr, cfg)
+}
+
+// applyPrettyPrinters replaces the values in the tree of v that have a
+// pretty-printer with their summary, which api.Variable prints as
+// Type(summary). Values are left as they are if their printer fails, and
+// with the -raw and -json formats.
+func (t *Term) applyPrettyPrinters(v *api.Variable, format printFormat) {
+	if format.raw || format.json {
+		return
+	}
+	if v.Unreadable == "" && (v.Kind == reflect.Struct || v.Kind == reflect.Slice) {
+		if fn := t.prettyPrinter(v.Type); fn != nil {
+			if s, err := fn(t, v); err == nil {
+				v.Value = s
+				v.Children = nil
+				return
+			}
+		}
+	}
+	for i := range v.Children {
+		t.applyPrettyPrinters(&v.Children[i], format)
+	}
+}
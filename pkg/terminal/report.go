@@ -0,0 +1,291 @@
+package terminal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+const (
+	// reportDefaultFrames is the number of frames of the current goroutine
+	// whose local variables are included in a report.
+	reportDefaultFrames = 3
+	// reportStackDepth is the maximum depth of the stacks of a report.
+	reportStackDepth = 50
+	// reportGoroutineDepth is the maximum depth of the stacks of the
+	// goroutines other than the current one.
+	reportGoroutineDepth = 20
+)
+
+// crashReport is the information gathered by the report command.
+type crashReport struct {
+	Time       time.Time
+	Version    *api.GetVersionOut
+	BuildInfo  *api.BuildInfo `json:",omitempty"`
+	Pid        int
+	StopReason api.StopReason
+	// BreakpointArgs are the arguments of the breakpoint that stopped the
+	// target, for unrecovered panics the value passed to panic.
+	BreakpointArgs []api.Variable `json:",omitempty"`
+	Goroutine      *api.Goroutine
+	// Stack is the stack of the current goroutine, the first frames
+	// include their arguments and local variables.
+	Stack       []api.Stackframe
+	Registers   api.Registers `json:",omitempty"`
+	Goroutines  []reportGoroutine
+	Libraries   []api.Image `json:",omitempty"`
+	Unavailable []string    `json:",omitempty"`
+}
+
+// reportGoroutine is a goroutine and its stack.
+type reportGoroutine struct {
+	*api.Goroutine
+	Stack []api.Stackframe `json:",omitempty"`
+	Err   string           `json:",omitempty"`
+}
+
+func report(t *Term, ctx callContext, args string) error {
+	asJSON := false
+	frames := reportDefaultFrames
+	path := ""
+	fields := strings.Fields(args)
+	for i := 0; i < len(fields); i++ {
+		switch fields[i] {
+		case "-json":
+			asJSON = true
+		case "-frames":
+			if i+1 >= len(fields) {
+				return fmt.Errorf("expected argument after -frames")
+			}
+			i++
+			n, err := strconv.Atoi(fields[i])
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid number of frames %q", fields[i])
+			}
+			frames = n
+		default:
+			if path != "" {
+				return fmt.Errorf("too many arguments")
+			}
+			path = fields[i]
+		}
+	}
+	if path == "" {
+		return fmt.Errorf("not enough arguments")
+	}
+
+	r, err := collectReport(t, frames)
+	if err != nil {
+		return err
+	}
+
+	fh, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		enc := json.NewEncoder(fh)
+		enc.SetIndent("", "\t")
+		err = enc.Encode(r)
+	} else {
+		err = writeReportMarkdown(t, fh, r)
+	}
+	if err1 := fh.Close(); err == nil {
+		err = err1
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(t.stdout, "Report written to %s\n", path)
+	return nil
+}
+
+// collectReport gathers the information about the current state of the
+// target included in a report, loading the local variables of the first
+// frames of the current goroutine.
+// Information that can not be retrieved is listed in the Unavailable field
+// of the report instead of making it fail.
+func collectReport(t *Term, frames int) (*crashReport, error) {
+	state, err := t.client.GetState()
+	if err != nil {
+		return nil, err
+	}
+	if state.Exited {
+		return nil, fmt.Errorf("process has exited with status %d", state.ExitStatus)
+	}
+	r := &crashReport{Time: time.Now(), Pid: t.client.ProcessPid(), StopReason: state.StopReason, Goroutine: state.SelectedGoroutine}
+	unavailable := func(what string, err error) {
+		r.Unavailable = append(r.Unavailable, fmt.Sprintf("%s: %v", what, err))
+	}
+
+	if r.Version, err = t.client.GetVersion(); err != nil {
+		unavailable("version", err)
+	}
+	if r.BuildInfo, err = t.client.GetBuildInfo(); err != nil {
+		unavailable("build information", err)
+	}
+	if th := state.CurrentThread; th != nil {
+		if th.BreakpointInfo != nil {
+			r.BreakpointArgs = th.BreakpointInfo.Arguments
+		}
+		if r.Registers, err = t.client.ListThreadRegisters(th.ID, false); err != nil {
+			unavailable("registers", err)
+		}
+	}
+
+	r.Stack, err = t.client.Stacktrace(-1, reportStackDepth, 0, nil)
+	if err != nil {
+		unavailable("stack", err)
+	} else if frames > 0 {
+		cfg := ShortLoadConfig
+		top, err := t.client.Stacktrace(-1, frames-1, 0, &cfg)
+		if err != nil {
+			unavailable("local variables", err)
+		}
+		copy(r.Stack, top)
+	}
+
+	// Unwind all goroutines with a single request, goroutines missing from
+	// its result, for example because they were created after it, are
+	// unwound one by one.
+	stacks := make(map[int]api.GoroutineStacktrace)
+	if stacktraces, err := t.client.GoroutinesStacktraces(reportGoroutineDepth, 0); err == nil {
+		for _, st := range stacktraces {
+			stacks[st.GoroutineID] = st
+		}
+	}
+	for start := 0; start >= 0; {
+		gs, next, err := t.client.ListGoroutines(start, goroutineBatchSize)
+		if err != nil {
+			unavailable("goroutines", err)
+			break
+		}
+		for _, g := range gs {
+			rg := reportGoroutine{Goroutine: g}
+			if st, ok := stacks[g.ID]; ok {
+				rg.Stack, rg.Err = st.Locations, st.Err
+			} else if rg.Stack, err = t.client.Stacktrace(g.ID, reportGoroutineDepth, 0, nil); err != nil {
+				rg.Err = err.Error()
+			}
+			r.Goroutines = append(r.Goroutines, rg)
+		}
+		start = next
+	}
+
+	if r.Libraries, err = t.client.ListDynamicLibraries(); err != nil {
+		unavailable("dynamic libraries", err)
+	}
+	return r, nil
+}
+
+// writeReportMarkdown writes r to w formatted as Markdown.
+func writeReportMarkdown(t *Term, w io.Writer, r *crashReport) error {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "# Debugger report\n\n")
+	fmt.Fprintf(&buf, "- Generated: %s\n", r.Time.Format(time.RFC3339))
+	if r.Version != nil {
+		fmt.Fprintf(&buf, "- Delve: %s (API v%d, backend %s)\n", strings.Replace(strings.TrimSpace(r.Version.DelveVersion), "\n", ", ", -1), r.Version.APIVersion, r.Version.Backend)
+		fmt.Fprintf(&buf, "- Target Go version: %s\n", r.Version.TargetGoVersion)
+	}
+	if r.BuildInfo != nil {
+		if r.BuildInfo.BuildID != "" {
+			fmt.Fprintf(&buf, "- Build ID: %s\n", r.BuildInfo.BuildID)
+		}
+		if r.BuildInfo.Main.Path != "" {
+			fmt.Fprintf(&buf, "- Main module: %s %s\n", r.BuildInfo.Main.Path, r.BuildInfo.Main.Version)
+		}
+	}
+	fmt.Fprintf(&buf, "- Process: %d\n", r.Pid)
+	fmt.Fprintf(&buf, "- Stop reason: %s", r.StopReason.Kind)
+	if r.StopReason.Signal != "" {
+		fmt.Fprintf(&buf, " (%s, fault address %#x)", r.StopReason.Signal, r.StopReason.FaultAddr)
+	}
+	fmt.Fprintf(&buf, "\n")
+
+	if len(r.BreakpointArgs) > 0 {
+		fmt.Fprintf(&buf, "\n## Breakpoint arguments\n\n```\n")
+		for i := range r.BreakpointArgs {
+			fmt.Fprintf(&buf, "%s = %s\n", r.BreakpointArgs[i].Name, r.BreakpointArgs[i].MultilineString("", ""))
+		}
+		fmt.Fprintf(&buf, "```\n")
+	}
+
+	if r.Goroutine != nil {
+		fmt.Fprintf(&buf, "\n## Current goroutine %d\n", r.Goroutine.ID)
+	} else {
+		fmt.Fprintf(&buf, "\n## Current thread\n")
+	}
+	fmt.Fprintf(&buf, "\n```\n")
+	printStack(t, &buf, r.Stack, "", false)
+	fmt.Fprintf(&buf, "```\n")
+	for i := range r.Stack {
+		frame := &r.Stack[i]
+		if len(frame.Arguments) == 0 && len(frame.Locals) == 0 {
+			continue
+		}
+		fnname := "(unknown function)"
+		if frame.Function != nil {
+			fnname = frame.Function.Name()
+		}
+		fmt.Fprintf(&buf, "\n### Frame %d: %s\n\n```\n", i, fnname)
+		for _, vars := range [][]api.Variable{frame.Arguments, frame.Locals} {
+			for j := range vars {
+				fmt.Fprintf(&buf, "%s = %s\n", vars[j].Name, vars[j].SinglelineString())
+			}
+		}
+		fmt.Fprintf(&buf, "```\n")
+	}
+
+	if len(r.Registers) > 0 {
+		fmt.Fprintf(&buf, "\n## Registers\n\n```\n%s```\n", r.Registers.String())
+	}
+
+	fmt.Fprintf(&buf, "\n## Goroutines (%d)\n", len(r.Goroutines))
+	for _, g := range r.Goroutines {
+		fmt.Fprintf(&buf, "\n### Goroutine %d\n\n```\n", g.ID)
+		if g.Err != "" {
+			fmt.Fprintf(&buf, "stack unavailable: %s\n", g.Err)
+		} else {
+			printStack(t, &buf, g.Stack, "", false)
+		}
+		fmt.Fprintf(&buf, "```\n")
+	}
+
+	if r.BuildInfo != nil && (len(r.BuildInfo.Deps) > 0 || len(r.BuildInfo.Settings) > 0) {
+		fmt.Fprintf(&buf, "\n## Build information\n\n```\n")
+		for _, m := range r.BuildInfo.Deps {
+			fmt.Fprintf(&buf, "dep\t%s\t%s\n", m.Path, m.Version)
+			if m.Replace != nil {
+				fmt.Fprintf(&buf, "=>\t%s\t%s\n", m.Replace.Path, m.Replace.Version)
+			}
+		}
+		for _, s := range r.BuildInfo.Settings {
+			fmt.Fprintf(&buf, "build\t%s=%s\n", s.Key, s.Value)
+		}
+		fmt.Fprintf(&buf, "```\n")
+	}
+
+	if len(r.Libraries) > 0 {
+		fmt.Fprintf(&buf, "\n## Dynamic libraries\n\n```\n")
+		for _, lib := range r.Libraries {
+			fmt.Fprintf(&buf, "%#x %s\n", lib.Address, lib.Path)
+		}
+		fmt.Fprintf(&buf, "```\n")
+	}
+
+	if len(r.Unavailable) > 0 {
+		fmt.Fprintf(&buf, "\n## Unavailable information\n\n")
+		for _, s := range r.Unavailable {
+			fmt.Fprintf(&buf, "- %s\n", s)
+		}
+	}
+
+	_, err := io.WriteString(w, buf.String())
+	return err
+}
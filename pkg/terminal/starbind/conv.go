@@ -649,6 +649,11 @@ func unmarshalStarlarkValueIntl(val starlark.Value, dst reflect.Value, path stri
 	case starlark.Float:
 		dst.SetFloat(float64(val))
 	case starlark.String:
+		if dst.Kind() == reflect.Slice && dst.Type().Elem().Kind() == reflect.Uint8 {
+			// byte slices, like json.RawMessage, are set from strings
+			dst.SetBytes([]byte(val))
+			break
+		}
 		dst.SetString(string(val))
 	case *starlark.List:
 		if dst.Kind() != reflect.Slice {
@@ -26,6 +26,7 @@ const (
 	dlvContextName               = "dlv_context"
 	curScopeBuiltinName          = "cur_scope"
 	defaultLoadConfigBuiltinName = "default_load_config"
+	registerPrinterBuiltinName   = "register_printer"
 )
 
 func init() {
@@ -43,6 +44,9 @@ func init() {
 type Context interface {
 	Client() service.Client
 	RegisterCommand(name, helpMsg string, cmdfn func(args string) error)
+	// RegisterPrinter registers fn as the pretty-printer of the type
+	// called typename, a nil fn disables pretty-printing of the type.
+	RegisterPrinter(typename string, fn func(v *api.Variable) (string, error))
 	CallCommand(cmdstr string) error
 	Scope() api.EvalScope
 	LoadConfig() api.LoadConfig
@@ -114,6 +118,35 @@ func New(ctx Context) *Env {
 	env.env[defaultLoadConfigBuiltinName] = starlark.NewBuiltin(defaultLoadConfigBuiltinName, func(_ *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		return env.interfaceToStarlarkValue(env.ctx.LoadConfig()), nil
 	})
+	env.env[registerPrinterBuiltinName] = starlark.NewBuiltin(registerPrinterBuiltinName, func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if len(args) != 2 {
+			return nil, decorateError(thread, fmt.Errorf("wrong number of arguments"))
+		}
+		typename, ok := args[0].(starlark.String)
+		if !ok {
+			return nil, decorateError(thread, fmt.Errorf("first argument of register_printer was not a string"))
+		}
+		if args[1] == starlark.None {
+			env.ctx.RegisterPrinter(string(typename), nil)
+			return starlark.None, nil
+		}
+		fnval, ok := args[1].(starlark.Callable)
+		if !ok {
+			return nil, decorateError(thread, fmt.Errorf("second argument of register_printer was not a function"))
+		}
+		env.ctx.RegisterPrinter(string(typename), func(v *api.Variable) (string, error) {
+			r, err := starlark.Call(env.newThread(), fnval, starlark.Tuple{env.interfaceToStarlarkValue(*v)}, nil)
+			if err != nil {
+				return "", err
+			}
+			s, ok := r.(starlark.String)
+			if !ok {
+				return "", fmt.Errorf("printer of %s returned %s instead of a string", typename, r.Type())
+			}
+			return string(s), nil
+		})
+		return starlark.None, nil
+	})
 	return env
 }
 
@@ -46,6 +46,16 @@ func (ctx starlarkContext) RegisterCommand(name, helpMsg string, fn func(args st
 	}
 }
 
+func (ctx starlarkContext) RegisterPrinter(typename string, fn func(v *api.Variable) (string, error)) {
+	if fn == nil {
+		ctx.term.registerPrettyPrinter(typename, nil)
+		return
+	}
+	ctx.term.registerPrettyPrinter(typename, func(t *Term, v *api.Variable) (string, error) {
+		return fn(v)
+	})
+}
+
 func (ctx starlarkContext) CallCommand(cmdstr string) error {
 	return ctx.term.cmds.Call(cmdstr, ctx.term)
 }
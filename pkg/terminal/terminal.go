@@ -1,6 +1,7 @@
 package terminal
 
 import (
+	"bufio"
 	"fmt"
 	"io"
 	"net/rpc"
@@ -22,6 +23,7 @@ import (
 
 const (
 	historyFile                 string = ".dbg_history"
+	continuationPrompt          string = "... "
 	terminalHighlightEscapeCode string = "\033[%2dm"
 	terminalResetEscapeCode     string = "\033[0m"
 )
@@ -57,15 +59,62 @@ type Term struct {
 	displays     []displayEntry
 	colorEscapes map[colorize.Style]string
 
-	historyFile *os.File
+	// diffs are the expressions tracked by the diff command.
+	diffs []diffEntry
+	// grsSnapshots are the goroutines that existed at the last two stops,
+	// recorded after 'goroutines -diff' is first used.
+	grsSnapshots *goroutinesSnapshots
+
+	// OnExit is what happens to the target when the terminal exits, unless
+	// overridden by the arguments of the exit command.
+	OnExit ExitBehavior
+
+	// Batch, if set, makes Run read commands from standard input without a
+	// prompt, line editing or history and answer every question with its
+	// default.
+	Batch bool
+
+	// goroutineContexts saves the current frame and the display list of
+	// the goroutines that were switched away from, indexed by goroutine
+	// ID, see switchGoroutineContext.
+	goroutineContexts map[int]*goroutineContext
+	// contextGoroutine is the goroutine the current frame and display list
+	// belong to.
+	contextGoroutine int
+
+	// historyPath is the path of the history file, see loadHistory.
+	historyPath string
+
+	// stdin reads commands in batch mode.
+	stdin *bufio.Scanner
 
 	starlarkEnv *starbind.Env
 
 	substitutePathRulesCache [][2]string
 
-	// quitContinue is set to true by exitCommand to signal that the process
-	// should be resumed before quitting.
-	quitContinue bool
+	// bpVarFormats are the formats of the expressions printed by
+	// breakpoints, indexed by breakpoint ID and expression.
+	bpVarFormats map[int]map[string]printFormat
+
+	// lastBreakpointID is the ID of the last breakpoint created by the
+	// break and trace commands, it can be referred to as $.
+	lastBreakpointID int
+
+	// prettyPrinters are the pretty-printers registered by scripts, indexed
+	// by type name, see registerPrettyPrinter.
+	prettyPrinters map[string]prettyPrinter
+
+	// outputOffsets are the offsets in the standard output and standard
+	// error of the target up to which the output command printed them.
+	outputOffsets [2]int64
+
+	// runningHook is set while the hooks of a command are executed, see
+	// Commands.runHooks.
+	runningHook bool
+
+	// quitBehavior is set by exitCommand to signal whether the process
+	// should be killed or resumed before quitting.
+	quitBehavior ExitBehavior
 
 	longCommandMu         sync.Mutex
 	longCommandCancelFlag bool
@@ -74,9 +123,32 @@ type Term struct {
 	quitting      bool
 }
 
+// ExitBehavior describes what happens to the target process when the
+// terminal exits.
+type ExitBehavior uint8
+
+const (
+	// ExitAsk asks the user whether the target should be killed, when
+	// there is a choice.
+	ExitAsk ExitBehavior = iota
+	// ExitKill kills the target process, and the headless instance when
+	// connected to one.
+	ExitKill
+	// ExitContinue resumes the target process and leaves it running, when
+	// connected to a headless instance the instance is also left running.
+	ExitContinue
+)
+
 type displayEntry struct {
 	expr   string
-	fmtstr string
+	format printFormat
+}
+
+// goroutineContext is the state of the terminal saved for a goroutine
+// when switching to a different goroutine.
+type goroutineContext struct {
+	frame    int
+	displays []displayEntry
 }
 
 // New returns a new Term.
@@ -145,6 +217,7 @@ func (t *Term) sigintGuard(ch <-chan os.Signal, multiClient bool) {
 	for range ch {
 		t.longCommandCancel()
 		t.starlarkEnv.Cancel()
+		t.client.CancelRequest()
 		state, err := t.client.GetStateNonBlocking()
 		if err == nil && state.Recording {
 			fmt.Printf("received SIGINT, stopping recording (will not forward signal)\n")
@@ -162,7 +235,7 @@ func (t *Term) sigintGuard(ch <-chan os.Signal, multiClient bool) {
 			}
 			continue
 		}
-		if multiClient {
+		if multiClient && !t.Batch {
 			answer, err := t.line.Prompt("Would you like to [p]ause the target (returning to Delve's prompt) or [q]uit this client (leaving the target running) [p/q]? ")
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "%v", err)
@@ -210,40 +283,19 @@ func (t *Term) Run() (int, error) {
 	signal.Notify(ch, syscall.SIGINT)
 	go t.sigintGuard(ch, multiClient)
 
-	t.line.SetCompleter(func(line string) (c []string) {
-		if strings.HasPrefix(line, "break ") || strings.HasPrefix(line, "b ") {
-			filter := line[strings.Index(line, " ")+1:]
-			funcs, _ := t.client.ListFunctions(filter)
-			for _, f := range funcs {
-				c = append(c, "break "+f)
-			}
-			return
-		}
-		for _, cmd := range t.cmds.cmds {
-			for _, alias := range cmd.aliases {
-				if strings.HasPrefix(alias, strings.ToLower(line)) {
-					c = append(c, alias)
-				}
-			}
-		}
-		return
-	})
-
-	fullHistoryFile, err := config.GetConfigFilePath(historyFile)
-	if err != nil {
-		fmt.Printf("Unable to load history file: %v.", err)
+	if t.Batch {
+		// Output is meant to be consumed by other programs.
+		t.stdout = os.Stdout
+		t.colorEscapes = nil
+	} else {
+		t.setupLineEditing()
+		fmt.Println("Type 'help' for list of commands.")
 	}
 
-	t.historyFile, err = os.OpenFile(fullHistoryFile, os.O_RDWR|os.O_CREATE, 0600)
-	if err != nil {
-		fmt.Printf("Unable to open history file: %v. History will not be saved for this session.", err)
-	}
-	if _, err := t.line.ReadHistory(t.historyFile); err != nil {
-		fmt.Printf("Unable to read history file: %v", err)
+	if err := t.restoreDormantBreakpoints(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error restoring breakpoints: %s\n", err)
 	}
 
-	fmt.Println("Type 'help' for list of commands.")
-
 	if t.InitFile != "" {
 		err := t.cmds.executeFile(t, t.InitFile)
 		if err != nil {
@@ -255,6 +307,10 @@ func (t *Term) Run() (int, error) {
 	}
 
 	var lastCmd string
+	var failed bool
+	// aborted is set when Ctrl-C was pressed at the prompt, pressing it a
+	// second time exits.
+	var aborted bool
 
 	// Ensure that the target process is neither running nor recording by
 	// making a blocking call.
@@ -264,13 +320,33 @@ func (t *Term) Run() (int, error) {
 		cmdstr, err := t.promptForInput()
 		if err != nil {
 			if err == io.EOF {
+				if t.Batch {
+					status, err := t.handleExit()
+					if status == 0 && failed {
+						status = 1
+					}
+					return status, err
+				}
 				fmt.Println("exit")
 				return t.handleExit()
 			}
+			if err == liner.ErrPromptAborted {
+				if aborted {
+					fmt.Println("exit")
+					return t.handleExit()
+				}
+				aborted = true
+				fmt.Println("(press Ctrl-C again to exit)")
+				continue
+			}
 			return 1, fmt.Errorf("Prompt for input failed.\n")
 		}
+		aborted = false
 
 		if strings.TrimSpace(cmdstr) == "" {
+			if t.Batch {
+				continue
+			}
 			cmdstr = lastCmd
 		}
 
@@ -280,6 +356,7 @@ func (t *Term) Run() (int, error) {
 			if _, ok := err.(ExitRequestError); ok {
 				return t.handleExit()
 			}
+			failed = true
 			// The type information gets lost in serialization / de-serialization,
 			// so we do a string compare on the error message to see if the process
 			// has exited, or if the command actually failed.
@@ -298,6 +375,35 @@ func (t *Term) Run() (int, error) {
 	}
 }
 
+// setupLineEditing configures completion and loads the command history
+// for the interactive prompt.
+func (t *Term) setupLineEditing() {
+	// Ctrl-C at the prompt is handled by Run, the target is stopped by
+	// sigintGuard while it is running.
+	t.line.SetCtrlCAborts(true)
+
+	t.line.SetCompleter(func(line string) (c []string) {
+		if strings.HasPrefix(line, "break ") || strings.HasPrefix(line, "b ") {
+			filter := line[strings.Index(line, " ")+1:]
+			funcs, _ := t.client.ListFunctions(filter)
+			for _, f := range funcs {
+				c = append(c, "break "+f)
+			}
+			return
+		}
+		for _, cmd := range t.cmds.cmds {
+			for _, alias := range cmd.aliases {
+				if strings.HasPrefix(alias, strings.ToLower(line)) {
+					c = append(c, alias)
+				}
+			}
+		}
+		return
+	})
+
+	t.loadHistory()
+}
+
 // Substitutes directory to source file.
 //
 // Ensures that only directory is substituted, for example:
@@ -339,22 +445,101 @@ func (t *Term) formatPath(path string) string {
 }
 
 func (t *Term) promptForInput() (string, error) {
-	l, err := t.line.Prompt(t.prompt)
+	l, err := t.readLine(t.prompt)
 	if err != nil {
 		return "", err
 	}
+	for {
+		prefix, cont := lineContinuation(l)
+		if !cont {
+			break
+		}
+		next, err := t.readLine(continuationPrompt)
+		if err != nil {
+			return "", err
+		}
+		l = prefix + next
+	}
 
-	l = strings.TrimSuffix(l, "\n")
-	if l != "" {
+	if l != "" && !t.Batch {
 		t.line.AppendHistory(l)
 	}
 
 	return l, nil
 }
 
-func yesno(line *liner.State, question string) (bool, error) {
+// readLine reads one line of input, prompting with prompt unless the
+// terminal is in batch mode.
+func (t *Term) readLine(prompt string) (string, error) {
+	if t.Batch {
+		return t.readBatchInput()
+	}
+	l, err := t.line.Prompt(prompt)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(l, "\n"), nil
+}
+
+// lineContinuation reports whether the command in line continues on the
+// next line, because line ends with a backslash or contains unbalanced
+// parenthesis, brackets, braces or an unterminated raw string literal.
+// If it does the returned prefix, with the trailing backslash removed,
+// should be prepended to the next line.
+func lineContinuation(line string) (prefix string, cont bool) {
+	if strings.HasSuffix(line, "\\") {
+		return line[:len(line)-1] + " ", true
+	}
+	depth := 0
+	var quote rune
+	escaped := false
+	for _, ch := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case quote != 0:
+			switch {
+			case ch == '\\' && quote != '`':
+				escaped = true
+			case ch == quote:
+				quote = 0
+			}
+		case ch == '"' || ch == '\'' || ch == '`':
+			quote = ch
+		case ch == '(' || ch == '[' || ch == '{':
+			depth++
+		case ch == ')' || ch == ']' || ch == '}':
+			depth--
+		}
+	}
+	if depth > 0 || quote == '`' {
+		return line + " ", true
+	}
+	return "", false
+}
+
+// readBatchInput reads the next command from standard input.
+func (t *Term) readBatchInput() (string, error) {
+	if t.stdin == nil {
+		t.stdin = bufio.NewScanner(os.Stdin)
+	}
+	if !t.stdin.Scan() {
+		if err := t.stdin.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return strings.TrimSuffix(t.stdin.Text(), "\r"), nil
+}
+
+// yesno asks question to the user, in batch mode dflt is returned
+// without asking.
+func (t *Term) yesno(question string, dflt bool) (bool, error) {
+	if t.Batch {
+		return dflt, nil
+	}
 	for {
-		answer, err := line.Prompt(question)
+		answer, err := t.line.Prompt(question)
 		if err != nil {
 			return false, err
 		}
@@ -369,14 +554,7 @@ func yesno(line *liner.State, question string) (bool, error) {
 }
 
 func (t *Term) handleExit() (int, error) {
-	if t.historyFile != nil {
-		if _, err := t.line.WriteHistory(t.historyFile); err != nil {
-			fmt.Println("readline history error:", err)
-		}
-		if err := t.historyFile.Close(); err != nil {
-			fmt.Printf("error closing history file: %s\n", err)
-		}
-	}
+	t.saveHistory()
 
 	t.quittingMutex.Lock()
 	quitting := t.quitting
@@ -385,37 +563,61 @@ func (t *Term) handleExit() (int, error) {
 		return 0, nil
 	}
 
+	exit := t.OnExit
+	if t.quitBehavior != ExitAsk {
+		exit = t.quitBehavior
+	}
+
 	s, err := t.client.GetState()
 	if err != nil {
 		if isErrProcessExited(err) {
 			if t.client.IsMulticlient() {
-				answer, err := yesno(t.line, "Remote process has exited. Would you like to kill the headless instance? [Y/n] ")
-				if err != nil {
-					return 2, io.EOF
+				var answer bool
+				switch exit {
+				case ExitKill:
+					answer = true
+				case ExitContinue:
+					answer = false
+				default:
+					answer, err = t.yesno("Remote process has exited. Would you like to kill the headless instance? [Y/n] ", true)
+					if err != nil {
+						return 2, io.EOF
+					}
 				}
 				if answer {
 					if err := t.client.Detach(true); err != nil {
 						return 1, err
 					}
 				}
-				return 0, err
+				return 0, nil
 			}
 			return 0, nil
 		}
 		return 1, err
 	}
 	if !s.Exited {
-		if t.quitContinue {
-			err := t.client.Disconnect(true)
+		switch exit {
+		case ExitContinue:
+			if t.client.IsMulticlient() {
+				err = t.client.Disconnect(true)
+			} else {
+				t.saveDormantBreakpoints()
+				err = t.client.Detach(false)
+			}
 			if err != nil {
 				return 2, err
 			}
 			return 0, nil
+		case ExitKill:
+			if err := t.client.Detach(true); err != nil {
+				return 1, err
+			}
+			return 0, nil
 		}
 
 		doDetach := true
 		if t.client.IsMulticlient() {
-			answer, err := yesno(t.line, "Would you like to kill the headless instance? [Y/n] ")
+			answer, err := t.yesno("Would you like to kill the headless instance? [Y/n] ", true)
 			if err != nil {
 				return 2, io.EOF
 			}
@@ -425,12 +627,15 @@ func (t *Term) handleExit() (int, error) {
 		if doDetach {
 			kill := true
 			if t.client.AttachedToExistingProcess() {
-				answer, err := yesno(t.line, "Would you like to kill the process? [Y/n] ")
+				answer, err := t.yesno("Would you like to kill the process? [Y/n] ", true)
 				if err != nil {
 					return 2, io.EOF
 				}
 				kill = answer
 			}
+			if !kill {
+				t.saveDormantBreakpoints()
+			}
 			if err := t.client.Detach(kill); err != nil {
 				return 1, err
 			}
@@ -461,7 +666,7 @@ func (t *Term) removeDisplay(n int) error {
 	if n < 0 || n >= len(t.displays) {
 		return fmt.Errorf("%d is out of range", n)
 	}
-	t.displays[n] = displayEntry{"", ""}
+	t.displays[n] = displayEntry{}
 	for i := len(t.displays) - 1; i >= 0; i-- {
 		if t.displays[i].expr != "" {
 			t.displays = t.displays[:i+1]
@@ -472,12 +677,12 @@ func (t *Term) removeDisplay(n int) error {
 	return nil
 }
 
-func (t *Term) addDisplay(expr, fmtstr string) {
-	t.displays = append(t.displays, displayEntry{expr: expr, fmtstr: fmtstr})
+func (t *Term) addDisplay(expr string, format printFormat) {
+	t.displays = append(t.displays, displayEntry{expr: expr, format: format})
 }
 
 func (t *Term) printDisplay(i int) {
-	expr, fmtstr := t.displays[i].expr, t.displays[i].fmtstr
+	expr, format := t.displays[i].expr, t.displays[i].format
 	val, err := t.client.EvalVariable(api.EvalScope{GoroutineID: -1}, expr, ShortLoadConfig)
 	if err != nil {
 		if isErrProcessExited(err) {
@@ -486,7 +691,8 @@ func (t *Term) printDisplay(i int) {
 		fmt.Printf("%d: %s = error %v\n", i, expr, err)
 		return
 	}
-	fmt.Printf("%d: %s = %s\n", i, val.Name, val.SinglelineStringFormatted(fmtstr))
+	t.applyPrettyPrinters(val, format)
+	fmt.Printf("%d: %s = %s\n", i, val.Name, format.singleline(val))
 }
 
 func (t *Term) printDisplays() {
@@ -498,7 +704,43 @@ func (t *Term) printDisplays() {
 }
 
 func (t *Term) onStop() {
+	// The stacks of all goroutines may have changed.
+	for _, gctx := range t.goroutineContexts {
+		gctx.frame = 0
+	}
+	if state, err := t.client.GetStateNonBlocking(); err == nil && !state.Running && !state.Exited {
+		if gid := selectedGID(state); gid != t.contextGoroutine {
+			// The target stopped on a different goroutine, the frame and the
+			// display list selected for the previous one do not apply.
+			t.switchGoroutineContext(t.contextGoroutine, gid)
+		}
+	}
+	t.cmds.frame = 0
 	t.printDisplays()
+	t.printDiffs()
+	if t.grsSnapshots != nil {
+		t.snapshotGoroutines()
+	}
+}
+
+// switchGoroutineContext saves the current frame and display list for
+// goroutine from and restores the ones saved for goroutine to. If nothing
+// was saved for goroutine to the display list is kept and the current frame
+// is reset.
+func (t *Term) switchGoroutineContext(from, to int) {
+	if t.goroutineContexts == nil {
+		t.goroutineContexts = make(map[int]*goroutineContext)
+	}
+	t.goroutineContexts[from] = &goroutineContext{frame: t.cmds.frame, displays: t.displays}
+	t.contextGoroutine = to
+	gctx := t.goroutineContexts[to]
+	if gctx == nil {
+		t.cmds.frame = 0
+		t.displays = append([]displayEntry(nil), t.displays...)
+		return
+	}
+	t.cmds.frame = gctx.frame
+	t.displays = gctx.displays
 }
 
 func (t *Term) longCommandCancel() {
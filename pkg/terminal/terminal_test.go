@@ -3,6 +3,7 @@ package terminal
 import (
 	"errors"
 	"net/rpc"
+	"reflect"
 	"runtime"
 	"testing"
 
@@ -105,3 +106,44 @@ func TestIsErrProcessExited(t *testing.T) {
 		}
 	}
 }
+
+func TestDedupHistory(t *testing.T) {
+	for _, tc := range []struct {
+		in   []string
+		size int
+		out  []string
+	}{
+		{[]string{"a", "b", "a", "", "c", "b"}, 10, []string{"a", "c", "b"}},
+		{[]string{"a", "b", "a", "c", "b"}, 2, []string{"c", "b"}},
+		{[]string{"a", "a"}, 0, []string{}},
+	} {
+		out := dedupHistory(tc.in, tc.size)
+		if !reflect.DeepEqual(out, tc.out) {
+			t.Errorf("dedupHistory(%q, %d): got %q expected %q", tc.in, tc.size, out, tc.out)
+		}
+	}
+}
+
+func TestLineContinuation(t *testing.T) {
+	for _, tc := range []struct {
+		in     string
+		prefix string
+		cont   bool
+	}{
+		{"print a", "", false},
+		{"print a +\\", "print a + ", true},
+		{"call f(a,", "call f(a, ", true},
+		{"print []int{1, 2,", "print []int{1, 2, ", true},
+		{"print f(a)[0]", "", false},
+		{`print "(["`, "", false},
+		{`print '('`, "", false},
+		{`print "\"("`, "", false},
+		{"print `(", "print `( ", true},
+		{"print a)", "", false},
+	} {
+		prefix, cont := lineContinuation(tc.in)
+		if prefix != tc.prefix || cont != tc.cont {
+			t.Errorf("lineContinuation(%q): got %q %v expected %q %v", tc.in, prefix, cont, tc.prefix, tc.cont)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/parser"
+)
+
+// CommandOptions is implemented by the options of every command that can
+// be executed with a DebuggerCommand.
+type CommandOptions interface {
+	// Validate returns an error if the options are not valid.
+	Validate() error
+}
+
+// ContinueOptions are the options of the Continue command.
+type ContinueOptions struct {
+	// ReverseDirection resumes execution backwards, the target must be a
+	// recording. Equivalent to the Rewind command.
+	ReverseDirection bool `json:"reverseDirection,omitempty"`
+	// Until is a boolean expression, evaluated in the scope of the selected
+	// goroutine every time the target stops at a breakpoint or watchpoint:
+	// execution is resumed again, without returning to the client, until it
+	// is true.
+	Until string `json:"until,omitempty"`
+	// MaxIterations is the maximum number of times execution is resumed
+	// while Until is false, after which an error is returned. Zero means
+	// DefaultContinueUntilMaxIterations.
+	MaxIterations int `json:"maxIterations,omitempty"`
+}
+
+// DefaultContinueUntilMaxIterations is the default value of
+// ContinueOptions.MaxIterations.
+const DefaultContinueUntilMaxIterations = 1000
+
+// DirectionCongruentContinueOptions are the options of the
+// DirectionCongruentContinue command.
+type DirectionCongruentContinueOptions struct {
+}
+
+// NextOptions are the options of the Next command.
+type NextOptions struct {
+	// ReverseDirection steps backwards, the target must be a recording.
+	// Equivalent to the ReverseNext command.
+	ReverseDirection bool `json:"reverseDirection,omitempty"`
+	// Count is the number of times the command is repeated, without
+	// returning control to the client in between. Zero is equivalent to one.
+	// Repetition stops early if a breakpoint is hit.
+	Count int `json:"count,omitempty"`
+}
+
+// StepGranularity is the unit of execution of a Step command.
+type StepGranularity string
+
+const (
+	// StepLine steps to the next source line, this is the default.
+	StepLine StepGranularity = "line"
+	// StepInstructionGranularity steps a single CPU instruction.
+	StepInstructionGranularity StepGranularity = "instruction"
+)
+
+// StepOptions are the options of the Step command.
+type StepOptions struct {
+	// ReverseDirection steps backwards, the target must be a recording.
+	// Equivalent to the ReverseStep and ReverseStepInstruction commands.
+	ReverseDirection bool `json:"reverseDirection,omitempty"`
+	// Granularity is the unit of execution of the step, if empty StepLine
+	// is used.
+	Granularity StepGranularity `json:"granularity,omitempty"`
+	// Count is the number of times the command is repeated, see
+	// NextOptions.Count.
+	Count int `json:"count,omitempty"`
+}
+
+// StepOutOptions are the options of the StepOut command.
+type StepOutOptions struct {
+	// ReverseDirection steps out backwards, to the caller of the current
+	// function, the target must be a recording. Equivalent to the
+	// ReverseStepOut command.
+	ReverseDirection bool `json:"reverseDirection,omitempty"`
+	// Count is the number of times the command is repeated, see
+	// NextOptions.Count.
+	Count int `json:"count,omitempty"`
+}
+
+// CallOptions are the options of the Call command.
+type CallOptions struct {
+	// Expr is the expression containing the function call.
+	Expr string `json:"expr"`
+	// GoroutineID is the goroutine that will execute the call, if zero the
+	// selected goroutine is used.
+	GoroutineID int `json:"goroutineID,omitempty"`
+	// UnsafeCall disables parameter escape checking, see
+	// DebuggerCommand.UnsafeCall.
+	UnsafeCall bool `json:"unsafeCall,omitempty"`
+}
+
+// SwitchThreadOptions are the options of the SwitchThread command.
+type SwitchThreadOptions struct {
+	ThreadID int `json:"threadID"`
+}
+
+// SwitchGoroutineOptions are the options of the SwitchGoroutine command.
+type SwitchGoroutineOptions struct {
+	GoroutineID int `json:"goroutineID"`
+}
+
+// HaltOptions are the options of the Halt command.
+type HaltOptions struct {
+}
+
+func (opts *ContinueOptions) Validate() error {
+	if opts.Until != "" {
+		if _, err := parser.ParseExpr(opts.Until); err != nil {
+			return fmt.Errorf("invalid until expression: %v", err)
+		}
+	} else if opts.MaxIterations != 0 {
+		return errors.New("maximum number of iterations specified without an until expression")
+	}
+	return validateCount(opts.MaxIterations)
+}
+
+func (opts *DirectionCongruentContinueOptions) Validate() error { return nil }
+
+func (opts *NextOptions) Validate() error { return validateCount(opts.Count) }
+
+func (opts *StepOptions) Validate() error {
+	switch opts.Granularity {
+	case "", StepLine:
+		return validateCount(opts.Count)
+	case StepInstructionGranularity:
+		if opts.Count > 1 {
+			return errors.New("repeat count not supported with instruction granularity")
+		}
+		return validateCount(opts.Count)
+	default:
+		return fmt.Errorf("unknown step granularity %q", opts.Granularity)
+	}
+}
+
+func (opts *StepOutOptions) Validate() error { return validateCount(opts.Count) }
+
+func (opts *CallOptions) Validate() error {
+	if opts.Expr == "" {
+		return errors.New("no expression specified for call")
+	}
+	if opts.GoroutineID < 0 {
+		return fmt.Errorf("invalid goroutine %d", opts.GoroutineID)
+	}
+	return nil
+}
+
+func (opts *SwitchThreadOptions) Validate() error { return nil }
+
+func (opts *SwitchGoroutineOptions) Validate() error { return nil }
+
+func (opts *HaltOptions) Validate() error { return nil }
+
+func validateCount(count int) error {
+	if count < 0 {
+		return fmt.Errorf("invalid repeat count %d", count)
+	}
+	return nil
+}
+
+// commandOptions maps the name of every command to a function returning
+// its default options, derived from the legacy fields of cmd.
+var commandOptions = map[string]func(cmd *DebuggerCommand) CommandOptions{
+	Continue: func(*DebuggerCommand) CommandOptions { return &ContinueOptions{} },
+	Rewind:   func(*DebuggerCommand) CommandOptions { return &ContinueOptions{ReverseDirection: true} },
+	DirectionCongruentContinue: func(*DebuggerCommand) CommandOptions {
+		return &DirectionCongruentContinueOptions{}
+	},
+	Next:        func(*DebuggerCommand) CommandOptions { return &NextOptions{} },
+	ReverseNext: func(*DebuggerCommand) CommandOptions { return &NextOptions{ReverseDirection: true} },
+	Step:        func(*DebuggerCommand) CommandOptions { return &StepOptions{} },
+	ReverseStep: func(*DebuggerCommand) CommandOptions { return &StepOptions{ReverseDirection: true} },
+	StepInstruction: func(*DebuggerCommand) CommandOptions {
+		return &StepOptions{Granularity: StepInstructionGranularity}
+	},
+	ReverseStepInstruction: func(*DebuggerCommand) CommandOptions {
+		return &StepOptions{ReverseDirection: true, Granularity: StepInstructionGranularity}
+	},
+	StepOut:        func(*DebuggerCommand) CommandOptions { return &StepOutOptions{} },
+	ReverseStepOut: func(*DebuggerCommand) CommandOptions { return &StepOutOptions{ReverseDirection: true} },
+	Call: func(cmd *DebuggerCommand) CommandOptions {
+		return &CallOptions{Expr: cmd.Expr, GoroutineID: cmd.GoroutineID, UnsafeCall: cmd.UnsafeCall}
+	},
+	SwitchThread: func(cmd *DebuggerCommand) CommandOptions {
+		return &SwitchThreadOptions{ThreadID: cmd.ThreadID}
+	},
+	SwitchGoroutine: func(cmd *DebuggerCommand) CommandOptions {
+		return &SwitchGoroutineOptions{GoroutineID: cmd.GoroutineID}
+	},
+	Halt: func(*DebuggerCommand) CommandOptions { return &HaltOptions{} },
+}
+
+// NewCommand returns a DebuggerCommand executing the command name with the
+// specified options. The type of opts must be the one corresponding to
+// name, for example *NextOptions for Next.
+func NewCommand(name string, opts CommandOptions) (*DebuggerCommand, error) {
+	cmd := &DebuggerCommand{Name: name}
+	if opts != nil {
+		buf, err := json.Marshal(opts)
+		if err != nil {
+			return nil, err
+		}
+		cmd.Options = buf
+	}
+	if _, err := cmd.DecodeOptions(); err != nil {
+		return nil, err
+	}
+	return cmd, nil
+}
+
+// DecodeOptions returns the options of cmd, validated. The concrete type of
+// the returned value depends on the command name, for example Next and
+// ReverseNext return *NextOptions.
+// If cmd.Options is empty the options are derived from the name of the
+// command and the legacy fields of DebuggerCommand (ThreadID, GoroutineID,
+// Expr and UnsafeCall).
+func (cmd *DebuggerCommand) DecodeOptions() (CommandOptions, error) {
+	mkopts, ok := commandOptions[cmd.Name]
+	if !ok {
+		return nil, fmt.Errorf("unknown command %q", cmd.Name)
+	}
+	opts := mkopts(cmd)
+	if len(cmd.Options) > 0 {
+		dec := json.NewDecoder(bytes.NewReader(cmd.Options))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(opts); err != nil {
+			return nil, fmt.Errorf("invalid options for command %q: %v", cmd.Name, err)
+		}
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
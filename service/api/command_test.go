@@ -0,0 +1,70 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecodeCommandOptions(t *testing.T) {
+	tests := []struct {
+		cmd     DebuggerCommand
+		want    CommandOptions
+		wantErr bool
+	}{
+		{DebuggerCommand{Name: Continue}, &ContinueOptions{}, false},
+		{DebuggerCommand{Name: Rewind}, &ContinueOptions{ReverseDirection: true}, false},
+		{DebuggerCommand{Name: ReverseStepInstruction}, &StepOptions{ReverseDirection: true, Granularity: StepInstructionGranularity}, false},
+		{DebuggerCommand{Name: Call, Expr: "f()", GoroutineID: 2}, &CallOptions{Expr: "f()", GoroutineID: 2}, false},
+		{DebuggerCommand{Name: SwitchThread, ThreadID: 10}, &SwitchThreadOptions{ThreadID: 10}, false},
+		{DebuggerCommand{Name: Next, Options: []byte(`{"reverseDirection":true}`)}, &NextOptions{ReverseDirection: true}, false},
+		{DebuggerCommand{Name: Step, Options: []byte(`{"granularity":"instruction"}`)}, &StepOptions{Granularity: StepInstructionGranularity}, false},
+		{DebuggerCommand{Name: Call, Options: []byte(`{"expr":"g()","unsafeCall":true}`)}, &CallOptions{Expr: "g()", UnsafeCall: true}, false},
+		{DebuggerCommand{Name: Next, Options: []byte(`{"count":3}`)}, &NextOptions{Count: 3}, false},
+		{DebuggerCommand{Name: Continue, Options: []byte(`{"until":"i == 3","maxIterations":10}`)}, &ContinueOptions{Until: "i == 3", MaxIterations: 10}, false},
+
+		{DebuggerCommand{Name: "nonexistent"}, nil, true},
+		{DebuggerCommand{Name: Call}, nil, true},
+		{DebuggerCommand{Name: Step, Options: []byte(`{"granularity":"function"}`)}, nil, true},
+		{DebuggerCommand{Name: Next, Options: []byte(`{"count":"many"}`)}, nil, true},
+		{DebuggerCommand{Name: StepOut, Options: []byte(`{"count":-1}`)}, nil, true},
+		{DebuggerCommand{Name: StepInstruction, Options: []byte(`{"count":2}`)}, nil, true},
+		{DebuggerCommand{Name: Continue, Options: []byte(`{"until":"i =="}`)}, nil, true},
+		{DebuggerCommand{Name: Continue, Options: []byte(`{"maxIterations":10}`)}, nil, true},
+		{DebuggerCommand{Name: Continue, Options: []byte(`{"until":"i == 3","maxIterations":-1}`)}, nil, true},
+	}
+
+	for _, tc := range tests {
+		opts, err := tc.cmd.DecodeOptions()
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("%s %s: expected error, got %#v", tc.cmd.Name, tc.cmd.Options, opts)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s %s: unexpected error %v", tc.cmd.Name, tc.cmd.Options, err)
+			continue
+		}
+		if !reflect.DeepEqual(opts, tc.want) {
+			t.Errorf("%s %s: got %#v expected %#v", tc.cmd.Name, tc.cmd.Options, opts, tc.want)
+		}
+	}
+}
+
+func TestNewCommand(t *testing.T) {
+	cmd, err := NewCommand(StepOut, &StepOutOptions{ReverseDirection: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts, err := cmd.DecodeOptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !opts.(*StepOutOptions).ReverseDirection {
+		t.Errorf("options not preserved: %#v", opts)
+	}
+
+	if _, err := NewCommand(Call, &CallOptions{}); err == nil {
+		t.Errorf("expected error for call without expression")
+	}
+}
@@ -21,10 +21,12 @@ import (
 func ConvertBreakpoint(bp *proc.Breakpoint) *Breakpoint {
 	b := &Breakpoint{
 		Name:         bp.Name,
+		Groups:       bp.Groups,
 		ID:           bp.LogicalID,
 		FunctionName: bp.FunctionName,
 		File:         bp.File,
 		Line:         bp.Line,
+		Column:       bp.Column,
 		Addr:         bp.Addr,
 		Tracepoint:   bp.Tracepoint,
 		TraceReturn:  bp.TraceReturn,
@@ -38,20 +40,26 @@ func ConvertBreakpoint(bp *proc.Breakpoint) *Breakpoint {
 		Addrs:        []uint64{bp.Addr},
 	}
 
-	breaklet := bp.UserBreaklet()
-	if breaklet != nil {
-		b.TotalHitCount = breaklet.TotalHitCount
+	if bp.Logical != nil {
+		b.TotalHitCount = bp.Logical.TotalHitCount
 		b.HitCount = map[string]uint64{}
-		for idx := range breaklet.HitCount {
-			b.HitCount[strconv.Itoa(idx)] = breaklet.HitCount[idx]
+		for idx := range bp.Logical.HitCount {
+			b.HitCount[strconv.Itoa(idx)] = bp.Logical.HitCount[idx]
+		}
+		if bp.Logical.History != nil {
+			b.WatchHistory = bp.Logical.History.Max
 		}
+	}
 
+	breaklet := bp.UserBreaklet()
+	if breaklet != nil {
 		var buf bytes.Buffer
 		printer.Fprint(&buf, token.NewFileSet(), breaklet.Cond)
 		b.Cond = buf.String()
 		if breaklet.HitCond != nil {
 			b.HitCond = fmt.Sprintf("%s %d", breaklet.HitCond.Op.String(), breaklet.HitCond.Val)
 		}
+		b.Caller = breaklet.Caller
 	}
 
 	return b
@@ -115,6 +123,7 @@ func ConvertThread(th proc.Thread) *Thread {
 		Line:        line,
 		Function:    function,
 		GoroutineID: gid,
+		Activity:    proc.ThreadActivity(th).String(),
 		Breakpoint:  bp,
 	}
 }
@@ -133,13 +142,60 @@ func PrettyTypeName(typ godwarf.Type) string {
 		return ""
 	}
 	if typ.Common().Name != "" {
-		return typ.Common().Name
+		return demangleShapes(typ.Common().Name)
 	}
 	r := typ.String()
 	if r == "*void" {
 		return "unsafe.Pointer"
 	}
-	return r
+	return demangleShapes(r)
+}
+
+// demangleShapes removes the decorations of the shape types that the
+// compiler uses to instantiate generic code, for example
+// main.Pair[go.shape.string_0,go.shape.int] becomes main.Pair[string,int].
+// Shape types are only left in type names when the concrete types couldn't
+// be read from the dictionary of the instantiation.
+func demangleShapes(name string) string {
+	const shapePrefix = "go.shape."
+	if !strings.Contains(name, shapePrefix) {
+		return name
+	}
+	var buf strings.Builder
+	for {
+		i := strings.Index(name, shapePrefix)
+		if i < 0 {
+			buf.WriteString(name)
+			break
+		}
+		buf.WriteString(name[:i])
+		name = name[i+len(shapePrefix):]
+		// the shape name extends up to the end of the list of type parameters
+		// it is part of, Go 1.18 appended an index to it (_0, _1, ...)
+		end := strings.IndexAny(name, ",]")
+		if end < 0 {
+			end = len(name)
+		}
+		shape := name[:end]
+		if j := strings.LastIndex(shape, "_"); j >= 0 && isDigits(shape[j+1:]) {
+			shape = shape[:j]
+		}
+		buf.WriteString(shape)
+		name = name[end:]
+	}
+	return buf.String()
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, ch := range s {
+		if ch < '0' || ch > '9' {
+			return false
+		}
+	}
+	return true
 }
 
 func convertFloatValue(v *proc.Variable, sz int) string {
@@ -302,11 +358,16 @@ func ConvertGoroutine(tgt *proc.Target, g *proc.G) *Goroutine {
 		UserCurrentLoc: ConvertLocation(g.UserCurrent()),
 		GoStatementLoc: ConvertLocation(g.Go()),
 		StartLoc:       ConvertLocation(g.StartLoc(tgt)),
+		GoPC:           g.GoPC,
+		StartPC:        g.StartPC,
 		ThreadID:       tid,
 		WaitSince:      g.WaitSince,
 		WaitReason:     g.WaitReason,
 		Labels:         g.Labels(),
 		Status:         g.Status,
+		Frozen:         tgt.IsFrozen(g.ID),
+		Stack:          GoroutineStack(g.Stack(tgt)),
+		Activity:       proc.GoroutineActivity(tgt, g).String(),
 	}
 }
 
@@ -329,6 +390,64 @@ func ConvertLocation(loc proc.Location) Location {
 	}
 }
 
+// ConvertBranchTraceEvent converts from proc.BranchTraceEvent to
+// api.BranchTraceEvent.
+func ConvertBranchTraceEvent(bi *proc.BinaryInfo, ev proc.BranchTraceEvent) BranchTraceEvent {
+	loc := func(pc uint64) Location {
+		if pc == 0 {
+			return Location{}
+		}
+		file, line, fn := bi.PCToLine(pc)
+		return ConvertLocation(proc.Location{PC: pc, File: file, Line: line, Fn: fn})
+	}
+	r := BranchTraceEvent{From: loc(ev.From), To: loc(ev.To)}
+	switch ev.Kind {
+	case proc.BranchTraceCall:
+		r.Kind = BranchTraceCall
+	case proc.BranchTraceReturn:
+		r.Kind = BranchTraceReturn
+	case proc.BranchTraceJump:
+		r.Kind = BranchTraceJump
+	case proc.BranchTraceGap:
+		r.Kind = BranchTraceGap
+	}
+	return r
+}
+
+// ConvertStopReason converts from proc.StopReason to api.StopKind.
+func ConvertStopReason(sr proc.StopReason) StopKind {
+	switch sr {
+	case proc.StopLaunched:
+		return StopLaunched
+	case proc.StopAttached:
+		return StopAttached
+	case proc.StopExited:
+		return StopExited
+	case proc.StopBreakpoint:
+		return StopBreakpoint
+	case proc.StopHardcodedBreakpoint:
+		return StopHardcodedBreakpoint
+	case proc.StopManual:
+		return StopManual
+	case proc.StopNextFinished:
+		return StopStepComplete
+	case proc.StopCallReturned:
+		return StopCallReturned
+	case proc.StopWatchpoint:
+		return StopWatchpoint
+	case proc.StopPanic:
+		return StopPanic
+	case proc.StopFatalThrow:
+		return StopFatalThrow
+	case proc.StopTracepoint:
+		return StopTracepoint
+	case proc.StopSignal:
+		return StopSignal
+	default:
+		return StopUnknown
+	}
+}
+
 // ConvertAsmInstruction converts from proc.AsmInstruction to api.AsmInstruction.
 func ConvertAsmInstruction(inst proc.AsmInstruction, text string) AsmInstruction {
 	var destloc *Location
@@ -446,3 +565,54 @@ func ConvertDumpState(dumpState *proc.DumpState) *DumpState {
 	}
 	return r
 }
+
+// ConvertMemoryRegion converts a proc.MemoryMapEntry to an api.MemoryRegion.
+func ConvertMemoryRegion(m *proc.MemoryMapEntry) *MemoryRegion {
+	if m == nil {
+		return nil
+	}
+	return &MemoryRegion{
+		Addr:     m.Addr,
+		Size:     m.Size,
+		Read:     m.Read,
+		Write:    m.Write,
+		Exec:     m.Exec,
+		Filename: m.Filename,
+		Offset:   m.Offset,
+	}
+}
+
+// ConvertAnnotatedMemoryRegion converts a proc.MemoryRegion to an
+// api.MemoryRegion.
+func ConvertAnnotatedMemoryRegion(m *proc.MemoryRegion) *MemoryRegion {
+	r := ConvertMemoryRegion(&m.MemoryMapEntry)
+	r.Image = m.Image
+	r.GoHeap = m.GoHeap
+	r.GoroutineStacks = m.GoroutineStacks
+	return r
+}
+
+// ConvertBuildInfo converts a proc.BuildInfo to an api.BuildInfo.
+func ConvertBuildInfo(bi *proc.BuildInfo) *BuildInfo {
+	r := &BuildInfo{
+		GoVersion: bi.GoVersion,
+		Path:      bi.Path,
+		Main:      convertModule(&bi.Main),
+	}
+	for _, dep := range bi.Deps {
+		r.Deps = append(r.Deps, convertModule(dep))
+	}
+	for _, s := range bi.Settings {
+		r.Settings = append(r.Settings, BuildSetting{Key: s.Key, Value: s.Value})
+	}
+	return r
+}
+
+func convertModule(m *proc.Module) Module {
+	r := Module{Path: m.Path, Version: m.Version, Sum: m.Sum}
+	if m.Replace != nil {
+		replace := convertModule(m.Replace)
+		r.Replace = &replace
+	}
+	return r
+}
@@ -0,0 +1,18 @@
+package api
+
+import "testing"
+
+func TestDemangleShapes(t *testing.T) {
+	for _, tc := range []struct{ in, tgt string }{
+		{"int", "int"},
+		{"go.shape.int", "int"},
+		{"go.shape.string_0", "string"},
+		{"main.Pair[go.shape.string_0,go.shape.int_1]", "main.Pair[string,int]"},
+		{"map[go.shape.string]go.shape.*uint8", "map[string]*uint8"},
+		{"main.Pair[go.shape.map[string]int,go.shape.int]", "main.Pair[map[string]int,int]"},
+	} {
+		if out := demangleShapes(tc.in); out != tc.tgt {
+			t.Errorf("demangleShapes(%q): expected %q got %q", tc.in, tc.tgt, out)
+		}
+	}
+}
@@ -54,6 +54,12 @@ func (v *Variable) writeTo(buf io.Writer, top, newlines, includeType bool, inden
 		return
 	}
 
+	if v.Value != "" && (v.Kind == reflect.Struct || v.Kind == reflect.Slice) {
+		// summary of the value produced by a pretty-printer
+		fmt.Fprintf(buf, "%s(%s)", v.Type, v.Value)
+		return
+	}
+
 	switch v.Kind {
 	case reflect.Slice:
 		v.writeSliceTo(buf, newlines, includeType, indent, fmtstr)
@@ -481,6 +487,40 @@ func PrettyExamineMemory(address uintptr, memArea []byte, isLittleEndian bool, f
 	return b.String()
 }
 
+// PrettyHexDump formats memArea as a canonical hex and ASCII dump, sixteen
+// bytes per row, each row starting with its offset. The offset of the first
+// byte of memArea is offset.
+func PrettyHexDump(memArea []byte, offset int) string {
+	const cols = 16
+	var b strings.Builder
+	for i := 0; i < len(memArea); i += cols {
+		row := memArea[i:]
+		if len(row) > cols {
+			row = row[:cols]
+		}
+		fmt.Fprintf(&b, "%08x  ", offset+i)
+		for j := 0; j < cols; j++ {
+			if j < len(row) {
+				fmt.Fprintf(&b, "%02x ", row[j])
+			} else {
+				b.WriteString("   ")
+			}
+			if j == cols/2-1 {
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteString(" |")
+		for _, c := range row {
+			if c < 0x20 || c > 0x7e {
+				c = '.'
+			}
+			b.WriteByte(c)
+		}
+		b.WriteString("|\n")
+	}
+	return b.String()
+}
+
 func byteArrayToUInt64(buf []byte, isLittleEndian bool) uint64 {
 	var n uint64
 	if isLittleEndian {
@@ -508,35 +548,44 @@ func PrintStack(formatPath func(string) string, out io.Writer, stack []Stackfram
 	if len(stack) == 0 {
 		return
 	}
+	PrintStackChunk(formatPath, out, stack, 0, len(stack), ind, offsets, include)
+	PrintStackEnd(out, stack, ind)
+}
 
+// PrintStackChunk prints a contiguous subset of the frames of a stacktrace,
+// first is the index of the first frame of chunk in the full stacktrace and
+// total is the number of frames in the full stacktrace. It can be used to
+// print a stacktrace that is retrieved incrementally, PrintStackEnd must be
+// called after the last chunk has been printed.
+func PrintStackChunk(formatPath func(string) string, out io.Writer, chunk []Stackframe, first, total int, ind string, offsets bool, include func(Stackframe) bool) {
 	extranl := offsets
-	for i := range stack {
+	for i := range chunk {
 		if extranl {
 			break
 		}
-		extranl = extranl || (len(stack[i].Defers) > 0) || (len(stack[i].Arguments) > 0) || (len(stack[i].Locals) > 0)
+		extranl = extranl || (len(chunk[i].Defers) > 0) || (len(chunk[i].Arguments) > 0) || (len(chunk[i].Locals) > 0)
 	}
 
-	d := digits(len(stack) - 1)
+	d := digits(total - 1)
 	fmtstr := "%s%" + strconv.Itoa(d) + "d  0x%016x in %s\n"
 	s := ind + strings.Repeat(" ", d+2+len(ind))
 
-	for i := range stack {
-		if !include(stack[i]) {
+	for i := range chunk {
+		if !include(chunk[i]) {
 			continue
 		}
-		if stack[i].Err != "" {
-			fmt.Fprintf(out, "%serror: %s\n", s, stack[i].Err)
+		if chunk[i].Err != "" {
+			fmt.Fprintf(out, "%serror: %s\n", s, chunk[i].Err)
 			continue
 		}
-		fmt.Fprintf(out, fmtstr, ind, i, stack[i].PC, stack[i].Function.Name())
-		fmt.Fprintf(out, "%sat %s:%d\n", s, formatPath(stack[i].File), stack[i].Line)
+		fmt.Fprintf(out, fmtstr, ind, first+i, chunk[i].PC, chunk[i].Function.Name())
+		fmt.Fprintf(out, "%sat %s:%d\n", s, formatPath(chunk[i].File), chunk[i].Line)
 
 		if offsets {
-			fmt.Fprintf(out, "%sframe: %+#x frame pointer %+#x\n", s, stack[i].FrameOffset, stack[i].FramePointerOffset)
+			fmt.Fprintf(out, "%sframe: %+#x frame pointer %+#x\n", s, chunk[i].FrameOffset, chunk[i].FramePointerOffset)
 		}
 
-		for j, d := range stack[i].Defers {
+		for j, d := range chunk[i].Defers {
 			deferHeader := fmt.Sprintf("%s    defer %d: ", s, j+1)
 			s2 := strings.Repeat(" ", len(deferHeader))
 			if d.Unreadable != "" {
@@ -548,18 +597,22 @@ func PrintStack(formatPath func(string) string, out io.Writer, stack []Stackfram
 			fmt.Fprintf(out, "%sdeferred by %s at %s:%d\n", s2, d.DeferLoc.Function.Name(), formatPath(d.DeferLoc.File), d.DeferLoc.Line)
 		}
 
-		for j := range stack[i].Arguments {
-			fmt.Fprintf(out, "%s    %s = %s\n", s, stack[i].Arguments[j].Name, stack[i].Arguments[j].SinglelineString())
+		for j := range chunk[i].Arguments {
+			fmt.Fprintf(out, "%s    %s = %s\n", s, chunk[i].Arguments[j].Name, chunk[i].Arguments[j].SinglelineString())
 		}
-		for j := range stack[i].Locals {
-			fmt.Fprintf(out, "%s    %s = %s\n", s, stack[i].Locals[j].Name, stack[i].Locals[j].SinglelineString())
+		for j := range chunk[i].Locals {
+			fmt.Fprintf(out, "%s    %s = %s\n", s, chunk[i].Locals[j].Name, chunk[i].Locals[j].SinglelineString())
 		}
 
 		if extranl {
 			fmt.Fprintln(out)
 		}
 	}
+}
 
+// PrintStackEnd prints the truncation marker for stack if the stacktrace
+// did not reach the bottom of the stack.
+func PrintStackEnd(out io.Writer, stack []Stackframe, ind string) {
 	if len(stack) > 0 && !stack[len(stack)-1].Bottom {
 		fmt.Fprintf(out, "%s"+stacktraceTruncatedMessage+"\n", ind)
 	}
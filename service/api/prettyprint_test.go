@@ -34,6 +34,15 @@ func TestPrettyExamineMemory(t *testing.T) {
 	}
 }
 
+func TestPrettyHexDump(t *testing.T) {
+	res := PrettyHexDump([]byte("hello world\n\x00\x01\xffABCDEFGHIJ"), 0x20)
+	tgt := "00000020  68 65 6c 6c 6f 20 77 6f  72 6c 64 0a 00 01 ff 41  |hello world....A|\n" +
+		"00000030  42 43 44 45 46 47 48 49  4a                       |BCDEFGHIJ|\n"
+	if res != tgt {
+		t.Errorf("wrong hex dump, expected:\n%s\ngot:\n%s", tgt, res)
+	}
+}
+
 func Test_byteArrayToUInt64(t *testing.T) {
 	tests := []struct {
 		name string
@@ -0,0 +1,456 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EvalFunc evaluates expr in the selected goroutine with cfg, the
+// pretty-printers use it to read the parts of a value they need.
+type EvalFunc func(expr string, cfg LoadConfig) (*Variable, error)
+
+// PrettyPrinter returns a summary of the logical contents of v, a variable
+// of the type the printer is registered for, that clients show instead of
+// the fields of v. Printers that need more of the value than was loaded
+// read it again by address with eval, cfg is the load configuration of the
+// client and limits the length of the summary.
+type PrettyPrinter func(eval EvalFunc, v *Variable, cfg LoadConfig) (string, error)
+
+// BuiltinPrettyPrinters are the pretty-printers of the containers and
+// values of the standard library whose internals are opaque, indexed by
+// type name.
+var BuiltinPrettyPrinters = map[string]PrettyPrinter{
+	"time.Time":    printTime,
+	"math/big.Int": printBigInt,
+	"net.IP":       printNetIP,
+	"bytes.Buffer": printBytesBuffer,
+	"sync.Map":     printSyncMap,
+}
+
+// reloadVariable evaluates v again, using its address, with cfg.
+func reloadVariable(eval EvalFunc, v *Variable, cfg LoadConfig) (*Variable, error) {
+	if v.Addr == 0 {
+		return nil, errors.New("variable has no address")
+	}
+	return eval(fmt.Sprintf("*(*%q)(%#x)", v.Type, v.Addr), cfg)
+}
+
+// varField returns the field name of the struct v.
+func varField(v *Variable, name string) (*Variable, error) {
+	for i := range v.Children {
+		if v.Children[i].Name == name {
+			if v.Children[i].Unreadable != "" {
+				return nil, fmt.Errorf("field %s unreadable: %s", name, v.Children[i].Unreadable)
+			}
+			return &v.Children[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no field %s in %s", name, v.Type)
+}
+
+// varInt returns the value of the integer field name of the struct v.
+func varInt(v *Variable, name string) (int64, error) {
+	f, err := varField(v, name)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(f.Value, 10, 64)
+}
+
+// varBytes returns the loaded elements of the []byte v.
+func varBytes(v *Variable) ([]byte, error) {
+	r := make([]byte, 0, len(v.Children))
+	for i := range v.Children {
+		n, err := strconv.ParseUint(v.Children[i].Value, 10, 8)
+		if err != nil {
+			return nil, err
+		}
+		r = append(r, byte(n))
+	}
+	return r, nil
+}
+
+// Layout of the wall and ext fields of time.Time, see $GOROOT/src/time/time.go.
+const (
+	timeHasMonotonic   = 1 << 63
+	timeNsecShift      = 30
+	timeNsecMask       = 1<<timeNsecShift - 1
+	timeWallToInternal = (1884*365 + 1884/4 - 1884/100 + 1884/400) * 24 * 60 * 60
+	timeUnixToInternal = (1969*365 + 1969/4 - 1969/100 + 1969/400) * 24 * 60 * 60
+	timeFormat         = "2006-01-02 15:04:05.999999999 -0700 MST"
+
+	// timeMaxTransitions is the maximum number of transitions of a
+	// time.Location read to find the zone of a time.
+	timeMaxTransitions = 1024
+)
+
+func printTime(eval EvalFunc, v *Variable, _ LoadConfig) (string, error) {
+	v, err := reloadVariable(eval, v, LoadConfig{FollowPointers: true, MaxVariableRecurse: 1, MaxStringLen: 64, MaxStructFields: -1})
+	if err != nil {
+		return "", err
+	}
+	wallv, err := varField(v, "wall")
+	if err != nil {
+		return "", err
+	}
+	wall, err := strconv.ParseUint(wallv.Value, 10, 64)
+	if err != nil {
+		return "", err
+	}
+	ext, err := varInt(v, "ext")
+	if err != nil {
+		return "", err
+	}
+	tm := timeFromFields(wall, ext)
+	locv, err := varField(v, "loc")
+	if err != nil || len(locv.Children) != 1 || locv.Children[0].Addr == 0 {
+		// a nil location is UTC
+		return formatTime(tm, time.UTC), nil
+	}
+	loc, name := targetLocation(eval, locv.Children[0].Addr, tm.Unix())
+	if loc == nil {
+		return formatTime(tm, time.UTC) + " (" + name + ")", nil
+	}
+	return formatTime(tm, loc), nil
+}
+
+// timeFromFields returns the time.Time with the given wall and ext fields.
+func timeFromFields(wall uint64, ext int64) time.Time {
+	var sec int64
+	if wall&timeHasMonotonic != 0 {
+		sec = timeWallToInternal + int64(wall<<1>>(timeNsecShift+1))
+	} else {
+		sec = ext
+	}
+	return time.Unix(sec-timeUnixToInternal, int64(wall&timeNsecMask))
+}
+
+// formatTime formats tm in the location loc.
+func formatTime(tm time.Time, loc *time.Location) string {
+	return tm.In(loc).Format(timeFormat)
+}
+
+// targetLocation returns the zone in effect at the Unix time sec in the
+// time.Location of the target at addr, as a fixed zone, and the name of
+// the location. The zone is looked up in the data of the location in the
+// target, like time.Location.lookup does, rather than by loading the
+// location by name, because the Local location of the target is not the
+// one of the debugger. If the zone can't be determined nil is returned.
+func targetLocation(eval EvalFunc, addr uint64, sec int64) (*time.Location, string) {
+	l, err := eval(fmt.Sprintf("*(*time.Location)(%#x)", addr), LoadConfig{FollowPointers: true, MaxVariableRecurse: 3, MaxStringLen: 64, MaxArrayValues: timeMaxTransitions, MaxStructFields: -1})
+	if err != nil {
+		return nil, "unknown location"
+	}
+	name := "unknown location"
+	if namev, err := varField(l, "name"); err == nil {
+		name = namev.Value
+	}
+	fail := func() (*time.Location, string) { return nil, name }
+
+	type zone struct {
+		name   string
+		offset int64
+		isDST  bool
+	}
+	readZone := func(v *Variable) (zone, error) {
+		namev, err := varField(v, "name")
+		if err != nil {
+			return zone{}, err
+		}
+		isDSTv, err := varField(v, "isDST")
+		if err != nil {
+			return zone{}, err
+		}
+		offset, err := varInt(v, "offset")
+		return zone{namev.Value, offset, isDSTv.Value == "true"}, err
+	}
+	fixed := func(z zone) (*time.Location, string) { return time.FixedZone(z.name, int(z.offset)), name }
+
+	zonev, err := varField(l, "zone")
+	if err != nil {
+		return fail()
+	}
+	if len(zonev.Children) == 0 {
+		if zonev.Len == 0 {
+			return time.UTC, name
+		}
+		return fail()
+	}
+	zones := make([]zone, len(zonev.Children))
+	for i := range zonev.Children {
+		if zones[i], err = readZone(&zonev.Children[i]); err != nil {
+			return fail()
+		}
+	}
+
+	if cachev, err := varField(l, "cacheZone"); err == nil && len(cachev.Children) == 1 && cachev.Children[0].Addr != 0 {
+		start, err1 := varInt(l, "cacheStart")
+		end, err2 := varInt(l, "cacheEnd")
+		if err1 == nil && err2 == nil && start <= sec && sec < end {
+			if z, err := readZone(&cachev.Children[0]); err == nil {
+				return fixed(z)
+			}
+		}
+	}
+
+	txv, err := varField(l, "tx")
+	if err != nil || int64(len(txv.Children)) < txv.Len {
+		return fail()
+	}
+	type zoneTrans struct {
+		when  int64
+		index int64
+	}
+	tx := make([]zoneTrans, len(txv.Children))
+	for i := range txv.Children {
+		if tx[i].when, err = varInt(&txv.Children[i], "when"); err != nil {
+			return fail()
+		}
+		if tx[i].index, err = varInt(&txv.Children[i], "index"); err != nil || tx[i].index >= int64(len(zones)) {
+			return fail()
+		}
+	}
+
+	if len(tx) == 0 || sec < tx[0].when {
+		// see time.Location.lookupFirstZone
+		firstZoneUsed := false
+		for i := range tx {
+			if tx[i].index == 0 {
+				firstZoneUsed = true
+				break
+			}
+		}
+		if !firstZoneUsed {
+			return fixed(zones[0])
+		}
+		if len(tx) > 0 && zones[tx[0].index].isDST {
+			for zi := tx[0].index - 1; zi >= 0; zi-- {
+				if !zones[zi].isDST {
+					return fixed(zones[zi])
+				}
+			}
+		}
+		for _, z := range zones {
+			if !z.isDST {
+				return fixed(z)
+			}
+		}
+		return fixed(zones[0])
+	}
+
+	i := sort.Search(len(tx), func(i int) bool { return tx[i].when > sec }) - 1
+	if i == len(tx)-1 {
+		// after the last transition the zone is computed from the TZ
+		// string in extend, if there is one.
+		if extend, err := varField(l, "extend"); err == nil && extend.Value != "" {
+			return fail()
+		}
+	}
+	return fixed(zones[tx[i].index])
+}
+
+func printBigInt(eval EvalFunc, v *Variable, _ LoadConfig) (string, error) {
+	v, err := reloadVariable(eval, v, LoadConfig{MaxVariableRecurse: 1, MaxArrayValues: 64, MaxStructFields: -1})
+	if err != nil {
+		return "", err
+	}
+	negv, err := varField(v, "neg")
+	if err != nil {
+		return "", err
+	}
+	abs, err := varField(v, "abs")
+	if err != nil {
+		return "", err
+	}
+	if int64(len(abs.Children)) < abs.Len {
+		return "", errors.New("number too large")
+	}
+	words := make([]uint64, len(abs.Children))
+	for i := range abs.Children {
+		if words[i], err = strconv.ParseUint(abs.Children[i].Value, 10, 64); err != nil {
+			return "", err
+		}
+	}
+	wordBits := uint(64)
+	if len(abs.Children) > 1 {
+		wordBits = uint(abs.Children[1].Addr-abs.Children[0].Addr) * 8
+	}
+	return formatBigInt(negv.Value == "true", words, wordBits), nil
+}
+
+// formatBigInt formats the big.Int with sign neg and absolute value words,
+// a little-endian sequence of wordBits bits words.
+func formatBigInt(neg bool, words []uint64, wordBits uint) string {
+	x := new(big.Int)
+	for i := len(words) - 1; i >= 0; i-- {
+		x.Lsh(x, wordBits)
+		x.Or(x, new(big.Int).SetUint64(words[i]))
+	}
+	if neg {
+		x.Neg(x)
+	}
+	return x.String()
+}
+
+func printNetIP(eval EvalFunc, v *Variable, _ LoadConfig) (string, error) {
+	if v.Len > net.IPv6len {
+		return "", errors.New("invalid IP address")
+	}
+	if int64(len(v.Children)) < v.Len {
+		var err error
+		if v, err = reloadVariable(eval, v, LoadConfig{MaxArrayValues: net.IPv6len}); err != nil {
+			return "", err
+		}
+	}
+	b, err := varBytes(v)
+	if err != nil {
+		return "", err
+	}
+	return net.IP(b).String(), nil
+}
+
+func printBytesBuffer(eval EvalFunc, v *Variable, lc LoadConfig) (string, error) {
+	v, err := reloadVariable(eval, v, LoadConfig{MaxVariableRecurse: 1, MaxStructFields: -1})
+	if err != nil {
+		return "", err
+	}
+	if _, err := varField(v, "buf"); err != nil {
+		return "", err
+	}
+	off, err := varInt(v, "off")
+	if err != nil {
+		return "", err
+	}
+	// the unread portion of the buffer is buf[off:]
+	buf, err := eval(fmt.Sprintf("(*(*%q)(%#x)).buf[%d:]", v.Type, v.Addr, off), LoadConfig{MaxArrayValues: lc.MaxStringLen})
+	if err != nil {
+		return "", err
+	}
+	b, err := varBytes(buf)
+	if err != nil {
+		return "", err
+	}
+	s := strconv.Quote(string(b))
+	if more := buf.Len - int64(len(b)); more > 0 {
+		s += fmt.Sprintf("...+%d more", more)
+	}
+	return s, nil
+}
+
+// printSyncMap prints the entries of a sync.Map, see
+// $GOROOT/src/sync/map.go. The entries are in the map read, unless it is
+// amended, in which case the map dirty contains all of them. Deleted
+// entries point to nil or to sync.expunged.
+func printSyncMap(eval EvalFunc, v *Variable, lc LoadConfig) (string, error) {
+	if v.Addr == 0 {
+		return "", errors.New("variable has no address")
+	}
+	cfg := LoadConfig{FollowPointers: true, MaxVariableRecurse: 2, MaxStringLen: lc.MaxStringLen, MaxArrayValues: lc.MaxArrayValues, MaxStructFields: -1}
+	evalm := func(expr string) (*Variable, error) {
+		return eval(expr, cfg)
+	}
+	m := fmt.Sprintf("(*(*%q)(%#x))", v.Type, v.Addr)
+
+	readv, err := evalm(m + ".read.v")
+	if err != nil {
+		return "", err
+	}
+	var readOnly *Variable
+	switch readv.Kind {
+	case reflect.Interface:
+		// up to Go 1.19 read is an atomic.Value storing a readOnly
+		if len(readv.Children) == 1 && readv.Children[0].Kind == reflect.Struct {
+			readOnly = &readv.Children[0]
+		}
+	case reflect.UnsafePointer:
+		// since Go 1.20 read is an atomic.Pointer[readOnly]
+		if len(readv.Children) == 1 && readv.Children[0].Addr != 0 {
+			if readOnly, err = evalm(fmt.Sprintf("*(*sync.readOnly)(%#x)", readv.Children[0].Addr)); err != nil {
+				return "", err
+			}
+		}
+	default:
+		return "", fmt.Errorf("unknown layout of %s", v.Type)
+	}
+
+	var entries *Variable
+	if readOnly != nil {
+		amended, err := varField(readOnly, "amended")
+		if err != nil {
+			return "", err
+		}
+		if amended.Value != "true" {
+			if entries, err = varField(readOnly, "m"); err != nil {
+				return "", err
+			}
+		}
+	}
+	if entries == nil {
+		if entries, err = evalm(m + ".dirty"); err != nil {
+			return "", err
+		}
+	}
+
+	var expunged uint64
+	if e, err := evalm("sync.expunged"); err == nil && len(e.Children) == 1 {
+		expunged = e.Children[0].Addr
+	}
+
+	type entry struct{ key, value string }
+	var r []entry
+	for i := 0; i+1 < len(entries.Children); i += 2 {
+		key, e := &entries.Children[i], &entries.Children[i+1]
+		if len(e.Children) != 1 || e.Children[0].Addr == 0 {
+			continue
+		}
+		// p is the first field of sync.entry, either an unsafe.Pointer or
+		// an atomic.Pointer[any]
+		pv, err := evalm(fmt.Sprintf("*(*uintptr)(%#x)", e.Children[0].Addr))
+		if err != nil {
+			return "", err
+		}
+		p, err := strconv.ParseUint(pv.Value, 10, 64)
+		if err != nil {
+			return "", err
+		}
+		if p == 0 || p == expunged {
+			continue
+		}
+		val, err := evalm(fmt.Sprintf("*(*interface {})(%#x)", p))
+		if err != nil {
+			return "", err
+		}
+		r = append(r, entry{concreteString(key), concreteString(val)})
+	}
+	sort.Slice(r, func(i, j int) bool { return r[i].key < r[j].key })
+
+	var buf strings.Builder
+	buf.WriteString("[")
+	for i, e := range r {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		fmt.Fprintf(&buf, "%s: %s", e.key, e.value)
+	}
+	if more := entries.Len - int64(len(entries.Children)/2); more > 0 {
+		fmt.Fprintf(&buf, ", ...+%d more", more)
+	}
+	buf.WriteString("]")
+	return buf.String(), nil
+}
+
+// concreteString returns the representation of the value stored in the
+// interface v, or of v itself if it isn't an interface.
+func concreteString(v *Variable) string {
+	if v.Kind == reflect.Interface && len(v.Children) == 1 && v.Children[0].Kind != reflect.Invalid {
+		return v.Children[0].SinglelineString()
+	}
+	return v.SinglelineString()
+}
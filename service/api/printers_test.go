@@ -0,0 +1,80 @@
+package api
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestFormatTime(t *testing.T) {
+	tm := timeFromFields(0, 63750448800)
+	if s := formatTime(tm, time.UTC); s != "2021-03-04 10:00:00 +0000 UTC" {
+		t.Errorf("formatTime: %q", s)
+	}
+	if s := formatTime(tm, time.FixedZone("XYZ", 3*60*60)); s != "2021-03-04 13:00:00 +0300 XYZ" {
+		t.Errorf("formatTime fixed zone: %q", s)
+	}
+}
+
+func TestFormatBigInt(t *testing.T) {
+	if s := formatBigInt(true, []uint64{0, 1}, 64); s != "-18446744073709551616" {
+		t.Errorf("formatBigInt: %q", s)
+	}
+	if s := formatBigInt(false, []uint64{5, 1}, 32); s != "4294967301" {
+		t.Errorf("formatBigInt 32bit: %q", s)
+	}
+}
+
+func TestTargetLocation(t *testing.T) {
+	intv := func(name string, n int64) Variable {
+		return Variable{Name: name, Kind: reflect.Int64, Value: strconv.FormatInt(n, 10)}
+	}
+	zone := func(name string, offset int64, isDST bool) Variable {
+		return Variable{Kind: reflect.Struct, Children: []Variable{
+			{Name: "name", Kind: reflect.String, Value: name},
+			intv("offset", offset),
+			{Name: "isDST", Kind: reflect.Bool, Value: strconv.FormatBool(isDST)},
+		}}
+	}
+	trans := func(when, index int64) Variable {
+		return Variable{Kind: reflect.Struct, Children: []Variable{intv("when", when), intv("index", index)}}
+	}
+	// a location with standard time before 1000 and summer time after it
+	loc := &Variable{Kind: reflect.Struct, Children: []Variable{
+		{Name: "name", Kind: reflect.String, Value: "Local"},
+		{Name: "zone", Kind: reflect.Slice, Len: 2, Children: []Variable{zone("STD", 3600, false), zone("DST", 7200, true)}},
+		{Name: "tx", Kind: reflect.Slice, Len: 1, Children: []Variable{trans(1000, 1)}},
+		{Name: "extend", Kind: reflect.String},
+		{Name: "cacheStart", Kind: reflect.Int64, Value: "0"},
+		{Name: "cacheEnd", Kind: reflect.Int64, Value: "0"},
+		{Name: "cacheZone", Kind: reflect.Ptr, Children: []Variable{{}}},
+	}}
+	eval := func(expr string, cfg LoadConfig) (*Variable, error) {
+		return loc, nil
+	}
+
+	for _, tc := range []struct {
+		sec  int64
+		zone string
+	}{
+		{0, "STD"},
+		{1000, "DST"},
+		{2000, "DST"},
+	} {
+		l, name := targetLocation(eval, 0x1000, tc.sec)
+		if l == nil || name != "Local" {
+			t.Errorf("%d: got %v %q", tc.sec, l, name)
+			continue
+		}
+		if zone, _ := time.Unix(tc.sec, 0).In(l).Zone(); zone != tc.zone {
+			t.Errorf("%d: got zone %q, expected %q", tc.sec, zone, tc.zone)
+		}
+	}
+
+	// after the last transition the zone depends on extend
+	loc.Children[3].Value = "STD-1DST,M3.5.0,M10.5.0/3"
+	if l, name := targetLocation(eval, 0x1000, 2000); l != nil || name != "Local" {
+		t.Errorf("extended location: got %v %q", l, name)
+	}
+}
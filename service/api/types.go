@@ -2,10 +2,12 @@ package api
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
 	"strconv"
+	"time"
 	"unicode"
 
 	"github.com/go-delve/delve/pkg/proc"
@@ -44,25 +46,95 @@ type DebuggerState struct {
 	ExitStatus int  `json:"exitStatus"`
 	// When contains a description of the current position in a recording
 	When string
+	// ContinueIterations is the number of times execution was resumed by a
+	// continue command with the Until option. While the command runs it is
+	// also reported by the non-blocking state.
+	ContinueIterations int `json:"continueIterations,omitempty"`
+	// StopReason describes why the target is stopped.
+	StopReason StopReason `json:"stopReason"`
 	// Filled by RPCClient.Continue, indicates an error
 	Err error `json:"-"`
 }
 
+// ExitError returns the error describing how process pid exited, for a
+// state with Exited set.
+func (s *DebuggerState) ExitError(pid int) error {
+	if s.StopReason.Signal == "" {
+		return fmt.Errorf("Process %d has exited with status %d", pid, s.ExitStatus)
+	}
+	coreDumped := ""
+	if s.StopReason.CoreDumped {
+		coreDumped = ", core dumped"
+	}
+	return fmt.Errorf("Process %d has exited with status %d (killed by %s%s)", pid, s.ExitStatus, s.StopReason.Signal, coreDumped)
+}
+
+// StopKind is the kind of event that stopped the target.
+type StopKind string
+
+const (
+	StopUnknown             StopKind = "unknown"
+	StopLaunched            StopKind = "launched"
+	StopAttached            StopKind = "attached"
+	StopBreakpoint          StopKind = "breakpoint"
+	StopTracepoint          StopKind = "tracepoint"
+	StopWatchpoint          StopKind = "watchpoint"
+	StopHardcodedBreakpoint StopKind = "hardcoded breakpoint"
+	StopPanic               StopKind = "panic"
+	StopFatalThrow          StopKind = "fatal throw"
+	StopManual              StopKind = "manual"
+	StopStepComplete        StopKind = "step complete"
+	StopCallReturned        StopKind = "call returned"
+	StopExited              StopKind = "exited"
+	StopSignal              StopKind = "signal"
+)
+
+// StopReason describes why the target is stopped.
+type StopReason struct {
+	Kind StopKind `json:"kind"`
+	// BreakpointID is the ID of the breakpoint that stopped the current
+	// thread, for the breakpoint, tracepoint, watchpoint, panic and fatal
+	// throw kinds.
+	BreakpointID int `json:"breakpointID,omitempty"`
+	// Signal is the name of the signal that killed the target, if it
+	// exited because of a signal, or of the fault signal received by the
+	// current thread for the signal kind.
+	Signal string `json:"signal,omitempty"`
+	// CoreDumped is true if the signal that killed the target produced a
+	// core dump.
+	CoreDumped bool `json:"coreDumped,omitempty"`
+	// SignalCode is the si_code of the fault signal, for the signal kind.
+	SignalCode int `json:"signalCode,omitempty"`
+	// FaultAddr is the faulting memory address (si_addr), for the signal
+	// kind.
+	FaultAddr uint64 `json:"faultAddr,omitempty"`
+}
+
 // Breakpoint addresses a set of locations at which process execution may be
 // suspended.
 type Breakpoint struct {
-	// ID is a unique identifier for the breakpoint.
+	// ID is a unique identifier for the breakpoint. It identifies the
+	// logical breakpoint: a single logical breakpoint can be set on multiple
+	// addresses (see Addrs), for example when a function is inlined, and
+	// hits on any of them are reported with this ID.
 	ID int `json:"id"`
 	// User defined name of the breakpoint.
 	Name string `json:"name"`
+	// Groups are the user defined groups the breakpoint belongs to, all the
+	// breakpoints of a group can be enabled, disabled or cleared at once.
+	Groups []string `json:"groups,omitempty"`
 	// Addr is deprecated, use Addrs.
 	Addr uint64 `json:"addr"`
-	// Addrs is the list of addresses for this breakpoint.
+	// Addrs is the list of addresses of the physical breakpoints
+	// associated with this logical breakpoint.
 	Addrs []uint64 `json:"addrs"`
 	// File is the source file for the breakpoint.
 	File string `json:"file"`
 	// Line is a line in File for the breakpoint.
 	Line int `json:"line"`
+	// Column is the column of Line the breakpoint was resolved to, 0 if the
+	// breakpoint was set on the whole line.
+	Column int `json:"column,omitempty"`
 	// FunctionName is the name of the function at the current breakpoint, and
 	// may not always be available.
 	FunctionName string `json:"functionName,omitempty"`
@@ -72,6 +144,10 @@ type Breakpoint struct {
 	// Breakpoint hit count condition.
 	// Supported hit count conditions are "NUMBER" and "OP NUMBER".
 	HitCond string
+	// Caller is the name of the function that must have called the
+	// function containing the breakpoint for it to trigger, a path suffix
+	// like "mypkg.HandleRequest" is enough.
+	Caller string `json:"caller,omitempty"`
 
 	// Tracepoint flag, signifying this is a tracepoint.
 	Tracepoint bool `json:"continue"`
@@ -92,6 +168,12 @@ type Breakpoint struct {
 	// WatchExpr is the expression used to create this watchpoint
 	WatchExpr string
 	WatchType WatchType
+	// WatchHistory, if greater than zero, is the number of writes recorded
+	// by this watchpoint, or of hits recorded by this breakpoint, which
+	// does not stop the target, see the WatchHistory API call. The history
+	// of breakpoints is changed with AmendBreakpoint, that of watchpoints
+	// is set when they are created.
+	WatchHistory int `json:"watchHistory,omitempty"`
 
 	// number of times a breakpoint has been reached in a certain goroutine
 	HitCount map[string]uint64 `json:"hitCount"`
@@ -99,6 +181,11 @@ type Breakpoint struct {
 	TotalHitCount uint64 `json:"totalHitCount"`
 	// Disabled flag, signifying the state of the breakpoint
 	Disabled bool `json:"disabled"`
+
+	// VerboseDescr describes the breakpoints set internally by Delve, for
+	// example by next and step. It is only set for the breakpoints returned
+	// by ListBreakpoints when All is true, they have ID 0.
+	VerboseDescr []string `json:"VerboseDescr,omitempty"`
 }
 
 // ValidBreakpointName returns an error if
@@ -119,6 +206,35 @@ func ValidBreakpointName(name string) error {
 	return nil
 }
 
+// BreakpointFilter selects the breakpoints deleted by ClearBreakpoints.
+// A breakpoint is selected if it matches all the non-empty fields, the
+// zero value selects all the breakpoints set by the user.
+type BreakpointFilter struct {
+	// Addrs selects the breakpoints set on one of these addresses.
+	Addrs []uint64 `json:"addrs,omitempty"`
+	// File selects the breakpoints in File, which can also be a suffix of
+	// the path made of whole path components, for example "main.go" or
+	// "pkg/main.go".
+	File string `json:"file,omitempty"`
+	// FunctionName selects the breakpoints in the function FunctionName.
+	FunctionName string `json:"functionName,omitempty"`
+}
+
+// ValidBreakpointGroup returns an error if the name of a breakpoint group
+// is invalid, it must be a non-empty series of letters, numbers, '-' and
+// '_'.
+func ValidBreakpointGroup(group string) error {
+	if group == "" {
+		return errors.New("empty breakpoint group")
+	}
+	for _, ch := range group {
+		if !(unicode.IsLetter(ch) || unicode.IsDigit(ch) || ch == '-' || ch == '_') {
+			return fmt.Errorf("invalid character in breakpoint group '%c'", ch)
+		}
+	}
+	return nil
+}
+
 // WatchType is the watchpoint type
 type WatchType uint8
 
@@ -127,6 +243,26 @@ const (
 	WatchWrite
 )
 
+// WatchHistoryEntry describes a write to the memory location of a
+// watchpoint, or a hit of a breakpoint, recording its history.
+type WatchHistoryEntry struct {
+	GoroutineID int `json:"goroutineID"`
+	// PC is the address of the instruction following the write, for
+	// watchpoints, or the address of the breakpoint.
+	PC       uint64    `json:"pc"`
+	File     string    `json:"file"`
+	Line     int       `json:"line"`
+	Function *Function `json:"function,omitempty"`
+	// Value is the value of the watched expression after the write, it is
+	// empty for breakpoints.
+	Value Variable `json:"value"`
+	// Variables are the values of the Variables expressions of the
+	// breakpoint at the time of the hit.
+	Variables []Variable `json:"variables,omitempty"`
+	// Stacktrace is the stacktrace of the goroutine that wrote the value.
+	Stacktrace []Stackframe `json:"stacktrace,omitempty"`
+}
+
 // Thread is a thread within the debugged process.
 type Thread struct {
 	// ID is a unique identifier for the thread.
@@ -142,6 +278,9 @@ type Thread struct {
 
 	// ID of the goroutine running on this thread
 	GoroutineID int `json:"goroutineID"`
+	// Activity classifies what the thread is doing, one of the Activity
+	// constants.
+	Activity string `json:"activity"`
 
 	// Breakpoint this thread is stopped at
 	Breakpoint *Breakpoint `json:"breakPoint,omitempty"`
@@ -160,9 +299,12 @@ type Thread struct {
 // FindLocations however returns logical locations that can either have
 // multiple PC addresses each (due to inlining) or no PC address at all.
 type Location struct {
-	PC       uint64    `json:"pc"`
-	File     string    `json:"file"`
-	Line     int       `json:"line"`
+	PC   uint64 `json:"pc"`
+	File string `json:"file"`
+	Line int    `json:"line"`
+	// Column is the column of Line the location was resolved to, it is only
+	// set for file:line:col locations.
+	Column   int       `json:"column,omitempty"`
 	Function *Function `json:"function,omitempty"`
 	PCs      []uint64  `json:"pcs,omitempty"`
 }
@@ -176,6 +318,12 @@ type Stackframe struct {
 	FrameOffset        int64
 	FramePointerOffset int64
 
+	// CFA is the canonical frame address of this frame and FramePointer the
+	// value of the frame pointer register, both are absolute addresses and
+	// can be used to correlate frames with the output of examinemem.
+	CFA          uint64 `json:"CFA,omitempty"`
+	FramePointer uint64 `json:"FramePointer,omitempty"`
+
 	Defers []Defer
 
 	Bottom bool `json:"Bottom,omitempty"` // Bottom is true if this is the bottom frame of the stack
@@ -340,6 +488,11 @@ type Goroutine struct {
 	GoStatementLoc Location `json:"goStatementLoc"`
 	// Location of the starting function
 	StartLoc Location `json:"startLoc"`
+	// GoPC is the return address of the go statement that created this
+	// goroutine (g.gopc), StartPC the address of the function it was started
+	// with (g.startpc).
+	GoPC    uint64 `json:"goPC"`
+	StartPC uint64 `json:"startPC"`
 	// ID of the associated thread for running goroutines
 	ThreadID   int    `json:"threadID"`
 	Status     uint64 `json:"status"`
@@ -348,6 +501,64 @@ type Goroutine struct {
 	Unreadable string `json:"unreadable"`
 	// Goroutine's pprof labels
 	Labels map[string]string `json:"labels,omitempty"`
+	// Frozen is true if the goroutine was frozen and will not run when the
+	// target is resumed.
+	Frozen bool `json:"frozen,omitempty"`
+	// Stack describes the bounds and the usage of the goroutine's stack.
+	Stack GoroutineStack `json:"stack"`
+	// Activity classifies what the goroutine is doing, one of the Activity
+	// constants.
+	Activity string `json:"activity"`
+}
+
+// Values of Thread.Activity and Goroutine.Activity.
+const (
+	// ActivityUser is code of the program or of its libraries.
+	ActivityUser = "user"
+	// ActivityRuntime is code of the runtime.
+	ActivityRuntime = "runtime"
+	// ActivitySyscall is a thread or goroutine blocked in a system call.
+	ActivitySyscall = "syscall"
+	// ActivityGC is a thread or goroutine running the garbage collector.
+	ActivityGC = "gc"
+)
+
+// GoroutineStack describes the stack of a goroutine.
+type GoroutineStack struct {
+	Lo uint64 `json:"lo"`
+	Hi uint64 `json:"hi"`
+	// Used is the number of bytes of the stack in use.
+	Used uint64 `json:"used"`
+	// Growths is an estimate of the number of times the stack was grown,
+	// computed from its current size.
+	Growths int `json:"growths"`
+}
+
+// TargetOutput is a chunk of the standard output or standard error of the
+// target.
+type TargetOutput struct {
+	// Data is the output of the target starting at Offset.
+	Data []byte `json:"data"`
+	// Offset is the position of Data in the output stream, it is greater
+	// than the requested offset if the output in between was discarded.
+	// The next chunk of output starts at Offset+len(Data).
+	Offset int64 `json:"offset"`
+}
+
+// GoroutineStacktrace is the stacktrace of a goroutine.
+type GoroutineStacktrace struct {
+	GoroutineID int          `json:"goroutineID"`
+	Locations   []Stackframe `json:"locations"`
+	// Err is set if the stack of the goroutine could not be unwound.
+	Err string `json:"err,omitempty"`
+}
+
+// ThreadStacktrace is the stacktrace of a thread.
+type ThreadStacktrace struct {
+	ThreadID  int          `json:"threadID"`
+	Locations []Stackframe `json:"locations"`
+	// Err is set if the stack of the thread could not be unwound.
+	Err string `json:"err,omitempty"`
 }
 
 const (
@@ -385,6 +596,13 @@ type DebuggerCommand struct {
 	// violate the rules about stack objects you can disable this safety check
 	// by setting UnsafeCall to true.
 	UnsafeCall bool `json:"unsafeCall,omitempty"`
+
+	// Options contains the JSON encoded options specific to the command, the
+	// type of the options depends on Name (for example NextOptions for the
+	// Next command), see NewCommand and DecodeOptions.
+	// The fields ThreadID, GoroutineID, Expr and UnsafeCall are superseded by
+	// Options and kept for backwards compatibility.
+	Options json.RawMessage `json:"options,omitempty"`
 }
 
 // BreakpointInfo contains informations about the current breakpoint
@@ -482,6 +700,9 @@ type GetVersionOut struct {
 	APIVersion      int
 	Backend         string // backend currently in use
 	TargetGoVersion string
+	// TargetBuildID is the build ID of the executable of the target, see
+	// proc.BinaryInfo.BuildID.
+	TargetBuildID string
 
 	MinSupportedVersionOfGo string
 	MaxSupportedVersionOfGo string
@@ -496,6 +717,87 @@ type SetAPIVersionIn struct {
 type SetAPIVersionOut struct {
 }
 
+// ClientInfo describes a client connected to the server.
+type ClientInfo struct {
+	ID   int
+	Addr string
+	// Driver is true for the client controlling the session.
+	Driver bool
+	// Observer is true for read-only clients.
+	Observer bool
+}
+
+// ListClientsIn is the argument for ListClients.
+type ListClientsIn struct {
+}
+
+// ListClientsOut is the result of ListClients.
+type ListClientsOut struct {
+	Clients []ClientInfo
+	// Self is the ID of the calling client.
+	Self int
+}
+
+// HandOffIn is the argument for HandOff.
+type HandOffIn struct {
+	// To is the ID of the client receiving control of the session, if it
+	// is zero control is released.
+	To int
+}
+
+// HandOffOut is the result of HandOff.
+type HandOffOut struct {
+}
+
+// WaitStateChangeIn is the argument for WaitStateChange.
+type WaitStateChangeIn struct {
+	Seq int
+}
+
+// WaitStateChangeOut is the result of WaitStateChange.
+type WaitStateChangeOut struct {
+	Seq int
+	// ChangedBy is the ID of the client that caused the change.
+	ChangedBy int
+	State     DebuggerState
+}
+
+// WatchExpression is an expression the server evaluates in the selected
+// goroutine every time the target stops.
+type WatchExpression struct {
+	ID   int    `json:"id"`
+	Expr string `json:"expr"`
+	// Value is the value of Expr at the last stop and OldValue its value at
+	// the stop before, they are nil if Expr could not be evaluated.
+	Value    *Variable `json:"value,omitempty"`
+	OldValue *Variable `json:"oldValue,omitempty"`
+	// Changed is true if the value of Expr changed at the last stop.
+	Changed bool `json:"changed"`
+	// Err is the error evaluating Expr at the last stop.
+	Err string `json:"err,omitempty"`
+}
+
+// CommandHooks are the terminal commands executed automatically before and
+// after a command, the server stores them so that all its clients share
+// them.
+type CommandHooks struct {
+	// Pre are executed before the command.
+	Pre []string `json:"pre,omitempty"`
+	// Post are executed after the command, if it succeeds.
+	Post []string `json:"post,omitempty"`
+}
+
+// WaitWatchExpressionsIn is the argument for WaitWatchExpressions.
+type WaitWatchExpressionsIn struct {
+	Seq int
+}
+
+// WaitWatchExpressionsOut is the result of WaitWatchExpressions.
+type WaitWatchExpressionsOut struct {
+	Seq   int
+	Exprs []WatchExpression
+}
+
 // Register holds information on a CPU register.
 type Register struct {
 	Name        string
@@ -546,6 +848,9 @@ type Image struct {
 type Ancestor struct {
 	ID    int64
 	Stack []Stackframe
+	// GoStatementLoc is the location of the go statement that created this
+	// ancestor, it is the point where the previous generation was spawned.
+	GoStatementLoc Location
 
 	Unreadable string
 }
@@ -607,6 +912,7 @@ const (
 	GoroutineLabel                     // the goroutine's label
 	GoroutineRunning                   // the goroutine is running
 	GoroutineUser                      // the goroutine is a user goroutine
+	GoroutineRuntime                   // the goroutine is a goroutine of the runtime, see proc.RuntimeGoroutine
 )
 
 // GoroutineGroup represents a group of goroutines in the return value of
@@ -624,3 +930,204 @@ type GoroutineGroupingOptions struct {
 	MaxGroupMembers int
 	MaxGroups       int
 }
+
+// ProfileKind is the kind of profile collected by the Profile API call.
+type ProfileKind string
+
+const (
+	// CPUProfile is a CPU profile collected while the target runs.
+	CPUProfile ProfileKind = "cpu"
+	// HeapProfile is a heap profile of the target's current state.
+	HeapProfile ProfileKind = "heap"
+)
+
+// MutexState describes the state of a sync.Mutex or sync.RWMutex.
+type MutexState struct {
+	Addr uint64 `json:"addr"`
+	// RW is true for a sync.RWMutex, in which case the fields Locked,
+	// Woken, Starving, Waiters and Queued describe its writer mutex.
+	RW bool `json:"rw"`
+
+	Locked   bool `json:"locked"`
+	Woken    bool `json:"woken"`
+	Starving bool `json:"starving"`
+	// Waiters is the number of waiters recorded in the state word.
+	Waiters int `json:"waiters"`
+	// Queued are the goroutines waiting to acquire the mutex.
+	Queued []*Goroutine `json:"queued"`
+
+	// Readers is the number of readers holding a sync.RWMutex.
+	Readers int `json:"readers"`
+	// WriterPending is true if a writer holds, or is waiting for, a
+	// sync.RWMutex, the writer holds it if Readers is zero.
+	WriterPending bool `json:"writerPending"`
+	// QueuedWriter are the goroutines holding the writer mutex and waiting
+	// for the readers to release a sync.RWMutex.
+	QueuedWriter []*Goroutine `json:"queuedWriter"`
+	// QueuedReaders are the goroutines waiting for a writer to release a
+	// sync.RWMutex.
+	QueuedReaders []*Goroutine `json:"queuedReaders"`
+
+	// Holders are the goroutines that probably hold the mutex, because
+	// they have a deferred call unlocking it. The owner of a mutex is not
+	// recorded by the sync package.
+	Holders []*Goroutine `json:"holders"`
+}
+
+// ChanState describes the state of a channel.
+type ChanState struct {
+	Addr   uint64 `json:"addr"`
+	Len    int64  `json:"len"`
+	Cap    int64  `json:"cap"`
+	Closed bool   `json:"closed"`
+	// Buffer are the elements in the buffer of the channel, in the order
+	// they will be received.
+	Buffer []Variable `json:"buffer"`
+	// Senders are the goroutines blocked sending to the channel.
+	Senders []*Goroutine `json:"senders"`
+	// Receivers are the goroutines blocked receiving from the channel.
+	Receivers []*Goroutine `json:"receivers"`
+}
+
+// Timer is a pending timer of the runtime.
+type Timer struct {
+	Addr uint64 `json:"addr"`
+	// When is the time at which the timer fires, on the monotonic clock of
+	// the runtime of the target.
+	When int64 `json:"when"`
+	// Period is the period of tickers, or zero.
+	Period time.Duration `json:"period"`
+	// Func is the name of the function called by the runtime when the
+	// timer fires.
+	Func string `json:"func"`
+	// Arg is the argument passed to Func, the channel of time.Timer and
+	// time.Ticker or the function called by time.AfterFunc.
+	Arg Variable `json:"arg"`
+}
+
+// TargetInfo describes the process being debugged, it is meant to let
+// clients label debugging sessions.
+type TargetInfo struct {
+	Pid int `json:"pid"`
+	// Path is the path of the executable of the target.
+	Path string `json:"path"`
+	// Args is the command line of the target, Args[0] is the name of the
+	// program.
+	Args []string `json:"args"`
+	// WorkingDir is the working directory of the target, empty if unknown.
+	WorkingDir string `json:"workingDir,omitempty"`
+	// StartTime is when the target was started, the zero time if unknown.
+	StartTime time.Time `json:"startTime"`
+	// GoVersion is the version of Go the executable was built with, for
+	// example "go1.17.2", empty if unknown.
+	GoVersion string `json:"goVersion,omitempty"`
+}
+
+// BuildInfo is the build information embedded in the executable of the
+// target by the Go linker, see runtime/debug.BuildInfo.
+type BuildInfo struct {
+	// GoVersion is the version of the Go toolchain that built the
+	// executable.
+	GoVersion string `json:"goVersion"`
+	// BuildID is the Go build ID of the executable or, if it doesn't have
+	// one, its GNU build ID.
+	BuildID string `json:"buildID,omitempty"`
+	// Path is the package path of the main package.
+	Path string `json:"path,omitempty"`
+	// Main is the module containing the main package.
+	Main Module `json:"main"`
+	// Deps are the dependencies of the main module.
+	Deps []Module `json:"deps,omitempty"`
+	// Settings describe the build, for example "vcs.revision" is the
+	// revision of the sources and "-tags" the build tags.
+	Settings []BuildSetting `json:"settings,omitempty"`
+}
+
+// Module is a module linked in the executable of the target.
+type Module struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+	Sum     string `json:"sum,omitempty"`
+	// Replace is the replacement of this module, if any.
+	Replace *Module `json:"replace,omitempty"`
+}
+
+// BuildSetting is a key/value pair describing one setting of a build.
+type BuildSetting struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// FileDescriptor is an open file descriptor of the target process.
+type FileDescriptor struct {
+	Num int `json:"num"`
+	// Path is the file opened by the file descriptor, or a description of
+	// it such as "socket:[inode]" or "pipe:[inode]".
+	Path string `json:"path"`
+	// Proto is the protocol of sockets: tcp, tcp6, udp, udp6 or unix.
+	Proto string `json:"proto,omitempty"`
+	// Local and Remote are the addresses of sockets.
+	Local  string `json:"local,omitempty"`
+	Remote string `json:"remote,omitempty"`
+	// State is the state of TCP sockets.
+	State string `json:"state,omitempty"`
+	// Goroutines are the IDs of the goroutines blocked waiting for the file
+	// descriptor to become ready.
+	Goroutines []int `json:"goroutines,omitempty"`
+}
+
+// MemoryRegion is a memory mapping of the target process.
+type MemoryRegion struct {
+	Addr  uint64 `json:"addr"`
+	Size  uint64 `json:"size"`
+	Read  bool   `json:"read"`
+	Write bool   `json:"write"`
+	Exec  bool   `json:"exec"`
+	// Filename is the file mapped in memory, if any.
+	Filename string `json:"filename,omitempty"`
+	Offset   uint64 `json:"offset,omitempty"`
+
+	// Image is the path of the executable or shared library mapped by the
+	// region, if any.
+	Image string `json:"image,omitempty"`
+	// GoHeap is true if the region contains spans of the Go heap.
+	GoHeap bool `json:"goHeap,omitempty"`
+	// GoroutineStacks is the number of goroutine stacks in the region.
+	GoroutineStacks int `json:"goroutineStacks,omitempty"`
+}
+
+// CoverageLine is a source line instrumented to record whether it is
+// executed.
+type CoverageLine struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Executed bool   `json:"executed"`
+}
+
+// Kinds of BranchTraceEvent.
+const (
+	BranchTraceCall   = "call"
+	BranchTraceReturn = "return"
+	BranchTraceJump   = "jump"
+	BranchTraceGap    = "gap"
+)
+
+// BranchTraceEvent is a call, return or jump between two functions
+// reconstructed from the hardware branch trace of a thread.
+type BranchTraceEvent struct {
+	// Kind is one of BranchTraceCall, BranchTraceReturn, BranchTraceJump
+	// or BranchTraceGap, a gap is a part of the trace that was lost or
+	// could not be decoded.
+	Kind string   `json:"kind"`
+	From Location `json:"from"`
+	To   Location `json:"to"`
+}
+
+// MemorySearchResult is an occurrence of a pattern found in the memory
+// of the target.
+type MemorySearchResult struct {
+	Addr uint64 `json:"addr"`
+	// Region is the memory mapping containing Addr, nil if the memory map
+	// of the target is not available.
+	Region *MemoryRegion `json:"region,omitempty"`
+}
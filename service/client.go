@@ -53,6 +53,11 @@ type Client interface {
 	StepInstruction() (*api.DebuggerState, error)
 	// ReverseSingleStep will reverse step a single cpu instruction.
 	ReverseStepInstruction() (*api.DebuggerState, error)
+	// Command executes the command name with the specified options, opts
+	// must be of the type corresponding to name (for example
+	// *api.NextOptions for api.Next).
+	Command(name string, opts api.CommandOptions) (*api.DebuggerState, error)
+
 	// SwitchThread switches the current thread context.
 	SwitchThread(threadID int) (*api.DebuggerState, error)
 	// SwitchGoroutine switches the current goroutine (and the current thread as well)
@@ -68,8 +73,49 @@ type Client interface {
 	CreateBreakpoint(*api.Breakpoint) (*api.Breakpoint, error)
 	// CreateWatchpoint creates a new watchpoint.
 	CreateWatchpoint(api.EvalScope, string, api.WatchType) (*api.Breakpoint, error)
-	// ListBreakpoints gets all breakpoints.
+	// CreateWatchpointHistory creates a write watchpoint that records the
+	// last n writes instead of stopping the target.
+	CreateWatchpointHistory(scope api.EvalScope, expr string, n int) (*api.Breakpoint, error)
+	// CreateChanWatchpoint creates a breakpoint that stops when any goroutine
+	// sends to or receives from the channel expr evaluates to.
+	CreateChanWatchpoint(scope api.EvalScope, expr string) (*api.Breakpoint, error)
+	// SearchMemory searches pattern in the memory of the target between
+	// start and end, or in all of its readable memory if end is zero, and
+	// returns at most max occurrences.
+	SearchMemory(pattern []byte, start, end uint64, max int) ([]api.MemorySearchResult, error)
+	// ListMemoryRegions returns the memory mappings of the target, with the
+	// regions of the executable, of the Go heap and of goroutine stacks
+	// annotated.
+	ListMemoryRegions() ([]api.MemoryRegion, error)
+	// Environ returns the environment of the target, as returned by
+	// os.Environ.
+	Environ() ([]string, error)
+	// SetEnv sets an environment variable of the target.
+	SetEnv(key, value string) error
+	// UnsetEnv removes a variable from the environment of the target.
+	UnsetEnv(key string) error
+	// ListFileDescriptors returns the open file descriptors of the target
+	// process and the goroutines blocked waiting for them.
+	ListFileDescriptors() ([]api.FileDescriptor, error)
+	// ListTimers returns the pending timers of the runtime, sorted by the
+	// time at which they fire.
+	ListTimers() ([]api.Timer, error)
+	// MutexState decodes the state of the sync.Mutex or sync.RWMutex expr
+	// evaluates to and lists the goroutines waiting on it.
+	MutexState(scope api.EvalScope, expr string) (*api.MutexState, error)
+	// ChanState lists the elements buffered in the channel expr evaluates
+	// to and the goroutines blocked on it.
+	ChanState(scope api.EvalScope, expr string, cfg api.LoadConfig) (*api.ChanState, error)
+	// WatchHistory returns the writes recorded by a watchpoint created with
+	// CreateWatchpointHistory, or the hits recorded by a breakpoint with a
+	// non-zero WatchHistory.
+	WatchHistory(id int) ([]api.WatchHistoryEntry, error)
+	// ListBreakpoints gets all breakpoints, enabled and disabled, sorted by ID.
 	ListBreakpoints() ([]*api.Breakpoint, error)
+	// ListAllBreakpoints is like ListBreakpoints but also returns the
+	// breakpoints set internally by Delve, for example by next and step,
+	// with ID 0.
+	ListAllBreakpoints() ([]*api.Breakpoint, error)
 	// ClearBreakpoint deletes a breakpoint by ID.
 	ClearBreakpoint(id int) (*api.Breakpoint, error)
 	// ClearBreakpointByName deletes a breakpoint by name
@@ -78,11 +124,22 @@ type Client interface {
 	ToggleBreakpoint(id int) (*api.Breakpoint, error)
 	// ToggleBreakpointByName toggles on or off a breakpoint by name.
 	ToggleBreakpointByName(name string) (*api.Breakpoint, error)
+	// AmendBreakpointGroup enables, or disables if disabled is true, all the
+	// breakpoints belonging to group and returns the ones that changed.
+	AmendBreakpointGroup(group string, disabled bool) ([]*api.Breakpoint, error)
+	// ClearBreakpointGroup deletes all the breakpoints belonging to group.
+	ClearBreakpointGroup(group string) ([]*api.Breakpoint, error)
+	// ClearBreakpoints deletes all the breakpoints selected by filter, except
+	// the ones set internally by Delve, and returns them.
+	ClearBreakpoints(filter api.BreakpointFilter) ([]*api.Breakpoint, error)
 	// Allows user to update an existing breakpoint for example to change the information
 	// retrieved when the breakpoint is hit or to change, add or remove the break condition
 	AmendBreakpoint(*api.Breakpoint) error
 	// Cancels a Next or Step call that was interrupted by a manual stop or by another breakpoint
 	CancelNext() error
+	// CancelRequest interrupts the long running call currently being
+	// served, like ListGoroutines or a full Stacktrace.
+	CancelRequest() error
 
 	// ListThreads lists all threads.
 	ListThreads() ([]*api.Thread, error)
@@ -91,7 +148,11 @@ type Client interface {
 
 	// ListPackageVariables lists all package variables in the context of the current thread.
 	ListPackageVariables(filter string, cfg api.LoadConfig) ([]api.Variable, error)
+	// ListPackageVariablesInPackage lists package variables belonging to
+	// package pkg, "." selects the package of the current thread.
+	ListPackageVariablesInPackage(pkg, filter string, cfg api.LoadConfig) ([]api.Variable, error)
 	// EvalVariable returns a variable in the context of the current thread.
+	// Function calls are not allowed, use Call to evaluate them.
 	EvalVariable(scope api.EvalScope, symbol string, cfg api.LoadConfig) (*api.Variable, error)
 
 	// SetVariable sets the value of a variable
@@ -119,6 +180,32 @@ type Client interface {
 
 	// Returns stacktrace
 	Stacktrace(goroutineID int, depth int, opts api.StacktraceOptions, cfg *api.LoadConfig) ([]api.Stackframe, error)
+	// StacktraceChunk returns the frames of a stacktrace starting at frame
+	// skip, up to depth frames after it.
+	StacktraceChunk(goroutineID, skip, depth int, opts api.StacktraceOptions, cfg *api.LoadConfig) ([]api.Stackframe, error)
+	// ThreadStacktrace returns the stacktrace of the given thread, without
+	// changing the current thread.
+	ThreadStacktrace(threadID, depth int, cfg *api.LoadConfig) ([]api.Stackframe, error)
+
+	// GoroutinesStacktraces returns the stacktraces of all goroutines, up to
+	// depth frames each.
+	GoroutinesStacktraces(depth int, opts api.StacktraceOptions) ([]api.GoroutineStacktrace, error)
+
+	// ThreadsStacktraces returns the stacktraces of all threads, up to depth
+	// frames each.
+	ThreadsStacktraces(depth int) ([]api.ThreadStacktrace, error)
+
+	// GetOutput returns the standard output and standard error of the
+	// target, starting at the specified offsets, if the server captures it.
+	GetOutput(stdoutOffset, stderrOffset int64) (stdout, stderr api.TargetOutput, err error)
+
+	// GetTargetInfo returns the executable, command line, working directory,
+	// start time and Go version of the target.
+	GetTargetInfo() (*api.TargetInfo, error)
+
+	// GetBuildInfo returns the Go version, module versions and build
+	// settings embedded in the executable of the target.
+	GetBuildInfo() (*api.BuildInfo, error)
 
 	// Returns ancestor stacktraces
 	Ancestors(goroutineID int, numAncestors int, depth int) ([]api.Ancestor, error)
@@ -156,11 +243,81 @@ type Client interface {
 	// ClearCheckpoint removes a checkpoint
 	ClearCheckpoint(id int) error
 
+	// FreezeGoroutine prevents a goroutine, and the thread running it, from
+	// running when the target is resumed.
+	FreezeGoroutine(id int) error
+	// ThawGoroutine lets a frozen goroutine run again.
+	ThawGoroutine(id int) error
+
+	// StartCoverage starts recording which lines of the source files
+	// matching the regular expression filter are executed, returns the
+	// number of instrumented lines.
+	StartCoverage(filter string) (int, error)
+	// StopCoverage stops recording executed lines.
+	StopCoverage() error
+	// GetCoverage returns the lines instrumented by StartCoverage and
+	// whether they were executed.
+	GetCoverage() ([]api.CoverageLine, error)
+
+	// StartBranchTrace starts recording the control flow of the target's
+	// threads with the hardware branch tracer of the CPU.
+	StartBranchTrace() error
+	// GetBranchTrace returns the calls, returns and jumps between functions
+	// recorded for thread threadID, or the current thread if it is 0,
+	// oldest first.
+	GetBranchTrace(threadID int) ([]api.BranchTraceEvent, error)
+
+	// StartRuntimeTrace starts the execution tracer of the target's runtime,
+	// writing the trace to path.
+	StartRuntimeTrace(path string) error
+	// StopRuntimeTrace stops the execution trace started by
+	// StartRuntimeTrace and returns the path of the trace file.
+	StopRuntimeTrace() (string, error)
+	// Profile collects a profile of the target and writes it to path, to
+	// collect CPU profiles the target is resumed for the specified duration.
+	Profile(kind api.ProfileKind, duration time.Duration, path string) (*api.DebuggerState, error)
+
 	// SetReturnValuesLoadConfig sets the load configuration for return values.
 	SetReturnValuesLoadConfig(*api.LoadConfig)
 
+	// GetVersion returns the version of Delve, of the API and of the
+	// target.
+	GetVersion() (*api.GetVersionOut, error)
+
 	// IsMulticlien returns true if the headless instance is multiclient.
 	IsMulticlient() bool
+	// ListClients returns the clients connected to the server and the ID
+	// of this client.
+	ListClients() ([]api.ClientInfo, int, error)
+	// HandOff releases control of the session, if to is not zero control
+	// is handed to the client with that ID.
+	HandOff(to int) error
+	// WaitStateChange waits for the state of the target or of the debugger
+	// to change after sequence number seq, which should be zero on the
+	// first call and the sequence number returned by the previous call
+	// afterwards.
+	WaitStateChange(seq int) (*api.WaitStateChangeOut, error)
+
+	// AddWatchExpression adds expr to the expressions the server evaluates,
+	// with cfg, in the selected goroutine every time the target stops.
+	AddWatchExpression(expr string, cfg api.LoadConfig) (*api.WatchExpression, error)
+	// RemoveWatchExpression removes the watch expression with the given ID.
+	RemoveWatchExpression(id int) error
+	// ListWatchExpressions returns the watch expressions with their values
+	// at the last stop.
+	ListWatchExpressions() ([]api.WatchExpression, error)
+	// WaitWatchExpressions waits for the watch expressions, or their values,
+	// to change after sequence number seq, which should be zero on the
+	// first call and the sequence number returned by the previous call
+	// afterwards, and returns them with their old and new values.
+	WaitWatchExpressions(seq int) (*api.WaitWatchExpressionsOut, error)
+
+	// SetCommandHooks replaces the hooks of command stored by the server,
+	// empty hooks remove them.
+	SetCommandHooks(command string, hooks api.CommandHooks) error
+	// ListCommandHooks returns the hooks stored by the server, indexed by
+	// command name.
+	ListCommandHooks() (map[string]api.CommandHooks, error)
 
 	// ListDynamicLibraries returns a list of loaded dynamic libraries.
 	ListDynamicLibraries() ([]api.Image, error)
@@ -2,6 +2,7 @@ package service
 
 import (
 	"net"
+	"time"
 
 	"github.com/go-delve/delve/service/debugger"
 )
@@ -23,6 +24,17 @@ type Config struct {
 	// ProcessArgs are the arguments to launch a new process.
 	ProcessArgs []string
 
+	// ObserverListener, if not nil, is used to serve read-only clients:
+	// they can inspect the state of the target (stacks, variables,
+	// goroutines, etc) but not set breakpoints, resume it or otherwise
+	// change it. Observers disconnecting never stop the server.
+	ObserverListener net.Listener
+
+	// GRPCListener, if not nil, is served by GRPCServer. All gRPC clients
+	// share a single client of the session.
+	GRPCListener net.Listener
+	GRPCServer   GRPCServer
+
 	// AcceptMulti configures the server to accept multiple connection.
 	// Note that the server API is not reentrant and clients will have to coordinate.
 	AcceptMulti bool
@@ -36,4 +48,9 @@ type Config struct {
 
 	// DisconnectChan will be closed by the server when the client disconnects
 	DisconnectChan chan<- struct{}
+
+	// IdleTimeout, if non-zero, is the amount of time without client
+	// activity after which the server detaches from the target and closes
+	// DisconnectChan.
+	IdleTimeout time.Duration
 }
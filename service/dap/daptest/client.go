@@ -267,9 +267,17 @@ func (c *Client) SetHitConditionalBreakpointsRequest(file string, lines []int, c
 	c.send(request)
 }
 
-// SetExceptionBreakpointsRequest sends a 'setExceptionBreakpoints' request.
+// SetExceptionBreakpointsRequest sends a 'setExceptionBreakpoints' request
+// enabling the default filters, like VS Code does.
 func (c *Client) SetExceptionBreakpointsRequest() {
-	request := &dap.SetBreakpointsRequest{Request: *c.newRequest("setExceptionBreakpoints")}
+	c.SetExceptionBreakpointsRequestWithFilters("unrecovered-panic", "fatal-error")
+}
+
+// SetExceptionBreakpointsRequestWithFilters sends a 'setExceptionBreakpoints'
+// request enabling the specified filters.
+func (c *Client) SetExceptionBreakpointsRequestWithFilters(filters ...string) {
+	request := &dap.SetExceptionBreakpointsRequest{Request: *c.newRequest("setExceptionBreakpoints")}
+	request.Arguments.Filters = filters
 	c.send(request)
 }
 
@@ -381,6 +389,13 @@ func (c *Client) SetFunctionBreakpointsRequest(breakpoints []dap.FunctionBreakpo
 	})
 }
 
+// SetInstructionBreakpointsRequest sends a 'setInstructionBreakpoints' request.
+func (c *Client) SetInstructionBreakpointsRequest(breakpoints []dap.InstructionBreakpoint) {
+	request := &dap.SetInstructionBreakpointsRequest{Request: *c.newRequest("setInstructionBreakpoints")}
+	request.Arguments.Breakpoints = breakpoints
+	c.send(request)
+}
+
 // StepBackRequest sends a 'stepBack' request.
 func (c *Client) StepBackRequest() {
 	c.send(&dap.StepBackRequest{Request: *c.newRequest("stepBack")})
@@ -462,13 +477,18 @@ func (c *Client) LoadedSourcesRequest() {
 }
 
 // DataBreakpointInfoRequest sends a 'dataBreakpointInfo' request.
-func (c *Client) DataBreakpointInfoRequest() {
-	c.send(&dap.DataBreakpointInfoRequest{Request: *c.newRequest("dataBreakpointInfo")})
+func (c *Client) DataBreakpointInfoRequest(variablesReference int, name string) {
+	request := &dap.DataBreakpointInfoRequest{Request: *c.newRequest("dataBreakpointInfo")}
+	request.Arguments.VariablesReference = variablesReference
+	request.Arguments.Name = name
+	c.send(request)
 }
 
 // SetDataBreakpointsRequest sends a 'setDataBreakpoints' request.
-func (c *Client) SetDataBreakpointsRequest() {
-	c.send(&dap.SetDataBreakpointsRequest{Request: *c.newRequest("setDataBreakpoints")})
+func (c *Client) SetDataBreakpointsRequest(breakpoints []dap.DataBreakpoint) {
+	request := &dap.SetDataBreakpointsRequest{Request: *c.newRequest("setDataBreakpoints")}
+	request.Arguments.Breakpoints = breakpoints
+	c.send(request)
 }
 
 // ReadMemoryRequest sends a 'readMemory' request.
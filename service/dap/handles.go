@@ -27,6 +27,10 @@ type fullyQualifiedVariable struct {
 	// startIndex is the index of the first child for an array or slice.
 	// This variable represents a chunk of the array, slice or map.
 	startIndex int
+	// loadChildren, if not nil, loads the children of this variable the
+	// first time they are requested, it is used for scopes that are
+	// expensive to load.
+	loadChildren func() ([]proc.Variable, error)
 }
 
 func newHandlesMap() *handlesMap {
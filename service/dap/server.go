@@ -25,14 +25,18 @@ import (
 	"regexp"
 	"runtime"
 	"runtime/debug"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/go-delve/delve/pkg/gobuild"
 	"github.com/go-delve/delve/pkg/goversion"
 	"github.com/go-delve/delve/pkg/locspec"
 	"github.com/go-delve/delve/pkg/logflags"
+	"github.com/go-delve/delve/pkg/metrics"
 	"github.com/go-delve/delve/pkg/proc"
+	"github.com/go-delve/delve/pkg/proc/evalexpr"
 
 	"github.com/go-delve/delve/service"
 	"github.com/go-delve/delve/service/api"
@@ -113,6 +117,9 @@ type Server struct {
 	exceptionErr error
 	// clientCapabilities tracks special settings for handling debug session requests.
 	clientCapabilities dapClientCapabilites
+	// exceptionFilters is the set of exception breakpoint filters enabled
+	// by the last setExceptionBreakpoints request.
+	exceptionFilters map[string]bool
 
 	// mu synchronizes access to objects set on start-up (from run goroutine)
 	// and stopped on teardown (from main goroutine)
@@ -130,6 +137,26 @@ type Server struct {
 	// sendingMu synchronizes writing to net.Conn
 	// to ensure that messages do not get interleaved
 	sendingMu sync.Mutex
+
+	// stopMu synchronizes calls to triggerServerStop.
+	stopMu sync.Mutex
+
+	// idleMu protects idleTimer and activeRequests.
+	idleMu sync.Mutex
+	// idleTimer stops the server after config.IdleTimeout without client
+	// requests, it is nil if config.IdleTimeout is zero.
+	idleTimer *time.Timer
+	// activeRequests is the number of requests being handled, requests
+	// resuming the target are handled until the target stops.
+	activeRequests int
+
+	// outputMu protects outputDebugger and the offsets of the output of
+	// the target already forwarded to the client, see forwardOutput.
+	outputMu sync.Mutex
+	// outputDebugger is the debugger capturing the output of the target,
+	// nil if the output is not being forwarded.
+	outputDebugger             *debugger.Debugger
+	stdoutOffset, stderrOffset int64
 }
 
 // launchAttachArgs captures arguments from launch/attach request that
@@ -141,6 +168,9 @@ type launchAttachArgs struct {
 	stackTraceDepth int
 	// showGlobalVariables indicates if global package variables should be loaded.
 	showGlobalVariables bool
+	// hideSystemGoroutines indicates if goroutines of the runtime that are
+	// not executing code of the program should be left out of the threads.
+	hideSystemGoroutines bool
 	// substitutePathClientToServer indicates rules for converting file paths between client and debugger.
 	// These must be directory paths.
 	substitutePathClientToServer [][2]string
@@ -154,6 +184,7 @@ var defaultArgs = launchAttachArgs{
 	stopOnEntry:                  false,
 	stackTraceDepth:              50,
 	showGlobalVariables:          false,
+	hideSystemGoroutines:         true,
 	substitutePathClientToServer: [][2]string{},
 	substitutePathServerToClient: [][2]string{},
 }
@@ -193,6 +224,9 @@ const (
 	maxStringLenInCallRetVars = 1 << 10 // 1024
 	// Max number of goroutines that we will return.
 	maxGoroutines = 1 << 10
+	// Max number of times that we continue past exceptions whose filter is
+	// disabled before reporting a stop.
+	maxSkippedExceptions = 1 << 7 // 128
 )
 
 // NewServer creates a new DAP Server. It takes an opened Listener
@@ -213,6 +247,7 @@ func NewServer(config *service.Config) *Server {
 		variableHandles:   newVariablesHandlesMap(),
 		args:              defaultArgs,
 		exceptionErr:      nil,
+		exceptionFilters:  map[string]bool{unrecoveredPanicFilter: true, fatalErrorFilter: true},
 	}
 }
 
@@ -231,6 +266,10 @@ func (s *Server) setLaunchAttachArgs(request dap.LaunchAttachRequest) error {
 	if ok {
 		s.args.showGlobalVariables = globals
 	}
+	hideSystem, ok := request.GetArguments()["hideSystemGoroutines"].(bool)
+	if ok {
+		s.args.hideSystemGoroutines = hideSystem
+	}
 	paths, ok := request.GetArguments()["substitutePath"]
 	if ok {
 		typeMismatchError := fmt.Errorf("'substitutePath' attribute '%v' in debug configuration is not a []{'from': string, 'to': string}", paths)
@@ -262,6 +301,14 @@ func (s *Server) setLaunchAttachArgs(request dap.LaunchAttachRequest) error {
 	return nil
 }
 
+// Debugger returns the debugger of the current debug session, or nil if
+// there is none.
+func (s *Server) Debugger() *debugger.Debugger {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.debugger
+}
+
 // Stop stops the DAP debugger service, closes the listener and the client
 // connection. It shuts down the underlying debugger and kills the target
 // process if it was launched by it or stops the noDebug process.
@@ -269,6 +316,11 @@ func (s *Server) setLaunchAttachArgs(request dap.LaunchAttachRequest) error {
 func (s *Server) Stop() {
 	s.log.Debug("DAP server stopping...")
 	close(s.stopTriggered)
+	s.idleMu.Lock()
+	if s.idleTimer != nil {
+		s.idleTimer.Stop()
+	}
+	s.idleMu.Unlock()
 	_ = s.listener.Close()
 
 	s.mu.Lock()
@@ -301,9 +353,11 @@ func (s *Server) Stop() {
 // failure or closure. Since the server currently services only one
 // client, this is used as a signal to stop the entire server.
 // The function safeguards agaist closing the channel more
-// than once and can be called multiple times. It is not thread-safe
-// and is currently only called from the run goroutine.
+// than once and can be called multiple times, from the run goroutine
+// and when the idle timeout expires.
 func (s *Server) triggerServerStop() {
+	s.stopMu.Lock()
+	defer s.stopMu.Unlock()
 	// Avoid accidentally closing the channel twice and causing a panic, when
 	// this function is called more than once. For example, we could have the
 	// following sequence of events:
@@ -330,6 +384,11 @@ func (s *Server) triggerServerStop() {
 // TODO(polina): allow new client connections for new debug sessions,
 // so the editor needs to launch delve only once?
 func (s *Server) Run() {
+	if s.config.IdleTimeout > 0 {
+		s.idleMu.Lock()
+		s.idleTimer = time.AfterFunc(s.config.IdleTimeout, s.idleTimeout)
+		s.idleMu.Unlock()
+	}
 	go func() {
 		conn, err := s.listener.Accept() // listener is closed in Stop()
 		if err != nil {
@@ -360,6 +419,11 @@ func (s *Server) Run() {
 // a disconnect signal and returns.
 func (s *Server) serveDAPCodec() {
 	s.reader = bufio.NewReader(s.conn)
+	// Requests are handled on a separate goroutine so that a 'cancel'
+	// request can be read, and acted upon, while a long running request is
+	// being handled. All other requests wait for the previous one to be
+	// handled, to preserve ordering.
+	var inflight sync.WaitGroup
 	for {
 		request, err := dap.ReadProtocolMessage(s.reader)
 		// Handle dap.DecodeProtocolMessageFieldError errors gracefully by responding with an ErrorResponse.
@@ -371,6 +435,7 @@ func (s *Server) serveDAPCodec() {
 		// Other errors, such as unmarshalling errors, will log the error and cause the server to trigger
 		// a stop.
 		if err != nil {
+			inflight.Wait()
 			select {
 			case <-s.stopTriggered:
 			default:
@@ -386,8 +451,53 @@ func (s *Server) serveDAPCodec() {
 			}
 			return
 		}
-		s.handleRequest(request)
+		if _, ok := request.(*dap.CancelRequest); ok {
+			s.handleRequest(request)
+			continue
+		}
+		inflight.Wait()
+		inflight.Add(1)
+		go func() {
+			defer inflight.Done()
+			s.handleRequest(request)
+		}()
+	}
+}
+
+// requestStarted must be called when the server starts handling a
+// request, it pauses the idle timer.
+func (s *Server) requestStarted() {
+	s.idleMu.Lock()
+	defer s.idleMu.Unlock()
+	s.activeRequests++
+	if s.idleTimer != nil {
+		s.idleTimer.Stop()
+	}
+}
+
+// requestDone must be called when the server is done handling a request,
+// the idle timer is restarted once no requests are left.
+func (s *Server) requestDone() {
+	s.idleMu.Lock()
+	defer s.idleMu.Unlock()
+	s.activeRequests--
+	if s.idleTimer != nil && s.activeRequests == 0 {
+		s.idleTimer.Reset(s.config.IdleTimeout)
+	}
+}
+
+// idleTimeout is called when no client requests were handled for
+// config.IdleTimeout, it stops the server like a disconnection of the
+// client would.
+func (s *Server) idleTimeout() {
+	s.idleMu.Lock()
+	busy := s.activeRequests > 0
+	s.idleMu.Unlock()
+	if busy {
+		return
 	}
+	s.log.Infof("no client activity for %v, stopping", s.config.IdleTimeout)
+	s.triggerServerStop()
 }
 
 // In case a handler panics, we catch the panic to avoid crashing both
@@ -401,6 +511,8 @@ func (s *Server) recoverPanic(request dap.Message) {
 }
 
 func (s *Server) handleRequest(request dap.Message) {
+	s.requestStarted()
+	defer s.requestDone()
 	defer s.recoverPanic(request)
 
 	jsonmsg, _ := json.Marshal(request)
@@ -410,6 +522,7 @@ func (s *Server) handleRequest(request dap.Message) {
 		s.sendInternalErrorResponse(request.GetSeq(), fmt.Sprintf("Unable to process non-request %#v\n", request))
 		return
 	}
+	metrics.CommandsServed.Inc()
 
 	// These requests, can be handled regardless of whether the targret is running
 	switch request := request.(type) {
@@ -510,55 +623,71 @@ func (s *Server) handleRequest(request dap.Message) {
 
 	// Non-blocking request handlers will signal when they are ready
 	// setting up for async execution, so more requests can be processed.
+	// They remain active requests, pausing the idle timer, until the
+	// target stops.
 	resumeRequestLoop := make(chan struct{})
 
 	switch request := request.(type) {
 	//--- Asynchronous requests ---
 	case *dap.ConfigurationDoneRequest:
 		// Optional (capability ‘supportsConfigurationDoneRequest’)
+		s.requestStarted()
 		go func() {
+			defer s.requestDone()
 			defer s.recoverPanic(request)
 			s.onConfigurationDoneRequest(request, resumeRequestLoop)
 		}()
 		<-resumeRequestLoop
 	case *dap.ContinueRequest:
 		// Required
+		s.requestStarted()
 		go func() {
+			defer s.requestDone()
 			defer s.recoverPanic(request)
 			s.onContinueRequest(request, resumeRequestLoop)
 		}()
 		<-resumeRequestLoop
 	case *dap.NextRequest:
 		// Required
+		s.requestStarted()
 		go func() {
+			defer s.requestDone()
 			defer s.recoverPanic(request)
 			s.onNextRequest(request, resumeRequestLoop)
 		}()
 		<-resumeRequestLoop
 	case *dap.StepInRequest:
 		// Required
+		s.requestStarted()
 		go func() {
+			defer s.requestDone()
 			defer s.recoverPanic(request)
 			s.onStepInRequest(request, resumeRequestLoop)
 		}()
 		<-resumeRequestLoop
 	case *dap.StepOutRequest:
 		// Required
+		s.requestStarted()
 		go func() {
+			defer s.requestDone()
 			defer s.recoverPanic(request)
 			s.onStepOutRequest(request, resumeRequestLoop)
 		}()
 		<-resumeRequestLoop
 	case *dap.StepBackRequest:
 		// Optional (capability ‘supportsStepBack’)
+		s.requestStarted()
 		go func() {
+			defer s.requestDone()
 			defer s.recoverPanic(request)
 			s.onStepBackRequest(request, resumeRequestLoop)
 		}()
 		<-resumeRequestLoop
 	case *dap.ReverseContinueRequest:
 		// Optional (capability ‘supportsStepBack’)
+		s.requestStarted()
 		go func() {
+			defer s.requestDone()
 			defer s.recoverPanic(request)
 			s.onReverseContinueRequest(request, resumeRequestLoop)
 		}()
@@ -608,8 +737,10 @@ func (s *Server) handleRequest(request dap.Message) {
 		s.onSetExpressionRequest(request)
 	case *dap.LoadedSourcesRequest:
 		// Optional (capability ‘supportsLoadedSourcesRequest’)
-		// TODO: implement this request in V1
 		s.onLoadedSourcesRequest(request)
+	case *dap.ModulesRequest:
+		// Optional (capability ‘supportsModulesRequest’)
+		s.onModulesRequest(request)
 	case *dap.ReadMemoryRequest:
 		// Optional (capability ‘supportsReadMemoryRequest‘)
 		// TODO: implement this request in V1
@@ -625,6 +756,15 @@ func (s *Server) handleRequest(request dap.Message) {
 	case *dap.ExceptionInfoRequest:
 		// Optional (capability ‘supportsExceptionInfoRequest’)
 		s.onExceptionInfoRequest(request)
+	case *dap.DataBreakpointInfoRequest:
+		// Optional (capability ‘supportsDataBreakpoints’)
+		s.onDataBreakpointInfoRequest(request)
+	case *dap.SetDataBreakpointsRequest:
+		// Optional (capability ‘supportsDataBreakpoints’)
+		s.onSetDataBreakpointsRequest(request)
+	case *dap.SetInstructionBreakpointsRequest:
+		// Optional (capability ‘supportsInstructionBreakpoints’)
+		s.onSetInstructionBreakpointsRequest(request)
 	//--- Requests that we do not plan to support ---
 	case *dap.RestartFrameRequest:
 		// Optional (capability ’supportsRestartFrame’)
@@ -649,19 +789,9 @@ func (s *Server) handleRequest(request dap.Message) {
 	case *dap.CompletionsRequest:
 		// Optional (capability ‘supportsCompletionsRequest’)
 		s.sendUnsupportedErrorResponse(request.Request)
-	case *dap.DataBreakpointInfoRequest:
-		// Optional (capability ‘supportsDataBreakpoints’)
-		s.sendUnsupportedErrorResponse(request.Request)
-	case *dap.SetDataBreakpointsRequest:
-		// Optional (capability ‘supportsDataBreakpoints’)
-		s.sendUnsupportedErrorResponse(request.Request)
 	case *dap.BreakpointLocationsRequest:
 		// Optional (capability ‘supportsBreakpointLocationsRequest’)
 		s.sendUnsupportedErrorResponse(request.Request)
-	case *dap.ModulesRequest:
-		// Optional (capability ‘supportsModulesRequest’)
-		// TODO: does this request make sense for delve?
-		s.sendUnsupportedErrorResponse(request.Request)
 	default:
 		// This is a DAP message that go-dap has a struct for, so
 		// decoding succeeded, but this function does not know how
@@ -671,6 +801,11 @@ func (s *Server) handleRequest(request dap.Message) {
 }
 
 func (s *Server) send(message dap.Message) {
+	switch message.(type) {
+	case *dap.StoppedEvent, *dap.ExitedEvent, *dap.TerminatedEvent:
+		// the client must see what the target printed before it stopped
+		s.flushOutput()
+	}
 	jsonmsg, _ := json.Marshal(message)
 	s.log.Debug("[-> to client]", string(jsonmsg))
 	// TODO(polina): consider using a channel for all the sends and to have a dedicated
@@ -724,10 +859,14 @@ func (s *Server) onInitializeRequest(request *dap.InitializeRequest) {
 	response.Body.SupportsRestartRequest = false
 	response.Body.SupportsStepBack = false // To be enabled by CapabilitiesEvent based on configuration
 	response.Body.SupportsSetExpression = false
-	response.Body.SupportsLoadedSourcesRequest = false
+	response.Body.SupportsLoadedSourcesRequest = true
+	response.Body.SupportsModulesRequest = true
+	response.Body.SupportsDataBreakpoints = true
+	response.Body.SupportsInstructionBreakpoints = true
 	response.Body.SupportsReadMemoryRequest = false
 	response.Body.SupportsDisassembleRequest = false
-	response.Body.SupportsCancelRequest = false
+	response.Body.SupportsCancelRequest = true
+	response.Body.ExceptionBreakpointFilters = exceptionBreakpointFilters
 	s.send(response)
 }
 
@@ -913,6 +1052,20 @@ func (s *Server) onLaunchRequest(request *dap.LaunchRequest) {
 		s.config.Debugger.Backend = "default"
 	}
 
+	s.config.Debugger.CaptureOutput = false
+	if outputMode, ok := request.Arguments["outputMode"]; ok {
+		switch outputMode {
+		case "local":
+		case "remote":
+			s.config.Debugger.CaptureOutput = true
+		default:
+			s.sendErrorResponse(request.Request,
+				FailedToLaunch, "Failed to launch",
+				fmt.Sprintf("'outputMode' attribute '%v' in debug configuration is not 'local' or 'remote'.", outputMode))
+			return
+		}
+	}
+
 	s.config.ProcessArgs = append([]string{program}, targetArgs...)
 	s.config.Debugger.WorkingDir = filepath.Dir(program)
 
@@ -968,6 +1121,9 @@ func (s *Server) onLaunchRequest(request *dap.LaunchRequest) {
 		s.sendErrorResponse(request.Request, FailedToLaunch, "Failed to launch", err.Error())
 		return
 	}
+	if s.config.Debugger.CaptureOutput {
+		go s.forwardOutput(s.debugger)
+	}
 	// Enable StepBack controls on supported backends
 	if s.config.Debugger.Backend == "rr" {
 		s.send(&dap.CapabilitiesEvent{ Event: *newEvent("capabilities"), Body: dap.CapabilitiesEventBody{Capabilities: dap.Capabilities{ SupportsStepBack: true }}})
@@ -980,6 +1136,53 @@ func (s *Server) onLaunchRequest(request *dap.LaunchRequest) {
 	s.send(&dap.LaunchResponse{Response: *newResponse(request.Request)})
 }
 
+// outputPollInterval is how often the output of the target is forwarded to
+// the client when outputMode is "remote".
+const outputPollInterval = 100 * time.Millisecond
+
+// forwardOutput sends the output of the target captured by d to the client
+// as output events, until the server is stopped. The output is also
+// flushed before stopped, exited and terminated events are sent.
+func (s *Server) forwardOutput(d *debugger.Debugger) {
+	s.outputMu.Lock()
+	s.outputDebugger = d
+	s.outputMu.Unlock()
+	ticker := time.NewTicker(outputPollInterval)
+	defer ticker.Stop()
+	for s.flushOutput() {
+		select {
+		case <-s.stopTriggered:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// flushOutput sends the output of the target that was not forwarded yet,
+// it returns false if the output can not be read anymore.
+func (s *Server) flushOutput() bool {
+	s.outputMu.Lock()
+	defer s.outputMu.Unlock()
+	if s.outputDebugger == nil {
+		return false
+	}
+	stdout, stderr, err := s.outputDebugger.GetOutput(s.stdoutOffset, s.stderrOffset)
+	if err != nil {
+		s.log.Debugf("could not read the output of the target: %v", err)
+		s.outputDebugger = nil
+		return false
+	}
+	if len(stdout.Data) > 0 {
+		s.send(&dap.OutputEvent{Event: *newEvent("output"), Body: dap.OutputEventBody{Output: string(stdout.Data), Category: "stdout"}})
+	}
+	if len(stderr.Data) > 0 {
+		s.send(&dap.OutputEvent{Event: *newEvent("output"), Body: dap.OutputEventBody{Output: string(stderr.Data), Category: "stderr"}})
+	}
+	s.stdoutOffset = stdout.Offset + int64(len(stdout.Data))
+	s.stderrOffset = stderr.Offset + int64(len(stderr.Data))
+	return true
+}
+
 // startNoDebugProcess is called from onLaunchRequest (run goroutine) and
 // requires holding mu lock.
 func (s *Server) startNoDebugProcess(program string, targetArgs []string, wd string) (*exec.Cmd, error) {
@@ -1212,7 +1415,7 @@ func (s *Server) onSetBreakpointsRequest(request *dap.SetBreakpointsRequest) {
 		} else {
 			// Create new breakpoints.
 			got, err = s.debugger.CreateBreakpoint(
-				&api.Breakpoint{File: serverPath, Line: want.Line, Cond: want.Condition, HitCond: want.HitCondition, Name: reqString})
+				&api.Breakpoint{File: serverPath, Line: want.Line, Column: want.Column, Cond: want.Condition, HitCond: want.HitCondition, Name: reqString})
 			bpAdded[reqString] = struct{}{}
 		}
 
@@ -1231,6 +1434,7 @@ func updateBreakpointsResponse(breakpoints []dap.Breakpoint, i int, err error, g
 	} else {
 		breakpoints[i].Id = got.ID
 		breakpoints[i].Line = got.Line
+		breakpoints[i].Column = got.Column
 		breakpoints[i].Source = dap.Source{Name: filepath.Base(path), Path: path}
 	}
 }
@@ -1389,12 +1593,277 @@ func (s *Server) getMatchingBreakpoints(prefix string) map[string]*api.Breakpoin
 	return matchingBps
 }
 
+// onDataBreakpointInfoRequest handles 'dataBreakpointInfo' requests.
+// Capability 'supportsDataBreakpoints' is set in 'initialize' response.
+// The returned data id is an expression that dereferences the address of
+// the variable, so that the watchpoint does not depend on the scope in
+// which the variable was found.
+func (s *Server) onDataBreakpointInfoRequest(request *dap.DataBreakpointInfoRequest) {
+	response := &dap.DataBreakpointInfoResponse{Response: *newResponse(request.Request)}
+	v, name, err := s.findDataBreakpointVariable(request.Arguments.VariablesReference, request.Arguments.Name)
+	switch {
+	case err != nil:
+		response.Body.Description = err.Error()
+	case v.Addr == 0 || v.Flags&proc.VariableFakeAddress != 0:
+		response.Body.Description = fmt.Sprintf("%s is not addressable", name)
+	case v.RealType == nil || v.RealType.Size() <= 0 || v.RealType.Size() > int64(s.debugger.Target().BinInfo().Arch.PtrSize()):
+		response.Body.Description = fmt.Sprintf("can not watch variable of type %s", v.TypeString())
+	default:
+		response.Body.DataId = fmt.Sprintf("*(*%q)(%#x)", v.TypeString(), v.Addr)
+		response.Body.Description = name
+		for _, at := range dataBpAccessTypes {
+			response.Body.AccessTypes = append(response.Body.AccessTypes, at.accessType)
+		}
+	}
+	s.send(response)
+}
+
+// findDataBreakpointVariable returns the variable a 'dataBreakpointInfo'
+// request refers to: either the child called name of the container
+// variablesReference or, if variablesReference is zero, the result of
+// evaluating name in the topmost frame of the selected goroutine.
+func (s *Server) findDataBreakpointVariable(variablesReference int, name string) (*proc.Variable, string, error) {
+	if variablesReference == 0 {
+		v, err := s.debugger.EvalVariableInScope(-1, 0, 0, name, DefaultLoadConfig)
+		return v, name, err
+	}
+	parent, ok := s.variableHandles.get(variablesReference)
+	if !ok {
+		return nil, "", fmt.Errorf("unknown reference %d", variablesReference)
+	}
+	for i := range parent.Children {
+		child := &parent.Children[i]
+		childName := child.Name
+		if parent.Kind == reflect.Array || parent.Kind == reflect.Slice {
+			childName = fmt.Sprintf("[%d]", parent.startIndex+i)
+		}
+		if childName != name {
+			continue
+		}
+		if parent.fullyQualifiedNameOrExpr != "" {
+			if parent.isScope {
+				return child, name, nil
+			}
+			return child, fmt.Sprintf("%s.%s", parent.fullyQualifiedNameOrExpr, name), nil
+		}
+		return child, name, nil
+	}
+	return nil, "", fmt.Errorf("variable %s not found", name)
+}
+
+// instructionBpPrefix is the prefix of bp.Name for every breakpoint set by
+// setInstructionBreakpoints.
+const instructionBpPrefix = "instructionBreakpoint"
+
+// onSetInstructionBreakpointsRequest handles 'setInstructionBreakpoints'
+// requests.
+// Capability 'supportsInstructionBreakpoints' is set in 'initialize' response.
+// Instruction references are the hexadecimal addresses returned in the
+// instructionPointerReference field of stack frames. Like for
+// setFunctionBreakpoints the request replaces all instruction breakpoints.
+func (s *Server) onSetInstructionBreakpointsRequest(request *dap.SetInstructionBreakpointsRequest) {
+	if s.noDebugProcess != nil {
+		s.sendErrorResponse(request.Request, UnableToSetBreakpoints, "Unable to set or clear breakpoints", "running in noDebug mode")
+		return
+	}
+
+	existingBps := s.getMatchingBreakpoints(instructionBpPrefix)
+	if err := s.clearBreakpoints(existingBps, nil); err != nil {
+		s.sendErrorResponse(request.Request, UnableToSetBreakpoints, "Unable to set or clear breakpoints", err.Error())
+		return
+	}
+
+	breakpoints := make([]dap.Breakpoint, len(request.Arguments.Breakpoints))
+	for i, want := range request.Arguments.Breakpoints {
+		addr, err := strconv.ParseUint(want.InstructionReference, 0, 64)
+		if err != nil {
+			breakpoints[i].Message = fmt.Sprintf("invalid instruction reference %q", want.InstructionReference)
+			continue
+		}
+		addr = uint64(int64(addr) + int64(want.Offset))
+		got, err := s.debugger.CreateBreakpoint(&api.Breakpoint{
+			Addr:    addr,
+			Cond:    want.Condition,
+			HitCond: want.HitCondition,
+			Name:    fmt.Sprintf("%s Addr=%#x", instructionBpPrefix, addr),
+		})
+		var clientPath string
+		if got != nil {
+			clientPath = s.toClientPath(got.File)
+		}
+		updateBreakpointsResponse(breakpoints, i, err, got, clientPath)
+		if err == nil {
+			breakpoints[i].InstructionReference = fmt.Sprintf("%#x", got.Addr)
+		}
+	}
+
+	response := &dap.SetInstructionBreakpointsResponse{Response: *newResponse(request.Request)}
+	response.Body.Breakpoints = breakpoints
+	s.send(response)
+}
+
+// dataBpPrefix is the prefix of bp.Name for every watchpoint set by
+// setDataBreakpoints.
+const dataBpPrefix = "dataBreakpoint"
+
+// dataBpAccessTypes are the access types of data breakpoints that
+// setDataBreakpoints implements, and that dataBreakpointInfo advertises.
+// The first one is used when a request doesn't specify one.
+var dataBpAccessTypes = []struct {
+	accessType dap.DataBreakpointAccessType
+	wtype      api.WatchType
+}{
+	{"write", api.WatchWrite},
+	{"read", api.WatchRead},
+	{"readWrite", api.WatchRead | api.WatchWrite},
+}
+
+// dataBpWatchType returns the type of watchpoint implementing access type
+// accessType, or false if accessType is not supported.
+func dataBpWatchType(accessType dap.DataBreakpointAccessType) (api.WatchType, bool) {
+	if accessType == "" {
+		return dataBpAccessTypes[0].wtype, true
+	}
+	for _, at := range dataBpAccessTypes {
+		if at.accessType == accessType {
+			return at.wtype, true
+		}
+	}
+	return 0, false
+}
+
+// onSetDataBreakpointsRequest handles 'setDataBreakpoints' requests.
+// Capability 'supportsDataBreakpoints' is set in 'initialize' response.
+// Like for setFunctionBreakpoints the request replaces all data breakpoints.
+func (s *Server) onSetDataBreakpointsRequest(request *dap.SetDataBreakpointsRequest) {
+	if s.noDebugProcess != nil {
+		s.sendErrorResponse(request.Request, UnableToSetBreakpoints, "Unable to set or clear breakpoints", "running in noDebug mode")
+		return
+	}
+
+	existingBps := s.getMatchingBreakpoints(dataBpPrefix)
+	if err := s.clearBreakpoints(existingBps, nil); err != nil {
+		s.sendErrorResponse(request.Request, UnableToSetBreakpoints, "Unable to set or clear breakpoints", err.Error())
+		return
+	}
+
+	breakpoints := make([]dap.Breakpoint, len(request.Arguments.Breakpoints))
+	for i, want := range request.Arguments.Breakpoints {
+		wtype, ok := dataBpWatchType(want.AccessType)
+		if !ok {
+			breakpoints[i].Message = fmt.Sprintf("unknown access type %q", want.AccessType)
+			continue
+		}
+		got, err := s.debugger.CreateWatchpoint(-1, 0, 0, want.DataId, wtype, 0)
+		if err == nil {
+			got.Name = fmt.Sprintf("%s Id=%s", dataBpPrefix, want.DataId)
+			got.Cond = want.Condition
+			got.HitCond = want.HitCondition
+			err = s.debugger.AmendBreakpoint(got)
+		}
+		breakpoints[i].Verified = err == nil
+		if err != nil {
+			breakpoints[i].Message = err.Error()
+			continue
+		}
+		breakpoints[i].Id = got.ID
+	}
+
+	response := &dap.SetDataBreakpointsResponse{Response: *newResponse(request.Request)}
+	response.Body.Breakpoints = breakpoints
+	s.send(response)
+}
+
+// Exception breakpoint filters advertised in the 'initialize' response.
+const (
+	// panicFilter stops every time a panic starts, even if it is later
+	// recovered, using a breakpoint on runtime.gopanic.
+	panicFilter = "panic"
+	// unrecoveredPanicFilter stops on the proc.UnrecoveredPanic breakpoint.
+	unrecoveredPanicFilter = "unrecovered-panic"
+	// fatalErrorFilter stops on the proc.FatalThrow breakpoint.
+	fatalErrorFilter = "fatal-error"
+)
+
+var exceptionBreakpointFilters = []dap.ExceptionBreakpointsFilter{
+	{Filter: panicFilter, Label: "All panics", Default: false},
+	{Filter: unrecoveredPanicFilter, Label: "Unrecovered panics", Default: true},
+	{Filter: fatalErrorFilter, Label: "Fatal errors", Default: true},
+}
+
+// panicBpName is the name of the breakpoint created for panicFilter.
+const panicBpName = "exceptionBreakpoint Name=panic"
+
 func (s *Server) onSetExceptionBreakpointsRequest(request *dap.SetExceptionBreakpointsRequest) {
-	// Unlike what DAP documentation claims, this request is always sent
-	// even though we specified no filters at initialization. Handle as no-op.
+	filters := make(map[string]bool, len(request.Arguments.Filters))
+	for _, filter := range request.Arguments.Filters {
+		switch filter {
+		case panicFilter, unrecoveredPanicFilter, fatalErrorFilter:
+			filters[filter] = true
+		default:
+			s.sendErrorResponse(request.Request, UnableToSetBreakpoints, "Unable to set exception breakpoints", fmt.Sprintf("unknown filter %q", filter))
+			return
+		}
+	}
+
+	// The unrecovered panic and fatal error breakpoints are always set by
+	// proc, stops on them are skipped by doRunCommand if their filter is not
+	// enabled. Stopping on all panics needs a breakpoint of its own.
+	if s.debugger != nil && s.noDebugProcess == nil {
+		existing := s.debugger.FindBreakpointByName(panicBpName)
+		switch {
+		case filters[panicFilter] && existing == nil:
+			var locs []api.Location
+			spec, err := locspec.Parse("runtime.gopanic")
+			if err == nil {
+				locs, err = s.debugger.FindLocationSpec(-1, 0, 0, "runtime.gopanic", spec, true, nil)
+			}
+			if err == nil && len(locs) == 0 {
+				err = errors.New("could not find runtime.gopanic")
+			}
+			if err == nil {
+				_, err = s.debugger.CreateBreakpoint(&api.Breakpoint{Addr: locs[0].PC, Addrs: locs[0].PCs, Name: panicBpName})
+			}
+			if err != nil {
+				s.sendErrorResponse(request.Request, UnableToSetBreakpoints, "Unable to set exception breakpoints", err.Error())
+				return
+			}
+		case !filters[panicFilter] && existing != nil:
+			if _, err := s.debugger.ClearBreakpoint(existing); err != nil {
+				s.sendErrorResponse(request.Request, UnableToSetBreakpoints, "Unable to set exception breakpoints", err.Error())
+				return
+			}
+		}
+	}
+
+	s.mu.Lock()
+	s.exceptionFilters = filters
+	s.mu.Unlock()
+
 	s.send(&dap.SetExceptionBreakpointsResponse{Response: *newResponse(request.Request)})
 }
 
+// skipException returns true if the target is stopped on one of the
+// breakpoints used to catch exceptions but the corresponding filter is not
+// enabled.
+func (s *Server) skipException(state *api.DebuggerState) bool {
+	if state == nil || state.CurrentThread == nil || state.CurrentThread.Breakpoint == nil {
+		return false
+	}
+	var filter string
+	switch state.CurrentThread.Breakpoint.Name {
+	case proc.UnrecoveredPanic:
+		filter = unrecoveredPanicFilter
+	case proc.FatalThrow:
+		filter = fatalErrorFilter
+	default:
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.exceptionFilters[filter]
+}
+
 func (s *Server) asyncCommandDone(asyncSetupDone chan struct{}) {
 	if asyncSetupDone != nil {
 		select {
@@ -1475,6 +1944,10 @@ func (s *Server) onThreadsRequest(request *dap.ThreadsRequest) {
 		s.logToConsole(fmt.Sprintf("too many goroutines, only loaded %d", len(gs)))
 	}
 
+	if s.args.hideSystemGoroutines {
+		gs = s.hideSystemGoroutines(gs)
+	}
+
 	threads := make([]dap.Thread, len(gs))
 	if len(threads) == 0 {
 		// Depending on the debug session stage, goroutines information
@@ -1516,6 +1989,23 @@ func (s *Server) onThreadsRequest(request *dap.ThreadsRequest) {
 	s.send(response)
 }
 
+// hideSystemGoroutines removes from gs the goroutines of the runtime that
+// are not executing code of the program, the selected goroutine is always
+// kept.
+func (s *Server) hideSystemGoroutines(gs []*proc.G) []*proc.G {
+	s.debugger.LockTarget()
+	defer s.debugger.UnlockTarget()
+	tgt := s.debugger.Target()
+	selected := tgt.SelectedGoroutine()
+	r := gs[:0]
+	for _, g := range gs {
+		if (selected != nil && g.ID == selected.ID) || !proc.RuntimeGoroutine(tgt, g) {
+			r = append(r, g)
+		}
+	}
+	return r
+}
+
 // onAttachRequest handles 'attach' request.
 // This is a mandatory request to support.
 func (s *Server) onAttachRequest(request *dap.AttachRequest) {
@@ -1691,7 +2181,7 @@ func (s *Server) onStackTraceRequest(request *dap.StackTraceRequest) {
 	for i, frame := range frames {
 		loc := &frame.Call
 		uniqueStackFrameID := s.stackFrameHandles.create(stackFrame{goroutineID, i})
-		stackFrames[i] = dap.StackFrame{Id: uniqueStackFrameID, Line: loc.Line, Name: fnName(loc)}
+		stackFrames[i] = dap.StackFrame{Id: uniqueStackFrameID, Line: loc.Line, Name: fnName(loc), InstructionPointerReference: fmt.Sprintf("%#x", loc.PC)}
 		if loc.File != "<autogenerated>" {
 			clientPath := s.toClientPath(loc.File)
 			stackFrames[i].Source = dap.Source{Name: filepath.Base(clientPath), Path: clientPath}
@@ -1752,7 +2242,7 @@ func (s *Server) onScopesRequest(request *dap.ScopesRequest) {
 		s.sendErrorResponse(request.Request, UnableToListArgs, "Unable to list args", err.Error())
 		return
 	}
-	argScope := &fullyQualifiedVariable{&proc.Variable{Name: fmt.Sprintf("Arguments%s", suffix), Children: slicePtrVarToSliceVar(args)}, "", true, 0}
+	argScope := &fullyQualifiedVariable{&proc.Variable{Name: fmt.Sprintf("Arguments%s", suffix), Children: slicePtrVarToSliceVar(args)}, "", true, 0, nil}
 
 	// Retrieve local variables
 	locals, err := s.debugger.LocalVariables(goid, frame, 0, DefaultLoadConfig)
@@ -1760,7 +2250,7 @@ func (s *Server) onScopesRequest(request *dap.ScopesRequest) {
 		s.sendErrorResponse(request.Request, UnableToListLocals, "Unable to list locals", err.Error())
 		return
 	}
-	locScope := &fullyQualifiedVariable{&proc.Variable{Name: fmt.Sprintf("Locals%s", suffix), Children: slicePtrVarToSliceVar(locals)}, "", true, 0}
+	locScope := &fullyQualifiedVariable{&proc.Variable{Name: fmt.Sprintf("Locals%s", suffix), Children: slicePtrVarToSliceVar(locals)}, "", true, 0, nil}
 
 	scopeArgs := dap.Scope{Name: argScope.Name, VariablesReference: s.variableHandles.create(argScope)}
 	scopeLocals := dap.Scope{Name: locScope.Name, VariablesReference: s.variableHandles.create(locScope)}
@@ -1769,35 +2259,33 @@ func (s *Server) onScopesRequest(request *dap.ScopesRequest) {
 	if s.args.showGlobalVariables {
 		// Limit what global variables we will return to the current package only.
 		// TODO(polina): This is how vscode-go currently does it to make
-		// the amount of the returned data manageable. In fact, this is
-		// considered so expensive even with the package filter, that
-		// the default for showGlobalVariables was recently flipped to
-		// not showing. If we delay loading of the globals until the corresponding
-		// scope is expanded, generating an explicit variable request,
-		// should we consider making all globals accessible with a scope per package?
+		// the amount of the returned data manageable. Since the globals are
+		// only loaded when the corresponding scope is expanded, generating an
+		// explicit variable request, should we consider making all globals
+		// accessible with a scope per package?
 		// Or users can just rely on watch variables.
 		currPkg, err := s.debugger.CurrentPackage()
 		if err != nil {
 			s.sendErrorResponse(request.Request, UnableToListGlobals, "Unable to list globals", err.Error())
 			return
 		}
-		currPkgFilter := fmt.Sprintf("^%s\\.", currPkg)
-		globals, err := s.debugger.PackageVariables(currPkgFilter, DefaultLoadConfig)
-		if err != nil {
-			s.sendErrorResponse(request.Request, UnableToListGlobals, "Unable to list globals", err.Error())
-			return
-		}
-		// Remove package prefix from the fully-qualified variable names.
-		// We will include the package info once in the name of the scope instead.
-		for i, g := range globals {
-			globals[i].Name = strings.TrimPrefix(g.Name, currPkg+".")
+		loadGlobals := func() ([]proc.Variable, error) {
+			globals, err := s.debugger.PackageVariables(currPkg, "", DefaultLoadConfig)
+			if err != nil {
+				return nil, err
+			}
+			// Remove package prefix from the fully-qualified variable names.
+			// We will include the package info once in the name of the scope instead.
+			for i, g := range globals {
+				globals[i].Name = strings.TrimPrefix(g.Name, currPkg+".")
+			}
+			return slicePtrVarToSliceVar(globals), nil
 		}
 
 		globScope := &fullyQualifiedVariable{&proc.Variable{
-			Name:     fmt.Sprintf("Globals (package %s)", currPkg),
-			Children: slicePtrVarToSliceVar(globals),
-		}, currPkg, true, 0}
-		scopeGlobals := dap.Scope{Name: globScope.Name, VariablesReference: s.variableHandles.create(globScope)}
+			Name: fmt.Sprintf("Globals (package %s)", currPkg),
+		}, currPkg, true, 0, loadGlobals}
+		scopeGlobals := dap.Scope{Name: globScope.Name, VariablesReference: s.variableHandles.create(globScope), Expensive: true}
 		scopes = append(scopes, scopeGlobals)
 	}
 	response := &dap.ScopesResponse{
@@ -1825,10 +2313,23 @@ func (s *Server) onVariablesRequest(request *dap.VariablesRequest) {
 		return
 	}
 
+	if v.loadChildren != nil {
+		children, err := v.loadChildren()
+		if err != nil {
+			s.sendErrorResponse(request.Request, UnableToListGlobals, "Unable to list globals", err.Error())
+			return
+		}
+		v.Children = children
+		v.loadChildren = nil
+	}
+
 	// If there is a filter applied, we will need to create a new variable that includes
 	// the values actually needed to load. This cannot be done when loading the parent
 	// node, since it is unknown at that point which children will need to be loaded.
-	if request.Arguments.Filter == "indexed" {
+	// Clients that do not use filters can still page through the indexed
+	// children using start and count.
+	paged := request.Arguments.Filter == "" && (request.Arguments.Start > 0 || request.Arguments.Count > 0) && getIndexedVariableCount(v.Variable) > 0
+	if request.Arguments.Filter == "indexed" || paged {
 		var err error
 		v, err = s.maybeLoadResliced(v, request.Arguments.Start, request.Arguments.Count)
 		if err != nil {
@@ -1838,7 +2339,7 @@ func (s *Server) onVariablesRequest(request *dap.VariablesRequest) {
 	}
 
 	children := []dap.Variable{} // must return empty array, not null, if no children
-	if request.Arguments.Filter == "named" || request.Arguments.Filter == "" {
+	if request.Arguments.Filter == "named" || request.Arguments.Filter == "" && request.Arguments.Start == 0 {
 		named, err := s.metadataToDAPVariables(v)
 		if err != nil {
 			s.sendErrorResponse(request.Request, UnableToLookupVariable, "Unable to lookup variable", err.Error())
@@ -1861,7 +2362,19 @@ func (s *Server) onVariablesRequest(request *dap.VariablesRequest) {
 	s.send(response)
 }
 
+// maybeLoadResliced returns v with its indexed children in the range
+// [start, start+count) loaded. If count is zero all children starting at
+// start are loaded, as specified by DAP.
 func (s *Server) maybeLoadResliced(v *fullyQualifiedVariable, start, count int) (*fullyQualifiedVariable, error) {
+	if start < 0 || count < 0 {
+		return nil, fmt.Errorf("invalid range start=%d count=%d", start, count)
+	}
+	if count == 0 {
+		count = getIndexedVariableCount(v.Variable) - start
+		if count <= 0 {
+			return nil, fmt.Errorf("start %d out of range", start)
+		}
+	}
 	if start == 0 && count == len(v.Children) {
 		// If we have already loaded the correct children,
 		// just return the variable.
@@ -1873,7 +2386,7 @@ func (s *Server) maybeLoadResliced(v *fullyQualifiedVariable, start, count int)
 	if err != nil {
 		return nil, err
 	}
-	return &fullyQualifiedVariable{newV, v.fullyQualifiedNameOrExpr, false, start}, nil
+	return &fullyQualifiedVariable{newV, v.fullyQualifiedNameOrExpr, false, start, nil}, nil
 }
 
 func getIndexedVariableCount(c *proc.Variable) int {
@@ -2106,6 +2619,33 @@ func (s *Server) convertVariable(v *proc.Variable, qualifiedNameOrExpr string) (
 	return s.convertVariableWithOpts(v, qualifiedNameOrExpr, 0)
 }
 
+// prettyPrint returns the summary of v computed by its built-in
+// pretty-printer, as Type(summary), if it has one. The children of v are
+// still shown when the variable is expanded.
+func (s *Server) prettyPrint(v *proc.Variable) (string, bool) {
+	if (v.Kind != reflect.Struct && v.Kind != reflect.Slice) || v.Addr == 0 || v.DwarfType == nil {
+		return "", false
+	}
+	fn := api.BuiltinPrettyPrinters[api.PrettyTypeName(v.DwarfType)]
+	if fn == nil {
+		return "", false
+	}
+	eval := func(expr string, cfg api.LoadConfig) (*api.Variable, error) {
+		pv, err := s.debugger.EvalVariableInScope(-1, 0, 0, expr, *api.LoadConfigToProc(&cfg))
+		if err != nil {
+			return nil, err
+		}
+		return api.ConvertVar(pv), nil
+	}
+	av := api.ConvertVar(v)
+	summary, err := fn(eval, av, *api.LoadConfigFromProc(&DefaultLoadConfig))
+	if err != nil {
+		s.log.Debugf("pretty-printing %s: %v", av.Type, err)
+		return "", false
+	}
+	return fmt.Sprintf("%s(%s)", av.Type, summary), true
+}
+
 func (s *Server) convertVariableToString(v *proc.Variable) string {
 	val, _ := s.convertVariableWithOpts(v, "", skipRef)
 	return val
@@ -2137,7 +2677,7 @@ func (s *Server) convertVariableWithOpts(v *proc.Variable, qualifiedNameOrExpr s
 		if opts&skipRef != 0 {
 			return 0
 		}
-		return s.variableHandles.create(&fullyQualifiedVariable{v, qualifiedNameOrExpr, false /*not a scope*/, 0})
+		return s.variableHandles.create(&fullyQualifiedVariable{v, qualifiedNameOrExpr, false /*not a scope*/, 0, nil})
 	}
 	value = api.ConvertVar(v).SinglelineString()
 	if v.Unreadable != nil {
@@ -2269,6 +2809,10 @@ func (s *Server) convertVariableWithOpts(v *proc.Variable, qualifiedNameOrExpr s
 		}
 	}
 
+	if summary, ok := s.prettyPrint(v); ok {
+		value = summary
+	}
+
 	// By default, only values of variables that have children can be truncated.
 	// If showFullValue is set, then all value strings are not truncated.
 	canTruncateValue := showFullValue&opts == 0
@@ -2322,7 +2866,7 @@ func (s *Server) onEvaluateRequest(request *dap.EvaluateRequest) {
 			}
 			response.Body = dap.EvaluateResponseBody{
 				Result:             strings.TrimRight(retVarsAsStr, ", "),
-				VariablesReference: s.variableHandles.create(&fullyQualifiedVariable{retVarsAsVar, "", false /*not a scope*/, 0}),
+				VariablesReference: s.variableHandles.create(&fullyQualifiedVariable{retVarsAsVar, "", false /*not a scope*/, 0, nil}),
 			}
 		}
 	} else { // {expression}
@@ -2421,13 +2965,9 @@ func (s *Server) doCall(goid, frame int, expr string) (*api.DebuggerState, []*pr
 	}
 	// Normal function calls expect return values, but call commands
 	// used for variable assignments do not return a value when they succeed.
-	// In go '=' is not an operator. Check if go/parser complains.
-	// If the above Call command passed but the expression is not a valid
-	// go expression, we just handled a variable assignment request.
-	isAssignment := false
-	if _, err := parser.ParseExpr(expr); err != nil {
-		isAssignment = true
-	}
+	// In go '=' is not an operator, go/parser rejects assignments.
+	_, err = parser.ParseExpr(expr)
+	_, isAssignment := evalexpr.IsAssignment(err)
 
 	// note: as described in https://github.com/golang/go/issues/25578, function call injection
 	// causes to resume the entire Go process. Due to this limitation, there is no guarantee
@@ -2611,10 +3151,82 @@ func (s *Server) onSetExpressionRequest(request *dap.SetExpressionRequest) {
 	s.sendNotYetImplementedErrorResponse(request.Request)
 }
 
-// onLoadedSourcesRequest sends a not-yet-implemented error response.
-// Capability 'supportsLoadedSourcesRequest' is not set 'initialize' response.
+// onLoadedSourcesRequest handles 'loadedSources' requests.
+// Capability 'supportsLoadedSourcesRequest' is set in 'initialize' response.
+// All source files referenced by the debug information of the target are
+// returned, files that are not listed are not part of the binary.
 func (s *Server) onLoadedSourcesRequest(request *dap.LoadedSourcesRequest) {
-	s.sendNotYetImplementedErrorResponse(request.Request)
+	if s.debugger == nil || s.noDebugProcess != nil {
+		s.sendErrorResponse(request.Request, UnableToListSources, "Unable to list loaded sources", "no debug session")
+		return
+	}
+	files, err := s.debugger.Sources("")
+	if err != nil {
+		s.sendErrorResponse(request.Request, UnableToListSources, "Unable to list loaded sources", err.Error())
+		return
+	}
+	response := &dap.LoadedSourcesResponse{Response: *newResponse(request.Request)}
+	response.Body.Sources = make([]dap.Source, 0, len(files))
+	for _, file := range files {
+		if strings.HasPrefix(file, "<") {
+			// Not a real file, for example <autogenerated>.
+			continue
+		}
+		clientPath := s.toClientPath(file)
+		response.Body.Sources = append(response.Body.Sources, dap.Source{Name: filepath.Base(clientPath), Path: clientPath})
+	}
+	s.send(response)
+}
+
+// onModulesRequest handles 'modules' requests.
+// Capability 'supportsModulesRequest' is set in 'initialize' response.
+// The executable is returned as the first module, followed by the shared
+// objects and plugins loaded by the target.
+func (s *Server) onModulesRequest(request *dap.ModulesRequest) {
+	if s.debugger == nil || s.noDebugProcess != nil {
+		s.sendErrorResponse(request.Request, UnableToListModules, "Unable to list modules", "no debug session")
+		return
+	}
+	images := append([]*proc.Image{s.debugger.ExecutableImage()}, s.debugger.ListDynamicLibraries()...)
+
+	modules := make([]dap.Module, 0, len(images))
+	for i, image := range images {
+		module := dap.Module{
+			Id:           i,
+			Name:         filepath.Base(image.Path),
+			Path:         s.toClientPath(image.Path),
+			SymbolStatus: "Symbols loaded.",
+			AddressRange: fmt.Sprintf("%#x", image.StaticBase),
+		}
+		if err := image.LoadError(); err != nil {
+			module.SymbolStatus = fmt.Sprintf("Symbols not loaded: %v", err)
+		}
+		modules = append(modules, module)
+	}
+
+	start, end := modulesPage(len(modules), request.Arguments.StartModule, request.Arguments.ModuleCount)
+
+	response := &dap.ModulesResponse{Response: *newResponse(request.Request)}
+	response.Body.Modules = modules[start:end]
+	response.Body.TotalModules = len(modules)
+	s.send(response)
+}
+
+// modulesPage returns the range of the n modules requested by a modules
+// request starting at start with count modules, a count of 0 or less
+// means all remaining modules.
+func modulesPage(n, start, count int) (int, int) {
+	if start < 0 {
+		start = 0
+	}
+	if start > n {
+		start = n
+	}
+	end := n
+	if count > 0 && start+count < end {
+		end = start + count
+	}
+	return start, end
 }
 
 // onReadMemoryRequest sends a not-yet-implemented error response.
@@ -2629,10 +3241,16 @@ func (s *Server) onDisassembleRequest(request *dap.DisassembleRequest) {
 	s.sendNotYetImplementedErrorResponse(request.Request)
 }
 
-// onCancelRequest sends a not-yet-implemented error response.
-// Capability 'supportsCancelRequest' is not set 'initialize' response.
+// onCancelRequest handles 'cancel' requests.
+// Capability 'supportsCancelRequest' is set in 'initialize' response.
+// Long running requests, such as listing threads or loading the variables
+// of a scope, are interrupted and will respond with an error. Requests
+// that are not long running will complete normally.
 func (s *Server) onCancelRequest(request *dap.CancelRequest) {
-	s.sendNotYetImplementedErrorResponse(request.Request)
+	if s.debugger != nil {
+		s.debugger.CancelRequest()
+	}
+	s.send(&dap.CancelResponse{Response: *newResponse(request.Request)})
 }
 
 // onExceptionInfoRequest handles 'exceptionInfo' requests.
@@ -2655,7 +3273,7 @@ func (s *Server) onExceptionInfoRequest(request *dap.ExceptionInfoRequest) {
 		bpState = g.Thread.Breakpoint()
 	}
 	// Check if this goroutine ID is stopped at a breakpoint.
-	if bpState != nil && bpState.Breakpoint != nil && (bpState.Breakpoint.Name == proc.FatalThrow || bpState.Breakpoint.Name == proc.UnrecoveredPanic) {
+	if bpState != nil && bpState.Breakpoint != nil && (bpState.Breakpoint.Name == proc.FatalThrow || bpState.Breakpoint.Name == proc.UnrecoveredPanic || bpState.Breakpoint.Name == panicBpName) {
 		switch bpState.Breakpoint.Name {
 		case proc.FatalThrow:
 			body.ExceptionId = "fatal error"
@@ -2675,6 +3293,13 @@ func (s *Server) onExceptionInfoRequest(request *dap.ExceptionInfoRequest) {
 			if err != nil {
 				body.Description = fmt.Sprintf("Error getting panic message: %s", err.Error())
 			}
+		case panicBpName:
+			body.ExceptionId = "panic"
+			body.BreakMode = "always"
+			body.Description, err = s.panicValue(goroutineID)
+			if err != nil {
+				body.Description = fmt.Sprintf("Error getting panic value: %s", err.Error())
+			}
 		}
 	} else {
 		// If this thread is not stopped on a breakpoint, then a runtime error must have occurred.
@@ -2737,6 +3362,12 @@ func (s *Server) panicReason(goroutineID int) (string, error) {
 	return s.getExprString("(*msgs).arg.(data)", goroutineID, 0)
 }
 
+// panicValue returns the argument of runtime.gopanic, the target must be
+// stopped on the breakpoint created for panicFilter.
+func (s *Server) panicValue(goroutineID int) (string, error) {
+	return s.getExprString("e.(data)", goroutineID, 0)
+}
+
 func (s *Server) getExprString(expr string, goroutineID, frame int) (string, error) {
 	exprVar, err := s.debugger.EvalVariableInScope(goroutineID, frame, 0, expr, DefaultLoadConfig)
 	if err != nil {
@@ -2837,6 +3468,16 @@ func (s *Server) doRunCommand(command string, asyncSetupDone chan struct{}) {
 	// So we should always close it ourselves just in case.
 	defer s.asyncCommandDone(asyncSetupDone)
 	state, err := s.debugger.Command(&api.DebuggerCommand{Name: command}, asyncSetupDone)
+	// Continue past the exceptions whose filter is disabled, unless the
+	// client paused the target meanwhile. A program that keeps hitting them
+	// is stopped after maxSkippedExceptions so that the client regains
+	// control.
+	for skipped := 0; err == nil && !state.Exited && skipped < maxSkippedExceptions && s.skipException(state); skipped++ {
+		if s.debugger.StopReason() == proc.StopManual {
+			break
+		}
+		state, err = s.debugger.Command(&api.DebuggerCommand{Name: api.Continue}, nil)
+	}
 	if _, isexited := err.(proc.ErrProcessExited); isexited || err == nil && state.Exited {
 		s.send(&dap.TerminatedEvent{Event: *newEvent("terminated")})
 		return
@@ -2868,6 +3509,10 @@ func (s *Server) doRunCommand(command string, asyncSetupDone chan struct{}) {
 			stopped.Body.Reason = "unknown"
 		case proc.StopWatchpoint:
 			stopped.Body.Reason = "data breakpoint"
+		case proc.StopSignal:
+			stopped.Body.Reason = "exception"
+			stopped.Body.Description = state.StopReason.Signal
+			stopped.Body.Text = fmt.Sprintf("%s received, fault address %#x (code %d)", state.StopReason.Signal, state.StopReason.FaultAddr, state.StopReason.SignalCode)
 		default:
 			stopped.Body.Reason = "breakpoint"
 		}
@@ -2881,10 +3526,17 @@ func (s *Server) doRunCommand(command string, asyncSetupDone chan struct{}) {
 				stopped.Body.Reason = "exception"
 				stopped.Body.Description = "panic"
 				stopped.Body.Text, _ = s.panicReason(stopped.Body.ThreadId)
+			case panicBpName:
+				stopped.Body.Reason = "exception"
+				stopped.Body.Description = "panic"
+				stopped.Body.Text, _ = s.panicValue(stopped.Body.ThreadId)
 			}
 			if strings.HasPrefix(state.CurrentThread.Breakpoint.Name, functionBpPrefix) {
 				stopped.Body.Reason = "function breakpoint"
 			}
+			if strings.HasPrefix(state.CurrentThread.Breakpoint.Name, instructionBpPrefix) {
+				stopped.Body.Reason = "instruction breakpoint"
+			}
 			stopped.Body.HitBreakpointIds = []int{state.CurrentThread.Breakpoint.ID}
 		}
 	} else {
@@ -19,8 +19,10 @@ import (
 
 	"github.com/go-delve/delve/pkg/goversion"
 	"github.com/go-delve/delve/pkg/logflags"
+	"github.com/go-delve/delve/pkg/metrics"
 	protest "github.com/go-delve/delve/pkg/proc/test"
 	"github.com/go-delve/delve/service"
+	"github.com/go-delve/delve/service/api"
 	"github.com/go-delve/delve/service/dap/daptest"
 	"github.com/google/go-dap"
 )
@@ -280,7 +282,7 @@ func TestAttachStopOnEntry(t *testing.T) {
 
 		// 2 >> attach, << initialized, << attach
 		client.AttachRequest(
-			map[string]interface{}{"mode": "local", "processId": cmd.Process.Pid, "stopOnEntry": true, "backend": "default"})
+			map[string]interface{}{"mode": "local", "processId": cmd.Process.Pid, "stopOnEntry": true, "backend": "default", "hideSystemGoroutines": false})
 		initEvent := client.ExpectInitializedEvent(t)
 		if initEvent.Seq != 0 {
 			t.Errorf("\ngot %#v\nwant Seq=0", initEvent)
@@ -461,7 +463,8 @@ func TestPreSetBreakpoint(t *testing.T) {
 		client.InitializeRequest()
 		client.ExpectInitializeResponseAndCapabilities(t)
 
-		client.LaunchRequest("exec", fixture.Path, !stopOnEntry)
+		client.LaunchRequestWithArgs(map[string]interface{}{
+			"mode": "exec", "program": fixture.Path, "stopOnEntry": !stopOnEntry, "hideSystemGoroutines": false})
 		client.ExpectInitializedEvent(t)
 		client.ExpectLaunchResponse(t)
 
@@ -644,8 +647,10 @@ func checkScope(t *testing.T, got *dap.ScopesResponse, i int, name string, varRe
 		t.Errorf("\ngot  %d\nwant len(Scopes)>%d", len(got.Body.Scopes), i)
 	}
 	goti := got.Body.Scopes[i]
-	if goti.Name != name || goti.VariablesReference != varRef || goti.Expensive {
-		t.Errorf("\ngot  %#v\nwant Name=%q VariablesReference=%d Expensive=false", goti, name, varRef)
+	// Globals are only loaded when their scope is expanded.
+	expensive := strings.HasPrefix(name, "Globals")
+	if goti.Name != name || goti.VariablesReference != varRef || goti.Expensive != expensive {
+		t.Errorf("\ngot  %#v\nwant Name=%q VariablesReference=%d Expensive=%v", goti, name, varRef, expensive)
 	}
 }
 
@@ -857,6 +862,46 @@ func TestStackTraceRequest(t *testing.T) {
 	})
 }
 
+// TestRequestsOrdering sends several requests without waiting for their
+// responses: they are handled on their own goroutine, one at a time, and
+// the responses must come back in the same order.
+func TestRequestsOrdering(t *testing.T) {
+	runTest(t, "increment", func(client *daptest.Client, fixture protest.Fixture) {
+		runDebugSessionWithBPs(t, client, "launch",
+			// Launch
+			func() {
+				client.LaunchRequest("exec", fixture.Path, !stopOnEntry)
+			},
+			// Set breakpoints
+			fixture.Source, []int{8},
+			[]onBreakpoint{{
+				// Stop at line 8
+				execute: func() {
+					client.ThreadsRequest()
+					client.StackTraceRequest(1, 0, 0)
+					client.EvaluateRequest("1+1", 1000, "repl")
+					client.ThreadsRequest()
+
+					var seqs []int
+					seqs = append(seqs, client.ExpectThreadsResponse(t).RequestSeq)
+					seqs = append(seqs, client.ExpectStackTraceResponse(t).RequestSeq)
+					er := client.ExpectEvaluateResponse(t)
+					if er.Body.Result != "2" {
+						t.Errorf("got %#v, want result 2", er)
+					}
+					seqs = append(seqs, er.RequestSeq)
+					seqs = append(seqs, client.ExpectThreadsResponse(t).RequestSeq)
+					for i := 1; i < len(seqs); i++ {
+						if seqs[i] <= seqs[i-1] {
+							t.Errorf("responses out of order: %v", seqs)
+						}
+					}
+				},
+				disconnect: false,
+			}})
+	})
+}
+
 // TestScopesAndVariablesRequests executes to a breakpoint and tests different
 // configurations of 'scopes' and 'variables' requests.
 func TestScopesAndVariablesRequests(t *testing.T) {
@@ -1530,6 +1575,15 @@ func TestVariablesLoading(t *testing.T) {
 						longarr = client.ExpectVariablesResponse(t)
 						checkChildren(t, longarr, "longarr", 50)
 						checkArrayChildren(t, longarr, "longarr", 50)
+
+						// A count of zero loads all remaining elements.
+						client.IndexedVariablesRequest(ref, 90, 0)
+						longarr = client.ExpectVariablesResponse(t)
+						checkChildren(t, longarr, "longarr", 10)
+						checkArrayChildren(t, longarr, "longarr", 90)
+
+						client.IndexedVariablesRequest(ref, 100, 0)
+						client.ExpectErrorResponse(t)
 					}
 
 					// Slice not fully loaded based on LoadConfig.MaxArrayValues.
@@ -3646,6 +3700,51 @@ func TestPanicBreakpointOnContinue(t *testing.T) {
 	})
 }
 
+func TestPanicExceptionFilter(t *testing.T) {
+	runTest(t, "panic", func(client *daptest.Client, fixture protest.Fixture) {
+		runDebugSessionWithBPs(t, client, "launch",
+			// Launch
+			func() {
+				client.LaunchRequest("exec", fixture.Path, !stopOnEntry)
+			},
+			// Set breakpoints
+			fixture.Source, []int{5},
+			[]onBreakpoint{{
+				execute: func() {
+					checkStop(t, client, 1, "main.main", 5)
+
+					client.SetExceptionBreakpointsRequestWithFilters("bogus")
+					client.ExpectErrorResponse(t)
+
+					// Only stop on all panics, the unrecovered panic
+					// breakpoint should be skipped.
+					client.SetExceptionBreakpointsRequestWithFilters("panic")
+					client.ExpectSetExceptionBreakpointsResponse(t)
+
+					client.ContinueRequest(1)
+					client.ExpectContinueResponse(t)
+
+					text := "\"BOOM!\""
+					se := client.ExpectStoppedEvent(t)
+					if se.Body.Reason != "exception" || se.Body.Description != "panic" || se.Body.Text != text {
+						t.Errorf("\ngot  %#v\nwant Reason=\"exception\" Description=\"panic\" Text=%q", se, text)
+					}
+
+					client.ExceptionInfoRequest(se.Body.ThreadId)
+					eInfo := client.ExpectExceptionInfoResponse(t)
+					if eInfo.Body.ExceptionId != "panic" || eInfo.Body.Description != text {
+						t.Errorf("\ngot  %#v\nwant ExceptionId=\"panic\" Description=%q", eInfo, text)
+					}
+
+					client.ContinueRequest(1)
+					client.ExpectContinueResponse(t)
+					client.ExpectTerminatedEvent(t)
+				},
+				disconnect: true,
+			}})
+	})
+}
+
 func TestPanicBreakpointOnNext(t *testing.T) {
 	if !goversion.VersionAfterOrEqual(runtime.Version(), 1, 14) {
 		// In Go 1.13, 'next' will step into the defer in the runtime
@@ -4038,6 +4137,38 @@ func TestLaunchRequestWithArgs(t *testing.T) {
 	})
 }
 
+// Tests that the output of the target is sent to the client as output
+// events when 'outputMode' is 'remote'.
+func TestLaunchRequestOutputModeRemote(t *testing.T) {
+	runTest(t, "outputprog", func(client *daptest.Client, fixture protest.Fixture) {
+		client.InitializeRequest()
+		client.ExpectInitializeResponse(t)
+
+		client.LaunchRequestWithArgs(map[string]interface{}{
+			"mode": "exec", "program": fixture.Path, "outputMode": "remote"})
+		client.ExpectInitializedEvent(t)
+		client.ExpectLaunchResponse(t)
+
+		client.ConfigurationDoneRequest()
+		client.ExpectConfigurationDoneResponse(t)
+
+		output := map[string]string{}
+		for {
+			msg := client.ExpectMessage(t)
+			if e, ok := msg.(*dap.OutputEvent); ok {
+				output[e.Body.Category] += e.Body.Output
+			}
+			if _, ok := msg.(*dap.TerminatedEvent); ok {
+				break
+			}
+		}
+		// The output is flushed before the terminated event.
+		if output["stdout"] != "to stdout\n" || output["stderr"] != "to stderr\n" {
+			t.Errorf("wrong output: %q", output)
+		}
+	})
+}
+
 // Tests that 'buildFlags' from LaunchRequest are parsed and passed to the
 // compiler. The target program exits without an error on success, and
 // panics on error, causing an unexpected StoppedEvent instead of
@@ -4166,17 +4297,8 @@ func TestUnupportedCommandResponses(t *testing.T) {
 		client.CompletionsRequest()
 		expectUnsupportedCommand("completions")
 
-		client.DataBreakpointInfoRequest()
-		expectUnsupportedCommand("dataBreakpointInfo")
-
-		client.SetDataBreakpointsRequest()
-		expectUnsupportedCommand("setDataBreakpoints")
-
 		client.BreakpointLocationsRequest()
 		expectUnsupportedCommand("breakpointLocations")
-
-		client.ModulesRequest()
-		expectUnsupportedCommand("modules")
 	})
 }
 
@@ -4552,6 +4674,185 @@ func TestSetVariableWithCall(t *testing.T) {
 	})
 }
 
+func TestLoadedSourcesAndModules(t *testing.T) {
+	runTest(t, "panic", func(client *daptest.Client, fixture protest.Fixture) {
+		runDebugSessionWithBPs(t, client, "launch",
+			// Launch
+			func() {
+				client.LaunchRequest("exec", fixture.Path, !stopOnEntry)
+			},
+			// Set breakpoints
+			fixture.Source, []int{5},
+			[]onBreakpoint{{
+				execute: func() {
+					checkStop(t, client, 1, "main.main", 5)
+
+					client.LoadedSourcesRequest()
+					ls := client.ExpectLoadedSourcesResponse(t)
+					found := false
+					for _, src := range ls.Body.Sources {
+						if src.Path == fixture.Source {
+							found = true
+						}
+						if strings.HasPrefix(src.Path, "<") {
+							t.Errorf("unexpected source %#v", src)
+						}
+					}
+					if !found {
+						t.Errorf("fixture source %s not found in %#v", fixture.Source, ls.Body.Sources)
+					}
+
+					client.ModulesRequest()
+					mods := client.ExpectModulesResponse(t)
+					if mods.Body.TotalModules < 1 || len(mods.Body.Modules) != mods.Body.TotalModules || mods.Body.Modules[0].Path != fixture.Path {
+						t.Errorf("\ngot  %#v\nwant executable %s as first module", mods, fixture.Path)
+					}
+				},
+				disconnect: true,
+			}})
+	})
+}
+
+func TestModulesPage(t *testing.T) {
+	for _, tc := range []struct {
+		n, start, count int
+		wantStart       int
+		wantEnd         int
+	}{
+		{3, 0, 0, 0, 3},
+		{3, 1, 0, 1, 3},
+		{3, 1, 1, 1, 2},
+		{3, 2, 5, 2, 3},
+		{3, 5, 1, 3, 3},
+		{3, -1, 0, 0, 3},
+		{3, -5, 2, 0, 2},
+		{3, 0, -1, 0, 3},
+		{0, 0, 0, 0, 0},
+	} {
+		start, end := modulesPage(tc.n, tc.start, tc.count)
+		if start != tc.wantStart || end != tc.wantEnd {
+			t.Errorf("modulesPage(%d, %d, %d) = %d, %d, want %d, %d", tc.n, tc.start, tc.count, start, end, tc.wantStart, tc.wantEnd)
+		}
+	}
+}
+
+func TestDataBpWatchType(t *testing.T) {
+	for _, tc := range []struct {
+		accessType dap.DataBreakpointAccessType
+		wtype      api.WatchType
+		ok         bool
+	}{
+		{"", api.WatchWrite, true},
+		{"write", api.WatchWrite, true},
+		{"read", api.WatchRead, true},
+		{"readWrite", api.WatchRead | api.WatchWrite, true},
+		{"execute", 0, false},
+	} {
+		wtype, ok := dataBpWatchType(tc.accessType)
+		if wtype != tc.wtype || ok != tc.ok {
+			t.Errorf("dataBpWatchType(%q) = %v, %v, want %v, %v", tc.accessType, wtype, ok, tc.wtype, tc.ok)
+		}
+	}
+}
+
+func TestDataBreakpoints(t *testing.T) {
+	runTest(t, "databpeasy", func(client *daptest.Client, fixture protest.Fixture) {
+		runDebugSessionWithBPs(t, client, "launch",
+			// Launch
+			func() {
+				client.LaunchRequest("exec", fixture.Path, !stopOnEntry)
+			},
+			// Set breakpoints
+			fixture.Source, []int{13},
+			[]onBreakpoint{{
+				execute: func() {
+					checkStop(t, client, 1, "main.main", 13)
+
+					client.DataBreakpointInfoRequest(0, "fmt.Sprintf")
+					info := client.ExpectDataBreakpointInfoResponse(t)
+					if info.Body.DataId != nil {
+						t.Errorf("\ngot  %#v\nwant DataId=nil", info)
+					}
+
+					client.DataBreakpointInfoRequest(0, "globalvar1")
+					info = client.ExpectDataBreakpointInfoResponse(t)
+					dataID, _ := info.Body.DataId.(string)
+					if dataID == "" || info.Body.Description != "globalvar1" {
+						t.Fatalf("\ngot  %#v\nwant DataId!=\"\" Description=\"globalvar1\"", info)
+					}
+
+					client.SetDataBreakpointsRequest([]dap.DataBreakpoint{{DataId: dataID}, {DataId: "nonexistent"}})
+					bps := client.ExpectSetDataBreakpointsResponse(t)
+					if len(bps.Body.Breakpoints) != 2 || !bps.Body.Breakpoints[0].Verified || bps.Body.Breakpoints[1].Verified {
+						t.Fatalf("\ngot  %#v\nwant first breakpoint verified, second not verified", bps)
+					}
+
+					client.ContinueRequest(1)
+					client.ExpectContinueResponse(t)
+					se := client.ExpectStoppedEvent(t)
+					if se.Body.Reason != "data breakpoint" || len(se.Body.HitBreakpointIds) != 1 || se.Body.HitBreakpointIds[0] != bps.Body.Breakpoints[0].Id {
+						t.Errorf("\ngot  %#v\nwant Reason=\"data breakpoint\" HitBreakpointIds=[%d]", se, bps.Body.Breakpoints[0].Id)
+					}
+
+					client.SetDataBreakpointsRequest(nil)
+					bps = client.ExpectSetDataBreakpointsResponse(t)
+					if len(bps.Body.Breakpoints) != 0 {
+						t.Errorf("\ngot  %#v\nwant no breakpoints", bps)
+					}
+				},
+				disconnect: true,
+			}})
+	})
+}
+
+func TestInstructionBreakpoints(t *testing.T) {
+	runTest(t, "increment", func(client *daptest.Client, fixture protest.Fixture) {
+		runDebugSessionWithBPs(t, client, "launch",
+			// Launch
+			func() {
+				client.LaunchRequest("exec", fixture.Path, !stopOnEntry)
+			},
+			// Set breakpoints
+			fixture.Source, []int{7},
+			[]onBreakpoint{{
+				execute: func() {
+					checkStop(t, client, 1, "main.Increment", 7)
+
+					client.StackTraceRequest(1, 0, 1)
+					st := client.ExpectStackTraceResponse(t)
+					ref := st.Body.StackFrames[0].InstructionPointerReference
+					if !strings.HasPrefix(ref, "0x") {
+						t.Fatalf("\ngot  %#v\nwant InstructionPointerReference set", st.Body.StackFrames[0])
+					}
+
+					// Replace the line breakpoint with an instruction breakpoint
+					// on the current instruction.
+					client.SetBreakpointsRequest(fixture.Source, []int{})
+					client.ExpectSetBreakpointsResponse(t)
+					client.SetInstructionBreakpointsRequest([]dap.InstructionBreakpoint{{InstructionReference: ref}, {InstructionReference: "bogus"}})
+					bps := client.ExpectSetInstructionBreakpointsResponse(t)
+					if len(bps.Body.Breakpoints) != 2 || !bps.Body.Breakpoints[0].Verified || bps.Body.Breakpoints[0].Line != 7 || bps.Body.Breakpoints[1].Verified {
+						t.Fatalf("\ngot  %#v\nwant first breakpoint verified on line 7, second not verified", bps)
+					}
+
+					client.ContinueRequest(1)
+					client.ExpectContinueResponse(t)
+					se := client.ExpectStoppedEvent(t)
+					if se.Body.Reason != "instruction breakpoint" {
+						t.Errorf("\ngot  %#v\nwant Reason=\"instruction breakpoint\"", se)
+					}
+
+					client.SetInstructionBreakpointsRequest(nil)
+					bps = client.ExpectSetInstructionBreakpointsResponse(t)
+					if len(bps.Body.Breakpoints) != 0 {
+						t.Errorf("\ngot  %#v\nwant no breakpoints", bps)
+					}
+				},
+				disconnect: true,
+			}})
+	})
+}
+
 func TestOptionalNotYetImplementedResponses(t *testing.T) {
 	var got *dap.ErrorResponse
 	runTest(t, "increment", func(client *daptest.Client, fixture protest.Fixture) {
@@ -4575,9 +4876,6 @@ func TestOptionalNotYetImplementedResponses(t *testing.T) {
 		client.SetExpressionRequest()
 		expectNotYetImplemented("setExpression")
 
-		client.LoadedSourcesRequest()
-		expectNotYetImplemented("loadedSources")
-
 		client.ReadMemoryRequest()
 		expectNotYetImplemented("readMemory")
 
@@ -4585,7 +4883,7 @@ func TestOptionalNotYetImplementedResponses(t *testing.T) {
 		expectNotYetImplemented("disassemble")
 
 		client.CancelRequest()
-		expectNotYetImplemented("cancel")
+		client.ExpectCancelResponse(t)
 	})
 }
 
@@ -4685,6 +4983,11 @@ func TestBadLaunchRequests(t *testing.T) {
 		checkFailedToLaunchWithMessage(client.ExpectInvisibleErrorResponse(t),
 			"Failed to launch: could not launch process: unknown backend \"\"")
 
+		// Bad "outputMode"
+		client.LaunchRequestWithArgs(map[string]interface{}{"mode": "debug", "program": fixture.Source, "outputMode": "foo"})
+		checkFailedToLaunchWithMessage(client.ExpectInvisibleErrorResponse(t),
+			"Failed to launch: 'outputMode' attribute 'foo' in debug configuration is not 'local' or 'remote'.")
+
 		// Bad "substitutePath"
 		client.LaunchRequestWithArgs(map[string]interface{}{"mode": "debug", "program": fixture.Source, "substitutePath": 123})
 		checkFailedToLaunchWithMessage(client.ExpectInvisibleErrorResponse(t),
@@ -4877,6 +5180,93 @@ func TestBadAttachRequest(t *testing.T) {
 	})
 }
 
+func TestIdleTimeoutWhileRunning(t *testing.T) {
+	fixture := protest.BuildFixture("loopprog", protest.AllNonOptimized)
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	disconnectChan := make(chan struct{})
+	server := NewServer(&service.Config{
+		Listener:       listener,
+		DisconnectChan: disconnectChan,
+		IdleTimeout:    300 * time.Millisecond,
+	})
+	server.Run()
+	defer server.Stop()
+
+	client := daptest.NewClient(listener.Addr().String())
+	defer client.Close()
+	client.InitializeRequest()
+	client.ExpectInitializeResponseAndCapabilities(t)
+	client.LaunchRequest("exec", fixture.Path, !stopOnEntry)
+	client.ExpectInitializedEvent(t)
+	client.ExpectLaunchResponse(t)
+	// The program runs an infinite loop, the session must survive while
+	// the client waits for it to stop for longer than the idle timeout.
+	client.ConfigurationDoneRequest()
+	client.ExpectConfigurationDoneResponse(t)
+
+	select {
+	case <-disconnectChan:
+		t.Fatal("server stopped while the target was running")
+	case <-time.After(time.Second):
+	}
+
+	client.PauseRequest(1)
+	for i := 0; i < 2; i++ {
+		switch m := client.ExpectMessage(t).(type) {
+		case *dap.StoppedEvent, *dap.PauseResponse:
+		default:
+			t.Fatalf("got %#v, want StoppedEvent or PauseResponse", m)
+		}
+	}
+
+	// once stopped the idle timer runs again
+	select {
+	case <-disconnectChan:
+	case <-time.After(5 * time.Second):
+		t.Fatal("server was not stopped after the idle timeout")
+	}
+}
+
+func TestCommandsServedMetric(t *testing.T) {
+	client := startDapServer(t)
+	defer client.Close()
+	before := metrics.CommandsServed.Value()
+	client.InitializeRequest()
+	client.ExpectInitializeResponse(t)
+	if served := metrics.CommandsServed.Value() - before; served != 1 {
+		t.Errorf("got %d commands served, want 1", served)
+	}
+}
+
+func TestIdleTimeout(t *testing.T) {
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	disconnectChan := make(chan struct{})
+	server := NewServer(&service.Config{
+		Listener:       listener,
+		DisconnectChan: disconnectChan,
+		IdleTimeout:    200 * time.Millisecond,
+	})
+	server.Run()
+	defer server.Stop()
+
+	client := daptest.NewClient(listener.Addr().String())
+	defer client.Close()
+	client.InitializeRequest()
+	client.ExpectInitializeResponse(t)
+
+	select {
+	case <-disconnectChan:
+	case <-time.After(5 * time.Second):
+		t.Fatal("server was not stopped after the idle timeout")
+	}
+}
+
 func TestBadInitializeRequest(t *testing.T) {
 	runInitializeTest := func(args dap.InitializeRequestArguments, err string) {
 		t.Helper()
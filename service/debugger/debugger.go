@@ -2,19 +2,24 @@ package debugger
 
 import (
 	"bytes"
+	"context"
 	"debug/dwarf"
 	"errors"
 	"fmt"
-	"go/parser"
+	"go/constant"
 	"go/token"
+	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"runtime"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/go-delve/delve/pkg/dwarf/op"
@@ -22,8 +27,10 @@ import (
 	"github.com/go-delve/delve/pkg/goversion"
 	"github.com/go-delve/delve/pkg/locspec"
 	"github.com/go-delve/delve/pkg/logflags"
+	"github.com/go-delve/delve/pkg/metrics"
 	"github.com/go-delve/delve/pkg/proc"
 	"github.com/go-delve/delve/pkg/proc/core"
+	"github.com/go-delve/delve/pkg/proc/evalexpr"
 	"github.com/go-delve/delve/pkg/proc/gdbserial"
 	"github.com/go-delve/delve/pkg/proc/native"
 	"github.com/go-delve/delve/service/api"
@@ -67,15 +74,87 @@ type Debugger struct {
 
 	running      bool
 	runningMutex sync.Mutex
+	// continueIterations is the number of times execution was resumed by
+	// the running continue command with the Until option, zero if there
+	// isn't one, protected by runningMutex.
+	continueIterations int
+	// runningBuildID is the build ID of the executable of the target the
+	// last time it was resumed, protected by runningMutex.
+	runningBuildID string
 
 	stopRecording func() error
+	recordingErr  error
 	recordMutex   sync.Mutex
 
 	dumpState proc.DumpState
+
+	// opCancel cancels the long running operation currently in progress,
+	// see startOperation and CancelRequest.
+	opCancel      context.CancelFunc
+	opCancelMutex sync.Mutex
+
 	// Debugger keeps a map of disabled breakpoints
 	// so lower layers like proc doesn't need to deal
 	// with them
 	disabledBreakpoints map[int]*api.Breakpoint
+
+	// runtimeTrace is the execution trace started by StartRuntimeTrace, if
+	// any.
+	runtimeTrace *runtimeTrace
+
+	// reattachStop is closed to stop waiting for a new process to attach to,
+	// see reattach.
+	reattachStop  chan struct{}
+	reattachMutex sync.Mutex
+
+	// output holds the standard output and standard error of the target if
+	// Config.CaptureOutput is set.
+	output [2]*outputBuffer
+
+	// targetIdentity identifies the process the debugger attached to, it
+	// is nil for launched processes or if the identity of processes can not
+	// be read on this operating system.
+	targetIdentity *processIdentity
+
+	// watchExprs are the expressions evaluated every time the target stops,
+	// see AddWatchExpression. watchSeq is incremented every time they, or
+	// their values, change and watchChanged is closed and replaced when it
+	// is. They are protected by watchMutex.
+	watchExprs     []*watchExpr
+	watchExprCount int
+	watchSeq       int
+	watchChanged   chan struct{}
+	watchMutex     sync.Mutex
+
+	// commandHooks are the hooks of terminal commands, indexed by command
+	// name, see SetCommandHooks. They are protected by hooksMutex.
+	commandHooks map[string]api.CommandHooks
+	hooksMutex   sync.Mutex
+}
+
+// watchExpr is an expression evaluated every time the target stops.
+type watchExpr struct {
+	api.WatchExpression
+	cfg proc.LoadConfig
+}
+
+// processIdentity identifies a process beyond its pid, which the operating
+// system reuses once the process has exited and been reaped.
+type processIdentity struct {
+	startTime uint64    // start time of the process, in an OS specific unit
+	startedAt time.Time // start time of the process as wall clock time, if known
+}
+
+// errProcessIdentityUnsupported is returned by readProcessIdentity on
+// operating systems where the identity of a process can not be read, the
+// pid reuse checks are skipped there.
+var errProcessIdentityUnsupported = errors.New("reading the identity of a process is not supported")
+
+// runtimeTrace describes an execution trace of the target runtime.
+type runtimeTrace struct {
+	path string
+	// file is the address of the *os.File the trace is written to.
+	file uint64
 }
 
 type ExecuteKind int
@@ -101,6 +180,14 @@ type Config struct {
 	// attach.
 	AttachPid int
 
+	// AttachWaitFor, if set, makes the debugger wait for a new process and
+	// attach to it, new processes are looked for every
+	// reattachPollInterval. If it contains a path separator it
+	// is the path of a file containing the pid of the process, otherwise it
+	// is the name of its executable. Processes already running when the
+	// debugger starts are ignored.
+	AttachWaitFor string
+
 	// CoreFile specifies the path to the core dump to open.
 	CoreFile string
 
@@ -135,8 +222,30 @@ type Config struct {
 	// Redirects specifies redirect rules for stdin, stdout and stderr
 	Redirects [3]string
 
+	// CaptureOutput, if set, makes the debugger capture the standard output
+	// and standard error of launched targets, unless they are redirected to
+	// a file, the captured output can be read with GetOutput.
+	CaptureOutput bool
+
 	// DisableASLR disables ASLR
 	DisableASLR bool
+
+	// ReattachOnExit, if set, makes the debugger wait for a new process when
+	// the target exits while continuing, attach to it and continue it. If
+	// it contains a path separator it is the path of a file containing the
+	// pid of the new process, otherwise it is the name of its executable.
+	// Only processes owned by the user running the debugger are attached.
+	ReattachOnExit string
+
+	// StopOnEntry, if set, makes the debugger run launched processes until
+	// the entry point of main.main, so that the runtime and package
+	// initialization have completed when the debug session starts.
+	StopOnEntry bool
+
+	// StopOnFault, if set, makes the target stop on all fault signals,
+	// including the ones that the Go runtime converts into panics, see
+	// proc.Target.StopOnFault.
+	StopOnFault bool
 }
 
 // New creates a new Debugger. ProcessArgs specify the commandline arguments for the
@@ -144,13 +253,39 @@ type Config struct {
 func New(config *Config, processArgs []string) (*Debugger, error) {
 	logger := logflags.DebuggerLogger()
 	d := &Debugger{
-		config:      config,
-		processArgs: processArgs,
-		log:         logger,
+		config:       config,
+		processArgs:  processArgs,
+		log:          logger,
+		watchChanged: make(chan struct{}),
+	}
+	if config.CaptureOutput {
+		d.output = [2]*outputBuffer{newOutputBuffer(), newOutputBuffer()}
 	}
 
 	// Create the process by either attaching or launching.
 	switch {
+	case d.config.AttachWaitFor != "":
+		d.log.Infof("waiting for a process matching %q", d.config.AttachWaitFor)
+		path := ""
+		if len(d.processArgs) > 0 {
+			path = d.processArgs[0]
+		}
+		owner := os.Getuid()
+		if owner == 0 {
+			owner = anyOwner
+		}
+		exclude, err := d.runningProcesses(d.config.AttachWaitFor, owner)
+		if err != nil {
+			return nil, err
+		}
+		p, err := d.waitForProcess(d.config.AttachWaitFor, path, exclude, owner)
+		if err != nil {
+			return nil, err
+		}
+		d.config.AttachPid = p.Pid()
+		d.log.Infof("attached to pid %d", p.Pid())
+		d.target = p
+
 	case d.config.AttachPid > 0:
 		d.log.Infof("attaching to pid %d", d.config.AttachPid)
 		path := ""
@@ -203,6 +338,12 @@ func New(config *Config, processArgs []string) (*Debugger, error) {
 			d.target.Detach(true)
 			return nil, err
 		}
+		if p != nil && d.config.StopOnEntry {
+			if err := d.runToMain(); err != nil {
+				d.target.Detach(true)
+				return nil, fmt.Errorf("could not run to main.main: %v", err)
+			}
+		}
 	}
 
 	d.disabledBreakpoints = make(map[int]*api.Breakpoint)
@@ -213,7 +354,7 @@ func New(config *Config, processArgs []string) (*Debugger, error) {
 // canRestart returns true if the target was started with Launch and can be restarted
 func (d *Debugger) canRestart() bool {
 	switch {
-	case d.config.AttachPid > 0:
+	case d.config.AttachPid > 0, d.config.AttachWaitFor != "":
 		return false
 	case d.config.CoreFile != "":
 		return false
@@ -257,11 +398,22 @@ func (d *Debugger) Launch(processArgs []string, wd string) (*proc.Target, error)
 		launchFlags |= proc.LaunchDisableASLR
 	}
 
+	var stdoutOR, stderrOR proc.OutputRedirect
+	if d.config.Backend != "rr" {
+		var closefn func()
+		var err error
+		stdoutOR, stderrOR, closefn, err = d.outputRedirects()
+		if err != nil {
+			return nil, err
+		}
+		defer closefn()
+	}
+
 	switch d.config.Backend {
 	case "native":
-		return native.Launch(processArgs, wd, launchFlags, d.config.DebugInfoDirectories, d.config.TTY, d.config.Redirects)
+		return native.Launch(processArgs, wd, launchFlags, d.config.DebugInfoDirectories, d.config.TTY, d.config.Redirects[0], stdoutOR, stderrOR)
 	case "lldb":
-		return betterGdbserialLaunchError(gdbserial.LLDBLaunch(processArgs, wd, launchFlags, d.config.DebugInfoDirectories, d.config.TTY, d.config.Redirects))
+		return betterGdbserialLaunchError(gdbserial.LLDBLaunch(processArgs, wd, launchFlags, d.config.DebugInfoDirectories, d.config.TTY, d.config.Redirects[0], stdoutOR, stderrOR))
 	case "rr":
 		if d.target != nil {
 			// restart should not call us if the backend is 'rr'
@@ -283,13 +435,11 @@ func (d *Debugger) Launch(processArgs []string, wd string) (*proc.Target, error)
 			defer d.targetMutex.Unlock()
 
 			p, err := d.recordingRun(run)
+			d.recordingDone(err)
 			if err != nil {
 				d.log.Errorf("could not record target: %v", err)
-				// this is ugly but we can't respond to any client requests at this
-				// point so it's better if we die.
-				os.Exit(1)
+				return
 			}
-			d.recordingDone()
 			d.target = p
 			if err := d.checkGoVersion(); err != nil {
 				d.log.Error(err)
@@ -303,26 +453,70 @@ func (d *Debugger) Launch(processArgs []string, wd string) (*proc.Target, error)
 
 	case "default":
 		if runtime.GOOS == "darwin" {
-			return betterGdbserialLaunchError(gdbserial.LLDBLaunch(processArgs, wd, launchFlags, d.config.DebugInfoDirectories, d.config.TTY, d.config.Redirects))
+			return betterGdbserialLaunchError(gdbserial.LLDBLaunch(processArgs, wd, launchFlags, d.config.DebugInfoDirectories, d.config.TTY, d.config.Redirects[0], stdoutOR, stderrOR))
 		}
-		return native.Launch(processArgs, wd, launchFlags, d.config.DebugInfoDirectories, d.config.TTY, d.config.Redirects)
+		return native.Launch(processArgs, wd, launchFlags, d.config.DebugInfoDirectories, d.config.TTY, d.config.Redirects[0], stdoutOR, stderrOR)
 	default:
 		return nil, fmt.Errorf("unknown backend %q", d.config.Backend)
 	}
 }
 
+// outputRedirects returns the redirects of the standard output and standard
+// error of a target launched by Launch. If the output is captured the
+// streams that aren't redirected to a file are connected to pipes read into
+// d.output, closefn must be called once the target is started to close the
+// ends of the pipes used by the target.
+func (d *Debugger) outputRedirects() (stdoutOR, stderrOR proc.OutputRedirect, closefn func(), err error) {
+	ors := [2]proc.OutputRedirect{{Path: d.config.Redirects[1]}, {Path: d.config.Redirects[2]}}
+	var toclose []*os.File
+	closefn = func() {
+		for _, f := range toclose {
+			_ = f.Close()
+		}
+	}
+	for i := range ors {
+		if ors[i].Path != "" || d.output[i] == nil {
+			continue
+		}
+		r, w, err := os.Pipe()
+		if err != nil {
+			closefn()
+			return proc.OutputRedirect{}, proc.OutputRedirect{}, nil, err
+		}
+		toclose = append(toclose, w)
+		ors[i].File = w
+		go d.output[i].readFrom(r)
+	}
+	return ors[0], ors[1], closefn, nil
+}
+
 func (d *Debugger) recordingStart(stop func() error) {
 	d.recordMutex.Lock()
 	d.stopRecording = stop
 	d.recordMutex.Unlock()
 }
 
-func (d *Debugger) recordingDone() {
+// recordingDone marks the end of a recording, err is the error that made
+// it fail, if any, and is returned by recordingError afterwards.
+func (d *Debugger) recordingDone(err error) {
 	d.recordMutex.Lock()
 	d.stopRecording = nil
+	d.recordingErr = err
 	d.recordMutex.Unlock()
 }
 
+// recordingError returns the error that made the initial recording fail,
+// when that happens the debugger has no target and every request that
+// needs one fails with this error.
+func (d *Debugger) recordingError() error {
+	d.recordMutex.Lock()
+	defer d.recordMutex.Unlock()
+	if d.recordingErr == nil {
+		return nil
+	}
+	return fmt.Errorf("could not record target: %w", d.recordingErr)
+}
+
 func (d *Debugger) isRecording() bool {
 	d.recordMutex.Lock()
 	defer d.recordMutex.Unlock()
@@ -339,7 +533,24 @@ func (d *Debugger) recordingRun(run func() (string, error)) (*proc.Target, error
 }
 
 // Attach will attach to the process specified by 'pid'.
+// Once the process is stopped it is checked to still be the process that
+// had pid when Attach was called, in case the pid was reused. Whether
+// delve is allowed to attach is left to the operating system.
 func (d *Debugger) Attach(pid int, path string) (*proc.Target, error) {
+	id, idErr := readProcessIdentity(pid)
+	p, err := d.attach(pid, path)
+	if err != nil || idErr != nil {
+		return p, err
+	}
+	if id2, err := readProcessIdentity(pid); err != nil || id2.startTime != id.startTime {
+		p.Detach(false)
+		return nil, fmt.Errorf("pid %d was reused by a different process while attaching", pid)
+	}
+	d.targetIdentity = &id
+	return p, nil
+}
+
+func (d *Debugger) attach(pid int, path string) (*proc.Target, error) {
 	switch d.config.Backend {
 	case "native":
 		return native.Attach(pid, d.config.DebugInfoDirectories)
@@ -391,35 +602,11 @@ func (d *Debugger) FunctionReturnLocations(fnName string) ([]uint64, error) {
 	d.targetMutex.Lock()
 	defer d.targetMutex.Unlock()
 
-	var (
-		p = d.target
-		g = p.SelectedGoroutine()
-	)
-
-	fn, ok := p.BinInfo().LookupFunc[fnName]
-	if !ok {
+	addrs, err := proc.FindFunctionReturnLocations(d.target, fnName)
+	if _, notfound := err.(*proc.ErrFunctionNotFound); notfound {
 		return nil, fmt.Errorf("unable to find function %s", fnName)
 	}
-
-	var regs proc.Registers
-	mem := p.Memory()
-	if g != nil && g.Thread != nil {
-		regs, _ = g.Thread.Registers()
-	}
-	instructions, err := proc.Disassemble(mem, regs, p.Breakpoints(), p.BinInfo(), fn.Entry, fn.End)
-	if err != nil {
-		return nil, err
-	}
-
-	var addrs []uint64
-	for _, instruction := range instructions {
-		if instruction.IsRet() {
-			addrs = append(addrs, instruction.Loc.PC)
-		}
-	}
-	addrs = append(addrs, proc.FindDeferReturnCalls(instructions)...)
-
-	return addrs, nil
+	return addrs, err
 }
 
 // Detach detaches from the target process.
@@ -429,6 +616,10 @@ func (d *Debugger) Detach(kill bool) error {
 	d.log.Debug("detaching")
 	d.targetMutex.Lock()
 	defer d.targetMutex.Unlock()
+	if d.target == nil {
+		// the initial recording failed, there is nothing to detach from
+		return nil
+	}
 	if ok, _ := d.target.Valid(); !ok {
 		return nil
 	}
@@ -439,9 +630,28 @@ func (d *Debugger) detach(kill bool) error {
 	if d.config.AttachPid == 0 {
 		kill = true
 	}
+	if kill && d.targetIdentity != nil {
+		if err := d.checkTargetIdentity(); err != nil {
+			d.target.Detach(false)
+			return err
+		}
+	}
 	return d.target.Detach(kill)
 }
 
+// checkTargetIdentity returns an error if the pid of the target no longer
+// refers to the process the debugger attached to. It should be called
+// before any operation that could harm an unrelated process that reused
+// the pid.
+func (d *Debugger) checkTargetIdentity() error {
+	pid := d.target.Pid()
+	id, err := readProcessIdentity(pid)
+	if err != nil || id.startTime != d.targetIdentity.startTime {
+		return fmt.Errorf("pid %d no longer refers to the process delve attached to, refusing to kill it", pid)
+	}
+	return nil
+}
+
 // Restart will restart the target process, first killing
 // and then exec'ing it again.
 // If the target process is a recording it will restart it from the given
@@ -506,7 +716,7 @@ func (d *Debugger) Restart(rerecord bool, pos string, resetArgs bool, newArgs []
 
 		d.recordingStart(stop)
 		p, err = d.recordingRun(run)
-		d.recordingDone()
+		d.recordingDone(nil)
 	} else {
 		p, err = d.Launch(d.processArgs, d.config.WorkingDir)
 	}
@@ -514,9 +724,48 @@ func (d *Debugger) Restart(rerecord bool, pos string, resetArgs bool, newArgs []
 		return nil, fmt.Errorf("could not launch process: %s", err)
 	}
 
+	discarded, err := d.switchTarget(p, rebuild)
+	if err != nil {
+		return nil, err
+	}
+	if d.config.StopOnEntry && !recorded {
+		if err := d.runToMain(); err != nil {
+			return discarded, fmt.Errorf("could not run to main.main: %v", err)
+		}
+	}
+	return discarded, nil
+}
+
+// runToMain resumes the target, which must have just been launched, until
+// the entry point of main.main. The breakpoint used is a stepping
+// breakpoint, so that the target stops as if a 'next' had completed and no
+// breakpoint is left behind, unless a user breakpoint is hit first.
+func (d *Debugger) runToMain() error {
+	pcs, err := proc.FindFunctionLocation(d.target, "main.main", 0)
+	if err != nil {
+		return err
+	}
+	for _, pc := range pcs {
+		if _, err := d.target.SetBreakpoint(pc, proc.NextBreakpoint, nil); err != nil {
+			d.target.ClearSteppingBreakpoints()
+			return err
+		}
+	}
+	err = d.target.Continue()
+	if clearErr := d.target.ClearSteppingBreakpoints(); err == nil {
+		err = clearErr
+	}
+	return err
+}
+
+// switchTarget replaces the target with p and recreates the breakpoints
+// of the old target on it, returning the ones that could not be
+// recreated. Address breakpoints are discarded if rebuild is true.
+func (d *Debugger) switchTarget(p *proc.Target, rebuild bool) ([]api.DiscardedBreakpoint, error) {
 	discarded := []api.DiscardedBreakpoint{}
 	breakpoints := api.ConvertBreakpoints(d.breakpoints())
 	d.target = p
+	d.runtimeTrace = nil
 	maxID := 0
 	for _, oldBp := range breakpoints {
 		if oldBp.ID < 0 {
@@ -528,7 +777,7 @@ func (d *Debugger) Restart(rerecord bool, pos string, resetArgs bool, newArgs []
 		if oldBp.WatchExpr != "" {
 			discarded = append(discarded, api.DiscardedBreakpoint{Breakpoint: oldBp, Reason: "can not recreate watchpoints on restart"})
 		} else if len(oldBp.File) > 0 {
-			addrs, err := proc.FindFileLocation(p, oldBp.File, oldBp.Line)
+			addrs, _, err := proc.FindFileColumnLocation(p, oldBp.File, oldBp.Line, oldBp.Column)
 			if err != nil {
 				discarded = append(discarded, api.DiscardedBreakpoint{Breakpoint: oldBp, Reason: err.Error()})
 				continue
@@ -540,11 +789,7 @@ func (d *Debugger) Restart(rerecord bool, pos string, resetArgs bool, newArgs []
 				discarded = append(discarded, api.DiscardedBreakpoint{Breakpoint: oldBp, Reason: "can not recreate address breakpoints on restart"})
 				continue
 			}
-			newBp, err := p.SetBreakpointWithID(oldBp.ID, oldBp.Addr)
-			if err != nil {
-				return nil, err
-			}
-			if err := copyBreakpointInfo(newBp, oldBp); err != nil {
+			if _, err := createLogicalBreakpoint(d, oldBp.Addrs, oldBp, oldBp.ID); err != nil {
 				return nil, err
 			}
 		}
@@ -558,15 +803,167 @@ func (d *Debugger) Restart(rerecord bool, pos string, resetArgs bool, newArgs []
 	return discarded, nil
 }
 
+// reattachPollInterval is how often waitForProcess looks for a new process.
+const reattachPollInterval = 100 * time.Millisecond
+
+var errReattachStopped = errors.New("stopped waiting for a new process")
+
+// anyOwner is passed to findProcessBySpec to accept processes of any user.
+const anyOwner = -1
+
+// continueReattaching is called when the target exits while being
+// continued by cont: every time the target exits it waits for a new
+// process matching Config.ReattachOnExit, attaches to it, recreates the
+// breakpoints and calls cont again. Returns the error that stopped the
+// target or, if no new process could be attached, exitErr.
+// Only processes owned by owner, the owner of the original target, are
+// attached.
+func (d *Debugger) continueReattaching(exitErr error, owner int, cont func() error) error {
+	for {
+		pe, exited := exitErr.(proc.ErrProcessExited)
+		if !exited {
+			return exitErr
+		}
+		d.log.Infof("process %d exited, waiting for a new process matching %q", pe.Pid, d.config.ReattachOnExit)
+		p, err := d.waitForProcess(d.config.ReattachOnExit, "", map[int]bool{pe.Pid: true, os.Getpid(): true}, owner)
+		if err != nil {
+			if err != errReattachStopped {
+				d.log.Errorf("could not reattach: %v", err)
+			}
+			return exitErr
+		}
+		d.config.AttachPid = p.Pid()
+		discarded, err := d.switchTarget(p, false)
+		if err != nil {
+			return err
+		}
+		for _, dbp := range discarded {
+			d.log.Warnf("breakpoint %d discarded after reattaching: %s", dbp.Breakpoint.ID, dbp.Reason)
+		}
+		d.log.Infof("reattached to pid %d", p.Pid())
+		exitErr = cont()
+	}
+}
+
+// waitForProcess waits for a process of owner, not in exclude, matching
+// spec and attaches to it, see Config.ReattachOnExit for the syntax of spec.
+// Returns errReattachStopped if a halt is requested while waiting, processes
+// that exit before they are attached are skipped, other errors attaching
+// to a process are returned.
+func (d *Debugger) waitForProcess(spec, path string, exclude map[int]bool, owner int) (*proc.Target, error) {
+	stop := make(chan struct{})
+	d.reattachMutex.Lock()
+	d.reattachStop = stop
+	d.reattachMutex.Unlock()
+	defer func() {
+		d.reattachMutex.Lock()
+		d.reattachStop = nil
+		d.reattachMutex.Unlock()
+	}()
+
+	failed := exclude
+	for {
+		pid, err := findProcessBySpec(spec, failed, owner)
+		if err != nil {
+			return nil, err
+		}
+		if pid > 0 {
+			p, err := d.Attach(pid, path)
+			if err == nil {
+				return p, nil
+			}
+			if !processExited(pid, err) {
+				return nil, attachErrorMessage(pid, err)
+			}
+			// the process exited before we could attach to it, keep waiting
+			// for the next one.
+			d.log.Debugf("%v", attachErrorMessage(pid, err))
+			failed[pid] = true
+		}
+		select {
+		case <-stop:
+			return nil, errReattachStopped
+		case <-time.After(reattachPollInterval):
+		}
+	}
+}
+
+// processExited returns true if attaching to pid failed with err because
+// the process exited.
+func processExited(pid int, err error) bool {
+	if errors.Is(err, syscall.ESRCH) {
+		return true
+	}
+	_, err = readProcessIdentity(pid)
+	return err != nil && err != errProcessIdentityUnsupported
+}
+
+// runningProcesses returns the set of processes of owner matching spec that
+// are already running, as well as the debugger itself.
+func (d *Debugger) runningProcesses(spec string, owner int) (map[int]bool, error) {
+	r := map[int]bool{os.Getpid(): true}
+	for {
+		pid, err := findProcessBySpec(spec, r, owner)
+		if err != nil {
+			return nil, err
+		}
+		if pid == 0 {
+			return r, nil
+		}
+		r[pid] = true
+	}
+}
+
+// findProcessBySpec returns the pid of a process matching spec that isn't
+// in exclude, or 0 if there isn't one. See Config.ReattachOnExit for the
+// syntax of spec. Only processes of owner are returned, unless owner is
+// anyOwner.
+func findProcessBySpec(spec string, exclude map[int]bool, owner int) (int, error) {
+	if !strings.ContainsRune(spec, filepath.Separator) && !strings.ContainsRune(spec, '/') {
+		return findProcessByName(spec, exclude, owner)
+	}
+	buf, err := ioutil.ReadFile(spec)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(buf)))
+	if err != nil || pid <= 0 || exclude[pid] {
+		// the pid file could be partially written or stale
+		return 0, nil
+	}
+	if uid, err := processOwner(pid); err == nil && owner != anyOwner && uid != owner {
+		// never attach to processes of other users, even if they managed to
+		// write the pid file
+		return 0, nil
+	}
+	if fi, err := os.Stat(spec); err == nil {
+		// A process writes its pid file after it starts, if the process with
+		// this pid started after the pid file was written the pid file is
+		// stale and the pid was reused.
+		if id, err := readProcessIdentity(pid); err == nil && !id.startedAt.IsZero() && id.startedAt.After(fi.ModTime().Add(time.Second)) {
+			return 0, nil
+		}
+	}
+	return pid, nil
+}
+
 // State returns the current state of the debugger.
 func (d *Debugger) State(nowait bool) (*api.DebuggerState, error) {
 	if d.IsRunning() && nowait {
-		return &api.DebuggerState{Running: true}, nil
+		d.runningMutex.Lock()
+		defer d.runningMutex.Unlock()
+		return &api.DebuggerState{Running: true, ContinueIterations: d.continueIterations}, nil
 	}
 
 	if d.isRecording() && nowait {
 		return &api.DebuggerState{Recording: true}, nil
 	}
+	if err := d.recordingError(); err != nil {
+		return nil, err
+	}
 
 	d.dumpState.Mutex.Lock()
 	if d.dumpState.Dumping && nowait {
@@ -619,6 +1016,20 @@ func (d *Debugger) state(retLoadCfg *proc.LoadConfig) (*api.DebuggerState, error
 
 	state.NextInProgress = d.target.Breakpoints().HasSteppingBreakpoints()
 
+	state.StopReason.Kind = api.ConvertStopReason(d.target.StopReason)
+	switch state.StopReason.Kind {
+	case api.StopBreakpoint, api.StopTracepoint, api.StopWatchpoint, api.StopPanic, api.StopFatalThrow:
+		if state.CurrentThread != nil && state.CurrentThread.Breakpoint != nil {
+			state.StopReason.BreakpointID = state.CurrentThread.Breakpoint.ID
+		}
+	case api.StopSignal:
+		if sig := d.target.CurrentThread().Common().Signal; sig != nil {
+			state.StopReason.Signal = signalName(sig.Signo)
+			state.StopReason.SignalCode = sig.Code
+			state.StopReason.FaultAddr = sig.Addr
+		}
+	}
+
 	if recorded, _ := d.target.Recorded(); recorded {
 		state.When, _ = d.target.When()
 	}
@@ -639,7 +1050,9 @@ func (d *Debugger) state(retLoadCfg *proc.LoadConfig) (*api.DebuggerState, error
 // supplied by the caller.
 //
 // - If requestedBp.File is not an empty string the breakpoint
-// will be created on the specified file:line location
+// will be created on the specified file:line location, if
+// requestedBp.Column is also set the statement of the line starting
+// closest to that column is selected.
 //
 // - If requestedBp.FunctionName is not an empty string
 // the breakpoint will be created on the specified function:line
@@ -683,7 +1096,13 @@ func (d *Debugger) CreateBreakpoint(requestedBp *api.Breakpoint) (*api.Breakpoin
 				}
 			}
 		}
-		addrs, err = proc.FindFileLocation(d.target, fileName, requestedBp.Line)
+		var column int
+		addrs, column, err = proc.FindFileColumnLocation(d.target, fileName, requestedBp.Line, requestedBp.Column)
+		if column != requestedBp.Column {
+			bp := *requestedBp
+			bp.Column = column
+			requestedBp = &bp
+		}
 	case len(requestedBp.FunctionName) > 0:
 		addrs, err = proc.FindFunctionLocation(d.target, requestedBp.FunctionName, requestedBp.Line)
 	case len(requestedBp.Addrs) > 0:
@@ -716,7 +1135,13 @@ func createLogicalBreakpoint(d *Debugger, addrs []uint64, requestedBp *api.Break
 	bps := make([]*proc.Breakpoint, len(addrs))
 	var err error
 	for i := range addrs {
-		if id > 0 {
+		// All physical breakpoints after the first one are added to the same
+		// logical breakpoint, so that logical IDs do not depend on how many
+		// addresses a location resolves to.
+		if i > 0 {
+			id = bps[0].LogicalID
+		}
+		if id != 0 {
 			bps[i], err = p.SetBreakpointWithID(id, addrs[i])
 		} else {
 			bps[i], err = p.SetBreakpoint(addrs[i], proc.UserBreakpoint, nil)
@@ -724,9 +1149,6 @@ func createLogicalBreakpoint(d *Debugger, addrs []uint64, requestedBp *api.Break
 		if err != nil {
 			break
 		}
-		if i > 0 {
-			bps[i].LogicalID = bps[0].LogicalID
-		}
 		err = copyBreakpointInfo(bps[i], requestedBp)
 		if err != nil {
 			break
@@ -762,7 +1184,10 @@ func isBreakpointExistsErr(err error) bool {
 func (d *Debugger) AmendBreakpoint(amend *api.Breakpoint) error {
 	d.targetMutex.Lock()
 	defer d.targetMutex.Unlock()
+	return d.amendBreakpoint(amend)
+}
 
+func (d *Debugger) amendBreakpoint(amend *api.Breakpoint) error {
 	originals := d.findBreakpoint(amend.ID)
 
 	if len(originals) > 0 && originals[0].WatchExpr != "" && amend.Disabled {
@@ -774,14 +1199,26 @@ func (d *Debugger) AmendBreakpoint(amend *api.Breakpoint) error {
 		return fmt.Errorf("no breakpoint with ID %d", amend.ID)
 	}
 	if !amend.Disabled && disabled { // enable the breakpoint
-		bp, err := d.target.SetBreakpointWithID(amend.ID, amend.Addr)
-		if err != nil {
-			return err
+		addrs := amend.Addrs
+		if len(addrs) == 0 {
+			addrs = []uint64{amend.Addr}
 		}
-		copyBreakpointInfo(bp, amend)
+		dbp := d.disabledBreakpoints[amend.ID]
 		delete(d.disabledBreakpoints, amend.ID)
+		if _, err := createLogicalBreakpoint(d, addrs, amend, amend.ID); err != nil {
+			d.disabledBreakpoints[amend.ID] = dbp
+			return err
+		}
+		d.restoreHitCounts(amend.ID, dbp)
 	}
 	if amend.Disabled && !disabled { // disable the breakpoint
+		if lbp := d.target.Breakpoints().Logical[amend.ID]; lbp != nil {
+			amend.TotalHitCount = lbp.TotalHitCount
+			amend.HitCount = make(map[string]uint64, len(lbp.HitCount))
+			for gid, n := range lbp.HitCount {
+				amend.HitCount[strconv.Itoa(gid)] = n
+			}
+		}
 		if _, err := d.clearBreakpoint(amend); err != nil {
 			return err
 		}
@@ -796,6 +1233,91 @@ func (d *Debugger) AmendBreakpoint(amend *api.Breakpoint) error {
 	return nil
 }
 
+// restoreHitCounts copies the hit counts saved in dbp, when the breakpoint
+// was disabled, to the logical breakpoint with the specified ID, so that
+// disabling and enabling a breakpoint does not reset them.
+func (d *Debugger) restoreHitCounts(id int, dbp *api.Breakpoint) {
+	lbp := d.target.Breakpoints().Logical[id]
+	if lbp == nil || dbp == nil {
+		return
+	}
+	lbp.TotalHitCount = dbp.TotalHitCount
+	for s, n := range dbp.HitCount {
+		if gid, err := strconv.Atoi(s); err == nil {
+			lbp.HitCount[gid] = n
+		}
+	}
+}
+
+// AmendBreakpointGroup enables or disables all the breakpoints belonging to
+// group and returns them. Watchpoints can not be disabled and are skipped.
+func (d *Debugger) AmendBreakpointGroup(group string, disabled bool) ([]*api.Breakpoint, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	bps := d.breakpointGroup(group)
+	if len(bps) == 0 {
+		return nil, fmt.Errorf("no breakpoints in group %s", group)
+	}
+	r := make([]*api.Breakpoint, 0, len(bps))
+	for _, bp := range bps {
+		if bp.Disabled == disabled || (disabled && bp.WatchExpr != "") {
+			continue
+		}
+		bp.Disabled = disabled
+		if err := d.amendBreakpoint(bp); err != nil {
+			return r, err
+		}
+		r = append(r, bp)
+	}
+	return r, nil
+}
+
+// ClearBreakpointGroup clears all the breakpoints belonging to group and
+// returns them.
+func (d *Debugger) ClearBreakpointGroup(group string) ([]*api.Breakpoint, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	bps := d.breakpointGroup(group)
+	if len(bps) == 0 {
+		return nil, fmt.Errorf("no breakpoints in group %s", group)
+	}
+	r := make([]*api.Breakpoint, 0, len(bps))
+	for _, bp := range bps {
+		if bp.ID < 0 {
+			// Breakpoints set by Delve, like the one on unrecovered panics,
+			// can only be cleared one at a time.
+			continue
+		}
+		if _, err := d.clearBreakpoint(bp); err != nil {
+			return r, err
+		}
+		r = append(r, bp)
+	}
+	return r, nil
+}
+
+// breakpointGroup returns the enabled and disabled breakpoints belonging
+// to group, sorted by ID.
+func (d *Debugger) breakpointGroup(group string) []*api.Breakpoint {
+	var r []*api.Breakpoint
+	bps := api.ConvertBreakpoints(d.breakpoints())
+	for _, bp := range d.disabledBreakpoints {
+		bps = append(bps, bp)
+	}
+	for _, bp := range bps {
+		for _, g := range bp.Groups {
+			if g == group {
+				r = append(r, bp)
+				break
+			}
+		}
+	}
+	sort.Slice(r, func(i, j int) bool { return r[i].ID < r[j].ID })
+	return r
+}
+
 // CancelNext will clear internal breakpoints, thus cancelling the 'next',
 // 'step' or 'stepout' operation.
 func (d *Debugger) CancelNext() error {
@@ -806,6 +1328,8 @@ func (d *Debugger) CancelNext() error {
 
 func copyBreakpointInfo(bp *proc.Breakpoint, requested *api.Breakpoint) (err error) {
 	bp.Name = requested.Name
+	bp.Groups = requested.Groups
+	bp.Column = requested.Column
 	bp.Tracepoint = requested.Tracepoint
 	bp.TraceReturn = requested.TraceReturn
 	bp.Goroutine = requested.Goroutine
@@ -813,12 +1337,19 @@ func copyBreakpointInfo(bp *proc.Breakpoint, requested *api.Breakpoint) (err err
 	bp.Variables = requested.Variables
 	bp.LoadArgs = api.LoadConfigToProc(requested.LoadArgs)
 	bp.LoadLocals = api.LoadConfigToProc(requested.LoadLocals)
+	if bp.WatchType == 0 && bp.Logical != nil {
+		// the history of watchpoints is set by CreateWatchpoint
+		if err := bp.Logical.SetHistory(requested.WatchHistory); err != nil {
+			return err
+		}
+	}
 	breaklet := bp.UserBreaklet()
 	if breaklet != nil {
 		breaklet.Cond = nil
 		if requested.Cond != "" {
-			breaklet.Cond, err = parser.ParseExpr(requested.Cond)
+			breaklet.Cond, err = evalexpr.Parse(requested.Cond)
 		}
+		breaklet.Caller = requested.Caller
 		breaklet.HitCond = nil
 		if requested.HitCond != "" {
 			opTok, val, parseErr := parseHitCondition(requested.HitCond)
@@ -938,8 +1469,84 @@ func (d *Debugger) clearBreakpoint(requestedBp *api.Breakpoint) (*api.Breakpoint
 	return clearedBp[0], nil
 }
 
-// Breakpoints returns the list of current breakpoints.
+// ClearBreakpoints deletes, in one operation, the enabled and disabled
+// breakpoints selected by filter and returns them sorted by ID.
+// The breakpoints set internally by Delve, like the one on unrecovered
+// panics, are never deleted.
+func (d *Debugger) ClearBreakpoints(filter api.BreakpointFilter) ([]*api.Breakpoint, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	ids := map[int]bool{}
+	for _, bp := range api.ConvertBreakpoints(d.breakpoints()) {
+		if bp.ID >= 0 && breakpointMatches(bp, filter) {
+			ids[bp.ID] = true
+		}
+	}
+
+	var r []*api.Breakpoint
+	for id, bp := range d.disabledBreakpoints {
+		if bp.ID >= 0 && breakpointMatches(bp, filter) {
+			delete(d.disabledBreakpoints, id)
+			r = append(r, bp)
+		}
+	}
+
+	bps, err := d.target.ClearBreakpoints(func(bp *proc.Breakpoint) bool {
+		return ids[bp.LogicalID]
+	})
+	r = append(r, api.ConvertBreakpoints(bps)...)
+	sort.Slice(r, func(i, j int) bool { return r[i].ID < r[j].ID })
+	if len(r) > 0 {
+		d.log.Infof("cleared %d breakpoints", len(r))
+	}
+	return r, err
+}
+
+// breakpointMatches returns true if bp is selected by filter.
+func breakpointMatches(bp *api.Breakpoint, filter api.BreakpointFilter) bool {
+	if len(filter.Addrs) > 0 {
+		found := false
+		for _, addr := range filter.Addrs {
+			for _, bpaddr := range bp.Addrs {
+				if addr == bpaddr {
+					found = true
+				}
+			}
+			if addr == bp.Addr {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if filter.File != "" {
+		file, bpfile := filepath.ToSlash(filter.File), filepath.ToSlash(bp.File)
+		if file != bpfile && !strings.HasSuffix(bpfile, "/"+file) {
+			return false
+		}
+	}
+	if filter.FunctionName != "" && filter.FunctionName != bp.FunctionName {
+		return false
+	}
+	return true
+}
+
+// Breakpoints returns the list of current breakpoints, including the
+// disabled ones, sorted by ID.
 func (d *Debugger) Breakpoints() []*api.Breakpoint {
+	return d.listBreakpoints(false)
+}
+
+// AllBreakpoints is like Breakpoints but also returns the breakpoints set
+// internally by Delve, for example by next and step, after the others,
+// sorted by address. They have ID 0 and can not be cleared.
+func (d *Debugger) AllBreakpoints() []*api.Breakpoint {
+	return d.listBreakpoints(true)
+}
+
+func (d *Debugger) listBreakpoints(all bool) []*api.Breakpoint {
 	d.targetMutex.Lock()
 	defer d.targetMutex.Unlock()
 
@@ -948,6 +1555,25 @@ func (d *Debugger) Breakpoints() []*api.Breakpoint {
 	for _, bp := range d.disabledBreakpoints {
 		bps = append(bps, bp)
 	}
+	sort.Slice(bps, func(i, j int) bool { return bps[i].ID < bps[j].ID })
+
+	if all {
+		internal := []*api.Breakpoint{}
+		for _, bp := range d.target.Breakpoints().M {
+			if bp.IsUser() {
+				continue
+			}
+			abp := api.ConvertBreakpoint(bp)
+			abp.ID = 0
+			abp.Name = ""
+			abp.HitCount = nil
+			abp.TotalHitCount = 0
+			abp.VerboseDescr = bp.VerboseDescr()
+			internal = append(internal, abp)
+		}
+		sort.Slice(internal, func(i, j int) bool { return internal[i].Addr < internal[j].Addr })
+		bps = append(bps, internal...)
+	}
 
 	return bps
 }
@@ -1032,7 +1658,9 @@ func (d *Debugger) findDisabledBreakpointByName(name string) *api.Breakpoint {
 }
 
 // CreateWatchpoint creates a watchpoint on the specified expression.
-func (d *Debugger) CreateWatchpoint(goid, frame, deferredCall int, expr string, wtype api.WatchType) (*api.Breakpoint, error) {
+// If history is greater than zero the watchpoint records its last history
+// hits, returned by WatchHistory, instead of stopping the target.
+func (d *Debugger) CreateWatchpoint(goid, frame, deferredCall int, expr string, wtype api.WatchType, history int) (*api.Breakpoint, error) {
 	s, err := proc.ConvertEvalScope(d.target, goid, frame, deferredCall)
 	if err != nil {
 		return nil, err
@@ -1041,14 +1669,322 @@ func (d *Debugger) CreateWatchpoint(goid, frame, deferredCall int, expr string,
 	if err != nil {
 		return nil, err
 	}
+	if history > 0 {
+		if err := d.target.SetWatchpointHistory(bp, history); err != nil {
+			d.target.ClearBreakpoint(bp.Addr)
+			return nil, err
+		}
+	}
 	if d.findBreakpointByName(expr) == nil {
 		bp.Name = expr
 	}
 	return api.ConvertBreakpoint(bp), nil
 }
 
-// Threads returns the threads of the target process.
-func (d *Debugger) Threads() ([]proc.Thread, error) {
+// MutexState decodes the state of the sync.Mutex or sync.RWMutex expr
+// evaluates to and lists the goroutines waiting on it.
+func (d *Debugger) MutexState(goid, frame, deferredCall int, expr string) (*api.MutexState, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	s, err := proc.ConvertEvalScope(d.target, goid, frame, deferredCall)
+	if err != nil {
+		return nil, err
+	}
+	v, err := s.EvalVariable(expr, proc.LoadConfig{})
+	if err != nil {
+		return nil, err
+	}
+	ms, err := d.target.MutexInfo(v)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &api.MutexState{
+		Addr:          ms.Addr,
+		RW:            ms.RW,
+		Locked:        ms.Locked,
+		Woken:         ms.Woken,
+		Starving:      ms.Starving,
+		Waiters:       ms.Waiters,
+		Readers:       ms.Readers,
+		WriterPending: ms.WriterPending,
+	}
+	for _, x := range []struct {
+		dst *[]*api.Goroutine
+		ids []int
+	}{
+		{&r.Queued, ms.Queued},
+		{&r.QueuedWriter, ms.QueuedWriter},
+		{&r.QueuedReaders, ms.QueuedReaders},
+		{&r.Holders, ms.Holders},
+	} {
+		if *x.dst, err = d.convertGoroutineIDs(x.ids); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// ChanState lists the elements buffered in the channel expr evaluates to
+// and the goroutines blocked on it.
+func (d *Debugger) ChanState(goid, frame, deferredCall int, expr string, cfg proc.LoadConfig) (*api.ChanState, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	s, err := proc.ConvertEvalScope(d.target, goid, frame, deferredCall)
+	if err != nil {
+		return nil, err
+	}
+	v, err := s.EvalVariable(expr, proc.LoadConfig{})
+	if err != nil {
+		return nil, err
+	}
+	cs, err := d.target.ChanInfo(v, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &api.ChanState{
+		Addr:   cs.Addr,
+		Len:    cs.Len,
+		Cap:    cs.Cap,
+		Closed: cs.Closed,
+		Buffer: make([]api.Variable, 0, len(cs.Buffer)),
+	}
+	for _, v := range cs.Buffer {
+		r.Buffer = append(r.Buffer, *api.ConvertVar(v))
+	}
+	if r.Senders, err = d.convertGoroutineIDs(cs.Senders); err != nil {
+		return nil, err
+	}
+	if r.Receivers, err = d.convertGoroutineIDs(cs.Receivers); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// AddWatchExpression adds expr to the expressions evaluated, with cfg,
+// in the selected goroutine every time the target stops. The new watch
+// expression is evaluated immediately.
+func (d *Debugger) AddWatchExpression(expr string, cfg proc.LoadConfig) (*api.WatchExpression, error) {
+	if _, err := evalexpr.Parse(expr); err != nil {
+		return nil, err
+	}
+
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	d.watchMutex.Lock()
+	defer d.watchMutex.Unlock()
+	d.watchExprCount++
+	w := &watchExpr{WatchExpression: api.WatchExpression{ID: d.watchExprCount, Expr: expr}, cfg: cfg}
+	d.evalWatchExpr(w)
+	w.OldValue = nil
+	w.Changed = false
+	d.watchExprs = append(d.watchExprs, w)
+	d.watchExprsChangedLocked()
+	r := w.WatchExpression
+	return &r, nil
+}
+
+// RemoveWatchExpression removes the watch expression with the given ID.
+func (d *Debugger) RemoveWatchExpression(id int) error {
+	d.watchMutex.Lock()
+	defer d.watchMutex.Unlock()
+	for i, w := range d.watchExprs {
+		if w.ID == id {
+			d.watchExprs = append(d.watchExprs[:i], d.watchExprs[i+1:]...)
+			d.watchExprsChangedLocked()
+			return nil
+		}
+	}
+	return fmt.Errorf("no watch expression with ID %d", id)
+}
+
+// WatchExpressions returns the watch expressions with their values at
+// the last stop, the sequence number of that list and a channel that is
+// closed when the list or the values change.
+func (d *Debugger) WatchExpressions() (int, []api.WatchExpression, <-chan struct{}) {
+	d.watchMutex.Lock()
+	defer d.watchMutex.Unlock()
+	r := make([]api.WatchExpression, len(d.watchExprs))
+	for i := range d.watchExprs {
+		r[i] = d.watchExprs[i].WatchExpression
+	}
+	return d.watchSeq, r, d.watchChanged
+}
+
+// SetCommandHooks replaces the hooks of the terminal command cmd, empty
+// hooks remove them. The debugger only stores the hooks for its clients,
+// it can not execute them because commands are implemented client side.
+func (d *Debugger) SetCommandHooks(cmd string, hooks api.CommandHooks) {
+	d.hooksMutex.Lock()
+	defer d.hooksMutex.Unlock()
+	if len(hooks.Pre) == 0 && len(hooks.Post) == 0 {
+		delete(d.commandHooks, cmd)
+		return
+	}
+	if d.commandHooks == nil {
+		d.commandHooks = make(map[string]api.CommandHooks)
+	}
+	d.commandHooks[cmd] = hooks
+}
+
+// CommandHooks returns the hooks of terminal commands, indexed by command
+// name.
+func (d *Debugger) CommandHooks() map[string]api.CommandHooks {
+	d.hooksMutex.Lock()
+	defer d.hooksMutex.Unlock()
+	r := make(map[string]api.CommandHooks, len(d.commandHooks))
+	for cmd, hooks := range d.commandHooks {
+		r[cmd] = hooks
+	}
+	return r
+}
+
+// evalWatchExpressions evaluates all watch expressions, it must be called
+// with targetMutex held every time the target stops.
+func (d *Debugger) evalWatchExpressions() {
+	d.watchMutex.Lock()
+	defer d.watchMutex.Unlock()
+	if len(d.watchExprs) == 0 {
+		return
+	}
+	for _, w := range d.watchExprs {
+		d.evalWatchExpr(w)
+	}
+	d.watchExprsChangedLocked()
+}
+
+// evalWatchExpr evaluates w in the selected goroutine, the previous value
+// of w becomes its old value.
+func (d *Debugger) evalWatchExpr(w *watchExpr) {
+	w.OldValue = w.Value
+	w.Value = nil
+	w.Err = ""
+	s, err := proc.ConvertEvalScope(d.target, -1, 0, 0)
+	if err == nil {
+		var v *proc.Variable
+		v, err = s.EvalVariable(w.Expr, w.cfg)
+		if err == nil {
+			w.Value = api.ConvertVar(v)
+		}
+	}
+	if err != nil {
+		w.Err = err.Error()
+	}
+	w.Changed = (w.Value == nil) != (w.OldValue == nil) || (w.Value != nil && w.Value.SinglelineString() != w.OldValue.SinglelineString())
+}
+
+func (d *Debugger) watchExprsChangedLocked() {
+	d.watchSeq++
+	close(d.watchChanged)
+	d.watchChanged = make(chan struct{})
+}
+
+// convertGoroutineIDs converts the goroutines with the given IDs, the
+// goroutines that no longer exist are skipped.
+func (d *Debugger) convertGoroutineIDs(ids []int) ([]*api.Goroutine, error) {
+	r := make([]*api.Goroutine, 0, len(ids))
+	for _, id := range ids {
+		g, err := proc.FindGoroutine(d.target, id)
+		if err != nil {
+			return nil, err
+		}
+		if g != nil {
+			r = append(r, api.ConvertGoroutine(d.target, g))
+		}
+	}
+	return r, nil
+}
+
+// chanWatchpointFunctions are the runtime functions that implement sending
+// to and receiving from a channel, their first argument is the channel.
+var chanWatchpointFunctions = []string{"runtime.chansend", "runtime.chanrecv"}
+
+// CreateChanWatchpoint creates a breakpoint that stops when any goroutine
+// sends to or receives from the channel expr evaluates to. The breakpoint
+// is set on the runtime functions implementing channel operations and is
+// conditioned on their channel argument.
+func (d *Debugger) CreateChanWatchpoint(goid, frame, deferredCall int, expr string) (*api.Breakpoint, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	s, err := proc.ConvertEvalScope(d.target, goid, frame, deferredCall)
+	if err != nil {
+		return nil, err
+	}
+	v, err := s.EvalVariable(expr, proc.LoadConfig{})
+	if err != nil {
+		return nil, err
+	}
+	if v.Kind != reflect.Chan {
+		return nil, fmt.Errorf("%s (type %s) is not a channel", expr, v.TypeString())
+	}
+	if v.Base == 0 {
+		return nil, fmt.Errorf("%s is a nil channel", expr)
+	}
+
+	var addrs []uint64
+	for _, fn := range chanWatchpointFunctions {
+		fnaddrs, err := proc.FindFunctionLocation(d.target, fn, 0)
+		if err != nil {
+			return nil, err
+		}
+		addrs = append(addrs, fnaddrs...)
+	}
+	requestedBp := &api.Breakpoint{Cond: fmt.Sprintf("uintptr(c) == %#x", v.Base)}
+	bp, err := createLogicalBreakpoint(d, addrs, requestedBp, 0)
+	if err != nil {
+		return nil, err
+	}
+	d.log.Infof("created channel watchpoint: %#v", bp)
+	return bp, nil
+}
+
+// WatchHistory returns the writes recorded by the watchpoint, or the hits
+// recorded by the breakpoint, with the given ID, created with a non-zero
+// history, oldest first.
+func (d *Debugger) WatchHistory(id int) ([]api.WatchHistoryEntry, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	bps := d.findBreakpoint(id)
+	if len(bps) == 0 {
+		return nil, fmt.Errorf("no breakpoint with id %d", id)
+	}
+	bp := bps[0]
+	if bp.Logical == nil || bp.Logical.History == nil {
+		return nil, fmt.Errorf("breakpoint %d does not record a history", id)
+	}
+	r := make([]api.WatchHistoryEntry, len(bp.Logical.History.Entries))
+	for i, e := range bp.Logical.History.Entries {
+		file, line, fn := d.target.BinInfo().PCToLine(e.PC)
+		stack, err := d.convertStacktrace(e.Stack, nil)
+		if err != nil {
+			return nil, err
+		}
+		r[i] = api.WatchHistoryEntry{
+			GoroutineID: e.GoroutineID,
+			PC:          e.PC,
+			File:        file,
+			Line:        line,
+			Function:    api.ConvertFunction(fn),
+			Stacktrace:  stack,
+		}
+		if e.Value != nil {
+			r[i].Value = *api.ConvertVar(e.Value)
+		}
+		for _, v := range e.Variables {
+			r[i].Variables = append(r[i].Variables, *api.ConvertVar(v))
+		}
+	}
+	return r, nil
+}
+
+// Threads returns the threads of the target process.
+func (d *Debugger) Threads() ([]proc.Thread, error) {
 	d.targetMutex.Lock()
 	defer d.targetMutex.Unlock()
 
@@ -1087,6 +2023,9 @@ func (d *Debugger) FindGoroutine(id int) (*proc.G, error) {
 func (d *Debugger) setRunning(running bool) {
 	d.runningMutex.Lock()
 	d.running = running
+	if running && d.target != nil {
+		d.runningBuildID = d.target.BinInfo().BuildID
+	}
 	d.runningMutex.Unlock()
 }
 
@@ -1100,16 +2039,31 @@ func (d *Debugger) IsRunning() bool {
 func (d *Debugger) Command(command *api.DebuggerCommand, resumeNotify chan struct{}) (*api.DebuggerState, error) {
 	var err error
 
-	if command.Name == api.Halt {
+	opts, err := command.DecodeOptions()
+	if err != nil {
+		if resumeNotify != nil {
+			close(resumeNotify)
+		}
+		return nil, err
+	}
+
+	if _, ishalt := opts.(*api.HaltOptions); ishalt {
 		// RequestManualStop does not invoke any ptrace syscalls, so it's safe to
 		// access the process directly.
 		d.log.Debug("halting")
 
 		d.recordMutex.Lock()
-		if d.stopRecording == nil {
+		if d.stopRecording == nil && d.target != nil {
 			err = d.target.RequestManualStop()
 		}
 		d.recordMutex.Unlock()
+
+		d.reattachMutex.Lock()
+		if d.reattachStop != nil {
+			close(d.reattachStop)
+			d.reattachStop = nil
+		}
+		d.reattachMutex.Unlock()
 	}
 
 	withBreakpointInfo := true
@@ -1117,27 +2071,64 @@ func (d *Debugger) Command(command *api.DebuggerCommand, resumeNotify chan struc
 	d.targetMutex.Lock()
 	defer d.targetMutex.Unlock()
 
+	if err := d.recordingError(); err != nil {
+		if resumeNotify != nil {
+			close(resumeNotify)
+		}
+		return nil, err
+	}
+
+	// The target is replaced on restart and reattach, set the option every
+	// time it is resumed.
+	d.target.StopOnFault = d.config.StopOnFault
+
 	d.setRunning(true)
 	defer d.setRunning(false)
 
-	if command.Name != api.SwitchGoroutine && command.Name != api.SwitchThread && command.Name != api.Halt {
+	switch opts.(type) {
+	case *api.SwitchGoroutineOptions, *api.SwitchThreadOptions, *api.HaltOptions:
+		if resumeNotify != nil {
+			close(resumeNotify)
+		}
+	default:
 		d.target.ResumeNotify(resumeNotify)
-	} else if resumeNotify != nil {
-		close(resumeNotify)
 	}
 
-	switch command.Name {
-	case api.Continue:
-		d.log.Debug("continuing")
-		if err := d.target.ChangeDirection(proc.Forward); err != nil {
+	continueIterations := 0
+	switch opts := opts.(type) {
+	case *api.ContinueOptions:
+		if opts.ReverseDirection {
+			d.log.Debug("rewinding")
+		} else {
+			d.log.Debug("continuing")
+		}
+		if err := d.changeDirection(opts.ReverseDirection); err != nil {
 			return nil, err
 		}
-		err = d.target.Continue()
-	case api.DirectionCongruentContinue:
+		cont := func() error {
+			if opts.Until != "" {
+				var err error
+				continueIterations, err = d.continueUntil(opts.Until, opts.MaxIterations)
+				return err
+			}
+			return d.target.Continue()
+		}
+		owner := os.Getuid()
+		if d.config.ReattachOnExit != "" {
+			// the target is gone once it exits
+			if uid, err := processOwner(d.target.Pid()); err == nil {
+				owner = uid
+			}
+		}
+		err = cont()
+		if _, exited := err.(proc.ErrProcessExited); exited && d.config.ReattachOnExit != "" && !opts.ReverseDirection {
+			err = d.continueReattaching(err, owner, cont)
+		}
+	case *api.DirectionCongruentContinueOptions:
 		d.log.Debug("continuing (direction congruent)")
 		err = d.target.Continue()
-	case api.Call:
-		d.log.Debugf("function call %s", command.Expr)
+	case *api.CallOptions:
+		d.log.Debugf("function call %s", opts.Expr)
 		if err := d.target.ChangeDirection(proc.Forward); err != nil {
 			return nil, err
 		}
@@ -1145,90 +2136,85 @@ func (d *Debugger) Command(command *api.DebuggerCommand, resumeNotify chan struc
 			return nil, errors.New("can not call function with nil ReturnInfoLoadConfig")
 		}
 		g := d.target.SelectedGoroutine()
-		if command.GoroutineID > 0 {
-			g, err = proc.FindGoroutine(d.target, command.GoroutineID)
+		if opts.GoroutineID > 0 {
+			g, err = proc.FindGoroutine(d.target, opts.GoroutineID)
 			if err != nil {
 				return nil, err
 			}
 		}
-		err = proc.EvalExpressionWithCalls(d.target, g, command.Expr, *api.LoadConfigToProc(command.ReturnInfoLoadConfig), !command.UnsafeCall)
-	case api.Rewind:
-		d.log.Debug("rewinding")
-		if err := d.target.ChangeDirection(proc.Backward); err != nil {
-			return nil, err
-		}
-		err = d.target.Continue()
-	case api.Next:
-		d.log.Debug("nexting")
-		if err := d.target.ChangeDirection(proc.Forward); err != nil {
-			return nil, err
-		}
-		err = d.target.Next()
-	case api.ReverseNext:
-		d.log.Debug("reverse nexting")
-		if err := d.target.ChangeDirection(proc.Backward); err != nil {
-			return nil, err
-		}
-		err = d.target.Next()
-	case api.Step:
-		d.log.Debug("stepping")
-		if err := d.target.ChangeDirection(proc.Forward); err != nil {
-			return nil, err
+		err = proc.EvalExpressionWithCalls(d.target, g, opts.Expr, *api.LoadConfigToProc(command.ReturnInfoLoadConfig), !opts.UnsafeCall)
+	case *api.NextOptions:
+		if opts.ReverseDirection {
+			d.log.Debug("reverse nexting")
+		} else {
+			d.log.Debug("nexting")
 		}
-		err = d.target.Step()
-	case api.ReverseStep:
-		d.log.Debug("reverse stepping")
-		if err := d.target.ChangeDirection(proc.Backward); err != nil {
+		if err := d.changeDirection(opts.ReverseDirection); err != nil {
 			return nil, err
 		}
-		err = d.target.Step()
-	case api.StepInstruction:
-		d.log.Debug("single stepping")
-		if err := d.target.ChangeDirection(proc.Forward); err != nil {
+		err = d.target.NextCount(opts.Count)
+	case *api.StepOptions:
+		if err := d.changeDirection(opts.ReverseDirection); err != nil {
 			return nil, err
 		}
-		err = d.target.StepInstruction()
-	case api.ReverseStepInstruction:
-		d.log.Debug("reverse single stepping")
-		if err := d.target.ChangeDirection(proc.Backward); err != nil {
-			return nil, err
+		switch opts.Granularity {
+		case api.StepInstructionGranularity:
+			if opts.ReverseDirection {
+				d.log.Debug("reverse single stepping")
+			} else {
+				d.log.Debug("single stepping")
+			}
+			err = d.target.StepInstruction()
+		default:
+			if opts.ReverseDirection {
+				d.log.Debug("reverse stepping")
+			} else {
+				d.log.Debug("stepping")
+			}
+			err = d.target.StepCount(opts.Count)
 		}
-		err = d.target.StepInstruction()
-	case api.StepOut:
-		d.log.Debug("step out")
-		if err := d.target.ChangeDirection(proc.Forward); err != nil {
-			return nil, err
+	case *api.StepOutOptions:
+		if opts.ReverseDirection {
+			d.log.Debug("reverse step out")
+		} else {
+			d.log.Debug("step out")
 		}
-		err = d.target.StepOut()
-	case api.ReverseStepOut:
-		d.log.Debug("reverse step out")
-		if err := d.target.ChangeDirection(proc.Backward); err != nil {
+		if err := d.changeDirection(opts.ReverseDirection); err != nil {
 			return nil, err
 		}
-		err = d.target.StepOut()
-	case api.SwitchThread:
-		d.log.Debugf("switching to thread %d", command.ThreadID)
-		err = d.target.SwitchThread(command.ThreadID)
+		err = d.target.StepOutCount(opts.Count)
+	case *api.SwitchThreadOptions:
+		d.log.Debugf("switching to thread %d", opts.ThreadID)
+		err = d.target.SwitchThread(opts.ThreadID)
 		withBreakpointInfo = false
-	case api.SwitchGoroutine:
-		d.log.Debugf("switching to goroutine %d", command.GoroutineID)
+	case *api.SwitchGoroutineOptions:
+		d.log.Debugf("switching to goroutine %d", opts.GoroutineID)
 		var g *proc.G
-		g, err = proc.FindGoroutine(d.target, command.GoroutineID)
+		g, err = proc.FindGoroutine(d.target, opts.GoroutineID)
 		if err == nil {
 			err = d.target.SwitchGoroutine(g)
 		}
 		withBreakpointInfo = false
-	case api.Halt:
+	case *api.HaltOptions:
 		// RequestManualStop already called
 		withBreakpointInfo = false
 	}
 
+	if withBreakpointInfo {
+		metrics.StopEvents.Inc()
+	}
+
 	if err != nil {
 		if pe, ok := err.(proc.ErrProcessExited); ok && command.Name != api.SwitchGoroutine && command.Name != api.SwitchThread {
 			state := &api.DebuggerState{}
 			state.Pid = d.target.Pid()
 			state.Exited = true
 			state.ExitStatus = pe.Status
+			state.StopReason.Kind = api.StopExited
+			if pe.Signal != 0 {
+				state.StopReason.Signal = signalName(pe.Signal)
+				state.StopReason.CoreDumped = pe.CoreDumped
+			}
 			state.Err = pe
 			return state, nil
 		}
@@ -1238,9 +2224,11 @@ func (d *Debugger) Command(command *api.DebuggerCommand, resumeNotify chan struc
 	if stateErr != nil {
 		return state, stateErr
 	}
+	d.evalWatchExpressions()
 	if withBreakpointInfo {
 		err = d.collectBreakpointInformation(state)
 	}
+	state.ContinueIterations = continueIterations
 	for _, th := range state.Threads {
 		if th.Breakpoint != nil && th.Breakpoint.TraceReturn {
 			for _, v := range th.BreakpointInfo.Arguments {
@@ -1253,6 +2241,77 @@ func (d *Debugger) Command(command *api.DebuggerCommand, resumeNotify chan struc
 	return state, err
 }
 
+// continueUntil resumes the target until cond, evaluated in the scope of
+// the selected goroutine, is true when the target stops at a breakpoint or
+// watchpoint. Any other stop, for example a manual stop, ends the loop.
+// Execution is resumed at most maxIterations times, the number of times it
+// was resumed is returned.
+func (d *Debugger) continueUntil(cond string, maxIterations int) (int, error) {
+	// Catch syntax errors, and conditions that are not boolean where they
+	// can already be evaluated, before resuming the target.
+	if _, err := evalexpr.Parse(cond); err != nil {
+		return 0, fmt.Errorf("error parsing %q: %v", cond, err)
+	}
+	if scope, err := proc.ConvertEvalScope(d.target, -1, 0, 0); err == nil {
+		if v, err := scope.EvalExpression(cond, proc.LoadConfig{}); err == nil && v.Unreadable == nil && v.Kind != reflect.Bool {
+			return 0, fmt.Errorf("%q is not a boolean expression", cond)
+		}
+	}
+	if maxIterations == 0 {
+		maxIterations = api.DefaultContinueUntilMaxIterations
+	}
+	defer func() {
+		d.runningMutex.Lock()
+		d.continueIterations = 0
+		d.runningMutex.Unlock()
+	}()
+	for i := 1; ; i++ {
+		d.runningMutex.Lock()
+		d.continueIterations = i
+		d.runningMutex.Unlock()
+
+		if err := d.target.Continue(); err != nil {
+			return i, err
+		}
+		switch d.target.StopReason {
+		case proc.StopBreakpoint, proc.StopWatchpoint, proc.StopTracepoint:
+			// evaluate the condition
+		default:
+			return i, nil
+		}
+		scope, err := proc.ConvertEvalScope(d.target, -1, 0, 0)
+		if err != nil {
+			return i, err
+		}
+		v, err := scope.EvalExpression(cond, proc.LoadConfig{})
+		if err != nil {
+			return i, fmt.Errorf("error evaluating %q: %v", cond, err)
+		}
+		if v.Unreadable != nil {
+			return i, fmt.Errorf("error evaluating %q: %v", cond, v.Unreadable)
+		}
+		if v.Kind != reflect.Bool {
+			return i, fmt.Errorf("%q is not a boolean expression", cond)
+		}
+		if constant.BoolVal(v.Value) {
+			return i, nil
+		}
+		if i >= maxIterations {
+			return i, fmt.Errorf("%q still false after resuming execution %d times", cond, i)
+		}
+		d.log.Debugf("continue until %q: condition false after %d iterations", cond, i)
+	}
+}
+
+// changeDirection sets the direction of execution of the target, backward
+// if reverse is true and forward otherwise.
+func (d *Debugger) changeDirection(reverse bool) error {
+	if reverse {
+		return d.target.ChangeDirection(proc.Backward)
+	}
+	return d.target.ChangeDirection(proc.Forward)
+}
+
 func (d *Debugger) collectBreakpointInformation(state *api.DebuggerState) error {
 	if state == nil {
 		return nil
@@ -1392,7 +2451,10 @@ func (d *Debugger) Types(filter string) ([]string, error) {
 
 // PackageVariables returns a list of package variables for the thread,
 // optionally regexp filtered using regexp described in 'filter'.
-func (d *Debugger) PackageVariables(filter string, cfg proc.LoadConfig) ([]*proc.Variable, error) {
+// If pkg is not empty only variables belonging to that package are
+// returned, the special value "." selects the package of the function
+// the current thread is stopped in.
+func (d *Debugger) PackageVariables(pkg, filter string, cfg proc.LoadConfig) ([]*proc.Variable, error) {
 	d.targetMutex.Lock()
 	defer d.targetMutex.Unlock()
 
@@ -1401,21 +2463,28 @@ func (d *Debugger) PackageVariables(filter string, cfg proc.LoadConfig) ([]*proc
 		return nil, fmt.Errorf("invalid filter argument: %s", err.Error())
 	}
 
-	scope, err := proc.ThreadScope(d.target, d.target.CurrentThread())
-	if err != nil {
-		return nil, err
+	if pkg == "." {
+		loc, err := d.target.CurrentThread().Location()
+		if err != nil {
+			return nil, err
+		}
+		if loc.Fn == nil {
+			return nil, fmt.Errorf("unable to determine current package due to unspecified function location")
+		}
+		pkg = loc.Fn.PackageName()
 	}
-	pv, err := scope.PackageVariables(cfg)
+
+	scope, err := proc.ThreadScope(d.target, d.target.CurrentThread())
 	if err != nil {
 		return nil, err
 	}
-	pvr := pv[:0]
-	for i := range pv {
-		if regex.Match([]byte(pv[i].Name)) {
-			pvr = append(pvr, pv[i])
+	defer d.startOperation()()
+	return scope.PackageVariables(func(name string) bool {
+		if pkg != "" && !strings.HasPrefix(name, pkg+".") {
+			return false
 		}
-	}
-	return pvr, nil
+		return regex.MatchString(name)
+	}, cfg)
 }
 
 // ThreadRegisters returns registers of the specified thread.
@@ -1423,9 +2492,9 @@ func (d *Debugger) ThreadRegisters(threadID int, floatingPoint bool) (*op.DwarfR
 	d.targetMutex.Lock()
 	defer d.targetMutex.Unlock()
 
-	thread, found := d.target.FindThread(threadID)
-	if !found {
-		return nil, fmt.Errorf("couldn't find thread %d", threadID)
+	thread, err := proc.FindThread(d.target, threadID)
+	if err != nil {
+		return nil, err
 	}
 	regs, err := thread.Registers()
 	if err != nil {
@@ -1489,9 +2558,13 @@ func (d *Debugger) Function(goid, frame, deferredCall int, cfg proc.LoadConfig)
 
 // EvalVariableInScope will attempt to evaluate the variable represented by 'symbol'
 // in the scope provided.
+// The evaluation can be interrupted by CancelRequest.
+// Function calls are not supported, evaluating one would resume the
+// target: use Command with api.Call instead.
 func (d *Debugger) EvalVariableInScope(goid, frame, deferredCall int, symbol string, cfg proc.LoadConfig) (*proc.Variable, error) {
 	d.targetMutex.Lock()
 	defer d.targetMutex.Unlock()
+	defer d.startOperation()()
 
 	s, err := proc.ConvertEvalScope(d.target, goid, frame, deferredCall)
 	if err != nil {
@@ -1510,6 +2583,8 @@ func (d *Debugger) LoadResliced(v *proc.Variable, start int, cfg proc.LoadConfig
 
 // SetVariableInScope will set the value of the variable represented by
 // 'symbol' to the value given, in the given scope.
+// In the topmost frame of a goroutine running on a thread string literals
+// can also be assigned, which resumes the target to allocate them.
 func (d *Debugger) SetVariableInScope(goid, frame, deferredCall int, symbol, value string) error {
 	d.targetMutex.Lock()
 	defer d.targetMutex.Unlock()
@@ -1518,16 +2593,61 @@ func (d *Debugger) SetVariableInScope(goid, frame, deferredCall int, symbol, val
 	if err != nil {
 		return err
 	}
-	return s.SetVariable(symbol, value)
+	err = s.SetVariable(symbol, value)
+	if err != proc.ErrFuncCallNotAllowedStrAlloc || frame != 0 || deferredCall != 0 {
+		return err
+	}
+	g, gerr := proc.FindGoroutine(d.target, goid)
+	if gerr != nil || g == nil || g.Thread == nil {
+		return err
+	}
+	d.setRunning(true)
+	defer d.setRunning(false)
+	err = proc.SetVariable(d.target, g.Thread, symbol, value)
+	// the target was resumed to allocate the string
+	d.evalWatchExpressions()
+	return err
 }
 
 // Goroutines will return a list of goroutines in the target process.
 func (d *Debugger) Goroutines(start, count int) ([]*proc.G, int, error) {
 	d.targetMutex.Lock()
 	defer d.targetMutex.Unlock()
+	defer d.startOperation()()
 	return proc.GoroutinesInfo(d.target, start, count)
 }
 
+// startOperation marks the beginning of a long running operation that
+// can be interrupted by CancelRequest, the returned function must be
+// called when the operation is finished.
+// Must be called with targetMutex held.
+func (d *Debugger) startOperation() func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	d.opCancelMutex.Lock()
+	d.opCancel = cancel
+	d.opCancelMutex.Unlock()
+	d.target.SetOperationContext(ctx)
+	return func() {
+		d.target.SetOperationContext(nil)
+		d.opCancelMutex.Lock()
+		d.opCancel = nil
+		d.opCancelMutex.Unlock()
+		cancel()
+	}
+}
+
+// CancelRequest interrupts the long running operation currently in
+// progress (for example enumerating goroutines or loading the variables
+// of a stacktrace), if any. The interrupted operation will return
+// proc.ErrOperationCanceled.
+func (d *Debugger) CancelRequest() {
+	d.opCancelMutex.Lock()
+	defer d.opCancelMutex.Unlock()
+	if d.opCancel != nil {
+		d.opCancel()
+	}
+}
+
 // FilterGoroutines returns the goroutines in gs that satisfy the specified filters.
 func (d *Debugger) FilterGoroutines(gs []*proc.G, filters []api.ListGoroutinesFilter) []*proc.G {
 	if len(filters) == 0 {
@@ -1577,6 +2697,8 @@ func matchGoroutineFilter(tgt *proc.Target, g *proc.G, filter *api.ListGoroutine
 		val = g.Thread != nil
 	case api.GoroutineUser:
 		val = !g.System(tgt)
+	case api.GoroutineRuntime:
+		val = proc.RuntimeGoroutine(tgt, g)
 	}
 	if filter.Negated {
 		val = !val
@@ -1626,6 +2748,8 @@ func (d *Debugger) GroupGoroutines(gs []*proc.G, group *api.GoroutineGroupingOpt
 			key = fmt.Sprintf("running=%v", g.Thread != nil)
 		case api.GoroutineUser:
 			key = fmt.Sprintf("user=%v", !g.System(d.target))
+		case api.GoroutineRuntime:
+			key = fmt.Sprintf("runtime=%v", proc.RuntimeGoroutine(d.target, g))
 		}
 		if len(groupMembers[key]) < group.MaxGroupMembers {
 			groupMembers[key] = append(groupMembers[key], g)
@@ -1656,6 +2780,8 @@ func (d *Debugger) GroupGoroutines(gs []*proc.G, group *api.GoroutineGroupingOpt
 // Stacktrace returns a list of Stackframes for the given goroutine. The
 // length of the returned list will be min(stack_len, depth).
 // If 'full' is true, then local vars, function args, etc will be returned as well.
+// The stacktrace of a goroutine is cached until the target resumes, see
+// proc.CachedStacktrace.
 func (d *Debugger) Stacktrace(goroutineID, depth int, opts api.StacktraceOptions) ([]proc.Stackframe, error) {
 	d.targetMutex.Lock()
 	defer d.targetMutex.Unlock()
@@ -1672,70 +2798,375 @@ func (d *Debugger) Stacktrace(goroutineID, depth int, opts api.StacktraceOptions
 	if g == nil {
 		return proc.ThreadStacktrace(d.target.CurrentThread(), depth)
 	} else {
-		return g.Stacktrace(depth, proc.StacktraceOptions(opts))
+		return proc.CachedStacktrace(d.target, g, depth, proc.StacktraceOptions(opts))
 	}
 }
 
-// Ancestors returns the stacktraces for the ancestors of a goroutine.
-func (d *Debugger) Ancestors(goroutineID, numAncestors, depth int) ([]api.Ancestor, error) {
+// GoroutinesStacktraces returns the stacktraces of all goroutines, up to
+// depth frames each, unwinding them concurrently.
+func (d *Debugger) GoroutinesStacktraces(depth int, opts api.StacktraceOptions) ([]api.GoroutineStacktrace, error) {
 	d.targetMutex.Lock()
 	defer d.targetMutex.Unlock()
+	defer d.startOperation()()
 
 	if _, err := d.target.Valid(); err != nil {
 		return nil, err
 	}
 
-	g, err := proc.FindGoroutine(d.target, goroutineID)
+	stacks, err := proc.GoroutinesStacktraces(d.target, depth, proc.StacktraceOptions(opts))
 	if err != nil {
 		return nil, err
 	}
-	if g == nil {
-		return nil, errors.New("no selected goroutine")
+	r := make([]api.GoroutineStacktrace, len(stacks))
+	for i := range stacks {
+		r[i].GoroutineID = stacks[i].G.ID
+		if stacks[i].Err != nil {
+			r[i].Err = stacks[i].Err.Error()
+			continue
+		}
+		r[i].Locations, err = d.convertStacktrace(stacks[i].Frames, nil)
+		if err != nil {
+			return nil, err
+		}
 	}
+	return r, nil
+}
 
-	ancestors, err := proc.Ancestors(d.target, g, numAncestors)
-	if err != nil {
+// ThreadsStacktraces returns the stacktraces of all threads, sorted by
+// thread ID, up to depth frames each.
+func (d *Debugger) ThreadsStacktraces(depth int) ([]api.ThreadStacktrace, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	if _, err := d.target.Valid(); err != nil {
 		return nil, err
 	}
 
-	r := make([]api.Ancestor, len(ancestors))
-	for i := range ancestors {
-		r[i].ID = ancestors[i].ID
-		if ancestors[i].Unreadable != nil {
-			r[i].Unreadable = ancestors[i].Unreadable.Error()
-			continue
-		}
-		frames, err := ancestors[i].Stack(depth)
+	threads := d.target.ThreadList()
+	r := make([]api.ThreadStacktrace, len(threads))
+	for i, th := range threads {
+		r[i].ThreadID = th.ThreadID()
+		frames, err := proc.ThreadStacktrace(th, depth)
 		if err != nil {
-			r[i].Unreadable = fmt.Sprintf("could not read ancestor stacktrace: %v", err)
+			r[i].Err = err.Error()
 			continue
 		}
-		r[i].Stack, err = d.convertStacktrace(frames, nil)
+		r[i].Locations, err = d.convertStacktrace(frames, nil)
 		if err != nil {
-			r[i].Unreadable = fmt.Sprintf("could not read ancestor stacktrace: %v", err)
+			return nil, err
 		}
 	}
+	sort.Slice(r, func(i, j int) bool { return r[i].ThreadID < r[j].ThreadID })
 	return r, nil
 }
 
-// ConvertStacktrace converts a slice of proc.Stackframe into a slice of
-// api.Stackframe, loading local variables and arguments of each frame if
-// cfg is not nil.
-func (d *Debugger) ConvertStacktrace(rawlocs []proc.Stackframe, cfg *proc.LoadConfig) ([]api.Stackframe, error) {
+// ThreadStacktrace returns a list of Stackframes for the thread threadID,
+// or the current thread if threadID is 0, up to depth frames.
+func (d *Debugger) ThreadStacktrace(threadID, depth int) ([]proc.Stackframe, error) {
 	d.targetMutex.Lock()
 	defer d.targetMutex.Unlock()
-	return d.convertStacktrace(rawlocs, cfg)
-}
 
-func (d *Debugger) convertStacktrace(rawlocs []proc.Stackframe, cfg *proc.LoadConfig) ([]api.Stackframe, error) {
-	locations := make([]api.Stackframe, 0, len(rawlocs))
-	for i := range rawlocs {
-		frame := api.Stackframe{
-			Location: api.ConvertLocation(rawlocs[i].Call),
+	if _, err := d.target.Valid(); err != nil {
+		return nil, err
+	}
+
+	thread, err := proc.FindThread(d.target, threadID)
+	if err != nil {
+		return nil, err
+	}
+	return proc.ThreadStacktrace(thread, depth)
+}
+
+// SearchMemory searches pattern in the memory of the target between start
+// and end, or in all of its readable memory if end is zero, and returns
+// at most max occurrences.
+func (d *Debugger) SearchMemory(pattern []byte, start, end uint64, max int) ([]api.MemorySearchResult, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+	defer d.startOperation()()
+
+	if _, err := d.target.Valid(); err != nil {
+		return nil, err
+	}
+	results, err := d.target.SearchMemory(pattern, start, end, max)
+	if err != nil {
+		return nil, err
+	}
+	r := make([]api.MemorySearchResult, len(results))
+	for i := range results {
+		r[i] = api.MemorySearchResult{Addr: results[i].Addr, Region: api.ConvertMemoryRegion(results[i].Mapping)}
+	}
+	return r, nil
+}
+
+// MemoryRegions returns the memory map of the target, annotated with the
+// regions belonging to the executable, to the Go heap and to goroutine
+// stacks.
+func (d *Debugger) MemoryRegions() ([]api.MemoryRegion, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	if _, err := d.target.Valid(); err != nil {
+		return nil, err
+	}
+	regions, err := d.target.MemoryRegions()
+	if err != nil {
+		return nil, err
+	}
+	r := make([]api.MemoryRegion, len(regions))
+	for i := range regions {
+		r[i] = *api.ConvertAnnotatedMemoryRegion(&regions[i])
+	}
+	return r, nil
+}
+
+// environLoadConfig is used to load the environment of the target.
+var environLoadConfig = proc.LoadConfig{MaxStringLen: 1 << 20, MaxArrayValues: 1 << 16}
+
+// Environ returns the environment of the target, as returned by
+// os.Environ, reading it from the variable used by the syscall package
+// or, if the syscall package is not linked in, from the copy made by the
+// runtime at startup.
+func (d *Debugger) Environ() ([]string, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	s, err := proc.ConvertEvalScope(d.target, -1, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	v, err := s.EvalVariable("syscall.envs", environLoadConfig)
+	if err != nil {
+		v, err = s.EvalVariable("runtime.envs", environLoadConfig)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if v.Unreadable != nil {
+		return nil, v.Unreadable
+	}
+	r := make([]string, 0, len(v.Children))
+	for i := range v.Children {
+		if v.Children[i].Unreadable != nil {
+			return nil, v.Children[i].Unreadable
+		}
+		// syscall.Unsetenv leaves empty entries in envs, os.Environ skips them
+		if kv := constant.StringVal(v.Children[i].Value); kv != "" {
+			r = append(r, kv)
+		}
+	}
+	return r, nil
+}
+
+// SetEnv sets the environment variable key of the target to value by
+// calling os.Setenv on the selected goroutine, or os.Unsetenv if unset is
+// true.
+func (d *Debugger) SetEnv(key, value string, unset bool) error {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	d.setRunning(true)
+	defer d.setRunning(false)
+
+	g := d.target.SelectedGoroutine()
+	if g == nil {
+		return errors.New("no selected goroutine")
+	}
+	expr := fmt.Sprintf("os.Setenv(%q, %q)", key, value)
+	if unset {
+		expr = fmt.Sprintf("os.Unsetenv(%q)", key)
+	}
+	return d.callFunctionCheckError(g.ID, expr)
+}
+
+// TargetInfo returns the executable, command line, working directory,
+// start time and Go version of the target.
+// The command line is read from the memory of the target, falling back to
+// the arguments used to launch it.
+func (d *Debugger) TargetInfo() (*api.TargetInfo, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	if _, err := d.target.Valid(); err != nil {
+		return nil, err
+	}
+
+	bi := d.target.BinInfo()
+	r := &api.TargetInfo{Pid: d.target.Pid()}
+	if len(bi.Images) > 0 {
+		r.Path = bi.Images[0].Path
+	}
+
+	if s, err := proc.ConvertEvalScope(d.target, -1, 0, 0); err == nil {
+		if v, err := s.EvalVariable("runtime.argslice", environLoadConfig); err == nil && v.Unreadable == nil {
+			for i := range v.Children {
+				if v.Children[i].Unreadable != nil {
+					r.Args = nil
+					break
+				}
+				r.Args = append(r.Args, constant.StringVal(v.Children[i].Value))
+			}
+		}
+	}
+	if len(r.Args) == 0 {
+		r.Args = d.processArgs
+	}
+
+	if producer := bi.Producer(); producer != "" {
+		ver := strings.TrimPrefix(producer, "Go cmd/compile ")
+		if i := strings.Index(ver, ";"); i >= 0 {
+			ver = ver[:i]
+		}
+		r.GoVersion = strings.TrimSpace(ver)
+	}
+
+	if recorded, _ := d.target.Recorded(); recorded || d.config.CoreFile != "" {
+		return r, nil
+	}
+	if wd, err := processWorkingDir(r.Pid); err == nil {
+		r.WorkingDir = wd
+	} else if d.config.AttachPid == 0 {
+		r.WorkingDir = d.config.WorkingDir
+		if r.WorkingDir == "" {
+			// launched processes inherit our working directory
+			r.WorkingDir, _ = os.Getwd()
+		}
+	}
+	if id, err := readProcessIdentity(r.Pid); err == nil {
+		r.StartTime = id.startedAt
+	}
+	return r, nil
+}
+
+// BuildInfo returns the build ID and the build information embedded in
+// the executable of the target.
+func (d *Debugger) BuildInfo() (*api.BuildInfo, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	bi, err := d.target.BinInfo().BuildInfo()
+	if err != nil {
+		return nil, err
+	}
+	r := api.ConvertBuildInfo(bi)
+	r.BuildID = d.target.BinInfo().BuildID
+	return r, nil
+}
+
+// FileDescriptors returns the open file descriptors of the target process,
+// each with the goroutines blocked waiting for it to become ready.
+func (d *Debugger) FileDescriptors() ([]api.FileDescriptor, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	if _, err := d.target.Valid(); err != nil {
+		return nil, err
+	}
+	if recorded, _ := d.target.Recorded(); recorded || d.config.CoreFile != "" {
+		return nil, errors.New("can not list the file descriptors of a core file or recording")
+	}
+	fds, err := fileDescriptors(d.target.Pid())
+	if err != nil {
+		return nil, err
+	}
+	waiters, err := proc.NetpollWaiters(d.target)
+	if err != nil {
+		return nil, err
+	}
+	for i := range fds {
+		fds[i].Goroutines = waiters[fds[i].Num]
+	}
+	return fds, nil
+}
+
+// Timers returns the pending timers of the target, sorted by the time at
+// which they fire.
+func (d *Debugger) Timers() ([]api.Timer, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	timers, err := proc.Timers(d.target)
+	if err != nil {
+		return nil, err
+	}
+	r := make([]api.Timer, len(timers))
+	for i, t := range timers {
+		r[i] = api.Timer{
+			Addr:   t.Addr,
+			When:   t.When,
+			Period: time.Duration(t.Period),
+			Func:   api.ConvertVar(t.Func).Value,
+			Arg:    *api.ConvertVar(t.Arg),
+		}
+	}
+	return r, nil
+}
+
+// Ancestors returns the stacktraces for the ancestors of a goroutine.
+func (d *Debugger) Ancestors(goroutineID, numAncestors, depth int) ([]api.Ancestor, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	if _, err := d.target.Valid(); err != nil {
+		return nil, err
+	}
+
+	g, err := proc.FindGoroutine(d.target, goroutineID)
+	if err != nil {
+		return nil, err
+	}
+	if g == nil {
+		return nil, errors.New("no selected goroutine")
+	}
+
+	ancestors, err := proc.Ancestors(d.target, g, numAncestors)
+	if err != nil {
+		return nil, err
+	}
+
+	r := make([]api.Ancestor, len(ancestors))
+	for i := range ancestors {
+		r[i].ID = ancestors[i].ID
+		r[i].GoStatementLoc = api.ConvertLocation(ancestors[i].Go())
+		if ancestors[i].Unreadable != nil {
+			r[i].Unreadable = ancestors[i].Unreadable.Error()
+			continue
+		}
+		frames, err := ancestors[i].Stack(depth)
+		if err != nil {
+			r[i].Unreadable = fmt.Sprintf("could not read ancestor stacktrace: %v", err)
+			continue
+		}
+		r[i].Stack, err = d.convertStacktrace(frames, nil)
+		if err != nil {
+			r[i].Unreadable = fmt.Sprintf("could not read ancestor stacktrace: %v", err)
+		}
+	}
+	return r, nil
+}
+
+// ConvertStacktrace converts a slice of proc.Stackframe into a slice of
+// api.Stackframe, loading local variables and arguments of each frame if
+// cfg is not nil.
+func (d *Debugger) ConvertStacktrace(rawlocs []proc.Stackframe, cfg *proc.LoadConfig) ([]api.Stackframe, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+	defer d.startOperation()()
+	return d.convertStacktrace(rawlocs, cfg)
+}
+
+func (d *Debugger) convertStacktrace(rawlocs []proc.Stackframe, cfg *proc.LoadConfig) ([]api.Stackframe, error) {
+	locations := make([]api.Stackframe, 0, len(rawlocs))
+	for i := range rawlocs {
+		frame := api.Stackframe{
+			Location: api.ConvertLocation(rawlocs[i].Call),
 
 			FrameOffset:        rawlocs[i].FrameOffset(),
 			FramePointerOffset: rawlocs[i].FramePointerOffset(),
 
+			CFA:          uint64(rawlocs[i].Regs.CFA),
+			FramePointer: rawlocs[i].Regs.BP(),
+
 			Defers: d.convertDefers(rawlocs[i].Defers),
 
 			Bottom: rawlocs[i].Bottom,
@@ -1744,7 +3175,9 @@ func (d *Debugger) convertStacktrace(rawlocs []proc.Stackframe, cfg *proc.LoadCo
 			frame.Err = rawlocs[i].Err.Error()
 		}
 		if cfg != nil && rawlocs[i].Current.Fn != nil {
-			var err error
+			if err := d.target.OperationCanceled(); err != nil {
+				return nil, err
+			}
 			scope := proc.FrameToScope(d.target, d.target.BinInfo(), d.target.Memory(), nil, rawlocs[i:]...)
 			locals, err := scope.LocalVariables(*cfg)
 			if err != nil {
@@ -1947,6 +3380,97 @@ func (d *Debugger) ClearCheckpoint(id int) error {
 	return d.target.ClearCheckpoint(id)
 }
 
+// FreezeGoroutine prevents the goroutine with the given ID from running
+// when the target is resumed.
+func (d *Debugger) FreezeGoroutine(goid int) error {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+	return d.target.FreezeGoroutine(goid)
+}
+
+// ThawGoroutine lets a goroutine frozen by FreezeGoroutine run again.
+func (d *Debugger) ThawGoroutine(goid int) error {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+	return d.target.ThawGoroutine(goid)
+}
+
+// StartCoverage starts recording which lines of the source files matching
+// the regular expression filter are executed. If filter is empty the files
+// of the standard library, of the module cache and of vendor directories
+// are excluded. Returns the number of instrumented lines.
+func (d *Debugger) StartCoverage(filter string) (int, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	var match func(string) bool
+	if filter != "" {
+		re, err := regexp.Compile(filter)
+		if err != nil {
+			return 0, fmt.Errorf("invalid filter argument: %s", err.Error())
+		}
+		match = re.MatchString
+	} else {
+		goroot := ""
+		if fn := d.target.BinInfo().LookupFunc["runtime.main"]; fn != nil {
+			file, _, _ := d.target.BinInfo().PCToLine(fn.Entry)
+			goroot = strings.TrimSuffix(file, "runtime/proc.go")
+		}
+		match = func(file string) bool {
+			if goroot != "" && strings.HasPrefix(file, goroot) {
+				return false
+			}
+			file = filepath.ToSlash(file)
+			return !strings.Contains(file, "/pkg/mod/") && !strings.Contains(file, "/vendor/")
+		}
+	}
+	return d.target.StartCoverage(match)
+}
+
+// StopCoverage stops recording executed lines.
+func (d *Debugger) StopCoverage() error {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+	return d.target.StopCoverage()
+}
+
+// Coverage returns the lines instrumented by StartCoverage and whether
+// they were executed.
+func (d *Debugger) Coverage() ([]proc.CoverageLine, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+	return d.target.Coverage()
+}
+
+// StartBranchTrace starts recording the control flow of the target's
+// threads with the hardware branch tracer.
+func (d *Debugger) StartBranchTrace() error {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+	return d.target.StartBranchTrace()
+}
+
+// BranchTrace returns the calls, returns and jumps between functions that
+// led thread threadID, or the current thread if threadID is 0, to its
+// current position.
+func (d *Debugger) BranchTrace(threadID int) ([]api.BranchTraceEvent, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+	if threadID == 0 {
+		threadID = d.target.CurrentThread().ThreadID()
+	}
+	evs, err := d.target.BranchTrace(threadID)
+	if err != nil {
+		return nil, err
+	}
+	bi := d.target.BinInfo()
+	r := make([]api.BranchTraceEvent, len(evs))
+	for i := range evs {
+		r[i] = api.ConvertBranchTraceEvent(bi, evs[i])
+	}
+	return r, nil
+}
+
 // ListDynamicLibraries returns a list of loaded dynamic libraries.
 func (d *Debugger) ListDynamicLibraries() []*proc.Image {
 	d.targetMutex.Lock()
@@ -1955,6 +3479,13 @@ func (d *Debugger) ListDynamicLibraries() []*proc.Image {
 
 }
 
+// ExecutableImage returns the image of the executable file of the target.
+func (d *Debugger) ExecutableImage() *proc.Image {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+	return d.target.BinInfo().Images[0]
+}
+
 // ExamineMemory returns the raw memory stored at the given address.
 // The amount of data to be read is specified by length.
 // This function will return an error if it reads less than `length` bytes.
@@ -1995,6 +3526,13 @@ func (d *Debugger) GetVersion(out *api.GetVersionOut) error {
 
 	if !d.isRecording() && !d.IsRunning() {
 		out.TargetGoVersion = d.target.BinInfo().Producer()
+		out.TargetBuildID = d.target.BinInfo().BuildID
+	} else if !d.isRecording() {
+		// the target can not be accessed while it runs, its executable
+		// does not change
+		d.runningMutex.Lock()
+		out.TargetBuildID = d.runningBuildID
+		d.runningMutex.Unlock()
 	}
 
 	out.MinSupportedVersionOfGo = fmt.Sprintf("%d.%d.0", goversion.MinSupportedVersionOfGoMajor, goversion.MinSupportedVersionOfGoMinor)
@@ -2143,3 +3681,300 @@ func (v breakpointsByLogicalID) Less(i, j int) bool {
 	}
 	return v[i].LogicalID < v[j].LogicalID
 }
+
+// StartRuntimeTrace starts the execution tracer of the target's runtime,
+// the trace is written by the target to path (relative to its working
+// directory) until StopRuntimeTrace is called, and can be inspected with
+// 'go tool trace'.
+// The trace is started by calling runtime/trace.Start on the selected
+// goroutine, the target must import runtime/trace and use os.Create for
+// the functions to be available.
+func (d *Debugger) StartRuntimeTrace(path string) error {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	if d.runtimeTrace != nil {
+		return fmt.Errorf("runtime execution trace already being written to %s", d.runtimeTrace.path)
+	}
+
+	d.setRunning(true)
+	defer d.setRunning(false)
+
+	g := d.target.SelectedGoroutine()
+	if g == nil {
+		return errors.New("no selected goroutine")
+	}
+	goid := g.ID
+
+	file, err := d.createFile(goid, path)
+	if err != nil {
+		return err
+	}
+	if err := d.callFunctionCheckError(goid, fmt.Sprintf("\"runtime/trace\".Start((*os.File)(%#x))", file)); err != nil {
+		d.closeFile(goid, file, path)
+		return fmt.Errorf("could not start runtime execution trace: %v", err)
+	}
+
+	d.runtimeTrace = &runtimeTrace{path: path, file: file}
+	return nil
+}
+
+// StopRuntimeTrace stops the execution trace started by StartRuntimeTrace
+// and returns the path of the trace file.
+func (d *Debugger) StopRuntimeTrace() (string, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	if d.runtimeTrace == nil {
+		return "", errors.New("runtime execution trace not started")
+	}
+
+	d.setRunning(true)
+	defer d.setRunning(false)
+
+	g := d.target.SelectedGoroutine()
+	if g == nil {
+		return "", errors.New("no selected goroutine")
+	}
+
+	// runtime/trace.Stop returns after the goroutine started by
+	// runtime/trace.Start has written the whole trace.
+	if _, err := d.callFunction(g.ID, "\"runtime/trace\".Stop()", 0); err != nil {
+		return "", fmt.Errorf("could not stop runtime execution trace: %v", err)
+	}
+	path := d.runtimeTrace.path
+	err := d.closeFile(g.ID, d.runtimeTrace.file, path)
+	d.runtimeTrace = nil
+	return path, err
+}
+
+// Profile collects a profile of the target using runtime/pprof, the
+// profile is written by the target to path (relative to its working
+// directory).
+// For CPU profiles the target is resumed and stopped again after duration,
+// or earlier if it stops for another reason.
+// The target must import runtime/pprof and use os.Create for the functions
+// to be available.
+func (d *Debugger) Profile(kind api.ProfileKind, duration time.Duration, path string) (*api.DebuggerState, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	if kind != api.CPUProfile && kind != api.HeapProfile {
+		return nil, fmt.Errorf("unknown profile %q", kind)
+	}
+
+	d.setRunning(true)
+	defer d.setRunning(false)
+
+	g := d.target.SelectedGoroutine()
+	if g == nil {
+		return nil, errors.New("no selected goroutine")
+	}
+
+	file, err := d.createFile(g.ID, path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch kind {
+	case api.CPUProfile:
+		if err := d.callFunctionCheckError(g.ID, fmt.Sprintf("\"runtime/pprof\".StartCPUProfile((*os.File)(%#x))", file)); err != nil {
+			d.closeFile(g.ID, file, path)
+			return nil, fmt.Errorf("could not start CPU profile: %v", err)
+		}
+		profiling := true
+		defer func() {
+			if !profiling {
+				return
+			}
+			// Collecting the profile failed, it must not keep running.
+			if g := d.target.SelectedGoroutine(); g != nil {
+				d.callFunction(g.ID, "\"runtime/pprof\".StopCPUProfile()", 0)
+				d.closeFile(g.ID, file, path)
+			}
+		}()
+		d.log.Debugf("collecting CPU profile for %v", duration)
+		timer := time.AfterFunc(duration, func() { d.target.RequestManualStop() })
+		err = d.target.Continue()
+		timer.Stop()
+		if err != nil {
+			return nil, err
+		}
+		// The profile is stopped on whichever goroutine is selected now.
+		g = d.target.SelectedGoroutine()
+		if g == nil {
+			return nil, errors.New("could not stop CPU profile: no selected goroutine")
+		}
+		profiling = false
+		if _, err := d.callFunction(g.ID, "\"runtime/pprof\".StopCPUProfile()", 0); err != nil {
+			d.closeFile(g.ID, file, path)
+			return nil, fmt.Errorf("could not stop CPU profile: %v", err)
+		}
+	case api.HeapProfile:
+		if err := d.callFunctionCheckError(g.ID, fmt.Sprintf("\"runtime/pprof\".WriteHeapProfile((*os.File)(%#x))", file)); err != nil {
+			d.closeFile(g.ID, file, path)
+			return nil, fmt.Errorf("could not write heap profile: %v", err)
+		}
+	}
+
+	if err := d.closeFile(g.ID, file, path); err != nil {
+		return nil, err
+	}
+	return d.state(nil)
+}
+
+// createFile creates path calling os.Create on goroutine goid, it returns
+// the address of the *os.File.
+func (d *Debugger) createFile(goid int, path string) (uint64, error) {
+	rv, err := d.callFunction(goid, fmt.Sprintf("os.Create(%q)", path), 2)
+	if err != nil {
+		return 0, err
+	}
+	if err := callError(rv[1]); err != nil {
+		return 0, fmt.Errorf("could not create %s: %v", path, err)
+	}
+	if rv[0].Kind != reflect.Ptr || len(rv[0].Children) != 1 {
+		return 0, errors.New("unexpected return value of os.Create")
+	}
+	return rv[0].Children[0].Addr, nil
+}
+
+// closeFile closes the file created by createFile.
+func (d *Debugger) closeFile(goid int, file uint64, path string) error {
+	if err := d.callFunctionCheckError(goid, fmt.Sprintf("(*os.File)(%#x).Close()", file)); err != nil {
+		return fmt.Errorf("could not close %s: %v", path, err)
+	}
+	return nil
+}
+
+// callLoadConfig is used to load the return values of callFunction.
+var callLoadConfig = proc.LoadConfig{FollowPointers: true, MaxVariableRecurse: 1, MaxStringLen: 256, MaxArrayValues: 64, MaxStructFields: -1}
+
+// callFunction evaluates expr, a function call, on goroutine goid using
+// call injection and returns its nret return values.
+func (d *Debugger) callFunction(goid int, expr string, nret int) ([]*proc.Variable, error) {
+	g, err := proc.FindGoroutine(d.target, goid)
+	if err != nil {
+		return nil, err
+	}
+	if err := proc.EvalExpressionWithCalls(d.target, g, expr, callLoadConfig, true); err != nil {
+		return nil, err
+	}
+	if d.target.StopReason != proc.StopCallReturned {
+		return nil, fmt.Errorf("target stopped before %s returned", expr)
+	}
+	for _, thread := range d.target.ThreadList() {
+		if !thread.Common().CallReturn {
+			continue
+		}
+		if g, _ := proc.GetG(thread); g == nil || g.ID != goid {
+			continue
+		}
+		rv := thread.Common().ReturnValues(callLoadConfig)
+		if len(rv) == 1 && rv[0].Name == "" {
+			// return values are always named, this is the value of a panic
+			return nil, fmt.Errorf("%s panicked: %s", expr, api.ConvertVar(rv[0]).SinglelineString())
+		}
+		if len(rv) != nret {
+			return nil, fmt.Errorf("wrong number of return values for %s", expr)
+		}
+		return rv, nil
+	}
+	return nil, fmt.Errorf("could not find the return values of %s", expr)
+}
+
+// callFunctionCheckError is like callFunction for functions returning
+// only an error, which is converted to a Go error.
+func (d *Debugger) callFunctionCheckError(goid int, expr string) error {
+	rv, err := d.callFunction(goid, expr, 1)
+	if err != nil {
+		return err
+	}
+	return callError(rv[0])
+}
+
+// callError returns v, the error returned by a function called with
+// callFunction, converted to a Go error.
+func callError(v *proc.Variable) error {
+	if v.Kind != reflect.Interface || len(v.Children) == 0 || v.Children[0].Addr == 0 {
+		return nil
+	}
+	return errors.New(api.ConvertVar(v).SinglelineString())
+}
+
+// outputBufferSize is the maximum amount of output retained for each
+// output stream of the target.
+const outputBufferSize = 1 << 20
+
+// outputBuffer is a ring buffer holding the last outputBufferSize bytes
+// written by the target to one of its output streams.
+type outputBuffer struct {
+	mu    sync.Mutex
+	buf   []byte
+	total int64 // number of bytes ever written to the buffer
+}
+
+func newOutputBuffer() *outputBuffer {
+	return &outputBuffer{buf: make([]byte, outputBufferSize)}
+}
+
+func (ob *outputBuffer) Write(p []byte) (int, error) {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+	n := len(p)
+	if len(p) > len(ob.buf) {
+		ob.total += int64(len(p) - len(ob.buf))
+		p = p[len(p)-len(ob.buf):]
+	}
+	for len(p) > 0 {
+		m := copy(ob.buf[ob.total%int64(len(ob.buf)):], p)
+		p = p[m:]
+		ob.total += int64(m)
+	}
+	return n, nil
+}
+
+// readFrom copies r into the buffer until r is closed.
+func (ob *outputBuffer) readFrom(r *os.File) {
+	_, _ = io.Copy(ob, r)
+	_ = r.Close()
+}
+
+// read returns the output written starting at offset and the offset of
+// the first returned byte, which is greater than offset if the output in
+// between was discarded.
+func (ob *outputBuffer) read(offset int64) ([]byte, int64) {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+	if lo := ob.total - int64(len(ob.buf)); offset < lo {
+		offset = lo
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > ob.total {
+		offset = ob.total
+	}
+	data := make([]byte, 0, ob.total-offset)
+	for off := offset; off < ob.total; {
+		chunk := ob.buf[off%int64(len(ob.buf)):]
+		if rem := ob.total - off; int64(len(chunk)) > rem {
+			chunk = chunk[:rem]
+		}
+		data = append(data, chunk...)
+		off += int64(len(chunk))
+	}
+	return data, offset
+}
+
+// GetOutput returns the standard output and standard error of the target
+// starting at stdoutOffset and stderrOffset respectively. Only the last
+// outputBufferSize bytes of each stream are retained.
+func (d *Debugger) GetOutput(stdoutOffset, stderrOffset int64) (stdout, stderr api.TargetOutput, err error) {
+	if d.output[0] == nil {
+		return stdout, stderr, errors.New("the output of the target is not being captured")
+	}
+	stdout.Data, stdout.Offset = d.output[0].read(stdoutOffset)
+	stderr.Data, stderr.Offset = d.output[1].read(stderrOffset)
+	return stdout, stderr, nil
+}
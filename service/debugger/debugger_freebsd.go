@@ -1,8 +1,12 @@
 package debugger
 
 import (
+	"errors"
 	"fmt"
+
 	sys "golang.org/x/sys/unix"
+
+	"github.com/go-delve/delve/service/api"
 )
 
 func attachErrorMessage(pid int, err error) error {
@@ -12,3 +16,23 @@ func attachErrorMessage(pid int, err error) error {
 func stopProcess(pid int) error {
 	return sys.Kill(pid, sys.SIGSTOP)
 }
+
+func fileDescriptors(pid int) ([]api.FileDescriptor, error) {
+	return nil, errors.New("listing file descriptors is not supported on freebsd")
+}
+
+func findProcessByName(name string, exclude map[int]bool, owner int) (int, error) {
+	return 0, errors.New("finding processes by name is not supported on freebsd, use a pid file")
+}
+
+func processOwner(pid int) (int, error) {
+	return 0, errors.New("reading the owner of a process is not supported on freebsd")
+}
+
+func readProcessIdentity(pid int) (processIdentity, error) {
+	return processIdentity{}, errProcessIdentityUnsupported
+}
+
+func processWorkingDir(pid int) (string, error) {
+	return "", errors.New("reading the working directory of a process is not supported on freebsd")
+}
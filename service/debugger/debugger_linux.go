@@ -1,12 +1,21 @@
 package debugger
 
 import (
+	"bytes"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"syscall"
+	"time"
 
 	sys "golang.org/x/sys/unix"
+
+	"github.com/go-delve/delve/pkg/proc/linutil"
+	"github.com/go-delve/delve/service/api"
 )
 
 func attachErrorMessage(pid int, err error) error {
@@ -34,3 +43,115 @@ func attachErrorMessage(pid int, err error) error {
 func stopProcess(pid int) error {
 	return sys.Kill(pid, sys.SIGSTOP)
 }
+
+func fileDescriptors(pid int) ([]api.FileDescriptor, error) {
+	fds, err := linutil.ProcessFileDescriptors(pid)
+	if err != nil {
+		return nil, err
+	}
+	r := make([]api.FileDescriptor, len(fds))
+	for i, fd := range fds {
+		r[i] = api.FileDescriptor{Num: fd.Num, Path: fd.Path, Proto: fd.Proto, Local: fd.Local, Remote: fd.Remote, State: fd.State}
+	}
+	return r, nil
+}
+
+// findProcessByName returns the pid of a process, not in exclude, whose
+// executable is called name, or 0 if there isn't one. When more than one
+// process matches the one with the lowest pid is returned.
+// Only processes of owner are considered, unless owner is anyOwner, any
+// user can start a process with a given name.
+func findProcessByName(name string, exclude map[int]bool, owner int) (int, error) {
+	fis, err := ioutil.ReadDir("/proc")
+	if err != nil {
+		return 0, err
+	}
+	pids := []int{}
+	for _, fi := range fis {
+		pid, err := strconv.Atoi(fi.Name())
+		if err != nil || exclude[pid] {
+			continue
+		}
+		pids = append(pids, pid)
+	}
+	sort.Ints(pids)
+	for _, pid := range pids {
+		if owner != anyOwner {
+			if uid, err := processOwner(pid); err != nil || uid != owner {
+				continue
+			}
+		}
+		if exe, err := os.Readlink(fmt.Sprintf("/proc/%d/exe", pid)); err == nil && filepath.Base(exe) == name {
+			return pid, nil
+		}
+		// comm is truncated to 15 characters
+		if comm, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/comm", pid)); err == nil && len(name) <= 15 && strings.TrimSpace(string(comm)) == name {
+			return pid, nil
+		}
+	}
+	return 0, nil
+}
+
+// processOwner returns the uid of the owner of process pid.
+func processOwner(pid int) (int, error) {
+	fi, err := os.Stat(fmt.Sprintf("/proc/%d", pid))
+	if err != nil {
+		return 0, err
+	}
+	return int(fi.Sys().(*syscall.Stat_t).Uid), nil
+}
+
+// processWorkingDir returns the working directory of process pid.
+func processWorkingDir(pid int) (string, error) {
+	return os.Readlink(fmt.Sprintf("/proc/%d/cwd", pid))
+}
+
+// readProcessIdentity reads the identity of process pid from /proc.
+func readProcessIdentity(pid int) (processIdentity, error) {
+	buf, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return processIdentity{}, err
+	}
+	// The second field, the command name, is enclosed in parenthesis and can
+	// contain spaces, the fields we want follow it.
+	i := bytes.LastIndexByte(buf, ')')
+	if i < 0 {
+		return processIdentity{}, fmt.Errorf("malformed /proc/%d/stat", pid)
+	}
+	fields := strings.Fields(string(buf[i+1:]))
+	// starttime is the 22nd field, 20th after the command name.
+	const starttimeField = 22 - 3
+	if len(fields) <= starttimeField {
+		return processIdentity{}, fmt.Errorf("malformed /proc/%d/stat", pid)
+	}
+	startTime, err := strconv.ParseUint(fields[starttimeField], 10, 64)
+	if err != nil {
+		return processIdentity{}, fmt.Errorf("malformed /proc/%d/stat: %v", pid, err)
+	}
+	id := processIdentity{startTime: startTime}
+	if btime := bootTime(); !btime.IsZero() {
+		// starttime is expressed in clock ticks since boot, USER_HZ is 100 on
+		// all the architectures we support.
+		const userHZ = 100
+		id.startedAt = btime.Add(time.Duration(startTime) * time.Second / userHZ)
+	}
+	return id, nil
+}
+
+// bootTime returns the time the system booted, read from /proc/stat.
+func bootTime() time.Time {
+	buf, err := ioutil.ReadFile("/proc/stat")
+	if err != nil {
+		return time.Time{}
+	}
+	for _, line := range strings.Split(string(buf), "\n") {
+		if strings.HasPrefix(line, "btime ") {
+			btime, err := strconv.ParseInt(strings.TrimSpace(line[len("btime "):]), 10, 64)
+			if err != nil {
+				return time.Time{}
+			}
+			return time.Unix(btime, 0)
+		}
+	}
+	return time.Time{}
+}
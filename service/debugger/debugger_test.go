@@ -2,6 +2,7 @@ package debugger
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -68,3 +69,61 @@ func TestDebugger_LaunchInvalidFormat(t *testing.T) {
 		t.Fatalf("expected error \"%s\" got \"%v\"", api.ErrNotExecutable, err)
 	}
 }
+
+func TestDebugger_FindReattachPid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reattach")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	pidfile := filepath.Join(dir, "target.pid")
+
+	check := func(exclude map[int]bool, tgt int) {
+		t.Helper()
+		pid, err := findProcessBySpec(pidfile, exclude, os.Getuid())
+		if err != nil {
+			t.Fatalf("findProcessBySpec: %v", err)
+		}
+		if pid != tgt {
+			t.Errorf("findProcessBySpec returned %d, expected %d", pid, tgt)
+		}
+	}
+
+	check(nil, 0) // no pid file yet
+	if err := ioutil.WriteFile(pidfile, []byte("1234\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	check(nil, 1234)
+	check(map[int]bool{1234: true}, 0) // old process
+	if err := ioutil.WriteFile(pidfile, []byte("123x"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	check(nil, 0) // partially written
+}
+
+func TestOutputBuffer(t *testing.T) {
+	ob := &outputBuffer{buf: make([]byte, 8)}
+	check := func(offset int64, tgt string, tgtOffset int64) {
+		t.Helper()
+		data, off := ob.read(offset)
+		if string(data) != tgt || off != tgtOffset {
+			t.Errorf("read(%d): expected %q at %d, got %q at %d", offset, tgt, tgtOffset, data, off)
+		}
+	}
+
+	ob.Write([]byte("abcde"))
+	check(0, "abcde", 0)
+	check(3, "de", 3)
+	check(5, "", 5)
+	check(10, "", 5)
+
+	// Wrap around, the oldest output is discarded.
+	ob.Write([]byte("fghij"))
+	check(0, "cdefghij", 2)
+	check(6, "ghij", 6)
+
+	// Writes larger than the buffer only keep their end.
+	ob.Write([]byte("0123456789AB"))
+	check(0, "456789AB", 14)
+	check(20, "AB", 20)
+}
@@ -5,8 +5,12 @@ package debugger
 import (
 	"debug/elf"
 	"debug/macho"
+	"fmt"
 	"os"
 	"runtime"
+	"syscall"
+
+	"golang.org/x/sys/unix"
 
 	"github.com/go-delve/delve/service/api"
 )
@@ -40,3 +44,11 @@ func verifyBinaryFormat(exePath string) error {
 	}
 	return nil
 }
+
+// signalName returns the name of signal sig, for example SIGSEGV.
+func signalName(sig int) string {
+	if name := unix.SignalName(syscall.Signal(sig)); name != "" {
+		return name
+	}
+	return fmt.Sprintf("signal %d", sig)
+}
@@ -5,11 +5,14 @@ package debugger
 import (
 	"bytes"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/creack/pty"
 	"github.com/go-delve/delve/pkg/gobuild"
@@ -95,3 +98,132 @@ func TestDebugger_LaunchWithTTY(t *testing.T) {
 		t.Fatal("process open file list does not contain expected tty")
 	}
 }
+
+func TestDebugger_FindProcessByName(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("finding processes by name is only supported on linux")
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid, err := findProcessByName(filepath.Base(exe), nil, os.Getuid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pid != os.Getpid() {
+		t.Errorf("expected %d got %d", os.Getpid(), pid)
+	}
+	pid, err = findProcessByName(filepath.Base(exe), map[int]bool{os.Getpid(): true}, os.Getuid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pid == os.Getpid() {
+		t.Errorf("excluded pid returned")
+	}
+	// processes of other owners are skipped, unless any owner is accepted
+	if pid, _ := findProcessByName(filepath.Base(exe), nil, os.Getuid()+1); pid == os.Getpid() {
+		t.Errorf("process of a different owner returned")
+	}
+	if pid, _ := findProcessByName(filepath.Base(exe), nil, anyOwner); pid != os.Getpid() {
+		t.Errorf("expected %d got %d with any owner", os.Getpid(), pid)
+	}
+}
+
+func TestDebugger_FindProcessOtherUser(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("finding processes by name is only supported on linux")
+	}
+	if os.Getuid() == 0 {
+		t.Skip("init is owned by the current user")
+	}
+	// init is owned by root and must never be found, either by name or
+	// through a pid file.
+	comm, err := ioutil.ReadFile("/proc/1/comm")
+	if err != nil {
+		t.Skip("could not read name of init:", err)
+	}
+	if pid, err := findProcessByName(strings.TrimSpace(string(comm)), nil, os.Getuid()); err != nil || pid == 1 {
+		t.Errorf("findProcessByName returned %d %v", pid, err)
+	}
+	dir, err := ioutil.TempDir("", "reattach")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	pidfile := filepath.Join(dir, "init.pid")
+	if err := ioutil.WriteFile(pidfile, []byte("1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if pid, err := findProcessBySpec(pidfile, nil, os.Getuid()); err != nil || pid != 0 {
+		t.Errorf("findProcessBySpec returned %d %v, expected 0", pid, err)
+	}
+}
+
+func TestDebugger_RunningProcesses(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("finding processes by name is only supported on linux")
+	}
+	cmd := exec.Command("sleep", "10")
+	if err := cmd.Start(); err != nil {
+		t.Skip("could not start sleep:", err)
+	}
+	defer func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}()
+
+	d := new(Debugger)
+	running, err := d.runningProcesses("sleep", os.Getuid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !running[cmd.Process.Pid] || !running[os.Getpid()] {
+		t.Errorf("running processes %v do not include %d and %d", running, cmd.Process.Pid, os.Getpid())
+	}
+	// a process that was already running is never waited for
+	if pid, _ := findProcessBySpec("sleep", running, os.Getuid()); pid != 0 {
+		t.Errorf("findProcessBySpec returned %d, expected 0", pid)
+	}
+}
+
+func TestDebugger_ReadProcessIdentity(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("reading the identity of a process is only supported on linux")
+	}
+	id, err := readProcessIdentity(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id.startTime == 0 || id.startedAt.IsZero() || id.startedAt.After(time.Now().Add(time.Second)) {
+		t.Errorf("wrong start time %d (%v)", id.startTime, id.startedAt)
+	}
+	id2, err := readProcessIdentity(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id2 != id {
+		t.Errorf("identity changed: %#v %#v", id, id2)
+	}
+
+	// A pid file older than the process it refers to is stale.
+	dir, err := ioutil.TempDir("", "reattach")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	pidfile := filepath.Join(dir, "target.pid")
+	if err := ioutil.WriteFile(pidfile, []byte(fmt.Sprintf("%d\n", os.Getpid())), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if pid, _ := findProcessBySpec(pidfile, nil, os.Getuid()); pid != os.Getpid() {
+		t.Errorf("findProcessBySpec returned %d, expected %d", pid, os.Getpid())
+	}
+	old := id.startedAt.Add(-time.Hour)
+	if err := os.Chtimes(pidfile, old, old); err != nil {
+		t.Fatal(err)
+	}
+	if pid, _ := findProcessBySpec(pidfile, nil, os.Getuid()); pid != 0 {
+		t.Errorf("findProcessBySpec returned %d for a stale pid file", pid)
+	}
+}
@@ -2,6 +2,7 @@ package debugger
 
 import (
 	"debug/pe"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -40,3 +41,29 @@ func verifyBinaryFormat(exePath string) error {
 	}
 	return nil
 }
+
+func fileDescriptors(pid int) ([]api.FileDescriptor, error) {
+	return nil, errors.New("listing file descriptors is not supported on windows")
+}
+
+// signalName returns a description of signal sig, processes on windows are
+// never killed by signals.
+func signalName(sig int) string {
+	return fmt.Sprintf("signal %d", sig)
+}
+
+func findProcessByName(name string, exclude map[int]bool, owner int) (int, error) {
+	return 0, errors.New("finding processes by name is not supported on windows, use a pid file")
+}
+
+func processOwner(pid int) (int, error) {
+	return 0, errors.New("reading the owner of a process is not supported on windows")
+}
+
+func readProcessIdentity(pid int) (processIdentity, error) {
+	return processIdentity{}, errProcessIdentityUnsupported
+}
+
+func processWorkingDir(pid int) (string, error) {
+	return "", errors.New("reading the working directory of a process is not supported on windows")
+}
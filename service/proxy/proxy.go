@@ -0,0 +1,321 @@
+// Package proxy implements recording and replaying of the traffic between
+// a client and a headless instance of Delve.
+//
+// A recording proxy sits between a client and a server and logs every
+// message exchanged as a line of JSON. A replaying server answers the
+// requests of a client using the responses stored in such a log, without
+// needing a target process, which is useful to test editor plugins
+// against realistic traffic.
+//
+// Both the JSON-RPC and the DAP protocols are supported.
+package proxy
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/go-delve/delve/pkg/logflags"
+	"github.com/google/go-dap"
+)
+
+// Protocol is the protocol spoken by clients and servers.
+type Protocol uint8
+
+const (
+	// JSONRPC is the JSON-RPC protocol served by the headless server.
+	JSONRPC Protocol = iota
+	// DAP is the Debug Adapter Protocol served by 'dlv dap'.
+	DAP
+)
+
+// Direction describes which side of the connection sent a message.
+type Direction string
+
+const (
+	// FromClient is used for messages sent by the client.
+	FromClient Direction = "client"
+	// FromServer is used for messages sent by the server.
+	FromServer Direction = "server"
+)
+
+// Entry is a recorded message.
+type Entry struct {
+	// Conn identifies the connection the message was exchanged on.
+	Conn      int             `json:"conn"`
+	Time      time.Time       `json:"time"`
+	Direction Direction       `json:"direction"`
+	Message   json.RawMessage `json:"message"`
+}
+
+// ReadLog reads a log produced by Record.
+func ReadLog(r io.Reader) ([]Entry, error) {
+	var entries []Entry
+	dec := json.NewDecoder(r)
+	for {
+		var e Entry
+		err := dec.Decode(&e)
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+}
+
+// Record accepts connections on listener and forwards them to the server
+// at addr, writing every message exchanged to out, one Entry per line.
+// Connections that can not be forwarded are closed and logged. Record
+// returns when listener is closed, or with an error when writing to out
+// fails, since the log would be incomplete from then on.
+func Record(listener net.Listener, addr string, p Protocol, out io.Writer) error {
+	log := logflags.RPCLogger()
+	if p == DAP {
+		log = logflags.DAPLogger()
+	}
+	var mu sync.Mutex
+	var recordErr error
+	enc := json.NewEncoder(out)
+	record := func(e *Entry) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		if recordErr != nil {
+			return false
+		}
+		if err := enc.Encode(e); err != nil {
+			recordErr = fmt.Errorf("could not record message: %v", err)
+			listener.Close()
+			return false
+		}
+		return true
+	}
+
+	for connID := 0; ; connID++ {
+		client, err := listener.Accept()
+		if err != nil {
+			mu.Lock()
+			defer mu.Unlock()
+			return recordErr
+		}
+		server, err := net.Dial("tcp", addr)
+		if err != nil {
+			log.Errorf("could not connect to %s: %v", addr, err)
+			client.Close()
+			continue
+		}
+		connID := connID
+		forward := func(dir Direction, dst, src net.Conn) {
+			defer dst.Close()
+			r := newReader(p, src)
+			for {
+				msg, err := r.read()
+				if err != nil {
+					return
+				}
+				if !record(&Entry{Conn: connID, Time: time.Now(), Direction: dir, Message: msg}) {
+					src.Close()
+					return
+				}
+				if err := write(p, dst, msg); err != nil {
+					return
+				}
+			}
+		}
+		go forward(FromClient, server, client)
+		go forward(FromServer, client, server)
+	}
+}
+
+// Replay accepts connections on listener and answers the requests of
+// clients using the responses recorded in entries. Requests are matched to
+// the first recorded request with the same method that was not used yet
+// on the same connection. Replay returns when listener is closed.
+func Replay(listener net.Listener, p Protocol, entries []Entry) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return nil
+		}
+		go replayConn(conn, p, entries)
+	}
+}
+
+func replayConn(conn net.Conn, p Protocol, entries []Entry) {
+	defer conn.Close()
+	used := make([]bool, len(entries))
+	r := newReader(p, conn)
+	for {
+		msg, err := r.read()
+		if err != nil {
+			return
+		}
+		req, err := parseMessage(p, msg)
+		if err != nil {
+			return
+		}
+		resps := findResponse(p, entries, used, req.method)
+		if resps == nil {
+			resps = []json.RawMessage{errorResponse(p, req.method)}
+		}
+		resps[0], err = setResponseID(p, resps[0], req.id)
+		if err != nil {
+			return
+		}
+		for _, resp := range resps {
+			if err := write(p, conn, resp); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// findResponse finds the first unused request for method in entries and
+// returns its response followed by the messages the server sent, without
+// being asked, before the next request.
+func findResponse(p Protocol, entries []Entry, used []bool, method string) []json.RawMessage {
+	for i := range entries {
+		if used[i] || entries[i].Direction != FromClient {
+			continue
+		}
+		req, err := parseMessage(p, entries[i].Message)
+		if err != nil || req.method != method {
+			continue
+		}
+		for j := i + 1; j < len(entries); j++ {
+			if used[j] || entries[j].Direction != FromServer || entries[j].Conn != entries[i].Conn {
+				continue
+			}
+			resp, err := parseMessage(p, entries[j].Message)
+			if err != nil || !resp.isResponse || string(resp.id) != string(req.id) {
+				continue
+			}
+			used[i], used[j] = true, true
+			r := []json.RawMessage{entries[j].Message}
+			for k := j + 1; k < len(entries) && entries[k].Direction == FromServer; k++ {
+				if ev, err := parseMessage(p, entries[k].Message); err != nil || ev.isResponse || entries[k].Conn != entries[i].Conn {
+					continue
+				}
+				used[k] = true
+				r = append(r, entries[k].Message)
+			}
+			return r
+		}
+	}
+	return nil
+}
+
+// message contains the fields of a message used to match requests and
+// responses.
+type message struct {
+	method     string
+	id         json.RawMessage
+	isResponse bool
+}
+
+func parseMessage(p Protocol, msg json.RawMessage) (message, error) {
+	switch p {
+	case DAP:
+		var m struct {
+			Seq        json.RawMessage `json:"seq"`
+			Type       string          `json:"type"`
+			Command    string          `json:"command"`
+			RequestSeq json.RawMessage `json:"request_seq"`
+		}
+		if err := json.Unmarshal(msg, &m); err != nil {
+			return message{}, err
+		}
+		if m.Type == "response" {
+			return message{method: m.Command, id: m.RequestSeq, isResponse: true}, nil
+		}
+		return message{method: m.Command, id: m.Seq}, nil
+	default:
+		var m struct {
+			Method string          `json:"method"`
+			ID     json.RawMessage `json:"id"`
+		}
+		if err := json.Unmarshal(msg, &m); err != nil {
+			return message{}, err
+		}
+		return message{method: m.Method, id: m.ID, isResponse: m.Method == ""}, nil
+	}
+}
+
+// setResponseID changes the request id a response refers to.
+func setResponseID(p Protocol, msg, id json.RawMessage) (json.RawMessage, error) {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(msg, &m); err != nil {
+		return nil, err
+	}
+	switch p {
+	case DAP:
+		m["request_seq"] = id
+	default:
+		m["id"] = id
+	}
+	return json.Marshal(m)
+}
+
+func errorResponse(p Protocol, method string) json.RawMessage {
+	errmsg := fmt.Sprintf("no recorded response for %s", method)
+	var resp interface{}
+	switch p {
+	case DAP:
+		resp = &dap.ErrorResponse{
+			Response: dap.Response{
+				ProtocolMessage: dap.ProtocolMessage{Type: "response"},
+				Command:         method,
+				Success:         false,
+				Message:         errmsg,
+			},
+		}
+	default:
+		resp = map[string]interface{}{"result": nil, "error": errmsg}
+	}
+	buf, _ := json.Marshal(resp)
+	return buf
+}
+
+// reader reads messages from a connection.
+type reader struct {
+	p   Protocol
+	dec *json.Decoder
+	r   *bufio.Reader
+}
+
+func newReader(p Protocol, conn io.Reader) *reader {
+	if p == DAP {
+		return &reader{p: p, r: bufio.NewReader(conn)}
+	}
+	return &reader{p: p, dec: json.NewDecoder(conn)}
+}
+
+func (r *reader) read() (json.RawMessage, error) {
+	if r.p == DAP {
+		buf, err := dap.ReadBaseMessage(r.r)
+		if err != nil {
+			return nil, err
+		}
+		if !json.Valid(buf) {
+			return nil, errors.New("invalid message")
+		}
+		return buf, nil
+	}
+	var msg json.RawMessage
+	err := r.dec.Decode(&msg)
+	return msg, err
+}
+
+func write(p Protocol, w io.Writer, msg json.RawMessage) error {
+	if p == DAP {
+		return dap.WriteBaseMessage(w, msg)
+	}
+	_, err := w.Write(append(msg, '\n'))
+	return err
+}
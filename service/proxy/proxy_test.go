@@ -0,0 +1,231 @@
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"strings"
+	"testing"
+
+	"github.com/google/go-dap"
+)
+
+type Arith struct{}
+
+func (*Arith) Double(in int, out *int) error {
+	*out = 2 * in
+	return nil
+}
+
+func listen(t *testing.T) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return listener
+}
+
+func TestRecordReplayJSONRPC(t *testing.T) {
+	srv := rpc.NewServer()
+	srv.Register(&Arith{})
+	serverListener := listen(t)
+	defer serverListener.Close()
+	go func() {
+		for {
+			conn, err := serverListener.Accept()
+			if err != nil {
+				return
+			}
+			go srv.ServeCodec(jsonrpc.NewServerCodec(conn))
+		}
+	}()
+
+	// Record
+	var log bytes.Buffer
+	proxyListener := listen(t)
+	done := make(chan struct{})
+	go func() {
+		Record(proxyListener, serverListener.Addr().String(), JSONRPC, &log)
+		close(done)
+	}()
+	client, err := jsonrpc.Dial("tcp", proxyListener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, in := range []int{1, 21} {
+		var out int
+		if err := client.Call("Arith.Double", in, &out); err != nil {
+			t.Fatal(err)
+		}
+	}
+	client.Close()
+	proxyListener.Close()
+	<-done
+
+	entries, err := ReadLog(strings.NewReader(log.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("expected 4 entries got %d:\n%s", len(entries), log.String())
+	}
+
+	// Replay, the server is stopped to make sure that it isn't used.
+	serverListener.Close()
+	replayListener := listen(t)
+	defer replayListener.Close()
+	go Replay(replayListener, JSONRPC, entries)
+	client, err = jsonrpc.Dial("tcp", replayListener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	for _, tc := range []int{2, 42} {
+		var out int
+		if err := client.Call("Arith.Double", 0, &out); err != nil {
+			t.Fatal(err)
+		}
+		if out != tc {
+			t.Errorf("expected %d got %d", tc, out)
+		}
+	}
+	var out int
+	if err := client.Call("Arith.Double", 0, &out); err == nil || !strings.Contains(err.Error(), "no recorded response") {
+		t.Errorf("expected error for request without a recorded response, got %v", err)
+	}
+}
+
+func TestRecordDialError(t *testing.T) {
+	// nothing listens on the address of a closed listener
+	serverListener := listen(t)
+	addr := serverListener.Addr().String()
+	serverListener.Close()
+
+	proxyListener := listen(t)
+	done := make(chan error)
+	go func() {
+		done <- Record(proxyListener, addr, JSONRPC, &bytes.Buffer{})
+	}()
+	for i := 0; i < 2; i++ {
+		conn, err := net.Dial("tcp", proxyListener.Addr().String())
+		if err != nil {
+			t.Fatalf("connection %d: %v", i, err)
+		}
+		if _, err := conn.Read(make([]byte, 1)); err == nil {
+			t.Errorf("connection %d: expected connection to be closed", i)
+		}
+		conn.Close()
+	}
+	proxyListener.Close()
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+type errWriter struct{}
+
+func (errWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestRecordWriteError(t *testing.T) {
+	srv := rpc.NewServer()
+	srv.Register(&Arith{})
+	serverListener := listen(t)
+	defer serverListener.Close()
+	go func() {
+		for {
+			conn, err := serverListener.Accept()
+			if err != nil {
+				return
+			}
+			go srv.ServeCodec(jsonrpc.NewServerCodec(conn))
+		}
+	}()
+
+	proxyListener := listen(t)
+	defer proxyListener.Close()
+	done := make(chan error)
+	go func() {
+		done <- Record(proxyListener, serverListener.Addr().String(), JSONRPC, errWriter{})
+	}()
+	client, err := jsonrpc.Dial("tcp", proxyListener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	var out int
+	if err := client.Call("Arith.Double", 1, &out); err == nil {
+		t.Errorf("call succeeded without being recorded")
+	}
+	if err := <-done; err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Errorf("expected write error, got %v", err)
+	}
+}
+
+func TestReplayDAP(t *testing.T) {
+	var log bytes.Buffer
+	enc := json.NewEncoder(&log)
+	for _, e := range []struct {
+		dir Direction
+		msg dap.Message
+	}{
+		{FromClient, &dap.InitializeRequest{Request: *newRequest(1, "initialize")}},
+		{FromServer, &dap.InitializeResponse{Response: *newResponse(1, 1, "initialize")}},
+		{FromServer, &dap.InitializedEvent{Event: dap.Event{ProtocolMessage: dap.ProtocolMessage{Seq: 2, Type: "event"}, Event: "initialized"}}},
+	} {
+		buf, _ := json.Marshal(e.msg)
+		enc.Encode(&Entry{Direction: e.dir, Message: buf})
+	}
+	entries, err := ReadLog(&log)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listener := listen(t)
+	defer listener.Close()
+	go Replay(listener, DAP, entries)
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	dap.WriteProtocolMessage(conn, &dap.InitializeRequest{Request: *newRequest(7, "initialize")})
+	msg, err := dap.ReadProtocolMessage(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp, ok := msg.(*dap.InitializeResponse); !ok || resp.RequestSeq != 7 {
+		t.Errorf("unexpected response %#v", msg)
+	}
+	msg, err = dap.ReadProtocolMessage(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := msg.(*dap.InitializedEvent); !ok {
+		t.Errorf("unexpected message %#v", msg)
+	}
+
+	dap.WriteProtocolMessage(conn, &dap.InitializeRequest{Request: *newRequest(8, "initialize")})
+	msg, err = dap.ReadProtocolMessage(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp, ok := msg.(*dap.ErrorResponse); !ok || resp.Success || resp.RequestSeq != 8 {
+		t.Errorf("unexpected response %#v", msg)
+	}
+}
+
+func newRequest(seq int, command string) *dap.Request {
+	return &dap.Request{ProtocolMessage: dap.ProtocolMessage{Seq: seq, Type: "request"}, Command: command}
+}
+
+func newResponse(seq, requestSeq int, command string) *dap.Response {
+	return &dap.Response{ProtocolMessage: dap.ProtocolMessage{Seq: seq, Type: "response"}, RequestSeq: requestSeq, Command: command, Success: true}
+}
@@ -174,7 +174,7 @@ func (s *RPCServer) GetThread(id int, thread *api.Thread) error {
 }
 
 func (s *RPCServer) ListPackageVars(filter string, variables *[]api.Variable) error {
-	vars, err := s.debugger.PackageVariables(filter, defaultLoadConfig)
+	vars, err := s.debugger.PackageVariables("", filter, defaultLoadConfig)
 	if err != nil {
 		return err
 	}
@@ -188,7 +188,7 @@ type ThreadListArgs struct {
 }
 
 func (s *RPCServer) ListThreadPackageVars(args *ThreadListArgs, variables *[]api.Variable) error {
-	vars, err := s.debugger.PackageVariables(args.Filter, defaultLoadConfig)
+	vars, err := s.debugger.PackageVariables("", args.Filter, defaultLoadConfig)
 	if err != nil {
 		return err
 	}
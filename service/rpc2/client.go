@@ -1,7 +1,6 @@
 package rpc2
 
 import (
-	"fmt"
 	"log"
 	"net"
 	"net/rpc"
@@ -108,7 +107,7 @@ func (c *RPCClient) continueDir(cmd string) <-chan *api.DebuggerState {
 			}
 			if state.Exited {
 				// Error types apparently cannot be marshalled by Go correctly. Must reset error here.
-				state.Err = fmt.Errorf("Process %d has exited with status %d", c.ProcessPid(), state.ExitStatus)
+				state.Err = state.ExitError(c.ProcessPid())
 			}
 			ch <- &state
 			if err != nil || state.Exited {
@@ -208,6 +207,17 @@ func (c *RPCClient) SwitchGoroutine(goroutineID int) (*api.DebuggerState, error)
 	return &out.State, err
 }
 
+func (c *RPCClient) Command(name string, opts api.CommandOptions) (*api.DebuggerState, error) {
+	cmd, err := api.NewCommand(name, opts)
+	if err != nil {
+		return nil, err
+	}
+	cmd.ReturnInfoLoadConfig = c.retValLoadCfg
+	var out CommandOut
+	err = c.call("Command", cmd, &out)
+	return &out.State, err
+}
+
 func (c *RPCClient) Halt() (*api.DebuggerState, error) {
 	var out CommandOut
 	err := c.call("Command", api.DebuggerCommand{Name: api.Halt}, &out)
@@ -238,16 +248,114 @@ func (c *RPCClient) CreateBreakpoint(breakPoint *api.Breakpoint) (*api.Breakpoin
 
 func (c *RPCClient) CreateWatchpoint(scope api.EvalScope, expr string, wtype api.WatchType) (*api.Breakpoint, error) {
 	var out CreateWatchpointOut
-	err := c.call("CreateWatchpoint", CreateWatchpointIn{scope, expr, wtype}, &out)
+	err := c.call("CreateWatchpoint", CreateWatchpointIn{scope, expr, wtype, 0}, &out)
 	return out.Breakpoint, err
 }
 
+// CreateWatchpointHistory creates a write watchpoint that records the last
+// n writes instead of stopping the target.
+func (c *RPCClient) CreateWatchpointHistory(scope api.EvalScope, expr string, n int) (*api.Breakpoint, error) {
+	var out CreateWatchpointOut
+	err := c.call("CreateWatchpoint", CreateWatchpointIn{scope, expr, api.WatchWrite, n}, &out)
+	return out.Breakpoint, err
+}
+
+// CreateChanWatchpoint creates a breakpoint that stops when any goroutine
+// sends to or receives from the channel expr evaluates to.
+func (c *RPCClient) CreateChanWatchpoint(scope api.EvalScope, expr string) (*api.Breakpoint, error) {
+	var out CreateChanWatchpointOut
+	err := c.call("CreateChanWatchpoint", CreateChanWatchpointIn{scope, expr}, &out)
+	return out.Breakpoint, err
+}
+
+// SearchMemory searches pattern in the memory of the target between start
+// and end, or in all of its readable memory if end is zero.
+func (c *RPCClient) SearchMemory(pattern []byte, start, end uint64, max int) ([]api.MemorySearchResult, error) {
+	var out SearchMemoryOut
+	err := c.call("SearchMemory", SearchMemoryIn{pattern, start, end, max}, &out)
+	return out.Results, err
+}
+
+// ListMemoryRegions returns the memory mappings of the target.
+func (c *RPCClient) ListMemoryRegions() ([]api.MemoryRegion, error) {
+	var out ListMemoryRegionsOut
+	err := c.call("ListMemoryRegions", ListMemoryRegionsIn{}, &out)
+	return out.Regions, err
+}
+
+// Environ returns the environment of the target.
+func (c *RPCClient) Environ() ([]string, error) {
+	var out EnvironOut
+	err := c.call("Environ", EnvironIn{}, &out)
+	return out.Env, err
+}
+
+// SetEnv sets an environment variable of the target.
+func (c *RPCClient) SetEnv(key, value string) error {
+	var out SetEnvOut
+	return c.call("SetEnv", SetEnvIn{Key: key, Value: value}, &out)
+}
+
+// UnsetEnv removes a variable from the environment of the target.
+func (c *RPCClient) UnsetEnv(key string) error {
+	var out SetEnvOut
+	return c.call("SetEnv", SetEnvIn{Key: key, Unset: true}, &out)
+}
+
+// ListFileDescriptors returns the open file descriptors of the target
+// process.
+func (c *RPCClient) ListFileDescriptors() ([]api.FileDescriptor, error) {
+	var out ListFileDescriptorsOut
+	err := c.call("ListFileDescriptors", ListFileDescriptorsIn{}, &out)
+	return out.FileDescriptors, err
+}
+
+// ListTimers returns the pending timers of the runtime.
+func (c *RPCClient) ListTimers() ([]api.Timer, error) {
+	var out ListTimersOut
+	err := c.call("ListTimers", ListTimersIn{}, &out)
+	return out.Timers, err
+}
+
+// MutexState decodes the state of the sync.Mutex or sync.RWMutex expr
+// evaluates to.
+func (c *RPCClient) MutexState(scope api.EvalScope, expr string) (*api.MutexState, error) {
+	var out MutexStateOut
+	err := c.call("MutexState", MutexStateIn{scope, expr}, &out)
+	return out.State, err
+}
+
+// ChanState lists the elements buffered in the channel expr evaluates to
+// and the goroutines blocked on it.
+func (c *RPCClient) ChanState(scope api.EvalScope, expr string, cfg api.LoadConfig) (*api.ChanState, error) {
+	var out ChanStateOut
+	err := c.call("ChanState", ChanStateIn{scope, expr, cfg}, &out)
+	return out.State, err
+}
+
+// WatchHistory returns the writes recorded by a watchpoint created with
+// CreateWatchpointHistory, or the hits recorded by a breakpoint with a
+// non-zero WatchHistory.
+func (c *RPCClient) WatchHistory(id int) ([]api.WatchHistoryEntry, error) {
+	var out WatchHistoryOut
+	err := c.call("WatchHistory", WatchHistoryIn{id}, &out)
+	return out.Entries, err
+}
+
 func (c *RPCClient) ListBreakpoints() ([]*api.Breakpoint, error) {
 	var out ListBreakpointsOut
 	err := c.call("ListBreakpoints", ListBreakpointsIn{}, &out)
 	return out.Breakpoints, err
 }
 
+// ListAllBreakpoints returns the user breakpoints followed by the
+// breakpoints set internally by Delve.
+func (c *RPCClient) ListAllBreakpoints() ([]*api.Breakpoint, error) {
+	var out ListBreakpointsOut
+	err := c.call("ListBreakpoints", ListBreakpointsIn{All: true}, &out)
+	return out.Breakpoints, err
+}
+
 func (c *RPCClient) ClearBreakpoint(id int) (*api.Breakpoint, error) {
 	var out ClearBreakpointOut
 	err := c.call("ClearBreakpoint", ClearBreakpointIn{id, ""}, &out)
@@ -272,6 +380,27 @@ func (c *RPCClient) ToggleBreakpointByName(name string) (*api.Breakpoint, error)
 	return out.Breakpoint, err
 }
 
+// AmendBreakpointGroup enables or disables all the breakpoints of a group.
+func (c *RPCClient) AmendBreakpointGroup(group string, disabled bool) ([]*api.Breakpoint, error) {
+	var out AmendBreakpointGroupOut
+	err := c.call("AmendBreakpointGroup", AmendBreakpointGroupIn{group, disabled}, &out)
+	return out.Breakpoints, err
+}
+
+// ClearBreakpointGroup deletes all the breakpoints of a group.
+func (c *RPCClient) ClearBreakpointGroup(group string) ([]*api.Breakpoint, error) {
+	var out ClearBreakpointGroupOut
+	err := c.call("ClearBreakpointGroup", ClearBreakpointGroupIn{group}, &out)
+	return out.Breakpoints, err
+}
+
+// ClearBreakpoints deletes all the breakpoints selected by filter.
+func (c *RPCClient) ClearBreakpoints(filter api.BreakpointFilter) ([]*api.Breakpoint, error) {
+	var out ClearBreakpointsOut
+	err := c.call("ClearBreakpoints", ClearBreakpointsIn{filter}, &out)
+	return out.Breakpoints, err
+}
+
 func (c *RPCClient) AmendBreakpoint(bp *api.Breakpoint) error {
 	out := new(AmendBreakpointOut)
 	err := c.call("AmendBreakpoint", AmendBreakpointIn{*bp}, out)
@@ -283,6 +412,11 @@ func (c *RPCClient) CancelNext() error {
 	return c.call("CancelNext", CancelNextIn{}, &out)
 }
 
+func (c *RPCClient) CancelRequest() error {
+	var out CancelRequestOut
+	return c.call("CancelRequest", CancelRequestIn{}, &out)
+}
+
 func (c *RPCClient) ListThreads() ([]*api.Thread, error) {
 	var out ListThreadsOut
 	err := c.call("ListThreads", ListThreadsIn{}, &out)
@@ -326,7 +460,13 @@ func (c *RPCClient) ListTypes(filter string) ([]string, error) {
 
 func (c *RPCClient) ListPackageVariables(filter string, cfg api.LoadConfig) ([]api.Variable, error) {
 	var out ListPackageVarsOut
-	err := c.call("ListPackageVars", ListPackageVarsIn{filter, cfg}, &out)
+	err := c.call("ListPackageVars", ListPackageVarsIn{filter, cfg, ""}, &out)
+	return out.Variables, err
+}
+
+func (c *RPCClient) ListPackageVariablesInPackage(pkg, filter string, cfg api.LoadConfig) ([]api.Variable, error) {
+	var out ListPackageVarsOut
+	err := c.call("ListPackageVars", ListPackageVarsIn{filter, cfg, pkg}, &out)
 	return out.Variables, err
 }
 
@@ -371,10 +511,54 @@ func (c *RPCClient) ListGoroutinesWithFilter(start, count int, filters []api.Lis
 
 func (c *RPCClient) Stacktrace(goroutineId, depth int, opts api.StacktraceOptions, cfg *api.LoadConfig) ([]api.Stackframe, error) {
 	var out StacktraceOut
-	err := c.call("Stacktrace", StacktraceIn{goroutineId, depth, false, false, opts, cfg}, &out)
+	err := c.call("Stacktrace", StacktraceIn{goroutineId, depth, false, false, opts, cfg, 0, 0}, &out)
 	return out.Locations, err
 }
 
+func (c *RPCClient) ThreadStacktrace(threadID, depth int, cfg *api.LoadConfig) ([]api.Stackframe, error) {
+	var out StacktraceOut
+	err := c.call("Stacktrace", StacktraceIn{Depth: depth, Cfg: cfg, ThreadID: threadID}, &out)
+	return out.Locations, err
+}
+
+func (c *RPCClient) StacktraceChunk(goroutineId, skip, depth int, opts api.StacktraceOptions, cfg *api.LoadConfig) ([]api.Stackframe, error) {
+	var out StacktraceOut
+	err := c.call("Stacktrace", StacktraceIn{goroutineId, depth, false, false, opts, cfg, skip, 0}, &out)
+	return out.Locations, err
+}
+
+func (c *RPCClient) GoroutinesStacktraces(depth int, opts api.StacktraceOptions) ([]api.GoroutineStacktrace, error) {
+	var out GoroutinesStacktracesOut
+	err := c.call("GoroutinesStacktraces", GoroutinesStacktracesIn{depth, opts}, &out)
+	return out.Stacktraces, err
+}
+
+func (c *RPCClient) ThreadsStacktraces(depth int) ([]api.ThreadStacktrace, error) {
+	var out ThreadsStacktracesOut
+	err := c.call("ThreadsStacktraces", ThreadsStacktracesIn{depth}, &out)
+	return out.Stacktraces, err
+}
+
+func (c *RPCClient) GetOutput(stdoutOffset, stderrOffset int64) (stdout, stderr api.TargetOutput, err error) {
+	var out GetOutputOut
+	err = c.call("GetOutput", GetOutputIn{stdoutOffset, stderrOffset}, &out)
+	return out.Stdout, out.Stderr, err
+}
+
+// GetTargetInfo returns information about the target process.
+func (c *RPCClient) GetTargetInfo() (*api.TargetInfo, error) {
+	var out GetTargetInfoOut
+	err := c.call("GetTargetInfo", GetTargetInfoIn{}, &out)
+	return &out.TargetInfo, err
+}
+
+// GetBuildInfo returns the build information of the executable of the target.
+func (c *RPCClient) GetBuildInfo() (*api.BuildInfo, error) {
+	var out GetBuildInfoOut
+	err := c.call("GetBuildInfo", GetBuildInfoIn{}, &out)
+	return &out.BuildInfo, err
+}
+
 func (c *RPCClient) Ancestors(goroutineID int, numAncestors int, depth int) ([]api.Ancestor, error) {
 	var out AncestorsOut
 	err := c.call("Ancestors", AncestorsIn{goroutineID, numAncestors, depth}, &out)
@@ -442,6 +626,77 @@ func (c *RPCClient) ClearCheckpoint(id int) error {
 	return err
 }
 
+// FreezeGoroutine prevents a goroutine from running when the target is
+// resumed.
+func (c *RPCClient) FreezeGoroutine(id int) error {
+	var out FreezeGoroutineOut
+	return c.call("FreezeGoroutine", FreezeGoroutineIn{id}, &out)
+}
+
+// ThawGoroutine lets a frozen goroutine run again.
+func (c *RPCClient) ThawGoroutine(id int) error {
+	var out ThawGoroutineOut
+	return c.call("ThawGoroutine", ThawGoroutineIn{id}, &out)
+}
+
+// StartCoverage starts recording which lines of the source files matching
+// filter are executed.
+func (c *RPCClient) StartCoverage(filter string) (int, error) {
+	var out StartCoverageOut
+	err := c.call("StartCoverage", StartCoverageIn{filter}, &out)
+	return out.Lines, err
+}
+
+// StopCoverage stops recording executed lines.
+func (c *RPCClient) StopCoverage() error {
+	var out StopCoverageOut
+	return c.call("StopCoverage", StopCoverageIn{}, &out)
+}
+
+// GetCoverage returns the lines instrumented by StartCoverage.
+func (c *RPCClient) GetCoverage() ([]api.CoverageLine, error) {
+	var out GetCoverageOut
+	err := c.call("GetCoverage", GetCoverageIn{}, &out)
+	return out.Lines, err
+}
+
+// StartBranchTrace starts recording the control flow of the target's
+// threads with the hardware branch tracer.
+func (c *RPCClient) StartBranchTrace() error {
+	var out StartBranchTraceOut
+	return c.call("StartBranchTrace", StartBranchTraceIn{}, &out)
+}
+
+// GetBranchTrace returns the calls, returns and jumps between functions
+// recorded for thread threadID, or the current thread if it is 0.
+func (c *RPCClient) GetBranchTrace(threadID int) ([]api.BranchTraceEvent, error) {
+	var out GetBranchTraceOut
+	err := c.call("GetBranchTrace", GetBranchTraceIn{threadID}, &out)
+	return out.Events, err
+}
+
+// StartRuntimeTrace starts the execution tracer of the target's runtime,
+// writing the trace to path.
+func (c *RPCClient) StartRuntimeTrace(path string) error {
+	var out StartRuntimeTraceOut
+	return c.call("StartRuntimeTrace", StartRuntimeTraceIn{path}, &out)
+}
+
+// StopRuntimeTrace stops the execution trace started by StartRuntimeTrace
+// and returns the path of the trace file.
+func (c *RPCClient) StopRuntimeTrace() (string, error) {
+	var out StopRuntimeTraceOut
+	err := c.call("StopRuntimeTrace", StopRuntimeTraceIn{}, &out)
+	return out.Path, err
+}
+
+// Profile collects a profile of the target and writes it to path.
+func (c *RPCClient) Profile(kind api.ProfileKind, duration time.Duration, path string) (*api.DebuggerState, error) {
+	var out ProfileOut
+	err := c.call("Profile", ProfileIn{kind, duration, path}, &out)
+	return &out.State, err
+}
+
 func (c *RPCClient) SetReturnValuesLoadConfig(cfg *api.LoadConfig) {
 	c.retValLoadCfg = cfg
 }
@@ -458,11 +713,88 @@ func (c *RPCClient) IsMulticlient() bool {
 	return out.IsMulticlient
 }
 
+// GetVersion returns the version of Delve and of the target.
+func (c *RPCClient) GetVersion() (*api.GetVersionOut, error) {
+	var out api.GetVersionOut
+	err := c.call("GetVersion", api.GetVersionIn{}, &out)
+	return &out, err
+}
+
+// ListClients returns the clients connected to the server and the ID of
+// this client.
+func (c *RPCClient) ListClients() ([]api.ClientInfo, int, error) {
+	var out api.ListClientsOut
+	err := c.call("ListClients", api.ListClientsIn{}, &out)
+	return out.Clients, out.Self, err
+}
+
+// HandOff releases control of the session, handing it to client to if it
+// isn't zero.
+func (c *RPCClient) HandOff(to int) error {
+	var out api.HandOffOut
+	return c.call("HandOff", api.HandOffIn{To: to}, &out)
+}
+
+// WaitStateChange waits for the state of the target to change after
+// sequence number seq.
+func (c *RPCClient) WaitStateChange(seq int) (*api.WaitStateChangeOut, error) {
+	var out api.WaitStateChangeOut
+	err := c.call("WaitStateChange", api.WaitStateChangeIn{Seq: seq}, &out)
+	return &out, err
+}
+
+// AddWatchExpression adds expr to the expressions evaluated by the server
+// every time the target stops.
+func (c *RPCClient) AddWatchExpression(expr string, cfg api.LoadConfig) (*api.WatchExpression, error) {
+	var out AddWatchExpressionOut
+	err := c.call("AddWatchExpression", AddWatchExpressionIn{expr, cfg}, &out)
+	return out.Expr, err
+}
+
+// RemoveWatchExpression removes the watch expression with the given ID.
+func (c *RPCClient) RemoveWatchExpression(id int) error {
+	var out RemoveWatchExpressionOut
+	return c.call("RemoveWatchExpression", RemoveWatchExpressionIn{id}, &out)
+}
+
+// ListWatchExpressions returns the watch expressions with their values at
+// the last stop.
+func (c *RPCClient) ListWatchExpressions() ([]api.WatchExpression, error) {
+	var out ListWatchExpressionsOut
+	err := c.call("ListWatchExpressions", ListWatchExpressionsIn{}, &out)
+	return out.Exprs, err
+}
+
+// SetCommandHooks replaces the hooks of command stored by the server.
+func (c *RPCClient) SetCommandHooks(command string, hooks api.CommandHooks) error {
+	var out SetCommandHooksOut
+	return c.call("SetCommandHooks", SetCommandHooksIn{command, hooks}, &out)
+}
+
+// ListCommandHooks returns the hooks stored by the server.
+func (c *RPCClient) ListCommandHooks() (map[string]api.CommandHooks, error) {
+	var out ListCommandHooksOut
+	err := c.call("ListCommandHooks", ListCommandHooksIn{}, &out)
+	return out.Hooks, err
+}
+
+// WaitWatchExpressions waits for the watch expressions or their values to
+// change after sequence number seq.
+func (c *RPCClient) WaitWatchExpressions(seq int) (*api.WaitWatchExpressionsOut, error) {
+	var out api.WaitWatchExpressionsOut
+	err := c.call("WaitWatchExpressions", api.WaitWatchExpressionsIn{Seq: seq}, &out)
+	return &out, err
+}
+
 func (c *RPCClient) Disconnect(cont bool) error {
 	if cont {
 		out := new(CommandOut)
 		c.client.Go("RPCServer.Command", &api.DebuggerCommand{Name: api.Continue, ReturnInfoLoadConfig: c.retValLoadCfg}, &out, nil)
 	}
+	// Release control of the session before closing the connection, so that
+	// other clients can drive it as soon as Disconnect returns. The error is
+	// ignored, this client may not be the driver.
+	c.call("HandOff", api.HandOffIn{}, &api.HandOffOut{})
 	return c.client.Close()
 }
 
@@ -170,16 +170,39 @@ type StacktraceIn struct {
 	Defers bool // read deferred functions (equivalent to passing StacktraceReadDefers in Opts)
 	Opts   api.StacktraceOptions
 	Cfg    *api.LoadConfig
+	// Skip is the number of frames, starting from the top of the stack,
+	// that will not be returned. Used together with Depth it lets clients
+	// retrieve a long stacktrace in chunks.
+	Skip int
+	// ThreadID, if not 0, selects the thread whose stacktrace is returned,
+	// instead of the goroutine Id.
+	ThreadID int
 }
 
 type StacktraceOut struct {
 	Locations []api.Stackframe
 }
 
+// maxStacktraceDepth is the depth used when all frames of a stacktrace are
+// requested, with a negative depth.
+const maxStacktraceDepth = 1000
+
 // Stacktrace returns stacktrace of goroutine Id up to the specified Depth.
+// If Depth is negative all frames are returned, up to maxStacktraceDepth+1.
+// The last frame returned has Bottom set if the stacktrace was not
+// truncated.
 //
 // If Full is set it will also the variable of all local variables
 // and function arguments of all stack frames.
+//
+// If Skip is set the first Skip frames are omitted from the result and
+// Depth is counted starting from the first returned frame, variables are
+// only loaded for the returned frames. The frames of a goroutine are
+// cached until the target resumes, retrieving a stack in chunks does not
+// unwind it from the top for each chunk.
+//
+// If ThreadID is set the stacktrace of that thread is returned and Id is
+// ignored, this does not change the current thread.
 func (s *RPCServer) Stacktrace(arg StacktraceIn, out *StacktraceOut) error {
 	cfg := arg.Cfg
 	if cfg == nil && arg.Full {
@@ -189,14 +212,133 @@ func (s *RPCServer) Stacktrace(arg StacktraceIn, out *StacktraceOut) error {
 		arg.Opts |= api.StacktraceReadDefers
 	}
 	var err error
-	rawlocs, err := s.debugger.Stacktrace(arg.Id, arg.Depth, arg.Opts)
+	if arg.Skip < 0 {
+		return fmt.Errorf("invalid skip %d", arg.Skip)
+	}
+	depth := arg.Depth
+	if depth < 0 {
+		depth = maxStacktraceDepth
+	}
+	var rawlocs []proc.Stackframe
+	if arg.ThreadID != 0 {
+		rawlocs, err = s.debugger.ThreadStacktrace(arg.ThreadID, arg.Skip+depth)
+	} else {
+		rawlocs, err = s.debugger.Stacktrace(arg.Id, arg.Skip+depth, arg.Opts)
+	}
 	if err != nil {
 		return err
 	}
+	if arg.Skip >= len(rawlocs) {
+		rawlocs = rawlocs[:0]
+	} else {
+		rawlocs = rawlocs[arg.Skip:]
+	}
 	out.Locations, err = s.debugger.ConvertStacktrace(rawlocs, api.LoadConfigToProc(cfg))
 	return err
 }
 
+type GoroutinesStacktracesIn struct {
+	Depth int
+	Opts  api.StacktraceOptions
+}
+
+type GoroutinesStacktracesOut struct {
+	Stacktraces []api.GoroutineStacktrace
+}
+
+// GoroutinesStacktraces returns the stacktraces of all goroutines, up to
+// Depth frames each, or maxStacktraceDepth+1 if Depth is negative. It is
+// much faster than calling Stacktrace for each goroutine when the target
+// has many goroutines.
+func (s *RPCServer) GoroutinesStacktraces(arg GoroutinesStacktracesIn, out *GoroutinesStacktracesOut) error {
+	depth := arg.Depth
+	if depth < 0 {
+		depth = maxStacktraceDepth
+	}
+	var err error
+	out.Stacktraces, err = s.debugger.GoroutinesStacktraces(depth, arg.Opts)
+	return err
+}
+
+type ThreadsStacktracesIn struct {
+	Depth int
+}
+
+type ThreadsStacktracesOut struct {
+	Stacktraces []api.ThreadStacktrace
+}
+
+// ThreadsStacktraces returns the stacktraces of all threads, up to Depth
+// frames each, or maxStacktraceDepth+1 if Depth is negative.
+func (s *RPCServer) ThreadsStacktraces(arg ThreadsStacktracesIn, out *ThreadsStacktracesOut) error {
+	depth := arg.Depth
+	if depth < 0 {
+		depth = maxStacktraceDepth
+	}
+	var err error
+	out.Stacktraces, err = s.debugger.ThreadsStacktraces(depth)
+	return err
+}
+
+type GetOutputIn struct {
+	// StdoutOffset and StderrOffset are the positions in the standard
+	// output and standard error of the target from which to read.
+	StdoutOffset int64
+	StderrOffset int64
+}
+
+type GetOutputOut struct {
+	Stdout api.TargetOutput
+	Stderr api.TargetOutput
+}
+
+// GetOutput returns the standard output and standard error of the target
+// captured by the debugger, starting at the specified offsets. The output
+// is only captured if the debugger was started with the CaptureOutput
+// option, only the most recent output is retained.
+func (s *RPCServer) GetOutput(arg GetOutputIn, out *GetOutputOut) error {
+	var err error
+	out.Stdout, out.Stderr, err = s.debugger.GetOutput(arg.StdoutOffset, arg.StderrOffset)
+	return err
+}
+
+type GetTargetInfoIn struct {
+}
+
+type GetTargetInfoOut struct {
+	TargetInfo api.TargetInfo
+}
+
+// GetTargetInfo returns the executable, command line, working directory,
+// start time and Go version of the target.
+func (s *RPCServer) GetTargetInfo(arg GetTargetInfoIn, out *GetTargetInfoOut) error {
+	info, err := s.debugger.TargetInfo()
+	if err != nil {
+		return err
+	}
+	out.TargetInfo = *info
+	return nil
+}
+
+type GetBuildInfoIn struct {
+}
+
+type GetBuildInfoOut struct {
+	BuildInfo api.BuildInfo
+}
+
+// GetBuildInfo returns the build information embedded in the executable
+// of the target: the Go version, module versions and build settings,
+// such as the VCS revision.
+func (s *RPCServer) GetBuildInfo(arg GetBuildInfoIn, out *GetBuildInfoOut) error {
+	info, err := s.debugger.BuildInfo()
+	if err != nil {
+		return err
+	}
+	out.BuildInfo = *info
+	return nil
+}
+
 type AncestorsIn struct {
 	GoroutineID  int
 	NumAncestors int
@@ -214,16 +356,129 @@ func (s *RPCServer) Ancestors(arg AncestorsIn, out *AncestorsOut) error {
 	return err
 }
 
+type SearchMemoryIn struct {
+	Pattern []byte
+	// Start and End delimit the searched memory, if End is zero all the
+	// readable memory of the target is searched.
+	Start, End uint64
+	// Max is the maximum number of results returned, zero means no limit.
+	Max int
+}
+
+type SearchMemoryOut struct {
+	Results []api.MemorySearchResult
+}
+
+// SearchMemory searches a sequence of bytes in the memory of the target.
+// The search can be interrupted with CancelRequest.
+func (s *RPCServer) SearchMemory(arg SearchMemoryIn, out *SearchMemoryOut) error {
+	var err error
+	out.Results, err = s.debugger.SearchMemory(arg.Pattern, arg.Start, arg.End, arg.Max)
+	return err
+}
+
+type ListMemoryRegionsIn struct {
+}
+
+type ListMemoryRegionsOut struct {
+	Regions []api.MemoryRegion
+}
+
+// ListMemoryRegions lists the memory mappings of the target, sorted by
+// address, with their permissions and backing files. Regions belonging
+// to the executable or its shared libraries, to the Go heap and to
+// goroutine stacks are annotated.
+func (s *RPCServer) ListMemoryRegions(arg ListMemoryRegionsIn, out *ListMemoryRegionsOut) error {
+	var err error
+	out.Regions, err = s.debugger.MemoryRegions()
+	return err
+}
+
+type EnvironIn struct {
+}
+
+type EnvironOut struct {
+	Env []string
+}
+
+// Environ returns the environment of the target, in the form
+// "key=value", as returned by os.Environ.
+func (s *RPCServer) Environ(arg EnvironIn, out *EnvironOut) error {
+	var err error
+	out.Env, err = s.debugger.Environ()
+	return err
+}
+
+type SetEnvIn struct {
+	Key   string
+	Value string
+	// Unset removes the variable from the environment instead of setting
+	// it, Value is ignored.
+	Unset bool
+}
+
+type SetEnvOut struct {
+}
+
+// SetEnv sets an environment variable of the target. The variable is set
+// by calling os.Setenv (or os.Unsetenv) on the selected goroutine, the
+// same restrictions that apply to the call command apply.
+func (s *RPCServer) SetEnv(arg SetEnvIn, out *SetEnvOut) error {
+	return s.debugger.SetEnv(arg.Key, arg.Value, arg.Unset)
+}
+
+type ListFileDescriptorsIn struct {
+}
+
+type ListFileDescriptorsOut struct {
+	FileDescriptors []api.FileDescriptor
+}
+
+// ListFileDescriptors returns the open file descriptors of the target
+// process, with the addresses of sockets and the goroutines blocked
+// waiting for each file descriptor to become ready.
+// Only supported on linux.
+func (s *RPCServer) ListFileDescriptors(arg ListFileDescriptorsIn, out *ListFileDescriptorsOut) error {
+	var err error
+	out.FileDescriptors, err = s.debugger.FileDescriptors()
+	return err
+}
+
+type ListTimersIn struct {
+}
+
+type ListTimersOut struct {
+	Timers []api.Timer
+}
+
+// ListTimers returns the pending timers of the runtime, created for
+// example by time.NewTimer, time.AfterFunc and time.Sleep, sorted by the
+// time at which they fire.
+func (s *RPCServer) ListTimers(arg ListTimersIn, out *ListTimersOut) error {
+	var err error
+	out.Timers, err = s.debugger.Timers()
+	return err
+}
+
 type ListBreakpointsIn struct {
+	// All also returns the breakpoints set internally by Delve, for
+	// example by next and step, with ID 0.
+	All bool
 }
 
 type ListBreakpointsOut struct {
 	Breakpoints []*api.Breakpoint
 }
 
-// ListBreakpoints gets all breakpoints.
+// ListBreakpoints gets all breakpoints, enabled and disabled, sorted by ID.
+// If arg.All is true the breakpoints set internally by Delve are returned
+// after them, see Debugger.Breakpoints.
 func (s *RPCServer) ListBreakpoints(arg ListBreakpointsIn, out *ListBreakpointsOut) error {
-	out.Breakpoints = s.debugger.Breakpoints()
+	if arg.All {
+		out.Breakpoints = s.debugger.AllBreakpoints()
+	} else {
+		out.Breakpoints = s.debugger.Breakpoints()
+	}
 	return nil
 }
 
@@ -243,6 +498,11 @@ func (s *RPCServer) CreateBreakpoint(arg CreateBreakpointIn, out *CreateBreakpoi
 	if err := api.ValidBreakpointName(arg.Breakpoint.Name); err != nil {
 		return err
 	}
+	for _, group := range arg.Breakpoint.Groups {
+		if err := api.ValidBreakpointGroup(group); err != nil {
+			return err
+		}
+	}
 	createdbp, err := s.debugger.CreateBreakpoint(&arg.Breakpoint)
 	if err != nil {
 		return err
@@ -318,6 +578,57 @@ func (s *RPCServer) ToggleBreakpoint(arg ToggleBreakpointIn, out *ToggleBreakpoi
 	return nil
 }
 
+type AmendBreakpointGroupIn struct {
+	Group    string
+	Disabled bool
+}
+
+type AmendBreakpointGroupOut struct {
+	// Breakpoints are the breakpoints that were enabled or disabled.
+	Breakpoints []*api.Breakpoint
+}
+
+// AmendBreakpointGroup enables (or disables, if Disabled is true) all the
+// breakpoints belonging to Group, see api.Breakpoint.Groups. Watchpoints
+// can not be disabled and are skipped.
+func (s *RPCServer) AmendBreakpointGroup(arg AmendBreakpointGroupIn, out *AmendBreakpointGroupOut) error {
+	var err error
+	out.Breakpoints, err = s.debugger.AmendBreakpointGroup(arg.Group, arg.Disabled)
+	return err
+}
+
+type ClearBreakpointGroupIn struct {
+	Group string
+}
+
+type ClearBreakpointGroupOut struct {
+	Breakpoints []*api.Breakpoint
+}
+
+// ClearBreakpointGroup deletes all the breakpoints belonging to Group.
+func (s *RPCServer) ClearBreakpointGroup(arg ClearBreakpointGroupIn, out *ClearBreakpointGroupOut) error {
+	var err error
+	out.Breakpoints, err = s.debugger.ClearBreakpointGroup(arg.Group)
+	return err
+}
+
+type ClearBreakpointsIn struct {
+	Filter api.BreakpointFilter
+}
+
+type ClearBreakpointsOut struct {
+	Breakpoints []*api.Breakpoint
+}
+
+// ClearBreakpoints deletes all the breakpoints selected by Filter, see
+// api.BreakpointFilter, in one operation. The breakpoints set internally
+// by Delve, like the one on unrecovered panics, are not deleted.
+func (s *RPCServer) ClearBreakpoints(arg ClearBreakpointsIn, out *ClearBreakpointsOut) error {
+	var err error
+	out.Breakpoints, err = s.debugger.ClearBreakpoints(arg.Filter)
+	return err
+}
+
 type AmendBreakpointIn struct {
 	Breakpoint api.Breakpoint
 }
@@ -334,6 +645,11 @@ func (s *RPCServer) AmendBreakpoint(arg AmendBreakpointIn, out *AmendBreakpointO
 	if err := api.ValidBreakpointName(arg.Breakpoint.Name); err != nil {
 		return err
 	}
+	for _, group := range arg.Breakpoint.Groups {
+		if err := api.ValidBreakpointGroup(group); err != nil {
+			return err
+		}
+	}
 	return s.debugger.AmendBreakpoint(&arg.Breakpoint)
 }
 
@@ -347,6 +663,21 @@ func (s *RPCServer) CancelNext(arg CancelNextIn, out *CancelNextOut) error {
 	return s.debugger.CancelNext()
 }
 
+type CancelRequestIn struct {
+}
+
+type CancelRequestOut struct {
+}
+
+// CancelRequest interrupts the long running API call currently in
+// progress, for example Eval, ListGoroutines, ListPackageVars or a
+// Stacktrace call with Full set, which will return an error.
+// Calling CancelRequest when no call is in progress has no effect.
+func (s *RPCServer) CancelRequest(arg CancelRequestIn, out *CancelRequestOut) error {
+	s.debugger.CancelRequest()
+	return nil
+}
+
 type ListThreadsIn struct {
 }
 
@@ -392,6 +723,10 @@ func (s *RPCServer) GetThread(arg GetThreadIn, out *GetThreadOut) error {
 type ListPackageVarsIn struct {
 	Filter string
 	Cfg    api.LoadConfig
+	// Package, if not empty, restricts the result to variables of the
+	// specified package. The special value "." selects the package of the
+	// function the current thread is stopped in.
+	Package string
 }
 
 type ListPackageVarsOut struct {
@@ -400,7 +735,7 @@ type ListPackageVarsOut struct {
 
 // ListPackageVars lists all package variables in the context of the current thread.
 func (s *RPCServer) ListPackageVars(arg ListPackageVarsIn, out *ListPackageVarsOut) error {
-	vars, err := s.debugger.PackageVariables(arg.Filter, *api.LoadConfigToProc(&arg.Cfg))
+	vars, err := s.debugger.PackageVariables(arg.Package, arg.Filter, *api.LoadConfigToProc(&arg.Cfg))
 	if err != nil {
 		return err
 	}
@@ -421,16 +756,9 @@ type ListRegistersOut struct {
 
 // ListRegisters lists registers and their values.
 // If ListRegistersIn.Scope is not nil the registers of that eval scope will
-// be returned, otherwise ListRegistersIn.ThreadID will be used.
+// be returned, otherwise ListRegistersIn.ThreadID will be used, or the
+// current thread if ThreadID is 0.
 func (s *RPCServer) ListRegisters(arg ListRegistersIn, out *ListRegistersOut) error {
-	if arg.ThreadID == 0 && arg.Scope == nil {
-		state, err := s.debugger.State(false)
-		if err != nil {
-			return err
-		}
-		arg.ThreadID = state.CurrentThread.ID
-	}
-
 	var regs *op.DwarfRegisters
 	var err error
 
@@ -500,6 +828,9 @@ type EvalOut struct {
 //
 // See https://github.com/go-delve/delve/blob/master/Documentation/cli/expr.md
 // for a description of acceptable values of arg.Expr.
+//
+// Expressions containing function calls are rejected, use Command with
+// the api.Call command to evaluate them.
 func (s *RPCServer) Eval(arg EvalIn, out *EvalOut) error {
 	cfg := arg.Cfg
 	if cfg == nil {
@@ -522,8 +853,10 @@ type SetIn struct {
 type SetOut struct {
 }
 
-// Set sets the value of a variable. Only numerical types and
-// pointers are currently supported.
+// Set sets the value of a variable. Numerical, boolean, pointer and
+// string variables are supported. String literals are allocated in the
+// target with an injected function call, which resumes it, this is only
+// possible in the topmost frame of a goroutine running on a thread.
 func (s *RPCServer) Set(arg SetIn, out *SetOut) error {
 	return s.debugger.SetVariableInScope(arg.Scope.GoroutineID, arg.Scope.Frame, arg.Scope.DeferredCall, arg.Symbol, arg.Value)
 }
@@ -618,6 +951,11 @@ type ListGoroutinesOut struct {
 //    ListGoroutineFilter{ Kind: ListGoroutinesFilterLabel, Negated: false, Arg: "key=value" }
 // this filter will only return goroutines that have a key=value label.
 //
+// Goroutines of the runtime that aren't executing code of the program
+// (garbage collector workers, the finalizer goroutine, etc) can be hidden
+// with:
+//    ListGoroutineFilter{ Kind: ListGoroutinesFilterRuntime, Negated: true }
+//
 // If arg.GroupBy is not GoroutineFieldNone then the goroutines will
 // be grouped with the specified criterion.
 // If the value of arg.GroupBy is GoroutineLabel goroutines will
@@ -780,6 +1118,178 @@ func (s *RPCServer) ClearCheckpoint(arg ClearCheckpointIn, out *ClearCheckpointO
 	return s.debugger.ClearCheckpoint(arg.ID)
 }
 
+type FreezeGoroutineIn struct {
+	ID int
+}
+
+type FreezeGoroutineOut struct {
+}
+
+// FreezeGoroutine prevents a goroutine from running when the target is
+// resumed, until ThawGoroutine is called, by keeping the thread running
+// it stopped once it reaches its next instruction.
+func (s *RPCServer) FreezeGoroutine(arg FreezeGoroutineIn, out *FreezeGoroutineOut) error {
+	return s.debugger.FreezeGoroutine(arg.ID)
+}
+
+type ThawGoroutineIn struct {
+	ID int
+}
+
+type ThawGoroutineOut struct {
+}
+
+// ThawGoroutine lets a goroutine frozen by FreezeGoroutine run again.
+func (s *RPCServer) ThawGoroutine(arg ThawGoroutineIn, out *ThawGoroutineOut) error {
+	return s.debugger.ThawGoroutine(arg.ID)
+}
+
+type StartCoverageIn struct {
+	// Filter is a regular expression selecting the source files to
+	// instrument, if empty the files of the standard library, of the module
+	// cache and of vendor directories are excluded.
+	Filter string
+}
+
+type StartCoverageOut struct {
+	// Lines is the number of instrumented lines.
+	Lines int
+}
+
+// StartCoverage starts recording which lines of the selected source files
+// are executed. Previously collected coverage is discarded.
+func (s *RPCServer) StartCoverage(arg StartCoverageIn, out *StartCoverageOut) error {
+	var err error
+	out.Lines, err = s.debugger.StartCoverage(arg.Filter)
+	return err
+}
+
+type StopCoverageIn struct {
+}
+
+type StopCoverageOut struct {
+}
+
+// StopCoverage stops recording executed lines, the coverage collected so
+// far is still returned by GetCoverage.
+func (s *RPCServer) StopCoverage(arg StopCoverageIn, out *StopCoverageOut) error {
+	return s.debugger.StopCoverage()
+}
+
+type GetCoverageIn struct {
+}
+
+type GetCoverageOut struct {
+	Lines []api.CoverageLine
+}
+
+// GetCoverage returns the lines instrumented by StartCoverage, sorted by
+// file and line number, and whether they were executed.
+func (s *RPCServer) GetCoverage(arg GetCoverageIn, out *GetCoverageOut) error {
+	lines, err := s.debugger.Coverage()
+	if err != nil {
+		return err
+	}
+	out.Lines = make([]api.CoverageLine, len(lines))
+	for i := range lines {
+		out.Lines[i] = api.CoverageLine(lines[i])
+	}
+	return nil
+}
+
+type StartBranchTraceIn struct {
+}
+
+type StartBranchTraceOut struct {
+}
+
+// StartBranchTrace starts recording the control flow of the target's
+// threads with the hardware branch tracer of the CPU, currently only Intel
+// Processor Trace on linux/amd64 with the native backend is supported.
+func (s *RPCServer) StartBranchTrace(arg StartBranchTraceIn, out *StartBranchTraceOut) error {
+	return s.debugger.StartBranchTrace()
+}
+
+type GetBranchTraceIn struct {
+	// ThreadID is the thread whose trace is returned, 0 for the current
+	// thread.
+	ThreadID int
+}
+
+type GetBranchTraceOut struct {
+	Events []api.BranchTraceEvent
+}
+
+// GetBranchTrace returns the calls, returns and jumps between functions
+// executed by a thread before reaching its current position, oldest first.
+// Only the most recent part of the trace is kept.
+func (s *RPCServer) GetBranchTrace(arg GetBranchTraceIn, out *GetBranchTraceOut) error {
+	var err error
+	out.Events, err = s.debugger.BranchTrace(arg.ThreadID)
+	return err
+}
+
+type StartRuntimeTraceIn struct {
+	// Path of the trace file, relative to the working directory of the
+	// target.
+	Path string
+}
+
+type StartRuntimeTraceOut struct {
+}
+
+// StartRuntimeTrace starts the execution tracer of the target's runtime,
+// by calling runtime/trace.Start on the selected goroutine. The trace is
+// written to the specified file until StopRuntimeTrace is called.
+// The target must import runtime/trace.
+func (s *RPCServer) StartRuntimeTrace(arg StartRuntimeTraceIn, out *StartRuntimeTraceOut) error {
+	return s.debugger.StartRuntimeTrace(arg.Path)
+}
+
+type StopRuntimeTraceIn struct {
+}
+
+type StopRuntimeTraceOut struct {
+	// Path of the trace file.
+	Path string
+}
+
+// StopRuntimeTrace stops the execution trace started by StartRuntimeTrace.
+func (s *RPCServer) StopRuntimeTrace(arg StopRuntimeTraceIn, out *StopRuntimeTraceOut) error {
+	var err error
+	out.Path, err = s.debugger.StopRuntimeTrace()
+	return err
+}
+
+type ProfileIn struct {
+	Kind api.ProfileKind
+	// Duration is the time the target runs while a CPU profile is
+	// collected.
+	Duration time.Duration
+	// Path of the profile, relative to the working directory of the target.
+	Path string
+}
+
+type ProfileOut struct {
+	State api.DebuggerState
+}
+
+// Profile collects a profile of the target by calling functions of
+// runtime/pprof, the profile is written to the specified file.
+// To collect a CPU profile the target is resumed and stopped again after
+// Duration. The target must import runtime/pprof.
+func (s *RPCServer) Profile(arg ProfileIn, cb service.RPCCallback) {
+	close(cb.SetupDoneChan())
+	st, err := s.debugger.Profile(arg.Kind, arg.Duration, arg.Path)
+	if err != nil {
+		cb.Return(nil, err)
+		return
+	}
+	var out ProfileOut
+	out.State = *st
+	cb.Return(out, nil)
+}
+
 type IsMulticlientIn struct {
 }
 
@@ -894,6 +1404,9 @@ type ExaminedMemoryOut struct {
 	IsLittleEndian bool
 }
 
+// ExamineMemory reads Length bytes of target memory starting at Address.
+// At most 1000 bytes can be read with a single call, clients that need to
+// read more should issue multiple calls for consecutive chunks.
 func (s *RPCServer) ExamineMemory(arg ExamineMemoryIn, out *ExaminedMemoryOut) error {
 	if arg.Length > 1000 {
 		return fmt.Errorf("len must be less than or equal to 1000")
@@ -975,6 +1488,10 @@ type CreateWatchpointIn struct {
 	Scope api.EvalScope
 	Expr  string
 	Type  api.WatchType
+	// History, if greater than zero, is the number of writes recorded by
+	// the watchpoint, which will not stop the target. Type must include
+	// api.WatchWrite.
+	History int
 }
 
 type CreateWatchpointOut struct {
@@ -983,6 +1500,173 @@ type CreateWatchpointOut struct {
 
 func (s *RPCServer) CreateWatchpoint(arg CreateWatchpointIn, out *CreateWatchpointOut) error {
 	var err error
-	out.Breakpoint, err = s.debugger.CreateWatchpoint(arg.Scope.GoroutineID, arg.Scope.Frame, arg.Scope.DeferredCall, arg.Expr, arg.Type)
+	out.Breakpoint, err = s.debugger.CreateWatchpoint(arg.Scope.GoroutineID, arg.Scope.Frame, arg.Scope.DeferredCall, arg.Expr, arg.Type, arg.History)
+	return err
+}
+
+type CreateChanWatchpointIn struct {
+	Scope api.EvalScope
+	// Expr is an expression evaluating to a channel.
+	Expr string
+}
+
+type CreateChanWatchpointOut struct {
+	*api.Breakpoint
+}
+
+// CreateChanWatchpoint creates a breakpoint that stops when any goroutine
+// sends to or receives from the channel arg.Expr evaluates to.
+// The target will stop inside the runtime function implementing the
+// operation.
+func (s *RPCServer) CreateChanWatchpoint(arg CreateChanWatchpointIn, out *CreateChanWatchpointOut) error {
+	var err error
+	out.Breakpoint, err = s.debugger.CreateChanWatchpoint(arg.Scope.GoroutineID, arg.Scope.Frame, arg.Scope.DeferredCall, arg.Expr)
+	return err
+}
+
+type MutexStateIn struct {
+	Scope api.EvalScope
+	// Expr is an expression evaluating to a sync.Mutex, a sync.RWMutex or
+	// a pointer to one of them.
+	Expr string
+}
+
+type MutexStateOut struct {
+	State *api.MutexState
+}
+
+// MutexState decodes the state of a sync.Mutex or sync.RWMutex and lists
+// the goroutines waiting on it, as well as the goroutines that probably
+// hold it.
+func (s *RPCServer) MutexState(arg MutexStateIn, out *MutexStateOut) error {
+	var err error
+	out.State, err = s.debugger.MutexState(arg.Scope.GoroutineID, arg.Scope.Frame, arg.Scope.DeferredCall, arg.Expr)
+	return err
+}
+
+type ChanStateIn struct {
+	Scope api.EvalScope
+	// Expr is an expression evaluating to a channel or a pointer to a
+	// channel.
+	Expr string
+	// Cfg is the load configuration of the buffered elements, at most
+	// Cfg.MaxArrayValues elements are returned.
+	Cfg api.LoadConfig
+}
+
+type ChanStateOut struct {
+	State *api.ChanState
+}
+
+// ChanState lists the elements in the buffer of a channel, in the order
+// they will be received, and the goroutines blocked sending to it or
+// receiving from it.
+func (s *RPCServer) ChanState(arg ChanStateIn, out *ChanStateOut) error {
+	var err error
+	out.State, err = s.debugger.ChanState(arg.Scope.GoroutineID, arg.Scope.Frame, arg.Scope.DeferredCall, arg.Expr, *api.LoadConfigToProc(&arg.Cfg))
+	return err
+}
+
+type AddWatchExpressionIn struct {
+	Expr string
+	// Cfg is the load configuration used to evaluate Expr.
+	Cfg api.LoadConfig
+}
+
+type AddWatchExpressionOut struct {
+	Expr *api.WatchExpression
+}
+
+// AddWatchExpression adds an expression to the list of expressions the
+// server evaluates in the selected goroutine every time the target stops.
+// Clients can wait for their new values with WaitWatchExpressions instead
+// of evaluating each of them after every stop.
+func (s *RPCServer) AddWatchExpression(arg AddWatchExpressionIn, out *AddWatchExpressionOut) error {
+	var err error
+	out.Expr, err = s.debugger.AddWatchExpression(arg.Expr, *api.LoadConfigToProc(&arg.Cfg))
+	return err
+}
+
+type RemoveWatchExpressionIn struct {
+	ID int
+}
+
+type RemoveWatchExpressionOut struct {
+}
+
+// RemoveWatchExpression removes a watch expression added by
+// AddWatchExpression.
+func (s *RPCServer) RemoveWatchExpression(arg RemoveWatchExpressionIn, out *RemoveWatchExpressionOut) error {
+	return s.debugger.RemoveWatchExpression(arg.ID)
+}
+
+type ListWatchExpressionsIn struct {
+}
+
+type ListWatchExpressionsOut struct {
+	Exprs []api.WatchExpression
+	// Seq is the sequence number to pass to WaitWatchExpressions to wait
+	// for the next change.
+	Seq int
+}
+
+// ListWatchExpressions returns the watch expressions with their values at
+// the last stop.
+func (s *RPCServer) ListWatchExpressions(arg ListWatchExpressionsIn, out *ListWatchExpressionsOut) error {
+	out.Seq, out.Exprs, _ = s.debugger.WatchExpressions()
+	return nil
+}
+
+type SetCommandHooksIn struct {
+	// Command is the name of the command, not one of its aliases.
+	Command string
+	Hooks   api.CommandHooks
+}
+
+type SetCommandHooksOut struct {
+}
+
+// SetCommandHooks replaces the commands the terminal clients of the server
+// execute automatically before and after Command, empty Hooks remove them.
+// The hooks are stored by the server so that they are shared by all its
+// clients, they are executed by the clients because commands are
+// implemented client side.
+func (s *RPCServer) SetCommandHooks(arg SetCommandHooksIn, out *SetCommandHooksOut) error {
+	if arg.Command == "" {
+		return errors.New("no command specified")
+	}
+	s.debugger.SetCommandHooks(arg.Command, arg.Hooks)
+	return nil
+}
+
+type ListCommandHooksIn struct {
+}
+
+type ListCommandHooksOut struct {
+	// Hooks are indexed by command name.
+	Hooks map[string]api.CommandHooks
+}
+
+// ListCommandHooks returns the hooks set with SetCommandHooks.
+func (s *RPCServer) ListCommandHooks(arg ListCommandHooksIn, out *ListCommandHooksOut) error {
+	out.Hooks = s.debugger.CommandHooks()
+	return nil
+}
+
+type WatchHistoryIn struct {
+	// ID of the watchpoint or breakpoint.
+	ID int
+}
+
+type WatchHistoryOut struct {
+	Entries []api.WatchHistoryEntry
+}
+
+// WatchHistory returns the writes recorded by a watchpoint created with
+// CreateWatchpoint and a non-zero History, or the hits recorded by a
+// breakpoint with a non-zero WatchHistory, oldest first.
+func (s *RPCServer) WatchHistory(arg WatchHistoryIn, out *WatchHistoryOut) error {
+	var err error
+	out.Entries, err = s.debugger.WatchHistory(arg.ID)
 	return err
 }
@@ -3,6 +3,7 @@ package rpccommon
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -12,10 +13,12 @@ import (
 	"reflect"
 	"runtime"
 	"sync"
+	"time"
 	"unicode"
 	"unicode/utf8"
 
 	"github.com/go-delve/delve/pkg/logflags"
+	"github.com/go-delve/delve/pkg/metrics"
 	"github.com/go-delve/delve/pkg/version"
 	"github.com/go-delve/delve/service"
 	"github.com/go-delve/delve/service/api"
@@ -43,7 +46,41 @@ type ServerImpl struct {
 	s2 *rpc2.RPCServer
 	// maps of served methods, one for each supported API.
 	methodMaps []map[string]*methodType
-	log        *logrus.Entry
+	// maps of the methods served to observers, one for each supported API.
+	observerMethodMaps []map[string]*methodType
+	log                *logrus.Entry
+
+	// mu protects the fields below and config.DisconnectChan.
+	mu sync.Mutex
+	// activeRequests is the number of requests currently being executed.
+	activeRequests int
+	// idleTimer detaches from the target after config.IdleTimeout without
+	// client activity.
+	idleTimer *time.Timer
+	// clients are the clients currently connected, in connection order.
+	clients       []*clientConn
+	clientCounter int
+	// driver is the client that controls the target, only the driver can
+	// call methods that change the state of the target or of the debugger.
+	// If it is nil the first client calling one of those methods becomes
+	// the driver.
+	driver *clientConn
+	// stateSeq is incremented every time the state of the target or of
+	// the debugger could have changed, stateChangedBy is the ID of the
+	// client that caused the last change and stateChanged is closed and
+	// replaced every time stateSeq is incremented.
+	stateSeq       int
+	stateChangedBy int
+	stateChanged   chan struct{}
+}
+
+// clientConn is a client connected to the server.
+type clientConn struct {
+	id       int
+	addr     string
+	observer bool
+	// done is closed when the client disconnects.
+	done chan struct{}
 }
 
 type RPCCallback struct {
@@ -52,13 +89,21 @@ type RPCCallback struct {
 	codec     rpc.ServerCodec
 	req       rpc.Request
 	setupDone chan struct{}
+	// changedBy, if not nil, is the client that called a method changing
+	// the state of the target, other clients are notified when it returns.
+	changedBy *clientConn
+	// longPoll is true for the methods in longPollMethods, they stop
+	// counting as client activity once their setup is done.
+	longPoll bool
 }
 
 var _ service.RPCCallback = &RPCCallback{}
 
-// RPCServer implements the RPC method calls common to all versions of the API.
+// RPCServer implements the RPC method calls common to all versions of the
+// API, an instance is created for each connected client.
 type RPCServer struct {
 	s *ServerImpl
+	c *clientConn
 }
 
 type methodType struct {
@@ -69,6 +114,88 @@ type methodType struct {
 	Synchronous bool
 }
 
+// observerMethods are the methods that observers, clients connected
+// through config.ObserverListener, are allowed to call. None of them
+// changes the state of the target or of the debugger, SetApiVersion is
+// included because clients call it when they connect, CancelRequest
+// because it only interrupts queries like the ones listed here.
+var observerMethods = map[string]bool{
+	"RPCServer.Ancestors":                 true,
+	"RPCServer.AttachedToExistingProcess": true,
+	"RPCServer.CancelRequest":             true,
+	"RPCServer.ChanState":                 true,
+	"RPCServer.Disassemble":               true,
+	"RPCServer.DumpWait":                  true,
+	"RPCServer.Environ":                   true,
+	"RPCServer.Eval":                      true,
+	"RPCServer.ExamineMemory":             true,
+	"RPCServer.FindLocation":              true,
+	"RPCServer.FunctionReturnLocations":   true,
+	"RPCServer.GetBranchTrace":            true,
+	"RPCServer.GetBreakpoint":             true,
+	"RPCServer.GetBuildInfo":              true,
+	"RPCServer.GetCoverage":               true,
+	"RPCServer.GetOutput":                 true,
+	"RPCServer.GetTargetInfo":             true,
+	"RPCServer.GetThread":                 true,
+	"RPCServer.GoroutinesStacktraces":     true,
+	"RPCServer.GetVersion":                true,
+	"RPCServer.IsMulticlient":             true,
+	"RPCServer.ListCommandHooks":          true,
+	"RPCServer.LastModified":              true,
+	"RPCServer.ListBreakpoints":           true,
+	"RPCServer.ListCheckpoints":           true,
+	"RPCServer.ListClients":               true,
+	"RPCServer.ListDynamicLibraries":      true,
+	"RPCServer.ListFileDescriptors":       true,
+	"RPCServer.ListFunctionArgs":          true,
+	"RPCServer.ListFunctions":             true,
+	"RPCServer.ListGoroutines":            true,
+	"RPCServer.ListLocalVars":             true,
+	"RPCServer.ListMemoryRegions":         true,
+	"RPCServer.ListPackageVars":           true,
+	"RPCServer.ListPackagesBuildInfo":     true,
+	"RPCServer.ListRegisters":             true,
+	"RPCServer.ListSources":               true,
+	"RPCServer.ListThreads":               true,
+	"RPCServer.ListTimers":                true,
+	"RPCServer.ListTypes":                 true,
+	"RPCServer.ListWatchExpressions":      true,
+	"RPCServer.MutexState":                true,
+	"RPCServer.ProcessPid":                true,
+	"RPCServer.Recorded":                  true,
+	"RPCServer.SearchMemory":              true,
+	"RPCServer.SetApiVersion":             true,
+	"RPCServer.Stacktrace":                true,
+	"RPCServer.State":                     true,
+	"RPCServer.ThreadsStacktraces":        true,
+	"RPCServer.WaitStateChange":           true,
+	"RPCServer.WaitWatchExpressions":      true,
+	"RPCServer.WatchHistory":              true,
+}
+
+// longPollMethods wait for the target or the debugger to change, clients
+// keep one of them pending while they are connected. They only count as
+// client activity for config.IdleTimeout until their setup is done.
+var longPollMethods = map[string]bool{
+	"RPCServer.WaitStateChange":      true,
+	"RPCServer.WaitWatchExpressions": true,
+}
+
+// sessionMethods are the methods that set up a connection or release
+// control of the session, clients do not need to be, or become, the
+// driver of the session to call them.
+var sessionMethods = map[string]bool{
+	"RPCServer.HandOff":       true,
+	"RPCServer.SetApiVersion": true,
+}
+
+// needsControl returns true if a client must be the driver of the session
+// to call method.
+func needsControl(method string) bool {
+	return !observerMethods[method] && !sessionMethods[method]
+}
+
 // NewServer creates a new RPCServer.
 func NewServer(config *service.Config) *ServerImpl {
 	logger := logflags.RPCLogger()
@@ -79,12 +206,19 @@ func NewServer(config *service.Config) *ServerImpl {
 		// Print listener address
 		logflags.WriteAPIListeningMessage(config.Listener.Addr().String())
 		logger.Debug("API server pid = ", os.Getpid())
+		if config.ObserverListener != nil {
+			logflags.WriteObserverListeningMessage(config.ObserverListener.Addr().String())
+		}
+		if config.GRPCListener != nil {
+			logflags.WriteGRPCListeningMessage(config.GRPCListener.Addr().String())
+		}
 	}
 	return &ServerImpl{
-		config:   config,
-		listener: config.Listener,
-		stopChan: make(chan struct{}),
-		log:      logger,
+		config:       config,
+		listener:     config.Listener,
+		stopChan:     make(chan struct{}),
+		log:          logger,
+		stateChanged: make(chan struct{}),
 	}
 }
 
@@ -94,6 +228,12 @@ func (s *ServerImpl) Stop() error {
 	if s.config.AcceptMulti {
 		s.listener.Close()
 	}
+	if s.config.ObserverListener != nil {
+		s.config.ObserverListener.Close()
+	}
+	if s.config.GRPCServer != nil {
+		s.config.GRPCServer.Stop()
+	}
 	kill := s.config.Debugger.AttachPid == 0
 	return s.debugger.Detach(kill)
 }
@@ -120,45 +260,147 @@ func (s *ServerImpl) Run() error {
 	s.s1 = rpc1.NewServer(s.config, s.debugger)
 	s.s2 = rpc2.NewServer(s.config, s.debugger)
 
-	rpcServer := &RPCServer{s}
-
 	s.methodMaps = make([]map[string]*methodType, 2)
 
 	s.methodMaps[0] = map[string]*methodType{}
 	s.methodMaps[1] = map[string]*methodType{}
 	suitableMethods(s.s1, s.methodMaps[0], s.log)
-	suitableMethods(rpcServer, s.methodMaps[0], s.log)
 	suitableMethods(s.s2, s.methodMaps[1], s.log)
-	suitableMethods(rpcServer, s.methodMaps[1], s.log)
 
-	go func() {
-		defer s.listener.Close()
-		for {
-			c, err := s.listener.Accept()
-			if err != nil {
-				select {
-				case <-s.stopChan:
-					// We were supposed to exit, do nothing and return
-					return
-				default:
-					panic(err)
+	if s.config.ObserverListener != nil {
+		// Observers are served by a separate instance of the API, which
+		// reports the server as accepting multiple clients so that
+		// observers never try to stop it when they disconnect.
+		observerConfig := *s.config
+		observerConfig.AcceptMulti = true
+		s.observerMethodMaps = make([]map[string]*methodType, 2)
+		for i, rcvr := range []interface{}{
+			rpc1.NewServer(&observerConfig, s.debugger),
+			rpc2.NewServer(&observerConfig, s.debugger),
+		} {
+			m := map[string]*methodType{}
+			suitableMethods(rcvr, m, s.log)
+			for name := range m {
+				if !observerMethods[name] {
+					delete(m, name)
 				}
 			}
+			s.observerMethodMaps[i] = m
+		}
+	}
 
-			if s.config.CheckLocalConnUser {
-				if !sameuser.CanAccept(s.listener.Addr(), c.RemoteAddr()) {
-					c.Close()
-					continue
-				}
+	if s.config.IdleTimeout > 0 {
+		s.mu.Lock()
+		s.idleTimer = time.AfterFunc(s.config.IdleTimeout, s.idleTimeout)
+		s.mu.Unlock()
+	}
+
+	go s.acceptLoop(s.listener, false)
+	if s.config.ObserverListener != nil {
+		go s.acceptLoop(s.config.ObserverListener, true)
+	}
+	if s.config.GRPCListener != nil && s.config.GRPCServer != nil {
+		s.serveGRPC(s.config.GRPCListener)
+	}
+	return nil
+}
+
+// serveGRPC serves the gRPC API on listener, all gRPC clients share a
+// single client of the session.
+func (s *ServerImpl) serveGRPC(listener net.Listener) {
+	if s.config.CheckLocalConnUser {
+		listener = sameUserListener{listener}
+	}
+	session := &grpcSession{s, s.connect(listener.Addr().String(), false)}
+	go func() {
+		if err := s.config.GRPCServer.Serve(listener, s.debugger, session); err != nil {
+			s.log.Errorf("gRPC server: %v", err)
+		}
+	}()
+}
+
+// sameUserListener only accepts local connections from the user that
+// started the server, see sameuser.CanAccept.
+type sameUserListener struct {
+	net.Listener
+}
+
+func (l sameUserListener) Accept() (net.Conn, error) {
+	for {
+		c, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		if sameuser.CanAccept(l.Addr(), c.RemoteAddr()) {
+			return c, nil
+		}
+		c.Close()
+	}
+}
+
+// grpcSession implements service.SessionClient, c is the client of the
+// session shared by all gRPC clients.
+type grpcSession struct {
+	s *ServerImpl
+	c *clientConn
+}
+
+func (gs *grpcSession) TakeControl() error {
+	return gs.s.takeControl(gs.c)
+}
+
+func (gs *grpcSession) HandOff() error {
+	return (&RPCServer{gs.s, gs.c}).HandOff(api.HandOffIn{}, &api.HandOffOut{})
+}
+
+func (gs *grpcSession) NotifyStateChange() {
+	gs.s.notifyStateChange(gs.c)
+}
+
+func (gs *grpcSession) StateChanged() <-chan struct{} {
+	gs.s.mu.Lock()
+	defer gs.s.mu.Unlock()
+	return gs.s.stateChanged
+}
+
+func (gs *grpcSession) RequestStarted() {
+	metrics.CommandsServed.Inc()
+	gs.s.requestStarted()
+}
+
+func (gs *grpcSession) RequestDone() {
+	gs.s.requestDone()
+}
+
+// acceptLoop accepts connections on listener and serves them, if observer
+// is true the clients are only allowed to call the methods in
+// observerMethods.
+func (s *ServerImpl) acceptLoop(listener net.Listener, observer bool) {
+	defer listener.Close()
+	for {
+		c, err := listener.Accept()
+		if err != nil {
+			select {
+			case <-s.stopChan:
+				// We were supposed to exit, do nothing and return
+				return
+			default:
+				panic(err)
 			}
+		}
 
-			go s.serveJSONCodec(c)
-			if !s.config.AcceptMulti {
-				break
+		if s.config.CheckLocalConnUser {
+			if !sameuser.CanAccept(listener.Addr(), c.RemoteAddr()) {
+				c.Close()
+				continue
 			}
 		}
-	}()
-	return nil
+
+		go s.serveJSONCodec(c, c.RemoteAddr().String(), observer)
+		if !s.config.AcceptMulti && !observer {
+			break
+		}
+	}
 }
 
 // Precompute the reflect type for error.  Can't use error directly
@@ -249,17 +491,131 @@ func suitableMethods(rcvr interface{}, methods map[string]*methodType, log *logr
 	}
 }
 
-func (s *ServerImpl) serveJSONCodec(conn io.ReadWriteCloser) {
+// triggerServerStop closes config.DisconnectChan if not nil, signaling
+// that the server should be stopped. It can be called multiple times.
+func (s *ServerImpl) triggerServerStop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.config.DisconnectChan != nil {
+		close(s.config.DisconnectChan)
+		s.config.DisconnectChan = nil
+	}
+}
+
+// requestStarted must be called when the server starts executing a
+// request, it pauses the idle timer.
+func (s *ServerImpl) requestStarted() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.activeRequests++
+	if s.idleTimer != nil {
+		s.idleTimer.Stop()
+	}
+}
+
+// requestDone must be called when the server is done executing a
+// request, the idle timer is restarted once no requests are left.
+func (s *ServerImpl) requestDone() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.activeRequests--
+	if s.idleTimer != nil && s.activeRequests == 0 {
+		s.idleTimer.Reset(s.config.IdleTimeout)
+	}
+}
+
+// idleTimeout is called when no client activity happened for
+// config.IdleTimeout, it detaches from the target and stops the server.
+func (s *ServerImpl) idleTimeout() {
+	s.mu.Lock()
+	busy := s.activeRequests > 0
+	s.mu.Unlock()
+	if busy {
+		return
+	}
+	s.log.Infof("no client activity for %v, detaching", s.config.IdleTimeout)
+	if err := s.debugger.Detach(false); err != nil {
+		s.log.Errorf("detach: %v", err)
+	}
+	s.triggerServerStop()
+}
+
+// connect registers a new client connected from addr.
+func (s *ServerImpl) connect(addr string, observer bool) *clientConn {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clientCounter++
+	c := &clientConn{id: s.clientCounter, addr: addr, observer: observer, done: make(chan struct{})}
+	s.clients = append(s.clients, c)
+	return c
+}
+
+// disconnect unregisters client c, if it was the driver of the session
+// control is released.
+func (s *ServerImpl) disconnect(c *clientConn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	close(c.done)
+	for i := range s.clients {
+		if s.clients[i] == c {
+			s.clients = append(s.clients[:i], s.clients[i+1:]...)
+			break
+		}
+	}
+	if s.driver == c {
+		s.driver = nil
+		s.notifyStateChangeLocked(c)
+	}
+}
+
+// takeControl makes c the driver of the session, unless another client
+// is driving it.
+func (s *ServerImpl) takeControl(c *clientConn) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.driver != nil && s.driver != c {
+		return fmt.Errorf("client %d (%s) is driving the session, it must hand off control first", s.driver.id, s.driver.addr)
+	}
+	s.driver = c
+	return nil
+}
+
+// notifyStateChange wakes up the clients waiting in WaitStateChange,
+// after client c called a method that could have changed the state of
+// the target or of the debugger.
+func (s *ServerImpl) notifyStateChange(c *clientConn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notifyStateChangeLocked(c)
+}
+
+func (s *ServerImpl) notifyStateChangeLocked(c *clientConn) {
+	s.stateSeq++
+	s.stateChangedBy = c.id
+	close(s.stateChanged)
+	s.stateChanged = make(chan struct{})
+}
+
+func (s *ServerImpl) serveJSONCodec(conn io.ReadWriteCloser, addr string, observer bool) {
 	defer func() {
-		if !s.config.AcceptMulti && s.config.DisconnectChan != nil {
-			close(s.config.DisconnectChan)
+		if !s.config.AcceptMulti && !observer {
+			s.triggerServerStop()
 		}
 	}()
 
+	client := s.connect(addr, observer)
+	defer s.disconnect(client)
+	clientMethods := map[string]*methodType{}
+	suitableMethods(&RPCServer{s, client}, clientMethods, s.log)
+
 	sending := new(sync.Mutex)
 	codec := jsonrpc.NewServerCodec(conn)
 	var req rpc.Request
-	var resp rpc.Response
+	// Synchronous calls are executed on a separate goroutine so that the
+	// next request can be read while they run, this allows CancelRequest to
+	// interrupt them. All other requests wait for the call in progress to
+	// finish before being executed, to preserve ordering.
+	var inflight sync.WaitGroup
 	for {
 		req = rpc.Request{}
 		err := codec.ReadRequestHeader(&req)
@@ -270,13 +626,42 @@ func (s *ServerImpl) serveJSONCodec(conn io.ReadWriteCloser) {
 			break
 		}
 
-		mtype, ok := s.methodMaps[s.config.APIVersion-1][req.ServiceMethod]
+		metrics.CommandsServed.Inc()
+		s.requestStarted()
+
+		methodMap := s.methodMaps[s.config.APIVersion-1]
+		if observer {
+			methodMap = s.observerMethodMaps[s.config.APIVersion-1]
+		}
+		mtype, ok := clientMethods[req.ServiceMethod]
+		if ok && observer && !observerMethods[req.ServiceMethod] {
+			ok = false
+		}
+		if !ok {
+			mtype, ok = methodMap[req.ServiceMethod]
+		}
 		if !ok {
-			s.log.Errorf("rpc: can't find method %s", req.ServiceMethod)
-			s.sendResponse(sending, &req, &rpc.Response{}, nil, codec, fmt.Sprintf("unknown method: %s", req.ServiceMethod))
+			errmsg := fmt.Sprintf("unknown method: %s", req.ServiceMethod)
+			if _, exists := s.methodMaps[s.config.APIVersion-1][req.ServiceMethod]; exists || clientMethods[req.ServiceMethod] != nil {
+				errmsg = fmt.Sprintf("%s is not allowed for read-only clients", req.ServiceMethod)
+			} else {
+				s.log.Errorf("rpc: can't find method %s", req.ServiceMethod)
+			}
+			s.sendResponse(sending, &req, &rpc.Response{}, nil, codec, errmsg)
+			s.requestDone()
 			continue
 		}
 
+		var changedBy *clientConn
+		if needsControl(req.ServiceMethod) {
+			if err := s.takeControl(client); err != nil {
+				s.sendResponse(sending, &req, &rpc.Response{}, nil, codec, err.Error())
+				s.requestDone()
+				continue
+			}
+			changedBy = client
+		}
+
 		var argv, replyv reflect.Value
 
 		// Decode the argument value.
@@ -289,52 +674,65 @@ func (s *ServerImpl) serveJSONCodec(conn io.ReadWriteCloser) {
 		}
 		// argv guaranteed to be a pointer now.
 		if err = codec.ReadRequestBody(argv.Interface()); err != nil {
+			s.requestDone()
+			inflight.Wait()
 			return
 		}
 		if argIsValue {
 			argv = argv.Elem()
 		}
 
+		if req.ServiceMethod != "RPCServer.CancelRequest" {
+			inflight.Wait()
+		}
+
 		if mtype.Synchronous {
 			if logflags.RPC() {
 				argvbytes, _ := json.Marshal(argv.Interface())
 				s.log.Debugf("<- %s(%T%s)", req.ServiceMethod, argv.Interface(), argvbytes)
 			}
 			replyv = reflect.New(mtype.ReplyType.Elem())
-			function := mtype.method.Func
-			var returnValues []reflect.Value
-			var errInter interface{}
-			func() {
-				defer func() {
-					if ierr := recover(); ierr != nil {
-						errInter = newInternalError(ierr, 2)
-					}
+			inflight.Add(1)
+			go func(req rpc.Request, argv, replyv reflect.Value) {
+				defer inflight.Done()
+				defer s.requestDone()
+				function := mtype.method.Func
+				var returnValues []reflect.Value
+				var errInter interface{}
+				func() {
+					defer func() {
+						if ierr := recover(); ierr != nil {
+							errInter = newInternalError(ierr, 2)
+						}
+					}()
+					returnValues = function.Call([]reflect.Value{mtype.Rcvr, argv, replyv})
+					errInter = returnValues[0].Interface()
 				}()
-				returnValues = function.Call([]reflect.Value{mtype.Rcvr, argv, replyv})
-				errInter = returnValues[0].Interface()
-			}()
 
-			errmsg := ""
-			if errInter != nil {
-				errmsg = errInter.(error).Error()
-			}
-			resp = rpc.Response{}
-			if logflags.RPC() {
-				replyvbytes, _ := json.Marshal(replyv.Interface())
-				s.log.Debugf("-> %T%s error: %q", replyv.Interface(), replyvbytes, errmsg)
-			}
-			s.sendResponse(sending, &req, &resp, replyv.Interface(), codec, errmsg)
-			if req.ServiceMethod == "RPCServer.Detach" && s.config.DisconnectChan != nil {
-				close(s.config.DisconnectChan)
-				s.config.DisconnectChan = nil
-			}
+				errmsg := ""
+				if errInter != nil {
+					errmsg = errInter.(error).Error()
+				}
+				resp := rpc.Response{}
+				if logflags.RPC() {
+					replyvbytes, _ := json.Marshal(replyv.Interface())
+					s.log.Debugf("-> %T%s error: %q", replyv.Interface(), replyvbytes, errmsg)
+				}
+				s.sendResponse(sending, &req, &resp, replyv.Interface(), codec, errmsg)
+				if changedBy != nil {
+					s.notifyStateChange(changedBy)
+				}
+				if req.ServiceMethod == "RPCServer.Detach" {
+					s.triggerServerStop()
+				}
+			}(req, argv, replyv)
 		} else {
 			if logflags.RPC() {
 				argvbytes, _ := json.Marshal(argv.Interface())
 				s.log.Debugf("(async %d) <- %s(%T%s)", req.Seq, req.ServiceMethod, argv.Interface(), argvbytes)
 			}
 			function := mtype.method.Func
-			ctl := &RPCCallback{s, sending, codec, req, make(chan struct{})}
+			ctl := &RPCCallback{s, sending, codec, req, make(chan struct{}), changedBy, longPollMethods[req.ServiceMethod]}
 			go func() {
 				defer func() {
 					if ierr := recover(); ierr != nil {
@@ -344,8 +742,12 @@ func (s *ServerImpl) serveJSONCodec(conn io.ReadWriteCloser) {
 				function.Call([]reflect.Value{mtype.Rcvr, argv, reflect.ValueOf(ctl)})
 			}()
 			<-ctl.setupDone
+			if ctl.longPoll {
+				s.requestDone()
+			}
 		}
 	}
+	inflight.Wait()
 	codec.Close()
 }
 
@@ -386,6 +788,12 @@ func (cb *RPCCallback) Return(out interface{}, err error) {
 		cb.s.log.Debugf("(async %d) -> %T%s error: %q", cb.req.Seq, out, outbytes, errmsg)
 	}
 	cb.s.sendResponse(cb.sending, &cb.req, &resp, out, cb.codec, errmsg)
+	if cb.changedBy != nil {
+		cb.s.notifyStateChange(cb.changedBy)
+	}
+	if !cb.longPoll {
+		cb.s.requestDone()
+	}
 }
 
 func (cb *RPCCallback) SetupDoneChan() chan struct{} {
@@ -412,6 +820,103 @@ func (s *RPCServer) SetApiVersion(args api.SetAPIVersionIn, out *api.SetAPIVersi
 	return nil
 }
 
+// ListClients returns the clients connected to the server and the ID of
+// the calling client.
+func (s *RPCServer) ListClients(args api.ListClientsIn, out *api.ListClientsOut) error {
+	s.s.mu.Lock()
+	defer s.s.mu.Unlock()
+	for _, c := range s.s.clients {
+		out.Clients = append(out.Clients, api.ClientInfo{ID: c.id, Addr: c.addr, Observer: c.observer, Driver: c == s.s.driver})
+	}
+	out.Self = s.c.id
+	return nil
+}
+
+// HandOff releases control of the session. If To is not zero control is
+// handed to the client with that ID, otherwise the next client changing
+// the state of the target becomes the driver.
+// Only the driver of the session can hand off control.
+func (s *RPCServer) HandOff(args api.HandOffIn, out *api.HandOffOut) error {
+	s.s.mu.Lock()
+	defer s.s.mu.Unlock()
+	if s.s.driver != nil && s.s.driver != s.c {
+		return fmt.Errorf("only the driving client (%d) can hand off control", s.s.driver.id)
+	}
+	if args.To == 0 {
+		s.s.driver = nil
+		s.s.notifyStateChangeLocked(s.c)
+		return nil
+	}
+	for _, c := range s.s.clients {
+		if c.id == args.To {
+			if c.observer {
+				return fmt.Errorf("client %d is read-only", args.To)
+			}
+			s.s.driver = c
+			s.s.notifyStateChangeLocked(s.c)
+			return nil
+		}
+	}
+	return fmt.Errorf("no client with ID %d", args.To)
+}
+
+// WaitStateChange waits until the state of the target or of the debugger
+// changes after sequence number Seq, for example because a client resumed
+// the target and it stopped again, and returns the new state.
+// Seq should be zero on the first call and the value of Seq returned by
+// the previous call afterwards.
+func (s *RPCServer) WaitStateChange(args api.WaitStateChangeIn, cb service.RPCCallback) {
+	close(cb.SetupDoneChan())
+	for {
+		s.s.mu.Lock()
+		seq, changedBy, ch := s.s.stateSeq, s.s.stateChangedBy, s.s.stateChanged
+		s.s.mu.Unlock()
+		if seq > args.Seq {
+			st, err := s.s.debugger.State(true)
+			if err != nil {
+				cb.Return(nil, err)
+				return
+			}
+			cb.Return(api.WaitStateChangeOut{Seq: seq, ChangedBy: changedBy, State: *st}, nil)
+			return
+		}
+		select {
+		case <-ch:
+		case <-s.c.done:
+			// Nobody to answer to.
+			return
+		case <-s.s.stopChan:
+			cb.Return(nil, errors.New("server stopped"))
+			return
+		}
+	}
+}
+
+// WaitWatchExpressions waits until the watch expressions, or their values,
+// change after sequence number Seq, typically because the target stopped,
+// and returns them with their old and new values. Seq should be zero on
+// the first call and the value of Seq returned by the previous call
+// afterwards.
+func (s *RPCServer) WaitWatchExpressions(args api.WaitWatchExpressionsIn, cb service.RPCCallback) {
+	close(cb.SetupDoneChan())
+	for {
+		seq, exprs, ch := s.s.debugger.WatchExpressions()
+		if seq > args.Seq {
+			cb.Return(api.WaitWatchExpressionsOut{Seq: seq, Exprs: exprs}, nil)
+			return
+		}
+		select {
+		case <-ch:
+		case <-s.c.done:
+			// Nobody to answer to.
+			return
+		case <-s.s.stopChan:
+			cb.Return(nil, errors.New("server stopped"))
+			return
+		}
+	}
+}
+
 type internalError struct {
 	Err   interface{}
 	Stack []internalErrorFrame
@@ -0,0 +1,30 @@
+// Command dlv is Delve, built with support for the gRPC API, see the
+// --grpc-listen flag.
+package main
+
+import (
+	"os"
+
+	"github.com/go-delve/delve/cmd/dlv/cmds"
+	"github.com/go-delve/delve/pkg/version"
+	"github.com/go-delve/delve/service"
+	"github.com/go-delve/delve/service/rpcgrpc"
+	"github.com/sirupsen/logrus"
+)
+
+// Build is the git sha of this binaries build.
+var Build string
+
+func main() {
+	if Build != "" {
+		version.DelveVersion.Build = Build
+	}
+	const cgoCflagsEnv = "CGO_CFLAGS"
+	if os.Getenv(cgoCflagsEnv) == "" {
+		os.Setenv(cgoCflagsEnv, "-O0 -g")
+	} else {
+		logrus.WithFields(logrus.Fields{"layer": "dlv"}).Warnln("CGO_CFLAGS already set, Cgo code could be optimized.")
+	}
+	cmds.NewGRPCServer = func() service.GRPCServer { return rpcgrpc.NewGRPCServer() }
+	cmds.New(false).Execute()
+}
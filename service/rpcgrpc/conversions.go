@@ -0,0 +1,210 @@
+package rpcgrpc
+
+import (
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/rpcgrpc/delvepb"
+)
+
+func convertState(st *api.DebuggerState) *delvepb.DebuggerState {
+	r := &delvepb.DebuggerState{
+		Running:           st.Running,
+		CurrentThread:     convertThread(st.CurrentThread),
+		SelectedGoroutine: convertGoroutine(st.SelectedGoroutine),
+		NextInProgress:    st.NextInProgress,
+		Exited:            st.Exited,
+		ExitStatus:        int32(st.ExitStatus),
+		Pid:               int32(st.Pid),
+		Recording:         st.Recording,
+		When:              st.When,
+		StopReason: &delvepb.StopReason{
+			Kind:         string(st.StopReason.Kind),
+			BreakpointId: int32(st.StopReason.BreakpointID),
+			Signal:       st.StopReason.Signal,
+			CoreDumped:   st.StopReason.CoreDumped,
+			SignalCode:   int32(st.StopReason.SignalCode),
+			FaultAddr:    st.StopReason.FaultAddr,
+		},
+	}
+	for _, th := range st.Threads {
+		r.Threads = append(r.Threads, convertThread(th))
+	}
+	if st.Err != nil {
+		r.Err = st.Err.Error()
+	}
+	return r
+}
+
+func convertThread(th *api.Thread) *delvepb.Thread {
+	if th == nil {
+		return nil
+	}
+	r := &delvepb.Thread{
+		Id:          int32(th.ID),
+		Pc:          th.PC,
+		File:        th.File,
+		Line:        int32(th.Line),
+		Function:    convertFunction(th.Function),
+		GoroutineId: int32(th.GoroutineID),
+		Breakpoint:  convertBreakpoint(th.Breakpoint),
+		CallReturn:  th.CallReturn,
+	}
+	for i := range th.ReturnValues {
+		r.ReturnValues = append(r.ReturnValues, convertVar(&th.ReturnValues[i]))
+	}
+	return r
+}
+
+func convertFunction(fn *api.Function) *delvepb.Function {
+	if fn == nil {
+		return nil
+	}
+	return &delvepb.Function{Name: fn.Name_, Value: fn.Value, Optimized: fn.Optimized}
+}
+
+func convertLocation(loc *api.Location) *delvepb.Location {
+	return &delvepb.Location{
+		Pc:       loc.PC,
+		File:     loc.File,
+		Line:     int32(loc.Line),
+		Function: convertFunction(loc.Function),
+		Pcs:      loc.PCs,
+		Column:   int32(loc.Column),
+	}
+}
+
+func convertGoroutine(g *api.Goroutine) *delvepb.Goroutine {
+	if g == nil {
+		return nil
+	}
+	return &delvepb.Goroutine{
+		Id:             int32(g.ID),
+		CurrentLoc:     convertLocation(&g.CurrentLoc),
+		UserCurrentLoc: convertLocation(&g.UserCurrentLoc),
+		GoStatementLoc: convertLocation(&g.GoStatementLoc),
+		StartLoc:       convertLocation(&g.StartLoc),
+		ThreadId:       int32(g.ThreadID),
+		Status:         g.Status,
+		Unreadable:     g.Unreadable,
+		Labels:         g.Labels,
+		GoPc:           g.GoPC,
+		StartPc:        g.StartPC,
+		WaitSince:      g.WaitSince,
+		WaitReason:     g.WaitReason,
+		Frozen:         g.Frozen,
+	}
+}
+
+func convertBreakpoint(bp *api.Breakpoint) *delvepb.Breakpoint {
+	if bp == nil {
+		return nil
+	}
+	return &delvepb.Breakpoint{
+		Id:            int32(bp.ID),
+		Name:          bp.Name,
+		Addr:          bp.Addr,
+		Addrs:         bp.Addrs,
+		File:          bp.File,
+		Line:          int32(bp.Line),
+		FunctionName:  bp.FunctionName,
+		Cond:          bp.Cond,
+		HitCond:       bp.HitCond,
+		Tracepoint:    bp.Tracepoint,
+		Disabled:      bp.Disabled,
+		HitCount:      bp.HitCount,
+		TotalHitCount: bp.TotalHitCount,
+		VerboseDescr:  bp.VerboseDescr,
+		Groups:        bp.Groups,
+		Column:        int32(bp.Column),
+		Caller:        bp.Caller,
+		Goroutine:     bp.Goroutine,
+		Stacktrace:    int32(bp.Stacktrace),
+		Variables:     bp.Variables,
+	}
+}
+
+// breakpointFromPB converts the breakpoint requested by a client, the
+// fields describing the state of an existing breakpoint are ignored.
+func breakpointFromPB(bp *delvepb.Breakpoint) *api.Breakpoint {
+	return &api.Breakpoint{
+		Name:         bp.Name,
+		Addr:         bp.Addr,
+		Addrs:        bp.Addrs,
+		File:         bp.File,
+		Line:         int(bp.Line),
+		FunctionName: bp.FunctionName,
+		Cond:         bp.Cond,
+		HitCond:      bp.HitCond,
+		Tracepoint:   bp.Tracepoint,
+		Disabled:     bp.Disabled,
+		Groups:       bp.Groups,
+		Column:       int(bp.Column),
+		Caller:       bp.Caller,
+		Goroutine:    bp.Goroutine,
+		Stacktrace:   int(bp.Stacktrace),
+		Variables:    bp.Variables,
+	}
+}
+
+func convertVar(v *api.Variable) *delvepb.Variable {
+	r := &delvepb.Variable{
+		Name:       v.Name,
+		Addr:       v.Addr,
+		OnlyAddr:   v.OnlyAddr,
+		Type:       v.Type,
+		RealType:   v.RealType,
+		Flags:      uint32(v.Flags),
+		Kind:       uint32(v.Kind),
+		Value:      v.Value,
+		Len:        v.Len,
+		Cap:        v.Cap,
+		Base:       v.Base,
+		Unreadable: v.Unreadable,
+	}
+	for i := range v.Children {
+		r.Children = append(r.Children, convertVar(&v.Children[i]))
+	}
+	return r
+}
+
+func convertStackframe(frame *api.Stackframe) *delvepb.Stackframe {
+	r := &delvepb.Stackframe{
+		Location:           convertLocation(&frame.Location),
+		FrameOffset:        frame.FrameOffset,
+		FramePointerOffset: frame.FramePointerOffset,
+		Bottom:             frame.Bottom,
+		Err:                frame.Err,
+	}
+	for i := range frame.Locals {
+		r.Locals = append(r.Locals, convertVar(&frame.Locals[i]))
+	}
+	for i := range frame.Arguments {
+		r.Arguments = append(r.Arguments, convertVar(&frame.Arguments[i]))
+	}
+	return r
+}
+
+func loadConfigFromPB(cfg *delvepb.LoadConfig) *api.LoadConfig {
+	if cfg == nil {
+		return nil
+	}
+	return &api.LoadConfig{
+		FollowPointers:     cfg.FollowPointers,
+		MaxVariableRecurse: int(cfg.MaxVariableRecurse),
+		MaxStringLen:       int(cfg.MaxStringLen),
+		MaxArrayValues:     int(cfg.MaxArrayValues),
+		MaxStructFields:    int(cfg.MaxStructFields),
+	}
+}
+
+// evalScopeFromPB converts scope, if it is nil the scope is the topmost
+// frame of the current goroutine.
+func evalScopeFromPB(scope *delvepb.EvalScope) api.EvalScope {
+	if scope == nil {
+		return api.EvalScope{GoroutineID: -1}
+	}
+	return api.EvalScope{
+		GoroutineID:  int(scope.GoroutineId),
+		Frame:        int(scope.Frame),
+		DeferredCall: int(scope.DeferredCall),
+	}
+}
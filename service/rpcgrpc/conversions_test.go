@@ -0,0 +1,217 @@
+package rpcgrpc
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/rpcgrpc/delvepb"
+)
+
+func TestConvertNil(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		out  proto.Message
+	}{
+		{"thread", convertThread(nil)},
+		{"function", convertFunction(nil)},
+		{"goroutine", convertGoroutine(nil)},
+		{"breakpoint", convertBreakpoint(nil)},
+	} {
+		if !reflect.ValueOf(tc.out).IsNil() {
+			t.Errorf("%s: expected nil, got %v", tc.name, tc.out)
+		}
+	}
+	if cfg := loadConfigFromPB(nil); cfg != nil {
+		t.Errorf("load config: expected nil, got %v", cfg)
+	}
+}
+
+func TestConvertBreakpoint(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		in   api.Breakpoint
+		out  *delvepb.Breakpoint
+	}{
+		{
+			"function",
+			api.Breakpoint{ID: 1, Addr: 0x1000, Addrs: []uint64{0x1000}, File: "main.go", Line: 10, FunctionName: "main.main", HitCount: map[string]uint64{"1": 2}, TotalHitCount: 2},
+			&delvepb.Breakpoint{Id: 1, Addr: 0x1000, Addrs: []uint64{0x1000}, File: "main.go", Line: 10, FunctionName: "main.main", HitCount: map[string]uint64{"1": 2}, TotalHitCount: 2},
+		},
+		{
+			"tracepoint",
+			api.Breakpoint{ID: 2, Name: "tp", Addrs: []uint64{0x1000, 0x2000}, Cond: "i == 3", HitCond: "> 2", Tracepoint: true, Goroutine: true, Stacktrace: 5, Variables: []string{"i", "s"}},
+			&delvepb.Breakpoint{Id: 2, Name: "tp", Addrs: []uint64{0x1000, 0x2000}, Cond: "i == 3", HitCond: "> 2", Tracepoint: true, Goroutine: true, Stacktrace: 5, Variables: []string{"i", "s"}},
+		},
+		{
+			"disabled",
+			api.Breakpoint{ID: 3, File: "main.go", Line: 20, Column: 4, Disabled: true, Groups: []string{"g1"}, Caller: "main.f", VerboseDescr: []string{"Stepping"}},
+			&delvepb.Breakpoint{Id: 3, File: "main.go", Line: 20, Column: 4, Disabled: true, Groups: []string{"g1"}, Caller: "main.f", VerboseDescr: []string{"Stepping"}},
+		},
+	} {
+		if out := convertBreakpoint(&tc.in); !proto.Equal(out, tc.out) {
+			t.Errorf("%s: convertBreakpoint:\ngot:  %v\nwant: %v", tc.name, out, tc.out)
+		}
+	}
+}
+
+func TestBreakpointFromPB(t *testing.T) {
+	// The fields describing the state of an existing breakpoint are
+	// ignored.
+	for _, tc := range []struct {
+		name string
+		in   *delvepb.Breakpoint
+		out  api.Breakpoint
+	}{
+		{
+			"file and line",
+			&delvepb.Breakpoint{File: "main.go", Line: 10, Column: 2},
+			api.Breakpoint{File: "main.go", Line: 10, Column: 2},
+		},
+		{
+			"state",
+			&delvepb.Breakpoint{Id: 7, FunctionName: "main.main", HitCount: map[string]uint64{"1": 2}, TotalHitCount: 2, VerboseDescr: []string{"x"}},
+			api.Breakpoint{FunctionName: "main.main"},
+		},
+		{
+			"tracepoint",
+			&delvepb.Breakpoint{Name: "tp", Addr: 0x1000, Addrs: []uint64{0x1000}, Cond: "i == 3", HitCond: "> 2", Tracepoint: true, Disabled: true, Groups: []string{"g1"}, Caller: "main.f", Goroutine: true, Stacktrace: 5, Variables: []string{"i"}},
+			api.Breakpoint{Name: "tp", Addr: 0x1000, Addrs: []uint64{0x1000}, Cond: "i == 3", HitCond: "> 2", Tracepoint: true, Disabled: true, Groups: []string{"g1"}, Caller: "main.f", Goroutine: true, Stacktrace: 5, Variables: []string{"i"}},
+		},
+	} {
+		if out := breakpointFromPB(tc.in); !reflect.DeepEqual(*out, tc.out) {
+			t.Errorf("%s: breakpointFromPB:\ngot:  %#v\nwant: %#v", tc.name, *out, tc.out)
+		}
+	}
+}
+
+func TestConvertState(t *testing.T) {
+	th := &api.Thread{ID: 10, PC: 0x1000, File: "main.go", Line: 3, GoroutineID: 1, Function: &api.Function{Name_: "main.main", Value: 0x900}}
+	pbth := &delvepb.Thread{Id: 10, Pc: 0x1000, File: "main.go", Line: 3, GoroutineId: 1, Function: &delvepb.Function{Name: "main.main", Value: 0x900}}
+	loc := &delvepb.Location{File: "main.go", Line: 3}
+	for _, tc := range []struct {
+		name string
+		in   api.DebuggerState
+		out  *delvepb.DebuggerState
+	}{
+		{
+			"running",
+			api.DebuggerState{Running: true, Pid: 42},
+			&delvepb.DebuggerState{Running: true, Pid: 42, StopReason: &delvepb.StopReason{}},
+		},
+		{
+			"breakpoint",
+			api.DebuggerState{
+				Pid:               42,
+				CurrentThread:     th,
+				Threads:           []*api.Thread{th},
+				SelectedGoroutine: &api.Goroutine{ID: 1, CurrentLoc: api.Location{File: "main.go", Line: 3}, ThreadID: 10, Frozen: true},
+				StopReason:        api.StopReason{Kind: api.StopBreakpoint, BreakpointID: 1},
+			},
+			&delvepb.DebuggerState{
+				Pid:               42,
+				CurrentThread:     pbth,
+				Threads:           []*delvepb.Thread{pbth},
+				SelectedGoroutine: &delvepb.Goroutine{Id: 1, CurrentLoc: loc, UserCurrentLoc: &delvepb.Location{}, GoStatementLoc: &delvepb.Location{}, StartLoc: &delvepb.Location{}, ThreadId: 10, Frozen: true},
+				StopReason:        &delvepb.StopReason{Kind: string(api.StopBreakpoint), BreakpointId: 1},
+			},
+		},
+		{
+			"exited",
+			api.DebuggerState{Exited: true, ExitStatus: 2, Err: errors.New("process 42 has exited with status 2")},
+			&delvepb.DebuggerState{Exited: true, ExitStatus: 2, Err: "process 42 has exited with status 2", StopReason: &delvepb.StopReason{}},
+		},
+	} {
+		if out := convertState(&tc.in); !proto.Equal(out, tc.out) {
+			t.Errorf("%s: convertState:\ngot:  %v\nwant: %v", tc.name, out, tc.out)
+		}
+	}
+}
+
+func TestConvertVar(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		in   api.Variable
+		out  *delvepb.Variable
+	}{
+		{
+			"int",
+			api.Variable{Name: "i", Addr: 0xc000, Type: "int", RealType: "int", Kind: reflect.Int, Value: "3"},
+			&delvepb.Variable{Name: "i", Addr: 0xc000, Type: "int", RealType: "int", Kind: uint32(reflect.Int), Value: "3"},
+		},
+		{
+			"unreadable",
+			api.Variable{Name: "p", Type: "*int", Kind: reflect.Ptr, Unreadable: "could not read memory"},
+			&delvepb.Variable{Name: "p", Type: "*int", Kind: uint32(reflect.Ptr), Unreadable: "could not read memory"},
+		},
+		{
+			"nested",
+			api.Variable{Name: "s", Type: "[]string", Kind: reflect.Slice, Len: 2, Cap: 4, Base: 0xc100, Flags: api.VariableEscaped, Children: []api.Variable{
+				{Type: "string", Kind: reflect.String, Value: "a", Len: 1},
+				{Type: "string", Kind: reflect.String, Value: "b", Len: 1},
+			}},
+			&delvepb.Variable{Name: "s", Type: "[]string", Kind: uint32(reflect.Slice), Len: 2, Cap: 4, Base: 0xc100, Flags: uint32(api.VariableEscaped), Children: []*delvepb.Variable{
+				{Type: "string", Kind: uint32(reflect.String), Value: "a", Len: 1},
+				{Type: "string", Kind: uint32(reflect.String), Value: "b", Len: 1},
+			}},
+		},
+	} {
+		if out := convertVar(&tc.in); !proto.Equal(out, tc.out) {
+			t.Errorf("%s: convertVar:\ngot:  %v\nwant: %v", tc.name, out, tc.out)
+		}
+	}
+}
+
+func TestConvertStackframe(t *testing.T) {
+	frame := api.Stackframe{
+		Location:    api.Location{PC: 0x1000, File: "main.go", Line: 3, Function: &api.Function{Name_: "main.f"}, Column: 1},
+		FrameOffset: -16,
+		Bottom:      true,
+		Err:         "some error",
+		Locals:      []api.Variable{{Name: "a", Kind: reflect.Int, Value: "1"}},
+		Arguments:   []api.Variable{{Name: "b", Kind: reflect.Bool, Value: "true"}},
+	}
+	want := &delvepb.Stackframe{
+		Location:    &delvepb.Location{Pc: 0x1000, File: "main.go", Line: 3, Function: &delvepb.Function{Name: "main.f"}, Column: 1},
+		FrameOffset: -16,
+		Bottom:      true,
+		Err:         "some error",
+		Locals:      []*delvepb.Variable{{Name: "a", Kind: uint32(reflect.Int), Value: "1"}},
+		Arguments:   []*delvepb.Variable{{Name: "b", Kind: uint32(reflect.Bool), Value: "true"}},
+	}
+	if out := convertStackframe(&frame); !proto.Equal(out, want) {
+		t.Errorf("convertStackframe:\ngot:  %v\nwant: %v", out, want)
+	}
+}
+
+func TestLoadConfigFromPB(t *testing.T) {
+	for _, tc := range []struct {
+		in  *delvepb.LoadConfig
+		out api.LoadConfig
+	}{
+		{&delvepb.LoadConfig{}, api.LoadConfig{}},
+		{&delvepb.LoadConfig{FollowPointers: true, MaxVariableRecurse: 1, MaxStringLen: 64, MaxArrayValues: 64, MaxStructFields: -1}, defaultLoadConfig},
+	} {
+		if out := loadConfigFromPB(tc.in); *out != tc.out {
+			t.Errorf("loadConfigFromPB(%v): got %#v, want %#v", tc.in, *out, tc.out)
+		}
+	}
+}
+
+func TestEvalScopeFromPB(t *testing.T) {
+	for _, tc := range []struct {
+		in  *delvepb.EvalScope
+		out api.EvalScope
+	}{
+		{nil, api.EvalScope{GoroutineID: -1}},
+		{&delvepb.EvalScope{}, api.EvalScope{}},
+		{&delvepb.EvalScope{GoroutineId: 3, Frame: 2, DeferredCall: 1}, api.EvalScope{GoroutineID: 3, Frame: 2, DeferredCall: 1}},
+	} {
+		if out := evalScopeFromPB(tc.in); out != tc.out {
+			t.Errorf("evalScopeFromPB(%v): got %#v, want %#v", tc.in, out, tc.out)
+		}
+	}
+}
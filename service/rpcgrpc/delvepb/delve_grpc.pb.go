@@ -0,0 +1,534 @@
+// Protocol buffer definition of the Delve API for gRPC clients.
+//
+// This mirrors the JSON-RPC API version 2 (see service/rpc2 and
+// service/api), field names and semantics are the same unless noted.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: delve.proto
+
+package delvepb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Debugger_State_FullMethodName            = "/delve.v1.Debugger/State"
+	Debugger_Command_FullMethodName          = "/delve.v1.Debugger/Command"
+	Debugger_Halt_FullMethodName             = "/delve.v1.Debugger/Halt"
+	Debugger_CreateBreakpoint_FullMethodName = "/delve.v1.Debugger/CreateBreakpoint"
+	Debugger_ClearBreakpoint_FullMethodName  = "/delve.v1.Debugger/ClearBreakpoint"
+	Debugger_ListBreakpoints_FullMethodName  = "/delve.v1.Debugger/ListBreakpoints"
+	Debugger_Eval_FullMethodName             = "/delve.v1.Debugger/Eval"
+	Debugger_Stacktrace_FullMethodName       = "/delve.v1.Debugger/Stacktrace"
+	Debugger_ListGoroutines_FullMethodName   = "/delve.v1.Debugger/ListGoroutines"
+	Debugger_HandOff_FullMethodName          = "/delve.v1.Debugger/HandOff"
+	Debugger_StopEvents_FullMethodName       = "/delve.v1.Debugger/StopEvents"
+)
+
+// DebuggerClient is the client API for Debugger service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type DebuggerClient interface {
+	// State returns the current debugger state.
+	State(ctx context.Context, in *StateRequest, opts ...grpc.CallOption) (*DebuggerState, error)
+	// Command resumes or steps the target, see api.DebuggerCommand.
+	Command(ctx context.Context, in *CommandRequest, opts ...grpc.CallOption) (*DebuggerState, error)
+	// Halt stops the target.
+	Halt(ctx context.Context, in *HaltRequest, opts ...grpc.CallOption) (*DebuggerState, error)
+	CreateBreakpoint(ctx context.Context, in *Breakpoint, opts ...grpc.CallOption) (*Breakpoint, error)
+	ClearBreakpoint(ctx context.Context, in *ClearBreakpointRequest, opts ...grpc.CallOption) (*Breakpoint, error)
+	ListBreakpoints(ctx context.Context, in *ListBreakpointsRequest, opts ...grpc.CallOption) (*ListBreakpointsResponse, error)
+	Eval(ctx context.Context, in *EvalRequest, opts ...grpc.CallOption) (*Variable, error)
+	Stacktrace(ctx context.Context, in *StacktraceRequest, opts ...grpc.CallOption) (*StacktraceResponse, error)
+	ListGoroutines(ctx context.Context, in *ListGoroutinesRequest, opts ...grpc.CallOption) (*ListGoroutinesResponse, error)
+	// HandOff releases control of the session, so that JSON-RPC clients can
+	// drive it. gRPC clients take control with the first call that changes
+	// the state of the target or of the debugger.
+	HandOff(ctx context.Context, in *HandOffRequest, opts ...grpc.CallOption) (*HandOffResponse, error)
+	// StopEvents streams the debugger state every time it changes, for
+	// example because the target stopped after being resumed, regardless of
+	// which client caused the change. The response headers are sent as soon
+	// as the changes are being watched, clients can wait for them to avoid
+	// missing the changes caused by their following calls.
+	StopEvents(ctx context.Context, in *StopEventsRequest, opts ...grpc.CallOption) (Debugger_StopEventsClient, error)
+}
+
+type debuggerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDebuggerClient(cc grpc.ClientConnInterface) DebuggerClient {
+	return &debuggerClient{cc}
+}
+
+func (c *debuggerClient) State(ctx context.Context, in *StateRequest, opts ...grpc.CallOption) (*DebuggerState, error) {
+	out := new(DebuggerState)
+	err := c.cc.Invoke(ctx, Debugger_State_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *debuggerClient) Command(ctx context.Context, in *CommandRequest, opts ...grpc.CallOption) (*DebuggerState, error) {
+	out := new(DebuggerState)
+	err := c.cc.Invoke(ctx, Debugger_Command_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *debuggerClient) Halt(ctx context.Context, in *HaltRequest, opts ...grpc.CallOption) (*DebuggerState, error) {
+	out := new(DebuggerState)
+	err := c.cc.Invoke(ctx, Debugger_Halt_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *debuggerClient) CreateBreakpoint(ctx context.Context, in *Breakpoint, opts ...grpc.CallOption) (*Breakpoint, error) {
+	out := new(Breakpoint)
+	err := c.cc.Invoke(ctx, Debugger_CreateBreakpoint_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *debuggerClient) ClearBreakpoint(ctx context.Context, in *ClearBreakpointRequest, opts ...grpc.CallOption) (*Breakpoint, error) {
+	out := new(Breakpoint)
+	err := c.cc.Invoke(ctx, Debugger_ClearBreakpoint_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *debuggerClient) ListBreakpoints(ctx context.Context, in *ListBreakpointsRequest, opts ...grpc.CallOption) (*ListBreakpointsResponse, error) {
+	out := new(ListBreakpointsResponse)
+	err := c.cc.Invoke(ctx, Debugger_ListBreakpoints_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *debuggerClient) Eval(ctx context.Context, in *EvalRequest, opts ...grpc.CallOption) (*Variable, error) {
+	out := new(Variable)
+	err := c.cc.Invoke(ctx, Debugger_Eval_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *debuggerClient) Stacktrace(ctx context.Context, in *StacktraceRequest, opts ...grpc.CallOption) (*StacktraceResponse, error) {
+	out := new(StacktraceResponse)
+	err := c.cc.Invoke(ctx, Debugger_Stacktrace_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *debuggerClient) ListGoroutines(ctx context.Context, in *ListGoroutinesRequest, opts ...grpc.CallOption) (*ListGoroutinesResponse, error) {
+	out := new(ListGoroutinesResponse)
+	err := c.cc.Invoke(ctx, Debugger_ListGoroutines_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *debuggerClient) HandOff(ctx context.Context, in *HandOffRequest, opts ...grpc.CallOption) (*HandOffResponse, error) {
+	out := new(HandOffResponse)
+	err := c.cc.Invoke(ctx, Debugger_HandOff_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *debuggerClient) StopEvents(ctx context.Context, in *StopEventsRequest, opts ...grpc.CallOption) (Debugger_StopEventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Debugger_ServiceDesc.Streams[0], Debugger_StopEvents_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &debuggerStopEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Debugger_StopEventsClient interface {
+	Recv() (*DebuggerState, error)
+	grpc.ClientStream
+}
+
+type debuggerStopEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *debuggerStopEventsClient) Recv() (*DebuggerState, error) {
+	m := new(DebuggerState)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// DebuggerServer is the server API for Debugger service.
+// All implementations must embed UnimplementedDebuggerServer
+// for forward compatibility
+type DebuggerServer interface {
+	// State returns the current debugger state.
+	State(context.Context, *StateRequest) (*DebuggerState, error)
+	// Command resumes or steps the target, see api.DebuggerCommand.
+	Command(context.Context, *CommandRequest) (*DebuggerState, error)
+	// Halt stops the target.
+	Halt(context.Context, *HaltRequest) (*DebuggerState, error)
+	CreateBreakpoint(context.Context, *Breakpoint) (*Breakpoint, error)
+	ClearBreakpoint(context.Context, *ClearBreakpointRequest) (*Breakpoint, error)
+	ListBreakpoints(context.Context, *ListBreakpointsRequest) (*ListBreakpointsResponse, error)
+	Eval(context.Context, *EvalRequest) (*Variable, error)
+	Stacktrace(context.Context, *StacktraceRequest) (*StacktraceResponse, error)
+	ListGoroutines(context.Context, *ListGoroutinesRequest) (*ListGoroutinesResponse, error)
+	// HandOff releases control of the session, so that JSON-RPC clients can
+	// drive it. gRPC clients take control with the first call that changes
+	// the state of the target or of the debugger.
+	HandOff(context.Context, *HandOffRequest) (*HandOffResponse, error)
+	// StopEvents streams the debugger state every time it changes, for
+	// example because the target stopped after being resumed, regardless of
+	// which client caused the change. The response headers are sent as soon
+	// as the changes are being watched, clients can wait for them to avoid
+	// missing the changes caused by their following calls.
+	StopEvents(*StopEventsRequest, Debugger_StopEventsServer) error
+	mustEmbedUnimplementedDebuggerServer()
+}
+
+// UnimplementedDebuggerServer must be embedded to have forward compatible implementations.
+type UnimplementedDebuggerServer struct {
+}
+
+func (UnimplementedDebuggerServer) State(context.Context, *StateRequest) (*DebuggerState, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method State not implemented")
+}
+func (UnimplementedDebuggerServer) Command(context.Context, *CommandRequest) (*DebuggerState, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Command not implemented")
+}
+func (UnimplementedDebuggerServer) Halt(context.Context, *HaltRequest) (*DebuggerState, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Halt not implemented")
+}
+func (UnimplementedDebuggerServer) CreateBreakpoint(context.Context, *Breakpoint) (*Breakpoint, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateBreakpoint not implemented")
+}
+func (UnimplementedDebuggerServer) ClearBreakpoint(context.Context, *ClearBreakpointRequest) (*Breakpoint, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClearBreakpoint not implemented")
+}
+func (UnimplementedDebuggerServer) ListBreakpoints(context.Context, *ListBreakpointsRequest) (*ListBreakpointsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBreakpoints not implemented")
+}
+func (UnimplementedDebuggerServer) Eval(context.Context, *EvalRequest) (*Variable, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Eval not implemented")
+}
+func (UnimplementedDebuggerServer) Stacktrace(context.Context, *StacktraceRequest) (*StacktraceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Stacktrace not implemented")
+}
+func (UnimplementedDebuggerServer) ListGoroutines(context.Context, *ListGoroutinesRequest) (*ListGoroutinesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListGoroutines not implemented")
+}
+func (UnimplementedDebuggerServer) HandOff(context.Context, *HandOffRequest) (*HandOffResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HandOff not implemented")
+}
+func (UnimplementedDebuggerServer) StopEvents(*StopEventsRequest, Debugger_StopEventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StopEvents not implemented")
+}
+func (UnimplementedDebuggerServer) mustEmbedUnimplementedDebuggerServer() {}
+
+// UnsafeDebuggerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DebuggerServer will
+// result in compilation errors.
+type UnsafeDebuggerServer interface {
+	mustEmbedUnimplementedDebuggerServer()
+}
+
+func RegisterDebuggerServer(s grpc.ServiceRegistrar, srv DebuggerServer) {
+	s.RegisterService(&Debugger_ServiceDesc, srv)
+}
+
+func _Debugger_State_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DebuggerServer).State(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Debugger_State_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DebuggerServer).State(ctx, req.(*StateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Debugger_Command_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CommandRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DebuggerServer).Command(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Debugger_Command_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DebuggerServer).Command(ctx, req.(*CommandRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Debugger_Halt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HaltRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DebuggerServer).Halt(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Debugger_Halt_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DebuggerServer).Halt(ctx, req.(*HaltRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Debugger_CreateBreakpoint_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Breakpoint)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DebuggerServer).CreateBreakpoint(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Debugger_CreateBreakpoint_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DebuggerServer).CreateBreakpoint(ctx, req.(*Breakpoint))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Debugger_ClearBreakpoint_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClearBreakpointRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DebuggerServer).ClearBreakpoint(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Debugger_ClearBreakpoint_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DebuggerServer).ClearBreakpoint(ctx, req.(*ClearBreakpointRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Debugger_ListBreakpoints_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBreakpointsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DebuggerServer).ListBreakpoints(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Debugger_ListBreakpoints_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DebuggerServer).ListBreakpoints(ctx, req.(*ListBreakpointsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Debugger_Eval_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EvalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DebuggerServer).Eval(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Debugger_Eval_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DebuggerServer).Eval(ctx, req.(*EvalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Debugger_Stacktrace_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StacktraceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DebuggerServer).Stacktrace(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Debugger_Stacktrace_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DebuggerServer).Stacktrace(ctx, req.(*StacktraceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Debugger_ListGoroutines_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListGoroutinesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DebuggerServer).ListGoroutines(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Debugger_ListGoroutines_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DebuggerServer).ListGoroutines(ctx, req.(*ListGoroutinesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Debugger_HandOff_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HandOffRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DebuggerServer).HandOff(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Debugger_HandOff_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DebuggerServer).HandOff(ctx, req.(*HandOffRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Debugger_StopEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StopEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DebuggerServer).StopEvents(m, &debuggerStopEventsServer{stream})
+}
+
+type Debugger_StopEventsServer interface {
+	Send(*DebuggerState) error
+	grpc.ServerStream
+}
+
+type debuggerStopEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *debuggerStopEventsServer) Send(m *DebuggerState) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Debugger_ServiceDesc is the grpc.ServiceDesc for Debugger service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Debugger_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "delve.v1.Debugger",
+	HandlerType: (*DebuggerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "State",
+			Handler:    _Debugger_State_Handler,
+		},
+		{
+			MethodName: "Command",
+			Handler:    _Debugger_Command_Handler,
+		},
+		{
+			MethodName: "Halt",
+			Handler:    _Debugger_Halt_Handler,
+		},
+		{
+			MethodName: "CreateBreakpoint",
+			Handler:    _Debugger_CreateBreakpoint_Handler,
+		},
+		{
+			MethodName: "ClearBreakpoint",
+			Handler:    _Debugger_ClearBreakpoint_Handler,
+		},
+		{
+			MethodName: "ListBreakpoints",
+			Handler:    _Debugger_ListBreakpoints_Handler,
+		},
+		{
+			MethodName: "Eval",
+			Handler:    _Debugger_Eval_Handler,
+		},
+		{
+			MethodName: "Stacktrace",
+			Handler:    _Debugger_Stacktrace_Handler,
+		},
+		{
+			MethodName: "ListGoroutines",
+			Handler:    _Debugger_ListGoroutines_Handler,
+		},
+		{
+			MethodName: "HandOff",
+			Handler:    _Debugger_HandOff_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StopEvents",
+			Handler:       _Debugger_StopEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "delve.proto",
+}
@@ -0,0 +1,5 @@
+// Package delvepb contains the Go code generated from delve.proto, the
+// protocol buffer definition of the API served by package rpcgrpc.
+package delvepb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative delve.proto
@@ -0,0 +1,32 @@
+module github.com/go-delve/delve/service/rpcgrpc
+
+go 1.18
+
+require (
+	github.com/go-delve/delve v0.0.0
+	github.com/sirupsen/logrus v1.6.0
+	google.golang.org/grpc v1.54.0
+	google.golang.org/protobuf v1.30.0
+)
+
+require (
+	github.com/cosiner/argv v0.1.0 // indirect
+	github.com/golang/protobuf v1.5.3 // indirect
+	github.com/google/go-dap v0.5.0 // indirect
+	github.com/hashicorp/golang-lru v0.5.4 // indirect
+	github.com/inconshreveable/mousetrap v1.0.0 // indirect
+	github.com/mattn/go-colorable v0.0.0-20170327083344-ded68f7a9561 // indirect
+	github.com/mattn/go-isatty v0.0.3 // indirect
+	github.com/peterh/liner v0.0.0-20170317030525-88609521dc4b // indirect
+	github.com/spf13/cobra v0.0.0-20170417170307-b6cb39589372 // indirect
+	github.com/spf13/pflag v0.0.0-20170417173400-9e4c21054fa1 // indirect
+	go.starlark.net v0.0.0-20200821142938-949cc6f4b097 // indirect
+	golang.org/x/arch v0.0.0-20190927153633-4e8777c89be4 // indirect
+	golang.org/x/net v0.11.0 // indirect
+	golang.org/x/sys v0.9.0 // indirect
+	golang.org/x/text v0.13.0 // indirect
+	google.golang.org/genproto v0.0.0-20230410155749-daa745c078e1 // indirect
+	gopkg.in/yaml.v2 v2.2.1 // indirect
+)
+
+replace github.com/go-delve/delve => ../..
@@ -0,0 +1,296 @@
+// Package rpcgrpc serves the API of the debugger over gRPC, the protocol
+// is defined in delvepb/delve.proto and mirrors version 2 of the JSON-RPC
+// API.
+//
+// The package is a separate module, because grpc needs a newer version of
+// Go than the rest of Delve. Its cmd/dlv builds dlv with the --grpc-listen
+// flag.
+package rpcgrpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/go-delve/delve/service"
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/debugger"
+	"github.com/go-delve/delve/service/rpcgrpc/delvepb"
+)
+
+// GRPCServer implements service.GRPCServer.
+type GRPCServer struct {
+	server *grpc.Server
+	client service.SessionClient
+}
+
+var _ service.GRPCServer = &GRPCServer{}
+
+// NewGRPCServer creates a new GRPCServer.
+func NewGRPCServer() *GRPCServer {
+	gs := &GRPCServer{}
+	gs.server = grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		gs.client.RequestStarted()
+		defer gs.client.RequestDone()
+		return handler(ctx, req)
+	}))
+	return gs
+}
+
+// Serve serves the Debugger service on listener until Stop is called, all
+// connections share client as their client of the session.
+func (gs *GRPCServer) Serve(listener net.Listener, debugger *debugger.Debugger, client service.SessionClient) error {
+	gs.client = client
+	delvepb.RegisterDebuggerServer(gs.server, NewServer(debugger, client))
+	return gs.server.Serve(listener)
+}
+
+// Stop stops the server, closing all connections.
+func (gs *GRPCServer) Stop() {
+	gs.server.Stop()
+}
+
+// Server implements delvepb.DebuggerServer.
+type Server struct {
+	delvepb.UnimplementedDebuggerServer
+	debugger *debugger.Debugger
+	session  service.SessionClient
+}
+
+// NewServer creates a new Server serving debugger, session is the client
+// of the session shared by all gRPC clients.
+func NewServer(debugger *debugger.Debugger, session service.SessionClient) *Server {
+	return &Server{debugger: debugger, session: session}
+}
+
+// defaultLoadConfig is used when clients do not specify a load
+// configuration, it is the same used by the JSON-RPC API.
+var defaultLoadConfig = api.LoadConfig{FollowPointers: true, MaxVariableRecurse: 1, MaxStringLen: 64, MaxArrayValues: 64, MaxStructFields: -1}
+
+// changeState takes control of the session and calls f, which changes
+// the state of the target or of the debugger, the other clients are
+// notified when it returns.
+func (s *Server) changeState(f func() error) error {
+	if err := s.session.TakeControl(); err != nil {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	defer s.session.NotifyStateChange()
+	return f()
+}
+
+// State returns the current debugger state.
+func (s *Server) State(ctx context.Context, req *delvepb.StateRequest) (*delvepb.DebuggerState, error) {
+	st, err := s.debugger.State(req.NonBlocking)
+	if err != nil {
+		return nil, err
+	}
+	return convertState(st), nil
+}
+
+// Command resumes or steps the target, see api.DebuggerCommand. If the
+// client cancels the call while the target is running the target is
+// halted, as by Halt.
+func (s *Server) Command(ctx context.Context, req *delvepb.CommandRequest) (*delvepb.DebuggerState, error) {
+	return s.command(ctx, &api.DebuggerCommand{
+		Name:                 req.Name,
+		Options:              req.Options,
+		ReturnInfoLoadConfig: loadConfigFromPB(req.ReturnInfoLoadConfig),
+	})
+}
+
+// Halt stops the target. Halting can not be canceled, ctx is ignored.
+func (s *Server) Halt(ctx context.Context, req *delvepb.HaltRequest) (*delvepb.DebuggerState, error) {
+	return s.command(context.Background(), &api.DebuggerCommand{Name: api.Halt})
+}
+
+func (s *Server) command(ctx context.Context, cmd *api.DebuggerCommand) (*delvepb.DebuggerState, error) {
+	var st *api.DebuggerState
+	err := s.changeState(func() (err error) {
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			select {
+			case <-ctx.Done():
+				if s.debugger.IsRunning() {
+					s.debugger.Command(&api.DebuggerCommand{Name: api.Halt}, nil)
+				}
+			case <-done:
+			}
+		}()
+		st, err = s.debugger.Command(cmd, nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return convertState(st), nil
+}
+
+// CreateBreakpoint creates a new breakpoint, see
+// Debugger.CreateBreakpoint.
+func (s *Server) CreateBreakpoint(ctx context.Context, req *delvepb.Breakpoint) (*delvepb.Breakpoint, error) {
+	requestedBp := breakpointFromPB(req)
+	if err := api.ValidBreakpointName(requestedBp.Name); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	for _, group := range requestedBp.Groups {
+		if err := api.ValidBreakpointGroup(group); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	var bp *api.Breakpoint
+	err := s.changeState(func() (err error) {
+		bp, err = s.debugger.CreateBreakpoint(requestedBp)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return convertBreakpoint(bp), nil
+}
+
+// ClearBreakpoint deletes a breakpoint by name (if name is not an empty
+// string) or by ID.
+func (s *Server) ClearBreakpoint(ctx context.Context, req *delvepb.ClearBreakpointRequest) (*delvepb.Breakpoint, error) {
+	var bp *api.Breakpoint
+	if req.Name != "" {
+		bp = s.debugger.FindBreakpointByName(req.Name)
+		if bp == nil {
+			return nil, status.Error(codes.NotFound, fmt.Sprintf("no breakpoint with name %s", req.Name))
+		}
+	} else {
+		bp = s.debugger.FindBreakpoint(int(req.Id))
+		if bp == nil {
+			return nil, status.Error(codes.NotFound, fmt.Sprintf("no breakpoint with id %d", req.Id))
+		}
+	}
+	var deleted *api.Breakpoint
+	err := s.changeState(func() (err error) {
+		deleted, err = s.debugger.ClearBreakpoint(bp)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return convertBreakpoint(deleted), nil
+}
+
+// ListBreakpoints returns all breakpoints, enabled and disabled, sorted
+// by ID.
+func (s *Server) ListBreakpoints(ctx context.Context, req *delvepb.ListBreakpointsRequest) (*delvepb.ListBreakpointsResponse, error) {
+	var bps []*api.Breakpoint
+	if req.All {
+		bps = s.debugger.AllBreakpoints()
+	} else {
+		bps = s.debugger.Breakpoints()
+	}
+	r := &delvepb.ListBreakpointsResponse{Breakpoints: make([]*delvepb.Breakpoint, 0, len(bps))}
+	for _, bp := range bps {
+		r.Breakpoints = append(r.Breakpoints, convertBreakpoint(bp))
+	}
+	return r, nil
+}
+
+// Eval evaluates an expression in the specified scope, expressions
+// containing function calls are rejected.
+func (s *Server) Eval(ctx context.Context, req *delvepb.EvalRequest) (*delvepb.Variable, error) {
+	scope := evalScopeFromPB(req.Scope)
+	cfg := loadConfigFromPB(req.Cfg)
+	if cfg == nil {
+		cfg = &defaultLoadConfig
+	}
+	v, err := s.debugger.EvalVariableInScope(scope.GoroutineID, scope.Frame, scope.DeferredCall, req.Expr, *api.LoadConfigToProc(cfg))
+	if err != nil {
+		return nil, err
+	}
+	return convertVar(api.ConvertVar(v)), nil
+}
+
+// maxStacktraceDepth is the depth used when all frames of a stacktrace
+// are requested, with a negative depth.
+const maxStacktraceDepth = 1000
+
+// Stacktrace returns the stacktrace of a goroutine, see the Stacktrace
+// method of the JSON-RPC API.
+func (s *Server) Stacktrace(ctx context.Context, req *delvepb.StacktraceRequest) (*delvepb.StacktraceResponse, error) {
+	if req.Skip < 0 {
+		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("invalid skip %d", req.Skip))
+	}
+	depth := int(req.Depth)
+	if depth < 0 {
+		depth = maxStacktraceDepth
+	}
+	skip := int(req.Skip)
+	rawlocs, err := s.debugger.Stacktrace(int(req.Id), skip+depth, 0)
+	if err != nil {
+		return nil, err
+	}
+	if skip >= len(rawlocs) {
+		rawlocs = rawlocs[:0]
+	} else {
+		rawlocs = rawlocs[skip:]
+	}
+	frames, err := s.debugger.ConvertStacktrace(rawlocs, api.LoadConfigToProc(loadConfigFromPB(req.Cfg)))
+	if err != nil {
+		return nil, err
+	}
+	r := &delvepb.StacktraceResponse{Locations: make([]*delvepb.Stackframe, 0, len(frames))}
+	for i := range frames {
+		r.Locations = append(r.Locations, convertStackframe(&frames[i]))
+	}
+	return r, nil
+}
+
+// ListGoroutines lists the goroutines of the target, if count is not
+// zero at most count goroutines are returned, starting from start, and
+// nextg can be passed as start to get the following ones.
+func (s *Server) ListGoroutines(ctx context.Context, req *delvepb.ListGoroutinesRequest) (*delvepb.ListGoroutinesResponse, error) {
+	gs, nextg, err := s.debugger.Goroutines(int(req.Start), int(req.Count))
+	if err != nil {
+		return nil, err
+	}
+	s.debugger.LockTarget()
+	defer s.debugger.UnlockTarget()
+	r := &delvepb.ListGoroutinesResponse{Nextg: int32(nextg)}
+	for _, g := range api.ConvertGoroutines(s.debugger.Target(), gs) {
+		r.Goroutines = append(r.Goroutines, convertGoroutine(g))
+	}
+	return r, nil
+}
+
+// HandOff releases control of the session.
+func (s *Server) HandOff(ctx context.Context, req *delvepb.HandOffRequest) (*delvepb.HandOffResponse, error) {
+	if err := s.session.HandOff(); err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return &delvepb.HandOffResponse{}, nil
+}
+
+// StopEvents sends the debugger state every time it changes, until the
+// client cancels the call or the server stops. The response headers are
+// sent as soon as the changes are being watched.
+func (s *Server) StopEvents(req *delvepb.StopEventsRequest, stream delvepb.Debugger_StopEventsServer) error {
+	changed := s.session.StateChanged()
+	if err := stream.SendHeader(nil); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-changed:
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+		changed = s.session.StateChanged()
+		st, err := s.debugger.State(true)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(convertState(st)); err != nil {
+			return err
+		}
+	}
+}
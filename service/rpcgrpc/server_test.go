@@ -0,0 +1,171 @@
+package rpcgrpc_test
+
+import (
+	"context"
+	"flag"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	protest "github.com/go-delve/delve/pkg/proc/test"
+	"github.com/go-delve/delve/service"
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/debugger"
+	"github.com/go-delve/delve/service/rpc2"
+	"github.com/go-delve/delve/service/rpccommon"
+	"github.com/go-delve/delve/service/rpcgrpc"
+	"github.com/go-delve/delve/service/rpcgrpc/delvepb"
+)
+
+var testBackend string
+
+func TestMain(m *testing.M) {
+	flag.StringVar(&testBackend, "backend", "", "selects backend")
+	flag.Parse()
+	protest.DefaultTestBackend(&testBackend)
+	os.Exit(protest.RunTestsWithFixtures(m))
+}
+
+func assertNoError(err error, t *testing.T, s string) {
+	if err != nil {
+		_, file, line, _ := runtime.Caller(1)
+		fname := filepath.Base(file)
+		t.Fatalf("failed assertion at %s:%d: %s - %s\n", fname, line, s, err)
+	}
+}
+
+// startServer starts a headless server debugging fixture, serving the
+// gRPC API next to the JSON-RPC API, and returns the listener of the
+// JSON-RPC API and a gRPC client.
+func startServer(t *testing.T, fixture string) (net.Listener, delvepb.DebuggerClient) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("couldn't start listener: %s\n", err)
+	}
+	grpcListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("couldn't start gRPC listener: %s\n", err)
+	}
+	server := rpccommon.NewServer(&service.Config{
+		Listener:     listener,
+		GRPCListener: grpcListener,
+		GRPCServer:   rpcgrpc.NewGRPCServer(),
+		ProcessArgs:  []string{protest.BuildFixture(fixture, 0).Path},
+		AcceptMulti:  true,
+		APIVersion:   2,
+		Debugger: debugger.Config{
+			Backend:     testBackend,
+			ExecuteKind: debugger.ExecutingGeneratedTest,
+		},
+	})
+	if err := server.Run(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { server.Stop() })
+
+	conn, err := grpc.Dial(grpcListener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assertNoError(err, t, "grpc.Dial()")
+	t.Cleanup(func() { conn.Close() })
+	return listener, delvepb.NewDebuggerClient(conn)
+}
+
+func TestGRPCServer(t *testing.T) {
+	// gRPC clients share the session with JSON-RPC clients.
+	listener, c := startServer(t, "testvariables2")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	events, err := c.StopEvents(ctx, &delvepb.StopEventsRequest{})
+	assertNoError(err, t, "StopEvents()")
+	_, err = events.Header()
+	assertNoError(err, t, "StopEvents() headers")
+
+	bp, err := c.CreateBreakpoint(ctx, &delvepb.Breakpoint{FunctionName: "main.main"})
+	assertNoError(err, t, "CreateBreakpoint()")
+	if bp.Id <= 0 || bp.FunctionName != "main.main" {
+		t.Fatalf("unexpected breakpoint: %v", bp)
+	}
+	st, err := c.Command(ctx, &delvepb.CommandRequest{Name: api.Continue})
+	assertNoError(err, t, "Command()")
+	if st.CurrentThread == nil || st.CurrentThread.Function.GetName() != "main.main" {
+		t.Fatalf("unexpected state after continue: %v", st)
+	}
+	if st.StopReason.GetKind() != string(api.StopBreakpoint) || st.StopReason.GetBreakpointId() != bp.Id {
+		t.Errorf("unexpected stop reason: %v", st.StopReason)
+	}
+
+	// Changes can be coalesced, the last one is the target stopping at the
+	// breakpoint.
+	for {
+		ev, err := events.Recv()
+		assertNoError(err, t, "StopEvents() Recv()")
+		if !ev.Running && ev.CurrentThread.GetFunction().GetName() == "main.main" {
+			break
+		}
+	}
+
+	v, err := c.Eval(ctx, &delvepb.EvalRequest{Expr: "1 + 2"})
+	assertNoError(err, t, "Eval()")
+	if v.Value != "3" {
+		t.Errorf("wrong value for 1 + 2: %q", v.Value)
+	}
+	frames, err := c.Stacktrace(ctx, &delvepb.StacktraceRequest{Id: -1, Depth: 10})
+	assertNoError(err, t, "Stacktrace()")
+	if len(frames.Locations) == 0 || frames.Locations[0].Location.Function.GetName() != "main.main" {
+		t.Errorf("unexpected stacktrace: %v", frames.Locations)
+	}
+	gs, err := c.ListGoroutines(ctx, &delvepb.ListGoroutinesRequest{})
+	assertNoError(err, t, "ListGoroutines()")
+	if len(gs.Goroutines) == 0 {
+		t.Error("no goroutines")
+	}
+
+	// The gRPC clients drive the session until they hand off control.
+	client := rpc2.NewClient(listener.Addr().String())
+	defer client.Disconnect(false)
+	if _, err := client.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.afunc1"}); err == nil || !strings.Contains(err.Error(), "driving") {
+		t.Fatalf("expected error for non-driving client, got %v", err)
+	}
+	_, err = c.HandOff(ctx, &delvepb.HandOffRequest{})
+	assertNoError(err, t, "HandOff()")
+	_, err = client.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.afunc1"})
+	assertNoError(err, t, "CreateBreakpoint() (JSON-RPC client)")
+	if _, err := c.ClearBreakpoint(ctx, &delvepb.ClearBreakpointRequest{Id: bp.Id}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition error after hand off, got %v", err)
+	}
+}
+
+func TestGRPCCancelCommand(t *testing.T) {
+	// Canceling a call that resumed the target halts it.
+	_, c := startServer(t, "loopprog")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err := c.Command(ctx, &delvepb.CommandRequest{Name: api.Continue})
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("expected DeadlineExceeded error, got %v", err)
+	}
+	ctx2, cancel2 := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel2()
+	for {
+		st, err := c.State(ctx2, &delvepb.StateRequest{NonBlocking: true})
+		assertNoError(err, t, "State()")
+		if !st.Running {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	st, err := c.State(ctx2, &delvepb.StateRequest{})
+	assertNoError(err, t, "State()")
+	if st.Exited {
+		t.Fatal("target exited")
+	}
+}
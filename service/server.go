@@ -1,8 +1,45 @@
 package service
 
+import (
+	"net"
+
+	"github.com/go-delve/delve/service/debugger"
+)
+
 // Server represents a server for a remote client
 // to connect to.
 type Server interface {
 	Run() error
 	Stop() error
 }
+
+// GRPCServer serves the API over gRPC. It is implemented by package
+// github.com/go-delve/delve/service/rpcgrpc, which is a separate module
+// because its dependencies need a newer version of Go than the rest of
+// Delve.
+type GRPCServer interface {
+	// Serve serves listener until Stop is called, all connections share
+	// client as their client of the session.
+	Serve(listener net.Listener, debugger *debugger.Debugger, client SessionClient) error
+	Stop()
+}
+
+// SessionClient is a client of the session of a server, see GRPCServer.
+type SessionClient interface {
+	// TakeControl makes the client the driver of the session, it fails if
+	// another client is driving it.
+	TakeControl() error
+	// HandOff releases control of the session, it fails if another
+	// client is driving it.
+	HandOff() error
+	// NotifyStateChange must be called after a call that could have
+	// changed the state of the target or of the debugger.
+	NotifyStateChange()
+	// StateChanged returns a channel that is closed the next time the
+	// state of the target or of the debugger changes.
+	StateChanged() <-chan struct{}
+	// RequestStarted and RequestDone must be called when the client
+	// starts and finishes executing a request.
+	RequestStarted()
+	RequestDone()
+}
@@ -1,6 +1,7 @@
 package service_test
 
 import (
+	"bytes"
 	"flag"
 	"fmt"
 	"io/ioutil"
@@ -23,6 +24,7 @@ import (
 
 	"github.com/go-delve/delve/pkg/goversion"
 	"github.com/go-delve/delve/pkg/logflags"
+	"github.com/go-delve/delve/pkg/proc"
 	"github.com/go-delve/delve/service"
 	"github.com/go-delve/delve/service/api"
 	"github.com/go-delve/delve/service/rpc2"
@@ -206,6 +208,48 @@ func TestRestart_duringStop(t *testing.T) {
 	})
 }
 
+func TestClientServer_ListInternalBreakpoints(t *testing.T) {
+	withTestClient2("testnextprog", t, func(c service.Client) {
+		_, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.testnext", Line: 0})
+		assertNoError(err, t, "CreateBreakpoint()")
+		_, err = c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.helloworld", Line: 0})
+		assertNoError(err, t, "CreateBreakpoint()")
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+		// main.testnext -> main.main, interrupted by the breakpoint on
+		// main.helloworld
+		state, err = c.StepOut()
+		assertNoError(err, t, "StepOut()")
+		if !state.NextInProgress {
+			t.Fatal("step out not interrupted")
+		}
+
+		bps, err := c.ListBreakpoints()
+		assertNoError(err, t, "ListBreakpoints()")
+		for _, bp := range bps {
+			if bp.ID == 0 {
+				t.Errorf("internal breakpoint returned: %#v", bp)
+			}
+		}
+
+		bps, err = c.ListAllBreakpoints()
+		assertNoError(err, t, "ListAllBreakpoints()")
+		found := false
+		for _, bp := range bps {
+			if bp.ID == 0 {
+				t.Logf("%#x %v", bp.Addr, bp.VerboseDescr)
+				found = true
+				if len(bp.VerboseDescr) == 0 {
+					t.Errorf("no description for internal breakpoint at %#x", bp.Addr)
+				}
+			}
+		}
+		if !found {
+			t.Error("no internal breakpoint returned")
+		}
+	})
+}
+
 // This source is a slightly modified version of
 // _fixtures/testenv.go. The only difference is that
 // the name of the environment variable we are trying to
@@ -1892,6 +1936,165 @@ func TestAcceptMulticlient(t *testing.T) {
 	<-serverDone
 }
 
+func TestLogicalBreakpoints(t *testing.T) {
+	// A breakpoint set inside an inlined function has one physical
+	// breakpoint for each place it was inlined in, IDs must be assigned to
+	// logical breakpoints and hits must be counted on them.
+	if ver, _ := goversion.Parse(runtime.Version()); ver.Major >= 0 && !ver.AfterOrEqual(goversion.GoVersion{Major: 1, Minor: 10, Rev: -1}) {
+		t.Skip("inlining not supported")
+	}
+	withTestClient2Extended("testinline", t, protest.EnableInlining|protest.EnableOptimization, [3]string{}, func(c service.Client, fixture protest.Fixture) {
+		bp1, err := c.CreateBreakpoint(&api.Breakpoint{File: fixture.Source, Line: 7})
+		assertNoError(err, t, "CreateBreakpoint()")
+		if len(bp1.Addrs) < 2 {
+			t.Fatalf("expected multiple addresses for breakpoint on inlined function, got %#v", bp1.Addrs)
+		}
+		bp2, err := c.CreateBreakpoint(&api.Breakpoint{File: fixture.Source, Line: 20})
+		assertNoError(err, t, "CreateBreakpoint()")
+		if bp2.ID != bp1.ID+1 {
+			t.Errorf("non-consecutive breakpoint IDs %d %d", bp1.ID, bp2.ID)
+		}
+
+		for i := 1; i <= 2; i++ {
+			state := <-c.Continue()
+			assertNoError(state.Err, t, "Continue()")
+			bp := state.CurrentThread.Breakpoint
+			if bp == nil || bp.ID != bp1.ID {
+				t.Fatalf("expected stop at breakpoint %d, got %#v", bp1.ID, bp)
+			}
+			if bp.TotalHitCount != uint64(i) {
+				t.Errorf("wrong total hit count %d, expected %d", bp.TotalHitCount, i)
+			}
+		}
+
+		bp, err := c.GetBreakpoint(bp1.ID)
+		assertNoError(err, t, "GetBreakpoint()")
+		if bp.TotalHitCount != 2 {
+			t.Errorf("wrong total hit count %d, expected 2", bp.TotalHitCount)
+		}
+	})
+}
+
+func TestBreakpointHitCountsToggle(t *testing.T) {
+	// Disabling and enabling a breakpoint must not reset its hit counts.
+	withTestClient2("loopprog", t, func(c service.Client) {
+		bp, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.loop", Line: 8})
+		assertNoError(err, t, "CreateBreakpoint()")
+		for i := 0; i < 3; i++ {
+			state := <-c.Continue()
+			assertNoError(state.Err, t, "Continue()")
+		}
+
+		bp, err = c.GetBreakpoint(bp.ID)
+		assertNoError(err, t, "GetBreakpoint()")
+		if bp.TotalHitCount != 3 {
+			t.Fatalf("wrong total hit count %d, expected 3", bp.TotalHitCount)
+		}
+		bp.Disabled = true
+		assertNoError(c.AmendBreakpoint(bp), t, "AmendBreakpoint() disable")
+		bp.Disabled = false
+		assertNoError(c.AmendBreakpoint(bp), t, "AmendBreakpoint() enable")
+
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+		bp, err = c.GetBreakpoint(bp.ID)
+		assertNoError(err, t, "GetBreakpoint()")
+		if bp.TotalHitCount != 4 {
+			t.Errorf("wrong total hit count %d, expected 4", bp.TotalHitCount)
+		}
+		var n uint64
+		for _, cnt := range bp.HitCount {
+			n += cnt
+		}
+		if n != 4 {
+			t.Errorf("wrong sum of per-goroutine hit counts %d, expected 4 (%v)", n, bp.HitCount)
+		}
+	})
+}
+
+func TestReturnLocation(t *testing.T) {
+	// A breakpoint on function:return stops when the function returns.
+	withTestClient2("testnextprog", t, func(c service.Client) {
+		locs, err := c.FindLocation(api.EvalScope{GoroutineID: -1}, "main.helloworld:return", false, nil)
+		assertNoError(err, t, "FindLocation()")
+		if len(locs) != 1 || len(locs[0].PCs) == 0 {
+			t.Fatalf("wrong locations %#v", locs)
+		}
+		bp, err := c.CreateBreakpoint(&api.Breakpoint{Addrs: locs[0].PCs})
+		assertNoError(err, t, "CreateBreakpoint()")
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+		if state.CurrentThread.Breakpoint == nil || state.CurrentThread.Breakpoint.ID != bp.ID {
+			t.Fatalf("did not stop at return breakpoint: %#v", state.CurrentThread)
+		}
+		if fn := state.CurrentThread.Function; fn == nil || fn.Name() != "main.helloworld" {
+			t.Fatalf("stopped in the wrong function %#v", fn)
+		}
+		if state.CurrentThread.Line != 15 {
+			t.Errorf("stopped at line %d, expected 15", state.CurrentThread.Line)
+		}
+	})
+}
+
+func TestIdleTimeout(t *testing.T) {
+	if testBackend == "rr" {
+		t.Skip("recording not allowed for TestIdleTimeout")
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("couldn't start listener: %s\n", err)
+	}
+	defer listener.Close()
+	disconnectChan := make(chan struct{})
+	server := rpccommon.NewServer(&service.Config{
+		Listener:       listener,
+		ProcessArgs:    []string{protest.BuildFixture("testvariables2", 0).Path},
+		AcceptMulti:    true,
+		DisconnectChan: disconnectChan,
+		IdleTimeout:    500 * time.Millisecond,
+		Debugger: debugger.Config{
+			Backend:     testBackend,
+			ExecuteKind: debugger.ExecutingGeneratedTest,
+		},
+	})
+	if err := server.Run(); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	client := rpc2.NewClient(listener.Addr().String())
+	// Client activity keeps the server alive.
+	for i := 0; i < 4; i++ {
+		time.Sleep(200 * time.Millisecond)
+		if _, err := client.GetState(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	select {
+	case <-disconnectChan:
+		t.Fatal("server stopped while the client was active")
+	default:
+	}
+
+	// Pending long polls do not count as client activity.
+	go func() {
+		seq := 0
+		for {
+			out, err := client.WaitStateChange(seq)
+			if err != nil {
+				return
+			}
+			seq = out.Seq
+		}
+	}()
+
+	select {
+	case <-disconnectChan:
+	case <-time.After(10 * time.Second):
+		t.Fatal("server did not stop after the idle timeout")
+	}
+}
+
 func TestClientServerFunctionCall(t *testing.T) {
 	protest.MustSupportFunctionCalls(t, testBackend)
 	withTestClient2("fncall", t, func(c service.Client) {
@@ -2033,6 +2236,40 @@ func TestAncestors(t *testing.T) {
 	})
 }
 
+func TestAncestorsGoStatementLoc(t *testing.T) {
+	if !goversion.VersionAfterOrEqual(runtime.Version(), 1, 11) {
+		t.Skip("not supported on Go <= 1.10")
+	}
+	savedGodebug := os.Getenv("GODEBUG")
+	os.Setenv("GODEBUG", "tracebackancestors=100")
+	defer os.Setenv("GODEBUG", savedGodebug)
+	withTestClient2("goroutineancestors", t, func(c service.Client) {
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+		if state.SelectedGoroutine.GoStatementLoc.Line != 16 {
+			t.Errorf("wrong go statement for the current goroutine: %s:%d", state.SelectedGoroutine.GoStatementLoc.File, state.SelectedGoroutine.GoStatementLoc.Line)
+		}
+		ancestors, err := c.Ancestors(-1, 1000, 1000)
+		assertNoError(err, t, "Ancestors")
+		t.Logf("ancestors: %#v\n", ancestors)
+		if len(ancestors) != 2 {
+			t.Fatalf("expected two ancestors got %d", len(ancestors))
+		}
+		// The direct creator is the goroutine running main.middle, created
+		// by the go statement in main.main.
+		loc := ancestors[0].GoStatementLoc
+		if loc.Function == nil || loc.Function.Name() != "main.main" || loc.Line != 22 {
+			t.Errorf("wrong go statement for the first ancestor: %#v", loc)
+		}
+		if len(ancestors[0].Stack) == 0 || ancestors[0].Stack[0].Function.Name() != "main.middle" {
+			t.Errorf("wrong stack for the first ancestor: %#v", ancestors[0].Stack)
+		}
+		if ancestors[1].ID != 1 {
+			t.Errorf("expected the second ancestor to be goroutine 1, got %d", ancestors[1].ID)
+		}
+	})
+}
+
 type brokenRPCClient struct {
 	client *rpc.Client
 }
@@ -2442,3 +2679,1212 @@ func TestLongStringArg(t *testing.T) {
 		}
 	})
 }
+
+func TestFreezeGoroutine(t *testing.T) {
+	// While a goroutine is frozen the other goroutines must keep running and
+	// hitting breakpoints, the frozen goroutine must never be reported as
+	// stopped.
+	if testBackend == "rr" {
+		t.Skip("can not freeze goroutines of a recording")
+	}
+	withTestClient2("parallel_next", t, func(c service.Client) {
+		bp, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.sayhi", Line: 1})
+		assertNoError(err, t, "CreateBreakpoint()")
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+		frozen := state.SelectedGoroutine.ID
+		assertNoError(c.FreezeGoroutine(frozen), t, "FreezeGoroutine()")
+
+		gs, _, err := c.ListGoroutines(0, 0)
+		assertNoError(err, t, "ListGoroutines()")
+		for _, g := range gs {
+			if g.Frozen != (g.ID == frozen) {
+				t.Errorf("goroutine %d frozen %v", g.ID, g.Frozen)
+			}
+		}
+
+		for i := 0; i < 9; i++ {
+			state = <-c.Continue()
+			assertNoError(state.Err, t, "Continue()")
+			if state.SelectedGoroutine.ID == frozen {
+				t.Fatalf("frozen goroutine %d stopped", frozen)
+			}
+		}
+
+		assertNoError(c.ThawGoroutine(frozen), t, "ThawGoroutine()")
+		_, err = c.ClearBreakpoint(bp.ID)
+		assertNoError(err, t, "ClearBreakpoint()")
+		state = <-c.Continue()
+		if !state.Exited {
+			t.Fatalf("expected process to exit, got %#v", state)
+		}
+	})
+}
+
+func TestFreezeGoroutineProgress(t *testing.T) {
+	// The thread running a frozen goroutine is not resumed, the other
+	// goroutines make progress while the frozen one does not.
+	if testBackend == "rr" {
+		t.Skip("can not freeze goroutines of a recording")
+	}
+	withTestClient2("freezeprogress", t, func(c service.Client) {
+		counts := func() [2]int64 {
+			var r [2]int64
+			for i := range r {
+				v, err := c.EvalVariable(api.EvalScope{GoroutineID: -1}, fmt.Sprintf("main.counts[%d]", i), normalLoadConfig)
+				assertNoError(err, t, "EvalVariable()")
+				r[i], err = strconv.ParseInt(v.Value, 10, 64)
+				assertNoError(err, t, "ParseInt()")
+			}
+			return r
+		}
+
+		bp, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.worker", Line: 2})
+		assertNoError(err, t, "CreateBreakpoint()")
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+		v, err := c.EvalVariable(api.EvalScope{GoroutineID: -1}, "i", normalLoadConfig)
+		assertNoError(err, t, "EvalVariable(i)")
+		frozen, _ := strconv.Atoi(v.Value)
+		other := 1 - frozen
+		frozenID := state.SelectedGoroutine.ID
+		assertNoError(c.FreezeGoroutine(frozenID), t, "FreezeGoroutine()")
+		_, err = c.ClearBreakpoint(bp.ID)
+		assertNoError(err, t, "ClearBreakpoint()")
+		_, err = c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.checkpoint"})
+		assertNoError(err, t, "CreateBreakpoint()")
+
+		state = <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+		before := counts()
+		for i := 0; i < 3; i++ {
+			state = <-c.Continue()
+			assertNoError(state.Err, t, "Continue()")
+		}
+		after := counts()
+		t.Logf("counts before %v after %v", before, after)
+		if after[frozen] != before[frozen] {
+			t.Errorf("frozen goroutine made progress: %d -> %d", before[frozen], after[frozen])
+		}
+		if after[other] <= before[other] {
+			t.Errorf("other goroutine did not make progress: %d -> %d", before[other], after[other])
+		}
+
+		// The frozen goroutine runs again once thawed, regardless of how
+		// many times the target was stopped while it was frozen (on Windows
+		// its thread must not be suspended again at every stop).
+		for i := 0; i < 5; i++ {
+			state = <-c.Continue()
+			assertNoError(state.Err, t, "Continue()")
+		}
+		assertNoError(c.ThawGoroutine(frozenID), t, "ThawGoroutine()")
+		before = counts()
+		for i := 0; i < 3; i++ {
+			state = <-c.Continue()
+			assertNoError(state.Err, t, "Continue()")
+		}
+		after = counts()
+		t.Logf("counts after thaw before %v after %v", before, after)
+		if after[frozen] <= before[frozen] {
+			t.Errorf("thawed goroutine did not make progress: %d -> %d", before[frozen], after[frozen])
+		}
+	})
+}
+
+func TestRuntimeTrace(t *testing.T) {
+	protest.MustSupportFunctionCalls(t, testBackend)
+	tracefile := filepath.Join(os.TempDir(), fmt.Sprintf("runtimetrace%d.out", rand.Int()))
+	defer os.Remove(tracefile)
+	withTestClient2("runtimetrace", t, func(c service.Client) {
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+		assertNoError(c.StartRuntimeTrace(tracefile), t, "StartRuntimeTrace()")
+		if err := c.StartRuntimeTrace(tracefile); err == nil {
+			t.Errorf("StartRuntimeTrace() succeeded while a trace was in progress")
+		}
+		state = <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+		path, err := c.StopRuntimeTrace()
+		assertNoError(err, t, "StopRuntimeTrace()")
+		if path != tracefile {
+			t.Errorf("wrong trace path %q, expected %q", path, tracefile)
+		}
+	})
+	buf, err := ioutil.ReadFile(tracefile)
+	assertNoError(err, t, "ReadFile()")
+	if !bytes.HasPrefix(buf, []byte("go 1.")) {
+		t.Errorf("trace file does not start with a trace header (%d bytes)", len(buf))
+	}
+}
+
+func TestProfile(t *testing.T) {
+	protest.MustSupportFunctionCalls(t, testBackend)
+	withTestClient2("pprofprog", t, func(c service.Client) {
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+		for _, kind := range []api.ProfileKind{api.HeapProfile, api.CPUProfile} {
+			path := filepath.Join(os.TempDir(), fmt.Sprintf("%s%d.pprof", kind, rand.Int()))
+			defer os.Remove(path)
+			state, err := c.Profile(kind, time.Second, path)
+			assertNoError(err, t, fmt.Sprintf("Profile(%s)", kind))
+			if state.Running || state.Exited {
+				t.Errorf("target not stopped after %s profile: %#v", kind, state)
+			}
+			fi, err := os.Stat(path)
+			assertNoError(err, t, "Stat()")
+			if fi.Size() == 0 {
+				t.Errorf("empty %s profile", kind)
+			}
+		}
+	})
+}
+
+func TestContinueUntil(t *testing.T) {
+	withTestClient2("loopprog", t, func(c service.Client) {
+		fp := testProgPath(t, "loopprog")
+		_, err := c.CreateBreakpoint(&api.Breakpoint{File: fp, Line: 8})
+		assertNoError(err, t, "CreateBreakpoint()")
+
+		state, err := c.Command(api.Continue, &api.ContinueOptions{Until: "i == 5"})
+		assertNoError(err, t, "Continue(until)")
+		if state.ContinueIterations != 6 {
+			t.Errorf("wrong number of iterations %d, expected 6", state.ContinueIterations)
+		}
+		v, err := c.EvalVariable(api.EvalScope{GoroutineID: -1}, "i", normalLoadConfig)
+		assertNoError(err, t, "EvalVariable()")
+		if v.Value != "5" {
+			t.Errorf("wrong value of i %s, expected 5", v.Value)
+		}
+
+		_, err = c.Command(api.Continue, &api.ContinueOptions{Until: "i == 1000", MaxIterations: 3})
+		if err == nil || !strings.Contains(err.Error(), "still false after resuming execution 3 times") {
+			t.Errorf("expected error after the maximum number of iterations, got %v", err)
+		}
+		v, err = c.EvalVariable(api.EvalScope{GoroutineID: -1}, "i", normalLoadConfig)
+		assertNoError(err, t, "EvalVariable()")
+		if v.Value != "8" {
+			t.Errorf("wrong value of i %s, expected 8", v.Value)
+		}
+
+		// malformed and non-boolean conditions are reported before resuming
+		// the target
+		assertI := func(tgt string) {
+			t.Helper()
+			v, err := c.EvalVariable(api.EvalScope{GoroutineID: -1}, "i", normalLoadConfig)
+			assertNoError(err, t, "EvalVariable()")
+			if v.Value != tgt {
+				t.Errorf("wrong value of i %s, expected %s", v.Value, tgt)
+			}
+		}
+		_, err = c.Command(api.Continue, &api.ContinueOptions{Until: "i =="})
+		if err == nil {
+			t.Errorf("expected error for malformed expression")
+		}
+		assertI("8")
+		_, err = c.Command(api.Continue, &api.ContinueOptions{Until: "i + 1"})
+		if err == nil {
+			t.Errorf("expected error for non-boolean expression")
+		}
+		assertI("8")
+
+		state, err = c.Command(api.Continue, &api.ContinueOptions{})
+		assertNoError(err, t, "Continue()")
+		if state.ContinueIterations != 0 {
+			t.Errorf("iterations of the previous continue reported: %d", state.ContinueIterations)
+		}
+	})
+}
+
+func TestChanWatchpoint(t *testing.T) {
+	// A channel watchpoint must stop on every send and receive on the
+	// watched channel, and only on those.
+	withTestClient2("chanwatch", t, func(c service.Client) {
+		_, err := c.CreateBreakpoint(&api.Breakpoint{File: testProgPath(t, "chanwatch"), Line: 15})
+		assertNoError(err, t, "CreateBreakpoint()")
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		bp, err := c.CreateChanWatchpoint(api.EvalScope{GoroutineID: -1}, "ch1")
+		assertNoError(err, t, "CreateChanWatchpoint()")
+		if len(bp.Addrs) < 2 || bp.Cond == "" {
+			t.Fatalf("unexpected breakpoint %#v", bp)
+		}
+
+		_, err = c.CreateChanWatchpoint(api.EvalScope{GoroutineID: -1}, "i")
+		if err == nil {
+			t.Fatal("channel watchpoint on an integer did not fail")
+		}
+
+		// Three sends from main.main and three receives from main.main.func1
+		var sends, recvs int
+		for i := 0; i < 6; i++ {
+			state = <-c.Continue()
+			assertNoError(state.Err, t, "Continue()")
+			if state.CurrentThread.Breakpoint == nil || state.CurrentThread.Breakpoint.ID != bp.ID {
+				t.Fatalf("stopped at unexpected location %s:%d", state.CurrentThread.File, state.CurrentThread.Line)
+			}
+			frames, err := c.Stacktrace(-1, 2, 0, nil)
+			assertNoError(err, t, "Stacktrace()")
+			if len(frames) < 2 {
+				t.Fatalf("stacktrace too short: %d", len(frames))
+			}
+			caller := frames[1].Function.Name()
+			switch state.CurrentThread.Function.Name() {
+			case "runtime.chansend":
+				sends++
+				if caller != "runtime.chansend1" {
+					t.Errorf("unexpected caller of chansend %s", caller)
+				}
+			case "runtime.chanrecv":
+				recvs++
+			default:
+				t.Errorf("stopped in %s", state.CurrentThread.Function.Name())
+			}
+		}
+		if sends != 3 || recvs != 3 {
+			t.Errorf("expected 3 sends and 3 receives, got %d and %d", sends, recvs)
+		}
+
+		state = <-c.Continue()
+		if !state.Exited {
+			t.Fatalf("expected process to exit, got %#v", state)
+		}
+	})
+}
+
+func TestMutexState(t *testing.T) {
+	withTestClient2("mutexprog", t, func(c service.Client) {
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+		scope := api.EvalScope{GoroutineID: -1}
+
+		ms, err := c.MutexState(scope, "mu")
+		assertNoError(err, t, "MutexState(mu)")
+		if ms.RW || !ms.Locked || ms.Waiters != 3 || len(ms.Queued) != 3 {
+			t.Errorf("unexpected state of mu: %#v", ms)
+		}
+		for _, g := range ms.Queued {
+			if g.StartLoc.Function == nil || g.StartLoc.Function.Name() != "main.waiter" {
+				t.Errorf("unexpected goroutine waiting on mu: %#v", g)
+			}
+		}
+		for _, g := range ms.Holders {
+			if g.ID != state.SelectedGoroutine.ID {
+				t.Errorf("unexpected holder of mu: %d", g.ID)
+			}
+		}
+
+		ms, err = c.MutexState(scope, "&rwmu")
+		assertNoError(err, t, "MutexState(&rwmu)")
+		if !ms.RW || ms.Locked || ms.WriterPending || ms.Readers != 1 || len(ms.Queued) != 0 {
+			t.Errorf("unexpected state of rwmu: %#v", ms)
+		}
+
+		_, err = c.MutexState(scope, "mu.state")
+		if err == nil {
+			t.Errorf("MutexState on an integer did not fail")
+		}
+	})
+}
+
+func TestListTimers(t *testing.T) {
+	withTestClient2("timersprog", t, func(c service.Client) {
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+		timers, err := c.ListTimers()
+		assertNoError(err, t, "ListTimers()")
+
+		// The ticker, the timer and the AfterFunc timer, in this order, must
+		// be the last timers, the runtime can have other timers.
+		if len(timers) < 3 {
+			t.Fatalf("expected at least 3 timers, got %d", len(timers))
+		}
+		timers = timers[len(timers)-3:]
+		for i := 1; i < len(timers); i++ {
+			if timers[i].When < timers[i-1].When {
+				t.Errorf("timers not sorted: %d %d", timers[i-1].When, timers[i].When)
+			}
+		}
+		if timers[0].Period != time.Hour {
+			t.Errorf("wrong period of the ticker: %v", timers[0].Period)
+		}
+		if timers[1].Period != 0 || timers[2].Period != 0 {
+			t.Errorf("wrong period of the timers: %v %v", timers[1].Period, timers[2].Period)
+		}
+		if d := time.Duration(timers[2].When - timers[0].When); d < 2*time.Hour-time.Minute || d > 2*time.Hour {
+			t.Errorf("wrong distance between timers: %v", d)
+		}
+		if arg := timers[2].Arg.SinglelineString(); !strings.Contains(arg, "main.afterFunc") {
+			t.Errorf("wrong argument of the AfterFunc timer: %s", arg)
+		}
+	})
+}
+
+func TestListFileDescriptors(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("only supported on linux")
+	}
+	if testBackend == "rr" {
+		t.Skip("not supported on recordings")
+	}
+	withTestClient2("fdsprog", t, func(c service.Client) {
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+		fds, err := c.ListFileDescriptors()
+		assertNoError(err, t, "ListFileDescriptors()")
+
+		found := false
+		for _, fd := range fds {
+			t.Logf("%#v", fd)
+			if fd.Proto != "tcp" || fd.State != "LISTEN" {
+				continue
+			}
+			found = true
+			if !strings.HasPrefix(fd.Local, "127.0.0.1:") {
+				t.Errorf("wrong local address %q", fd.Local)
+			}
+			if len(fd.Goroutines) != 1 {
+				t.Errorf("expected one goroutine waiting on the listener, got %v", fd.Goroutines)
+			}
+		}
+		if !found {
+			t.Errorf("listener not found")
+		}
+	})
+}
+
+func TestEnviron(t *testing.T) {
+	protest.MustSupportFunctionCalls(t, testBackend)
+	withTestClient2("envprog", t, func(c service.Client) {
+		getenv := func(key string) (string, bool) {
+			environ, err := c.Environ()
+			assertNoError(err, t, "Environ()")
+			for _, kv := range environ {
+				if strings.HasPrefix(kv, key+"=") {
+					return kv[len(key)+1:], true
+				}
+			}
+			return "", false
+		}
+
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+		if v, ok := getenv("DLV_ENV_A"); !ok || v != "1" {
+			t.Errorf("wrong value of DLV_ENV_A: %q %v", v, ok)
+		}
+
+		assertNoError(c.SetEnv("DLV_ENV_B", "hello"), t, "SetEnv()")
+		assertNoError(c.UnsetEnv("DLV_ENV_A"), t, "UnsetEnv()")
+		if v, ok := getenv("DLV_ENV_B"); !ok || v != "hello" {
+			t.Errorf("wrong value of DLV_ENV_B: %q %v", v, ok)
+		}
+		if _, ok := getenv("DLV_ENV_A"); ok {
+			t.Errorf("DLV_ENV_A still set")
+		}
+		environ, err := c.Environ()
+		assertNoError(err, t, "Environ()")
+		for _, kv := range environ {
+			if kv == "" {
+				t.Errorf("empty entry in environment %q", environ)
+				break
+			}
+		}
+	})
+}
+
+func TestListMemoryRegions(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("memory map only tested on linux")
+	}
+	if testBackend == "rr" {
+		t.Skip("not supported on recordings")
+	}
+	withTestClient2("fdsprog", t, func(c service.Client) {
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+		regions, err := c.ListMemoryRegions()
+		assertNoError(err, t, "ListMemoryRegions()")
+
+		var binary, heap bool
+		stacks := 0
+		for i, r := range regions {
+			t.Logf("%#x-%#x %q %q heap=%v stacks=%d", r.Addr, r.Addr+r.Size, r.Filename, r.Image, r.GoHeap, r.GoroutineStacks)
+			if i > 0 && r.Addr < regions[i-1].Addr {
+				t.Errorf("regions not sorted")
+			}
+			if r.Image != "" && r.Exec {
+				binary = true
+			}
+			heap = heap || r.GoHeap
+			stacks += r.GoroutineStacks
+		}
+		if !binary {
+			t.Errorf("executable mapping not found")
+		}
+		if !heap {
+			t.Errorf("go heap not found")
+		}
+		if stacks < 2 {
+			t.Errorf("expected at least two goroutine stacks, got %d", stacks)
+		}
+	})
+}
+
+func TestGoroutineStackUsage(t *testing.T) {
+	withTestClient2("stackgrowth", t, func(c service.Client) {
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+		g := state.SelectedGoroutine
+		if g == nil {
+			t.Fatal("no selected goroutine")
+		}
+		t.Logf("%#v", g.Stack)
+		size := g.Stack.Hi - g.Stack.Lo
+		if g.Stack.Lo == 0 || size == 0 {
+			t.Fatalf("wrong stack bounds %#x-%#x", g.Stack.Lo, g.Stack.Hi)
+		}
+		if g.Stack.Used < 64*1024 || g.Stack.Used > size {
+			t.Errorf("wrong stack usage %d of %d", g.Stack.Used, size)
+		}
+		if g.Stack.Growths == 0 {
+			t.Errorf("stack growths not detected")
+		}
+	})
+}
+
+func TestStacktraceDepthLimit(t *testing.T) {
+	// An explicit depth is honored, the limit only applies when all frames
+	// are requested.
+	withTestClient2("deepstack", t, func(c service.Client) {
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		frames, err := c.Stacktrace(-1, 1200, 0, nil)
+		assertNoError(err, t, "Stacktrace(1200)")
+		if len(frames) != 1201 || frames[len(frames)-1].Bottom {
+			t.Errorf("wrong stacktrace with depth 1200: %d frames", len(frames))
+		}
+		frames, err = c.Stacktrace(-1, 2000, 0, nil)
+		assertNoError(err, t, "Stacktrace(2000)")
+		if len(frames) < 1500 || len(frames) > 2000 || !frames[len(frames)-1].Bottom {
+			t.Errorf("wrong stacktrace with depth 2000: %d frames", len(frames))
+		}
+
+		frames, err = c.Stacktrace(-1, -1, 0, nil)
+		assertNoError(err, t, "Stacktrace(-1)")
+		if len(frames) != 1001 || frames[len(frames)-1].Bottom {
+			t.Errorf("wrong stacktrace with depth -1: %d frames", len(frames))
+		}
+	})
+}
+
+func TestCancelRequestEval(t *testing.T) {
+	// CancelRequest is read by the server while a long Eval is in progress
+	// and interrupts it, the session is usable afterwards.
+	withTestClient2("largeslice", t, func(c service.Client) {
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		cfg := normalLoadConfig
+		cfg.MaxArrayValues = 1 << 21
+		errch := make(chan error, 1)
+		go func() {
+			_, err := c.EvalVariable(api.EvalScope{GoroutineID: -1}, "s", cfg)
+			errch <- err
+		}()
+		time.Sleep(50 * time.Millisecond)
+		assertNoError(c.CancelRequest(), t, "CancelRequest()")
+		select {
+		case err := <-errch:
+			if err == nil || err.Error() != proc.ErrOperationCanceled.Error() {
+				t.Fatalf("expected %q error, got %v", proc.ErrOperationCanceled, err)
+			}
+		case <-time.After(30 * time.Second):
+			t.Fatal("Eval was not interrupted")
+		}
+
+		v, err := c.EvalVariable(api.EvalScope{GoroutineID: -1}, "x", normalLoadConfig)
+		assertNoError(err, t, "EvalVariable(x)")
+		if v.Value != "0" {
+			t.Errorf("wrong value for x: %s", v.Value)
+		}
+	})
+}
+
+func TestRequestsOrdering(t *testing.T) {
+	// Synchronous calls run on their own goroutine but are still executed
+	// one at a time, in the order they are received, and their responses
+	// are sent in the same order.
+	clientConn, _ := startServer("largeslice", 0, t, [3]string{})
+	client := &brokenRPCClient{jsonrpc.NewClient(clientConn)}
+	defer client.Detach(true)
+	assertNoError(client.call("SetApiVersion", api.SetAPIVersionIn{APIVersion: 2}, &api.SetAPIVersionOut{}), t, "SetApiVersion")
+	var cout rpc2.CommandOut
+	assertNoError(client.call("Command", api.DebuggerCommand{Name: api.Continue}, &cout), t, "Continue")
+
+	scope := api.EvalScope{GoroutineID: -1}
+	cfg := normalLoadConfig
+	cfg.MaxArrayValues = 1 << 20
+	done := make(chan *rpc.Call, 4)
+	calls := []*rpc.Call{
+		// slow, the following calls must wait for it
+		client.client.Go("RPCServer.Eval", rpc2.EvalIn{Scope: scope, Expr: "s", Cfg: &cfg}, new(rpc2.EvalOut), done),
+		client.client.Go("RPCServer.Set", rpc2.SetIn{Scope: scope, Symbol: "x", Value: "1"}, new(rpc2.SetOut), done),
+		client.client.Go("RPCServer.Eval", rpc2.EvalIn{Scope: scope, Expr: "x", Cfg: &normalLoadConfig}, new(rpc2.EvalOut), done),
+		client.client.Go("RPCServer.Eval", rpc2.EvalIn{Scope: scope, Expr: "len(s)", Cfg: &normalLoadConfig}, new(rpc2.EvalOut), done),
+	}
+	for i, call := range calls {
+		got := <-done
+		if got != call {
+			t.Fatalf("response %d is for %s %#v, expected %s %#v", i, got.ServiceMethod, got.Args, call.ServiceMethod, call.Args)
+		}
+		assertNoError(got.Error, t, call.ServiceMethod)
+	}
+	if v := calls[2].Reply.(*rpc2.EvalOut).Variable; v.Value != "1" {
+		t.Errorf("Eval(x) did not observe the assignment: %s", v.Value)
+	}
+}
+
+func TestStacktraceChunks(t *testing.T) {
+	// retrieving a stacktrace in chunks returns the same frames as
+	// retrieving it at once
+	withTestClient2("deepstack", t, func(c service.Client) {
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		frames, err := c.Stacktrace(-1, 2000, 0, nil)
+		assertNoError(err, t, "Stacktrace()")
+		const chunkSize = 100
+		var chunked []api.Stackframe
+		for skip := 0; ; skip += chunkSize {
+			chunk, err := c.StacktraceChunk(-1, skip, chunkSize-1, 0, nil)
+			assertNoError(err, t, "StacktraceChunk()")
+			if len(chunk) > chunkSize {
+				chunk = chunk[:chunkSize]
+			}
+			chunked = append(chunked, chunk...)
+			if len(chunk) < chunkSize {
+				break
+			}
+		}
+		if len(chunked) != len(frames) {
+			t.Fatalf("wrong number of frames %d, expected %d", len(chunked), len(frames))
+		}
+		for i := range frames {
+			if chunked[i].PC != frames[i].PC || chunked[i].CFA != frames[i].CFA {
+				t.Errorf("frame %d mismatch: %#x %#x, expected %#x %#x", i, chunked[i].PC, chunked[i].CFA, frames[i].PC, frames[i].CFA)
+			}
+		}
+		if !chunked[len(chunked)-1].Bottom {
+			t.Errorf("last frame not marked as the bottom of the stack")
+		}
+	})
+}
+
+func TestBreakpointGroups(t *testing.T) {
+	withTestClient2("testtoggle", t, func(c service.Client) {
+		bp1, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.main", Line: 1, Groups: []string{"a"}})
+		assertNoError(err, t, "CreateBreakpoint 1")
+		bp2, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.main", Line: 2, Groups: []string{"a", "b"}})
+		assertNoError(err, t, "CreateBreakpoint 2")
+		bp3, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.main", Line: 3, Groups: []string{"b"}})
+		assertNoError(err, t, "CreateBreakpoint 3")
+		if len(bp2.Groups) != 2 || bp2.Groups[0] != "a" || bp2.Groups[1] != "b" {
+			t.Errorf("wrong groups %v", bp2.Groups)
+		}
+
+		_, err = c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.main", Line: 4, Groups: []string{"a b"}})
+		if err == nil {
+			t.Errorf("invalid group accepted")
+		}
+
+		disabled, err := c.AmendBreakpointGroup("a", true)
+		assertNoError(err, t, "AmendBreakpointGroup")
+		if len(disabled) != 2 || disabled[0].ID != bp1.ID || disabled[1].ID != bp2.ID {
+			t.Errorf("wrong breakpoints disabled %v", disabled)
+		}
+		isDisabled := func(id int) bool {
+			bp, err := c.GetBreakpoint(id)
+			assertNoError(err, t, "GetBreakpoint")
+			return bp.Disabled
+		}
+		if !isDisabled(bp1.ID) || !isDisabled(bp2.ID) || isDisabled(bp3.ID) {
+			t.Errorf("wrong breakpoints disabled")
+		}
+
+		cleared, err := c.ClearBreakpointGroup("b")
+		assertNoError(err, t, "ClearBreakpointGroup")
+		if len(cleared) != 2 {
+			t.Errorf("wrong breakpoints cleared %v", cleared)
+		}
+		bps, err := c.ListBreakpoints()
+		assertNoError(err, t, "ListBreakpoints")
+		for _, bp := range bps {
+			if bp.ID == bp2.ID || bp.ID == bp3.ID {
+				t.Errorf("breakpoint %d not cleared", bp.ID)
+			}
+		}
+
+		_, err = c.AmendBreakpointGroup("a", false)
+		assertNoError(err, t, "AmendBreakpointGroup")
+		if isDisabled(bp1.ID) {
+			t.Errorf("breakpoint %d not enabled", bp1.ID)
+		}
+
+		if _, err := c.ClearBreakpointGroup("nonexistent"); err == nil {
+			t.Errorf("no error clearing an empty group")
+		}
+	})
+}
+
+func TestClientServer_ClearBreakpoints(t *testing.T) {
+	withTestClient2("testtoggle", t, func(c service.Client) {
+		bp1, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.main", Line: 1})
+		assertNoError(err, t, "CreateBreakpoint 1")
+		bp2, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.main", Line: 2})
+		assertNoError(err, t, "CreateBreakpoint 2")
+		bp3, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.lineOne", Line: 0})
+		assertNoError(err, t, "CreateBreakpoint 3")
+		bp4, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.lineTwo", Line: 0})
+		assertNoError(err, t, "CreateBreakpoint 4")
+		bp2.Disabled = true
+		assertNoError(c.AmendBreakpoint(bp2), t, "AmendBreakpoint")
+
+		cleared, err := c.ClearBreakpoints(api.BreakpointFilter{FunctionName: "main.main"})
+		assertNoError(err, t, "ClearBreakpoints(FunctionName)")
+		if len(cleared) != 2 || cleared[0].ID != bp1.ID || cleared[1].ID != bp2.ID {
+			t.Errorf("wrong breakpoints cleared %v", cleared)
+		}
+
+		cleared, err = c.ClearBreakpoints(api.BreakpointFilter{Addrs: []uint64{bp3.Addr}})
+		assertNoError(err, t, "ClearBreakpoints(Addrs)")
+		if len(cleared) != 1 || cleared[0].ID != bp3.ID {
+			t.Errorf("wrong breakpoints cleared %v", cleared)
+		}
+
+		cleared, err = c.ClearBreakpoints(api.BreakpointFilter{File: "nonexistent.go"})
+		assertNoError(err, t, "ClearBreakpoints(File)")
+		if len(cleared) != 0 {
+			t.Errorf("wrong breakpoints cleared %v", cleared)
+		}
+
+		cleared, err = c.ClearBreakpoints(api.BreakpointFilter{File: "testtoggle.go"})
+		assertNoError(err, t, "ClearBreakpoints(File)")
+		if len(cleared) != 1 || cleared[0].ID != bp4.ID {
+			t.Errorf("wrong breakpoints cleared %v", cleared)
+		}
+
+		bps, err := c.ListBreakpoints()
+		assertNoError(err, t, "ListBreakpoints")
+		for _, bp := range bps {
+			if bp.ID >= 0 {
+				t.Errorf("breakpoint %d not cleared", bp.ID)
+			}
+		}
+		if len(bps) == 0 {
+			t.Errorf("internal breakpoints cleared")
+		}
+	})
+}
+
+func TestStopReason(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("testprog", t, func(c service.Client) {
+		bp, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.helloworld", Line: -1})
+		assertNoError(err, t, "CreateBreakpoint()")
+
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+		if state.StopReason.Kind != api.StopBreakpoint || state.StopReason.BreakpointID != bp.ID {
+			t.Errorf("wrong stop reason after Continue: %#v", state.StopReason)
+		}
+
+		state, err = c.Next()
+		assertNoError(err, t, "Next()")
+		if state.StopReason.Kind != api.StopStepComplete || state.StopReason.BreakpointID != 0 {
+			t.Errorf("wrong stop reason after Next: %#v", state.StopReason)
+		}
+
+		_, err = c.ClearBreakpoint(bp.ID)
+		assertNoError(err, t, "ClearBreakpoint()")
+		state = <-c.Continue()
+		if !state.Exited || state.StopReason.Kind != api.StopExited {
+			t.Errorf("wrong stop reason after exit: %#v", state.StopReason)
+		}
+	})
+
+	withTestClient2("panic", t, func(c service.Client) {
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+		if state.StopReason.Kind != api.StopPanic || state.StopReason.BreakpointID != -1 {
+			t.Errorf("wrong stop reason on panic: %#v", state.StopReason)
+		}
+	})
+}
+
+func TestObserverClient(t *testing.T) {
+	// Clients connected through the observer listener can inspect the
+	// target but not change it.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("couldn't start listener: %s\n", err)
+	}
+	defer listener.Close()
+	observerListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("couldn't start observer listener: %s\n", err)
+	}
+	server := rpccommon.NewServer(&service.Config{
+		Listener:         listener,
+		ObserverListener: observerListener,
+		ProcessArgs:      []string{protest.BuildFixture("testvariables2", 0).Path},
+		APIVersion:       1,
+		Debugger: debugger.Config{
+			Backend:     testBackend,
+			ExecuteKind: debugger.ExecutingGeneratedTest,
+		},
+	})
+	if err := server.Run(); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	// The observer connects first, it must be able to switch the server to
+	// API version 2 like any other client.
+	observer := rpc2.NewClient(observerListener.Addr().String())
+	defer observer.Disconnect(false)
+
+	client := rpc2.NewClient(listener.Addr().String())
+
+	_, err = client.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.main"})
+	assertNoError(err, t, "CreateBreakpoint()")
+	state := <-client.Continue()
+	assertNoError(state.Err, t, "Continue()")
+
+	if !observer.IsMulticlient() {
+		t.Error("observers should see a multiclient server")
+	}
+	ostate, err := observer.GetState()
+	assertNoError(err, t, "GetState() (observer)")
+	if ostate.CurrentThread == nil || ostate.CurrentThread.Function == nil || ostate.CurrentThread.Function.Name() != "main.main" {
+		t.Errorf("unexpected state for observer: %#v", ostate)
+	}
+	_, err = observer.Stacktrace(-1, 10, 0, nil)
+	assertNoError(err, t, "Stacktrace() (observer)")
+	bps, err := observer.ListBreakpoints()
+	assertNoError(err, t, "ListBreakpoints() (observer)")
+	if len(bps) == 0 {
+		t.Error("no breakpoints listed for observer")
+	}
+	locs, err := observer.FindLocation(api.EvalScope{GoroutineID: -1}, "main.main", true, nil)
+	assertNoError(err, t, "FindLocation() (observer)")
+	if len(locs) == 0 {
+		t.Error("no locations found for observer")
+	} else {
+		_, err = observer.DisassembleRange(api.EvalScope{GoroutineID: -1}, locs[0].PC, locs[0].PC+16, api.IntelFlavour)
+		assertNoError(err, t, "DisassembleRange() (observer)")
+	}
+	if observer.AttachedToExistingProcess() {
+		t.Error("observer reports an attached process")
+	}
+
+	if _, err := observer.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.foobar"}); err == nil || !strings.Contains(err.Error(), "read-only") {
+		t.Errorf("expected read-only error for CreateBreakpoint, got %v", err)
+	}
+	if _, err := observer.Halt(); err == nil || !strings.Contains(err.Error(), "read-only") {
+		t.Errorf("expected read-only error for Halt, got %v", err)
+	}
+	if err := observer.Detach(true); err == nil {
+		t.Error("observer was allowed to detach")
+	}
+
+	// The driving client is unaffected.
+	_, err = client.GetState()
+	assertNoError(err, t, "GetState()")
+}
+
+func TestMulticlientHandOff(t *testing.T) {
+	// Only one client at a time can change the state of the target, the
+	// other clients are notified of the changes.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("couldn't start listener: %s\n", err)
+	}
+	server := rpccommon.NewServer(&service.Config{
+		Listener:    listener,
+		ProcessArgs: []string{protest.BuildFixture("testvariables2", 0).Path},
+		AcceptMulti: true,
+		APIVersion:  2,
+		Debugger: debugger.Config{
+			Backend:     testBackend,
+			ExecuteKind: debugger.ExecutingGeneratedTest,
+		},
+	})
+	if err := server.Run(); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	client1 := rpc2.NewClient(listener.Addr().String())
+	defer client1.Disconnect(false)
+	client2 := rpc2.NewClient(listener.Addr().String())
+	defer client2.Disconnect(false)
+
+	// Connecting does not make a client the driver.
+	clients, _, err := client1.ListClients()
+	assertNoError(err, t, "ListClients()")
+	for _, c := range clients {
+		if c.Driver {
+			t.Errorf("client %d is the driver before calling any method: %#v", c.ID, clients)
+		}
+	}
+
+	_, err = client1.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.main"})
+	assertNoError(err, t, "CreateBreakpoint() (client1)")
+
+	clients, self2, err := client2.ListClients()
+	assertNoError(err, t, "ListClients()")
+	if len(clients) != 2 {
+		t.Fatalf("wrong number of clients: %#v", clients)
+	}
+	self1 := 0
+	for _, c := range clients {
+		if c.ID != self2 {
+			self1 = c.ID
+			if !c.Driver {
+				t.Errorf("client1 is not the driver: %#v", clients)
+			}
+		}
+	}
+
+	// client2 sees the breakpoint created by client1.
+	change, err := client2.WaitStateChange(0)
+	assertNoError(err, t, "WaitStateChange()")
+	if change.ChangedBy != self1 {
+		t.Errorf("wrong client for state change %d, expected %d", change.ChangedBy, self1)
+	}
+
+	if _, err := client2.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.afunc1"}); err == nil || !strings.Contains(err.Error(), "driving") {
+		t.Fatalf("expected error for non-driving client, got %v", err)
+	}
+	if err := client2.HandOff(0); err == nil {
+		t.Fatal("non-driving client was allowed to hand off control")
+	}
+
+	assertNoError(client1.HandOff(self2), t, "HandOff()")
+	_, err = client2.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.afunc1"})
+	assertNoError(err, t, "CreateBreakpoint() (client2)")
+	if _, err := client1.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.afunc2"}); err == nil {
+		t.Fatal("client1 can still change the target after handing off control")
+	}
+
+	// Control is released before Disconnect returns.
+	client2.Disconnect(false)
+	_, err = client1.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.afunc2"})
+	assertNoError(err, t, "CreateBreakpoint() after the driver disconnected")
+}
+
+func TestThreadInspection(t *testing.T) {
+	// Stacktrace and registers of background threads can be read
+	// without changing the current thread.
+	protest.AllowRecording(t)
+	withTestClient2("goroutinestackprog", t, func(c service.Client) {
+		_, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.stacktraceme"})
+		assertNoError(err, t, "CreateBreakpoint")
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue")
+		curThread := state.CurrentThread.ID
+
+		threads, err := c.ListThreads()
+		assertNoError(err, t, "ListThreads")
+		for _, th := range threads {
+			frames, err := c.ThreadStacktrace(th.ID, 10, nil)
+			assertNoError(err, t, fmt.Sprintf("ThreadStacktrace(%d)", th.ID))
+			if len(frames) == 0 || frames[0].PC != th.PC {
+				t.Errorf("thread %d: stacktrace does not start at %#x: %v", th.ID, th.PC, frames)
+			}
+			regs, err := c.ListThreadRegisters(th.ID, false)
+			assertNoError(err, t, fmt.Sprintf("ListThreadRegisters(%d)", th.ID))
+			if len(regs) == 0 {
+				t.Errorf("thread %d: no registers", th.ID)
+			}
+		}
+
+		state, err = c.GetState()
+		assertNoError(err, t, "GetState")
+		if state.CurrentThread.ID != curThread {
+			t.Errorf("current thread changed from %d to %d", curThread, state.CurrentThread.ID)
+		}
+
+		if _, err := c.ThreadStacktrace(-1, 10, nil); err == nil {
+			t.Errorf("expected error for a thread that doesn't exist")
+		}
+	})
+}
+
+func TestThreadsStacktraces(t *testing.T) {
+	// ThreadsStacktraces returns the stacktrace of every thread in a single
+	// call.
+	protest.AllowRecording(t)
+	withTestClient2("goroutinestackprog", t, func(c service.Client) {
+		_, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.stacktraceme"})
+		assertNoError(err, t, "CreateBreakpoint")
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue")
+
+		threads, err := c.ListThreads()
+		assertNoError(err, t, "ListThreads")
+		stacks, err := c.ThreadsStacktraces(10)
+		assertNoError(err, t, "ThreadsStacktraces")
+		if len(stacks) != len(threads) {
+			t.Fatalf("got %d stacktraces for %d threads", len(stacks), len(threads))
+		}
+		for i := range stacks {
+			if i > 0 && stacks[i-1].ThreadID >= stacks[i].ThreadID {
+				t.Errorf("stacktraces not sorted by thread ID")
+			}
+			if stacks[i].Err != "" || stacks[i].ThreadID != state.CurrentThread.ID {
+				continue
+			}
+			if len(stacks[i].Locations) == 0 || stacks[i].Locations[0].Function.Name() != "main.stacktraceme" {
+				t.Errorf("wrong stacktrace for the current thread: %v", stacks[i].Locations)
+			}
+		}
+	})
+}
+
+func TestActivity(t *testing.T) {
+	// The thread stopped at a breakpoint executes user code, idle threads of
+	// the runtime are blocked in system calls and system goroutines are
+	// classified as runtime or gc.
+	protest.AllowRecording(t)
+	withTestClient2("goroutinestackprog", t, func(c service.Client) {
+		_, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.stacktraceme"})
+		assertNoError(err, t, "CreateBreakpoint")
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue")
+
+		threads, err := c.ListThreads()
+		assertNoError(err, t, "ListThreads")
+		syscalls := 0
+		for _, th := range threads {
+			if th.ID == state.CurrentThread.ID && th.Activity != api.ActivityUser {
+				t.Errorf("thread stopped at a breakpoint has activity %q", th.Activity)
+			}
+			if th.Activity == api.ActivitySyscall {
+				syscalls++
+			}
+		}
+		if runtime.GOOS == "linux" && len(threads) > 1 && syscalls == 0 {
+			t.Errorf("no thread blocked in a system call")
+		}
+
+		gs, _, err := c.ListGoroutines(0, 0)
+		assertNoError(err, t, "ListGoroutines")
+		system := 0
+		for _, g := range gs {
+			switch g.Activity {
+			case api.ActivityRuntime, api.ActivityGC:
+				system++
+			case api.ActivityUser, api.ActivitySyscall:
+			default:
+				t.Errorf("goroutine %d has activity %q", g.ID, g.Activity)
+			}
+			if g.ID == state.SelectedGoroutine.ID && g.Activity != api.ActivityUser {
+				t.Errorf("selected goroutine has activity %q", g.Activity)
+			}
+		}
+		if system == 0 {
+			t.Errorf("no system goroutine")
+		}
+	})
+}
+
+func TestCaptureOutput(t *testing.T) {
+	// The output of the target is captured by the server and returned by
+	// GetOutput.
+	protest.AllowRecording(t)
+	if testBackend == "rr" {
+		t.Skip("output of recordings is not captured")
+	}
+	listener, clientConn := service.ListenerPipe()
+	defer listener.Close()
+	server := rpccommon.NewServer(&service.Config{
+		Listener:    listener,
+		ProcessArgs: []string{protest.BuildFixture("outputprog", 0).Path},
+		APIVersion:  2,
+		Debugger: debugger.Config{
+			Backend:       testBackend,
+			ExecuteKind:   debugger.ExecutingGeneratedTest,
+			CaptureOutput: true,
+		},
+	})
+	if err := server.Run(); err != nil {
+		t.Fatal(err)
+	}
+	client := rpc2.NewClientFromConn(clientConn)
+	defer client.Detach(true)
+
+	state := <-client.Continue()
+	if !state.Exited {
+		t.Fatalf("expected the target to exit, got %#v", state)
+	}
+
+	var stdout, stderr api.TargetOutput
+	var err error
+	for i := 0; i < 10; i++ {
+		stdout, stderr, err = client.GetOutput(0, 0)
+		assertNoError(err, t, "GetOutput")
+		if len(stdout.Data) > 0 && len(stderr.Data) > 0 {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if string(stdout.Data) != "to stdout\n" || stdout.Offset != 0 {
+		t.Errorf("wrong stdout %q at %d", stdout.Data, stdout.Offset)
+	}
+	if string(stderr.Data) != "to stderr\n" || stderr.Offset != 0 {
+		t.Errorf("wrong stderr %q at %d", stderr.Data, stderr.Offset)
+	}
+
+	stdout, _, err = client.GetOutput(int64(len("to ")), 0)
+	assertNoError(err, t, "GetOutput")
+	if string(stdout.Data) != "stdout\n" || stdout.Offset != 3 {
+		t.Errorf("wrong stdout %q at %d after offset 3", stdout.Data, stdout.Offset)
+	}
+}
+
+func TestChanState(t *testing.T) {
+	withTestClient2("chanbuf", t, func(c service.Client) {
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+		scope := api.EvalScope{GoroutineID: -1}
+
+		cs, err := c.ChanState(scope, "ch", normalLoadConfig)
+		assertNoError(err, t, "ChanState(ch)")
+		if cs.Len != 4 || cs.Cap != 4 || cs.Closed || len(cs.Receivers) != 0 {
+			t.Errorf("unexpected state of ch: %#v", cs)
+		}
+		var buf []string
+		for _, v := range cs.Buffer {
+			buf = append(buf, v.Value)
+		}
+		if got := strings.Join(buf, " "); got != "3 4 5 6" {
+			t.Errorf("wrong buffer of ch: %q", got)
+		}
+		if len(cs.Senders) != 1 || cs.Senders[0].StartLoc.Function == nil || cs.Senders[0].StartLoc.Function.Name() != "main.sender" {
+			t.Errorf("unexpected goroutines blocked sending to ch: %#v", cs.Senders)
+		}
+
+		cs, err = c.ChanState(scope, "&empty", normalLoadConfig)
+		assertNoError(err, t, "ChanState(&empty)")
+		if cs.Len != 0 || cs.Cap != 0 || len(cs.Buffer) != 0 || len(cs.Senders) != 0 || len(cs.Receivers) != 2 {
+			t.Errorf("unexpected state of empty: %#v", cs)
+		}
+
+		_, err = c.ChanState(scope, "len(ch)", normalLoadConfig)
+		if err == nil {
+			t.Errorf("ChanState on an integer did not fail")
+		}
+	})
+}
+
+func TestWatchExpressions(t *testing.T) {
+	withTestClient2Extended("break", t, 0, [3]string{}, func(c service.Client, fixture protest.Fixture) {
+		_, err := c.CreateBreakpoint(&api.Breakpoint{File: fixture.Source, Line: 7})
+		assertNoError(err, t, "CreateBreakpoint()")
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		w, err := c.AddWatchExpression("i", normalLoadConfig)
+		assertNoError(err, t, "AddWatchExpression(i)")
+		if w.Value == nil || w.Value.Value != "1" || w.Changed {
+			t.Fatalf("unexpected watch expression %#v", w)
+		}
+		_, err = c.AddWatchExpression("nonexistent", normalLoadConfig)
+		assertNoError(err, t, "AddWatchExpression(nonexistent)")
+
+		exprs, err := c.ListWatchExpressions()
+		assertNoError(err, t, "ListWatchExpressions()")
+		if len(exprs) != 2 || exprs[1].Value != nil || exprs[1].Err == "" {
+			t.Fatalf("unexpected watch expressions %#v", exprs)
+		}
+
+		out, err := c.WaitWatchExpressions(0)
+		assertNoError(err, t, "WaitWatchExpressions(0)")
+		seq := out.Seq
+
+		done := make(chan *api.WaitWatchExpressionsOut)
+		go func() {
+			out, err := c.WaitWatchExpressions(seq)
+			if err != nil {
+				t.Errorf("WaitWatchExpressions(%d): %v", seq, err)
+			}
+			done <- out
+		}()
+		state = <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+		out = <-done
+		if out == nil || out.Seq <= seq || len(out.Exprs) != 2 {
+			t.Fatalf("unexpected result %#v", out)
+		}
+		w = &out.Exprs[0]
+		if w.Value == nil || w.OldValue == nil || w.Value.Value != "2" || w.OldValue.Value != "1" || !w.Changed {
+			t.Errorf("unexpected watch expression %#v", w)
+		}
+
+		assertNoError(c.RemoveWatchExpression(w.ID), t, "RemoveWatchExpression()")
+		if err := c.RemoveWatchExpression(w.ID); err == nil {
+			t.Errorf("removing a watch expression twice did not fail")
+		}
+		exprs, err = c.ListWatchExpressions()
+		assertNoError(err, t, "ListWatchExpressions()")
+		if len(exprs) != 1 || exprs[0].Expr != "nonexistent" {
+			t.Errorf("unexpected watch expressions after remove %#v", exprs)
+		}
+	})
+}
+
+func TestStopOnEntry(t *testing.T) {
+	if testBackend == "rr" {
+		t.Skip("stop-on-entry does not apply to recordings")
+	}
+	listener, clientConn := service.ListenerPipe()
+	defer listener.Close()
+	fixture := protest.BuildFixture("testnextprog", 0)
+	server := rpccommon.NewServer(&service.Config{
+		Listener:    listener,
+		ProcessArgs: []string{fixture.Path},
+		Debugger: debugger.Config{
+			Backend:     testBackend,
+			ExecuteKind: debugger.ExecutingGeneratedFile,
+			StopOnEntry: true,
+		},
+	})
+	if err := server.Run(); err != nil {
+		t.Fatal(err)
+	}
+	client := rpc2.NewClientFromConn(clientConn)
+	defer client.Detach(true)
+
+	checkAtMain := func(when string) {
+		t.Helper()
+		state, err := client.GetState()
+		assertNoError(err, t, "GetState()")
+		if state.CurrentThread == nil || state.CurrentThread.Function == nil || state.CurrentThread.Function.Name() != "main.main" {
+			t.Fatalf("%s: not stopped in main.main: %#v", when, state.CurrentThread)
+		}
+		bps, err := client.ListBreakpoints()
+		assertNoError(err, t, "ListBreakpoints()")
+		for _, bp := range bps {
+			if bp.ID >= 0 {
+				t.Errorf("%s: unexpected breakpoint %#v", when, bp)
+			}
+		}
+	}
+
+	checkAtMain("launch")
+	_, err := client.Restart(false)
+	assertNoError(err, t, "Restart()")
+	checkAtMain("restart")
+}
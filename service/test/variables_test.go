@@ -132,9 +132,9 @@ func withTestProcessArgs(name string, t *testing.T, wd string, args []string, bu
 	var tracedir string
 	switch testBackend {
 	case "native":
-		p, err = native.Launch(append([]string{fixture.Path}, args...), wd, 0, []string{}, "", [3]string{})
+		p, err = native.Launch(append([]string{fixture.Path}, args...), wd, 0, []string{}, "", "", proc.OutputRedirect{}, proc.OutputRedirect{})
 	case "lldb":
-		p, err = gdbserial.LLDBLaunch(append([]string{fixture.Path}, args...), wd, 0, []string{}, "", [3]string{})
+		p, err = gdbserial.LLDBLaunch(append([]string{fixture.Path}, args...), wd, 0, []string{}, "", "", proc.OutputRedirect{}, proc.OutputRedirect{})
 	case "rr":
 		protest.MustHaveRecordingAllowed(t)
 		t.Log("recording")
@@ -281,6 +281,37 @@ func TestSetVariable(t *testing.T) {
 	})
 }
 
+func TestSetVariableStringLiteral(t *testing.T) {
+	protest.MustSupportFunctionCalls(t, testBackend)
+	withTestProcess("testvariables2", t, func(p *proc.Target, fixture protest.Fixture) {
+		assertNoError(p.Continue(), t, "Continue()")
+
+		// string literals can not be assigned without allocating memory in the target
+		if err := setVariable(p, "s1[0]", `"four"`); err == nil {
+			t.Fatal("expected error assigning a string literal with EvalScope.SetVariable")
+		}
+
+		assertNoError(proc.SetVariable(p, p.CurrentThread(), "s1[0]", `"four"`), t, "SetVariable()")
+		variable, err := evalVariable(p, "s1[0]", pnormalLoadConfig)
+		assertNoError(err, t, "EvalVariable()")
+		assertVariable(t, variable, varTest{"s1[0]", true, `"four"`, "", "string", nil})
+
+		// other assignments do not resume the target
+		assertNoError(proc.SetVariable(p, p.CurrentThread(), "i1", "7"), t, "SetVariable()")
+		variable, err = evalVariable(p, "i1", pnormalLoadConfig)
+		assertNoError(err, t, "EvalVariable()")
+		assertVariable(t, variable, varTest{"i1", true, "7", "", "int", nil})
+
+		// a breakpoint inside the allocation interrupts the assignment
+		bp := setFunctionBreakpoint(p, t, "runtime.mallocgc")
+		if err := proc.SetVariable(p, p.CurrentThread(), "s1[1]", `"five"`); err == nil {
+			t.Fatal("expected error assigning a string literal when the allocation hits a breakpoint")
+		}
+		_, err = p.ClearBreakpoint(bp.Addr)
+		assertNoError(err, t, "ClearBreakpoint()")
+	})
+}
+
 func TestVariableEvaluationShort(t *testing.T) {
 	testcases := []varTest{
 		{"a1", true, "\"foofoofoofoofoofoo\"", "", "string", nil},
@@ -519,6 +550,12 @@ func TestEmbeddedStruct(t *testing.T) {
 			{"w4.I.F", false, `"T-inside-W1"`, `"T-inside-W1"`, "string", nil},
 			{"w4.F", false, ``, ``, "", errors.New("w4 has no member F")},
 			{"w5.F", false, ``, ``, "", errors.New("w5 has no member F")},
+
+			// Go selector rules: fields at the same depth are ambiguous, a field
+			// hides a method at a deeper depth
+			{"w6.F", false, ``, ``, "", errors.New("ambiguous selector w6.F")},
+			{"w6.W7.F", true, `"T-inside-W7"`, `"T-inside-W7"`, "string", nil},
+			{"w8.M", true, "8", "8", "int", nil},
 		}
 		assertNoError(p.Continue(), t, "Continue()")
 
@@ -1515,7 +1552,7 @@ func TestPluginVariables(t *testing.T) {
 		// test that PackageVariables returns variables from the executable and plugins
 		scope, err := evalScope(p)
 		assertNoError(err, t, "evalScope")
-		allvars, err := scope.PackageVariables(pnormalLoadConfig)
+		allvars, err := scope.PackageVariables(nil, pnormalLoadConfig)
 		assertNoError(err, t, "PackageVariables")
 		var plugin2AFound, mainExeGlobalFound bool
 		for _, v := range allvars {
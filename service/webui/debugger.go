@@ -0,0 +1,125 @@
+package webui
+
+import (
+	"errors"
+
+	"github.com/go-delve/delve/pkg/proc"
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/debugger"
+)
+
+var (
+	errNoSession = errors.New("no debug session")
+	errReadOnly  = errors.New("the debug session is controlled by its DAP client, the web frontend is read-only")
+)
+
+// debuggerClient is a read-only Client that calls a debugger directly.
+type debuggerClient struct {
+	debugger func() *debugger.Debugger
+}
+
+// NewDebuggerClient returns a Client that inspects the debugger returned
+// by getDebugger, which can return nil when there is no debug session.
+// Commands and breakpoint changes are refused, they belong to whoever
+// drives the debugger, for example a DAP client.
+func NewDebuggerClient(getDebugger func() *debugger.Debugger) Client {
+	return &debuggerClient{debugger: getDebugger}
+}
+
+func (c *debuggerClient) get() (*debugger.Debugger, error) {
+	d := c.debugger()
+	if d == nil {
+		return nil, errNoSession
+	}
+	return d, nil
+}
+
+func (c *debuggerClient) GetStateNonBlocking() (*api.DebuggerState, error) {
+	d, err := c.get()
+	if err != nil {
+		return nil, err
+	}
+	return d.State(true)
+}
+
+func (c *debuggerClient) Command(name string, opts api.CommandOptions) (*api.DebuggerState, error) {
+	return nil, errReadOnly
+}
+
+func (c *debuggerClient) Halt() (*api.DebuggerState, error) {
+	return nil, errReadOnly
+}
+
+func (c *debuggerClient) ListBreakpoints() ([]*api.Breakpoint, error) {
+	d, err := c.get()
+	if err != nil {
+		return nil, err
+	}
+	return d.Breakpoints(), nil
+}
+
+func (c *debuggerClient) FindLocation(scope api.EvalScope, loc string, findInstruction bool, substitutePathRules [][2]string) ([]api.Location, error) {
+	return nil, errReadOnly
+}
+
+func (c *debuggerClient) CreateBreakpoint(*api.Breakpoint) (*api.Breakpoint, error) {
+	return nil, errReadOnly
+}
+
+func (c *debuggerClient) ClearBreakpoint(id int) (*api.Breakpoint, error) {
+	return nil, errReadOnly
+}
+
+func (c *debuggerClient) ListGoroutines(start, count int) ([]*api.Goroutine, int, error) {
+	d, err := c.get()
+	if err != nil {
+		return nil, 0, err
+	}
+	gs, nextg, err := d.Goroutines(start, count)
+	if err != nil {
+		return nil, 0, err
+	}
+	d.LockTarget()
+	defer d.UnlockTarget()
+	return api.ConvertGoroutines(d.Target(), gs), nextg, nil
+}
+
+func (c *debuggerClient) Stacktrace(goroutineID int, depth int, opts api.StacktraceOptions, cfg *api.LoadConfig) ([]api.Stackframe, error) {
+	d, err := c.get()
+	if err != nil {
+		return nil, err
+	}
+	frames, err := d.Stacktrace(goroutineID, depth, opts)
+	if err != nil {
+		return nil, err
+	}
+	return d.ConvertStacktrace(frames, api.LoadConfigToProc(cfg))
+}
+
+func (c *debuggerClient) ListFunctionArgs(scope api.EvalScope, cfg api.LoadConfig) ([]api.Variable, error) {
+	return c.vars(scope, cfg, (*debugger.Debugger).FunctionArguments)
+}
+
+func (c *debuggerClient) ListLocalVariables(scope api.EvalScope, cfg api.LoadConfig) ([]api.Variable, error) {
+	return c.vars(scope, cfg, (*debugger.Debugger).LocalVariables)
+}
+
+func (c *debuggerClient) vars(scope api.EvalScope, cfg api.LoadConfig, f func(*debugger.Debugger, int, int, int, proc.LoadConfig) ([]*proc.Variable, error)) ([]api.Variable, error) {
+	d, err := c.get()
+	if err != nil {
+		return nil, err
+	}
+	vars, err := f(d, scope.GoroutineID, scope.Frame, scope.DeferredCall, *api.LoadConfigToProc(&cfg))
+	if err != nil {
+		return nil, err
+	}
+	return api.ConvertVars(vars), nil
+}
+
+func (c *debuggerClient) ListSources(filter string) ([]string, error) {
+	d, err := c.get()
+	if err != nil {
+		return nil, err
+	}
+	return d.Sources(filter)
+}
@@ -0,0 +1,150 @@
+package webui
+
+// indexPage is the single page application served by Server. It only uses
+// the JSON API exposed by Server and has no external dependencies.
+const indexPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Delve</title>
+<style>
+body { font-family: sans-serif; margin: 0; display: flex; height: 100vh; }
+#main { flex: 3; display: flex; flex-direction: column; border-right: 1px solid #ccc; }
+#side { flex: 2; overflow: auto; padding: 0 8px; }
+#toolbar { padding: 6px; border-bottom: 1px solid #ccc; }
+#status { margin-left: 12px; color: #555; }
+#source { flex: 1; overflow: auto; font-family: monospace; white-space: pre; margin: 0; }
+#source div { cursor: pointer; }
+#source .bp { background: #fdd; }
+#source .cur { background: #ffa; }
+#source .ln { display: inline-block; width: 5em; color: #999; text-align: right; padding-right: 1em; }
+h3 { margin: 12px 0 4px 0; }
+li { font-family: monospace; cursor: pointer; }
+</style>
+</head>
+<body>
+<div id="main">
+  <div id="toolbar">
+    <button onclick="command('continue')">Continue</button>
+    <button onclick="command('next')">Next</button>
+    <button onclick="command('step')">Step</button>
+    <button onclick="command('stepOut')">Step out</button>
+    <button onclick="command('halt')">Halt</button>
+    <button onclick="refresh()">Refresh</button>
+    <span id="status"></span>
+  </div>
+  <div id="source"></div>
+</div>
+<div id="side">
+  <h3>Breakpoints</h3>
+  <input id="newbp" placeholder="location, e.g. main.main"><button onclick="addBreakpoint()">Add</button>
+  <ul id="breakpoints"></ul>
+  <h3>Stack</h3><ul id="stack"></ul>
+  <h3>Variables</h3><ul id="variables"></ul>
+  <h3>Goroutines</h3><ul id="goroutines"></ul>
+</div>
+<script>
+var state = null, file = "", breakpoints = [], goroutine = -1, frame = 0;
+
+function api(method, path, params) {
+  var opts = {method: method};
+  if (method === "GET") {
+    if (params) path += "?" + new URLSearchParams(params);
+  } else {
+    opts.headers = {"Content-Type": "application/json"};
+    opts.body = JSON.stringify(params || {});
+  }
+  return fetch(path, opts).then(function(r) {
+    if (!r.ok) return r.text().then(function(t) { throw new Error(t); });
+    return r.headers.get("Content-Type") === "application/json" ? r.json() : r.text();
+  });
+}
+
+function status(msg) { document.getElementById("status").textContent = msg; }
+
+function list(id, items, text, onclick) {
+  var ul = document.getElementById(id);
+  ul.innerHTML = "";
+  (items || []).forEach(function(item, i) {
+    var li = document.createElement("li");
+    li.textContent = text(item, i);
+    if (onclick) li.onclick = function() { onclick(item, i); };
+    ul.appendChild(li);
+  });
+}
+
+function command(name) {
+  status(name + "...");
+  api("POST", "/api/command", {Name: name}).then(refresh, function(e) { status(e.message); });
+}
+
+function addBreakpoint() {
+  api("POST", "/api/breakpoints", {Loc: document.getElementById("newbp").value}).then(refresh, function(e) { status(e.message); });
+}
+
+function toggleBreakpoint(line) {
+  var bp = breakpoints.find(function(bp) { return bp.file === file && bp.line === line; });
+  var req = bp ? api("DELETE", "/api/breakpoints", {ID: bp.id}) : api("POST", "/api/breakpoints", {Loc: file + ":" + line});
+  req.then(refresh, function(e) { status(e.message); });
+}
+
+function showSource(f, line) {
+  var load = f === file ? Promise.resolve(null) : api("GET", "/api/source", {file: f});
+  load.then(function(text) {
+    var src = document.getElementById("source");
+    if (text !== null) {
+      file = f;
+      src.innerHTML = "";
+      text.split("\n").forEach(function(l, i) {
+        var div = document.createElement("div");
+        var ln = document.createElement("span");
+        ln.className = "ln";
+        ln.textContent = i + 1;
+        div.appendChild(ln);
+        div.appendChild(document.createTextNode(l));
+        div.onclick = function() { toggleBreakpoint(i + 1); };
+        src.appendChild(div);
+      });
+    }
+    Array.prototype.forEach.call(src.children, function(div, i) {
+      div.className = breakpoints.some(function(bp) { return bp.file === file && bp.line === i + 1; }) ? "bp" : "";
+      if (i + 1 === line) { div.className += " cur"; div.scrollIntoView({block: "center"}); }
+    });
+  }, function(e) { status(e.message); });
+}
+
+function selectFrame(f, i) {
+  frame = i;
+  showSource(f.file, f.line);
+  api("GET", "/api/variables", {goroutine: goroutine, frame: frame}).then(function(v) {
+    list("variables", (v.Args || []).concat(v.Locals || []), function(v) { return v.name + " " + v.type + " = " + v.value; });
+  }, function(e) { status(e.message); });
+}
+
+function selectGoroutine(id) {
+  goroutine = id;
+  api("GET", "/api/stacktrace", {goroutine: goroutine}).then(function(frames) {
+    list("stack", frames, function(f) { return (f.function ? f.function.name : "?") + " " + f.file + ":" + f.line; }, selectFrame);
+    if (frames && frames.length > 0) selectFrame(frames[0], 0);
+  }, function(e) { status(e.message); });
+}
+
+function refresh() {
+  Promise.all([api("GET", "/api/state"), api("GET", "/api/breakpoints")]).then(function(r) {
+    state = r[0]; breakpoints = r[1] || [];
+    list("breakpoints", breakpoints.filter(function(bp) { return bp.id > 0; }), function(bp) { return bp.id + " " + bp.file + ":" + bp.line; }, function(bp) { showSource(bp.file, bp.line); });
+    if (state.exited) { status("exited with status " + state.exitStatus); return; }
+    if (state.Running) { status("running"); return; }
+    status("stopped");
+    api("GET", "/api/goroutines").then(function(gs) {
+      list("goroutines", gs, function(g) { return g.id + " " + g.userCurrentLoc.file + ":" + g.userCurrentLoc.line; }, function(g) { selectGoroutine(g.id); });
+    });
+    selectGoroutine(state.currentGoroutine ? state.currentGoroutine.id : -1);
+  }, function(e) { status(e.message); });
+}
+
+refresh();
+</script>
+</body>
+</html>
+`
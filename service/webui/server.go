@@ -0,0 +1,275 @@
+// Package webui implements a small web frontend for Delve.
+//
+// The frontend is a single page served over HTTP together with a JSON API
+// that forwards requests to a headless instance of Delve through a
+// service.Client, or to the debugger of a DAP session. It is meant for
+// quick debugging sessions on headless machines where only a browser is
+// available.
+//
+// Source files are read from the file system of the machine serving the
+// frontend, when it is not the one running the debugger the source tree
+// must be available at the same paths on both.
+//
+// Requests that change the state of the debugger must have a JSON body
+// and, if they have an Origin header, it must match the Host they are sent
+// to. Browsers will not send such requests on behalf of other sites
+// without asking the server first, which the frontend never allows.
+package webui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+// Client is the subset of service.Client used by the frontend.
+type Client interface {
+	GetStateNonBlocking() (*api.DebuggerState, error)
+	Command(name string, opts api.CommandOptions) (*api.DebuggerState, error)
+	Halt() (*api.DebuggerState, error)
+	ListBreakpoints() ([]*api.Breakpoint, error)
+	FindLocation(scope api.EvalScope, loc string, findInstruction bool, substitutePathRules [][2]string) ([]api.Location, error)
+	CreateBreakpoint(*api.Breakpoint) (*api.Breakpoint, error)
+	ClearBreakpoint(id int) (*api.Breakpoint, error)
+	ListGoroutines(start, count int) ([]*api.Goroutine, int, error)
+	Stacktrace(goroutineID int, depth int, opts api.StacktraceOptions, cfg *api.LoadConfig) ([]api.Stackframe, error)
+	ListFunctionArgs(scope api.EvalScope, cfg api.LoadConfig) ([]api.Variable, error)
+	ListLocalVariables(scope api.EvalScope, cfg api.LoadConfig) ([]api.Variable, error)
+	ListSources(filter string) ([]string, error)
+}
+
+// Server serves the web frontend.
+type Server struct {
+	client   Client
+	listener net.Listener
+	srv      *http.Server
+}
+
+// loadConfig is used to load variables displayed by the frontend.
+var loadConfig = api.LoadConfig{FollowPointers: true, MaxVariableRecurse: 1, MaxStringLen: 64, MaxArrayValues: 64, MaxStructFields: -1}
+
+// maxGoroutines is the maximum number of goroutines listed by the frontend.
+const maxGoroutines = 1000
+
+// stackDepth is the depth of the stacktraces shown by the frontend.
+const stackDepth = 50
+
+// NewServer creates a new Server that will accept HTTP connections on
+// listener and execute requests using client.
+func NewServer(client Client, listener net.Listener) *Server {
+	s := &Server{client: client, listener: listener}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.index)
+	mux.HandleFunc("/api/state", s.state)
+	mux.HandleFunc("/api/command", s.command)
+	mux.HandleFunc("/api/breakpoints", s.breakpoints)
+	mux.HandleFunc("/api/goroutines", s.goroutines)
+	mux.HandleFunc("/api/stacktrace", s.stacktrace)
+	mux.HandleFunc("/api/variables", s.variables)
+	mux.HandleFunc("/api/source", s.source)
+	s.srv = &http.Server{Handler: mux}
+	return s
+}
+
+// Run serves HTTP requests until Stop is called.
+func (s *Server) Run() error {
+	err := s.srv.Serve(s.listener)
+	if err == http.ErrServerClosed {
+		return nil
+	}
+	return err
+}
+
+// Stop stops the server, the client is not detached.
+func (s *Server) Stop() error {
+	return s.srv.Close()
+}
+
+func (s *Server) index(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, indexPage)
+}
+
+func (s *Server) state(w http.ResponseWriter, r *http.Request) {
+	state, err := s.client.GetStateNonBlocking()
+	reply(w, state, err)
+}
+
+// command resumes the target, the request blocks until the target stops
+// again, except for halt.
+func (s *Server) command(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var in struct{ Name string }
+	if !decodeBody(w, r, &in) {
+		return
+	}
+	var state *api.DebuggerState
+	var err error
+	switch name := in.Name; name {
+	case api.Continue, api.Next, api.Step, api.StepOut:
+		state, err = s.client.Command(name, nil)
+	case api.Halt:
+		state, err = s.client.Halt()
+	default:
+		err = fmt.Errorf("unknown command %q", name)
+	}
+	reply(w, state, err)
+}
+
+func (s *Server) breakpoints(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		bps, err := s.client.ListBreakpoints()
+		reply(w, bps, err)
+	case http.MethodPost:
+		var in struct{ Loc string }
+		if !decodeBody(w, r, &in) {
+			return
+		}
+		locs, err := s.client.FindLocation(api.EvalScope{GoroutineID: -1}, in.Loc, true, nil)
+		if err == nil && len(locs) == 0 {
+			err = errors.New("location not found")
+		}
+		if err != nil {
+			reply(w, nil, err)
+			return
+		}
+		bp, err := s.client.CreateBreakpoint(&api.Breakpoint{Addr: locs[0].PC, Addrs: locs[0].PCs})
+		reply(w, bp, err)
+	case http.MethodDelete:
+		var in struct{ ID int }
+		if !decodeBody(w, r, &in) {
+			return
+		}
+		bp, err := s.client.ClearBreakpoint(in.ID)
+		reply(w, bp, err)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) goroutines(w http.ResponseWriter, r *http.Request) {
+	gs, _, err := s.client.ListGoroutines(0, maxGoroutines)
+	reply(w, gs, err)
+}
+
+func (s *Server) stacktrace(w http.ResponseWriter, r *http.Request) {
+	gid, err := intParam(r, "goroutine", -1)
+	if err != nil {
+		reply(w, nil, err)
+		return
+	}
+	frames, err := s.client.Stacktrace(gid, stackDepth, 0, nil)
+	reply(w, frames, err)
+}
+
+func (s *Server) variables(w http.ResponseWriter, r *http.Request) {
+	scope := api.EvalScope{GoroutineID: -1}
+	var err error
+	if scope.GoroutineID, err = intParam(r, "goroutine", -1); err != nil {
+		reply(w, nil, err)
+		return
+	}
+	if scope.Frame, err = intParam(r, "frame", 0); err != nil {
+		reply(w, nil, err)
+		return
+	}
+	args, err := s.client.ListFunctionArgs(scope, loadConfig)
+	if err != nil {
+		reply(w, nil, err)
+		return
+	}
+	locals, err := s.client.ListLocalVariables(scope, loadConfig)
+	reply(w, struct {
+		Args   []api.Variable
+		Locals []api.Variable
+	}{args, locals}, err)
+}
+
+// source returns the contents of a source file of the target. Only files
+// listed in the debug information of the target can be read.
+func (s *Server) source(w http.ResponseWriter, r *http.Request) {
+	file := r.FormValue("file")
+	sources, err := s.client.ListSources("")
+	if err != nil {
+		reply(w, nil, err)
+		return
+	}
+	found := false
+	for _, source := range sources {
+		if source == file {
+			found = true
+			break
+		}
+	}
+	if !found {
+		http.Error(w, fmt.Sprintf("%q is not a source file of the target", file), http.StatusForbidden)
+		return
+	}
+	buf, err := ioutil.ReadFile(file)
+	if err != nil {
+		reply(w, nil, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write(buf)
+}
+
+func intParam(r *http.Request, name string, dflt int) (int, error) {
+	v := r.FormValue(name)
+	if v == "" {
+		return dflt, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %v", name, err)
+	}
+	return n, nil
+}
+
+// decodeBody decodes the JSON body of a request that changes the state of
+// the debugger into v. Requests that a browser could send on behalf of
+// another site are rejected, the caller must return if false is returned.
+func decodeBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt != "application/json" {
+		http.Error(w, "request body must be JSON", http.StatusUnsupportedMediaType)
+		return false
+	}
+	if origin := r.Header.Get("Origin"); origin != "" {
+		u, err := url.Parse(origin)
+		if err != nil || u.Host != r.Host {
+			http.Error(w, "cross origin requests are not allowed", http.StatusForbidden)
+			return false
+		}
+	}
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// reply writes v encoded as JSON, or err.
+func reply(w http.ResponseWriter, v interface{}, err error) {
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
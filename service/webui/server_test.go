@@ -0,0 +1,88 @@
+package webui
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/debugger"
+)
+
+type fakeClient struct {
+	Client
+	sources []string
+}
+
+func (c *fakeClient) ListSources(filter string) ([]string, error) {
+	return c.sources, nil
+}
+
+func (c *fakeClient) GetStateNonBlocking() (*api.DebuggerState, error) {
+	return &api.DebuggerState{Running: true}, nil
+}
+
+func TestServer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := NewServer(&fakeClient{sources: []string{"server_test.go"}}, listener)
+	go server.Run()
+	defer server.Stop()
+	base := "http://" + listener.Addr().String()
+
+	for _, tc := range []struct {
+		method, path string
+		contentType  string
+		origin       string
+		body         string
+		status       int
+	}{
+		{"GET", "/", "", "", "", http.StatusOK},
+		{"GET", "/api/state", "", "", "", http.StatusOK},
+		{"GET", "/api/source?file=server_test.go", "", "", "", http.StatusOK},
+		{"GET", "/api/source?file=server.go", "", "", "", http.StatusForbidden},
+		{"GET", "/api/command?name=continue", "", "", "", http.StatusMethodNotAllowed},
+		{"POST", "/api/command", "application/json", "", `{"Name":"restart"}`, http.StatusInternalServerError},
+		{"POST", "/api/command", "application/json", base, `{"Name":"restart"}`, http.StatusInternalServerError},
+		{"POST", "/api/command", "application/x-www-form-urlencoded", "", "name=continue", http.StatusUnsupportedMediaType},
+		{"POST", "/api/command", "text/plain", "", `{"Name":"continue"}`, http.StatusUnsupportedMediaType},
+		{"POST", "/api/command", "application/json", "http://example.com", `{"Name":"continue"}`, http.StatusForbidden},
+		{"DELETE", "/api/breakpoints", "application/json", "http://example.com", `{"ID":1}`, http.StatusForbidden},
+		{"POST", "/api/breakpoints", "application/json", "", `{"Loc":`, http.StatusBadRequest},
+	} {
+		req, err := http.NewRequest(tc.method, base+tc.path, strings.NewReader(tc.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.contentType != "" {
+			req.Header.Set("Content-Type", tc.contentType)
+		}
+		if tc.origin != "" {
+			req.Header.Set("Origin", tc.origin)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tc.status {
+			t.Errorf("%s %s %s %s: got status %d, expected %d", tc.method, tc.path, tc.contentType, tc.origin, resp.StatusCode, tc.status)
+		}
+	}
+}
+
+func TestDebuggerClientNoSession(t *testing.T) {
+	client := NewDebuggerClient(func() *debugger.Debugger { return nil })
+	if _, err := client.GetStateNonBlocking(); err != errNoSession {
+		t.Errorf("GetStateNonBlocking: got %v, expected %v", err, errNoSession)
+	}
+	if _, err := client.Command(api.Continue, nil); err != errReadOnly {
+		t.Errorf("Command: got %v, expected %v", err, errReadOnly)
+	}
+	if _, err := client.CreateBreakpoint(&api.Breakpoint{}); err != errReadOnly {
+		t.Errorf("CreateBreakpoint: got %v, expected %v", err, errReadOnly)
+	}
+}